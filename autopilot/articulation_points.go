@@ -0,0 +1,104 @@
+package autopilot
+
+// dfsFrame is an entry of the explicit stack of the depth first search that
+// finds articulation points. It holds a node along with the index of the next
+// neighbor to visit.
+type dfsFrame struct {
+	node int
+	next int
+}
+
+// ArticulationPoints returns the nodes whose removal disconnects the part of
+// the graph that they belong to. These nodes are single points of failure for
+// the connectivity of the nodes behind them. The articulation points are
+// found with Tarjan's algorithm, using an explicit stack to support graphs of
+// any depth.
+func (graph *SimpleGraph) ArticulationPoints() []NodeID {
+	n := len(graph.Adj)
+
+	// disc holds the discovery time of each node, with zero meaning that
+	// the node wasn't visited yet. low holds the lowest discovery time
+	// that is reachable from the subtree of a node through a back edge.
+	var (
+		disc           = make([]int, n)
+		low            = make([]int, n)
+		parent         = make([]int, n)
+		isArticulation = make([]bool, n)
+		time           int
+	)
+
+	for root := 0; root < n; root++ {
+		if disc[root] != 0 {
+			continue
+		}
+
+		time++
+		disc[root], low[root] = time, time
+		parent[root] = -1
+
+		var rootChildren int
+		stack := []dfsFrame{{node: root}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			u := top.node
+
+			if top.next < len(graph.Adj[u]) {
+				v := graph.Adj[u][top.next]
+				top.next++
+
+				switch {
+				// Descend into unvisited neighbors.
+				case disc[v] == 0:
+					time++
+					disc[v], low[v] = time, time
+					parent[v] = u
+					if u == root {
+						rootChildren++
+					}
+					stack = append(stack, dfsFrame{node: v})
+
+				// Any other visited neighbor than the parent is
+				// reachable through a back edge. Parallel
+				// channels to the parent don't matter, as they
+				// can't lower low below the discovery time of
+				// the parent.
+				case v != parent[u]:
+					low[u] = min(low[u], disc[v])
+				}
+
+				continue
+			}
+
+			// All neighbors of u are visited, so we propagate its
+			// low value to its parent. The parent is an
+			// articulation point if no node in the subtree of u
+			// reaches above the parent.
+			stack = stack[:len(stack)-1]
+
+			p := parent[u]
+			if p < 0 {
+				continue
+			}
+
+			low[p] = min(low[p], low[u])
+			if p != root && low[u] >= disc[p] {
+				isArticulation[p] = true
+			}
+		}
+
+		// The root is an articulation point if it has more than one
+		// child in the depth first search tree.
+		if rootChildren > 1 {
+			isArticulation[root] = true
+		}
+	}
+
+	var points []NodeID
+	for u, ok := range isArticulation {
+		if ok {
+			points = append(points, graph.Nodes[u])
+		}
+	}
+
+	return points
+}
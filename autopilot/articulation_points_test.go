@@ -0,0 +1,55 @@
+package autopilot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSimpleGraph creates a simple graph with the given number of nodes
+// and undirected edges.
+func newTestSimpleGraph(numNodes int, edges [][2]int) *SimpleGraph {
+	graph := &SimpleGraph{
+		Nodes: make([]NodeID, numNodes),
+		Adj:   make([][]int, numNodes),
+	}
+	for i := range graph.Nodes {
+		graph.Nodes[i] = NodeID{2, byte(i)}
+	}
+	for _, edge := range edges {
+		u, v := edge[0], edge[1]
+		graph.Adj[u] = append(graph.Adj[u], v)
+		graph.Adj[v] = append(graph.Adj[v], u)
+	}
+
+	return graph
+}
+
+// TestArticulationPoints tests that the nodes whose removal disconnects the
+// graph are found.
+func TestArticulationPoints(t *testing.T) {
+	t.Parallel()
+
+	// The graph is a path 0-1-2-3 that ends in the triangle 3-4-5, with a
+	// parallel channel between 0 and 1. A separate component 6-7 has no
+	// articulation point.
+	graph := newTestSimpleGraph(8, [][2]int{
+		{0, 1}, {0, 1}, {1, 2}, {2, 3}, {3, 4}, {4, 5}, {5, 3}, {6, 7},
+	})
+
+	require.Equal(
+		t, []NodeID{graph.Nodes[1], graph.Nodes[2], graph.Nodes[3]},
+		graph.ArticulationPoints(),
+	)
+
+	// A cycle doesn't have any articulation point.
+	graph = newTestSimpleGraph(4, [][2]int{
+		{0, 1}, {1, 2}, {2, 3}, {3, 0},
+	})
+	require.Empty(t, graph.ArticulationPoints())
+
+	// The center of a star is the only articulation point, also if it is
+	// the root of the search.
+	graph = newTestSimpleGraph(4, [][2]int{{0, 1}, {0, 2}, {0, 3}})
+	require.Equal(t, []NodeID{graph.Nodes[0]}, graph.ArticulationPoints())
+}
@@ -1,10 +1,15 @@
 package autopilot
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 )
 
+// ErrRefreshAborted is returned if the refresh of a metric is aborted before
+// it completed.
+var ErrRefreshAborted = errors.New("metric refresh aborted")
+
 // stack is a simple int stack to help with readability of Brandes'
 // betweenness centrality implementation below.
 type stack struct {
@@ -174,6 +179,16 @@ func (bc *BetweennessCentrality) Refresh(graph ChannelGraph) error {
 		return err
 	}
 
+	return bc.RefreshGraph(cache, nil, nil)
+}
+
+// RefreshGraph recalculates and stores centrality values for an already
+// simplified graph. If progress is non-nil, it is called with the fraction of
+// the work that is done. The calculation is aborted with ErrRefreshAborted
+// once the quit channel is closed.
+func (bc *BetweennessCentrality) RefreshGraph(cache *SimpleGraph,
+	progress func(float64), quit <-chan struct{}) error {
+
 	var wg sync.WaitGroup
 	work := make(chan int)
 	partials := make(chan []float64, bc.workers)
@@ -202,7 +217,19 @@ func (bc *BetweennessCentrality) Refresh(graph ChannelGraph) error {
 	// Distribute work amongst workers.
 	// Should be fair when the graph is sufficiently large.
 	for node := range cache.Nodes {
-		work <- node
+		select {
+		case work <- node:
+
+		case <-quit:
+			close(work)
+			wg.Wait()
+
+			return ErrRefreshAborted
+		}
+
+		if progress != nil {
+			progress(float64(node+1) / float64(len(cache.Nodes)))
+		}
 	}
 
 	close(work)
@@ -0,0 +1,133 @@
+package autopilot
+
+import "math"
+
+const (
+	// eigenvectorMaxIterations is the maximum number of power iterations
+	// used to approximate the eigenvector centrality.
+	eigenvectorMaxIterations = 100
+
+	// eigenvectorTolerance is the per node tolerance below which the power
+	// iteration is considered to have converged.
+	eigenvectorTolerance = 1e-6
+)
+
+// EigenvectorCentrality is a NodeMetric that calculates the eigenvector
+// centrality of each node. A node has a high eigenvector centrality if it is
+// connected to many nodes that have a high eigenvector centrality themselves.
+// In contrast to the degree, this measures how well a node is embedded into
+// the well connected core of the network. Parallel channels between two nodes
+// increase the weight of their connection.
+type EigenvectorCentrality struct {
+	// centrality stores the centrality values of each node, with the
+	// vector of all values normalized to unit length.
+	centrality map[NodeID]float64
+
+	// max is the maximum centrality in the graph.
+	max float64
+}
+
+// NewEigenvectorCentralityMetric creates a new EigenvectorCentrality
+// instance.
+func NewEigenvectorCentralityMetric() *EigenvectorCentrality {
+	return &EigenvectorCentrality{}
+}
+
+// Name returns the name of the metric.
+func (ec *EigenvectorCentrality) Name() string {
+	return "eigenvector_centrality"
+}
+
+// Refresh recalculates and stores centrality values.
+func (ec *EigenvectorCentrality) Refresh(graph ChannelGraph) error {
+	cache, err := NewSimpleGraph(graph)
+	if err != nil {
+		return err
+	}
+
+	return ec.RefreshGraph(cache, nil, nil)
+}
+
+// RefreshGraph recalculates and stores centrality values for an already
+// simplified graph using power iteration. If progress is non-nil, it is
+// called with the fraction of the maximum number of iterations that is done.
+// The calculation is aborted with ErrRefreshAborted once the quit channel is
+// closed.
+func (ec *EigenvectorCentrality) RefreshGraph(cache *SimpleGraph,
+	progress func(float64), quit <-chan struct{}) error {
+
+	n := len(cache.Nodes)
+
+	// Start with a uniform vector. Iterating with the adjacency matrix
+	// plus the identity matrix doesn't change the eigenvectors, but
+	// guarantees convergence for bipartite graphs.
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 1.0 / float64(n)
+	}
+
+	for i := 0; i < eigenvectorMaxIterations; i++ {
+		select {
+		case <-quit:
+			return ErrRefreshAborted
+		default:
+		}
+
+		next := make([]float64, n)
+		copy(next, x)
+		for u, neighbors := range cache.Adj {
+			for _, v := range neighbors {
+				next[v] += x[u]
+			}
+		}
+
+		var norm float64
+		for _, v := range next {
+			norm += v * v
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			break
+		}
+
+		var diff float64
+		for j := range next {
+			next[j] /= norm
+			diff += math.Abs(next[j] - x[j])
+		}
+		x = next
+
+		if progress != nil {
+			progress(float64(i+1) / eigenvectorMaxIterations)
+		}
+
+		if diff < float64(n)*eigenvectorTolerance {
+			break
+		}
+	}
+
+	ec.max = 0
+	ec.centrality = make(map[NodeID]float64, n)
+	for u, value := range x {
+		ec.centrality[cache.Nodes[u]] = value
+		if value > ec.max {
+			ec.max = value
+		}
+	}
+
+	return nil
+}
+
+// GetMetric returns the current centrality values for each node indexed by
+// node id. Normalized values are relative to the most central node.
+func (ec *EigenvectorCentrality) GetMetric(normalize bool) map[NodeID]float64 {
+	centrality := make(map[NodeID]float64, len(ec.centrality))
+	for k, v := range ec.centrality {
+		if normalize && ec.max > 0 {
+			v /= ec.max
+		}
+		centrality[k] = v
+	}
+
+	return centrality
+}
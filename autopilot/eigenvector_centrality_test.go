@@ -0,0 +1,50 @@
+package autopilot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEigenvectorCentrality tests the eigenvector centrality of a star graph.
+func TestEigenvectorCentrality(t *testing.T) {
+	t.Parallel()
+
+	graph := newTestSimpleGraph(4, [][2]int{{0, 1}, {0, 2}, {0, 3}})
+
+	var lastProgress float64
+	metric := NewEigenvectorCentralityMetric()
+	err := metric.RefreshGraph(graph, func(progress float64) {
+		require.Greater(t, progress, lastProgress)
+		lastProgress = progress
+	}, nil)
+	require.NoError(t, err)
+
+	// The center is the most central node and all leaves are equally
+	// central. For a star with n leaves, the centrality of a leaf is
+	// 1/sqrt(n) of the centrality of the center.
+	centrality := metric.GetMetric(true)
+	require.Len(t, centrality, 4)
+	require.InDelta(t, 1, centrality[graph.Nodes[0]], 1e-4)
+	for _, node := range graph.Nodes[1:] {
+		require.InDelta(t, 0.57735, centrality[node], 1e-4)
+	}
+
+	// The raw values form a unit vector.
+	var sum float64
+	for _, v := range metric.GetMetric(false) {
+		sum += v * v
+	}
+	require.InDelta(t, 1, sum, 1e-9)
+
+	// An empty graph results in an empty metric.
+	err = metric.RefreshGraph(newTestSimpleGraph(0, nil), nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, metric.GetMetric(true))
+
+	// A closed quit channel aborts the calculation.
+	quit := make(chan struct{})
+	close(quit)
+	err = metric.RefreshGraph(graph, nil, quit)
+	require.ErrorIs(t, err, ErrRefreshAborted)
+}
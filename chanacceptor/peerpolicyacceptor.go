@@ -0,0 +1,136 @@
+package chanacceptor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// PeerPolicyStore persists the channel policies of individual peers.
+type PeerPolicyStore interface {
+	// WritePeerChannelPolicy stores the channel policy of a peer,
+	// overwriting any existing one.
+	WritePeerChannelPolicy(route.Vertex, *channeldb.PeerChannelPolicy) error
+
+	// DeletePeerChannelPolicy removes the channel policy of a peer.
+	DeletePeerChannelPolicy(route.Vertex) error
+
+	// FetchPeerChannelPolicies returns the channel policies of all peers.
+	FetchPeerChannelPolicies() (
+		map[route.Vertex]*channeldb.PeerChannelPolicy, error)
+}
+
+// PeerPolicyAcceptor is a ChannelAcceptor that applies per-peer policies on
+// zero-conf and scid-alias channels. Zero-conf channels are only accepted
+// from peers whose policy allows them, unless another acceptor accepts them.
+type PeerPolicyAcceptor struct {
+	store PeerPolicyStore
+
+	// policies is an in-memory copy of the policies in the store.
+	policies map[route.Vertex]*channeldb.PeerChannelPolicy
+	mtx      sync.RWMutex
+}
+
+// NewPeerPolicyAcceptor initializes a PeerPolicyAcceptor with the policies
+// currently persisted in the given store.
+func NewPeerPolicyAcceptor(store PeerPolicyStore) (*PeerPolicyAcceptor,
+	error) {
+
+	policies, err := store.FetchPeerChannelPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PeerPolicyAcceptor{
+		store:    store,
+		policies: policies,
+	}, nil
+}
+
+// SetPolicy persists the channel policy of the given peer and applies it to
+// all subsequent channel requests. A policy that neither allows zero-conf
+// nor requires scid-alias channels removes any existing policy of the peer.
+func (p *PeerPolicyAcceptor) SetPolicy(peer route.Vertex,
+	policy channeldb.PeerChannelPolicy) error {
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if policy == (channeldb.PeerChannelPolicy{}) {
+		if err := p.store.DeletePeerChannelPolicy(peer); err != nil {
+			return err
+		}
+		delete(p.policies, peer)
+
+		return nil
+	}
+
+	if err := p.store.WritePeerChannelPolicy(peer, &policy); err != nil {
+		return err
+	}
+	p.policies[peer] = &policy
+
+	return nil
+}
+
+// Policies returns a copy of the channel policies of all peers.
+func (p *PeerPolicyAcceptor) Policies() (
+	policies map[route.Vertex]channeldb.PeerChannelPolicy) {
+
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	policies = make(
+		map[route.Vertex]channeldb.PeerChannelPolicy, len(p.policies),
+	)
+	for peer, policy := range p.policies {
+		policies[peer] = *policy
+	}
+
+	return policies
+}
+
+// Accept rejects channels of peers that require scid-alias channels if the
+// channel doesn't negotiate the scid-alias channel type, and accepts
+// zero-conf channels of peers whose policy allows them.
+//
+// NOTE: Part of the ChannelAcceptor interface.
+func (p *PeerPolicyAcceptor) Accept(
+	req *ChannelAcceptRequest) *ChannelAcceptResponse {
+
+	p.mtx.RLock()
+	policy, ok := p.policies[route.NewVertex(req.Node)]
+	p.mtx.RUnlock()
+
+	// Peers without a policy are left to the other acceptors.
+	if !ok {
+		return NewChannelAcceptResponse(
+			true, nil, nil, 0, 0, 0, 0, 0, 0, false,
+		)
+	}
+
+	if policy.RequireScidAlias && !hasChannelTypeBit(
+		req.OpenChanMsg, lnwire.ScidAliasRequired,
+	) {
+
+		return NewChannelAcceptResponse(
+			false, fmt.Errorf("only scid-alias channels are "+
+				"accepted"), nil, 0, 0, 0, 0, 0, 0, false,
+		)
+	}
+
+	zeroConf := policy.AllowZeroConf && hasChannelTypeBit(
+		req.OpenChanMsg, lnwire.ZeroConfRequired,
+	)
+
+	return NewChannelAcceptResponse(
+		true, nil, nil, 0, 0, 0, 0, 0, 0, zeroConf,
+	)
+}
+
+// A compile-time constraint to ensure PeerPolicyAcceptor implements the
+// ChannelAcceptor interface.
+var _ ChannelAcceptor = (*PeerPolicyAcceptor)(nil)
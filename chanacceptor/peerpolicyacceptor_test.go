@@ -0,0 +1,135 @@
+package chanacceptor
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPeerPolicyStore is an in-memory PeerPolicyStore.
+type mockPeerPolicyStore struct {
+	policies map[route.Vertex]*channeldb.PeerChannelPolicy
+}
+
+func (m *mockPeerPolicyStore) WritePeerChannelPolicy(peer route.Vertex,
+	policy *channeldb.PeerChannelPolicy) error {
+
+	m.policies[peer] = policy
+	return nil
+}
+
+func (m *mockPeerPolicyStore) DeletePeerChannelPolicy(peer route.Vertex) error {
+	delete(m.policies, peer)
+	return nil
+}
+
+func (m *mockPeerPolicyStore) FetchPeerChannelPolicies() (
+	map[route.Vertex]*channeldb.PeerChannelPolicy, error) {
+
+	policies := make(map[route.Vertex]*channeldb.PeerChannelPolicy)
+	for peer, policy := range m.policies {
+		policies[peer] = policy
+	}
+
+	return policies, nil
+}
+
+// TestPeerPolicyAcceptor tests that the PeerPolicyAcceptor applies the
+// zero-conf and scid-alias policies of individual peers, and that policies
+// are persisted in its store.
+func TestPeerPolicyAcceptor(t *testing.T) {
+	t.Parallel()
+
+	newKey := func() *btcec.PublicKey {
+		priv, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+
+		return priv.PubKey()
+	}
+	alice, bob := newKey(), newKey()
+
+	store := &mockPeerPolicyStore{
+		policies: map[route.Vertex]*channeldb.PeerChannelPolicy{
+			route.NewVertex(alice): {AllowZeroConf: true},
+		},
+	}
+	acceptor, err := NewPeerPolicyAcceptor(store)
+	require.NoError(t, err)
+
+	channelType := func(bits ...lnwire.FeatureBit) *lnwire.ChannelType {
+		chanType := lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+			bits...,
+		))
+
+		return &chanType
+	}
+	scidAlias := channelType(
+		lnwire.AnchorsZeroFeeHtlcTxRequired, lnwire.ScidAliasRequired,
+	)
+	zeroConf := channelType(
+		lnwire.AnchorsZeroFeeHtlcTxRequired, lnwire.ZeroConfRequired,
+		lnwire.ScidAliasRequired,
+	)
+
+	accept := func(node *btcec.PublicKey,
+		chanType *lnwire.ChannelType) *ChannelAcceptResponse {
+
+		return acceptor.Accept(&ChannelAcceptRequest{
+			Node: node,
+			OpenChanMsg: &lnwire.OpenChannel{
+				ChannelType: chanType,
+			},
+		})
+	}
+
+	// The policy loaded from the store allows Alice to open zero-conf
+	// channels.
+	resp := accept(alice, zeroConf)
+	require.False(t, resp.RejectChannel())
+	require.True(t, resp.ZeroConf)
+
+	// Bob has no policy, so his channels are accepted, but not as
+	// zero-conf channels.
+	resp = accept(bob, zeroConf)
+	require.False(t, resp.RejectChannel())
+	require.False(t, resp.ZeroConf)
+
+	// Require Bob to open scid-alias channels.
+	err = acceptor.SetPolicy(
+		route.NewVertex(bob), channeldb.PeerChannelPolicy{
+			RequireScidAlias: true,
+		},
+	)
+	require.NoError(t, err)
+
+	resp = accept(bob, nil)
+	require.True(t, resp.RejectChannel())
+
+	resp = accept(bob, scidAlias)
+	require.False(t, resp.RejectChannel())
+	require.False(t, resp.ZeroConf)
+
+	require.Equal(t, map[route.Vertex]channeldb.PeerChannelPolicy{
+		route.NewVertex(alice): {AllowZeroConf: true},
+		route.NewVertex(bob):   {RequireScidAlias: true},
+	}, acceptor.Policies())
+
+	// Removing Alice's policy means her channels are no longer accepted as
+	// zero-conf channels. The policy is removed from the store as well.
+	err = acceptor.SetPolicy(
+		route.NewVertex(alice), channeldb.PeerChannelPolicy{},
+	)
+	require.NoError(t, err)
+
+	resp = accept(alice, zeroConf)
+	require.False(t, resp.RejectChannel())
+	require.False(t, resp.ZeroConf)
+
+	require.Equal(t, map[route.Vertex]*channeldb.PeerChannelPolicy{
+		route.NewVertex(bob): {RequireScidAlias: true},
+	}, store.policies)
+}
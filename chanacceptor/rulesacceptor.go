@@ -0,0 +1,165 @@
+package chanacceptor
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// RulesConfig holds the set of static rules that the RulesAcceptor evaluates
+// inbound channel requests against. The zero value of each field disables the
+// respective rule.
+type RulesConfig struct {
+	// MinChanSize is the smallest channel size that is accepted.
+	MinChanSize btcutil.Amount
+
+	// AllowedPeers is the set of peers that may open channels to us. If
+	// empty, channels from all peers not in DeniedPeers are accepted.
+	AllowedPeers map[route.Vertex]struct{}
+
+	// DeniedPeers is the set of peers whose channels are always rejected.
+	DeniedPeers map[route.Vertex]struct{}
+
+	// MaxChansPerPeer is the maximum number of channels, both pending and
+	// open, that we maintain with a single peer.
+	MaxChansPerPeer int
+
+	// RequireAnchors indicates that only channels which explicitly
+	// negotiate a channel type with anchor outputs are accepted.
+	RequireAnchors bool
+
+	// ZeroConfPeers is the set of peers that may open zero-conf channels
+	// to us. If non-empty, zero-conf channels of all other peers are
+	// rejected. Otherwise, the decision is left to the other acceptors.
+	ZeroConfPeers map[route.Vertex]struct{}
+
+	// NumChannels returns the number of pending and open channels that we
+	// currently have with the given peer. It must be set if
+	// MaxChansPerPeer is non-zero.
+	NumChannels func(*btcec.PublicKey) (int, error)
+}
+
+// RulesAcceptor is a ChannelAcceptor that evaluates inbound channel requests
+// against a static set of rules. It allows node operators to enforce common
+// channel acceptance policies without running an external RPC acceptor.
+type RulesAcceptor struct {
+	cfg *RulesConfig
+}
+
+// NewRulesAcceptor initializes a RulesAcceptor with the given rules.
+func NewRulesAcceptor(cfg *RulesConfig) *RulesAcceptor {
+	return &RulesAcceptor{
+		cfg: cfg,
+	}
+}
+
+// Accept evaluates the channel request against all configured rules and
+// rejects it as soon as one of them isn't satisfied. Zero-conf channels from
+// the configured zero-conf peers are accepted as such.
+//
+// NOTE: Part of the ChannelAcceptor interface.
+func (r *RulesAcceptor) Accept(
+	req *ChannelAcceptRequest) *ChannelAcceptResponse {
+
+	zeroConf, err := r.checkRules(req)
+	if err != nil {
+		log.Infof("Rejecting channel %x from peer %x: %v",
+			req.OpenChanMsg.PendingChannelID[:],
+			req.Node.SerializeCompressed(), err)
+
+		return NewChannelAcceptResponse(
+			false, err, nil, 0, 0, 0, 0, 0, 0, false,
+		)
+	}
+
+	return NewChannelAcceptResponse(
+		true, nil, nil, 0, 0, 0, 0, 0, 0, zeroConf,
+	)
+}
+
+// checkRules returns a non-nil error if the channel request violates any of
+// the configured rules. Otherwise, it returns whether the channel should be
+// accepted as a zero-conf channel.
+func (r *RulesAcceptor) checkRules(req *ChannelAcceptRequest) (bool, error) {
+	peer := route.NewVertex(req.Node)
+
+	// We don't disclose to the peer that it is on our deny list, or
+	// missing from our allow list.
+	if _, ok := r.cfg.DeniedPeers[peer]; ok {
+		return false, errChannelRejected
+	}
+	if len(r.cfg.AllowedPeers) > 0 {
+		if _, ok := r.cfg.AllowedPeers[peer]; !ok {
+			return false, errChannelRejected
+		}
+	}
+
+	amt := req.OpenChanMsg.FundingAmount
+	if amt < r.cfg.MinChanSize {
+		return false, fmt.Errorf("channel size %v is below the "+
+			"minimum of %v", amt, r.cfg.MinChanSize)
+	}
+
+	if r.cfg.RequireAnchors && !hasChannelTypeBit(
+		req.OpenChanMsg, lnwire.AnchorsZeroFeeHtlcTxRequired,
+		lnwire.SimpleTaprootChannelsRequiredStaging,
+	) {
+
+		return false, fmt.Errorf("only anchor channels are accepted")
+	}
+
+	var zeroConf bool
+	if len(r.cfg.ZeroConfPeers) > 0 && hasChannelTypeBit(
+		req.OpenChanMsg, lnwire.ZeroConfRequired,
+	) {
+
+		if _, ok := r.cfg.ZeroConfPeers[peer]; !ok {
+			return false, fmt.Errorf("zero-conf channels are not " +
+				"accepted")
+		}
+		zeroConf = true
+	}
+
+	if r.cfg.MaxChansPerPeer > 0 {
+		numChans, err := r.cfg.NumChannels(req.Node)
+		if err != nil {
+			log.Errorf("Unable to fetch channels of peer %x: %v",
+				req.Node.SerializeCompressed(), err)
+
+			return false, errChannelRejected
+		}
+
+		if numChans >= r.cfg.MaxChansPerPeer {
+			return false, fmt.Errorf("maximum number of %v "+
+				"channels reached", r.cfg.MaxChansPerPeer)
+		}
+	}
+
+	return zeroConf, nil
+}
+
+// hasChannelTypeBit returns true if the open channel message explicitly
+// negotiates a channel type that has any of the given feature bits set.
+func hasChannelTypeBit(msg *lnwire.OpenChannel,
+	bits ...lnwire.FeatureBit) bool {
+
+	if msg.ChannelType == nil {
+		return false
+	}
+
+	channelFeatures := lnwire.RawFeatureVector(*msg.ChannelType)
+	for _, bit := range bits {
+		if channelFeatures.IsSet(bit) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A compile-time constraint to ensure RulesAcceptor implements the
+// ChannelAcceptor interface.
+var _ ChannelAcceptor = (*RulesAcceptor)(nil)
@@ -0,0 +1,250 @@
+package chanacceptor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRulesAcceptor tests that the RulesAcceptor rejects channel requests
+// that violate any of its rules and accepts all others.
+func TestRulesAcceptor(t *testing.T) {
+	t.Parallel()
+
+	newKey := func() *btcec.PublicKey {
+		priv, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+
+		return priv.PubKey()
+	}
+	alice, bob := newKey(), newKey()
+
+	peerSet := func(keys ...*btcec.PublicKey) map[route.Vertex]struct{} {
+		set := make(map[route.Vertex]struct{})
+		for _, key := range keys {
+			set[route.NewVertex(key)] = struct{}{}
+		}
+
+		return set
+	}
+
+	channelType := func(bits ...lnwire.FeatureBit) *lnwire.ChannelType {
+		chanType := lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+			bits...,
+		))
+
+		return &chanType
+	}
+	anchors := channelType(lnwire.AnchorsZeroFeeHtlcTxRequired)
+	zeroConf := channelType(
+		lnwire.AnchorsZeroFeeHtlcTxRequired, lnwire.ZeroConfRequired,
+		lnwire.ScidAliasRequired,
+	)
+
+	numChans := func(n int, err error) func(*btcec.PublicKey) (int,
+		error) {
+
+		return func(*btcec.PublicKey) (int, error) {
+			return n, err
+		}
+	}
+
+	tests := []struct {
+		name         string
+		cfg          RulesConfig
+		node         *btcec.PublicKey
+		amt          btcutil.Amount
+		chanType     *lnwire.ChannelType
+		reject       bool
+		wantZeroConf bool
+	}{
+		{
+			name: "no rules",
+			node: alice,
+			amt:  1,
+		},
+		{
+			name: "denied peer",
+			cfg: RulesConfig{
+				DeniedPeers: peerSet(alice),
+			},
+			node:   alice,
+			amt:    100_000,
+			reject: true,
+		},
+		{
+			name: "peer not denied",
+			cfg: RulesConfig{
+				DeniedPeers: peerSet(bob),
+			},
+			node: alice,
+			amt:  100_000,
+		},
+		{
+			name: "allowed peer",
+			cfg: RulesConfig{
+				AllowedPeers: peerSet(alice),
+			},
+			node: alice,
+			amt:  100_000,
+		},
+		{
+			name: "peer not allowed",
+			cfg: RulesConfig{
+				AllowedPeers: peerSet(bob),
+			},
+			node:   alice,
+			amt:    100_000,
+			reject: true,
+		},
+		{
+			name: "channel too small",
+			cfg: RulesConfig{
+				MinChanSize: 100_000,
+			},
+			node:   alice,
+			amt:    99_999,
+			reject: true,
+		},
+		{
+			name: "minimum channel size",
+			cfg: RulesConfig{
+				MinChanSize: 100_000,
+			},
+			node: alice,
+			amt:  100_000,
+		},
+		{
+			name: "anchors required implicit channel type",
+			cfg: RulesConfig{
+				RequireAnchors: true,
+			},
+			node:   alice,
+			amt:    100_000,
+			reject: true,
+		},
+		{
+			name: "anchors required static remote key",
+			cfg: RulesConfig{
+				RequireAnchors: true,
+			},
+			node: alice,
+			amt:  100_000,
+			chanType: channelType(
+				lnwire.StaticRemoteKeyRequired,
+			),
+			reject: true,
+		},
+		{
+			name: "anchors required anchor channel",
+			cfg: RulesConfig{
+				RequireAnchors: true,
+			},
+			node:     alice,
+			amt:      100_000,
+			chanType: anchors,
+		},
+		{
+			name: "anchors required taproot channel",
+			cfg: RulesConfig{
+				RequireAnchors: true,
+			},
+			node: alice,
+			amt:  100_000,
+			chanType: channelType(
+				lnwire.SimpleTaprootChannelsRequiredStaging,
+			),
+		},
+		{
+			name:     "zero-conf without zero-conf peers",
+			node:     alice,
+			amt:      100_000,
+			chanType: zeroConf,
+		},
+		{
+			name: "zero-conf from other peer",
+			cfg: RulesConfig{
+				ZeroConfPeers: peerSet(bob),
+			},
+			node:     alice,
+			amt:      100_000,
+			chanType: zeroConf,
+			reject:   true,
+		},
+		{
+			name: "zero-conf from zero-conf peer",
+			cfg: RulesConfig{
+				ZeroConfPeers: peerSet(alice),
+			},
+			node:         alice,
+			amt:          100_000,
+			chanType:     zeroConf,
+			wantZeroConf: true,
+		},
+		{
+			name: "regular channel from zero-conf peer",
+			cfg: RulesConfig{
+				ZeroConfPeers: peerSet(alice),
+			},
+			node:     alice,
+			amt:      100_000,
+			chanType: anchors,
+		},
+		{
+			name: "below max channels per peer",
+			cfg: RulesConfig{
+				MaxChansPerPeer: 2,
+				NumChannels:     numChans(1, nil),
+			},
+			node: alice,
+			amt:  100_000,
+		},
+		{
+			name: "max channels per peer reached",
+			cfg: RulesConfig{
+				MaxChansPerPeer: 2,
+				NumChannels:     numChans(2, nil),
+			},
+			node:   alice,
+			amt:    100_000,
+			reject: true,
+		},
+		{
+			name: "channel count unavailable",
+			cfg: RulesConfig{
+				MaxChansPerPeer: 2,
+				NumChannels: numChans(
+					0, errors.New("db error"),
+				),
+			},
+			node:   alice,
+			amt:    100_000,
+			reject: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			acceptor := NewRulesAcceptor(&test.cfg)
+			resp := acceptor.Accept(&ChannelAcceptRequest{
+				Node: test.node,
+				OpenChanMsg: &lnwire.OpenChannel{
+					FundingAmount: test.amt,
+					ChannelType:   test.chanType,
+				},
+			})
+
+			require.Equal(t, test.reject, resp.RejectChannel())
+			require.Equal(t, test.wantZeroConf, resp.ZeroConf)
+		})
+	}
+}
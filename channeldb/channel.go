@@ -25,6 +25,7 @@ import (
 	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/shachain"
 	"github.com/lightningnetwork/lnd/tlv"
@@ -245,8 +246,54 @@ const (
 	// A tlv type definition used to serialize and deserialize the
 	// Memo for the channel channel.
 	channelMemoType tlv.Type = 5
+
+	// A tlv type definition used to serialize and deserialize the
+	// confirmation target of the commitment fee policy.
+	commitFeeConfTargetType tlv.Type = 6
+
+	// A tlv type definition used to serialize and deserialize the minimum
+	// fee rate of the commitment fee policy.
+	commitFeeMinRateType tlv.Type = 7
+
+	// A tlv type definition used to serialize and deserialize the maximum
+	// fee rate of the commitment fee policy.
+	commitFeeMaxRateType tlv.Type = 8
 )
 
+// CommitFeePolicy describes how the commitment fee rate of a channel is
+// managed if we're the initiator of the channel and therefore pay the
+// commitment fee. The zero value leaves the fee rate to the global defaults.
+type CommitFeePolicy struct {
+	// ConfTarget is the confirmation target in blocks that is used to
+	// estimate the commitment fee rate. If zero, the default target is
+	// used.
+	ConfTarget uint32
+
+	// MinFeeRate is the fee rate that the commitment fee rate must not
+	// drop below. For anchor channels it is capped at the maximum anchor
+	// commitment fee rate, as the fee of their commitment transactions
+	// can be bumped when they are broadcast. If zero, only the minimum
+	// relay fee rate is enforced.
+	MinFeeRate chainfee.SatPerKWeight
+
+	// MaxFeeRate is the fee rate that the commitment fee rate must not
+	// exceed. For anchor channels it replaces the global maximum anchor
+	// commitment fee rate. If zero, the global maximums apply.
+	MaxFeeRate chainfee.SatPerKWeight
+}
+
+// Validate checks that the minimum fee rate of the policy doesn't exceed its
+// maximum fee rate.
+func (p *CommitFeePolicy) Validate() error {
+	if p.MaxFeeRate != 0 && p.MinFeeRate > p.MaxFeeRate {
+		return fmt.Errorf("minimum commitment fee rate %v exceeds "+
+			"maximum commitment fee rate %v", p.MinFeeRate,
+			p.MaxFeeRate)
+	}
+
+	return nil
+}
+
 // indexStatus is an enum-like type that describes what state the
 // outpoint is in. Currently only two possible values.
 type indexStatus uint8
@@ -856,6 +903,10 @@ type OpenChannel struct {
 	// channel that will be useful to our future selves.
 	Memo []byte
 
+	// commitFeePolicy is the policy that governs the commitment fee rate
+	// of the channel if we're the initiator.
+	commitFeePolicy CommitFeePolicy
+
 	// TODO(roasbeef): eww
 	Db *ChannelStateDB
 
@@ -905,6 +956,15 @@ func (c *OpenChannel) ZeroConfRealScid() lnwire.ShortChannelID {
 	return c.confirmedScid
 }
 
+// CommitFeePolicy returns the policy that governs the commitment fee rate of
+// the channel.
+func (c *OpenChannel) CommitFeePolicy() CommitFeePolicy {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.commitFeePolicy
+}
+
 // ZeroConfConfirmed returns whether the zero-conf channel has confirmed. This
 // should only be called if IsZeroConf returns true.
 func (c *OpenChannel) ZeroConfConfirmed() bool {
@@ -1314,6 +1374,43 @@ func (c *OpenChannel) MarkRealScid(realScid lnwire.ShortChannelID) error {
 	return nil
 }
 
+// SetCommitFeePolicy sets the policy that governs the commitment fee rate of
+// the channel in-memory and in the database.
+func (c *OpenChannel) SetCommitFeePolicy(policy CommitFeePolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if err := kvdb.Update(c.Db.backend, func(tx kvdb.RwTx) error {
+		chanBucket, err := fetchChanBucketRw(
+			tx, c.IdentityPub, &c.FundingOutpoint, c.ChainHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		channel, err := fetchOpenChannel(
+			chanBucket, &c.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		channel.commitFeePolicy = policy
+
+		return putOpenChannel(chanBucket, channel)
+	}, func() {}); err != nil {
+		return err
+	}
+
+	c.commitFeePolicy = policy
+
+	return nil
+}
+
 // MarkScidAliasNegotiated adds ScidAliasFeatureBit to ChanType in-memory and
 // in the database.
 func (c *OpenChannel) MarkScidAliasNegotiated() error {
@@ -4007,9 +4104,11 @@ func putChanInfo(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
 		return err
 	}
 
-	// Convert balance fields into uint64.
+	// Convert balance and fee rate fields into uint64.
 	localBalance := uint64(channel.InitialLocalBalance)
 	remoteBalance := uint64(channel.InitialRemoteBalance)
+	commitFeeMinRate := uint64(channel.commitFeePolicy.MinFeeRate)
+	commitFeeMaxRate := uint64(channel.commitFeePolicy.MaxFeeRate)
 
 	// Create the tlv stream.
 	tlvStream, err := tlv.NewStream(
@@ -4026,6 +4125,16 @@ func putChanInfo(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
 		),
 		MakeScidRecord(realScidType, &channel.confirmedScid),
 		tlv.MakePrimitiveRecord(channelMemoType, &channel.Memo),
+		tlv.MakePrimitiveRecord(
+			commitFeeConfTargetType,
+			&channel.commitFeePolicy.ConfTarget,
+		),
+		tlv.MakePrimitiveRecord(
+			commitFeeMinRateType, &commitFeeMinRate,
+		),
+		tlv.MakePrimitiveRecord(
+			commitFeeMaxRateType, &commitFeeMaxRate,
+		),
 	)
 	if err != nil {
 		return err
@@ -4221,11 +4330,14 @@ func fetchChanInfo(chanBucket kvdb.RBucket, channel *OpenChannel) error {
 		}
 	}
 
-	// Create balance fields in uint64, and Memo field as byte slice.
+	// Create balance and fee rate fields in uint64, and Memo field as byte
+	// slice.
 	var (
-		localBalance  uint64
-		remoteBalance uint64
-		memo          []byte
+		localBalance     uint64
+		remoteBalance    uint64
+		memo             []byte
+		commitFeeMinRate uint64
+		commitFeeMaxRate uint64
 	)
 
 	// Create the tlv stream.
@@ -4243,6 +4355,16 @@ func fetchChanInfo(chanBucket kvdb.RBucket, channel *OpenChannel) error {
 		),
 		MakeScidRecord(realScidType, &channel.confirmedScid),
 		tlv.MakePrimitiveRecord(channelMemoType, &memo),
+		tlv.MakePrimitiveRecord(
+			commitFeeConfTargetType,
+			&channel.commitFeePolicy.ConfTarget,
+		),
+		tlv.MakePrimitiveRecord(
+			commitFeeMinRateType, &commitFeeMinRate,
+		),
+		tlv.MakePrimitiveRecord(
+			commitFeeMaxRateType, &commitFeeMaxRate,
+		),
 	)
 	if err != nil {
 		return err
@@ -4252,9 +4374,15 @@ func fetchChanInfo(chanBucket kvdb.RBucket, channel *OpenChannel) error {
 		return err
 	}
 
-	// Attach the balance fields.
+	// Attach the balance and fee rate fields.
 	channel.InitialLocalBalance = lnwire.MilliSatoshi(localBalance)
 	channel.InitialRemoteBalance = lnwire.MilliSatoshi(remoteBalance)
+	channel.commitFeePolicy.MinFeeRate = chainfee.SatPerKWeight(
+		commitFeeMinRate,
+	)
+	channel.commitFeePolicy.MaxFeeRate = chainfee.SatPerKWeight(
+		commitFeeMaxRate,
+	)
 
 	// Attach the memo field if non-empty.
 	if len(memo) > 0 {
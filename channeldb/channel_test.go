@@ -1219,6 +1219,44 @@ func testShutdownInfo(t *testing.T, locallyInitiated bool) {
 	})
 }
 
+// TestCommitFeePolicy asserts that the commitment fee policy of a channel is
+// persisted and that invalid policies are rejected.
+func TestCommitFeePolicy(t *testing.T) {
+	t.Parallel()
+
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to make test database")
+
+	cdb := fullDB.ChannelStateDB()
+
+	channel := createTestChannel(t, cdb, openChannelOption())
+
+	// Channels don't have a commitment fee policy by default.
+	require.Equal(t, CommitFeePolicy{}, channel.CommitFeePolicy())
+
+	policy := CommitFeePolicy{
+		ConfTarget: 6,
+		MinFeeRate: 1000,
+		MaxFeeRate: 5000,
+	}
+	require.NoError(t, channel.SetCommitFeePolicy(policy))
+	require.Equal(t, policy, channel.CommitFeePolicy())
+
+	// The policy is loaded from the database too.
+	dbChannel, err := cdb.FetchChannel(nil, channel.FundingOutpoint)
+	require.NoError(t, err)
+	require.Equal(t, policy, dbChannel.CommitFeePolicy())
+
+	// A minimum fee rate above the maximum fee rate is rejected and leaves
+	// the policy untouched.
+	err = channel.SetCommitFeePolicy(CommitFeePolicy{
+		MinFeeRate: 5000,
+		MaxFeeRate: 1000,
+	})
+	require.Error(t, err)
+	require.Equal(t, policy, channel.CommitFeePolicy())
+}
+
 // TestRefresh asserts that Refresh updates the in-memory state of another
 // OpenChannel to reflect a preceding call to MarkOpen on a different
 // OpenChannel.
@@ -26,6 +26,8 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb/migration29"
 	"github.com/lightningnetwork/lnd/channeldb/migration30"
 	"github.com/lightningnetwork/lnd/channeldb/migration31"
+	"github.com/lightningnetwork/lnd/channeldb/migration32"
+	"github.com/lightningnetwork/lnd/channeldb/migration33"
 	"github.com/lightningnetwork/lnd/channeldb/migration_01_to_11"
 	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/invoices"
@@ -286,6 +288,18 @@ var (
 			number:    31,
 			migration: migration31.DeleteLastPublishedTxTLB,
 		},
+		{
+			// Creates and populates the index of payments by
+			// their creation time.
+			number:    32,
+			migration: migration32.MigratePaymentCreationIndex,
+		},
+		{
+			// Creates and populates the index of open invoices by
+			// their expiry.
+			number:    33,
+			migration: migration33.MigrateInvoiceExpiryIndex,
+		},
 	}
 
 	// optionalVersions stores all optional migrations that are applied
@@ -402,6 +416,7 @@ func CreateWithBackend(backend kvdb.Backend,
 	chanDB.graph, err = NewChannelGraph(
 		backend, opts.RejectCacheSize, opts.ChannelCacheSize,
 		opts.BatchCommitInterval, opts.PreAllocCacheNumNodes,
+		opts.GraphSnapshotSize,
 		opts.UseGraphCache, opts.NoMigration,
 	)
 	if err != nil {
@@ -440,6 +455,7 @@ var dbTopLevelBuckets = [][]byte{
 	payAddrIndexBucket,
 	setIDIndexBucket,
 	paymentsIndexBucket,
+	paymentsCreationIndexBucket,
 	peersBucket,
 	nodeInfoBucket,
 	metaBucket,
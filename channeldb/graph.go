@@ -124,6 +124,15 @@ var (
 	// edge's participants.
 	zombieBucket = []byte("zombie-index")
 
+	// zombieEdgeInfoBucket is a sub-bucket of the main edgeBucket bucket
+	// that holds the static information of the channels that were removed
+	// from the graph as zombies. It allows restoring a channel when its
+	// zombie status is lifted manually. Each entry exists within the
+	// bucket as follows:
+	//
+	// maps: chanID -> edge info
+	zombieEdgeInfoBucket = []byte("zombie-edge-info")
+
 	// disabledEdgePolicyBucket is a sub-bucket of the main edgeBucket bucket
 	// responsible for maintaining an index of disabled edge policies. Each
 	// entry exists within the bucket as follows:
@@ -191,8 +200,9 @@ type ChannelGraph struct {
 // NewChannelGraph allocates a new ChannelGraph backed by a DB instance. The
 // returned instance has its own unique reject cache and channel cache.
 func NewChannelGraph(db kvdb.Backend, rejectCacheSize, chanCacheSize int,
-	batchCommitInterval time.Duration, preAllocCacheNumNodes int,
-	useGraphCache, noMigrations bool) (*ChannelGraph, error) {
+	batchCommitInterval time.Duration, preAllocCacheNumNodes,
+	graphSnapshotSize int, useGraphCache, noMigrations bool) (*ChannelGraph,
+	error) {
 
 	if !noMigrations {
 		if err := initChannelGraph(db); err != nil {
@@ -215,7 +225,9 @@ func NewChannelGraph(db kvdb.Backend, rejectCacheSize, chanCacheSize int,
 	// The graph cache can be turned off (e.g. for mobile users) for a
 	// speed/memory usage tradeoff.
 	if useGraphCache {
-		g.graphCache = NewGraphCache(preAllocCacheNumNodes)
+		g.graphCache = NewGraphCache(
+			preAllocCacheNumNodes, graphSnapshotSize,
+		)
 		startTime := time.Now()
 		log.Debugf("Populating in-memory channel graph, this might " +
 			"take a while...")
@@ -458,6 +470,69 @@ func (c *ChannelGraph) ForEachChannel(cb func(*models.ChannelEdgeInfo,
 	}, func() {})
 }
 
+// ForEachChannelAfter iterates through the channel edges stored within the
+// graph in the order of their channel ids, starting with the first edge after
+// the given channel id, or with the first edge if it is nil. Unlike
+// ForEachChannel, the policies are only loaded for the visited edges, which
+// makes it suitable to read a part of the graph. If the callback returns an
+// error, then the transaction is aborted and the iteration stops early.
+//
+// NOTE: If an edge can't be found, or wasn't advertised, then a nil pointer
+// for that particular channel edge routing policy will be passed into the
+// callback.
+func (c *ChannelGraph) ForEachChannelAfter(after *uint64,
+	cb func(*models.ChannelEdgeInfo, *models.ChannelEdgePolicy,
+		*models.ChannelEdgePolicy) error) error {
+
+	return kvdb.View(c.db, func(tx kvdb.RTx) error {
+		edges := tx.ReadBucket(edgeBucket)
+		if edges == nil {
+			return ErrGraphNoEdgesFound
+		}
+		edgeIndex := edges.NestedReadBucket(edgeIndexBucket)
+		if edgeIndex == nil {
+			return ErrGraphNoEdgesFound
+		}
+
+		cursor := edgeIndex.ReadCursor()
+
+		var k, edgeInfoBytes []byte
+		if after == nil {
+			k, edgeInfoBytes = cursor.First()
+		} else {
+			var afterKey [8]byte
+			byteOrder.PutUint64(afterKey[:], *after)
+
+			k, edgeInfoBytes = cursor.Seek(afterKey[:])
+			if bytes.Equal(k, afterKey[:]) {
+				k, edgeInfoBytes = cursor.Next()
+			}
+		}
+
+		for ; k != nil; k, edgeInfoBytes = cursor.Next() {
+			info, err := deserializeChanEdgeInfo(
+				bytes.NewReader(edgeInfoBytes),
+			)
+			if err != nil {
+				return err
+			}
+
+			policy1, policy2, err := fetchChanEdgePolicies(
+				edgeIndex, edges, k,
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := cb(&info, policy1, policy2); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
+
 // ForEachNodeDirectedChannel iterates through all channels of a given node,
 // executing the passed callback on the directed edge representing the channel
 // and its incoming policy. If the callback returns an error, then the iteration
@@ -703,6 +778,56 @@ func (c *ChannelGraph) ForEachNode(
 	return kvdb.View(c.db, traversal, func() {})
 }
 
+// ForEachNodeAfter iterates through the stored vertices/nodes in the graph in
+// the order of their public keys, starting with the first node after the given
+// public key, or with the first node if it is nil. If the callback returns an
+// error, then the transaction is aborted and the iteration stops early.
+func (c *ChannelGraph) ForEachNodeAfter(after []byte,
+	cb func(kvdb.RTx, *LightningNode) error) error {
+
+	traversal := func(tx kvdb.RTx) error {
+		nodes := tx.ReadBucket(nodeBucket)
+		if nodes == nil {
+			return ErrGraphNotFound
+		}
+
+		cursor := nodes.ReadCursor()
+
+		var pubKey, nodeBytes []byte
+		if after == nil {
+			pubKey, nodeBytes = cursor.First()
+		} else {
+			pubKey, nodeBytes = cursor.Seek(after)
+			if bytes.Equal(pubKey, after) {
+				pubKey, nodeBytes = cursor.Next()
+			}
+		}
+
+		for ; pubKey != nil; pubKey, nodeBytes = cursor.Next() {
+			// Skip the source key and the nested index buckets,
+			// which don't hold raw node information.
+			if bytes.Equal(pubKey, sourceKey) || len(pubKey) != 33 {
+				continue
+			}
+
+			node, err := deserializeLightningNode(
+				bytes.NewReader(nodeBytes),
+			)
+			if err != nil {
+				return err
+			}
+
+			if err := cb(tx, &node); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return kvdb.View(c.db, traversal, func() {})
+}
+
 // ForEachNodeCacheable iterates through all the stored vertices/nodes in the
 // graph, executing the passed callback with each node encountered. If the
 // callback returns an error, then the transaction is aborted and the iteration
@@ -2594,6 +2719,18 @@ func (c *ChannelGraph) delChannelEdgeUnsafe(edges, edgeIndex, chanIndex,
 		nodeKey1, nodeKey2 = makeZombiePubkeys(&edgeInfo, edge1, edge2)
 	}
 
+	// Keep the static information of the channel, so that it can be
+	// restored if the channel is resurrected.
+	zombieInfos, err := edges.CreateBucketIfNotExists(zombieEdgeInfoBucket)
+	if err != nil {
+		return err
+	}
+	var chanKey [8]byte
+	copy(chanKey[:], chanID)
+	if err := putChanEdgeInfo(zombieInfos, &edgeInfo, chanKey); err != nil {
+		return err
+	}
+
 	return markEdgeZombie(
 		zombieIndex, byteOrder.Uint64(chanID), nodeKey1, nodeKey2,
 	)
@@ -3684,6 +3821,16 @@ func (c *ChannelGraph) markEdgeLiveUnsafe(tx kvdb.RwTx, chanID uint64) error {
 			return ErrZombieEdgeNotFound
 		}
 
+		// The stored information of the channel is no longer needed,
+		// as the channel is either restored by the caller or announced
+		// again by our peers.
+		zombieInfos := edges.NestedReadWriteBucket(zombieEdgeInfoBucket)
+		if zombieInfos != nil {
+			if err := zombieInfos.Delete(k[:]); err != nil {
+				return err
+			}
+		}
+
 		return zombieIndex.Delete(k[:])
 	}
 
@@ -3721,6 +3868,45 @@ func (c *ChannelGraph) markEdgeLiveUnsafe(tx kvdb.RwTx, chanID uint64) error {
 	return nil
 }
 
+// FetchZombieEdgeInfo returns the static information of a channel that was
+// removed from the graph as a zombie. ErrEdgeNotFound is returned if no
+// information is stored for the channel, which is the case for channels that
+// were marked as zombies while they were still part of the graph, or before
+// this information was stored.
+func (c *ChannelGraph) FetchZombieEdgeInfo(
+	chanID uint64) (*models.ChannelEdgeInfo, error) {
+
+	var info *models.ChannelEdgeInfo
+	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
+		edges := tx.ReadBucket(edgeBucket)
+		if edges == nil {
+			return ErrEdgeNotFound
+		}
+		zombieInfos := edges.NestedReadBucket(zombieEdgeInfoBucket)
+		if zombieInfos == nil {
+			return ErrEdgeNotFound
+		}
+
+		var k [8]byte
+		byteOrder.PutUint64(k[:], chanID)
+
+		edgeInfo, err := fetchChanEdgeInfo(zombieInfos, k[:])
+		if err != nil {
+			return err
+		}
+		info = &edgeInfo
+
+		return nil
+	}, func() {
+		info = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
 // IsZombieEdge returns whether the edge is considered zombie. If it is a
 // zombie, then the two node public keys corresponding to this edge are also
 // returned.
@@ -3775,6 +3961,16 @@ func isZombieEdge(zombieIndex kvdb.RBucket,
 	return true, pubKey1, pubKey2
 }
 
+// GraphCacheMetrics returns metrics about the in-memory graph cache, or nil if
+// the graph cache is disabled.
+func (c *ChannelGraph) GraphCacheMetrics() *GraphCacheMetrics {
+	if c.graphCache == nil {
+		return nil
+	}
+
+	return c.graphCache.Metrics()
+}
+
 // NumZombies returns the current number of zombie channels in the graph.
 func (c *ChannelGraph) NumZombies() (uint64, error) {
 	var numZombies uint64
@@ -3802,6 +3998,35 @@ func (c *ChannelGraph) NumZombies() (uint64, error) {
 	return numZombies, nil
 }
 
+// ForEachZombieEdge calls the callback for every channel in the zombie index
+// with its channel ID and the public keys of its two nodes.
+func (c *ChannelGraph) ForEachZombieEdge(cb func(chanID uint64, pubKey1,
+	pubKey2 [33]byte) error) error {
+
+	return kvdb.View(c.db, func(tx kvdb.RTx) error {
+		edges := tx.ReadBucket(edgeBucket)
+		if edges == nil {
+			return nil
+		}
+		zombieIndex := edges.NestedReadBucket(zombieBucket)
+		if zombieIndex == nil {
+			return nil
+		}
+
+		return zombieIndex.ForEach(func(k, v []byte) error {
+			if len(k) != 8 || len(v) != 66 {
+				return nil
+			}
+
+			var pubKey1, pubKey2 [33]byte
+			copy(pubKey1[:], v[:33])
+			copy(pubKey2[:], v[33:])
+
+			return cb(byteOrder.Uint64(k), pubKey1, pubKey2)
+		})
+	}, func() {})
+}
+
 func putLightningNode(nodeBucket kvdb.RwBucket, aliasBucket kvdb.RwBucket, // nolint:dupl
 	updateIndex kvdb.RwBucket, node *LightningNode) error {
 
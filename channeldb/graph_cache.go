@@ -3,6 +3,8 @@ package channeldb
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/lightningnetwork/lnd/channeldb/models"
@@ -83,17 +85,78 @@ func (c *DirectedChannel) DeepCopy() *DirectedChannel {
 	return &channelCopy
 }
 
+// GraphCacheMetrics holds metrics about the usage and the size of the graph
+// cache.
+type GraphCacheMetrics struct {
+	// NumNodes is the number of nodes that have channels in the cache.
+	NumNodes int
+
+	// NumChannels is the number of directed channels in the cache.
+	NumChannels int
+
+	// Hits is the number of channel lookups that were served from an
+	// existing node snapshot.
+	Hits uint64
+
+	// Misses is the number of channel lookups that required a node
+	// snapshot to be built.
+	Misses uint64
+
+	// NumSnapshotChannels is the number of channels held in node
+	// snapshots.
+	NumSnapshotChannels int
+
+	// SizeBytes is an estimate of the memory used by the cache, including
+	// the node snapshots.
+	SizeBytes uint64
+}
+
+// HitRate returns the fraction of channel lookups that were served from an
+// existing node snapshot.
+func (m *GraphCacheMetrics) HitRate() float64 {
+	total := m.Hits + m.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(m.Hits) / float64(total)
+}
+
 // GraphCache is a type that holds a minimal set of information of the public
 // channel graph that can be used for pathfinding.
 type GraphCache struct {
 	nodeChannels map[route.Vertex]map[uint64]*DirectedChannel
 	nodeFeatures map[route.Vertex]*lnwire.FeatureVector
 
+	// nodeSnapshots holds a copy of the channels of a node as handed out
+	// to path finding. A snapshot is built on first use and is never
+	// modified afterwards. Graph updates only drop the snapshots of the
+	// nodes they affect, so that a burst of channel updates doesn't force
+	// a copy of every channel that path finding looks at.
+	nodeSnapshots map[route.Vertex][]*DirectedChannel
+
+	// numSnapshotChannels is the number of channels held in all node
+	// snapshots.
+	numSnapshotChannels int
+
+	// maxSnapshotChannels is the maximum number of channels that the node
+	// snapshots may hold. As every snapshot is a full copy of a node's
+	// channels, snapshots are evicted at random once the limit is
+	// reached. If it is zero, no snapshots are kept and the channels are
+	// copied on every lookup.
+	maxSnapshotChannels int
+
+	// hits and misses count the channel lookups that were served from an
+	// existing snapshot and that required a new one respectively.
+	hits   atomic.Uint64
+	misses atomic.Uint64
+
 	mtx sync.RWMutex
 }
 
-// NewGraphCache creates a new graphCache.
-func NewGraphCache(preAllocNumNodes int) *GraphCache {
+// NewGraphCache creates a new graphCache. The node snapshots that are handed
+// out to path finding hold at most maxSnapshotChannels channels.
+func NewGraphCache(preAllocNumNodes, maxSnapshotChannels int) *GraphCache {
 	return &GraphCache{
 		nodeChannels: make(
 			map[route.Vertex]map[uint64]*DirectedChannel,
@@ -106,6 +169,8 @@ func NewGraphCache(preAllocNumNodes int) *GraphCache {
 			map[route.Vertex]*lnwire.FeatureVector,
 			preAllocNumNodes,
 		),
+		nodeSnapshots:       make(map[route.Vertex][]*DirectedChannel),
+		maxSnapshotChannels: maxSnapshotChannels,
 	}
 }
 
@@ -114,13 +179,117 @@ func (c *GraphCache) Stats() string {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
 
-	numChannels := 0
-	for node := range c.nodeChannels {
-		numChannels += len(c.nodeChannels[node])
-	}
+	metrics := c.metrics()
+
 	return fmt.Sprintf("num_node_features=%d, num_nodes=%d, "+
-		"num_channels=%d", len(c.nodeFeatures), len(c.nodeChannels),
-		numChannels)
+		"num_channels=%d, num_snapshot_channels=%d, hit_rate=%.2f, "+
+		"size_bytes=%d", len(c.nodeFeatures), metrics.NumNodes,
+		metrics.NumChannels, metrics.NumSnapshotChannels,
+		metrics.HitRate(), metrics.SizeBytes)
+}
+
+// Metrics returns metrics about the usage and the size of the cache.
+func (c *GraphCache) Metrics() *GraphCacheMetrics {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.metrics()
+}
+
+// metrics returns metrics about the usage and the size of the cache.
+//
+// NOTE: The caller must hold the cache mutex.
+func (c *GraphCache) metrics() *GraphCacheMetrics {
+	var (
+		channelSize = uint64(unsafe.Sizeof(DirectedChannel{}))
+		policySize  = uint64(unsafe.Sizeof(models.CachedEdgePolicy{}))
+		vertexSize  = uint64(unsafe.Sizeof(route.Vertex{}))
+		pointerSize = uint64(unsafe.Sizeof(uintptr(0)))
+		sliceSize   = uint64(unsafe.Sizeof([]*DirectedChannel{}))
+		chanIDSize  = uint64(unsafe.Sizeof(uint64(0)))
+
+		// closureSize is the size of the ToNodePubKey callback that
+		// is shared by the policies of a snapshot. It holds a
+		// function pointer and the captured vertex.
+		closureSize = pointerSize + vertexSize
+	)
+
+	// entrySize estimates the memory used by a channel, including the
+	// pointer that references it.
+	entrySize := func(channel *DirectedChannel) uint64 {
+		size := pointerSize + channelSize
+		if channel.InPolicy != nil {
+			size += policySize
+		}
+
+		return size
+	}
+
+	metrics := &GraphCacheMetrics{
+		NumNodes:            len(c.nodeChannels),
+		NumSnapshotChannels: c.numSnapshotChannels,
+		Hits:                c.hits.Load(),
+		Misses:              c.misses.Load(),
+	}
+
+	// Every node references a map of its channels, which are keyed by
+	// their channel ID.
+	for _, channels := range c.nodeChannels {
+		metrics.NumChannels += len(channels)
+		metrics.SizeBytes += vertexSize + pointerSize
+
+		for _, channel := range channels {
+			metrics.SizeBytes += chanIDSize + entrySize(channel)
+		}
+	}
+
+	// Every snapshot is a slice of full copies of the node's channels.
+	for _, snapshot := range c.nodeSnapshots {
+		metrics.SizeBytes += vertexSize + sliceSize + closureSize
+
+		for _, channel := range snapshot {
+			metrics.SizeBytes += entrySize(channel)
+		}
+	}
+
+	metrics.SizeBytes += uint64(len(c.nodeFeatures)) * (vertexSize +
+		pointerSize)
+
+	return metrics
+}
+
+// invalidateSnapshot drops the channel snapshot of a node, so that it is
+// rebuilt with the latest information on its next use.
+//
+// NOTE: The caller must hold the cache mutex.
+func (c *GraphCache) invalidateSnapshot(node route.Vertex) {
+	c.numSnapshotChannels -= len(c.nodeSnapshots[node])
+	delete(c.nodeSnapshots, node)
+}
+
+// storeSnapshot keeps the snapshot of a node's channels for later lookups.
+// Snapshots of other nodes are evicted at random until the snapshots hold no
+// more than the maximum number of channels.
+//
+// NOTE: The caller must hold the cache mutex.
+func (c *GraphCache) storeSnapshot(node route.Vertex,
+	snapshot []*DirectedChannel) {
+
+	if len(snapshot) > c.maxSnapshotChannels {
+		return
+	}
+
+	maxChannels := c.maxSnapshotChannels - len(snapshot)
+	for other := range c.nodeSnapshots {
+		if c.numSnapshotChannels <= maxChannels {
+			break
+		}
+
+		c.invalidateSnapshot(other)
+	}
+
+	c.nodeSnapshots[node] = snapshot
+	c.numSnapshotChannels += len(snapshot)
 }
 
 // AddNodeFeatures adds a graph node and its features to the cache.
@@ -133,6 +302,10 @@ func (c *GraphCache) AddNodeFeatures(node GraphCacheNode) {
 	// lock anyway.
 	c.mtx.Lock()
 	c.nodeFeatures[nodePubKey] = node.Features()
+
+	// The snapshot of the node carries its features in the incoming
+	// policies.
+	c.invalidateSnapshot(nodePubKey)
 	c.mtx.Unlock()
 }
 
@@ -214,6 +387,7 @@ func (c *GraphCache) updateOrAddEdge(node route.Vertex, edge *DirectedChannel) {
 	}
 
 	c.nodeChannels[node][edge.ChannelID] = edge
+	c.invalidateSnapshot(node)
 }
 
 // UpdatePolicy updates a single policy on both the from and to node. The order
@@ -244,6 +418,10 @@ func (c *GraphCache) UpdatePolicy(policy *models.ChannelEdgePolicy, fromNode,
 			return
 		}
 
+		// Only the snapshot of the node that holds the channel is
+		// affected by the update.
+		c.invalidateSnapshot(nodeKey)
+
 		// Edge 1 is defined as the policy for the direction of node1 to
 		// node2.
 		switch {
@@ -285,6 +463,7 @@ func (c *GraphCache) RemoveNode(node route.Vertex) {
 
 	// Then remove our whole node completely.
 	delete(c.nodeChannels, node)
+	c.invalidateSnapshot(node)
 }
 
 // RemoveChannel removes a single channel between two nodes.
@@ -303,7 +482,12 @@ func (c *GraphCache) removeChannelIfFound(node route.Vertex, chanID uint64) {
 		return
 	}
 
+	if _, ok := c.nodeChannels[node][chanID]; !ok {
+		return
+	}
+
 	delete(c.nodeChannels[node], chanID)
+	c.invalidateSnapshot(node)
 }
 
 // UpdateChannel updates the channel edge information for a specific edge. We
@@ -325,21 +509,65 @@ func (c *GraphCache) UpdateChannel(info *models.ChannelEdgeInfo) {
 		// known.
 		channel.Capacity = info.Capacity
 		channel.OtherNode = info.NodeKey2Bytes
+		c.invalidateSnapshot(info.NodeKey1Bytes)
 	}
 
 	channel, ok = c.nodeChannels[info.NodeKey2Bytes][info.ChannelID]
 	if ok {
 		channel.Capacity = info.Capacity
 		channel.OtherNode = info.NodeKey1Bytes
+		c.invalidateSnapshot(info.NodeKey2Bytes)
 	}
 }
 
-// getChannels returns a copy of the passed node's channels or nil if there
-// isn't any.
+// getChannels returns a snapshot of the passed node's channels or nil if there
+// isn't any. The snapshot is shared between callers and MUST NOT be modified.
 func (c *GraphCache) getChannels(node route.Vertex) []*DirectedChannel {
+	// Without snapshots, every lookup copies the channels, which only
+	// requires the read lock.
+	if c.maxSnapshotChannels == 0 {
+		c.misses.Add(1)
+
+		c.mtx.RLock()
+		defer c.mtx.RUnlock()
+
+		return c.copyChannels(node)
+	}
+
 	c.mtx.RLock()
-	defer c.mtx.RUnlock()
+	snapshot, ok := c.nodeSnapshots[node]
+	c.mtx.RUnlock()
+
+	if ok {
+		c.hits.Add(1)
+
+		return snapshot
+	}
+
+	c.misses.Add(1)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// The snapshot may have been built by another caller while we were
+	// waiting for the lock.
+	if snapshot, ok := c.nodeSnapshots[node]; ok {
+		return snapshot
+	}
+
+	snapshot = c.copyChannels(node)
+	if snapshot != nil {
+		c.storeSnapshot(node, snapshot)
+	}
+
+	return snapshot
+}
 
+// copyChannels returns a copy of the passed node's channels or nil if there
+// isn't any.
+//
+// NOTE: The caller must hold the cache mutex.
+func (c *GraphCache) copyChannels(node route.Vertex) []*DirectedChannel {
 	channels, ok := c.nodeChannels[node]
 	if !ok {
 		return nil
@@ -363,10 +591,9 @@ func (c *GraphCache) getChannels(node route.Vertex) []*DirectedChannel {
 	i := 0
 	channelsCopy := make([]*DirectedChannel, len(channels))
 	for _, channel := range channels {
-		// We need to copy the channel and policy to avoid it being
-		// updated in the cache if the path finding algorithm sets
-		// fields on it (currently only the ToNodeFeatures of the
-		// policy).
+		// We need to copy the channel and policy, because the cache
+		// updates its channels in place while the snapshot may still
+		// be in use by path finding.
 		channelCopy := channel.DeepCopy()
 		if channelCopy.InPolicy != nil {
 			channelCopy.InPolicy.ToNodePubKey = toNodeCallback
@@ -384,7 +611,7 @@ func (c *GraphCache) getChannels(node route.Vertex) []*DirectedChannel {
 func (c *GraphCache) ForEachChannel(node route.Vertex,
 	cb func(channel *DirectedChannel) error) error {
 
-	// Obtain a copy of the node's channels. We need do this in order to
+	// Obtain a snapshot of the node's channels. We need do this in order to
 	// avoid deadlocks caused by interaction with the graph cache, channel
 	// state and the graph database from multiple goroutines. This snapshot
 	// is only used for path finding where being stale is acceptable since
@@ -98,7 +98,7 @@ func TestGraphCacheAddNode(t *testing.T) {
 			outPolicies: []*models.ChannelEdgePolicy{outPolicy1},
 			inPolicies:  []*models.ChannelEdgePolicy{inPolicy1},
 		}
-		cache := NewGraphCache(10)
+		cache := NewGraphCache(10, DefaultGraphSnapshotSize)
 		require.NoError(t, cache.AddNode(nil, node))
 
 		var fromChannels, toChannels []*DirectedChannel
@@ -171,3 +171,148 @@ func assertCachedPolicyEqual(t *testing.T, original *models.ChannelEdgePolicy,
 		t, route.Vertex(original.ToNode), cached.ToNodePubKey(),
 	)
 }
+
+// TestGraphCacheSnapshots tests that the channel snapshots of nodes are reused
+// and only rebuilt for the nodes that are affected by a graph update.
+func TestGraphCacheSnapshots(t *testing.T) {
+	t.Parallel()
+
+	pubKey3 := route.Vertex{3}
+
+	cache := NewGraphCache(10, DefaultGraphSnapshotSize)
+	cache.AddChannel(&models.ChannelEdgeInfo{
+		ChannelID:     1,
+		NodeKey1Bytes: pubKey1,
+		NodeKey2Bytes: pubKey2,
+		Capacity:      500,
+	}, nil, nil)
+	cache.AddChannel(&models.ChannelEdgeInfo{
+		ChannelID:     2,
+		NodeKey1Bytes: pubKey2,
+		NodeKey2Bytes: pubKey3,
+		Capacity:      700,
+	}, nil, nil)
+
+	channels := func(node route.Vertex) []*DirectedChannel {
+		var result []*DirectedChannel
+		cb := func(c *DirectedChannel) error {
+			result = append(result, c)
+			return nil
+		}
+		err := cache.ForEachChannel(node, cb)
+		require.NoError(t, err)
+
+		return result
+	}
+
+	// The first lookup of each node builds its snapshot, the second one
+	// reuses it.
+	node1Before := channels(pubKey1)
+	node3Before := channels(pubKey3)
+	require.Len(t, node1Before, 1)
+	require.Len(t, node3Before, 1)
+	require.Nil(t, node1Before[0].InPolicy)
+
+	channels(pubKey1)
+	channels(pubKey3)
+
+	metrics := cache.Metrics()
+	require.EqualValues(t, 2, metrics.Hits)
+	require.EqualValues(t, 2, metrics.Misses)
+	require.Equal(t, 3, metrics.NumNodes)
+	require.Equal(t, 4, metrics.NumChannels)
+	require.NotZero(t, metrics.SizeBytes)
+
+	// A policy update of channel 1 only affects the snapshots of the
+	// nodes 1 and 2.
+	cache.UpdatePolicy(&models.ChannelEdgePolicy{
+		ChannelID:   1,
+		FeeBaseMSat: 10,
+		ToNode:      pubKey1,
+	}, pubKey2, pubKey1, false)
+
+	node1After := channels(pubKey1)
+	require.NotNil(t, node1After[0].InPolicy)
+	require.EqualValues(t, 10, node1After[0].InPolicy.FeeBaseMSat)
+
+	// The snapshot that was handed out before remains unchanged.
+	require.Nil(t, node1Before[0].InPolicy)
+
+	node3After := channels(pubKey3)
+	require.Same(t, node3Before[0], node3After[0])
+
+	metrics = cache.Metrics()
+	require.EqualValues(t, 3, metrics.Hits)
+	require.EqualValues(t, 3, metrics.Misses)
+	require.InDelta(t, 0.5, metrics.HitRate(), 0.001)
+
+	// Removing the channel drops it from the snapshots of both nodes.
+	cache.RemoveChannel(pubKey1, pubKey2, 1)
+	require.Empty(t, channels(pubKey1))
+	require.Len(t, channels(pubKey2), 1)
+}
+
+// TestGraphCacheSnapshotLimit tests that the node snapshots never hold more
+// than the configured number of channels and that they can be turned off.
+func TestGraphCacheSnapshotLimit(t *testing.T) {
+	t.Parallel()
+
+	pubKey3 := route.Vertex{3}
+
+	newCache := func(maxSnapshotChannels int) *GraphCache {
+		cache := NewGraphCache(10, maxSnapshotChannels)
+		cache.AddChannel(&models.ChannelEdgeInfo{
+			ChannelID:     1,
+			NodeKey1Bytes: pubKey1,
+			NodeKey2Bytes: pubKey2,
+			Capacity:      500,
+		}, nil, nil)
+		cache.AddChannel(&models.ChannelEdgeInfo{
+			ChannelID:     2,
+			NodeKey1Bytes: pubKey2,
+			NodeKey2Bytes: pubKey3,
+			Capacity:      700,
+		}, nil, nil)
+
+		return cache
+	}
+	lookup := func(cache *GraphCache, node route.Vertex) {
+		err := cache.ForEachChannel(node, func(*DirectedChannel) error {
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	// The snapshots of the nodes 1 and 3 fill up the cache.
+	cache := newCache(2)
+	lookup(cache, pubKey1)
+	lookup(cache, pubKey3)
+	require.Equal(t, 2, cache.Metrics().NumSnapshotChannels)
+
+	// Node 2 has two channels, so both other snapshots are evicted.
+	lookup(cache, pubKey2)
+	lookup(cache, pubKey2)
+	metrics := cache.Metrics()
+	require.Equal(t, 2, metrics.NumSnapshotChannels)
+	require.EqualValues(t, 1, metrics.Hits)
+	require.EqualValues(t, 3, metrics.Misses)
+
+	// Node 1 needs a new snapshot, which evicts the one of node 2.
+	lookup(cache, pubKey1)
+	metrics = cache.Metrics()
+	require.Equal(t, 1, metrics.NumSnapshotChannels)
+	require.EqualValues(t, 4, metrics.Misses)
+
+	// Invalidating a snapshot releases its channels.
+	cache.RemoveChannel(pubKey1, pubKey2, 1)
+	require.Zero(t, cache.Metrics().NumSnapshotChannels)
+
+	// Without snapshots, every lookup copies the channels.
+	cache = newCache(0)
+	lookup(cache, pubKey2)
+	lookup(cache, pubKey2)
+	metrics = cache.Metrics()
+	require.Zero(t, metrics.NumSnapshotChannels)
+	require.Zero(t, metrics.Hits)
+	require.EqualValues(t, 2, metrics.Misses)
+}
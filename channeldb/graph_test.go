@@ -12,6 +12,7 @@ import (
 	"net"
 	"reflect"
 	"runtime"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -70,6 +71,7 @@ func MakeTestGraph(t testing.TB, modifiers ...OptionModifier) (*ChannelGraph, er
 	graph, err := NewChannelGraph(
 		backend, opts.RejectCacheSize, opts.ChannelCacheSize,
 		opts.BatchCommitInterval, opts.PreAllocCacheNumNodes,
+		opts.GraphSnapshotSize,
 		true, false,
 	)
 	if err != nil {
@@ -1091,6 +1093,89 @@ func TestGraphTraversal(t *testing.T) {
 	require.Equal(t, numChannels, numNodeChans)
 }
 
+// TestGraphTraversalAfter tests that the nodes and channels of the graph can
+// be traversed in order, starting after a given node or channel.
+func TestGraphTraversalAfter(t *testing.T) {
+	t.Parallel()
+
+	graph, err := MakeTestGraph(t)
+	require.NoError(t, err, "unable to make test database")
+
+	const numNodes = 10
+	const numChannels = 3
+	chanIndex, nodeList := fillTestGraph(t, graph, numNodes, numChannels)
+
+	// Collect the nodes and channels after the given cursors.
+	nodesAfter := func(after []byte) [][]byte {
+		var pubKeys [][]byte
+		err := graph.ForEachNodeAfter(after, func(_ kvdb.RTx,
+			node *LightningNode) error {
+
+			pubKeys = append(pubKeys, node.PubKeyBytes[:])
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		return pubKeys
+	}
+	chansAfter := func(after *uint64) []uint64 {
+		var chanIDs []uint64
+		err := graph.ForEachChannelAfter(after, func(
+			info *models.ChannelEdgeInfo, p1,
+			p2 *models.ChannelEdgePolicy) error {
+
+			require.NotNil(t, p1)
+			require.NotNil(t, p2)
+			chanIDs = append(chanIDs, info.ChannelID)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		return chanIDs
+	}
+
+	// Without a cursor, all nodes and channels are visited in order.
+	pubKeys := make([][]byte, 0, len(nodeList))
+	for _, node := range nodeList {
+		pubKeys = append(pubKeys, node.PubKeyBytes[:])
+	}
+	sort.Slice(pubKeys, func(i, j int) bool {
+		return bytes.Compare(pubKeys[i], pubKeys[j]) < 0
+	})
+	require.Equal(t, pubKeys, nodesAfter(nil))
+
+	chanIDs := make([]uint64, 0, len(chanIndex))
+	for chanID := range chanIndex {
+		chanIDs = append(chanIDs, chanID)
+	}
+	sort.Slice(chanIDs, func(i, j int) bool {
+		return chanIDs[i] < chanIDs[j]
+	})
+	require.Equal(t, chanIDs, chansAfter(nil))
+
+	// A cursor that is part of the graph is skipped.
+	require.Equal(t, pubKeys[4:], nodesAfter(pubKeys[3]))
+	require.Equal(t, chanIDs[4:], chansAfter(&chanIDs[3]))
+	require.Empty(t, nodesAfter(pubKeys[len(pubKeys)-1]))
+	require.Empty(t, chansAfter(&chanIDs[len(chanIDs)-1]))
+
+	// A cursor that isn't part of the graph (anymore) continues with the
+	// next node or channel.
+	cursor := append(append([]byte{}, pubKeys[3]...), 0)
+	require.Equal(t, pubKeys[4:], nodesAfter(cursor))
+
+	for i := 0; i < len(chanIDs)-1; i++ {
+		chanCursor := chanIDs[i] + 1
+		if chanCursor == chanIDs[i+1] {
+			continue
+		}
+
+		require.Equal(t, chanIDs[i+1:], chansAfter(&chanCursor))
+	}
+}
+
 // TestGraphTraversalCacheable tests that the memory optimized node traversal is
 // working correctly.
 func TestGraphTraversalCacheable(t *testing.T) {
@@ -3534,10 +3619,37 @@ func TestGraphZombieIndex(t *testing.T) {
 	require.Equal(t, node2.PubKeyBytes, pubKey2)
 	assertNumZombies(t, graph, 1)
 
+	// The zombie should also be returned when iterating over the zombie
+	// index.
+	var zombies []uint64
+	err = graph.ForEachZombieEdge(func(chanID uint64, pubKey1,
+		pubKey2 [33]byte) error {
+
+		require.Equal(t, node1.PubKeyBytes, pubKey1)
+		require.Equal(t, node2.PubKeyBytes, pubKey2)
+		zombies = append(zombies, chanID)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{edge.ChannelID}, zombies)
+
+	// The static information of the channel is kept, so that it can be
+	// restored.
+	zombieInfo, err := graph.FetchZombieEdgeInfo(edge.ChannelID)
+	require.NoError(t, err)
+	require.Equal(t, edge.ChannelPoint, zombieInfo.ChannelPoint)
+	require.Equal(t, edge.BitcoinKey1Bytes, zombieInfo.BitcoinKey1Bytes)
+	require.Equal(t, edge.AuthProof, zombieInfo.AuthProof)
+
 	// Similarly, if we mark the same edge as live, we should no longer see
 	// it within the index.
 	require.NoError(t, graph.MarkEdgeLive(edge.ChannelID))
 
+	// The stored information is removed along with the zombie.
+	_, err = graph.FetchZombieEdgeInfo(edge.ChannelID)
+	require.ErrorIs(t, err, ErrEdgeNotFound)
+
 	// Attempting to mark the edge as live again now that it is no longer
 	// in the zombie index should fail.
 	require.ErrorIs(
@@ -4003,6 +4115,7 @@ func TestGraphLoading(t *testing.T) {
 	graph, err := NewChannelGraph(
 		backend, opts.RejectCacheSize, opts.ChannelCacheSize,
 		opts.BatchCommitInterval, opts.PreAllocCacheNumNodes,
+		opts.GraphSnapshotSize,
 		true, false,
 	)
 	require.NoError(t, err)
@@ -4017,6 +4130,7 @@ func TestGraphLoading(t *testing.T) {
 	graphReloaded, err := NewChannelGraph(
 		backend, opts.RejectCacheSize, opts.ChannelCacheSize,
 		opts.BatchCommitInterval, opts.PreAllocCacheNumNodes,
+		opts.GraphSnapshotSize,
 		true, false,
 	)
 	require.NoError(t, err)
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/lightningnetwork/lnd/channeldb/models"
@@ -80,6 +81,14 @@ var (
 	//
 	//   settleIndexNo => invoiceKey
 	settleIndexBucket = []byte("invoice-settle-index")
+
+	// invoiceExpiryIndexBucket is the name of the sub-bucket within the
+	// invoiceBucket which indexes all open invoices by the time they
+	// expire. This index allows the expiry watcher to load the next
+	// invoices that expire without scanning all pending invoices.
+	//
+	// maps: expiryUnixNano || invoiceKey => payHash || keysendFlag
+	invoiceExpiryIndexBucket = []byte("invoice-expiry-index")
 )
 
 const (
@@ -126,6 +135,14 @@ const (
 	hodlInvoiceType     tlv.Type = 14
 	invoiceAmpStateType tlv.Type = 15
 
+	// hodlPolicyType uses an odd type so that older versions that don't
+	// know about hodl policies can still read the invoice.
+	hodlPolicyType tlv.Type = 17
+
+	// invoiceMetadataType uses an odd type so that older versions that
+	// don't know about invoice metadata can still read the invoice.
+	invoiceMetadataType tlv.Type = 19
+
 	// A set of tlv type definitions used to serialize the invoice AMP
 	// state along-side the main invoice body.
 	ampStateSetIDType       tlv.Type = 0
@@ -167,6 +184,12 @@ func (d *DB) AddInvoice(_ context.Context, newInvoice *invpkg.Invoice,
 		if err != nil {
 			return err
 		}
+		expiryIndex, err := invoices.CreateBucketIfNotExists(
+			invoiceExpiryIndexBucket,
+		)
+		if err != nil {
+			return err
+		}
 
 		// Ensure that an invoice an identical payment hash doesn't
 		// already exist within the index.
@@ -204,7 +227,7 @@ func (d *DB) AddInvoice(_ context.Context, newInvoice *invpkg.Invoice,
 
 		newIndex, err := putInvoice(
 			invoices, invoiceIndex, payAddrIndex, addIndex,
-			newInvoice, invoiceNum, paymentHash,
+			expiryIndex, newInvoice, invoiceNum, paymentHash,
 		)
 		if err != nil {
 			return err
@@ -436,12 +459,33 @@ func fetchInvoiceNumByRef(invoiceIndex, payAddrIndex, setIDIndex kvdb.RBucket,
 // FetchPendingInvoices returns all invoices that have not yet been settled or
 // canceled. The returned map is keyed by the payment hash of each respective
 // invoice.
-func (d *DB) FetchPendingInvoices(_ context.Context) (
+func (d *DB) FetchPendingInvoices(ctx context.Context) (
 	map[lntypes.Hash]invpkg.Invoice, error) {
 
-	result := make(map[lntypes.Hash]invpkg.Invoice)
+	var result map[lntypes.Hash]invpkg.Invoice
 
-	err := kvdb.View(d, func(tx kvdb.RTx) error {
+	err := d.ForEachPendingInvoice(ctx,
+		func(hash lntypes.Hash, invoice *invpkg.Invoice) error {
+			result[hash] = *invoice
+			return nil
+		}, func() {
+			result = make(map[lntypes.Hash]invpkg.Invoice)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ForEachPendingInvoice calls the passed callback for every invoice that has
+// not yet been settled or canceled. Only a single invoice is deserialized at a
+// time, so the pending invoices don't need to fit into memory at once.
+func (d *DB) ForEachPendingInvoice(_ context.Context,
+	cb func(lntypes.Hash, *invpkg.Invoice) error, reset func()) error {
+
+	return kvdb.View(d, func(tx kvdb.RTx) error {
 		invoices := tx.ReadBucket(invoiceBucket)
 		if invoices == nil {
 			return nil
@@ -473,23 +517,112 @@ func (d *DB) FetchPendingInvoices(_ context.Context) (
 				return err
 			}
 
-			if invoice.IsPending() {
-				var paymentHash lntypes.Hash
-				copy(paymentHash[:], k)
-				result[paymentHash] = invoice
+			if !invoice.IsPending() {
+				return nil
 			}
 
-			return nil
+			var paymentHash lntypes.Hash
+			copy(paymentHash[:], k)
+
+			return cb(paymentHash, &invoice)
 		})
+	}, reset)
+}
+
+// FetchInvoiceExpiries returns up to limit expiries of open invoices that are
+// ordered after the passed one. The expiries are ordered by the time the
+// invoices expire and, to break ties, by their payment hash.
+func (d *DB) FetchInvoiceExpiries(_ context.Context,
+	after invpkg.InvoiceExpiry, limit int) ([]invpkg.InvoiceExpiry,
+	error) {
+
+	var expiries []invpkg.InvoiceExpiry
+	err := kvdb.View(d, func(tx kvdb.RTx) error {
+		invoices := tx.ReadBucket(invoiceBucket)
+		if invoices == nil {
+			return nil
+		}
+
+		expiryIndex := invoices.NestedReadBucket(
+			invoiceExpiryIndexBucket,
+		)
+		if expiryIndex == nil {
+			return nil
+		}
+
+		afterUnixNano := expiryUnixNano(after.Expiry)
+
+		var afterKey [8]byte
+		byteOrder.PutUint64(afterKey[:], afterUnixNano)
+
+		// Invoices that expire at the same time are ordered by their
+		// invoice number within the index, so we'll collect all of
+		// them before ordering them by their payment hash.
+		var (
+			sameExpiry []invpkg.InvoiceExpiry
+			lastExpiry uint64
+		)
+		addSameExpiry := func() {
+			sort.Slice(sameExpiry, func(i, j int) bool {
+				return bytes.Compare(
+					sameExpiry[i].PaymentHash[:],
+					sameExpiry[j].PaymentHash[:],
+				) < 0
+			})
+			expiries = append(expiries, sameExpiry...)
+			sameExpiry = nil
+		}
+
+		cursor := expiryIndex.ReadCursor()
+		k, v := cursor.Seek(afterKey[:])
+		for ; k != nil; k, v = cursor.Next() {
+			if len(k) < 8 || len(v) != lntypes.HashSize+1 {
+				return fmt.Errorf("invalid expiry index entry "+
+					"%x", k)
+			}
+
+			unixNano := byteOrder.Uint64(k[:8])
+			if unixNano != lastExpiry {
+				addSameExpiry()
+				if len(expiries) >= limit {
+					break
+				}
+
+				lastExpiry = unixNano
+			}
+
+			expiry := invpkg.InvoiceExpiry{
+				Expiry:  time.Unix(0, int64(unixNano)),
+				Keysend: v[lntypes.HashSize] == 1,
+			}
+			copy(expiry.PaymentHash[:], v)
+
+			// Skip the invoices that expire at the same time as
+			// the passed one but are ordered before it.
+			if unixNano == afterUnixNano && bytes.Compare(
+				expiry.PaymentHash[:], after.PaymentHash[:],
+			) <= 0 {
+
+				continue
+			}
+
+			sameExpiry = append(sameExpiry, expiry)
+		}
+		addSameExpiry()
+
+		if len(expiries) > limit {
+			expiries = expiries[:limit]
+		}
+
+		return nil
 	}, func() {
-		result = make(map[lntypes.Hash]invpkg.Invoice)
+		expiries = nil
 	})
-
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return expiries, nil
 }
 
 // QueryInvoices allows a caller to query the invoice database for invoices
@@ -555,6 +688,12 @@ func (d *DB) QueryInvoices(_ context.Context, q invpkg.InvoiceQuery) (
 				return false, nil
 			}
 
+			// Skip any invoices that don't match the metadata
+			// filter.
+			if !q.MatchesMetadata(invoice.Metadata) {
+				return false, nil
+			}
+
 			// At this point, we've exhausted the offset, so we'll
 			// begin collecting invoices found within the range.
 			resp.Invoices = append(resp.Invoices, invoice)
@@ -632,6 +771,12 @@ func (d *DB) UpdateInvoice(_ context.Context, ref invpkg.InvoiceRef,
 		if err != nil {
 			return err
 		}
+		expiryIndex, err := invoices.CreateBucketIfNotExists(
+			invoiceExpiryIndexBucket,
+		)
+		if err != nil {
+			return err
+		}
 		payAddrIndex := tx.ReadBucket(payAddrIndexBucket)
 		setIDIndex := tx.ReadWriteBucket(setIDIndexBucket)
 
@@ -672,12 +817,22 @@ func (d *DB) UpdateInvoice(_ context.Context, ref invpkg.InvoiceRef,
 			settledSetIDs:     make(map[invpkg.SetID]struct{}),
 		}
 
+		// The invoice is updated in place, so we'll need to remember
+		// its entry in the expiry index before the update.
+		oldExpiryKey := invoiceExpiryKey(&invoice, invoiceNum)
+
 		payHash := ref.PayHash()
 		updatedInvoice, err = invpkg.UpdateInvoice(
 			payHash, updater.invoice, now, callback, updater,
 		)
+		if err != nil {
+			return err
+		}
 
-		return err
+		return updateInvoiceExpiry(
+			expiryIndex, invoiceIndex, oldExpiryKey, invoiceNum,
+			payHash, updatedInvoice,
+		)
 	}, func() {
 		updatedInvoice = nil
 	})
@@ -685,6 +840,103 @@ func (d *DB) UpdateInvoice(_ context.Context, ref invpkg.InvoiceRef,
 	return updatedInvoice, err
 }
 
+// expiryUnixNano returns the expiry of an invoice in unix nano as it is
+// stored in the expiry index. Expiries before the unix epoch are ordered
+// first.
+func expiryUnixNano(expiry time.Time) uint64 {
+	if !expiry.After(time.Unix(0, 0)) {
+		return 0
+	}
+
+	return uint64(expiry.UnixNano())
+}
+
+// invoiceExpiryKey returns the key of an invoice in the expiry index, or nil
+// if the invoice isn't open and therefore not part of the index.
+func invoiceExpiryKey(invoice *invpkg.Invoice, invoiceNum []byte) []byte {
+	if invoice.State != invpkg.ContractOpen {
+		return nil
+	}
+
+	key := make([]byte, 8, 8+len(invoiceNum))
+	byteOrder.PutUint64(key, expiryUnixNano(invoice.ExpiresAt()))
+
+	return append(key, invoiceNum...)
+}
+
+// putInvoiceExpiry adds an invoice to the expiry index under the passed key.
+func putInvoiceExpiry(expiryIndex kvdb.RwBucket, key []byte,
+	paymentHash lntypes.Hash, invoice *invpkg.Invoice) error {
+
+	var value [lntypes.HashSize + 1]byte
+	copy(value[:], paymentHash[:])
+	if len(invoice.PaymentRequest) == 0 {
+		value[lntypes.HashSize] = 1
+	}
+
+	return expiryIndex.Put(key, value[:])
+}
+
+// updateInvoiceExpiry moves the entry of an invoice in the expiry index after
+// an update changed its state or its expiry.
+func updateInvoiceExpiry(expiryIndex, invoiceIndex kvdb.RwBucket,
+	oldKey, invoiceNum []byte, payHash *lntypes.Hash,
+	invoice *invpkg.Invoice) error {
+
+	newKey := invoiceExpiryKey(invoice, invoiceNum)
+	if bytes.Equal(oldKey, newKey) {
+		return nil
+	}
+
+	// Invoices referenced by their payment address or set ID carry their
+	// payment hash in the entry that is replaced.
+	var paymentHash lntypes.Hash
+	if payHash != nil {
+		paymentHash = *payHash
+	}
+
+	if oldKey != nil {
+		if value := expiryIndex.Get(oldKey); payHash == nil &&
+			len(value) > lntypes.HashSize {
+
+			copy(paymentHash[:], value)
+			payHash = &paymentHash
+		}
+
+		if err := expiryIndex.Delete(oldKey); err != nil {
+			return err
+		}
+	}
+
+	if newKey == nil {
+		return nil
+	}
+
+	// An invoice that becomes open again has no entry to take the payment
+	// hash from, so we'll look it up in the payment hash index.
+	if payHash == nil {
+		err := invoiceIndex.ForEach(func(k, v []byte) error {
+			if len(k) == lntypes.HashSize &&
+				bytes.Equal(v, invoiceNum) {
+
+				copy(paymentHash[:], k)
+				payHash = &paymentHash
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if payHash == nil {
+			return invpkg.ErrInvoiceNotFound
+		}
+	}
+
+	return putInvoiceExpiry(expiryIndex, newKey, paymentHash, invoice)
+}
+
 // ampHTLCsMap is a map of AMP HTLCs affected by an invoice update.
 type ampHTLCsMap map[invpkg.SetID]map[models.CircuitKey]*invpkg.InvoiceHTLC
 
@@ -750,6 +1002,11 @@ func (k *kvInvoiceUpdater) UpdateInvoiceAmtPaid(_ lnwire.MilliSatoshi) error {
 	return nil
 }
 
+// NOTE: this method does nothing in the k/v implementation of InvoiceUpdater.
+func (k *kvInvoiceUpdater) AmendInvoice(_ *invpkg.InvoiceAmendment) error {
+	return nil
+}
+
 // UpdateAmpState updates the state of the AMP invoice identified by the setID.
 func (k *kvInvoiceUpdater) UpdateAmpState(setID [32]byte,
 	state invpkg.InvoiceStateAMP, circuitKey models.CircuitKey) error {
@@ -813,6 +1070,9 @@ func (k *kvInvoiceUpdater) Finalize(updateType invpkg.UpdateType) error {
 
 	case invpkg.CancelInvoiceUpdate:
 		return k.serializeAndStoreInvoice()
+
+	case invpkg.AmendInvoiceUpdate:
+		return k.serializeAndStoreInvoice()
 	}
 
 	return fmt.Errorf("unknown update type: %v", updateType)
@@ -1076,9 +1336,9 @@ func (d *DB) InvoicesSettledSince(_ context.Context, sinceSettleIndex uint64) (
 	return settledInvoices, nil
 }
 
-func putInvoice(invoices, invoiceIndex, payAddrIndex, addIndex kvdb.RwBucket,
-	i *invpkg.Invoice, invoiceNum uint32, paymentHash lntypes.Hash) (
-	uint64, error) {
+func putInvoice(invoices, invoiceIndex, payAddrIndex, addIndex,
+	expiryIndex kvdb.RwBucket, i *invpkg.Invoice, invoiceNum uint32,
+	paymentHash lntypes.Hash) (uint64, error) {
 
 	// Create the invoice key which is just the big-endian representation
 	// of the invoice number.
@@ -1132,6 +1392,15 @@ func putInvoice(invoices, invoiceIndex, payAddrIndex, addIndex kvdb.RwBucket,
 
 	i.AddIndex = nextAddSeqNo
 
+	// If the invoice is open, add it to the expiry index, so it is
+	// canceled once it expires.
+	if key := invoiceExpiryKey(i, invoiceKey[:]); key != nil {
+		err := putInvoiceExpiry(expiryIndex, key, paymentHash, i)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// Finally, serialize the invoice itself to be written to the disk.
 	var buf bytes.Buffer
 	if err := serializeInvoice(&buf, i); err != nil {
@@ -1209,7 +1478,7 @@ func serializeInvoice(w io.Writer, i *invpkg.Invoice) error {
 		hodlInvoice = 1
 	}
 
-	tlvStream, err := tlv.NewStream(
+	records := []tlv.Record{
 		// Memo and payreq.
 		tlv.MakePrimitiveRecord(memoType, &i.Memo),
 		tlv.MakePrimitiveRecord(payReqType, &i.PaymentRequest),
@@ -1240,7 +1509,33 @@ func serializeInvoice(w io.Writer, i *invpkg.Invoice) error {
 			ampRecordSize(&i.AMPState),
 			ampStateEncoder, ampStateDecoder,
 		),
-	)
+	}
+
+	// The hodl policy is optional, so we only add its record if it is
+	// set.
+	if i.HodlPolicy != nil {
+		policyBytes := serializeHodlPolicy(i.HodlPolicy)
+		records = append(
+			records,
+			tlv.MakePrimitiveRecord(hodlPolicyType, &policyBytes),
+		)
+	}
+
+	// Likewise, the metadata record is only added if there is metadata.
+	if len(i.Metadata) > 0 {
+		metadataBytes, err := serializeInvoiceMetadata(i.Metadata)
+		if err != nil {
+			return err
+		}
+
+		records = append(
+			records, tlv.MakePrimitiveRecord(
+				invoiceMetadataType, &metadataBytes,
+			),
+		)
+	}
+
+	tlvStream, err := tlv.NewStream(records...)
 	if err != nil {
 		return err
 	}
@@ -1599,6 +1894,8 @@ func deserializeInvoice(r io.Reader) (invpkg.Invoice, error) {
 		creationDateBytes []byte
 		settleDateBytes   []byte
 		featureBytes      []byte
+		policyBytes       []byte
+		metadataBytes     []byte
 	)
 
 	var i invpkg.Invoice
@@ -1633,6 +1930,9 @@ func deserializeInvoice(r io.Reader) (invpkg.Invoice, error) {
 			invoiceAmpStateType, &i.AMPState, nil,
 			ampStateEncoder, ampStateDecoder,
 		),
+
+		tlv.MakePrimitiveRecord(hodlPolicyType, &policyBytes),
+		tlv.MakePrimitiveRecord(invoiceMetadataType, &metadataBytes),
 	)
 	if err != nil {
 		return i, err
@@ -1664,6 +1964,20 @@ func deserializeInvoice(r io.Reader) (invpkg.Invoice, error) {
 		i.HodlInvoice = true
 	}
 
+	if len(policyBytes) > 0 {
+		i.HodlPolicy, err = deserializeHodlPolicy(policyBytes)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	if len(metadataBytes) > 0 {
+		i.Metadata, err = deserializeInvoiceMetadata(metadataBytes)
+		if err != nil {
+			return i, err
+		}
+	}
+
 	err = i.CreationDate.UnmarshalBinary(creationDateBytes)
 	if err != nil {
 		return i, err
@@ -1690,6 +2004,134 @@ func deserializeInvoice(r io.Reader) (invpkg.Invoice, error) {
 	return i, err
 }
 
+// serializeHodlPolicy encodes a hodl policy as its hold duration, followed by
+// the timeout action and the optional settle preimage.
+func serializeHodlPolicy(policy *invpkg.HodlPolicy) []byte {
+	b := make([]byte, 9, 9+lntypes.PreimageSize)
+	byteOrder.PutUint64(b[:8], uint64(policy.HoldDuration))
+	b[8] = uint8(policy.Action)
+
+	if policy.Preimage != nil {
+		b = append(b, policy.Preimage[:]...)
+	}
+
+	return b
+}
+
+// deserializeHodlPolicy decodes a hodl policy that was encoded with
+// serializeHodlPolicy.
+func deserializeHodlPolicy(b []byte) (*invpkg.HodlPolicy, error) {
+	if len(b) != 9 && len(b) != 9+lntypes.PreimageSize {
+		return nil, fmt.Errorf("invalid hodl policy length: %v",
+			len(b))
+	}
+
+	policy := &invpkg.HodlPolicy{
+		HoldDuration: time.Duration(byteOrder.Uint64(b[:8])),
+		Action:       invpkg.HodlTimeoutAction(b[8]),
+	}
+
+	if len(b) > 9 {
+		preimage, err := lntypes.MakePreimage(b[9:])
+		if err != nil {
+			return nil, err
+		}
+		policy.Preimage = &preimage
+	}
+
+	return policy, nil
+}
+
+// serializeInvoiceMetadata encodes invoice metadata as the number of entries,
+// followed by the length prefixed key and value of each entry ordered by key.
+func serializeInvoiceMetadata(metadata map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var (
+		b       bytes.Buffer
+		scratch [8]byte
+	)
+	err := tlv.WriteVarInt(&b, uint64(len(keys)), &scratch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		for _, field := range []string{key, metadata[key]} {
+			err := tlv.WriteVarInt(&b, uint64(len(field)), &scratch)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := b.WriteString(field); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeInvoiceMetadata decodes invoice metadata that was encoded with
+// serializeInvoiceMetadata.
+func deserializeInvoiceMetadata(b []byte) (map[string]string, error) {
+	var (
+		r       = bytes.NewReader(b)
+		scratch [8]byte
+	)
+
+	readField := func() (string, error) {
+		fieldLen, err := tlv.ReadVarInt(r, &scratch)
+		if err != nil {
+			return "", err
+		}
+
+		if fieldLen > uint64(r.Len()) {
+			return "", fmt.Errorf("invalid metadata field "+
+				"length: %v", fieldLen)
+		}
+
+		field := make([]byte, fieldLen)
+		if _, err := io.ReadFull(r, field); err != nil {
+			return "", err
+		}
+
+		return string(field), nil
+	}
+
+	numEntries, err := tlv.ReadVarInt(r, &scratch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each entry takes at least two bytes for the key and value length.
+	if numEntries > uint64(r.Len())/2 {
+		return nil, fmt.Errorf("invalid number of metadata entries: "+
+			"%v", numEntries)
+	}
+
+	metadata := make(map[string]string, numEntries)
+	for j := uint64(0); j < numEntries; j++ {
+		key, err := readField()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := readField()
+		if err != nil {
+			return nil, err
+		}
+
+		metadata[key] = value
+	}
+
+	return metadata, nil
+}
+
 func encodeCircuitKeys(w io.Writer, val interface{}, buf *[8]byte) error {
 	if v, ok := val.(*map[models.CircuitKey]struct{}); ok {
 		// We encode the set of circuit keys as a varint length prefix.
@@ -2253,6 +2695,12 @@ func (d *DB) DeleteInvoice(_ context.Context,
 		// when the first invoice is settled.
 		settleIndex := invoices.NestedReadWriteBucket(settleIndexBucket)
 
+		// expiryIndex can be nil, as the bucket is created lazily
+		// when the first invoice is added or updated.
+		expiryIndex := invoices.NestedReadWriteBucket(
+			invoiceExpiryIndexBucket,
+		)
+
 		payAddrIndex := tx.ReadWriteBucket(payAddrIndexBucket)
 
 		for _, ref := range invoicesToDelete {
@@ -2264,6 +2712,26 @@ func (d *DB) DeleteInvoice(_ context.Context,
 				return invpkg.ErrInvoiceNotFound
 			}
 
+			// Open invoices are part of the expiry index, which
+			// is keyed by their expiry, so we'll need to read it
+			// to remove them from the index.
+			if expiryIndex != nil {
+				invoice, err := fetchInvoice(
+					invoiceKey, invoices, &invpkg.SetID{},
+				)
+				if err != nil {
+					return err
+				}
+
+				key := invoiceExpiryKey(&invoice, invoiceKey)
+				if key != nil {
+					err := expiryIndex.Delete(key)
+					if err != nil {
+						return err
+					}
+				}
+			}
+
 			err := invoiceIndex.Delete(ref.PayHash[:])
 			if err != nil {
 				return err
@@ -0,0 +1,160 @@
+package migration32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+// MigratePaymentCreationIndex creates the index of payments by their creation
+// time and populates it with all stored payments, including the duplicate
+// payments of earlier versions of lnd.
+func MigratePaymentCreationIndex(tx kvdb.RwTx) error {
+	log.Info("Populating payment creation index")
+
+	index, err := tx.CreateTopLevelBucket(paymentsCreationIndexBucket)
+	if err != nil {
+		return err
+	}
+
+	payments := tx.ReadBucket(paymentsRootBucket)
+	if payments == nil {
+		return nil
+	}
+
+	var numPayments int
+	err = payments.ForEach(func(k, _ []byte) error {
+		bucket := payments.NestedReadBucket(k)
+		if bucket == nil {
+			return fmt.Errorf("non bucket element in payments " +
+				"bucket")
+		}
+
+		n, err := indexPayment(index, bucket)
+		if err != nil {
+			return fmt.Errorf("unable to index payment %x: %w", k,
+				err)
+		}
+		numPayments += n
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Indexed %v payments by creation time", numPayments)
+
+	return nil
+}
+
+// indexPayment adds the index entries of a payment and its duplicates. It
+// returns the number of entries that were added.
+func indexPayment(index kvdb.RwBucket, bucket kvdb.RBucket) (int, error) {
+	seq := bucket.Get(paymentSequenceKey)
+	if seq == nil {
+		return 0, errors.New("sequence number not found")
+	}
+
+	info := bucket.Get(paymentCreationInfoKey)
+	if len(info) < creationTimeOffset+8 {
+		return 0, errors.New("invalid creation info")
+	}
+
+	// The creation time of the payment is stored in unix nano, so the
+	// key can be copied as is.
+	creationTime := info[creationTimeOffset : creationTimeOffset+8]
+
+	var settled bool
+	if htlcs := bucket.NestedReadBucket(paymentHtlcsBucket); htlcs != nil {
+		err := htlcs.ForEach(func(k, _ []byte) error {
+			if bytes.HasPrefix(k, htlcSettleInfoKey) {
+				settled = true
+			}
+
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err := putIndexEntry(index, creationTime, seq, settled)
+	if err != nil {
+		return 0, err
+	}
+
+	duplicates := bucket.NestedReadBucket(duplicatePaymentsBucket)
+	if duplicates == nil {
+		return 1, nil
+	}
+
+	numEntries := 1
+	err = duplicates.ForEach(func(k, _ []byte) error {
+		dup := duplicates.NestedReadBucket(k)
+		if dup == nil {
+			return errors.New("non bucket element in duplicate " +
+				"bucket")
+		}
+
+		seq := dup.Get(duplicatePaymentSequenceKey)
+		if seq == nil {
+			return errors.New("duplicate sequence number not " +
+				"found")
+		}
+
+		info := dup.Get(duplicatePaymentCreationInfoKey)
+		if len(info) < creationTimeOffset+8 {
+			return errors.New("invalid duplicate creation info")
+		}
+
+		// Duplicate payments store their creation time in unix
+		// seconds, which is converted to unix nano for the key. Times
+		// that can't be represented in unix nano are clamped.
+		secs := binary.BigEndian.Uint64(
+			info[creationTimeOffset : creationTimeOffset+8],
+		)
+		unixNano := uint64(math.MaxInt64)
+		if secs <= math.MaxInt64/uint64(time.Second) {
+			unixNano = uint64(time.Unix(int64(secs), 0).UnixNano())
+		}
+
+		var creationTime [8]byte
+		binary.BigEndian.PutUint64(creationTime[:], unixNano)
+
+		settled := dup.Get(duplicatePaymentSettleInfoKey) != nil
+		err := putIndexEntry(index, creationTime[:], seq, settled)
+		if err != nil {
+			return err
+		}
+		numEntries++
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numEntries, nil
+}
+
+// putIndexEntry adds the index entry of a single payment.
+func putIndexEntry(index kvdb.RwBucket, creationTime, seq []byte,
+	settled bool) error {
+
+	key := make([]byte, 0, len(creationTime)+len(seq))
+	key = append(key, creationTime...)
+	key = append(key, seq...)
+
+	var value byte
+	if settled {
+		value = settledFlag
+	}
+
+	return index.Put(key, []byte{value})
+}
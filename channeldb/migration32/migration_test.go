@@ -0,0 +1,118 @@
+package migration32
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb/migtest"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	hexStr = migtest.Hex
+
+	// creationInfo returns a serialized creation info with the given
+	// hex encoded creation time and an empty payment request.
+	creationInfo = func(creationTime string) string {
+		return hexStr(strings.Repeat("11", 32) + "00000000000003e8" +
+			creationTime + "00000000")
+	}
+
+	// duplicateBefore is a failed duplicate payment whose creation time
+	// is stored in seconds.
+	duplicateBefore = map[string]interface{}{
+		"payment-sequence-key":  hexStr("0000000000000001"),
+		"payment-creation-info": creationInfo("0000000000000002"),
+		"payment-fail-info":     hexStr("00"),
+	}
+
+	// paymentsBefore holds a settled payment with a duplicate that
+	// failed, and a payment that is in flight.
+	paymentsBefore = map[string]interface{}{
+		hexStr(strings.Repeat("aa", 32)): map[string]interface{}{
+			"payment-sequence-key": hexStr("0000000000000003"),
+			"payment-creation-info": creationInfo(
+				"0000000000000064",
+			),
+			"payment-htlcs-bucket": map[string]interface{}{
+				hexStr("6169" + "0000000000000001"): "a",
+				hexStr("7369" + "0000000000000001"): "s",
+			},
+			"payment-duplicate-bucket": map[string]interface{}{
+				hexStr("0000000000000001"): duplicateBefore,
+			},
+		},
+		hexStr(strings.Repeat("bb", 32)): map[string]interface{}{
+			"payment-sequence-key": hexStr("0000000000000004"),
+			"payment-creation-info": creationInfo(
+				"00000000000000c8",
+			),
+			"payment-htlcs-bucket": map[string]interface{}{
+				hexStr("6169" + "0000000000000002"): "a",
+			},
+		},
+	}
+
+	// indexAfter is the expected creation index. The creation time of
+	// the duplicate payment is converted from seconds to nanoseconds.
+	indexAfter = map[string]interface{}{
+		hexStr("0000000000000064" + "0000000000000003"): hexStr("01"),
+		hexStr("0000000077359400" + "0000000000000001"): hexStr("00"),
+		hexStr("00000000000000c8" + "0000000000000004"): hexStr("00"),
+	}
+)
+
+// TestMigratePaymentCreationIndex asserts that the creation index is
+// populated with all payments and their duplicates.
+func TestMigratePaymentCreationIndex(t *testing.T) {
+	t.Parallel()
+
+	before := func(tx kvdb.RwTx) error {
+		return migtest.RestoreDB(
+			tx, paymentsRootBucket, paymentsBefore,
+		)
+	}
+
+	after := func(tx kvdb.RwTx) error {
+		err := migtest.VerifyDB(
+			tx, paymentsCreationIndexBucket, indexAfter,
+		)
+		if err != nil {
+			return err
+		}
+
+		var numEntries int
+		err = tx.ReadBucket(paymentsCreationIndexBucket).ForEach(
+			func(_, _ []byte) error {
+				numEntries++
+				return nil
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, len(indexAfter), numEntries)
+
+		return nil
+	}
+
+	migtest.ApplyMigration(
+		t, before, after, MigratePaymentCreationIndex, false,
+	)
+}
+
+// TestMigratePaymentCreationIndexEmpty asserts that the index is created if
+// there are no payments.
+func TestMigratePaymentCreationIndexEmpty(t *testing.T) {
+	t.Parallel()
+
+	after := func(tx kvdb.RwTx) error {
+		return migtest.VerifyDB(
+			tx, paymentsCreationIndexBucket, nil,
+		)
+	}
+
+	migtest.ApplyMigration(
+		t, func(kvdb.RwTx) error { return nil }, after,
+		MigratePaymentCreationIndex, false,
+	)
+}
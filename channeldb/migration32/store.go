@@ -0,0 +1,55 @@
+package migration32
+
+var (
+	// paymentsRootBucket is the name of the top-level bucket that stores
+	// all payments, each within a sub-bucket keyed by its payment hash.
+	paymentsRootBucket = []byte("payments-root-bucket")
+
+	// paymentSequenceKey is the key of the sequence number of a payment.
+	paymentSequenceKey = []byte("payment-sequence-key")
+
+	// paymentCreationInfoKey is the key of the creation info of a payment.
+	paymentCreationInfoKey = []byte("payment-creation-info")
+
+	// paymentHtlcsBucket is the bucket that stores the HTLC attempts of a
+	// payment.
+	paymentHtlcsBucket = []byte("payment-htlcs-bucket")
+
+	// htlcSettleInfoKey is the prefix of the settle info of an HTLC
+	// attempt.
+	htlcSettleInfoKey = []byte("si")
+
+	// duplicatePaymentsBucket is the bucket that holds the duplicate
+	// payments of a payment hash, which were possible in earlier versions
+	// of lnd.
+	duplicatePaymentsBucket = []byte("payment-duplicate-bucket")
+
+	// duplicatePaymentSettleInfoKey is the key of the settle info of a
+	// duplicate payment.
+	duplicatePaymentSettleInfoKey = []byte("payment-settle-info")
+
+	// duplicatePaymentCreationInfoKey is the key of the creation info of
+	// a duplicate payment.
+	duplicatePaymentCreationInfoKey = []byte("payment-creation-info")
+
+	// duplicatePaymentSequenceKey is the key of the sequence number of a
+	// duplicate payment.
+	duplicatePaymentSequenceKey = []byte("payment-sequence-key")
+
+	// paymentsCreationIndexBucket is the top-level bucket that indexes the
+	// payments by their creation time.
+	//
+	// maps: <creation time unix nano><sequence number> -> <settled flag>
+	paymentsCreationIndexBucket = []byte("payments-creation-index-bucket")
+)
+
+const (
+	// creationTimeOffset is the offset of the creation time within the
+	// serialized creation info of a payment, which starts with the
+	// payment hash and the value.
+	creationTimeOffset = 32 + 8
+
+	// settledFlag is the value of an index entry of a payment that
+	// settled.
+	settledFlag = 1
+)
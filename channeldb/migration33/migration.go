@@ -0,0 +1,180 @@
+package migration33
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+// defaultInvoiceExpiry is the expiry of invoices that were stored without
+// one.
+const defaultInvoiceExpiry = time.Hour
+
+// MigrateInvoiceExpiryIndex creates the index of open invoices by their expiry
+// and populates it with all invoices that are open.
+func MigrateInvoiceExpiryIndex(tx kvdb.RwTx) error {
+	log.Info("Populating invoice expiry index")
+
+	invoices := tx.ReadWriteBucket(invoiceBucket)
+	if invoices == nil {
+		return nil
+	}
+
+	expiryIndex, err := invoices.CreateBucketIfNotExists(
+		invoiceExpiryIndexBucket,
+	)
+	if err != nil {
+		return err
+	}
+
+	invoiceIndex := invoices.NestedReadBucket(invoiceIndexBucket)
+	if invoiceIndex == nil {
+		return nil
+	}
+
+	var numInvoices int
+	err = invoiceIndex.ForEach(func(k, v []byte) error {
+		// Skip the invoice counter and sub-buckets.
+		if bytes.Equal(k, numInvoicesKey) || v == nil {
+			return nil
+		}
+
+		if len(k) != hashSize {
+			return fmt.Errorf("invalid payment hash %x", k)
+		}
+
+		invoiceBytes := invoices.Get(v)
+		if invoiceBytes == nil {
+			return fmt.Errorf("invoice %x not found", k)
+		}
+
+		indexed, err := indexInvoice(expiryIndex, k, v, invoiceBytes)
+		if err != nil {
+			return fmt.Errorf("unable to index invoice %x: %w", k,
+				err)
+		}
+		if indexed {
+			numInvoices++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Indexed %v open invoices by their expiry", numInvoices)
+
+	return nil
+}
+
+// indexInvoice adds the expiry index entry of an invoice if it is open. It
+// returns true if the invoice was added.
+func indexInvoice(expiryIndex kvdb.RwBucket, payHash, invoiceKey,
+	invoiceBytes []byte) (bool, error) {
+
+	r := bytes.NewReader(invoiceBytes)
+
+	var bodyLen int64
+	if err := binary.Read(r, byteOrder, &bodyLen); err != nil {
+		return false, err
+	}
+	if bodyLen < 0 || bodyLen > int64(r.Len()) {
+		return false, errors.New("invalid invoice length")
+	}
+
+	body := make([]byte, bodyLen)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return false, err
+	}
+
+	records, err := readRecords(body)
+	if err != nil {
+		return false, err
+	}
+
+	state, ok := records[invStateType]
+	if !ok || len(state) != 1 {
+		return false, errors.New("invalid invoice state")
+	}
+	if state[0] != contractOpen {
+		return false, nil
+	}
+
+	var creationDate time.Time
+	err = creationDate.UnmarshalBinary(records[createTimeType])
+	if err != nil {
+		return false, err
+	}
+
+	var expiry time.Duration
+	if value, ok := records[expiryType]; ok {
+		if len(value) != 8 {
+			return false, errors.New("invalid invoice expiry")
+		}
+		expiry = time.Duration(byteOrder.Uint64(value))
+	}
+	if expiry == 0 {
+		expiry = defaultInvoiceExpiry
+	}
+
+	// Expiries before the unix epoch are ordered first.
+	var unixNano uint64
+	if expiresAt := creationDate.Add(expiry); expiresAt.After(
+		time.Unix(0, 0),
+	) {
+
+		unixNano = uint64(expiresAt.UnixNano())
+	}
+
+	key := make([]byte, 8, 8+len(invoiceKey))
+	byteOrder.PutUint64(key, unixNano)
+	key = append(key, invoiceKey...)
+
+	value := make([]byte, hashSize+1)
+	copy(value, payHash)
+	if len(records[payReqType]) == 0 {
+		value[hashSize] = keysendFlag
+	}
+
+	return true, expiryIndex.Put(key, value)
+}
+
+// readRecords reads the values of all records of a tlv stream, keyed by their
+// type.
+func readRecords(stream []byte) (map[tlv.Type][]byte, error) {
+	r := bytes.NewReader(stream)
+	records := make(map[tlv.Type][]byte)
+
+	var buf [8]byte
+	for r.Len() > 0 {
+		recordType, err := tlv.ReadVarInt(r, &buf)
+		if err != nil {
+			return nil, err
+		}
+
+		length, err := tlv.ReadVarInt(r, &buf)
+		if err != nil {
+			return nil, err
+		}
+		if length > uint64(r.Len()) {
+			return nil, fmt.Errorf("record %d exceeds stream",
+				recordType)
+		}
+
+		value := make([]byte, length)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+
+		records[tlv.Type(recordType)] = value
+	}
+
+	return records, nil
+}
@@ -0,0 +1,120 @@
+package migration33
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb/migtest"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/tlv"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	hexStr = migtest.Hex
+
+	openHash     = strings.Repeat("aa", 32)
+	keysendHash  = strings.Repeat("bb", 32)
+	settledHash  = strings.Repeat("cc", 32)
+	creationDate = time.Unix(1_000, 0)
+)
+
+// serializeInvoice returns the serialized body of an invoice with the passed
+// fields, prefixed by a memo record that isn't known to the migration.
+func serializeInvoice(t *testing.T, payReq []byte, expiry uint64,
+	state uint8) string {
+
+	memo := []byte("memo")
+	createTime, err := creationDate.MarshalBinary()
+	require.NoError(t, err)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(0, &memo),
+		tlv.MakePrimitiveRecord(payReqType, &payReq),
+		tlv.MakePrimitiveRecord(createTimeType, &createTime),
+		tlv.MakePrimitiveRecord(expiryType, &expiry),
+		tlv.MakePrimitiveRecord(invStateType, &state),
+	)
+	require.NoError(t, err)
+
+	var body bytes.Buffer
+	require.NoError(t, stream.Encode(&body))
+
+	var b bytes.Buffer
+	require.NoError(t, binary.Write(&b, byteOrder, int64(body.Len())))
+	b.Write(body.Bytes())
+
+	return b.String()
+}
+
+// TestMigrateInvoiceExpiryIndex asserts that all open invoices are added to
+// the expiry index.
+func TestMigrateInvoiceExpiryIndex(t *testing.T) {
+	t.Parallel()
+
+	invoicesBefore := map[string]interface{}{
+		"paymenthashes": map[string]interface{}{
+			"nik":               hexStr("00000003"),
+			hexStr(openHash):    hexStr("00000000"),
+			hexStr(keysendHash): hexStr("00000001"),
+			hexStr(settledHash): hexStr("00000002"),
+		},
+		hexStr("00000000"): serializeInvoice(
+			t, []byte("lnbc"), uint64(time.Minute), 0,
+		),
+		hexStr("00000001"): serializeInvoice(t, nil, 0, 0),
+		hexStr("00000002"): serializeInvoice(
+			t, []byte("lnbc"), uint64(time.Minute), 1,
+		),
+	}
+
+	// The open invoice expires after a minute, while the keysend invoice
+	// expires after the default expiry of an hour.
+	indexAfter := map[string]interface{}{
+		hexStr("000000f6ccec6800" + "00000000"): hexStr(
+			openHash + "00",
+		),
+		hexStr("0000042f055db000" + "00000001"): hexStr(
+			keysendHash + "01",
+		),
+	}
+
+	before := func(tx kvdb.RwTx) error {
+		return migtest.RestoreDB(tx, invoiceBucket, invoicesBefore)
+	}
+
+	// The invoices are kept as is, next to the new index.
+	invoicesAfter := map[string]interface{}{
+		string(invoiceExpiryIndexBucket): indexAfter,
+	}
+	for k, v := range invoicesBefore {
+		invoicesAfter[k] = v
+	}
+
+	after := func(tx kvdb.RwTx) error {
+		return migtest.VerifyDB(tx, invoiceBucket, invoicesAfter)
+	}
+
+	migtest.ApplyMigration(
+		t, before, after, MigrateInvoiceExpiryIndex, false,
+	)
+}
+
+// TestMigrateInvoiceExpiryIndexEmpty asserts that the migration succeeds if
+// there are no invoices.
+func TestMigrateInvoiceExpiryIndexEmpty(t *testing.T) {
+	t.Parallel()
+
+	after := func(tx kvdb.RwTx) error {
+		require.Nil(t, tx.ReadBucket(invoiceBucket))
+		return nil
+	}
+
+	migtest.ApplyMigration(
+		t, func(kvdb.RwTx) error { return nil }, after,
+		MigrateInvoiceExpiryIndex, false,
+	)
+}
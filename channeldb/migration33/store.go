@@ -0,0 +1,46 @@
+package migration33
+
+import "encoding/binary"
+
+var (
+	// invoiceBucket is the name of the top-level bucket that stores all
+	// invoices, keyed by their invoice number.
+	invoiceBucket = []byte("invoices")
+
+	// invoiceIndexBucket is the name of the sub-bucket within the
+	// invoiceBucket that maps the payment hashes to the invoice numbers.
+	invoiceIndexBucket = []byte("paymenthashes")
+
+	// numInvoicesKey is the key of the invoice counter within the
+	// invoiceIndexBucket.
+	numInvoicesKey = []byte("nik")
+
+	// invoiceExpiryIndexBucket is the name of the sub-bucket within the
+	// invoiceBucket that indexes all open invoices by the time they
+	// expire.
+	//
+	// maps: expiryUnixNano || invoiceKey => payHash || keysendFlag
+	invoiceExpiryIndexBucket = []byte("invoice-expiry-index")
+
+	// byteOrder is the byte order of the invoice serialization.
+	byteOrder = binary.BigEndian
+)
+
+const (
+	// The tlv types of the invoice fields that determine its entry in the
+	// expiry index.
+	payReqType     = 1
+	createTimeType = 2
+	expiryType     = 9
+	invStateType   = 12
+
+	// contractOpen is the state of an open invoice.
+	contractOpen = 0
+
+	// keysendFlag is the flag of an expiry index entry of an invoice
+	// without a payment request.
+	keysendFlag = 1
+
+	// hashSize is the size of a payment hash.
+	hashSize = 32
+)
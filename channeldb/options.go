@@ -23,6 +23,11 @@ const (
 	// September 2021, there currently are 14k nodes in a strictly pruned
 	// graph, so we choose a number that is slightly higher.
 	DefaultPreAllocCacheNumNodes = 15000
+
+	// DefaultGraphSnapshotSize is the default maximum number of channels
+	// that are kept in the node snapshots of the graph cache. With roughly
+	// 150 bytes per channel, this amounts to around 15MB when full.
+	DefaultGraphSnapshotSize = 100000
 )
 
 // OptionalMiragtionConfig defines the flags used to signal whether a
@@ -54,6 +59,10 @@ type Options struct {
 	// graph cache, so we can pre-allocate the map accordingly.
 	PreAllocCacheNumNodes int
 
+	// GraphSnapshotSize is the maximum number of channels that are kept in
+	// the node snapshots of the graph cache. Zero disables the snapshots.
+	GraphSnapshotSize int
+
 	// UseGraphCache denotes whether the in-memory graph cache should be
 	// used or a fallback version that uses the underlying database for
 	// path finding.
@@ -97,6 +106,7 @@ func DefaultOptions() Options {
 		RejectCacheSize:         DefaultRejectCacheSize,
 		ChannelCacheSize:        DefaultChannelCacheSize,
 		PreAllocCacheNumNodes:   DefaultPreAllocCacheNumNodes,
+		GraphSnapshotSize:       DefaultGraphSnapshotSize,
 		UseGraphCache:           true,
 		NoMigration:             false,
 		clock:                   clock.NewDefaultClock(),
@@ -127,6 +137,13 @@ func OptionSetPreAllocCacheNumNodes(n int) OptionModifier {
 	}
 }
 
+// OptionSetGraphSnapshotSize sets the GraphSnapshotSize to n.
+func OptionSetGraphSnapshotSize(n int) OptionModifier {
+	return func(o *Options) {
+		o.GraphSnapshotSize = n
+	}
+}
+
 // OptionSetUseGraphCache sets the UseGraphCache option to the given value.
 func OptionSetUseGraphCache(use bool) OptionModifier {
 	return func(o *Options) {
@@ -100,6 +100,10 @@ var (
 	// to a payment that already has a failure reason.
 	ErrPaymentPendingFailed = errors.New("payment has failure reason")
 
+	// ErrPaymentHTLCsInFlight is returned when we try to fail a payment
+	// that is expected to be idle, but still has HTLCs in flight.
+	ErrPaymentHTLCsInFlight = errors.New("payment has htlcs in flight")
+
 	// ErrSentExceedsTotal is returned if the payment's current total sent
 	// amount exceed the total amount.
 	ErrSentExceedsTotal = errors.New("total sent exceeds total amount")
@@ -192,6 +196,23 @@ func (p *PaymentControl) InitPayment(paymentHash lntypes.Hash,
 			if err := indexBucket.Delete(seqBytes); err != nil {
 				return err
 			}
+
+			// The creation index entry of the previous attempt is
+			// removed as well.
+			oldInfo, err := fetchCreationInfo(bucket)
+			if err != nil {
+				return err
+			}
+
+			creationIndex := tx.ReadWriteBucket(
+				paymentsCreationIndexBucket,
+			)
+			oldKey := creationIndexKey(
+				oldInfo.CreationTime, seqBytes,
+			)
+			if err := creationIndex.Delete(oldKey); err != nil {
+				return err
+			}
 		}
 
 		// Once we have obtained a sequence number, we add an entry
@@ -204,6 +225,13 @@ func (p *PaymentControl) InitPayment(paymentHash lntypes.Hash,
 			return err
 		}
 
+		err = putCreationIndexEntry(
+			tx, info.CreationTime, sequenceNum, false,
+		)
+		if err != nil {
+			return err
+		}
+
 		err = bucket.Put(paymentSequenceKey, sequenceNum)
 		if err != nil {
 			return err
@@ -491,6 +519,18 @@ func (p *PaymentControl) updateHtlcKey(paymentHash lntypes.Hash,
 			return err
 		}
 
+		// Flag the payment as settled in the creation index once its
+		// first HTLC settles.
+		if bytes.Equal(key, htlcSettleInfoKey) {
+			err := putCreationIndexEntry(
+				tx, p.Info.CreationTime,
+				bucket.Get(paymentSequenceKey), true,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
 		// Retrieve attempt info for the notification.
 		payment, err = fetchPayment(bucket)
 		return err
@@ -562,6 +602,94 @@ func (p *PaymentControl) Fail(paymentHash lntypes.Hash,
 	return payment, updateErr
 }
 
+// Cancel marks the payment as failed with FailureReasonCanceled, so that no
+// new attempts can be registered for it. Unlike Fail, the payment must still
+// allow new attempts, which is checked in the same transaction. A payment
+// that already has a settled HTLC can't be canceled anymore.
+func (p *PaymentControl) Cancel(paymentHash lntypes.Hash) (*MPPayment, error) {
+	var payment *MPPayment
+	err := kvdb.Batch(p.db.Backend, func(tx kvdb.RwTx) error {
+		payment = nil
+
+		prefetchPayment(tx, paymentHash)
+		bucket, err := fetchPaymentBucketUpdate(tx, paymentHash)
+		if err != nil {
+			return err
+		}
+
+		payment, err = fetchPayment(bucket)
+		if err != nil {
+			return err
+		}
+
+		if err := payment.Registrable(); err != nil {
+			return err
+		}
+
+		v := []byte{byte(FailureReasonCanceled)}
+		err = bucket.Put(paymentFailInfoKey, v)
+		if err != nil {
+			return err
+		}
+
+		payment, err = fetchPayment(bucket)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// FailIdle marks the payment as failed with the given reason if it can still
+// be updated and has no HTLCs in flight. Unlike Fail, both conditions are
+// checked in the same transaction, so an attempt that is registered
+// concurrently can't be left behind by a failed payment.
+func (p *PaymentControl) FailIdle(paymentHash lntypes.Hash,
+	reason FailureReason) (*MPPayment, error) {
+
+	var payment *MPPayment
+	err := kvdb.Batch(p.db.Backend, func(tx kvdb.RwTx) error {
+		payment = nil
+
+		prefetchPayment(tx, paymentHash)
+		bucket, err := fetchPaymentBucketUpdate(tx, paymentHash)
+		if err != nil {
+			return err
+		}
+
+		payment, err = fetchPayment(bucket)
+		if err != nil {
+			return err
+		}
+
+		if err := payment.Status.updatable(); err != nil {
+			return err
+		}
+
+		if len(payment.InFlightHTLCs()) > 0 {
+			return ErrPaymentHTLCsInFlight
+		}
+
+		v := []byte{byte(reason)}
+		err = bucket.Put(paymentFailInfoKey, v)
+		if err != nil {
+			return err
+		}
+
+		payment, err = fetchPayment(bucket)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
 // FetchPayment returns information about a payment from the database.
 func (p *PaymentControl) FetchPayment(paymentHash lntypes.Hash) (
 	*MPPayment, error) {
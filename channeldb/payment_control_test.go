@@ -561,6 +561,91 @@ func TestPaymentControlDeletePayments(t *testing.T) {
 	assertPayments(t, db, payments[2:])
 }
 
+// TestPruneFailedAttempts tests that the failed attempts of payments that
+// were created before a given time are pruned, while the payments are kept.
+func TestPruneFailedAttempts(t *testing.T) {
+	t.Parallel()
+
+	db, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to init db")
+
+	pControl := NewPaymentControl(db)
+
+	// Register three payments:
+	// 1. A payment with two failed attempts.
+	// 2. A payment with one failed and one settled attempt.
+	// 3. A payment with one failed and one in-flight attempt.
+	payments := []*payment{
+		{status: StatusFailed},
+		{status: StatusSucceeded},
+		{status: StatusInFlight},
+	}
+	createTestPayments(t, pControl, payments)
+
+	// None of the payments was created before an hour ago.
+	stats, err := db.PruneFailedAttempts(time.Now().Add(-time.Hour), false)
+	require.NoError(t, err)
+	require.Equal(t, &PruneFailedAttemptsStats{}, stats)
+	assertPayments(t, db, payments)
+
+	// The payments are visited in batches that resume from the creation
+	// index key of the previous batch.
+	cutoff := time.Now().Add(time.Hour)
+
+	var (
+		visited []lntypes.Hash
+		after   []byte
+	)
+	for {
+		var (
+			hashes []lntypes.Hash
+			next   []byte
+		)
+		err := kvdb.View(db, func(tx kvdb.RTx) error {
+			var err error
+			hashes, next, err = fetchPaymentsCreatedBefore(
+				tx, cutoff, after, 2,
+			)
+
+			return err
+		}, func() {})
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(hashes), 2)
+
+		visited = append(visited, hashes...)
+		if next == nil {
+			break
+		}
+		after = next
+	}
+	require.Equal(t, []lntypes.Hash{
+		payments[0].id, payments[1].id, payments[2].id,
+	}, visited)
+
+	// A dry run reports the failed attempts of the payments that aren't
+	// in flight, but doesn't delete them.
+	dryRunStats, err := db.PruneFailedAttempts(cutoff, true)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, dryRunStats.NumPayments)
+	require.EqualValues(t, 3, dryRunStats.NumAttempts)
+	require.NotZero(t, dryRunStats.NumBytes)
+	assertPayments(t, db, payments)
+
+	// Pruning deletes exactly what the dry run reported.
+	stats, err = db.PruneFailedAttempts(cutoff, false)
+	require.NoError(t, err)
+	require.Equal(t, dryRunStats, stats)
+
+	payments[0].htlcs = 0
+	payments[1].htlcs = 1
+	assertPayments(t, db, payments)
+
+	// There is nothing left to prune.
+	stats, err = db.PruneFailedAttempts(cutoff, false)
+	require.NoError(t, err)
+	require.Equal(t, &PruneFailedAttemptsStats{}, stats)
+}
+
 // TestPaymentControlDeleteSinglePayment tests that DeletePayment correctly
 // deletes information about a completed payment from the database.
 func TestPaymentControlDeleteSinglePayment(t *testing.T) {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"sort"
 	"time"
 
@@ -101,8 +102,33 @@ var (
 	// 	|--...
 	// 	|--<sequence-number>: <payment hash>
 	paymentsIndexBucket = []byte("payments-index-bucket")
+
+	// paymentsCreationIndexBucket is the name of the top-level bucket
+	// within the database that indexes the payments by their creation
+	// time. The value of an entry flags whether the payment has a settled
+	// HTLC, so that queries for completed payments in a range of time only
+	// need to fetch the payments that they return.
+	// payments-creation-index-bucket
+	// 	|--<creation time unix nano><sequence-number>: <settled flag>
+	// 	|--...
+	// 	|--<creation time unix nano><sequence-number>: <settled flag>
+	paymentsCreationIndexBucket = []byte("payments-creation-index-bucket")
 )
 
+const (
+	// creationIndexSettled is the value of a creation index entry of a
+	// payment that has a settled HTLC.
+	creationIndexSettled byte = 1
+
+	// creationIndexNotSettled is the value of a creation index entry of a
+	// payment that doesn't have a settled HTLC.
+	creationIndexNotSettled byte = 0
+)
+
+// maxCreationIndexTime is the latest creation time that can be represented
+// in the creation index.
+var maxCreationIndexTime = time.Unix(0, math.MaxInt64)
+
 var (
 	// ErrNoSequenceNumber is returned if we lookup a payment which does
 	// not have a sequence number.
@@ -147,7 +173,9 @@ const (
 	// balance to complete the payment.
 	FailureReasonInsufficientBalance FailureReason = 4
 
-	// TODO(halseth): cancel state.
+	// FailureReasonCanceled indicates that the payment was canceled by the
+	// user.
+	FailureReasonCanceled FailureReason = 5
 
 	// TODO(joostjager): Add failure reasons for:
 	// LocalLiquidityInsufficient, RemoteCapacityInsufficient.
@@ -171,6 +199,8 @@ func (r FailureReason) String() string {
 		return "incorrect_payment_details"
 	case FailureReasonInsufficientBalance:
 		return "insufficient_balance"
+	case FailureReasonCanceled:
+		return "canceled"
 	}
 
 	return "unknown"
@@ -191,6 +221,11 @@ type PaymentCreationInfo struct {
 
 	// PaymentRequest is the full payment request, if any.
 	PaymentRequest []byte
+
+	// MissionControlNamespace is the mission control namespace that the
+	// results of the payment's attempts are reported to. An empty string
+	// refers to the default namespace.
+	MissionControlNamespace string
 }
 
 // htlcBucketKey creates a composite key from prefix and id where the result is
@@ -581,16 +616,53 @@ func (d *DB) QueryPayments(query PaymentsQuery) (PaymentsResponse, error) {
 			return true, nil
 		}
 
-		// Create a paginator which reads from our sequence index bucket
-		// with the parameters provided by the payments query.
-		paginator := newPaginator(
-			indexes.ReadCursor(), query.Reversed, query.IndexOffset,
-			query.MaxPayments,
-		)
+		// If the query is limited to a creation date range, we use the
+		// creation index to only fetch the payments within the range.
+		// Otherwise, we read them from the sequence index directly.
+		if query.CreationDateStart != 0 || query.CreationDateEnd != 0 {
+			var numPayments uint64
+			accumulate := func(seqNum []byte) (bool, error) {
+				if numPayments >= query.MaxPayments {
+					return false, nil
+				}
 
-		// Run a paginated query, adding payments to our response.
-		if err := paginator.query(accumulatePayments); err != nil {
-			return err
+				hash := indexes.Get(seqNum)
+				if hash == nil {
+					return false, fmt.Errorf("sequence "+
+						"number %x not indexed", seqNum)
+				}
+
+				added, err := accumulatePayments(seqNum, hash)
+				if err != nil {
+					return false, err
+				}
+
+				if added {
+					numPayments++
+				}
+
+				return numPayments < query.MaxPayments, nil
+			}
+
+			err := queryCreationIndex(tx, query, accumulate)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Create a paginator which reads from our sequence
+			// index bucket with the parameters provided by the
+			// payments query.
+			paginator := newPaginator(
+				indexes.ReadCursor(), query.Reversed,
+				query.IndexOffset, query.MaxPayments,
+			)
+
+			// Run a paginated query, adding payments to our
+			// response.
+			err := paginator.query(accumulatePayments)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Counting the total number of payments is expensive, since we
@@ -800,6 +872,11 @@ func (d *DB) DeletePayment(paymentHash lntypes.Hash,
 			return err
 		}
 
+		creationKeys, err := fetchCreationIndexKeys(bucket)
+		if err != nil {
+			return err
+		}
+
 		if err := payments.DeleteNestedBucket(paymentHash[:]); err != nil {
 			return err
 		}
@@ -811,6 +888,13 @@ func (d *DB) DeletePayment(paymentHash lntypes.Hash,
 			}
 		}
 
+		creationIndex := tx.ReadWriteBucket(paymentsCreationIndexBucket)
+		for _, k := range creationKeys {
+			if err := creationIndex.Delete(k); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}, func() {})
 }
@@ -835,6 +919,10 @@ func (d *DB) DeletePayments(failedOnly, failedHtlcsOnly bool) error {
 			// payments that need to be deleted.
 			deleteIndexes [][]byte
 
+			// deleteCreationIndexes is the set of creation index
+			// entries of these payments that need to be deleted.
+			deleteCreationIndexes [][]byte
+
 			// deleteHtlcs maps a payment hash to the HTLC IDs we
 			// want to delete for that payment.
 			deleteHtlcs = make(map[lntypes.Hash][][]byte)
@@ -897,6 +985,16 @@ func (d *DB) DeletePayments(failedOnly, failedHtlcsOnly bool) error {
 			}
 
 			deleteIndexes = append(deleteIndexes, seqNrs...)
+
+			creationKeys, err := fetchCreationIndexKeys(bucket)
+			if err != nil {
+				return err
+			}
+
+			deleteCreationIndexes = append(
+				deleteCreationIndexes, creationKeys...,
+			)
+
 			return nil
 		})
 		if err != nil {
@@ -946,10 +1044,288 @@ func (d *DB) DeletePayments(failedOnly, failedHtlcsOnly bool) error {
 			}
 		}
 
+		creationIndex := tx.ReadWriteBucket(paymentsCreationIndexBucket)
+		for _, k := range deleteCreationIndexes {
+			if err := creationIndex.Delete(k); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}, func() {})
 }
 
+// PruneFailedAttemptsStats summarizes the failed HTLC attempts that were
+// pruned, or that would be pruned in a dry run.
+type PruneFailedAttemptsStats struct {
+	// NumPayments is the number of payments that had failed attempts.
+	NumPayments uint64
+
+	// NumAttempts is the number of failed attempts.
+	NumAttempts uint64
+
+	// NumBytes is the total size of the keys and values of the failed
+	// attempts. Note that the space is only reclaimed on disk once the
+	// database is compacted.
+	NumBytes uint64
+}
+
+// pruneFailedAttemptsBatchSize is the maximum number of payments whose failed
+// HTLC attempts are pruned within a single database transaction.
+const pruneFailedAttemptsBatchSize = 1000
+
+// PruneFailedAttempts deletes the failed HTLC attempts of all payments that
+// were created before the given time, while the payments themselves are kept.
+// Payments that are still in flight are skipped. If dryRun is set, nothing is
+// deleted and only the statistics of what would be pruned are returned.
+//
+// The payments are pruned in batches of separate transactions, each resuming
+// from the creation index key of the last payment of the previous batch, so
+// that pruning a large payments database doesn't block it for long.
+func (d *DB) PruneFailedAttempts(createdBefore time.Time,
+	dryRun bool) (*PruneFailedAttemptsStats, error) {
+
+	var (
+		stats = &PruneFailedAttemptsStats{}
+		after []byte
+	)
+	for {
+		var (
+			batch *failedAttemptsBatch
+			err   error
+		)
+		if dryRun {
+			err = kvdb.View(d, func(tx kvdb.RTx) error {
+				batch, err = fetchFailedAttemptsBatch(
+					tx, createdBefore, after,
+				)
+
+				return err
+			}, func() {
+				batch = nil
+			})
+		} else {
+			err = kvdb.Update(d, func(tx kvdb.RwTx) error {
+				batch, err = fetchFailedAttemptsBatch(
+					tx, createdBefore, after,
+				)
+				if err != nil {
+					return err
+				}
+
+				return batch.delete(tx)
+			}, func() {
+				batch = nil
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		stats.NumPayments += batch.stats.NumPayments
+		stats.NumAttempts += batch.stats.NumAttempts
+		stats.NumBytes += batch.stats.NumBytes
+
+		if batch.next == nil {
+			return stats, nil
+		}
+		after = batch.next
+	}
+}
+
+// failedAttemptsBatch holds the failed HTLC attempts of a batch of payments.
+type failedAttemptsBatch struct {
+	// keys are the keys of the failed attempts, keyed by the hash of
+	// their payment.
+	keys map[lntypes.Hash][][]byte
+
+	// stats summarizes the failed attempts of the batch.
+	stats PruneFailedAttemptsStats
+
+	// next is the creation index key of the last payment of the batch,
+	// from which the next batch resumes. It is nil if there are no more
+	// payments left.
+	next []byte
+}
+
+// fetchFailedAttemptsBatch returns the failed HTLC attempts of the next batch
+// of payments that were created before the given time, starting after the
+// given creation index key.
+func fetchFailedAttemptsBatch(tx kvdb.RTx, createdBefore time.Time,
+	after []byte) (*failedAttemptsBatch, error) {
+
+	// htlcKeyPrefixes are the prefixes of all keys of an HTLC attempt.
+	htlcKeyPrefixes := [][]byte{
+		htlcAttemptInfoKey, htlcFailInfoKey, htlcSettleInfoKey,
+	}
+
+	batch := &failedAttemptsBatch{
+		keys: make(map[lntypes.Hash][][]byte),
+	}
+
+	payments := tx.ReadBucket(paymentsRootBucket)
+	if payments == nil {
+		return batch, nil
+	}
+
+	hashes, next, err := fetchPaymentsCreatedBefore(
+		tx, createdBefore, after, pruneFailedAttemptsBatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	batch.next = next
+
+	for _, hash := range hashes {
+		bucket := payments.NestedReadBucket(hash[:])
+		if bucket == nil {
+			return nil, fmt.Errorf("payment %v not found", hash)
+		}
+
+		paymentStatus, err := fetchPaymentStatus(bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		// We can't safely alter payments that are in flight.
+		if err := paymentStatus.removable(); err != nil {
+			continue
+		}
+
+		failed, err := fetchFailedHtlcKeys(bucket)
+		if err != nil {
+			return nil, err
+		}
+		if len(failed) == 0 {
+			continue
+		}
+
+		batch.stats.NumPayments++
+		batch.stats.NumAttempts += uint64(len(failed))
+
+		htlcsBucket := bucket.NestedReadBucket(paymentHtlcsBucket)
+		for _, aid := range failed {
+			for _, prefix := range htlcKeyPrefixes {
+				key := htlcBucketKey(prefix, aid)
+				value := htlcsBucket.Get(key)
+				if value == nil {
+					continue
+				}
+
+				batch.stats.NumBytes += uint64(
+					len(key) + len(value),
+				)
+				batch.keys[hash] = append(batch.keys[hash], key)
+			}
+		}
+	}
+
+	return batch, nil
+}
+
+// delete deletes the failed HTLC attempts of the batch.
+func (b *failedAttemptsBatch) delete(tx kvdb.RwTx) error {
+	payments := tx.ReadWriteBucket(paymentsRootBucket)
+	if payments == nil {
+		return nil
+	}
+
+	for hash, keys := range b.keys {
+		bucket := payments.NestedReadWriteBucket(hash[:])
+		if bucket == nil {
+			return fmt.Errorf("payment %v not found", hash)
+		}
+
+		htlcsBucket := bucket.NestedReadWriteBucket(paymentHtlcsBucket)
+		if htlcsBucket == nil {
+			return fmt.Errorf("htlcs of payment %v not found", hash)
+		}
+
+		for _, key := range keys {
+			if err := htlcsBucket.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fetchPaymentsCreatedBefore returns the hashes of up to limit payments that
+// were created before the given time, starting after the given creation index
+// key, using the creation index. Duplicate payments are skipped, as they only
+// hold a single attempt. The creation index key of the last visited payment is
+// returned as well, or nil if there are no more payments left.
+func fetchPaymentsCreatedBefore(tx kvdb.RTx, createdBefore time.Time,
+	after []byte, limit int) ([]lntypes.Hash, []byte, error) {
+
+	creationIndex := tx.ReadBucket(paymentsCreationIndexBucket)
+	if creationIndex == nil {
+		return nil, nil, fmt.Errorf("creation index bucket does not " +
+			"exist")
+	}
+
+	indexes := tx.ReadBucket(paymentsIndexBucket)
+	if indexes == nil {
+		return nil, nil, fmt.Errorf("index bucket does not exist")
+	}
+
+	end := creationIndexKey(createdBefore, nil)
+
+	cursor := creationIndex.ReadCursor()
+	k, _ := cursor.First()
+	if after != nil {
+		k, _ = cursor.Seek(after)
+		if bytes.Equal(k, after) {
+			k, _ = cursor.Next()
+		}
+	}
+
+	var (
+		hashes  []lntypes.Hash
+		lastKey []byte
+	)
+	for ; k != nil; k, _ = cursor.Next() {
+		if bytes.Compare(k[:8], end) >= 0 {
+			break
+		}
+
+		// The keys are only valid within the transaction, so we copy
+		// the key the next batch resumes from.
+		if lastKey != nil && len(hashes) == limit {
+			return hashes, append([]byte(nil), lastKey...), nil
+		}
+		lastKey = k
+
+		seqNum := k[8:]
+		indexValue := indexes.Get(seqNum)
+		if indexValue == nil {
+			return nil, nil, fmt.Errorf("sequence number %x not "+
+				"indexed", seqNum)
+		}
+
+		hash, err := deserializePaymentIndex(
+			bytes.NewReader(indexValue),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		bucket, err := fetchPaymentBucket(tx, hash)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !bytes.Equal(bucket.Get(paymentSequenceKey), seqNum) {
+			continue
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil, nil
+}
+
 // fetchSequenceNumbers fetches all the sequence numbers associated with a
 // payment, including those belonging to any duplicate payments.
 func fetchSequenceNumbers(paymentBucket kvdb.RBucket) ([][]byte, error) {
@@ -980,6 +1356,193 @@ func fetchSequenceNumbers(paymentBucket kvdb.RBucket) ([][]byte, error) {
 	return sequenceNumbers, nil
 }
 
+// creationIndexKey returns the key of the creation index entry of the payment
+// with the given creation time and sequence number.
+func creationIndexKey(creationTime time.Time, sequenceNumber []byte) []byte {
+	// Calling UnixNano() on a time that can't be represented in unix
+	// nanoseconds yields an undefined result. We use zero for the zero
+	// time as done for the serialized creation info, and clamp times
+	// beyond the representable range.
+	var unixNano int64
+	switch {
+	case creationTime.IsZero():
+
+	case creationTime.After(maxCreationIndexTime):
+		unixNano = math.MaxInt64
+
+	default:
+		unixNano = creationTime.UnixNano()
+	}
+
+	key := make([]byte, 8+len(sequenceNumber))
+	byteOrder.PutUint64(key[:8], uint64(unixNano))
+	copy(key[8:], sequenceNumber)
+
+	return key
+}
+
+// putCreationIndexEntry adds or updates the creation index entry of a payment.
+func putCreationIndexEntry(tx kvdb.RwTx, creationTime time.Time,
+	sequenceNumber []byte, settled bool) error {
+
+	index := tx.ReadWriteBucket(paymentsCreationIndexBucket)
+	if index == nil {
+		return fmt.Errorf("creation index bucket does not exist")
+	}
+
+	value := creationIndexNotSettled
+	if settled {
+		value = creationIndexSettled
+	}
+
+	return index.Put(
+		creationIndexKey(creationTime, sequenceNumber), []byte{value},
+	)
+}
+
+// fetchCreationIndexKeys fetches the creation index keys of a payment,
+// including those belonging to any duplicate payments.
+func fetchCreationIndexKeys(paymentBucket kvdb.RBucket) ([][]byte, error) {
+	seqNum := paymentBucket.Get(paymentSequenceKey)
+	if seqNum == nil {
+		return nil, errors.New("expected sequence number")
+	}
+
+	info, err := fetchCreationInfo(paymentBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := [][]byte{creationIndexKey(info.CreationTime, seqNum)}
+
+	duplicates := paymentBucket.NestedReadBucket(duplicatePaymentsBucket)
+	if duplicates == nil {
+		return keys, nil
+	}
+
+	err = duplicates.ForEach(func(k, _ []byte) error {
+		duplicate := duplicates.NestedReadBucket(k)
+		if duplicate == nil {
+			return ErrNoDuplicateNestedBucket
+		}
+
+		b := duplicate.Get(duplicatePaymentCreationInfoKey)
+		if b == nil {
+			return fmt.Errorf("creation info not found")
+		}
+
+		info, err := deserializeDuplicatePaymentCreationInfo(
+			bytes.NewReader(b),
+		)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, creationIndexKey(info.CreationTime, k))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// queryCreationIndex walks the creation index over the creation date range of
+// the query and calls the given function with the sequence number of every
+// payment that may match the status filter and lies within the pagination
+// window of the query. The payments are visited in the order of their
+// creation, or in reverse order for reversed queries, until the function
+// returns false or an error. Only the keys of the creation index are read, so
+// that only the payments that are returned have to be fetched.
+func queryCreationIndex(tx kvdb.RTx, query PaymentsQuery,
+	cb func(seqNum []byte) (bool, error)) error {
+
+	index := tx.ReadBucket(paymentsCreationIndexBucket)
+	if index == nil {
+		return fmt.Errorf("creation index bucket does not exist")
+	}
+
+	// Payments without a creation time have a zero key and are never
+	// returned, as their creation time lies before any valid start date.
+	const maxSeconds = math.MaxInt64 / int64(time.Second)
+	start := int64(math.MaxInt64)
+	if query.CreationDateStart <= maxSeconds {
+		start = max(query.CreationDateStart*int64(time.Second), 1)
+	}
+
+	// The end date is inclusive, so every payment that was created within
+	// the last second of the range is returned as well.
+	end := int64(math.MaxInt64)
+	if query.CreationDateEnd != 0 && query.CreationDateEnd < maxSeconds {
+		end = (query.CreationDateEnd+1)*int64(time.Second) - 1
+	}
+
+	cursor := index.ReadCursor()
+
+	// first positions the cursor at the first entry of the range in the
+	// order of the query, and next advances it.
+	first := func() ([]byte, []byte) {
+		var startKey [8]byte
+		byteOrder.PutUint64(startKey[:], uint64(start))
+
+		return cursor.Seek(startKey[:])
+	}
+	next := cursor.Next
+	if query.Reversed {
+		first = func() ([]byte, []byte) {
+			// All keys of the last nanosecond of the range sort
+			// before the key of the following nanosecond.
+			var endKey [8]byte
+			byteOrder.PutUint64(endKey[:], uint64(end)+1)
+
+			if k, _ := cursor.Seek(endKey[:]); k == nil {
+				return cursor.Last()
+			}
+
+			return cursor.Prev()
+		}
+		next = cursor.Prev
+	}
+
+	for k, v := first(); k != nil; k, v = next() {
+		if len(k) != 16 || len(v) != 1 {
+			return fmt.Errorf("invalid creation index entry %x", k)
+		}
+
+		creationTime := int64(byteOrder.Uint64(k[:8]))
+		if creationTime > end || creationTime < start {
+			break
+		}
+
+		if !query.IncludeIncomplete && v[0] != creationIndexSettled {
+			continue
+		}
+
+		seqNum := byteOrder.Uint64(k[8:])
+		switch {
+		case !query.Reversed && seqNum <= query.IndexOffset:
+			continue
+
+		case query.Reversed && query.IndexOffset != 0 &&
+			seqNum >= query.IndexOffset:
+
+			continue
+		}
+
+		more, err := cb(k[8:])
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+
+	return nil
+}
+
 // nolint: dupl
 func serializePaymentCreationInfo(w io.Writer, c *PaymentCreationInfo) error {
 	var scratch [8]byte
@@ -1006,7 +1569,23 @@ func serializePaymentCreationInfo(w io.Writer, c *PaymentCreationInfo) error {
 		return err
 	}
 
-	return nil
+	// The namespace is an optional trailing field, so that the creation
+	// info of payments in the default namespace is serialized the same way
+	// as before.
+	if c.MissionControlNamespace == "" {
+		return nil
+	}
+
+	byteOrder.PutUint32(
+		scratch[:4], uint32(len(c.MissionControlNamespace)),
+	)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	_, err := w.Write([]byte(c.MissionControlNamespace))
+
+	return err
 }
 
 func deserializePaymentCreationInfo(r io.Reader) (*PaymentCreationInfo, error) {
@@ -1042,6 +1621,23 @@ func deserializePaymentCreationInfo(r io.Reader) (*PaymentCreationInfo, error) {
 	}
 	c.PaymentRequest = payReq
 
+	// Creation infos without a mission control namespace end here.
+	_, err = io.ReadFull(r, scratch[:4])
+	switch {
+	case err == io.EOF:
+		return c, nil
+
+	case err != nil:
+		return nil, err
+	}
+
+	nsLen := byteOrder.Uint32(scratch[:4])
+	namespace := make([]byte, nsLen)
+	if _, err := io.ReadFull(r, namespace); err != nil {
+		return nil, err
+	}
+	c.MissionControlNamespace = string(namespace)
+
 	return c, nil
 }
 
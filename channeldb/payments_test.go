@@ -138,6 +138,15 @@ func TestSentPaymentSerialization(t *testing.T) {
 		)
 	}
 
+	// The mission control namespace is restored if set.
+	b.Reset()
+	c.MissionControlNamespace = "rebalance"
+	require.NoError(t, serializePaymentCreationInfo(&b, c))
+
+	newCreationInfo, err = deserializePaymentCreationInfo(&b)
+	require.NoError(t, err)
+	require.Equal(t, c, newCreationInfo)
+
 	b.Reset()
 	if err := serializeHTLCAttemptInfo(&b, s); err != nil {
 		t.Fatalf("unable to serialize info: %v", err)
@@ -209,12 +218,27 @@ func deletePayment(t *testing.T, db *DB, paymentHash lntypes.Hash, seqNr uint64)
 	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
 		payments := tx.ReadWriteBucket(paymentsRootBucket)
 
+		creationKeys, err := fetchCreationIndexKeys(
+			payments.NestedReadBucket(paymentHash[:]),
+		)
+		if err != nil {
+			return err
+		}
+
 		// Delete the payment bucket.
-		err := payments.DeleteNestedBucket(paymentHash[:])
+		err = payments.DeleteNestedBucket(paymentHash[:])
 		if err != nil {
 			return err
 		}
 
+		// Delete the creation index entries of the payment.
+		creationIndex := tx.ReadWriteBucket(paymentsCreationIndexBucket)
+		for _, k := range creationKeys {
+			if err := creationIndex.Delete(k); err != nil {
+				return err
+			}
+		}
+
 		key := make([]byte, 8)
 		byteOrder.PutUint64(key, seqNr)
 
@@ -472,6 +496,34 @@ func TestQueryPayments(t *testing.T) {
 			lastIndex:      5,
 			expectedSeqNrs: []uint64{3, 4, 5},
 		},
+		{
+			name: "query in reverse order, with start creation " +
+				"time and max payments",
+			query: PaymentsQuery{
+				IndexOffset:       0,
+				MaxPayments:       2,
+				Reversed:          true,
+				IncludeIncomplete: true,
+				CreationDateStart: 3,
+			},
+			firstIndex:     6,
+			lastIndex:      7,
+			expectedSeqNrs: []uint64{6, 7},
+		},
+		{
+			name: "query in reverse order, with end creation " +
+				"time and index offset",
+			query: PaymentsQuery{
+				IndexOffset:       5,
+				MaxPayments:       2,
+				Reversed:          true,
+				IncludeIncomplete: true,
+				CreationDateEnd:   5,
+			},
+			firstIndex:     3,
+			lastIndex:      4,
+			expectedSeqNrs: []uint64{3, 4},
+		},
 	}
 
 	for _, tt := range tests {
@@ -591,6 +643,102 @@ func TestQueryPayments(t *testing.T) {
 	}
 }
 
+// TestPaymentCreationIndex tests that the creation index is kept in sync
+// with the payments and used to filter queries for completed payments.
+func TestPaymentCreationIndex(t *testing.T) {
+	t.Parallel()
+
+	db, err := MakeTestDB(t)
+	require.NoError(t, err)
+
+	pControl := NewPaymentControl(db)
+
+	payments := []*payment{
+		{status: StatusFailed},
+		{status: StatusSucceeded},
+		{status: StatusInFlight},
+	}
+	createTestPayments(t, pControl, payments)
+
+	// assertIndex asserts that the creation index holds exactly the
+	// given sequence numbers, along with their settled flag.
+	assertIndex := func(expected map[uint64]bool) {
+		t.Helper()
+
+		index := make(map[uint64]bool)
+		err := kvdb.View(db, func(tx kvdb.RTx) error {
+			bucket := tx.ReadBucket(paymentsCreationIndexBucket)
+
+			return bucket.ForEach(func(k, v []byte) error {
+				seqNum := byteOrder.Uint64(k[8:])
+				index[seqNum] = v[0] == creationIndexSettled
+
+				return nil
+			})
+		}, func() {
+			index = make(map[uint64]bool)
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected, index)
+	}
+
+	seqNum := func(hash lntypes.Hash) uint64 {
+		t.Helper()
+
+		p, err := pControl.FetchPayment(hash)
+		require.NoError(t, err)
+
+		return p.SequenceNum
+	}
+
+	failedSeq := seqNum(payments[0].id)
+	settledSeq := seqNum(payments[1].id)
+	inFlightSeq := seqNum(payments[2].id)
+	assertIndex(map[uint64]bool{
+		failedSeq:   false,
+		settledSeq:  true,
+		inFlightSeq: false,
+	})
+
+	// Only the settled payment is returned if incomplete payments are
+	// excluded.
+	resp, err := db.QueryPayments(PaymentsQuery{
+		MaxPayments: math.MaxUint64,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Payments, 1)
+	require.Equal(t, settledSeq, resp.Payments[0].SequenceNum)
+
+	// Retrying the failed payment replaces its index entry.
+	info, _, _, err := genInfo()
+	require.NoError(t, err)
+	info.PaymentIdentifier = payments[0].id
+
+	err = pControl.InitPayment(info.PaymentIdentifier, info)
+	require.NoError(t, err)
+
+	retriedSeq := seqNum(payments[0].id)
+	require.NotEqual(t, failedSeq, retriedSeq)
+	assertIndex(map[uint64]bool{
+		retriedSeq:  false,
+		settledSeq:  true,
+		inFlightSeq: false,
+	})
+
+	// Deleting the payments that aren't in flight removes their index
+	// entries as well.
+	require.NoError(t, db.DeletePayments(false, false))
+	assertIndex(map[uint64]bool{
+		inFlightSeq: false,
+	})
+
+	resp, err = db.QueryPayments(PaymentsQuery{
+		MaxPayments: math.MaxUint64,
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Payments)
+}
+
 // TestFetchPaymentWithSequenceNumber tests lookup of payments with their
 // sequence number. It sets up one payment with no duplicates, and another with
 // two duplicates in its duplicates bucket then uses these payments to test the
@@ -748,6 +896,21 @@ func appendDuplicatePayment(t *testing.T, db *DB, paymentHash lntypes.Hash,
 		err = createPaymentIndexEntry(tx, sequenceKey[:], paymentHash)
 		require.NoError(t, err)
 
+		// Duplicate payments are added to the creation index by the
+		// migration that created the index.
+		dupPayment := dup.NestedReadBucket(sequenceKey[:])
+		info, err := deserializeDuplicatePaymentCreationInfo(
+			bytes.NewReader(
+				dupPayment.Get(duplicatePaymentCreationInfoKey),
+			),
+		)
+		require.NoError(t, err)
+
+		err = putCreationIndexEntry(
+			tx, info.CreationTime, sequenceKey[:], true,
+		)
+		require.NoError(t, err)
+
 		return nil
 	}, func() {})
 	require.NoError(t, err, "could not create payment")
@@ -20,6 +20,7 @@ var (
 	//      |
 	//      |-- <peer-pubkey>
 	//      |        |--flap-count-key: <ts><flap count>
+	//      |        |--channel-policy-key: <zero conf><scid alias>
 	//      |
 	//      |-- <peer-pubkey>
 	//      |        |--flap-count-key: <ts><flap count>
@@ -29,6 +30,10 @@ var (
 	// the timestamp of a peer's last flap count and its all time flap
 	// count.
 	flapCountKey = []byte("flap-count")
+
+	// channelPolicyKey is a key used in the peer pubkey sub-bucket that
+	// stores the policy we apply to inbound channels opened by the peer.
+	channelPolicyKey = []byte("channel-policy")
 )
 
 var (
@@ -121,3 +126,106 @@ func (d *DB) ReadFlapCount(pubkey route.Vertex) (*FlapCount, error) {
 
 	return &flapCount, nil
 }
+
+// PeerChannelPolicy is the policy we apply to inbound channels opened by a
+// specific peer.
+type PeerChannelPolicy struct {
+	// AllowZeroConf indicates that we accept zero-conf channels from the
+	// peer.
+	AllowZeroConf bool
+
+	// RequireScidAlias indicates that we only accept channels from the
+	// peer that negotiate the scid-alias channel type.
+	RequireScidAlias bool
+}
+
+// WritePeerChannelPolicy writes the channel policy for a peer to disk,
+// creating a bucket for the peer's pubkey if necessary. Note that this
+// function overwrites the current value.
+func (d *DB) WritePeerChannelPolicy(pubkey route.Vertex,
+	policy *PeerChannelPolicy) error {
+
+	return kvdb.Update(d, func(tx kvdb.RwTx) error {
+		peers := tx.ReadWriteBucket(peersBucket)
+
+		peerBucket, err := peers.CreateBucketIfNotExists(pubkey[:])
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		err = WriteElements(
+			&b, policy.AllowZeroConf, policy.RequireScidAlias,
+		)
+		if err != nil {
+			return err
+		}
+
+		return peerBucket.Put(channelPolicyKey, b.Bytes())
+	}, func() {})
+}
+
+// DeletePeerChannelPolicy removes the channel policy of a peer from disk. It
+// is a no-op if no policy is stored for the peer.
+func (d *DB) DeletePeerChannelPolicy(pubkey route.Vertex) error {
+	return kvdb.Update(d, func(tx kvdb.RwTx) error {
+		peers := tx.ReadWriteBucket(peersBucket)
+
+		peerBucket := peers.NestedReadWriteBucket(pubkey[:])
+		if peerBucket == nil {
+			return nil
+		}
+
+		return peerBucket.Delete(channelPolicyKey)
+	}, func() {})
+}
+
+// FetchPeerChannelPolicies returns the channel policies of all peers that
+// have one stored.
+func (d *DB) FetchPeerChannelPolicies() (map[route.Vertex]*PeerChannelPolicy,
+	error) {
+
+	var policies map[route.Vertex]*PeerChannelPolicy
+
+	if err := kvdb.View(d, func(tx kvdb.RTx) error {
+		peers := tx.ReadBucket(peersBucket)
+
+		return peers.ForEach(func(k, v []byte) error {
+			// Every peer is stored in its own sub-bucket, so we
+			// skip any non-bucket entries.
+			if v != nil {
+				return nil
+			}
+
+			peerBucket := peers.NestedReadBucket(k)
+			policyBytes := peerBucket.Get(channelPolicyKey)
+			if policyBytes == nil {
+				return nil
+			}
+
+			pubkey, err := route.NewVertexFromBytes(k)
+			if err != nil {
+				return err
+			}
+
+			var policy PeerChannelPolicy
+			err = ReadElements(
+				bytes.NewReader(policyBytes),
+				&policy.AllowZeroConf, &policy.RequireScidAlias,
+			)
+			if err != nil {
+				return err
+			}
+
+			policies[pubkey] = &policy
+
+			return nil
+		})
+	}, func() {
+		policies = make(map[route.Vertex]*PeerChannelPolicy)
+	}); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
@@ -47,3 +47,55 @@ func TestFlapCount(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, peer2FlapCount, count)
 }
+
+// TestPeerChannelPolicy tests writing, fetching and deleting of peer channel
+// policies.
+func TestPeerChannelPolicy(t *testing.T) {
+	db, err := MakeTestDB(t)
+	require.NoError(t, err)
+
+	// Without any stored policies, we expect an empty result.
+	policies, err := db.FetchPeerChannelPolicies()
+	require.NoError(t, err)
+	require.Empty(t, policies)
+
+	// Deleting the policy of an unknown peer is a no-op.
+	require.NoError(t, db.DeletePeerChannelPolicy(testPub))
+
+	var (
+		testPub2 = route.Vertex{2, 2, 2}
+		policy1  = &PeerChannelPolicy{
+			AllowZeroConf: true,
+		}
+		policy2 = &PeerChannelPolicy{
+			RequireScidAlias: true,
+		}
+	)
+
+	require.NoError(t, db.WritePeerChannelPolicy(testPub, policy1))
+	require.NoError(t, db.WritePeerChannelPolicy(testPub2, policy2))
+
+	// A peer that only has a flap count stored shouldn't show up.
+	err = db.WriteFlapCounts(map[route.Vertex]*FlapCount{
+		{3, 3, 3}: {Count: 1},
+	})
+	require.NoError(t, err)
+
+	policies, err = db.FetchPeerChannelPolicies()
+	require.NoError(t, err)
+	require.Equal(t, map[route.Vertex]*PeerChannelPolicy{
+		testPub:  policy1,
+		testPub2: policy2,
+	}, policies)
+
+	// Overwrite the first policy and delete the second one.
+	policy1.RequireScidAlias = true
+	require.NoError(t, db.WritePeerChannelPolicy(testPub, policy1))
+	require.NoError(t, db.DeletePeerChannelPolicy(testPub2))
+
+	policies, err = db.FetchPeerChannelPolicies()
+	require.NoError(t, err)
+	require.Equal(t, map[route.Vertex]*PeerChannelPolicy{
+		testPub: policy1,
+	}, policies)
+}
@@ -28,6 +28,7 @@ var importMissionControlCommand = cli.Command{
 			Name:  "force",
 			Usage: "whether to force the history entry import",
 		},
+		mcNamespaceFlag,
 	},
 }
 
@@ -92,6 +93,8 @@ func importMissionControl(ctx *cli.Context) error {
 			importResult,
 		},
 		Force: ctx.IsSet("force"),
+
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
 	}
 
 	rpcCtx := context.Background()
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lncfg"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/urfave/cli"
+)
+
+// probeResult is a single result of an external probing tool as it is read
+// from an import file.
+type probeResult struct {
+	// Timestamp is the unix time in seconds at which the result was
+	// observed.
+	Timestamp int64 `json:"timestamp"`
+
+	// From is the hex encoded pubkey of the node that forwarded the
+	// probe.
+	From string `json:"from"`
+
+	// To is the hex encoded pubkey of the node that the probe was
+	// forwarded to.
+	To string `json:"to"`
+
+	// AmtMsat is the amount that was forwarded from the first to the
+	// second node.
+	AmtMsat int64 `json:"amt_msat"`
+
+	// Success indicates whether the amount could be forwarded.
+	Success bool `json:"success"`
+}
+
+// probeResultsFile is the format of a file of external probing results.
+type probeResultsFile struct {
+	Results []probeResult `json:"results"`
+}
+
+var importMissionControlProbesCommand = cli.Command{
+	Name:      "importmcprobes",
+	Category:  "Mission Control",
+	Usage:     "Import the results of an external probing tool.",
+	ArgsUsage: "results-file",
+	Description: `
+	Import the pair history observed by an external probing tool into the
+	internal mission control state. The results are read from a JSON file
+	of the following format:
+
+	{
+	    "results": [
+	        {
+	            "timestamp": 1700000000,
+	            "from": "02ab...",
+	            "to": "03cd...",
+	            "amt_msat": 250000000,
+	            "success": true
+	        }
+	    ]
+	}
+
+	Every result states whether the amount could be forwarded from the
+	first to the second node at the given unix time in seconds. A failure
+	with an amount of zero means that the pair failed independently of the
+	amount. The results of a pair are replayed in the order of their
+	timestamps in the same way mission control applies its own results,
+	so that only the latest success and failure amounts of every pair are
+	imported.
+
+	The imported results can be weighted against the results that mission
+	control observed itself. Results that are older than --max_age are
+	skipped. With --age_penalty, the imported results are treated as if
+	they were observed earlier than they actually were, so that their
+	effect on path finding wears off sooner.
+	`,
+	Flags: []cli.Flag{
+		cli.DurationFlag{
+			Name: "max_age",
+			Usage: "skip results that are older than the given " +
+				"duration, 0 means that all results are " +
+				"imported",
+		},
+		cli.DurationFlag{
+			Name: "age_penalty",
+			Usage: "the duration by which the imported results " +
+				"are backdated",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "whether to force the history entry import",
+		},
+		mcNamespaceFlag,
+	},
+	Action: actionDecorator(importMissionControlProbes),
+}
+
+func importMissionControlProbes(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "importmcprobes")
+	}
+
+	data, err := os.ReadFile(lncfg.CleanAndExpandPath(ctx.Args().First()))
+	if err != nil {
+		return fmt.Errorf("unable to read results file: %w", err)
+	}
+
+	var file probeResultsFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("unable to decode results file: %w", err)
+	}
+
+	if ctx.Duration("max_age") < 0 || ctx.Duration("age_penalty") < 0 {
+		return errors.New("max_age and age_penalty must not be " +
+			"negative")
+	}
+
+	pairs, err := normalizeProbeResults(
+		file.Results, time.Now(), ctx.Duration("max_age"),
+		ctx.Duration("age_penalty"),
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(pairs) == 0 {
+		return errors.New("no results to import")
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	req := &routerrpc.XImportMissionControlRequest{
+		Pairs: pairs,
+		Force: ctx.Bool("force"),
+
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+	}
+	if _, err := client.XImportMissionControl(ctxc, req); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported the results of %v pairs\n", len(pairs))
+
+	return nil
+}
+
+// probePairState is the normalized state of a pair while its results are
+// replayed.
+type probePairState struct {
+	successAmt  lnwire.MilliSatoshi
+	successTime time.Time
+	failAmt     lnwire.MilliSatoshi
+	failTime    time.Time
+}
+
+// normalizeProbeResults converts external probing results into the pair
+// history representation of mission control. The results of every pair are
+// applied in chronological order following the rules of mission control for
+// its own results. Results older than maxAge are skipped and the timestamps
+// of the remaining ones are moved back by agePenalty.
+func normalizeProbeResults(results []probeResult, now time.Time, maxAge,
+	agePenalty time.Duration) ([]*routerrpc.PairHistory, error) {
+
+	// Validate all results before anything is applied and sort the
+	// remaining ones by their timestamp. The sort is stable so that
+	// results with the same timestamp are applied in the order of the
+	// file.
+	type parsedResult struct {
+		from, to  route.Vertex
+		timestamp time.Time
+		amt       lnwire.MilliSatoshi
+		success   bool
+	}
+
+	parsed := make([]parsedResult, 0, len(results))
+	for i, r := range results {
+		from, err := route.NewVertexFromStr(r.From)
+		if err != nil {
+			return nil, fmt.Errorf("result %v: invalid from node: "+
+				"%w", i, err)
+		}
+
+		to, err := route.NewVertexFromStr(r.To)
+		if err != nil {
+			return nil, fmt.Errorf("result %v: invalid to node: %w",
+				i, err)
+		}
+
+		switch {
+		case from == to:
+			return nil, fmt.Errorf("result %v: from and to node "+
+				"must differ", i)
+
+		case r.Timestamp <= 0:
+			return nil, fmt.Errorf("result %v: timestamp must be "+
+				"positive", i)
+
+		case r.AmtMsat < 0:
+			return nil, fmt.Errorf("result %v: amount must not be "+
+				"negative", i)
+
+		case r.Success && r.AmtMsat == 0:
+			return nil, fmt.Errorf("result %v: success amount "+
+				"must be positive", i)
+		}
+
+		timestamp := time.Unix(r.Timestamp, 0)
+		if maxAge > 0 && now.Sub(timestamp) > maxAge {
+			continue
+		}
+
+		// Mission control doesn't import failures without an amount,
+		// so an amount independent failure is stored as a failure of
+		// the smallest possible amount, which has the same effect.
+		amt := lnwire.MilliSatoshi(r.AmtMsat)
+		if !r.Success && amt == 0 {
+			amt = 1
+		}
+
+		parsed = append(parsed, parsedResult{
+			from:      from,
+			to:        to,
+			timestamp: timestamp.Add(-agePenalty),
+			amt:       amt,
+			success:   r.Success,
+		})
+	}
+
+	sort.SliceStable(parsed, func(i, j int) bool {
+		return parsed[i].timestamp.Before(parsed[j].timestamp)
+	})
+
+	states := make(map[[2]route.Vertex]*probePairState)
+	for _, r := range parsed {
+		key := [2]route.Vertex{r.from, r.to}
+		state, ok := states[key]
+		if !ok {
+			state = &probePairState{}
+			states[key] = state
+		}
+
+		if r.success {
+			state.successTime = r.timestamp
+			state.successAmt = max(state.successAmt, r.amt)
+
+			// A success in the failure range moves the failure
+			// range up.
+			if !state.failTime.IsZero() && r.amt >= state.failAmt {
+				state.failAmt = r.amt + 1
+			}
+
+			continue
+		}
+
+		state.failTime = r.timestamp
+		state.failAmt = r.amt
+
+		// A failure in the success range moves the success range down.
+		if r.amt <= state.successAmt {
+			state.successAmt = r.amt - 1
+		}
+	}
+
+	pairs := make([]*routerrpc.PairHistory, 0, len(states))
+	for key, state := range states {
+		history := &routerrpc.PairData{}
+		if !state.successTime.IsZero() {
+			history.SuccessTime = state.successTime.Unix()
+			history.SuccessAmtMsat = int64(state.successAmt)
+		}
+		if !state.failTime.IsZero() {
+			history.FailTime = state.failTime.Unix()
+			history.FailAmtMsat = int64(state.failAmt)
+		}
+
+		// A success that was entirely revoked by later failures
+		// carries no information anymore.
+		if history.SuccessAmtMsat == 0 {
+			history.SuccessTime = 0
+		}
+
+		from, to := key[0], key[1]
+		pairs = append(pairs, &routerrpc.PairHistory{
+			NodeFrom: from[:],
+			NodeTo:   to[:],
+			History:  history,
+		})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if c := bytes.Compare(
+			pairs[i].NodeFrom, pairs[j].NodeFrom,
+		); c != 0 {
+
+			return c < 0
+		}
+
+		return bytes.Compare(pairs[i].NodeTo, pairs[j].NodeTo) < 0
+	})
+
+	return pairs, nil
+}
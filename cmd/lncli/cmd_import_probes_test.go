@@ -0,0 +1,130 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	probeNodeA = "02ec95e4e8ad994861b95fc5986eedaac24739e5ea3d0634db4c" +
+		"8ccd44cd1b6f98"
+	probeNodeB = "0365d2e8ef2db6f7c6a5b5e2a7ce1e9ad3bd7f1a1b7a0d2bb" +
+		"a3df7f4c8c1f3e21a"
+)
+
+// TestNormalizeProbeResults tests the conversion of external probing results
+// into mission control pair history.
+func TestNormalizeProbeResults(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000_000, 0)
+
+	testCases := []struct {
+		name       string
+		results    []probeResult
+		maxAge     time.Duration
+		agePenalty time.Duration
+		expected   []*routerrpc.PairData
+		expectErr  bool
+	}{{
+		name: "latest results win",
+		results: []probeResult{{
+			Timestamp: 900_000, From: probeNodeA, To: probeNodeB,
+			AmtMsat: 5000, Success: true,
+		}, {
+			Timestamp: 800_000, From: probeNodeA, To: probeNodeB,
+			AmtMsat: 2000,
+		}, {
+			Timestamp: 950_000, From: probeNodeA, To: probeNodeB,
+			AmtMsat: 3000,
+		}},
+		expected: []*routerrpc.PairData{{
+			SuccessTime:    900_000,
+			SuccessAmtMsat: 2999,
+			FailTime:       950_000,
+			FailAmtMsat:    3000,
+		}},
+	}, {
+		name: "success moves failure up",
+		results: []probeResult{{
+			Timestamp: 800_000, From: probeNodeA, To: probeNodeB,
+			AmtMsat: 2000,
+		}, {
+			Timestamp: 900_000, From: probeNodeA, To: probeNodeB,
+			AmtMsat: 5000, Success: true,
+		}},
+		expected: []*routerrpc.PairData{{
+			SuccessTime:    900_000,
+			SuccessAmtMsat: 5000,
+			FailTime:       800_000,
+			FailAmtMsat:    5001,
+		}},
+	}, {
+		name: "amount independent failure",
+		results: []probeResult{{
+			Timestamp: 800_000, From: probeNodeA, To: probeNodeB,
+			AmtMsat: 5000, Success: true,
+		}, {
+			Timestamp: 900_000, From: probeNodeA, To: probeNodeB,
+		}},
+		expected: []*routerrpc.PairData{{
+			FailTime:    900_000,
+			FailAmtMsat: 1,
+		}},
+	}, {
+		name: "weighting",
+		results: []probeResult{{
+			Timestamp: 100_000, From: probeNodeA, To: probeNodeB,
+			AmtMsat: 1000, Success: true,
+		}, {
+			Timestamp: 900_000, From: probeNodeB, To: probeNodeA,
+			AmtMsat: 4000, Success: true,
+		}},
+		maxAge:     time.Hour * 48,
+		agePenalty: time.Hour,
+		expected: []*routerrpc.PairData{{
+			SuccessTime:    900_000 - 3600,
+			SuccessAmtMsat: 4000,
+		}},
+	}, {
+		name: "same node",
+		results: []probeResult{{
+			Timestamp: 900_000, From: probeNodeA, To: probeNodeA,
+			AmtMsat: 1000, Success: true,
+		}},
+		expectErr: true,
+	}, {
+		name: "zero success amount",
+		results: []probeResult{{
+			Timestamp: 900_000, From: probeNodeA, To: probeNodeB,
+			Success: true,
+		}},
+		expectErr: true,
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pairs, err := normalizeProbeResults(
+				tc.results, now, tc.maxAge, tc.agePenalty,
+			)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			histories := make([]*routerrpc.PairData, len(pairs))
+			for i, pair := range pairs {
+				histories[i] = pair.History
+			}
+			require.Equal(t, tc.expected, histories)
+		})
+	}
+}
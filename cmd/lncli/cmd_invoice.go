@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/urfave/cli"
@@ -82,10 +83,64 @@ var addInvoiceCommand = cli.Command{
 			Usage: "creates an AMP invoice. If true, preimage " +
 				"should not be set.",
 		},
+		hopHintChanFlag,
+		metadataFlag,
 	},
 	Action: actionDecorator(addInvoice),
 }
 
+var hopHintChanFlag = cli.Int64SliceFlag{
+	Name: "hop_hint_chan_id",
+	Usage: "short channel id of a channel that is always encoded as " +
+		"a routing hint in the invoice, also if it is public; can " +
+		"be specified multiple times in the same command",
+	Value: &cli.Int64Slice{},
+}
+
+// parseHopHintChans returns the channel ids of the hop hint channel flag.
+func parseHopHintChans(ctx *cli.Context) []uint64 {
+	var chanIDs []uint64
+	for _, chanID := range ctx.Int64Slice(hopHintChanFlag.Name) {
+		chanIDs = append(chanIDs, uint64(chanID))
+	}
+
+	return chanIDs
+}
+
+var metadataFlag = cli.StringSliceFlag{
+	Name: "metadata",
+	Usage: "a key=value pair of metadata that is stored along with " +
+		"the invoice, but not encoded in it; can be specified " +
+		"multiple times in the same command",
+	Value: &cli.StringSlice{},
+}
+
+// parseMetadata returns the key-value pairs of the metadata flag.
+func parseMetadata(ctx *cli.Context) (map[string]string, error) {
+	entries := ctx.StringSlice(metadataFlag.Name)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid metadata %q, expected "+
+				"key=value", entry)
+		}
+
+		if _, ok := metadata[key]; ok {
+			return nil, fmt.Errorf("duplicate metadata key %q",
+				key)
+		}
+
+		metadata[key] = value
+	}
+
+	return metadata, nil
+}
+
 func addInvoice(ctx *cli.Context) error {
 	var (
 		preimage []byte
@@ -127,6 +182,11 @@ func addInvoice(ctx *cli.Context) error {
 		return fmt.Errorf("unable to parse description_hash: %w", err)
 	}
 
+	metadata, err := parseMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
 	invoice := &lnrpc.Invoice{
 		Memo:            ctx.String("memo"),
 		RPreimage:       preimage,
@@ -138,6 +198,8 @@ func addInvoice(ctx *cli.Context) error {
 		CltvExpiry:      ctx.Uint64("cltv_expiry_delta"),
 		Private:         ctx.Bool("private"),
 		IsAmp:           ctx.Bool("amp"),
+		HopHintChanIds:  parseHopHintChans(ctx),
+		Metadata:        metadata,
 	}
 
 	resp, err := client.AddInvoice(ctxc, invoice)
@@ -256,6 +318,16 @@ var listInvoicesCommand = cli.Command{
 				"invoices with creation date less than or " +
 				"equal to it",
 		},
+		cli.StringFlag{
+			Name: "metadata_key",
+			Usage: "if set, only invoices with a metadata entry " +
+				"for this key are returned",
+		},
+		cli.StringFlag{
+			Name: "metadata_value",
+			Usage: "if set, only invoices whose metadata entry " +
+				"for metadata_key has this value are returned",
+		},
 	},
 	Action: actionDecorator(listInvoices),
 }
@@ -272,6 +344,8 @@ func listInvoices(ctx *cli.Context) error {
 		Reversed:          !ctx.Bool("paginate-forwards"),
 		CreationDateStart: ctx.Uint64("creation_date_start"),
 		CreationDateEnd:   ctx.Uint64("creation_date_end"),
+		MetadataKey:       ctx.String("metadata_key"),
+		MetadataValue:     ctx.String("metadata_value"),
 	}
 
 	invoices, err := client.ListInvoices(ctxc, req)
@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
@@ -268,11 +271,73 @@ func setCfg(ctx *cli.Context) error {
 	return err
 }
 
+var subscribeCfgCommand = cli.Command{
+	Name:     "subscribemccfg",
+	Category: "Mission Control",
+	Usage: "Print mission control's config every time it is " +
+		"changed.",
+	Action: actionDecorator(subscribeCfg),
+}
+
+func subscribeCfg(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	stream, err := client.SubscribeMissionControlConfig(
+		ctxc, &routerrpc.SubscribeMissionControlConfigRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		printRespJSON(event)
+	}
+}
+
 var queryMissionControlCommand = cli.Command{
 	Name:     "querymc",
 	Category: "Mission Control",
 	Usage:    "Query the internal mission control state.",
-	Action:   actionDecorator(queryMissionControl),
+	Flags: []cli.Flag{
+		mcNamespaceFlag,
+		cli.StringFlag{
+			Name: "node",
+			Usage: "(optional) only return pairs that have the " +
+				"given node as their source or destination",
+		},
+		cli.Int64Flag{
+			Name: "min_time",
+			Usage: "(optional) only return pairs with a " +
+				"result observed at or after the given unix " +
+				"timestamp in seconds",
+		},
+		cli.Int64Flag{
+			Name: "min_amt_msat",
+			Usage: "(optional) only return pairs with a " +
+				"success or failure amount of at least the " +
+				"given amount",
+		},
+		cli.Uint64Flag{
+			Name: "index_offset",
+			Usage: "(optional) the index of the first pair to " +
+				"return, used for paging",
+		},
+		cli.Uint64Flag{
+			Name: "max_pairs",
+			Usage: "(optional) the maximum number of pairs to " +
+				"return, all pairs are returned if zero",
+		},
+	},
+	Action: actionDecorator(queryMissionControl),
 }
 
 func queryMissionControl(ctx *cli.Context) error {
@@ -282,7 +347,22 @@ func queryMissionControl(ctx *cli.Context) error {
 
 	client := routerrpc.NewRouterClient(conn)
 
-	req := &routerrpc.QueryMissionControlRequest{}
+	req := &routerrpc.QueryMissionControlRequest{
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+		MinObservationTime:      ctx.Int64("min_time"),
+		MinAmtMsat:              ctx.Int64("min_amt_msat"),
+		IndexOffset:             ctx.Uint64("index_offset"),
+		MaxPairs:                ctx.Uint64("max_pairs"),
+	}
+
+	if ctx.IsSet("node") {
+		node, err := route.NewVertexFromStr(ctx.String("node"))
+		if err != nil {
+			return fmt.Errorf("invalid node key: %w", err)
+		}
+		req.Node = node[:]
+	}
+
 	snapshot, err := client.QueryMissionControl(ctxc, req)
 	if err != nil {
 		return err
@@ -293,6 +373,56 @@ func queryMissionControl(ctx *cli.Context) error {
 	return nil
 }
 
+var listMissionControlNamespacesCommand = cli.Command{
+	Name:     "listmcnamespaces",
+	Category: "Mission Control",
+	Usage:    "List all mission control namespaces.",
+	Action:   actionDecorator(listMissionControlNamespaces),
+}
+
+func listMissionControlNamespaces(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	req := &routerrpc.ListMissionControlNamespacesRequest{}
+	resp, err := client.ListMissionControlNamespaces(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var getProberStatsCommand = cli.Command{
+	Name:     "getproberstats",
+	Category: "Mission Control",
+	Usage:    "Display the statistics of the background payment prober.",
+	Action:   actionDecorator(getProberStats),
+}
+
+func getProberStats(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	req := &routerrpc.GetProberStatsRequest{}
+	resp, err := client.GetProberStats(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 var queryProbCommand = cli.Command{
 	Name:      "queryprob",
 	Category:  "Mission Control",
@@ -300,6 +430,9 @@ var queryProbCommand = cli.Command{
 	ArgsUsage: "from-node to-node amt",
 	Action:    actionDecorator(queryProb),
 	Hidden:    true,
+	Flags: []cli.Flag{
+		mcNamespaceFlag,
+	},
 }
 
 func queryProb(ctx *cli.Context) error {
@@ -338,6 +471,8 @@ func queryProb(ctx *cli.Context) error {
 		FromNode: fromNode[:],
 		ToNode:   toNode[:],
 		AmtMsat:  int64(amtMsat),
+
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
 	}
 
 	response, err := client.QueryProbability(ctxc, req)
@@ -350,11 +485,73 @@ func queryProb(ctx *cli.Context) error {
 	return nil
 }
 
+var queryRouteProbCommand = cli.Command{
+	Name:     "queryrouteprob",
+	Category: "Mission Control",
+	Usage:    "Estimate the success probability of a route.",
+	Description: `
+	Estimates the end-to-end success probability of a route based on the
+	current mission control state. The route is given as a json string in
+	the format of the output of queryroutes or buildroute, or as - to read
+	it from stdin.
+	`,
+	ArgsUsage: "route",
+	Flags: []cli.Flag{
+		mcNamespaceFlag,
+	},
+	Action: actionDecorator(queryRouteProb),
+}
+
+func queryRouteProb(ctx *cli.Context) error {
+	ctxc := getContext()
+	args := ctx.Args()
+
+	if len(args) != 1 {
+		return cli.ShowCommandHelp(ctx, "queryrouteprob")
+	}
+
+	jsonRoute := args.First()
+	if jsonRoute == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		jsonRoute = string(b)
+	}
+
+	rt, err := parseJSONRoute(jsonRoute)
+	if err != nil {
+		return err
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	req := &routerrpc.QueryRouteProbabilityRequest{
+		Route:                   rt,
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+	}
+
+	response, err := client.QueryRouteProbability(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(response)
+
+	return nil
+}
+
 var resetMissionControlCommand = cli.Command{
 	Name:     "resetmc",
 	Category: "Mission Control",
 	Usage:    "Reset internal mission control state.",
-	Action:   actionDecorator(resetMissionControl),
+	Flags: []cli.Flag{
+		mcNamespaceFlag,
+	},
+	Action: actionDecorator(resetMissionControl),
 }
 
 func resetMissionControl(ctx *cli.Context) error {
@@ -364,7 +561,208 @@ func resetMissionControl(ctx *cli.Context) error {
 
 	client := routerrpc.NewRouterClient(conn)
 
-	req := &routerrpc.ResetMissionControlRequest{}
+	req := &routerrpc.ResetMissionControlRequest{
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+	}
 	_, err := client.ResetMissionControl(ctxc, req)
 	return err
 }
+
+var resetMissionControlPairsCommand = cli.Command{
+	Name:     "resetmcpairs",
+	Category: "Mission Control",
+	Usage: "Reset the mission control state of specific node pairs " +
+		"or nodes.",
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name: "pair",
+			Usage: "directional node pair <node1>:<node2> to " +
+				"reset. This flag can be specified multiple " +
+				"times",
+		},
+		cli.StringSliceFlag{
+			Name: "node",
+			Usage: "node to reset all pairs in both " +
+				"directions for. This flag can be specified " +
+				"multiple times",
+		},
+		mcNamespaceFlag,
+	},
+	Action: actionDecorator(resetMissionControlPairs),
+}
+
+func resetMissionControlPairs(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	req := &routerrpc.ResetMissionControlPairsRequest{
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+	}
+
+	for _, pairStr := range ctx.StringSlice("pair") {
+		pair, err := parseNodePair(pairStr)
+		if err != nil {
+			return err
+		}
+		req.Pairs = append(req.Pairs, pair)
+	}
+
+	for _, nodeStr := range ctx.StringSlice("node") {
+		node, err := route.NewVertexFromStr(nodeStr)
+		if err != nil {
+			return fmt.Errorf("invalid node key: %w", err)
+		}
+		req.Nodes = append(req.Nodes, node[:])
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	resp, err := client.ResetMissionControlPairs(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var setMissionControlPairOverridesCommand = cli.Command{
+	Name:     "setmcpairoverrides",
+	Category: "Mission Control",
+	Usage: "Set bimodal estimator parameters for specific node " +
+		"pairs.",
+	Description: `
+	Set the bimodal node weight and liquidity scale of single directed node
+	pairs, replacing the global parameters for these pairs. This is useful
+	for channels to known liquidity drains or sources, whose balance
+	distribution doesn't follow the global bimodal assumption. The
+	overrides only apply if the bimodal estimator is used and aren't
+	persisted across restarts.
+
+	Every override is given as <node1>:<node2>=<node weight>:<scale msat>.
+
+	Example:
+	lncli setmcpairoverrides --set 02ab...:03cd...=0.1:50000000
+	`,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name: "set",
+			Usage: "override in the format <node1>:<node2>=" +
+				"<node weight>:<scale msat>. This flag can " +
+				"be specified multiple times",
+		},
+		cli.StringSliceFlag{
+			Name: "remove",
+			Usage: "directional node pair <node1>:<node2> whose " +
+				"override is removed. This flag can be " +
+				"specified multiple times",
+		},
+		mcNamespaceFlag,
+	},
+	Action: actionDecorator(setMissionControlPairOverrides),
+}
+
+func setMissionControlPairOverrides(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	req := &routerrpc.SetBimodalPairOverridesRequest{
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+	}
+
+	for _, overrideStr := range ctx.StringSlice("set") {
+		pairStr, paramsStr, ok := strings.Cut(overrideStr, "=")
+		if !ok {
+			return fmt.Errorf("invalid override %v, expected "+
+				"<node1>:<node2>=<node weight>:<scale msat>",
+				overrideStr)
+		}
+
+		pair, err := parseNodePair(pairStr)
+		if err != nil {
+			return err
+		}
+
+		weightStr, scaleStr, ok := strings.Cut(paramsStr, ":")
+		if !ok {
+			return fmt.Errorf("invalid parameters %v, expected "+
+				"<node weight>:<scale msat>", paramsStr)
+		}
+
+		weight, err := strconv.ParseFloat(weightStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid node weight: %w", err)
+		}
+
+		scale, err := strconv.ParseUint(scaleStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid scale: %w", err)
+		}
+
+		req.Overrides = append(
+			req.Overrides, &routerrpc.BimodalPairOverride{
+				NodeFrom:   pair.From,
+				NodeTo:     pair.To,
+				NodeWeight: weight,
+				ScaleMsat:  scale,
+			},
+		)
+	}
+
+	for _, pairStr := range ctx.StringSlice("remove") {
+		pair, err := parseNodePair(pairStr)
+		if err != nil {
+			return err
+		}
+		req.Remove = append(req.Remove, pair)
+	}
+
+	if len(req.Overrides) == 0 && len(req.Remove) == 0 {
+		return cli.ShowCommandHelp(ctx, "setmcpairoverrides")
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	_, err := client.SetBimodalPairOverrides(ctxc, req)
+
+	return err
+}
+
+var listMissionControlPairOverridesCommand = cli.Command{
+	Name:     "listmcpairoverrides",
+	Category: "Mission Control",
+	Usage: "List the bimodal estimator parameters of specific node " +
+		"pairs.",
+	Flags: []cli.Flag{
+		mcNamespaceFlag,
+	},
+	Action: actionDecorator(listMissionControlPairOverrides),
+}
+
+func listMissionControlPairOverrides(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	resp, err := client.ListBimodalPairOverrides(
+		ctxc, &routerrpc.ListBimodalPairOverridesRequest{
+			MissionControlNamespace: ctx.String(
+				mcNamespaceFlag.Name,
+			),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
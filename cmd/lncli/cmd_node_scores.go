@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/urfave/cli"
+)
+
+var importNodeScoresCommand = cli.Command{
+	Name:     "importnodescores",
+	Category: "Mission Control",
+	Usage:    "Import node scores from an external source.",
+	Description: `
+	Import per-node scores in the range [0, 1] from an external source,
+	for example a scoring oracle. Each score is given as a pubkey=score
+	argument. If enabled with routerrpc.usenodescores, path finding
+	multiplies the success probability of a channel with the score of the
+	node that forwards over it.
+
+	Example:
+	lncli importnodescores --source=oracle --ttl=24h 02ab...=0.5 03cd...=1
+	`,
+	ArgsUsage: "pubkey=score [pubkey=score...]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "source",
+			Usage: "the name of the source the scores originate from",
+		},
+		cli.DurationFlag{
+			Name: "ttl",
+			Usage: "the duration after which the imported scores " +
+				"expire, 0 means that they don't expire",
+		},
+		cli.BoolFlag{
+			Name: "replace",
+			Usage: "remove all scores that were previously " +
+				"imported from the source first",
+		},
+	},
+	Action: actionDecorator(importNodeScores),
+}
+
+func importNodeScores(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if !ctx.IsSet("source") {
+		return fmt.Errorf("source argument missing")
+	}
+
+	req := &routerrpc.ImportNodeScoresRequest{
+		Source:     ctx.String("source"),
+		TtlSeconds: uint64(ctx.Duration("ttl").Seconds()),
+		Replace:    ctx.Bool("replace"),
+	}
+
+	for _, arg := range ctx.Args() {
+		pubKeyStr, scoreStr, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("invalid score %v, expected "+
+				"pubkey=score", arg)
+		}
+
+		pubKey, err := hex.DecodeString(pubKeyStr)
+		if err != nil {
+			return fmt.Errorf("invalid pubkey %v: %w", pubKeyStr,
+				err)
+		}
+
+		score, err := strconv.ParseFloat(scoreStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid score %v: %w", scoreStr, err)
+		}
+
+		req.Scores = append(req.Scores, &routerrpc.NodeScore{
+			PubKey: pubKey,
+			Score:  score,
+		})
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	resp, err := client.ImportNodeScores(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var queryNodeScoresCommand = cli.Command{
+	Name:     "querynodescores",
+	Category: "Mission Control",
+	Usage:    "List the imported node scores.",
+	Description: `
+	List all imported node scores that didn't expire yet along with their
+	source and expiry.
+	`,
+	Action: actionDecorator(queryNodeScores),
+}
+
+func queryNodeScores(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	resp, err := client.QueryNodeScores(
+		ctxc, &routerrpc.QueryNodeScoresRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
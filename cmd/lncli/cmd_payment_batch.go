@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/urfave/cli"
+)
+
+var sendPaymentBatchCommand = cli.Command{
+	Name:      "sendpaymentbatch",
+	Category:  "Payments",
+	Usage:     "Pay multiple payment requests with a shared fee budget.",
+	ArgsUsage: "pay_req[=amt_msat] [pay_req[=amt_msat]...]",
+	Description: `
+	Pay multiple payment requests with a routing fee budget and a timeout
+	that are shared by all payments of the batch. Every payment is limited
+	to a share of the unused budget that is proportional to its amount.
+	The amount must be appended to payment requests that don't specify
+	one. The final result of every payment is printed as soon as it is
+	known.
+
+	Example:
+	lncli sendpaymentbatch --fee_limit_msat=10000 --max_parallel=2 \
+		lnbc1... lnbc1...=50000
+	`,
+	Flags: []cli.Flag{
+		cli.Int64Flag{
+			Name: "fee_limit_msat",
+			Usage: "the maximum total fee in millisatoshis that " +
+				"the payments of the batch may pay",
+		},
+		cli.DurationFlag{
+			Name: "timeout",
+			Usage: "the maximum amount of time we should spend " +
+				"on the batch, payments that weren't " +
+				"started by then fail",
+			Value: paymentTimeout,
+		},
+		cli.UintFlag{
+			Name: "max_parallel",
+			Usage: "the maximum number of payments that are in " +
+				"flight at the same time",
+			Value: 1,
+		},
+	},
+	Action: actionDecorator(sendPaymentBatch),
+}
+
+func sendPaymentBatch(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if ctx.NArg() == 0 {
+		return cli.ShowCommandHelp(ctx, "sendpaymentbatch")
+	}
+
+	req := &routerrpc.SendPaymentBatchRequest{
+		FeeLimitMsat:        ctx.Int64("fee_limit_msat"),
+		TimeoutSeconds:      int32(ctx.Duration("timeout").Seconds()),
+		MaxParallelPayments: uint32(ctx.Uint("max_parallel")),
+	}
+
+	for _, arg := range ctx.Args() {
+		payment := &routerrpc.BatchPayment{}
+
+		payReq, amtStr, ok := strings.Cut(arg, "=")
+		if ok {
+			amt, err := strconv.ParseInt(amtStr, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid amount %v: %w",
+					amtStr, err)
+			}
+			payment.AmtMsat = amt
+		}
+		payment.PaymentRequest = stripPrefix(payReq)
+
+		req.Payments = append(req.Payments, payment)
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	stream, err := client.SendPaymentBatch(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		printRespJSON(result)
+	}
+}
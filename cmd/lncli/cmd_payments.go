@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
@@ -40,6 +41,20 @@ var (
 			"this payment",
 	}
 
+	maxHopsFlag = cli.UintFlag{
+		Name: "max_hops",
+		Usage: "the maximum number of hops of the route; if not " +
+			"set, the number of hops is only limited by the " +
+			"size of the onion",
+	}
+
+	minChannelCapacityFlag = cli.Int64Flag{
+		Name: "min_channel_capacity",
+		Usage: "the minimum capacity in satoshis of the channels of " +
+			"other nodes that path finding considers; speeds up " +
+			"path finding for large amounts",
+	}
+
 	lastHopFlag = cli.StringFlag{
 		Name: "last_hop",
 		Usage: "pubkey of the last hop (penultimate node in the path) " +
@@ -99,6 +114,36 @@ var (
 		Usage: "(optional) expresses time preference (range -1 to 1)",
 	}
 
+	mcNamespaceFlag = cli.StringFlag{
+		Name: "mc_namespace",
+		Usage: "(optional) the mission control namespace to use, " +
+			"keeping the payment history isolated from other " +
+			"namespaces; if not set, the default namespace is used",
+	}
+
+	pathfindingTimeoutFlag = cli.DurationFlag{
+		Name: "pathfinding_timeout",
+		Usage: "(optional) the maximum time a single route search " +
+			"is allowed to take, e.g. 500ms; if not set, route " +
+			"searches are not time limited",
+	}
+
+	ignoreNodeFlag = cli.StringSliceFlag{
+		Name: "ignore_node",
+		Usage: "(optional) the hex encoded public key of a node to " +
+			"ignore during path finding. This flag can be " +
+			"specified multiple times if multiple nodes are to " +
+			"be ignored",
+	}
+
+	ignorePairFlag = cli.StringSliceFlag{
+		Name: "ignore_pair",
+		Usage: "ignore directional node pair " +
+			"<node1>:<node2>. This flag can be specified " +
+			"multiple times if multiple node pairs are " +
+			"to be ignored",
+	}
+
 	introductionNodeFlag = cli.StringFlag{
 		Name: "introduction_node",
 		Usage: "(blinded paths) the hex encoded, cleartext node ID " +
@@ -159,6 +204,23 @@ func paymentFlags() []cli.Flag {
 				"the maximum fee allowed when sending the " +
 				"payment",
 		},
+		cli.Uint64Flag{
+			Name: "fee_limit_ppm",
+			Usage: "maximum fee allowed in parts per million of " +
+				"the payment's amount; the limit is resolved " +
+				"by lnd and bounded by fee_limit_min_msat " +
+				"and fee_limit_max_msat",
+		},
+		cli.Int64Flag{
+			Name: "fee_limit_min_msat",
+			Usage: "the lower bound of the fee limit in msat if " +
+				"fee_limit_ppm is used",
+		},
+		cli.Int64Flag{
+			Name: "fee_limit_max_msat",
+			Usage: "the upper bound of the fee limit in msat if " +
+				"fee_limit_ppm is used",
+		},
 		cli.DurationFlag{
 			Name: "timeout",
 			Usage: "the maximum amount of time we should spend " +
@@ -167,7 +229,50 @@ func paymentFlags() []cli.Flag {
 			Value: paymentTimeout,
 		},
 		cltvLimitFlag,
+		cli.UintFlag{
+			Name: "max_total_cltv",
+			Usage: "the maximum time lock that the hops of the " +
+				"route may add on top of the final cltv " +
+				"delta of the recipient",
+		},
+		maxHopsFlag,
+		minChannelCapacityFlag,
+		cli.Uint64Flag{
+			Name: "latency_penalty_msat",
+			Usage: "the cost in msat that is assigned to each " +
+				"second of historical resolution latency of " +
+				"a hop; favors fast routes over cheap ones",
+		},
 		lastHopFlag,
+		cli.StringSliceFlag{
+			Name: "last_hop_candidate",
+			Usage: "pubkey of an acceptable last hop " +
+				"(penultimate node in the path); can be " +
+				"specified multiple times in the same " +
+				"command, the candidates are tried in the " +
+				"given order",
+		},
+		cli.BoolFlag{
+			Name: "joint_last_hops",
+			Usage: "if set, all last hop candidates are " +
+				"considered at once instead of in order",
+		},
+		cli.StringFlag{
+			Name: "split_strategy",
+			Usage: "the strategy to split the payment into " +
+				"shards if no route can be found for the " +
+				"full amount; possible values are halving, " +
+				"capacity and probability, if not set the " +
+				"default strategy of the node is used",
+		},
+		cli.StringSliceFlag{
+			Name: "route_prefix",
+			Usage: "pubkey of a node that the route must " +
+				"traverse directly after our own node; can " +
+				"be specified multiple times in the same " +
+				"command to require a prefix of several " +
+				"hops in the given order",
+		},
 		cli.Int64SliceFlag{
 			Name: "outgoing_chan_id",
 			Usage: "short channel id of the outgoing channel to " +
@@ -176,6 +281,19 @@ func paymentFlags() []cli.Flag {
 				"command",
 			Value: &cli.Int64Slice{},
 		},
+		cli.StringSliceFlag{
+			Name: "outgoing_chan_weight",
+			Usage: "weighted outgoing channel in the format " +
+				"<short channel id>:<weight>; the channel is " +
+				"allowed for the first hop and channels with " +
+				"a higher weight are preferred; can be " +
+				"specified multiple times in the same command",
+		},
+		cli.BoolFlag{
+			Name: "local_balance_aware",
+			Usage: "prefer outgoing channels whose local " +
+				"balance leaves more room for the payment",
+		},
 		cli.BoolFlag{
 			Name:  "force, f",
 			Usage: "will skip payment request confirmation",
@@ -186,7 +304,8 @@ func paymentFlags() []cli.Flag {
 		},
 		dataFlag, inflightUpdatesFlag, maxPartsFlag, jsonFlag,
 		maxShardSizeSatFlag, maxShardSizeMsatFlag, ampFlag,
-		timePrefFlag,
+		timePrefFlag, mcNamespaceFlag, pathfindingTimeoutFlag,
+		ignoreNodeFlag, ignorePairFlag,
 	}
 }
 
@@ -271,11 +390,52 @@ func retrieveFeeLimit(ctx *cli.Context, amt int64) (int64, error) {
 	return int64(limitMsat.ToSatoshis()), nil
 }
 
-func confirmPayReq(resp *lnrpc.PayReq, amt, feeLimit int64) error {
+// parseFeeLimitPolicy sets the relative fee limit of the request if one of
+// the relative fee limit flags is set. It returns whether the fee limit is
+// relative.
+func parseFeeLimitPolicy(ctx *cli.Context,
+	req *routerrpc.SendPaymentRequest) (bool, error) {
+
+	if !ctx.IsSet("fee_limit_ppm") && !ctx.IsSet("fee_limit_min_msat") &&
+		!ctx.IsSet("fee_limit_max_msat") {
+
+		return false, nil
+	}
+
+	if ctx.IsSet("fee_limit") || ctx.IsSet("fee_limit_percent") {
+		return false, errors.New("fee_limit_ppm, fee_limit_min_msat " +
+			"and fee_limit_max_msat can't be combined with " +
+			"fee_limit and fee_limit_percent")
+	}
+
+	if ctx.Uint64("fee_limit_ppm") > math.MaxUint32 {
+		return false, errors.New("fee_limit_ppm out of range")
+	}
+
+	req.FeeLimitPpm = uint32(ctx.Uint64("fee_limit_ppm"))
+	req.FeeLimitMinMsat = ctx.Int64("fee_limit_min_msat")
+	req.FeeLimitMaxMsat = ctx.Int64("fee_limit_max_msat")
+
+	return true, nil
+}
+
+// formatFeeLimitPolicy returns a human-readable description of the relative
+// fee limit of a request.
+func formatFeeLimitPolicy(req *routerrpc.SendPaymentRequest) string {
+	desc := fmt.Sprintf("%v ppm, min %v msat", req.FeeLimitPpm,
+		req.FeeLimitMinMsat)
+	if req.FeeLimitMaxMsat != 0 {
+		desc += fmt.Sprintf(", max %v msat", req.FeeLimitMaxMsat)
+	}
+
+	return desc
+}
+
+func confirmPayReq(resp *lnrpc.PayReq, amt int64, feeLimit string) error {
 	fmt.Printf("Payment hash: %v\n", resp.GetPaymentHash())
 	fmt.Printf("Description: %v\n", resp.GetDescription())
 	fmt.Printf("Amount (in satoshis): %v\n", amt)
-	fmt.Printf("Fee limit (in satoshis): %v\n", feeLimit)
+	fmt.Printf("Fee limit: %v\n", feeLimit)
 	fmt.Printf("Destination: %v\n", resp.GetDestination())
 
 	confirm := promptForConfirmation("Confirm payment (yes/no): ")
@@ -474,6 +634,16 @@ func sendPaymentRequest(ctx *cli.Context,
 		}
 	}
 
+	for _, w := range ctx.StringSlice("outgoing_chan_weight") {
+		chanWeight, err := parseOutgoingChanWeight(w)
+		if err != nil {
+			return err
+		}
+		req.OutgoingChanWeights = append(
+			req.OutgoingChanWeights, chanWeight,
+		)
+	}
+
 	if ctx.IsSet(lastHopFlag.Name) {
 		lastHop, err := route.NewVertexFromStr(
 			ctx.String(lastHopFlag.Name),
@@ -484,7 +654,39 @@ func sendPaymentRequest(ctx *cli.Context,
 		req.LastHopPubkey = lastHop[:]
 	}
 
+	for _, pubKey := range ctx.StringSlice("last_hop_candidate") {
+		lastHop, err := route.NewVertexFromStr(pubKey)
+		if err != nil {
+			return err
+		}
+		req.LastHopPubkeys = append(req.LastHopPubkeys, lastHop[:])
+	}
+	req.JointLastHops = ctx.Bool("joint_last_hops")
+
+	if ctx.IsSet("split_strategy") {
+		strategy, err := parseSplitStrategy(
+			ctx.String("split_strategy"),
+		)
+		if err != nil {
+			return err
+		}
+		req.SplitStrategy = strategy
+	}
+
+	for _, pubKey := range ctx.StringSlice("route_prefix") {
+		node, err := route.NewVertexFromStr(pubKey)
+		if err != nil {
+			return err
+		}
+		req.RoutePrefix = append(req.RoutePrefix, node[:])
+	}
+
 	req.CltvLimit = int32(ctx.Int(cltvLimitFlag.Name))
+	req.MaxTotalCltv = uint32(ctx.Uint("max_total_cltv"))
+	req.MaxHops = uint32(ctx.Uint(maxHopsFlag.Name))
+	req.LocalBalanceAware = ctx.Bool("local_balance_aware")
+	req.MinChannelCapacity = ctx.Int64(minChannelCapacityFlag.Name)
+	req.LatencyPenaltyMsat = ctx.Uint64("latency_penalty_msat")
 
 	pmtTimeout := ctx.Duration("timeout")
 	if pmtTimeout <= 0 {
@@ -540,6 +742,13 @@ func sendPaymentRequest(ctx *cli.Context,
 		}
 	}
 
+	// A relative fee limit is resolved by lnd, so that we don't need to
+	// calculate a fee limit ourselves.
+	hasFeeLimitPolicy, err := parseFeeLimitPolicy(ctx, req)
+	if err != nil {
+		return err
+	}
+
 	var feeLimit int64
 	if req.PaymentRequest != "" {
 		// Decode payment request to find out the amount.
@@ -558,21 +767,24 @@ func sendPaymentRequest(ctx *cli.Context,
 		}
 
 		// Calculate fee limit based on the determined amount.
-		feeLimit, err = retrieveFeeLimit(ctx, amt)
-		if err != nil {
-			return err
+		feeLimitDesc := formatFeeLimitPolicy(req)
+		if !hasFeeLimitPolicy {
+			feeLimit, err = retrieveFeeLimit(ctx, amt)
+			if err != nil {
+				return err
+			}
+			feeLimitDesc = fmt.Sprintf("%v sat", feeLimit)
 		}
 
 		// Ask for confirmation of amount and fee limit if payment is
 		// forced.
 		if !ctx.Bool("force") {
-			err := confirmPayReq(decodeResp, amt, feeLimit)
+			err := confirmPayReq(decodeResp, amt, feeLimitDesc)
 			if err != nil {
 				return err
 			}
 		}
-	} else {
-		var err error
+	} else if !hasFeeLimitPolicy {
 		feeLimit, err = retrieveFeeLimit(ctx, req.Amt)
 		if err != nil {
 			return err
@@ -584,6 +796,32 @@ func sendPaymentRequest(ctx *cli.Context,
 	// Set time pref.
 	req.TimePref = ctx.Float64(timePrefFlag.Name)
 
+	// Set the mission control namespace.
+	req.MissionControlNamespace = ctx.String(mcNamespaceFlag.Name)
+
+	// Set the time limit of the individual route searches.
+	req.PathfindingTimeoutMs = uint32(
+		ctx.Duration(pathfindingTimeoutFlag.Name).Milliseconds(),
+	)
+
+	// Set the nodes and pairs to ignore for this payment.
+	for _, node := range ctx.StringSlice(ignoreNodeFlag.Name) {
+		nodeBytes, err := hex.DecodeString(node)
+		if err != nil {
+			return fmt.Errorf("invalid ignored node %v: %w", node,
+				err)
+		}
+		req.IgnoredNodes = append(req.IgnoredNodes, nodeBytes)
+	}
+
+	ignoredPairs, err := parseNodePairs(
+		ctx.StringSlice(ignorePairFlag.Name),
+	)
+	if err != nil {
+		return err
+	}
+	req.IgnoredPairs = ignoredPairs
+
 	// Always print in-flight updates for the table output.
 	printJSON := ctx.Bool(jsonFlag.Name)
 	req.NoInflightUpdates = !ctx.Bool(inflightUpdatesFlag.Name) && printJSON
@@ -657,6 +895,159 @@ func trackPayment(ctx *cli.Context) error {
 	return err
 }
 
+var cancelPaymentCommand = cli.Command{
+	Name:     "cancelpayment",
+	Category: "Payments",
+	Usage:    "Stop an in-flight payment from launching new attempts.",
+	Description: `
+	Stop the payment specified by the hash argument from launching new HTLC
+	attempts. The attempts that are still in flight are left to resolve,
+	after which the payment fails with reason canceled. A payment that
+	already has a settled HTLC can't be canceled anymore.
+	`,
+	ArgsUsage: "hash",
+	Action:    actionDecorator(cancelPayment),
+}
+
+func cancelPayment(ctx *cli.Context) error {
+	ctxc := getContext()
+	args := ctx.Args()
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	routerClient := routerrpc.NewRouterClient(conn)
+
+	if !args.Present() {
+		return fmt.Errorf("hash argument missing")
+	}
+
+	hash, err := hex.DecodeString(args.First())
+	if err != nil {
+		return err
+	}
+
+	req := &routerrpc.CancelPaymentRequest{
+		PaymentHash: hash,
+	}
+
+	resp, err := routerClient.CancelPayment(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var updatePaymentLimitsCommand = cli.Command{
+	Name:     "updatepaymentlimits",
+	Category: "Payments",
+	Usage:    "Adjust the limits of an in-flight payment.",
+	Description: `
+	Adjust the limits of the payment specified by the hash argument while it
+	is in flight. The new limits are applied before the next HTLC attempt is
+	launched. Limits that aren't specified are left unchanged.
+	`,
+	ArgsUsage: "hash",
+	Flags: []cli.Flag{
+		cli.Int64Flag{
+			Name: "fee_limit_msat",
+			Usage: "the new maximum total fee in millisatoshis " +
+				"that may be paid for the payment",
+		},
+		cli.UintFlag{
+			Name: "cltv_limit",
+			Usage: "the new maximum total time lock of the " +
+				"routes that are tried",
+		},
+		cli.DurationFlag{
+			Name: "timeout",
+			Usage: "the new time after which no new attempts are " +
+				"launched, counted from now",
+		},
+	},
+	Action: actionDecorator(updatePaymentLimits),
+}
+
+func updatePaymentLimits(ctx *cli.Context) error {
+	ctxc := getContext()
+	args := ctx.Args()
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	routerClient := routerrpc.NewRouterClient(conn)
+
+	if !args.Present() {
+		return fmt.Errorf("hash argument missing")
+	}
+
+	hash, err := hex.DecodeString(args.First())
+	if err != nil {
+		return err
+	}
+
+	req := &routerrpc.UpdatePaymentLimitsRequest{
+		PaymentHash:    hash,
+		FeeLimitMsat:   ctx.Int64("fee_limit_msat"),
+		CltvLimit:      uint32(ctx.Uint("cltv_limit")),
+		TimeoutSeconds: int32(ctx.Duration("timeout").Seconds()),
+	}
+
+	resp, err := routerClient.UpdatePaymentLimits(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var abandonStalePaymentsCommand = cli.Command{
+	Name:     "abandonstalepayments",
+	Category: "Payments",
+	Usage:    "Fail payments that are stuck in flight.",
+	Description: `
+	Fail all payments that are in flight without any outstanding HTLCs and
+	were created before the given minimum age. Such payments can't make
+	progress on their own anymore. If no minimum age is given, the
+	configured stale payment timeout is used.
+	`,
+	Flags: []cli.Flag{
+		cli.DurationFlag{
+			Name: "min_age",
+			Usage: "the minimum age of the payments to abandon, " +
+				"e.g. 24h",
+		},
+	},
+	Action: actionDecorator(abandonStalePayments),
+}
+
+func abandonStalePayments(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	routerClient := routerrpc.NewRouterClient(conn)
+
+	req := &routerrpc.AbandonStalePaymentsRequest{
+		MinAgeSeconds: uint64(ctx.Duration("min_age").Seconds()),
+	}
+
+	resp, err := routerClient.AbandonStalePayments(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 // printLivePayment receives payment updates from the given stream and either
 // outputs them as json or as a more user-friendly formatted table. The table
 // option uses terminal control codes to rewrite the output. This call
@@ -938,6 +1329,7 @@ var sendToRouteCommand = cli.Command{
 				"it to true so the payment won't be failed " +
 				"unless a terminal error has occurred.",
 		},
+		mcNamespaceFlag,
 	},
 	Action: sendToRoute,
 }
@@ -1000,43 +1392,51 @@ func sendToRoute(ctx *cli.Context) error {
 		jsonRoutes = string(b)
 	}
 
-	// Try to parse the provided json both in the legacy QueryRoutes format
-	// that contains a list of routes and the single route BuildRoute
-	// format.
-	var route *lnrpc.Route
+	route, err := parseJSONRoute(jsonRoutes)
+	if err != nil {
+		return err
+	}
+
+	req := &routerrpc.SendToRouteRequest{
+		PaymentHash: rHash,
+		Route:       route,
+		SkipTempErr: ctx.Bool("skip_temp_err"),
+
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+	}
+
+	return sendToRouteRequest(ctx, req)
+}
+
+// parseJSONRoute parses a single route from the given json string. Both the
+// legacy QueryRoutes format that contains a list of routes and the single
+// route BuildRoute format are accepted.
+func parseJSONRoute(jsonRoutes string) (*lnrpc.Route, error) {
 	routes := &lnrpc.QueryRoutesResponse{}
-	err = lnrpc.ProtoJSONUnmarshalOpts.Unmarshal([]byte(jsonRoutes), routes)
+	err := lnrpc.ProtoJSONUnmarshalOpts.Unmarshal(
+		[]byte(jsonRoutes), routes,
+	)
 	if err == nil {
 		if len(routes.Routes) == 0 {
-			return fmt.Errorf("no routes provided")
+			return nil, fmt.Errorf("no routes provided")
 		}
 
 		if len(routes.Routes) != 1 {
-			return fmt.Errorf("expected a single route, but got %v",
-				len(routes.Routes))
-		}
-
-		route = routes.Routes[0]
-	} else {
-		routes := &routerrpc.BuildRouteResponse{}
-		err = lnrpc.ProtoJSONUnmarshalOpts.Unmarshal(
-			[]byte(jsonRoutes), routes,
-		)
-		if err != nil {
-			return fmt.Errorf("unable to unmarshal json string "+
-				"from incoming array of routes: %v", err)
+			return nil, fmt.Errorf("expected a single route, but "+
+				"got %v", len(routes.Routes))
 		}
 
-		route = routes.Route
+		return routes.Routes[0], nil
 	}
 
-	req := &routerrpc.SendToRouteRequest{
-		PaymentHash: rHash,
-		Route:       route,
-		SkipTempErr: ctx.Bool("skip_temp_err"),
+	route := &routerrpc.BuildRouteResponse{}
+	err = lnrpc.ProtoJSONUnmarshalOpts.Unmarshal([]byte(jsonRoutes), route)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal json string "+
+			"from incoming array of routes: %v", err)
 	}
 
-	return sendToRouteRequest(ctx, req)
+	return route.Route, nil
 }
 
 func sendToRouteRequest(ctx *cli.Context, req *routerrpc.SendToRouteRequest) error {
@@ -1056,6 +1456,90 @@ func sendToRouteRequest(ctx *cli.Context, req *routerrpc.SendToRouteRequest) err
 	return nil
 }
 
+// parseNodePair parses a directional node pair in the format
+// <node1 pub key>:<node2 pub key>.
+func parseNodePair(pair string) (*lnrpc.NodePair, error) {
+	nodes := strings.Split(pair, ":")
+	if len(nodes) != 2 {
+		return nil, fmt.Errorf("invalid node pair format. " +
+			"Expected <node1 pub key>:<node2 pub key>")
+	}
+
+	node1, err := hex.DecodeString(nodes[0])
+	if err != nil {
+		return nil, err
+	}
+
+	node2, err := hex.DecodeString(nodes[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.NodePair{
+		From: node1,
+		To:   node2,
+	}, nil
+}
+
+// parseSplitStrategy parses the name of a split strategy.
+func parseSplitStrategy(name string) (routerrpc.SplitStrategy, error) {
+	switch name {
+	case "halving":
+		return routerrpc.SplitStrategy_SPLIT_STRATEGY_HALVING, nil
+
+	case "capacity":
+		return routerrpc.SplitStrategy_SPLIT_STRATEGY_CAPACITY, nil
+
+	case "probability":
+		return routerrpc.SplitStrategy_SPLIT_STRATEGY_PROBABILITY, nil
+
+	default:
+		return 0, fmt.Errorf("unknown split strategy %v", name)
+	}
+}
+
+// parseOutgoingChanWeight parses a weighted outgoing channel in the format
+// <short channel id>:<weight>.
+func parseOutgoingChanWeight(
+	chanWeight string) (*routerrpc.OutgoingChannelWeight, error) {
+
+	parts := strings.Split(chanWeight, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid outgoing channel weight " +
+			"format. Expected <short channel id>:<weight>")
+	}
+
+	chanID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel id: %w", err)
+	}
+
+	weight, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weight: %w", err)
+	}
+
+	return &routerrpc.OutgoingChannelWeight{
+		ChanId: chanID,
+		Weight: weight,
+	}, nil
+}
+
+// parseNodePairs parses a list of directional node pairs in the format
+// <node1 pub key>:<node2 pub key>.
+func parseNodePairs(pairs []string) ([]*lnrpc.NodePair, error) {
+	nodePairs := make([]*lnrpc.NodePair, len(pairs))
+	for i, pair := range pairs {
+		var err error
+		nodePairs[i], err = parseNodePair(pair)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nodePairs, nil
+}
+
 var queryRoutesCommand = cli.Command{
 	Name:        "queryroutes",
 	Category:    "Payments",
@@ -1096,15 +1580,45 @@ var queryRoutesCommand = cli.Command{
 			Usage: "(optional) the channel id of the channel " +
 				"that must be taken to the first hop",
 		},
-		cli.StringSliceFlag{
-			Name: "ignore_pair",
-			Usage: "ignore directional node pair " +
-				"<node1>:<node2>. This flag can be specified " +
-				"multiple times if multiple node pairs are " +
-				"to be ignored",
+		cli.Uint64Flag{
+			Name: "incoming_chan_id",
+			Usage: "(optional) the channel id of the channel " +
+				"through which the destination must be " +
+				"reached; required together with " +
+				"outgoing_chan_id for circular routes to self",
+		},
+		ignorePairFlag,
+		cli.UintFlag{
+			Name: "num_routes",
+			Usage: "(optional) the maximum number of distinct " +
+				"routes to return, ordered by their total fees",
+		},
+		cli.Int64SliceFlag{
+			Name: "avoid_chan_id",
+			Usage: "(optional) short channel id of a previously " +
+				"used route that the returned routes should " +
+				"diverge from; can be specified multiple " +
+				"times in the same command",
+			Value: &cli.Int64Slice{},
+		},
+		maxHopsFlag,
+		minChannelCapacityFlag,
+		cli.Float64Flag{
+			Name: "max_route_overlap",
+			Usage: "(optional) the maximum fraction of the " +
+				"channels and intermediate nodes of the " +
+				"avoided route that the returned routes may " +
+				"share with it",
+		},
+		cli.BoolFlag{
+			Name: "include_diagnostics",
+			Usage: "(optional) include statistics of the path " +
+				"finding searches in the response",
 		},
 		timePrefFlag,
 		cltvLimitFlag,
+		mcNamespaceFlag,
+		pathfindingTimeoutFlag,
 		introductionNodeFlag,
 		blindingPointFlag,
 		blindedHopsFlag,
@@ -1162,29 +1676,11 @@ func queryRoutes(ctx *cli.Context) error {
 		return err
 	}
 
-	pairs := ctx.StringSlice("ignore_pair")
-	ignoredPairs := make([]*lnrpc.NodePair, len(pairs))
-	for i, pair := range pairs {
-		nodes := strings.Split(pair, ":")
-		if len(nodes) != 2 {
-			return fmt.Errorf("invalid node pair format. " +
-				"Expected <node1 pub key>:<node2 pub key>")
-		}
-
-		node1, err := hex.DecodeString(nodes[0])
-		if err != nil {
-			return err
-		}
-
-		node2, err := hex.DecodeString(nodes[1])
-		if err != nil {
-			return err
-		}
-
-		ignoredPairs[i] = &lnrpc.NodePair{
-			From: node1,
-			To:   node2,
-		}
+	ignoredPairs, err := parseNodePairs(
+		ctx.StringSlice(ignorePairFlag.Name),
+	)
+	if err != nil {
+		return err
 	}
 
 	blindedRoutes, err := parseBlindedPaymentParameters(ctx)
@@ -1200,10 +1696,27 @@ func queryRoutes(ctx *cli.Context) error {
 		UseMissionControl:   ctx.Bool("use_mc"),
 		CltvLimit:           uint32(ctx.Uint64(cltvLimitFlag.Name)),
 		OutgoingChanId:      ctx.Uint64("outgoing_chan_id"),
+		IncomingChanId:      ctx.Uint64("incoming_chan_id"),
 		TimePref:            ctx.Float64(timePrefFlag.Name),
 		IgnoredPairs:        ignoredPairs,
 		BlindedPaymentPaths: blindedRoutes,
+		NumRoutes:           uint32(ctx.Uint("num_routes")),
+
+		MissionControlNamespace: ctx.String(mcNamespaceFlag.Name),
+	}
+
+	for _, chanID := range ctx.Int64Slice("avoid_chan_id") {
+		req.AvoidRouteChanIds = append(
+			req.AvoidRouteChanIds, uint64(chanID),
+		)
 	}
+	req.MaxRouteOverlap = ctx.Float64("max_route_overlap")
+	req.MaxHops = uint32(ctx.Uint(maxHopsFlag.Name))
+	req.MinChannelCapacity = ctx.Int64(minChannelCapacityFlag.Name)
+	req.IncludeDiagnostics = ctx.Bool("include_diagnostics")
+
+	pathfindingTimeout := ctx.Duration(pathfindingTimeoutFlag.Name)
+	req.PathfindingTimeoutMs = uint32(pathfindingTimeout.Milliseconds())
 
 	route, err := client.QueryRoutes(ctxc, req)
 	if err != nil {
@@ -1558,7 +2071,7 @@ func forwardingHistory(ctx *cli.Context) error {
 var buildRouteCommand = cli.Command{
 	Name:     "buildroute",
 	Category: "Payments",
-	Usage:    "Build a route from a list of hop pubkeys.",
+	Usage:    "Build a route from a list of hop pubkeys or channels.",
 	Description: `
 	Builds a sphinx route for the supplied hops (public keys). Make sure to
 	use a custom final_cltv_delta to create the route depending on the
@@ -1569,6 +2082,15 @@ var buildRouteCommand = cli.Command{
 
 	Moreover a payment_addr has to be provided if the invoice supplied it as
 	well otherwise the payment will be rejected by the receiving node.
+
+	Custom records can be attached to any hop of the route, including
+	intermediate hops, with the hop_data flag. The flag takes the index of
+	the hop in the hops list followed by the records in the format of the
+	data flag of sendpayment, e.g. --hop_data=0:65536=0a0b,65537=0c.
+
+	Instead of the hops, the route can be defined by a list of short
+	channel ids with the chan_ids flag, starting with a channel of the
+	local node. This pins the exact channel that is used at every hop.
 	`,
 	Action: actionDecorator(buildRoute),
 	Flags: []cli.Flag{
@@ -1587,6 +2109,12 @@ var buildRouteCommand = cli.Command{
 			Name:  "hops",
 			Usage: "comma separated hex pubkeys",
 		},
+		cli.StringFlag{
+			Name: "chan_ids",
+			Usage: "comma separated short channel ids that " +
+				"define the route; can't be combined with " +
+				"hops and outgoing_chan_id",
+		},
 		cli.Uint64Flag{
 			Name: "outgoing_chan_id",
 			Usage: "short channel id of the outgoing channel to " +
@@ -1598,6 +2126,13 @@ var buildRouteCommand = cli.Command{
 			Usage: "hex encoded payment address to set in the " +
 				"last hop's mpp record",
 		},
+		cli.StringSliceFlag{
+			Name: "hop_data",
+			Usage: "custom records to attach to a hop in " +
+				"the format hop_index:type=hexvalue," +
+				"type=hexvalue; can be specified multiple " +
+				"times",
+		},
 	},
 }
 
@@ -1608,19 +2143,38 @@ func buildRoute(ctx *cli.Context) error {
 
 	client := routerrpc.NewRouterClient(conn)
 
-	if !ctx.IsSet("hops") {
-		return errors.New("hops required")
+	switch {
+	case ctx.IsSet("hops") && ctx.IsSet("chan_ids"):
+		return errors.New("hops and chan_ids are mutually exclusive")
+
+	case !ctx.IsSet("hops") && !ctx.IsSet("chan_ids"):
+		return errors.New("hops or chan_ids required")
 	}
 
 	// Build list of hop addresses for the rpc.
-	hops := strings.Split(ctx.String("hops"), ",")
-	rpcHops := make([][]byte, 0, len(hops))
-	for _, k := range hops {
-		pubkey, err := route.NewVertexFromStr(k)
-		if err != nil {
-			return fmt.Errorf("error parsing %v: %w", k, err)
+	var rpcHops [][]byte
+	if ctx.IsSet("hops") {
+		for _, k := range strings.Split(ctx.String("hops"), ",") {
+			pubkey, err := route.NewVertexFromStr(k)
+			if err != nil {
+				return fmt.Errorf("error parsing %v: %w", k,
+					err)
+			}
+			rpcHops = append(rpcHops, pubkey[:])
+		}
+	}
+
+	// Build list of channels for the rpc.
+	var chanIDs []uint64
+	if ctx.IsSet("chan_ids") {
+		for _, c := range strings.Split(ctx.String("chan_ids"), ",") {
+			chanID, err := strconv.ParseUint(c, 10, 64)
+			if err != nil {
+				return fmt.Errorf("error parsing %v: %w", c,
+					err)
+			}
+			chanIDs = append(chanIDs, chanID)
 		}
-		rpcHops = append(rpcHops, pubkey[:])
 	}
 
 	var amtMsat int64
@@ -1651,6 +2205,16 @@ func buildRoute(ctx *cli.Context) error {
 		HopPubkeys:     rpcHops,
 		OutgoingChanId: ctx.Uint64("outgoing_chan_id"),
 		PaymentAddr:    payAddr,
+		ChanIds:        chanIDs,
+	}
+
+	for _, hopData := range ctx.StringSlice("hop_data") {
+		hopRecords, err := parseHopData(hopData)
+		if err != nil {
+			return err
+		}
+
+		req.HopCustomRecords = append(req.HopCustomRecords, hopRecords)
 	}
 
 	route, err := client.BuildRoute(ctxc, req)
@@ -1663,6 +2227,49 @@ func buildRoute(ctx *cli.Context) error {
 	return nil
 }
 
+// parseHopData parses the custom records of a hop in the format
+// hop_index:type=hexvalue,type=hexvalue.
+func parseHopData(hopData string) (*routerrpc.HopCustomRecords, error) {
+	idxStr, data, ok := strings.Cut(hopData, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid hop data %v: hop index "+
+			"missing", hopData)
+	}
+
+	idx, err := strconv.ParseUint(idxStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hop index: %w", err)
+	}
+
+	hopRecords := &routerrpc.HopCustomRecords{
+		HopIndex:      uint32(idx),
+		CustomRecords: make(map[uint64][]byte),
+	}
+	for _, r := range strings.Split(data, ",") {
+		kv := strings.Split(r, "=")
+		if len(kv) != 2 {
+			return nil, errors.New("invalid hop data format: " +
+				"multiple equal signs in record")
+		}
+
+		recordID, err := strconv.ParseUint(kv[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hop data format: %w",
+				err)
+		}
+
+		hexValue, err := hex.DecodeString(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hop data format: %w",
+				err)
+		}
+
+		hopRecords.CustomRecords[recordID] = hexValue
+	}
+
+	return hopRecords, nil
+}
+
 var deletePaymentsCommand = cli.Command{
 	Name:     "deletepayments",
 	Category: "Payments",
@@ -1802,6 +2409,100 @@ func deletePayments(ctx *cli.Context) error {
 	return nil
 }
 
+var pruneFailedAttemptsCommand = cli.Command{
+	Name:     "prunefailedattempts",
+	Category: "Payments",
+	Usage: "Delete the failed HTLC attempts of old payments from the " +
+		"database.",
+	Description: `
+	Delete the failed HTLC attempts of all payments that were created
+	longer ago than --older_than, while the payments themselves are kept.
+	Payments that are in flight are skipped.
+
+	With --dry_run, nothing is deleted and only the number of attempts and
+	the estimated space that would be freed are reported.
+
+	NOTE: The disk space is only reclaimed after compacting the database.
+	`,
+	Flags: []cli.Flag{
+		cli.DurationFlag{
+			Name: "older_than",
+			Usage: "only prune the failed attempts of payments " +
+				"created at least this long ago, e.g. 720h",
+		},
+		cli.BoolFlag{
+			Name:  "dry_run",
+			Usage: "only report what would be pruned",
+		},
+	},
+	Action: actionDecorator(pruneFailedAttempts),
+}
+
+func pruneFailedAttempts(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	olderThan := ctx.Duration("older_than")
+	if olderThan < 0 {
+		return fmt.Errorf("older_than must not be negative")
+	}
+
+	resp, err := client.PruneFailedPaymentAttempts(
+		ctxc, &lnrpc.PruneFailedPaymentAttemptsRequest{
+			OlderThanSeconds: uint64(olderThan.Seconds()),
+			DryRun:           ctx.Bool("dry_run"),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var subscribeHtlcAttemptsCommand = cli.Command{
+	Name:     "subscribehtlcattempts",
+	Category: "Payments",
+	Usage:    "Stream the HTLC attempts of all payments.",
+	Description: `
+	Stream an event whenever an HTLC attempt of any payment is launched,
+	settled or failed. Failure events include the index of the node that
+	reported the failure and the decoded failure message. The stream starts
+	with the current state of the attempts of all payments in flight.
+	`,
+	Action: actionDecorator(subscribeHtlcAttempts),
+}
+
+func subscribeHtlcAttempts(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	stream, err := client.SubscribeHtlcAttempts(
+		ctxc, &routerrpc.SubscribeHtlcAttemptsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		printRespJSON(event)
+	}
+}
+
 var estimateRouteFeeCommand = cli.Command{
 	Name:     "estimateroutefee",
 	Category: "Payments",
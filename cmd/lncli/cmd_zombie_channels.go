@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/urfave/cli"
+)
+
+var listZombieChannelsCommand = cli.Command{
+	Name:     "listzombiechannels",
+	Category: "Graph",
+	Usage:    "List the channels of the zombie index.",
+	Description: `
+	List all channels that are marked as zombies. Zombie channels are
+	ignored by path finding and their updates are rejected.
+	`,
+	Action: actionDecorator(listZombieChannels),
+}
+
+func listZombieChannels(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	resp, err := client.ListZombieChannels(
+		ctxc, &routerrpc.ListZombieChannelsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var resurrectZombieChannelCommand = cli.Command{
+	Name:     "resurrectzombiechannel",
+	Category: "Graph",
+	Usage:    "Remove a channel from the zombie index.",
+	Description: `
+	Remove a channel that was falsely marked as a zombie from the zombie
+	index. If the channel was removed from the graph, it is added back if
+	its funding output is still unspent. It is used for path finding again
+	once its updates are received. Channels that were pruned before their
+	information was kept in the zombie index are added back the next time
+	their announcement is received.
+	`,
+	ArgsUsage: "chan_id",
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name:  "chan_id",
+			Usage: "the 8-byte compact channel ID to resurrect",
+		},
+	},
+	Action: actionDecorator(resurrectZombieChannel),
+}
+
+func resurrectZombieChannel(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	var (
+		chanID uint64
+		err    error
+	)
+	switch {
+	case ctx.IsSet("chan_id"):
+		chanID = ctx.Uint64("chan_id")
+
+	case ctx.Args().Present():
+		chanID, err = strconv.ParseUint(ctx.Args().First(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing chan_id: %w", err)
+		}
+
+	default:
+		return fmt.Errorf("chan_id argument missing")
+	}
+
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	resp, err := client.ResurrectZombieChannel(
+		ctxc, &routerrpc.ResurrectZombieChannelRequest{
+			ChanId: chanID,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var pruneZombieChannelsCommand = cli.Command{
+	Name:     "prunezombiechannels",
+	Category: "Graph",
+	Usage:    "Prune zombie channels from the graph right away.",
+	Description: `
+	Examine the graph for zombie channels and prune them right away instead
+	of waiting for the next prune interval.
+	`,
+	Action: actionDecorator(pruneZombieChannels),
+}
+
+func pruneZombieChannels(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	resp, err := client.PruneZombieChannels(
+		ctxc, &routerrpc.PruneZombieChannelsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
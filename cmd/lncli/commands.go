@@ -14,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
@@ -1205,6 +1206,187 @@ func closeAllChannels(ctx *cli.Context) error {
 	return nil
 }
 
+var batchCloseChannelCommand = cli.Command{
+	Name:     "batchclosechannel",
+	Category: "Channels",
+	Usage:    "Cooperatively close multiple channels at once.",
+	Description: `
+	Cooperatively close a set of channels, specified by repeating the
+	--chan_point flag. All closures are negotiated concurrently using the
+	same fee parameters. Before any closure is started, lnd verifies that
+	all given channels can be closed cooperatively, so the request either
+	starts closing every channel or none of them.
+
+	The fee parameters --conf_target, --sat_per_vbyte and --max_fee_rate
+	behave as for the closechannel command, but apply to all closing
+	transactions. Each channel is closed by its own transaction, as the
+	closing transaction is negotiated separately with every peer.
+
+	Without --delivery_addr, the funds of every channel are sent to a fresh
+	address of the wallet. A delivery address that is given for more than
+	one channel requires --reuse_delivery_addr, as the closing transactions
+	could then be linked through the shared address.
+
+	The optional --max_fee sets a total fee budget for the whole batch. It
+	is split evenly between the channels and lowers the maximum fee rate of
+	each closing transaction, so that the batch doesn't pay more than the
+	budget in fees.
+
+	The result of each closure is printed as soon as its closing
+	transaction is broadcast, or, if --block is set, once it is confirmed.`,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name: "chan_point",
+			Usage: "the channel point of a channel to close in " +
+				"the format txid:output_index; can be " +
+				"specified multiple times",
+		},
+		cli.BoolFlag{
+			Name:  "block",
+			Usage: "block until all channels are closed",
+		},
+		cli.Int64Flag{
+			Name: "conf_target",
+			Usage: "(optional) the number of blocks that the " +
+				"transactions *should* confirm in, will be " +
+				"used for fee estimation",
+		},
+		cli.Uint64Flag{
+			Name: "sat_per_vbyte",
+			Usage: "(optional) a manual fee expressed in " +
+				"sat/vbyte that should be used when crafting " +
+				"the transactions; default is a conf-target " +
+				"of 6 blocks",
+		},
+		cli.StringFlag{
+			Name: "delivery_addr",
+			Usage: "(optional) an address to deliver funds " +
+				"upon cooperative channel closing, may only " +
+				"be used if no channel has an upfront " +
+				"shutdown address set",
+		},
+		cli.BoolFlag{
+			Name: "reuse_delivery_addr",
+			Usage: "(optional) allow the delivery address to be " +
+				"used for all channels of the batch",
+		},
+		cli.Uint64Flag{
+			Name: "max_fee_rate",
+			Usage: "(optional) maximum fee rate in sat/vbyte " +
+				"accepted during the negotiations (default " +
+				"is x3 of the desired fee rate)",
+		},
+		cli.Uint64Flag{
+			Name: "max_fee",
+			Usage: "(optional) the maximum total fee in " +
+				"satoshis paid by all closing transactions " +
+				"of the batch",
+		},
+	},
+	Action: actionDecorator(batchCloseChannel),
+}
+
+func batchCloseChannel(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	// Show command help if no flags were provided.
+	if ctx.NumFlags() == 0 {
+		cli.ShowCommandHelp(ctx, "batchclosechannel")
+		return nil
+	}
+
+	chanPointStrs := ctx.StringSlice("chan_point")
+	if len(chanPointStrs) == 0 {
+		return errors.New("at least one chan_point must be specified")
+	}
+
+	req := &lnrpc.BatchCloseChannelRequest{
+		TargetConf:           int32(ctx.Int64("conf_target")),
+		SatPerVbyte:          ctx.Uint64("sat_per_vbyte"),
+		DeliveryAddress:      ctx.String("delivery_addr"),
+		MaxFeePerVbyte:       ctx.Uint64("max_fee_rate"),
+		MaxFeeSat:            ctx.Uint64("max_fee"),
+		ReuseDeliveryAddress: ctx.Bool("reuse_delivery_addr"),
+	}
+	for _, chanPointStr := range chanPointStrs {
+		chanPoint, err := parseChanPoint(chanPointStr)
+		if err != nil {
+			return fmt.Errorf("unable to parse chan_point %v: %w",
+				chanPointStr, err)
+		}
+		req.ChannelPoints = append(req.ChannelPoints, chanPoint)
+	}
+
+	stream, err := client.BatchCloseChannel(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	// result defines the result of closing a single channel of the batch.
+	// The closing transaction ID is populated if the closing transaction
+	// was broadcast. Otherwise, the error that prevented closing the
+	// channel is populated.
+	type result struct {
+		ChannelPoint string `json:"channel_point"`
+		ClosingTxid  string `json:"closing_txid"`
+		FailErr      string `json:"error"`
+	}
+
+	block := ctx.Bool("block")
+	for pending := len(req.ChannelPoints); pending > 0; {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		chanPoint, err := lnrpc.GetChanPointFundingTxid(
+			resp.ChannelPoint,
+		)
+		if err != nil {
+			return err
+		}
+		res := result{
+			ChannelPoint: fmt.Sprintf("%v:%d", chanPoint,
+				resp.ChannelPoint.GetOutputIndex()),
+			FailErr: resp.Error,
+		}
+
+		var closingTxid []byte
+		switch update := resp.GetUpdate().GetUpdate().(type) {
+		case *lnrpc.CloseStatusUpdate_ClosePending:
+			if block {
+				continue
+			}
+			closingTxid = update.ClosePending.Txid
+
+		case *lnrpc.CloseStatusUpdate_ChanClose:
+			closingTxid = update.ChanClose.ClosingTxid
+
+		default:
+			if resp.Error == "" {
+				continue
+			}
+		}
+
+		if closingTxid != nil {
+			txid, err := chainhash.NewHash(closingTxid)
+			if err != nil {
+				return err
+			}
+			res.ClosingTxid = txid.String()
+		}
+
+		printJSON(res)
+		pending--
+	}
+
+	return nil
+}
+
 // promptForConfirmation continuously prompts the user for the message until
 // receiving a response of "yes" or "no" and returns their answer as a bool.
 func promptForConfirmation(msg string) bool {
@@ -1387,6 +1569,95 @@ func listPeers(ctx *cli.Context) error {
 	return nil
 }
 
+var setPeerChannelPolicyCommand = cli.Command{
+	Name:      "setpeerchannelpolicy",
+	Category:  "Peers",
+	Usage:     "Set the inbound channel policy of a peer.",
+	ArgsUsage: "node_key",
+	Description: `
+	Set the policy that is applied to inbound channels opened by the given
+	peer. The policy allows accepting zero-conf channels from trusted peers
+	only, and requiring scid-alias channels on a per-peer basis.
+
+	Setting neither --allow_zero_conf nor --require_scid_alias removes the
+	policy of the peer.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "node_key",
+			Usage: "the hex-encoded compressed public key of the " +
+				"peer",
+		},
+		cli.BoolFlag{
+			Name: "allow_zero_conf",
+			Usage: "accept zero-conf channels from the peer; " +
+				"requires the zero-conf protocol option",
+		},
+		cli.BoolFlag{
+			Name: "require_scid_alias",
+			Usage: "only accept scid-alias channels from the " +
+				"peer; requires the scid-alias protocol option",
+		},
+	},
+	Action: actionDecorator(setPeerChannelPolicy),
+}
+
+func setPeerChannelPolicy(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	var pubKeyStr string
+	switch {
+	case ctx.IsSet("node_key"):
+		pubKeyStr = ctx.String("node_key")
+	case ctx.Args().Present():
+		pubKeyStr = ctx.Args().First()
+	default:
+		return fmt.Errorf("node_key argument missing")
+	}
+
+	pubKey, err := hex.DecodeString(pubKeyStr)
+	if err != nil {
+		return fmt.Errorf("unable to decode node_key: %w", err)
+	}
+
+	req := &lnrpc.SetPeerChannelPolicyRequest{
+		PubKey:           pubKey,
+		AllowZeroConf:    ctx.Bool("allow_zero_conf"),
+		RequireScidAlias: ctx.Bool("require_scid_alias"),
+	}
+	resp, err := client.SetPeerChannelPolicy(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+var listPeerChannelPoliciesCommand = cli.Command{
+	Name:     "listpeerchannelpolicies",
+	Category: "Peers",
+	Usage:    "List the inbound channel policies of all peers.",
+	Action:   actionDecorator(listPeerChannelPolicies),
+}
+
+func listPeerChannelPolicies(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListPeerChannelPolicies(
+		ctxc, &lnrpc.ListPeerChannelPoliciesRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
 var walletBalanceCommand = cli.Command{
 	Name:     "walletbalance",
 	Category: "Wallet",
@@ -1730,6 +2001,17 @@ var describeGraphCommand = cli.Command{
 				"graph. Unannounced channels are both private channels, and " +
 				"public channels that are not yet announced to the network.",
 		},
+		cli.UintFlag{
+			Name: "page_size",
+			Usage: "if set, only return up to this many " +
+				"nodes and edges, followed by a cursor to " +
+				"fetch the next page",
+		},
+		cli.StringFlag{
+			Name: "cursor",
+			Usage: "the hex encoded next_cursor of the previous " +
+				"page",
+		},
 	},
 	Action: actionDecorator(describeGraph),
 }
@@ -1739,8 +2021,15 @@ func describeGraph(ctx *cli.Context) error {
 	client, cleanUp := getClient(ctx)
 	defer cleanUp()
 
+	cursor, err := hex.DecodeString(ctx.String("cursor"))
+	if err != nil {
+		return fmt.Errorf("invalid cursor: %w", err)
+	}
+
 	req := &lnrpc.ChannelGraphRequest{
 		IncludeUnannounced: ctx.Bool("include_unannounced"),
+		PageSize:           uint32(ctx.Uint("page_size")),
+		Cursor:             cursor,
 	}
 
 	graph, err := client.DescribeGraph(ctxc, req)
@@ -1778,6 +2067,93 @@ func getNodeMetrics(ctx *cli.Context) error {
 	return nil
 }
 
+var startGraphAnalysisCommand = cli.Command{
+	Name:     "startgraphanalysis",
+	Category: "Graph",
+	Usage:    "Start computing centrality and connectivity metrics.",
+	Description: `
+	Start a background job that computes centrality and connectivity
+	metrics over the current graph. The returned job ID can be passed to
+	getgraphanalysis to query the progress and the results of the job.
+
+	The supported metrics are betweenness_centrality,
+	eigenvector_centrality and articulation_points. If no metric is given,
+	all of them are computed.
+	`,
+	Flags: []cli.Flag{
+		cli.StringSliceFlag{
+			Name: "metric",
+			Usage: "a metric to compute, can be specified " +
+				"multiple times",
+		},
+	},
+	Action: actionDecorator(startGraphAnalysis),
+}
+
+func startGraphAnalysis(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	req := &lnrpc.StartGraphAnalysisRequest{}
+	for _, metric := range ctx.StringSlice("metric") {
+		metricType, ok := lnrpc.NodeMetricType_value[strings.ToUpper(
+			metric,
+		)]
+		if !ok {
+			return fmt.Errorf("unknown metric %v", metric)
+		}
+
+		req.Types = append(
+			req.Types, lnrpc.NodeMetricType(metricType),
+		)
+	}
+
+	resp, err := client.StartGraphAnalysis(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var getGraphAnalysisCommand = cli.Command{
+	Name:      "getgraphanalysis",
+	Category:  "Graph",
+	Usage:     "Get the progress and results of a graph analysis.",
+	ArgsUsage: "job_id",
+	Action:    actionDecorator(getGraphAnalysis),
+}
+
+func getGraphAnalysis(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	if !ctx.Args().Present() {
+		return fmt.Errorf("job_id argument missing")
+	}
+
+	jobID, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("error parsing job_id: %w", err)
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.GetGraphAnalysis(ctxc, &lnrpc.GraphAnalysisRequest{
+		JobId: jobID,
+	})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 var getChanInfoCommand = cli.Command{
 	Name:     "getchaninfo",
 	Category: "Graph",
@@ -2173,6 +2549,130 @@ func feeReport(ctx *cli.Context) error {
 	return nil
 }
 
+var peerFeeReportCommand = cli.Command{
+	Name:     "peerfeereport",
+	Category: "Payments",
+	Usage: "Compare the fees paid to peers with their advertised " +
+		"policies.",
+	Description: `
+	Summarizes the fees that were actually paid to each peer for forwarding
+	the settled HTLCs of our payments and compares them with the fees that
+	the channel policies of the peer, including inbound fees, advertised
+	when the HTLCs were sent. HTLCs sent before the latest policy update of
+	the channels are reported as unknown. Peers whose real cost diverges the
+	most from their gossip are listed first.`,
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name: "start_time",
+			Usage: "the unix timestamp in seconds from which " +
+				"payments are taken into account, defaults " +
+				"to 30 days before the end time",
+		},
+		cli.Uint64Flag{
+			Name: "end_time",
+			Usage: "the unix timestamp in seconds until which " +
+				"payments are taken into account",
+		},
+	},
+	Action: actionDecorator(peerFeeReport),
+}
+
+func peerFeeReport(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	req := &lnrpc.PeerFeeReportRequest{
+		StartTime: ctx.Uint64("start_time"),
+		EndTime:   ctx.Uint64("end_time"),
+	}
+	resp, err := client.PeerFeeReport(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+// policyUpdateFlags are the flags of the updatechanpolicy command that
+// describe the new channel policy.
+var policyUpdateFlags = []cli.Flag{
+	cli.Int64Flag{
+		Name: "base_fee_msat",
+		Usage: "the base fee in milli-satoshis that will be " +
+			"charged for each forwarded HTLC, regardless " +
+			"of payment size",
+	},
+	cli.StringFlag{
+		Name: "fee_rate",
+		Usage: "the fee rate that will be charged " +
+			"proportionally based on the value of each " +
+			"forwarded HTLC, the lowest possible rate is " +
+			"0 with a granularity of 0.000001 " +
+			"(millionths). Can not be set at the same " +
+			"time as fee_rate_ppm",
+	},
+	cli.Uint64Flag{
+		Name: "fee_rate_ppm",
+		Usage: "the fee rate ppm (parts per million) that " +
+			"will be charged proportionally based on the " +
+			"value of each forwarded HTLC, the lowest " +
+			"possible rate is 0 with a granularity of " +
+			"0.000001 (millionths). Can not be set at " +
+			"the same time as fee_rate",
+	},
+	cli.Int64Flag{
+		Name: "inbound_base_fee_msat",
+		Usage: "the base inbound fee in milli-satoshis that " +
+			"will be charged for each forwarded HTLC, " +
+			"regardless of payment size. Its value must " +
+			"be zero or negative - it is a discount " +
+			"for using a particular incoming channel. " +
+			"Note that forwards will be rejected if the " +
+			"discount exceeds the outbound fee " +
+			"(forward at a loss), and lead to " +
+			"penalization by the sender",
+	},
+	cli.Int64Flag{
+		Name: "inbound_fee_rate_ppm",
+		Usage: "the inbound fee rate that will be charged " +
+			"proportionally based on the value of each " +
+			"forwarded HTLC and the outbound fee. Fee " +
+			"rate is expressed in parts per million and " +
+			"must be zero or negative - it is a discount " +
+			"for using a particular incoming channel." +
+			"Note that forwards will be rejected if the " +
+			"discount exceeds the outbound fee " +
+			"(forward at a loss), and lead to " +
+			"penalization by the sender",
+	},
+	cli.Uint64Flag{
+		Name: "time_lock_delta",
+		Usage: "the CLTV delta that will be applied to all " +
+			"forwarded HTLCs",
+	},
+	cli.Uint64Flag{
+		Name: "min_htlc_msat",
+		Usage: "if set, the min HTLC size that will be " +
+			"applied to all forwarded HTLCs. If unset, " +
+			"the min HTLC is left unchanged",
+	},
+	cli.Uint64Flag{
+		Name: "max_htlc_msat",
+		Usage: "if set, the max HTLC size that will be " +
+			"applied to all forwarded HTLCs. If unset, " +
+			"the max HTLC is left unchanged",
+	},
+	cli.StringFlag{
+		Name: "chan_point",
+		Usage: "the channel which this policy update should " +
+			"be applied to. If nil, the policies for all " +
+			"channels will be updated. Takes the form of " +
+			"txid:output_index",
+	},
+}
+
 var updateChannelPolicyCommand = cli.Command{
 	Name:     "updatechanpolicy",
 	Category: "Channels",
@@ -2186,81 +2686,7 @@ var updateChannelPolicyCommand = cli.Command{
 	and broadcast to the rest of the network within the next batch. Channel
         points are encoded as: funding_txid:output_index
 	`,
-	Flags: []cli.Flag{
-		cli.Int64Flag{
-			Name: "base_fee_msat",
-			Usage: "the base fee in milli-satoshis that will be " +
-				"charged for each forwarded HTLC, regardless " +
-				"of payment size",
-		},
-		cli.StringFlag{
-			Name: "fee_rate",
-			Usage: "the fee rate that will be charged " +
-				"proportionally based on the value of each " +
-				"forwarded HTLC, the lowest possible rate is " +
-				"0 with a granularity of 0.000001 " +
-				"(millionths). Can not be set at the same " +
-				"time as fee_rate_ppm",
-		},
-		cli.Uint64Flag{
-			Name: "fee_rate_ppm",
-			Usage: "the fee rate ppm (parts per million) that " +
-				"will be charged proportionally based on the " +
-				"value of each forwarded HTLC, the lowest " +
-				"possible rate is 0 with a granularity of " +
-				"0.000001 (millionths). Can not be set at " +
-				"the same time as fee_rate",
-		},
-		cli.Int64Flag{
-			Name: "inbound_base_fee_msat",
-			Usage: "the base inbound fee in milli-satoshis that " +
-				"will be charged for each forwarded HTLC, " +
-				"regardless of payment size. Its value must " +
-				"be zero or negative - it is a discount " +
-				"for using a particular incoming channel. " +
-				"Note that forwards will be rejected if the " +
-				"discount exceeds the outbound fee " +
-				"(forward at a loss), and lead to " +
-				"penalization by the sender",
-		},
-		cli.Int64Flag{
-			Name: "inbound_fee_rate_ppm",
-			Usage: "the inbound fee rate that will be charged " +
-				"proportionally based on the value of each " +
-				"forwarded HTLC and the outbound fee. Fee " +
-				"rate is expressed in parts per million and " +
-				"must be zero or negative - it is a discount " +
-				"for using a particular incoming channel." +
-				"Note that forwards will be rejected if the " +
-				"discount exceeds the outbound fee " +
-				"(forward at a loss), and lead to " +
-				"penalization by the sender",
-		},
-		cli.Uint64Flag{
-			Name: "time_lock_delta",
-			Usage: "the CLTV delta that will be applied to all " +
-				"forwarded HTLCs",
-		},
-		cli.Uint64Flag{
-			Name: "min_htlc_msat",
-			Usage: "if set, the min HTLC size that will be " +
-				"applied to all forwarded HTLCs. If unset, " +
-				"the min HTLC is left unchanged",
-		},
-		cli.Uint64Flag{
-			Name: "max_htlc_msat",
-			Usage: "if set, the max HTLC size that will be " +
-				"applied to all forwarded HTLCs. If unset, " +
-				"the max HTLC is left unchanged",
-		},
-		cli.StringFlag{
-			Name: "chan_point",
-			Usage: "the channel which this policy update should " +
-				"be applied to. If nil, the policies for all " +
-				"channels will be updated. Takes the form of " +
-				"txid:output_index",
-		},
-	},
+	Flags:  policyUpdateFlags,
 	Action: actionDecorator(updateChannelPolicy),
 }
 
@@ -2310,6 +2736,40 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	client, cleanUp := getClient(ctx)
 	defer cleanUp()
 
+	req, err := parsePolicyUpdateRequest(ctx, ctx.Args())
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.UpdateChannelPolicy(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	// Parse the response into the final json object that will be printed
+	// to stdout. At the moment, this filters out the raw txid bytes from
+	// each failed update's outpoint and only prints the txid string.
+	var listFailedUpdateResp = struct {
+		FailedUpdates []*FailedUpdate `json:"failed_updates"`
+	}{
+		FailedUpdates: make([]*FailedUpdate, 0, len(resp.FailedUpdates)),
+	}
+	for _, protoUpdate := range resp.FailedUpdates {
+		failedUpdate := NewFailedUpdateFromProto(protoUpdate)
+		listFailedUpdateResp.FailedUpdates = append(
+			listFailedUpdateResp.FailedUpdates, failedUpdate)
+	}
+
+	printJSON(listFailedUpdateResp)
+
+	return nil
+}
+
+// parsePolicyUpdateRequest assembles a policy update request from the
+// policyUpdateFlags and the positional arguments of a command.
+func parsePolicyUpdateRequest(ctx *cli.Context,
+	args cli.Args) (*lnrpc.PolicyUpdateRequest, error) {
+
 	var (
 		baseFee       int64
 		feeRate       float64
@@ -2317,7 +2777,6 @@ func updateChannelPolicy(ctx *cli.Context) error {
 		timeLockDelta uint16
 		err           error
 	)
-	args := ctx.Args()
 
 	switch {
 	case ctx.IsSet("base_fee_msat"):
@@ -2325,17 +2784,18 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	case args.Present():
 		baseFee, err = strconv.ParseInt(args.First(), 10, 64)
 		if err != nil {
-			return fmt.Errorf("unable to decode base_fee_msat: %w",
-				err)
+			return nil, fmt.Errorf("unable to decode "+
+				"base_fee_msat: %w", err)
 		}
 		args = args.Tail()
 	default:
-		return fmt.Errorf("base_fee_msat argument missing")
+		return nil, fmt.Errorf("base_fee_msat argument missing")
 	}
 
 	switch {
 	case ctx.IsSet("fee_rate") && ctx.IsSet("fee_rate_ppm"):
-		return fmt.Errorf("fee_rate or fee_rate_ppm can not both be set")
+		return nil, fmt.Errorf("fee_rate or fee_rate_ppm can not " +
+			"both be set")
 	case ctx.IsSet("fee_rate"):
 		feeRate = ctx.Float64("fee_rate")
 	case ctx.IsSet("fee_rate_ppm"):
@@ -2343,12 +2803,14 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	case args.Present():
 		feeRate, err = strconv.ParseFloat(args.First(), 64)
 		if err != nil {
-			return fmt.Errorf("unable to decode fee_rate: %w", err)
+			return nil, fmt.Errorf("unable to decode fee_rate: %w",
+				err)
 		}
 
 		args = args.Tail()
 	default:
-		return fmt.Errorf("fee_rate or fee_rate_ppm argument missing")
+		return nil, fmt.Errorf("fee_rate or fee_rate_ppm argument " +
+			"missing")
 	}
 
 	switch {
@@ -2356,17 +2818,17 @@ func updateChannelPolicy(ctx *cli.Context) error {
 		timeLockDeltaStr := ctx.String("time_lock_delta")
 		timeLockDelta, err = parseTimeLockDelta(timeLockDeltaStr)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	case args.Present():
 		timeLockDelta, err = parseTimeLockDelta(args.First())
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		args = args.Tail()
 	default:
-		return fmt.Errorf("time_lock_delta argument missing")
+		return nil, fmt.Errorf("time_lock_delta argument missing")
 	}
 
 	var (
@@ -2384,7 +2846,8 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	if chanPointStr != "" {
 		chanPoint, err = parseChanPoint(chanPointStr)
 		if err != nil {
-			return fmt.Errorf("unable to parse chan_point: %w", err)
+			return nil, fmt.Errorf("unable to parse chan_point: "+
+				"%w", err)
 		}
 	}
 
@@ -2392,14 +2855,14 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	if inboundBaseFeeMsat < math.MinInt32 ||
 		inboundBaseFeeMsat > math.MaxInt32 {
 
-		return errors.New("inbound_base_fee_msat out of range")
+		return nil, errors.New("inbound_base_fee_msat out of range")
 	}
 
 	inboundFeeRatePpm := ctx.Int64("inbound_fee_rate_ppm")
 	if inboundFeeRatePpm < math.MinInt32 ||
 		inboundFeeRatePpm > math.MaxInt32 {
 
-		return errors.New("inbound_fee_rate_ppm out of range")
+		return nil, errors.New("inbound_fee_rate_ppm out of range")
 	}
 
 	// Inbound fees are optional. However, if an update is required,
@@ -2408,7 +2871,7 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	if ctx.IsSet("inbound_base_fee_msat") !=
 		ctx.IsSet("inbound_fee_rate_ppm") {
 
-		return errors.New("both parameters must be provided: " +
+		return nil, errors.New("both parameters must be provided: " +
 			"inbound_base_fee_msat and inbound_fee_rate_ppm")
 	}
 
@@ -2447,18 +2910,250 @@ func updateChannelPolicy(ctx *cli.Context) error {
 		req.FeeRatePpm = uint32(feeRatePpm)
 	}
 
-	resp, err := client.UpdateChannelPolicy(ctxc, req)
+	return req, nil
+}
+
+var scheduleChanPolicyCommand = cli.Command{
+	Name:     "schedulechanpolicy",
+	Category: "Channels",
+	Usage: "Schedule a channel policy update for all channels, or a " +
+		"single channel.",
+	ArgsUsage: "--start_time=T [--interval=D] base_fee_msat fee_rate " +
+		"time_lock_delta [--max_htlc_msat=N] [channel_point]",
+	Description: `
+	Schedules a channel policy update that is applied at the given start
+	time, expressed as a unix timestamp. The policy is specified in the
+	same way as for the updatechanpolicy command.
+
+	If an interval is given, the update is repeated at that interval,
+	which allows setting up recurring fee plans, for example raising
+	fees every evening and lowering them again every morning. For
+	repeating updates, a start time in the past denotes the first
+	occurrence of the plan.
+	`,
+	Flags: append([]cli.Flag{
+		cli.Int64Flag{
+			Name: "start_time",
+			Usage: "the unix timestamp at which the update is " +
+				"first applied",
+		},
+		cli.DurationFlag{
+			Name: "interval",
+			Usage: "if set, the interval at which the update is " +
+				"repeated, e.g. 24h",
+		},
+	}, policyUpdateFlags...),
+	Action: actionDecorator(scheduleChanPolicy),
+}
+
+func scheduleChanPolicy(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	if !ctx.IsSet("start_time") {
+		return fmt.Errorf("start_time argument missing")
+	}
+
+	update, err := parsePolicyUpdateRequest(ctx, ctx.Args())
 	if err != nil {
 		return err
 	}
 
-	// Parse the response into the final json object that will be printed
-	// to stdout. At the moment, this filters out the raw txid bytes from
-	// each failed update's outpoint and only prints the txid string.
+	resp, err := client.SchedulePolicyUpdate(
+		ctxc, &lnrpc.SchedulePolicyUpdateRequest{
+			Update:    update,
+			StartTime: ctx.Int64("start_time"),
+			IntervalSeconds: uint64(
+				ctx.Duration("interval") / time.Second,
+			),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listChanPolicyScheduleCommand = cli.Command{
+	Name:     "listchanpolicyschedule",
+	Category: "Channels",
+	Usage:    "List all scheduled channel policy updates.",
+	Action:   actionDecorator(listChanPolicySchedule),
+}
+
+func listChanPolicySchedule(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListScheduledPolicyUpdates(
+		ctxc, &lnrpc.ListScheduledPolicyUpdatesRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var cancelChanPolicyScheduleCommand = cli.Command{
+	Name:      "cancelchanpolicyschedule",
+	Category:  "Channels",
+	Usage:     "Cancel a scheduled channel policy update.",
+	ArgsUsage: "id",
+	Description: `
+	Removes the scheduled channel policy update with the given id. The id
+	is returned by the schedulechanpolicy and listchanpolicyschedule
+	commands.
+	`,
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name:  "id",
+			Usage: "the id of the scheduled update to cancel",
+		},
+	},
+	Action: actionDecorator(cancelChanPolicySchedule),
+}
+
+func cancelChanPolicySchedule(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	var (
+		id   uint64
+		err  error
+		args = ctx.Args()
+	)
+	switch {
+	case ctx.IsSet("id"):
+		id = ctx.Uint64("id")
+	case args.Present():
+		id, err = strconv.ParseUint(args.First(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("unable to decode id: %w", err)
+		}
+	default:
+		return fmt.Errorf("id argument missing")
+	}
+
+	resp, err := client.CancelScheduledPolicyUpdate(
+		ctxc, &lnrpc.CancelScheduledPolicyUpdateRequest{
+			Id: id,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var updateCommitFeePolicyCommand = cli.Command{
+	Name:     "updatecommitfeepolicy",
+	Category: "Channels",
+	Usage: "Update the commitment fee policy for all channels, or a " +
+		"single channel.",
+	ArgsUsage: "[--conf_target=N] [--min_sat_per_vbyte=N] " +
+		"[--max_sat_per_vbyte=N] [--chan_point=txid:output_index]",
+	Description: `
+	Updates the policy that governs the commitment fee rate for all
+	channels, or just a particular channel identified by its channel point.
+	The policy only takes effect for channels that we initiated, as only
+	the initiator pays the commitment fee. The new policy is applied with
+	the next commitment fee update. Values that are not set are reset to
+	their defaults.
+	`,
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name: "conf_target",
+			Usage: "the confirmation target in blocks used to " +
+				"estimate the commitment fee rate, if unset " +
+				"the default of 3 blocks is used",
+		},
+		cli.Uint64Flag{
+			Name: "min_sat_per_vbyte",
+			Usage: "the fee rate in sat/vbyte that the " +
+				"commitment fee rate must not drop below. " +
+				"For anchor channels it is capped at the " +
+				"maximum anchor commitment fee rate",
+		},
+		cli.Uint64Flag{
+			Name: "max_sat_per_vbyte",
+			Usage: "the fee rate in sat/vbyte that the " +
+				"commitment fee rate must not exceed. For " +
+				"anchor channels it replaces the global " +
+				"maximum anchor commitment fee rate",
+		},
+		cli.StringFlag{
+			Name: "chan_point",
+			Usage: "the channel which this policy update should " +
+				"be applied to. If nil, the policies for all " +
+				"channels will be updated. Takes the form of " +
+				"txid:output_index",
+		},
+	},
+	Action: actionDecorator(updateCommitFeePolicy),
+}
+
+func updateCommitFeePolicy(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	if ctx.NArg() > 0 {
+		return fmt.Errorf("unexpected argument: %v",
+			ctx.Args().First())
+	}
+
+	confTarget := ctx.Uint64("conf_target")
+	if confTarget > math.MaxUint32 {
+		return fmt.Errorf("conf_target %v out of range", confTarget)
+	}
+
+	req := &lnrpc.CommitFeePolicyUpdateRequest{
+		Policy: &lnrpc.CommitFeePolicy{
+			ConfTarget:     uint32(confTarget),
+			MinSatPerVbyte: ctx.Uint64("min_sat_per_vbyte"),
+			MaxSatPerVbyte: ctx.Uint64("max_sat_per_vbyte"),
+		},
+	}
+
+	if ctx.IsSet("chan_point") {
+		chanPoint, err := parseChanPoint(ctx.String("chan_point"))
+		if err != nil {
+			return fmt.Errorf("unable to parse chan_point: %w", err)
+		}
+
+		req.Scope = &lnrpc.CommitFeePolicyUpdateRequest_ChanPoint{
+			ChanPoint: chanPoint,
+		}
+	} else {
+		req.Scope = &lnrpc.CommitFeePolicyUpdateRequest_Global{
+			Global: true,
+		}
+	}
+
+	resp, err := client.UpdateCommitFeePolicy(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	// Only print the txid string of the outpoints of failed updates.
 	var listFailedUpdateResp = struct {
 		FailedUpdates []*FailedUpdate `json:"failed_updates"`
 	}{
-		FailedUpdates: make([]*FailedUpdate, 0, len(resp.FailedUpdates)),
+		FailedUpdates: make(
+			[]*FailedUpdate, 0, len(resp.FailedUpdates),
+		),
 	}
 	for _, protoUpdate := range resp.FailedUpdates {
 		failedUpdate := NewFailedUpdateFromProto(protoUpdate)
@@ -2471,6 +3166,162 @@ func updateChannelPolicy(ctx *cli.Context) error {
 	return nil
 }
 
+var setChanSweepBudgetCommand = cli.Command{
+	Name:     "setchansweepbudget",
+	Category: "Channels",
+	Usage: "Set the budget used when sweeping the outputs of a force " +
+		"closed channel.",
+	ArgsUsage: "--chan_point=txid:output_index [--tolocal=N] " +
+		"[--tolocalratio=F] [--anchorcpfp=N] [--anchorcpfpratio=F] " +
+		"[--deadlinehtlc=N] [--deadlinehtlcratio=F] " +
+		"[--nodeadlinehtlc=N] [--nodeadlinehtlcratio=F] [--total=N]",
+	Description: `
+	Sets the budget that is used to pay fees when sweeping the outputs of
+	the given channel once it has been force closed. The budget also
+	applies to channels that are already being resolved, for all outputs
+	that are offered to the sweeper from then on.
+
+	Values that are not set fall back to the global budget configured with
+	the sweeper.budget options. Setting none of the values removes the
+	channel's budget.
+
+	The total budget caps the sum of the budgets of all outputs of the
+	channel. Outputs whose budget exceeds the rest of the total budget are
+	swept with the rest only, or deferred if nothing is left.
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "chan_point",
+			Usage: "the channel whose budget should be set. " +
+				"Takes the form of txid:output_index",
+		},
+		cli.Uint64Flag{
+			Name: "tolocal",
+			Usage: "the amount in satoshis to allocate as the " +
+				"budget when sweeping the to_local output",
+		},
+		cli.Float64Flag{
+			Name: "tolocalratio",
+			Usage: "the ratio of the to_local output value to " +
+				"allocate as the budget when sweeping it",
+		},
+		cli.Uint64Flag{
+			Name: "anchorcpfp",
+			Usage: "the amount in satoshis to allocate as the " +
+				"budget when CPFPing the force close tx " +
+				"using the anchor output",
+		},
+		cli.Float64Flag{
+			Name: "anchorcpfpratio",
+			Usage: "the ratio of the value of the " +
+				"time-sensitive HTLCs to allocate as the " +
+				"budget when CPFPing the force close tx " +
+				"using the anchor output",
+		},
+		cli.Uint64Flag{
+			Name: "deadlinehtlc",
+			Usage: "the amount in satoshis to allocate as the " +
+				"budget when sweeping a time-sensitive " +
+				"(first-level) HTLC",
+		},
+		cli.Float64Flag{
+			Name: "deadlinehtlcratio",
+			Usage: "the ratio of the value of a " +
+				"time-sensitive (first-level) HTLC to " +
+				"allocate as the budget when sweeping it",
+		},
+		cli.Uint64Flag{
+			Name: "nodeadlinehtlc",
+			Usage: "the amount in satoshis to allocate as the " +
+				"budget when sweeping a non-time-sensitive " +
+				"(second-level) HTLC",
+		},
+		cli.Float64Flag{
+			Name: "nodeadlinehtlcratio",
+			Usage: "the ratio of the value of a " +
+				"non-time-sensitive (second-level) HTLC to " +
+				"allocate as the budget when sweeping it",
+		},
+		cli.Uint64Flag{
+			Name: "total",
+			Usage: "the total amount in satoshis to allocate as " +
+				"the budget when sweeping all outputs of the " +
+				"channel",
+		},
+	},
+	Action: actionDecorator(setChanSweepBudget),
+}
+
+func setChanSweepBudget(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	if ctx.NArg() > 0 {
+		return fmt.Errorf("unexpected argument: %v",
+			ctx.Args().First())
+	}
+
+	if !ctx.IsSet("chan_point") {
+		return fmt.Errorf("chan_point argument missing")
+	}
+
+	chanPoint, err := parseChanPoint(ctx.String("chan_point"))
+	if err != nil {
+		return fmt.Errorf("unable to parse chan_point: %w", err)
+	}
+
+	req := &lnrpc.SetChannelSweepBudgetRequest{
+		ChanPoint: chanPoint,
+		Budget: &lnrpc.SweepBudget{
+			ToLocalSat:        ctx.Uint64("tolocal"),
+			ToLocalRatio:      ctx.Float64("tolocalratio"),
+			AnchorCpfpSat:     ctx.Uint64("anchorcpfp"),
+			AnchorCpfpRatio:   ctx.Float64("anchorcpfpratio"),
+			DeadlineHtlcSat:   ctx.Uint64("deadlinehtlc"),
+			DeadlineHtlcRatio: ctx.Float64("deadlinehtlcratio"),
+			NoDeadlineHtlcSat: ctx.Uint64("nodeadlinehtlc"),
+			NoDeadlineHtlcRatio: ctx.Float64(
+				"nodeadlinehtlcratio",
+			),
+			TotalSat: ctx.Uint64("total"),
+		},
+	}
+
+	resp, err := client.SetChannelSweepBudget(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listChanSweepBudgetsCommand = cli.Command{
+	Name:     "listchansweepbudgets",
+	Category: "Channels",
+	Usage:    "List the sweep budgets of all channels that have one set.",
+	Action:   actionDecorator(listChanSweepBudgets),
+}
+
+func listChanSweepBudgets(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListChannelSweepBudgets(
+		ctxc, &lnrpc.ListChannelSweepBudgetsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 var fishCompletionCommand = cli.Command{
 	Name:   "fish-completion",
 	Hidden: true,
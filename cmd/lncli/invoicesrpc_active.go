@@ -18,6 +18,9 @@ func invoicesCommands() []cli.Command {
 		cancelInvoiceCommand,
 		addHoldInvoiceCommand,
 		settleInvoiceCommand,
+		updateInvoiceCommand,
+		listAMPPaymentsCommand,
+		disableAMPReuseCommand,
 	}
 }
 
@@ -198,6 +201,23 @@ var addHoldInvoiceCommand = cli.Command{
 				"private channels in order to assist the " +
 				"payer in reaching you",
 		},
+		hopHintChanFlag,
+		metadataFlag,
+		cli.Uint64Flag{
+			Name: "hold_timeout",
+			Usage: "the maximum time in seconds the invoice is " +
+				"held after it was accepted. Once it passed, " +
+				"the invoice is canceled, or settled if " +
+				"--hold_timeout_preimage is set. If not " +
+				"specified, the invoice is held until it is " +
+				"resolved or close to its htlc expiry",
+		},
+		cli.StringFlag{
+			Name: "hold_timeout_preimage",
+			Usage: "the hex-encoded preimage (32 byte) used to " +
+				"settle the invoice instead of canceling it " +
+				"once --hold_timeout passed",
+		},
 	},
 	Action: actionDecorator(addHoldInvoice),
 }
@@ -241,6 +261,23 @@ func addHoldInvoice(ctx *cli.Context) error {
 		return fmt.Errorf("unable to parse description_hash: %w", err)
 	}
 
+	holdTimeoutAction := invoicesrpc.HoldTimeoutAction_CANCEL
+	holdTimeoutPreimage, err := hex.DecodeString(
+		ctx.String("hold_timeout_preimage"),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to parse hold_timeout_preimage: %w",
+			err)
+	}
+	if len(holdTimeoutPreimage) > 0 {
+		holdTimeoutAction = invoicesrpc.HoldTimeoutAction_SETTLE
+	}
+
+	metadata, err := parseMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
 	invoice := &invoicesrpc.AddHoldInvoiceRequest{
 		Memo:            ctx.String("memo"),
 		Hash:            hash,
@@ -251,6 +288,12 @@ func addHoldInvoice(ctx *cli.Context) error {
 		Expiry:          ctx.Int64("expiry"),
 		CltvExpiry:      ctx.Uint64("cltv_expiry_delta"),
 		Private:         ctx.Bool("private"),
+		HopHintChanIds:  parseHopHintChans(ctx),
+		Metadata:        metadata,
+
+		HoldTimeout:         ctx.Uint64("hold_timeout"),
+		HoldTimeoutAction:   holdTimeoutAction,
+		HoldTimeoutPreimage: holdTimeoutPreimage,
 	}
 
 	resp, err := client.AddHoldInvoice(ctxc, invoice)
@@ -262,3 +305,177 @@ func addHoldInvoice(ctx *cli.Context) error {
 
 	return nil
 }
+
+var updateInvoiceCommand = cli.Command{
+	Name:     "updateinvoice",
+	Category: "Invoices",
+	Usage:    "Amend the expiry, memo or fallback address of an invoice.",
+	Description: `
+	Update an open or accepted invoice in place. The payment hash and
+	payment address stay the same, so the new payment request can be paid
+	instead of the old one. Flags that are not set keep their current
+	value.
+
+	Once HTLCs have been accepted for the invoice, only its expiry can be
+	extended.`,
+	ArgsUsage: "paymenthash",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "paymenthash",
+			Usage: "the hex-encoded payment hash (32 byte) of " +
+				"the invoice to update",
+		},
+		cli.StringFlag{
+			Name:  "memo",
+			Usage: "the new description of the invoice",
+		},
+		cli.Int64Flag{
+			Name:  "expiry",
+			Usage: "the new expiry time of the invoice in seconds",
+		},
+		cli.StringFlag{
+			Name: "fallback_addr",
+			Usage: "the new fallback on-chain address that can " +
+				"be used in case the lightning payment fails",
+		},
+	},
+	Action: actionDecorator(updateInvoice),
+}
+
+func updateInvoice(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getInvoicesClient(ctx)
+	defer cleanUp()
+
+	paymentHash, err := parsePaymentHash(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := &invoicesrpc.UpdateInvoiceRequest{
+		PaymentHash:  paymentHash,
+		Memo:         ctx.String("memo"),
+		Expiry:       ctx.Int64("expiry"),
+		FallbackAddr: ctx.String("fallback_addr"),
+	}
+
+	resp, err := client.UpdateInvoice(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+// parsePaymentHash parses the hex-encoded payment hash that is passed either
+// via the paymenthash flag or as the first argument.
+func parsePaymentHash(ctx *cli.Context) ([]byte, error) {
+	var (
+		paymentHash []byte
+		err         error
+	)
+
+	args := ctx.Args()
+
+	switch {
+	case ctx.IsSet("paymenthash"):
+		paymentHash, err = hex.DecodeString(ctx.String("paymenthash"))
+	case args.Present():
+		paymentHash, err = hex.DecodeString(args.First())
+	default:
+		return nil, fmt.Errorf("payment hash argument missing")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse payment hash: %w", err)
+	}
+
+	return paymentHash, nil
+}
+
+var listAMPPaymentsCommand = cli.Command{
+	Name:     "listamppayments",
+	Category: "Invoices",
+	Usage:    "List the payments received against a reusable AMP invoice.",
+	Description: `
+	List the individual payments that were received against a reusable
+	AMP invoice. Each payment is identified by its set ID.`,
+	ArgsUsage: "paymenthash",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "paymenthash",
+			Usage: "the hex-encoded payment hash (32 byte) of " +
+				"the AMP invoice",
+		},
+	},
+	Action: actionDecorator(listAMPPayments),
+}
+
+func listAMPPayments(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getInvoicesClient(ctx)
+	defer cleanUp()
+
+	paymentHash, err := parsePaymentHash(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.ListAMPPayments(
+		ctxc, &invoicesrpc.ListAMPPaymentsRequest{
+			PaymentHash: paymentHash,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var disableAMPReuseCommand = cli.Command{
+	Name:     "disableampreuse",
+	Category: "Invoices",
+	Usage:    "Stop a reusable AMP invoice from accepting payments.",
+	Description: `
+	Stop a reusable AMP invoice from accepting further payments. The
+	invoice is canceled and the htlcs of payments that are still in flight
+	are failed back. The payments received so far remain listed.`,
+	ArgsUsage: "paymenthash",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "paymenthash",
+			Usage: "the hex-encoded payment hash (32 byte) of " +
+				"the AMP invoice",
+		},
+	},
+	Action: actionDecorator(disableAMPReuse),
+}
+
+func disableAMPReuse(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getInvoicesClient(ctx)
+	defer cleanUp()
+
+	paymentHash, err := parsePaymentHash(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.DisableAMPReuse(
+		ctxc, &invoicesrpc.DisableAMPReuseRequest{
+			PaymentHash: paymentHash,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
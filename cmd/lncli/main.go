@@ -455,8 +455,11 @@ func main() {
 		batchOpenChannelCommand,
 		closeChannelCommand,
 		closeAllChannelsCommand,
+		batchCloseChannelCommand,
 		abandonChannelCommand,
 		listPeersCommand,
+		setPeerChannelPolicyCommand,
+		listPeerChannelPoliciesCommand,
 		walletBalanceCommand,
 		channelBalanceCommand,
 		getInfoCommand,
@@ -467,6 +470,7 @@ func main() {
 		pendingChannelsCommand,
 		sendPaymentCommand,
 		payInvoiceCommand,
+		sendPaymentBatchCommand,
 		sendToRouteCommand,
 		addInvoiceCommand,
 		lookupInvoiceCommand,
@@ -476,6 +480,8 @@ func main() {
 		listPaymentsCommand,
 		describeGraphCommand,
 		getNodeMetricsCommand,
+		startGraphAnalysisCommand,
+		getGraphAnalysisCommand,
 		getChanInfoCommand,
 		getNodeInfoCommand,
 		queryRoutesCommand,
@@ -487,7 +493,14 @@ func main() {
 		signMessageCommand,
 		verifyMessageCommand,
 		feeReportCommand,
+		peerFeeReportCommand,
 		updateChannelPolicyCommand,
+		scheduleChanPolicyCommand,
+		listChanPolicyScheduleCommand,
+		cancelChanPolicyScheduleCommand,
+		updateCommitFeePolicyCommand,
+		setChanSweepBudgetCommand,
+		listChanSweepBudgetsCommand,
 		forwardingHistoryCommand,
 		exportChanBackupCommand,
 		verifyChanBackupCommand,
@@ -499,10 +512,15 @@ func main() {
 		printMacaroonCommand,
 		constrainMacaroonCommand,
 		trackPaymentCommand,
+		cancelPaymentCommand,
+		updatePaymentLimitsCommand,
+		abandonStalePaymentsCommand,
 		versionCommand,
 		profileSubCommand,
 		getStateCommand,
 		deletePaymentsCommand,
+		pruneFailedAttemptsCommand,
+		subscribeHtlcAttemptsCommand,
 		sendCustomCommand,
 		subscribeCustomCommand,
 		fishCompletionCommand,
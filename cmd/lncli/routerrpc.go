@@ -6,12 +6,25 @@ import "github.com/urfave/cli"
 func routerCommands() []cli.Command {
 	return []cli.Command{
 		queryMissionControlCommand,
+		listMissionControlNamespacesCommand,
+		getProberStatsCommand,
 		importMissionControlCommand,
+		importMissionControlProbesCommand,
 		queryProbCommand,
+		queryRouteProbCommand,
 		resetMissionControlCommand,
+		resetMissionControlPairsCommand,
+		setMissionControlPairOverridesCommand,
+		listMissionControlPairOverridesCommand,
 		buildRouteCommand,
 		getCfgCommand,
 		setCfgCommand,
+		subscribeCfgCommand,
 		updateChanStatusCommand,
+		listZombieChannelsCommand,
+		resurrectZombieChannelCommand,
+		pruneZombieChannelsCommand,
+		importNodeScoresCommand,
+		queryNodeScoresCommand,
 	}
 }
@@ -40,6 +40,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/rgs"
 	"github.com/lightningnetwork/lnd/routing"
 	"github.com/lightningnetwork/lnd/signal"
 	"github.com/lightningnetwork/lnd/tor"
@@ -397,6 +398,8 @@ type Config struct {
 
 	KeepFailedPaymentAttempts bool `long:"keep-failed-payment-attempts" description:"Keeps persistent record of all failed payment attempts for successfully settled payments."`
 
+	PruneFailedPaymentAttemptsAfter time.Duration `long:"prune-failed-payment-attempts-after" description:"If set, the failed attempts of payments that were created longer ago than this duration are deleted periodically, while the payments themselves are kept. Set to 0 to disable."`
+
 	StoreFinalHtlcResolutions bool `long:"store-final-htlc-resolutions" description:"Persistently store the final resolution of incoming htlcs."`
 
 	DefaultRemoteMaxHtlcs uint16 `long:"default-remote-max-htlcs" description:"The default max_htlc applied when opening or accepting channels. This value limits the number of concurrent HTLCs that the remote party can add to the commitment. The maximum possible value is 483."`
@@ -410,6 +413,8 @@ type Config struct {
 
 	RejectHTLC bool `long:"rejecthtlc" description:"If true, lnd will not forward any HTLCs that are meant as onward payments. This option will still allow lnd to send HTLCs and receive HTLCs but lnd won't be used as a hop."`
 
+	AllowedCloseAddrs []string `long:"allowcloseaddr" description:"An on-chain address that cooperative closes and on-chain sends may pay out to, in addition to the addresses of the default wallet account. If set, delivery and upfront shutdown addresses, SendCoins and SendMany destinations and the outputs of transactions funded, signed or finalized through the WalletKit RPC, or spending wallet coins and published through it, are rejected if they are neither part of this list nor derived from the wallet. Off-chain payments, channel push amounts and raw signing through the signer RPC are not restricted. The flag can be specified multiple times to add multiple addresses."`
+
 	AcceptPositiveInboundFees bool `long:"accept-positive-inbound-fees" description:"If true, lnd will also allow setting positive inbound fees. By default, lnd only allows to set negative inbound fees (an inbound \"discount\") to remain backwards compatible with senders whose implementations do not yet support inbound fees."`
 
 	// RequireInterceptor determines whether the HTLC interceptor is
@@ -446,10 +451,16 @@ type Config struct {
 
 	Invoices *lncfg.Invoices `group:"invoices" namespace:"invoices"`
 
+	Keysend *lncfg.Keysend `group:"keysend" namespace:"keysend"`
+
 	Routing *lncfg.Routing `group:"routing" namespace:"routing"`
 
 	Gossip *lncfg.Gossip `group:"gossip" namespace:"gossip"`
 
+	ChanAcceptor *lncfg.ChanAcceptor `group:"chanacceptor" namespace:"chanacceptor"`
+
+	RGS *lncfg.RapidGossipSync `group:"rgs" namespace:"rgs"`
+
 	Workers *lncfg.Workers `group:"workers" namespace:"workers"`
 
 	Caches *lncfg.Caches `group:"caches" namespace:"caches"`
@@ -632,8 +643,9 @@ func DefaultConfig() Config {
 			Sig:   lncfg.DefaultSigWorkers,
 		},
 		Caches: &lncfg.Caches{
-			RejectCacheSize:  channeldb.DefaultRejectCacheSize,
-			ChannelCacheSize: channeldb.DefaultChannelCacheSize,
+			RejectCacheSize:   channeldb.DefaultRejectCacheSize,
+			ChannelCacheSize:  channeldb.DefaultChannelCacheSize,
+			GraphSnapshotSize: channeldb.DefaultGraphSnapshotSize,
 		},
 		Prometheus: lncfg.DefaultPrometheus(),
 		Watchtower: lncfg.DefaultWatchtowerCfg(defaultTowerDir),
@@ -677,8 +689,22 @@ func DefaultConfig() Config {
 			ChannelUpdateInterval: discovery.DefaultChannelUpdateInterval,
 			SubBatchDelay:         discovery.DefaultSubBatchDelay,
 		},
+		ChanAcceptor: &lncfg.ChanAcceptor{},
+		Routing: &lncfg.Routing{
+			ZombieExpiry:        routing.DefaultChannelPruneExpiry,
+			ZombiePruneInterval: routing.DefaultGraphPruneInterval,
+		},
+		RGS: &lncfg.RapidGossipSync{
+			Listen:           lncfg.DefaultRGSListen,
+			SnapshotInterval: rgs.DefaultSnapshotInterval,
+		},
 		Invoices: &lncfg.Invoices{
-			HoldExpiryDelta: lncfg.DefaultHoldInvoiceExpiryDelta,
+			HoldExpiryDelta:      lncfg.DefaultHoldInvoiceExpiryDelta,
+			HopHintBalanceWeight: 1,
+			ExpiryWatcherWindow:  lncfg.DefaultExpiryWatcherWindow,
+		},
+		Keysend: &lncfg.Keysend{
+			RateLimitInterval: lncfg.DefaultKeysendRateLimitInterval,
 		},
 		MaxOutgoingCltvExpiry:     htlcswitch.DefaultMaxOutgoingCltvExpiry,
 		MaxChannelFeeAllocation:   htlcswitch.DefaultMaxLinkFeeAllocation,
@@ -1649,10 +1675,36 @@ func ValidateConfig(cfg Config, interceptor signal.Interceptor, fileParser,
 			maxPendingCommitInterval)
 	}
 
+	if cfg.PruneFailedPaymentAttemptsAfter < 0 {
+		return nil, mkErr("prune-failed-payment-attempts-after (%v) "+
+			"must not be negative",
+			cfg.PruneFailedPaymentAttemptsAfter)
+	}
+
 	if err := cfg.Gossip.Parse(); err != nil {
 		return nil, mkErr("error parsing gossip syncer: %v", err)
 	}
 
+	if err := cfg.ChanAcceptor.Parse(); err != nil {
+		return nil, mkErr("error parsing chanacceptor config: %v", err)
+	}
+
+	if err := cfg.Routing.Validate(); err != nil {
+		return nil, mkErr("error validating routing config: %v", err)
+	}
+
+	if err := cfg.RGS.Validate(); err != nil {
+		return nil, mkErr("error validating rgs config: %v", err)
+	}
+
+	if err := cfg.Invoices.Validate(); err != nil {
+		return nil, mkErr("error validating invoices config: %v", err)
+	}
+
+	if err := cfg.Keysend.Validate(); err != nil {
+		return nil, mkErr("error validating keysend config: %v", err)
+	}
+
 	// Log a warning if our expiry delta is not greater than our incoming
 	// broadcast delta. We do not fail here because this value may be set
 	// to zero to intentionally keep lnd's behavior unchanged from when we
@@ -966,6 +966,9 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 		channeldb.OptionSetChannelCacheSize(
 			cfg.Caches.ChannelCacheSize,
 		),
+		channeldb.OptionSetGraphSnapshotSize(
+			cfg.Caches.GraphSnapshotSize,
+		),
 		channeldb.OptionSetBatchCommitInterval(
 			cfg.DB.BatchCommitInterval,
 		),
@@ -246,6 +246,18 @@ type ChainArbitrator struct {
 	// methods and interface it needs to operate.
 	cfg ChainArbitratorConfig
 
+	// channelBudgets holds the per-channel sweep budgets, keyed by the
+	// channel point. Non-zero values take precedence over the budget
+	// config of cfg.
+	channelBudgets map[wire.OutPoint]BudgetConfig
+
+	// budgetAllocations holds the budgets of the outputs that have been
+	// offered to the sweeper, keyed by the channel point and the outpoint
+	// of the output. They are used to cap the sum of the budgets of a
+	// channel at its total budget.
+	budgetAllocations map[wire.OutPoint]map[wire.OutPoint]btcutil.Amount
+	budgetsMtx        sync.RWMutex
+
 	// chanSource will be used by the ChainArbitrator to fetch all the
 	// active channels that it must still watch over.
 	chanSource *channeldb.DB
@@ -264,8 +276,12 @@ func NewChainArbitrator(cfg ChainArbitratorConfig,
 		cfg:            cfg,
 		activeChannels: make(map[wire.OutPoint]*ChannelArbitrator),
 		activeWatchers: make(map[wire.OutPoint]*chainWatcher),
-		chanSource:     db,
-		quit:           make(chan struct{}),
+		channelBudgets: make(map[wire.OutPoint]BudgetConfig),
+		budgetAllocations: make(
+			map[wire.OutPoint]map[wire.OutPoint]btcutil.Amount,
+		),
+		chanSource: db,
+		quit:       make(chan struct{}),
 	}
 }
 
@@ -388,6 +404,14 @@ func newActiveChannelArbitrator(channel *channeldb.OpenChannel,
 		IsPendingClose:        false,
 		ChainArbitratorConfig: c.cfg,
 		ChainEvents:           chanEvents,
+		FetchBudget: func() BudgetConfig {
+			return c.ChannelBudget(chanPoint)
+		},
+		AllocateBudget: func(op wire.OutPoint,
+			budget btcutil.Amount) btcutil.Amount {
+
+			return c.AllocateBudget(chanPoint, op, budget)
+		},
 		PutResolverReport: func(tx kvdb.RwTx,
 			report *channeldb.ResolverReport) error {
 
@@ -502,6 +526,30 @@ func (c *ChainArbitrator) ResolveContract(chanPoint wire.OutPoint) error {
 		}
 	}
 
+	// The channel's budget and the budgets allocated to its outputs are
+	// no longer needed once all of its outputs have been swept.
+	c.budgetsMtx.Lock()
+	_, hasBudget := c.channelBudgets[chanPoint]
+	delete(c.channelBudgets, chanPoint)
+	_, hasAllocations := c.budgetAllocations[chanPoint]
+	delete(c.budgetAllocations, chanPoint)
+	c.budgetsMtx.Unlock()
+
+	if hasBudget {
+		err := deleteChannelBudget(c.chanSource, chanPoint)
+		if err != nil {
+			log.Warnf("Unable to delete budget of "+
+				"ChannelPoint(%v): %v", chanPoint, err)
+		}
+	}
+	if hasAllocations {
+		err := deleteBudgetAllocations(c.chanSource, chanPoint)
+		if err != nil {
+			log.Warnf("Unable to delete budget allocations of "+
+				"ChannelPoint(%v): %v", chanPoint, err)
+		}
+	}
+
 	// Once this has been marked as resolved, we'll wipe the log that the
 	// channel arbitrator was using to store its persistent state. We do
 	// this after marking the channel resolved, as otherwise, the
@@ -525,6 +573,23 @@ func (c *ChainArbitrator) Start() error {
 	log.Infof("ChainArbitrator starting with config: budget=[%v]",
 		&c.cfg.Budget)
 
+	// Load the per-channel budgets before creating the arbitrators, so
+	// they are in place once the arbitrators start to offer outputs to
+	// the sweeper.
+	channelBudgets, err := fetchChannelBudgets(c.chanSource)
+	if err != nil {
+		return err
+	}
+	budgetAllocations, err := fetchBudgetAllocations(c.chanSource)
+	if err != nil {
+		return err
+	}
+
+	c.budgetsMtx.Lock()
+	c.channelBudgets = channelBudgets
+	c.budgetAllocations = budgetAllocations
+	c.budgetsMtx.Unlock()
+
 	// First, we'll fetch all the channels that are still open, in order to
 	// collect them within our set of active contracts.
 	openChannels, err := c.chanSource.ChannelStateDB().FetchAllChannels()
@@ -611,8 +676,16 @@ func (c *ChainArbitrator) Start() error {
 			ChainArbitratorConfig: c.cfg,
 			ChainEvents:           &ChainEventSubscription{},
 			IsPendingClose:        true,
-			ClosingHeight:         closeChanInfo.CloseHeight,
-			CloseType:             closeChanInfo.CloseType,
+			FetchBudget: func() BudgetConfig {
+				return c.ChannelBudget(chanPoint)
+			},
+			AllocateBudget: func(op wire.OutPoint,
+				budget btcutil.Amount) btcutil.Amount {
+
+				return c.AllocateBudget(chanPoint, op, budget)
+			},
+			ClosingHeight: closeChanInfo.CloseHeight,
+			CloseType:     closeChanInfo.CloseType,
 			PutResolverReport: func(tx kvdb.RwTx,
 				report *channeldb.ResolverReport) error {
 
@@ -1092,6 +1165,136 @@ type forceCloseReq struct {
 	closeTx chan *wire.MsgTx
 }
 
+// SetChannelBudget sets the budget config used when sweeping the outputs of
+// the given channel, e.g. after it has been force closed. Non-zero values
+// take precedence over the global budget config, while zero values fall back
+// to it. Setting an empty budget config removes the channel's budget. The
+// budget is applied to all outputs that are offered to the sweeper from now
+// on.
+func (c *ChainArbitrator) SetChannelBudget(chanPoint wire.OutPoint,
+	budget BudgetConfig) error {
+
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+
+	c.Lock()
+	_, ok := c.activeChannels[chanPoint]
+	c.Unlock()
+	if !ok {
+		return fmt.Errorf("unable to find arbitrator")
+	}
+
+	c.budgetsMtx.Lock()
+	defer c.budgetsMtx.Unlock()
+
+	if budget == (BudgetConfig{}) {
+		err := deleteChannelBudget(c.chanSource, chanPoint)
+		if err != nil {
+			return err
+		}
+		delete(c.channelBudgets, chanPoint)
+
+		log.Infof("Removed budget of ChannelPoint(%v)", chanPoint)
+
+		return nil
+	}
+
+	err := putChannelBudget(c.chanSource, chanPoint, budget)
+	if err != nil {
+		return err
+	}
+	c.channelBudgets[chanPoint] = budget
+
+	log.Infof("Set budget of ChannelPoint(%v): %v", chanPoint, &budget)
+
+	return nil
+}
+
+// ChannelBudgets returns the budget configs of all channels that have a
+// per-channel budget.
+func (c *ChainArbitrator) ChannelBudgets() map[wire.OutPoint]BudgetConfig {
+	c.budgetsMtx.RLock()
+	defer c.budgetsMtx.RUnlock()
+
+	budgets := make(map[wire.OutPoint]BudgetConfig, len(c.channelBudgets))
+	for chanPoint, budget := range c.channelBudgets {
+		budgets[chanPoint] = budget
+	}
+
+	return budgets
+}
+
+// ChannelBudget returns the budget config used when sweeping the outputs of
+// the given channel, which is the global budget config with the channel's
+// budget applied.
+func (c *ChainArbitrator) ChannelBudget(chanPoint wire.OutPoint) BudgetConfig {
+	c.budgetsMtx.RLock()
+	defer c.budgetsMtx.RUnlock()
+
+	budget, ok := c.channelBudgets[chanPoint]
+	if !ok {
+		return c.cfg.Budget
+	}
+
+	return c.cfg.Budget.withOverrides(budget)
+}
+
+// AllocateBudget allocates the budget of an output of the given channel that
+// is offered to the sweeper, and returns the budget that should be used to
+// sweep it. If the channel has a total budget, the budget is capped at the
+// part of the total budget that hasn't been allocated to its other outputs
+// yet. An output that is offered again replaces its previous allocation.
+func (c *ChainArbitrator) AllocateBudget(chanPoint, op wire.OutPoint,
+	budget btcutil.Amount) btcutil.Amount {
+
+	total := c.ChannelBudget(chanPoint).Total
+
+	c.budgetsMtx.Lock()
+	defer c.budgetsMtx.Unlock()
+
+	allocations, ok := c.budgetAllocations[chanPoint]
+	if !ok {
+		allocations = make(map[wire.OutPoint]btcutil.Amount)
+		c.budgetAllocations[chanPoint] = allocations
+	}
+
+	if total != 0 {
+		var allocated btcutil.Amount
+		for allocatedOp, amt := range allocations {
+			if allocatedOp != op {
+				allocated += amt
+			}
+		}
+
+		remaining := total - allocated
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		if budget > remaining {
+			log.Infof("ChannelPoint(%v): capping budget=%v of "+
+				"output %v at the remaining total budget=%v",
+				chanPoint, budget, op, remaining)
+
+			budget = remaining
+		}
+	}
+
+	if prev, ok := allocations[op]; ok && prev == budget {
+		return budget
+	}
+
+	err := putBudgetAllocation(c.chanSource, chanPoint, op, budget)
+	if err != nil {
+		log.Errorf("Unable to store budget allocation of output %v of "+
+			"ChannelPoint(%v): %v", op, chanPoint, err)
+	}
+	allocations[op] = budget
+
+	return budget
+}
+
 // ForceCloseContract attempts to force close the channel infield by the passed
 // channel point. A force close will immediately terminate the contract,
 // causing it to enter the resolution phase. If the force close was successful,
@@ -4,6 +4,7 @@ import (
 	"net"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/chainntnfs"
@@ -226,3 +227,171 @@ func TestResolveContract(t *testing.T) {
 	err = chainArb.ResolveContract(channel.FundingOutpoint)
 	require.NoError(t, err, "second resolve call shouldn't fail")
 }
+
+// TestChainArbitratorChannelBudget tests that per-channel budgets take
+// precedence over the global budget config, are persisted across restarts and
+// are removed once the channel is resolved.
+func TestChainArbitratorChannelBudget(t *testing.T) {
+	t.Parallel()
+
+	db, err := channeldb.Open(t.TempDir())
+	require.NoError(t, err, "unable to open db")
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	newChannel, _, err := lnwallet.CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err, "unable to make new test channel")
+	channel := newChannel.State()
+	channel.Db = db.ChannelStateDB()
+	addr := &net.TCPAddr{
+		IP:   net.ParseIP("127.0.0.1"),
+		Port: 18556,
+	}
+	require.NoError(t, channel.SyncPending(addr, 101))
+	chanPoint := channel.FundingOutpoint
+
+	chainArbCfg := ChainArbitratorConfig{
+		ChainIO: &mock.ChainIO{},
+		Notifier: &mock.ChainNotifier{
+			SpendChan: make(chan *chainntnfs.SpendDetail),
+			EpochChan: make(chan *chainntnfs.BlockEpoch),
+			ConfChan:  make(chan *chainntnfs.TxConfirmation),
+		},
+		PublishTx: func(tx *wire.MsgTx, _ string) error {
+			return nil
+		},
+		Clock:  clock.NewDefaultClock(),
+		Budget: *DefaultBudgetConfig(),
+		QueryIncomingCircuit: func(
+			circuit models.CircuitKey) *models.CircuitKey {
+
+			return nil
+		},
+	}
+	startChainArb := func() *ChainArbitrator {
+		chainArb := NewChainArbitrator(chainArbCfg, db)
+		require.NoError(t, chainArb.Start())
+		t.Cleanup(func() {
+			require.NoError(t, chainArb.Stop())
+		})
+
+		return chainArb
+	}
+	chainArb := startChainArb()
+
+	// Budgets can only be set for known channels, and must be valid.
+	unknownChanPoint := wire.OutPoint{Index: 1}
+	err = chainArb.SetChannelBudget(unknownChanPoint, BudgetConfig{
+		ToLocal: MinBudgetValue,
+	})
+	require.Error(t, err)
+
+	err = chainArb.SetChannelBudget(chanPoint, BudgetConfig{
+		ToLocal: MinBudgetValue - 1,
+	})
+	require.Error(t, err)
+
+	// Set a budget that overrides some of the global values. The channel
+	// arbitrator uses the global config with the overrides applied.
+	budget := BudgetConfig{
+		ToLocal:           10_000,
+		DeadlineHTLCRatio: 0.2,
+	}
+	require.NoError(t, chainArb.SetChannelBudget(chanPoint, budget))
+
+	expectedBudget := *DefaultBudgetConfig()
+	expectedBudget.ToLocal = 10_000
+	expectedBudget.DeadlineHTLCRatio = 0.2
+
+	channelArb := chainArb.activeChannels[chanPoint]
+	require.Equal(t, expectedBudget, channelArb.cfg.budget())
+	require.Equal(
+		t, map[wire.OutPoint]BudgetConfig{chanPoint: budget},
+		chainArb.ChannelBudgets(),
+	)
+
+	// The budget is loaded again after a restart.
+	require.NoError(t, chainArb.Stop())
+	chainArb = startChainArb()
+
+	channelArb = chainArb.activeChannels[chanPoint]
+	require.Equal(t, expectedBudget, channelArb.cfg.budget())
+
+	// Once the channel is resolved, its budget is removed.
+	err = db.ChannelStateDB().AbandonChannel(&chanPoint, 4)
+	require.NoError(t, err, "unable to remove channel")
+	require.NoError(t, chainArb.ResolveContract(chanPoint))
+
+	require.Empty(t, chainArb.ChannelBudgets())
+
+	budgets, err := fetchChannelBudgets(db)
+	require.NoError(t, err)
+	require.Empty(t, budgets)
+}
+
+// TestChainArbitratorAllocateBudget tests that the budgets of the outputs of a
+// channel are capped at the channel's total budget, and that the allocations
+// are persisted across restarts.
+func TestChainArbitratorAllocateBudget(t *testing.T) {
+	t.Parallel()
+
+	db, err := channeldb.Open(t.TempDir())
+	require.NoError(t, err, "unable to open db")
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	chanPoint := wire.OutPoint{Index: 1}
+	op1 := wire.OutPoint{Index: 2}
+	op2 := wire.OutPoint{Index: 3}
+	op3 := wire.OutPoint{Index: 4}
+
+	chainArb := NewChainArbitrator(ChainArbitratorConfig{
+		Budget: *DefaultBudgetConfig(),
+	}, db)
+
+	// Without a total budget, the budgets are used as is, but they are
+	// still allocated.
+	require.EqualValues(t, 3_000, chainArb.AllocateBudget(
+		chanPoint, op1, 3_000,
+	))
+
+	// Once a total budget is set, the outputs are capped at the rest of
+	// it.
+	chainArb.channelBudgets[chanPoint] = BudgetConfig{Total: 5_000}
+	require.EqualValues(t, 2_000, chainArb.AllocateBudget(
+		chanPoint, op2, 3_000,
+	))
+	require.EqualValues(t, 0, chainArb.AllocateBudget(
+		chanPoint, op3, 1_000,
+	))
+
+	// Offering an output again replaces its previous allocation.
+	require.EqualValues(t, 1_000, chainArb.AllocateBudget(
+		chanPoint, op1, 1_000,
+	))
+	require.EqualValues(t, 2_000, chainArb.AllocateBudget(
+		chanPoint, op3, 3_000,
+	))
+
+	// The allocations are loaded from the db again.
+	allocations, err := fetchBudgetAllocations(db)
+	require.NoError(t, err)
+	require.Equal(t, map[wire.OutPoint]map[wire.OutPoint]btcutil.Amount{
+		chanPoint: {
+			op1: 1_000,
+			op2: 2_000,
+			op3: 2_000,
+		},
+	}, allocations)
+
+	// The allocations of a channel are removed together.
+	require.NoError(t, deleteBudgetAllocations(db, chanPoint))
+
+	allocations, err = fetchBudgetAllocations(db)
+	require.NoError(t, err)
+	require.Empty(t, allocations)
+}
@@ -172,9 +172,45 @@ type ChannelArbitratorConfig struct {
 	// spend his/her outgoing HTLC via the timeout path.
 	FindOutgoingHTLCDeadline func(htlc channeldb.HTLC) fn.Option[int32]
 
+	// FetchBudget returns the budget config used when sweeping the outputs
+	// of this channel, which takes per-channel budgets into account. If
+	// nil, the budget config of the ChainArbitratorConfig is used.
+	FetchBudget func() BudgetConfig
+
+	// AllocateBudget allocates the budget of an output of this channel
+	// that is offered to the sweeper, and returns the budget that should
+	// be used to sweep it, which is capped at the rest of the channel's
+	// total budget. If nil, the budget is used as is.
+	AllocateBudget func(op wire.OutPoint,
+		budget btcutil.Amount) btcutil.Amount
+
 	ChainArbitratorConfig
 }
 
+// budget returns the budget config used when sweeping the outputs of this
+// channel. It is looked up each time an output is offered to the sweeper, so
+// that a change of the channel's budget also applies to channels that are
+// already being resolved.
+func (c *ChannelArbitratorConfig) budget() BudgetConfig {
+	if c.FetchBudget == nil {
+		return c.Budget
+	}
+
+	return c.FetchBudget()
+}
+
+// allocateBudget returns the budget that should be used to sweep the given
+// output of this channel, taking the channel's total budget into account.
+func (c *ChannelArbitratorConfig) allocateBudget(op wire.OutPoint,
+	budget btcutil.Amount) btcutil.Amount {
+
+	if c.AllocateBudget == nil {
+		return budget
+	}
+
+	return c.AllocateBudget(op, budget)
+}
+
 // ReportOutputType describes the type of output that is being reported
 // on.
 type ReportOutputType uint8
@@ -1367,11 +1403,15 @@ func (c *ChannelArbitrator) sweepAnchors(anchors *lnwallet.AnchorResolutions,
 		// Calculate the budget based on the value under protection,
 		// which is the sum of all HTLCs on this commitment subtracted
 		// by their budgets.
+		budgetCfg := c.cfg.budget()
 		budget := calculateBudget(
-			value, c.cfg.Budget.AnchorCPFPRatio,
-			c.cfg.Budget.AnchorCPFP,
+			value, budgetCfg.AnchorCPFPRatio, budgetCfg.AnchorCPFP,
 		)
 
+		// The anchors of all commitments share a single allocation
+		// keyed by the channel point, as only one of them can confirm.
+		budget = c.cfg.allocateBudget(c.cfg.ChanPoint, budget)
+
 		log.Infof("ChannelArbitrator(%v): offering anchor from %s "+
 			"commitment %v to sweeper with deadline=%v, budget=%v",
 			c.cfg.ChanPoint, anchorPath, anchor.CommitAnchor,
@@ -1562,9 +1602,10 @@ func (c *ChannelArbitrator) findCommitmentDeadlineAndValue(heightHint uint32,
 
 	// Calculate the value left after subtracting the budget used for
 	// sweeping the time-sensitive HTLCs.
+	budgetCfg := c.cfg.budget()
 	valueLeft := totalValue - calculateBudget(
-		totalValue, c.cfg.Budget.DeadlineHTLCRatio,
-		c.cfg.Budget.DeadlineHTLC,
+		totalValue, budgetCfg.DeadlineHTLCRatio,
+		budgetCfg.DeadlineHTLC,
 	)
 
 	log.Debugf("ChannelArbitrator(%v): calculated valueLeft=%v, "+
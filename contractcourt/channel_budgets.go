@@ -0,0 +1,295 @@
+package contractcourt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+// channelBudgetsBucket is the top level bucket that stores the per-channel
+// sweep budgets, keyed by the channel point.
+var channelBudgetsBucket = []byte("channel-sweep-budgets")
+
+// budgetAllocationsBucket is the top level bucket that stores the budgets
+// allocated to the outputs of a channel that have been offered to the
+// sweeper. It holds a nested bucket for each channel, keyed by the channel
+// point, which maps the outpoints of the outputs to their budgets.
+var budgetAllocationsBucket = []byte("channel-sweep-budget-allocations")
+
+// withOverrides returns a copy of the budget config in which all non-zero
+// values of the given per-channel budget config take precedence.
+func (b BudgetConfig) withOverrides(o BudgetConfig) BudgetConfig {
+	override := func(value, o btcutil.Amount) btcutil.Amount {
+		if o != 0 {
+			return o
+		}
+
+		return value
+	}
+	overrideRatio := func(value, o float64) float64 {
+		if o != 0 {
+			return o
+		}
+
+		return value
+	}
+
+	return BudgetConfig{
+		ToLocal:      override(b.ToLocal, o.ToLocal),
+		ToLocalRatio: overrideRatio(b.ToLocalRatio, o.ToLocalRatio),
+		AnchorCPFP:   override(b.AnchorCPFP, o.AnchorCPFP),
+		AnchorCPFPRatio: overrideRatio(
+			b.AnchorCPFPRatio, o.AnchorCPFPRatio,
+		),
+		DeadlineHTLC: override(b.DeadlineHTLC, o.DeadlineHTLC),
+		DeadlineHTLCRatio: overrideRatio(
+			b.DeadlineHTLCRatio, o.DeadlineHTLCRatio,
+		),
+		NoDeadlineHTLC: override(b.NoDeadlineHTLC, o.NoDeadlineHTLC),
+		NoDeadlineHTLCRatio: overrideRatio(
+			b.NoDeadlineHTLCRatio, o.NoDeadlineHTLCRatio,
+		),
+		Total: override(b.Total, o.Total),
+	}
+}
+
+// fetchChannelBudgets returns all per-channel budgets stored in the database.
+func fetchChannelBudgets(db kvdb.Backend) (map[wire.OutPoint]BudgetConfig,
+	error) {
+
+	budgets := make(map[wire.OutPoint]BudgetConfig)
+	err := kvdb.View(db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(channelBudgetsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var chanPoint wire.OutPoint
+			err := readOutpoint(bytes.NewReader(k), &chanPoint)
+			if err != nil {
+				return err
+			}
+
+			budget, err := deserializeBudget(v)
+			if err != nil {
+				return err
+			}
+			budgets[chanPoint] = budget
+
+			return nil
+		})
+	}, func() {
+		budgets = make(map[wire.OutPoint]BudgetConfig)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return budgets, nil
+}
+
+// putChannelBudget stores the budget of the given channel in the database.
+func putChannelBudget(db kvdb.Backend, chanPoint wire.OutPoint,
+	budget BudgetConfig) error {
+
+	var key bytes.Buffer
+	if err := writeOutpoint(&key, &chanPoint); err != nil {
+		return err
+	}
+
+	return kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(channelBudgetsBucket)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key.Bytes(), serializeBudget(budget))
+	}, func() {})
+}
+
+// deleteChannelBudget removes the budget of the given channel from the
+// database.
+func deleteChannelBudget(db kvdb.Backend, chanPoint wire.OutPoint) error {
+	var key bytes.Buffer
+	if err := writeOutpoint(&key, &chanPoint); err != nil {
+		return err
+	}
+
+	return kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(channelBudgetsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Delete(key.Bytes())
+	}, func() {})
+}
+
+// fetchBudgetAllocations returns the budgets allocated to the outputs of all
+// channels, keyed by the channel point and the outpoint of the output.
+func fetchBudgetAllocations(db kvdb.Backend) (
+	map[wire.OutPoint]map[wire.OutPoint]btcutil.Amount, error) {
+
+	var allocations map[wire.OutPoint]map[wire.OutPoint]btcutil.Amount
+	err := kvdb.View(db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(budgetAllocationsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(chanKey, _ []byte) error {
+			chanBucket := bucket.NestedReadBucket(chanKey)
+			if chanBucket == nil {
+				return nil
+			}
+
+			var chanPoint wire.OutPoint
+			err := readOutpoint(
+				bytes.NewReader(chanKey), &chanPoint,
+			)
+			if err != nil {
+				return err
+			}
+
+			chanAllocations := make(
+				map[wire.OutPoint]btcutil.Amount,
+			)
+			allocations[chanPoint] = chanAllocations
+
+			return chanBucket.ForEach(func(k, v []byte) error {
+				var op wire.OutPoint
+				err := readOutpoint(bytes.NewReader(k), &op)
+				if err != nil {
+					return err
+				}
+
+				if len(v) != 8 {
+					return fmt.Errorf("invalid budget "+
+						"allocation of output %v", op)
+				}
+				chanAllocations[op] = btcutil.Amount(
+					binary.BigEndian.Uint64(v),
+				)
+
+				return nil
+			})
+		})
+	}, func() {
+		allocations = make(
+			map[wire.OutPoint]map[wire.OutPoint]btcutil.Amount,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allocations, nil
+}
+
+// putBudgetAllocation stores the budget allocated to the given output of a
+// channel in the database.
+func putBudgetAllocation(db kvdb.Backend, chanPoint, op wire.OutPoint,
+	budget btcutil.Amount) error {
+
+	var chanKey, opKey bytes.Buffer
+	if err := writeOutpoint(&chanKey, &chanPoint); err != nil {
+		return err
+	}
+	if err := writeOutpoint(&opKey, &op); err != nil {
+		return err
+	}
+
+	var value [8]byte
+	binary.BigEndian.PutUint64(value[:], uint64(budget))
+
+	return kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(budgetAllocationsBucket)
+		if err != nil {
+			return err
+		}
+
+		chanBucket, err := bucket.CreateBucketIfNotExists(
+			chanKey.Bytes(),
+		)
+		if err != nil {
+			return err
+		}
+
+		return chanBucket.Put(opKey.Bytes(), value[:])
+	}, func() {})
+}
+
+// deleteBudgetAllocations removes the budgets allocated to the outputs of the
+// given channel from the database.
+func deleteBudgetAllocations(db kvdb.Backend, chanPoint wire.OutPoint) error {
+	var chanKey bytes.Buffer
+	if err := writeOutpoint(&chanKey, &chanPoint); err != nil {
+		return err
+	}
+
+	return kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(budgetAllocationsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		err := bucket.DeleteNestedBucket(chanKey.Bytes())
+		if err != nil && err != kvdb.ErrBucketNotFound {
+			return err
+		}
+
+		return nil
+	}, func() {})
+}
+
+// serializeBudget encodes the budget config as a sequence of 64-bit values,
+// where the ratios are stored as the bits of the floating point number.
+func serializeBudget(b BudgetConfig) []byte {
+	values := []uint64{
+		uint64(b.ToLocal), math.Float64bits(b.ToLocalRatio),
+		uint64(b.AnchorCPFP), math.Float64bits(b.AnchorCPFPRatio),
+		uint64(b.DeadlineHTLC), math.Float64bits(b.DeadlineHTLCRatio),
+		uint64(b.NoDeadlineHTLC),
+		math.Float64bits(b.NoDeadlineHTLCRatio), uint64(b.Total),
+	}
+
+	buf := make([]byte, 8*len(values))
+	for i, value := range values {
+		binary.BigEndian.PutUint64(buf[i*8:], value)
+	}
+
+	return buf
+}
+
+// deserializeBudget decodes a budget config encoded by serializeBudget.
+func deserializeBudget(buf []byte) (BudgetConfig, error) {
+	var values [9]uint64
+	if len(buf) != 8*len(values) {
+		return BudgetConfig{}, fmt.Errorf("invalid budget length: %d",
+			len(buf))
+	}
+
+	if err := binary.Read(
+		bytes.NewReader(buf), binary.BigEndian, &values,
+	); err != nil {
+		return BudgetConfig{}, err
+	}
+
+	return BudgetConfig{
+		ToLocal:             btcutil.Amount(values[0]),
+		ToLocalRatio:        math.Float64frombits(values[1]),
+		AnchorCPFP:          btcutil.Amount(values[2]),
+		AnchorCPFPRatio:     math.Float64frombits(values[3]),
+		DeadlineHTLC:        btcutil.Amount(values[4]),
+		DeadlineHTLCRatio:   math.Float64frombits(values[5]),
+		NoDeadlineHTLC:      btcutil.Amount(values[6]),
+		NoDeadlineHTLCRatio: math.Float64frombits(values[7]),
+		Total:               btcutil.Amount(values[8]),
+	}, nil
+}
@@ -345,10 +345,12 @@ func (c *commitSweepResolver) Resolve(_ bool) (ContractResolver, error) {
 	// new input type, have sweeper set it?
 
 	// Calculate the budget for the sweeping this input.
+	budgetCfg := c.budget()
 	budget := calculateBudget(
 		btcutil.Amount(inp.SignDesc().Output.Value),
-		c.Budget.ToLocalRatio, c.Budget.ToLocal,
+		budgetCfg.ToLocalRatio, budgetCfg.ToLocal,
 	)
+	budget = c.allocateBudget(inp.OutPoint(), budget)
 	c.log.Infof("Sweeping commit output using budget=%v", budget)
 
 	// With our input constructed, we'll now offer it to the sweeper.
@@ -41,6 +41,8 @@ type BudgetConfig struct {
 
 	NoDeadlineHTLC      btcutil.Amount `long:"nodeadlinehtlc" description:"The amount in satoshis to allocate as the budget to pay fees when sweeping a non-time-sensitive (second-level) HTLC. If set, the budget calculated using the ratio (if set) will be capped at this value."`
 	NoDeadlineHTLCRatio float64        `long:"nodeadlinehtlcratio" description:"The ratio of the value in a non-time-sensitive (second-level) HTLC to allocate as the budget to pay fees when sweeping it."`
+
+	Total btcutil.Amount `long:"total" description:"The total amount in satoshis to allocate as the budget to pay fees when sweeping all outputs of a force closed channel, including the CPFP of the commitment. Outputs whose budget exceeds the rest of the total budget are swept with the rest only, or deferred if nothing is left. If not set, the budget of a channel is not capped."`
 }
 
 // Validate checks the budget configuration for any invalid values.
@@ -89,6 +91,10 @@ func (b *BudgetConfig) Validate() error {
 			MinBudgetRatio)
 	}
 
+	if b.Total != 0 && b.Total < MinBudgetValue {
+		return fmt.Errorf("total must be at least %v", MinBudgetValue)
+	}
+
 	return nil
 }
 
@@ -96,10 +102,10 @@ func (b *BudgetConfig) Validate() error {
 func (b *BudgetConfig) String() string {
 	return fmt.Sprintf("tolocal=%v tolocalratio=%v anchorcpfp=%v "+
 		"anchorcpfpratio=%v deadlinehtlc=%v deadlinehtlcratio=%v "+
-		"nodeadlinehtlc=%v nodeadlinehtlcratio=%v",
+		"nodeadlinehtlc=%v nodeadlinehtlcratio=%v total=%v",
 		b.ToLocal, b.ToLocalRatio, b.AnchorCPFP, b.AnchorCPFPRatio,
 		b.DeadlineHTLC, b.DeadlineHTLCRatio, b.NoDeadlineHTLC,
-		b.NoDeadlineHTLCRatio)
+		b.NoDeadlineHTLCRatio, b.Total)
 }
 
 // DefaultSweeperConfig returns the default configuration for the sweeper.
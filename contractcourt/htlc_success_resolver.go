@@ -261,10 +261,12 @@ func (h *htlcSuccessResolver) broadcastReSignedSuccessTx(immediate bool) (
 		value := btcutil.Amount(
 			secondLevelInput.SignDesc().Output.Value,
 		)
+		budgetCfg := h.budget()
 		budget := calculateBudget(
-			value, h.Budget.DeadlineHTLCRatio,
-			h.Budget.DeadlineHTLC,
+			value, budgetCfg.DeadlineHTLCRatio,
+			budgetCfg.DeadlineHTLC,
 		)
+		budget = h.allocateBudget(secondLevelInput.OutPoint(), budget)
 
 		// The deadline would be the CLTV in this HTLC output. If we
 		// are the initiator of this force close, with the default
@@ -407,11 +409,13 @@ func (h *htlcSuccessResolver) broadcastReSignedSuccessTx(immediate bool) (
 	)
 
 	// Calculate the budget for this sweep.
+	budgetCfg := h.budget()
 	budget := calculateBudget(
 		btcutil.Amount(inp.SignDesc().Output.Value),
-		h.Budget.NoDeadlineHTLCRatio,
-		h.Budget.NoDeadlineHTLC,
+		budgetCfg.NoDeadlineHTLCRatio,
+		budgetCfg.NoDeadlineHTLC,
 	)
+	budget = h.allocateBudget(inp.OutPoint(), budget)
 
 	log.Infof("%T(%x): offering second-level success tx output to sweeper "+
 		"with no deadline and budget=%v at height=%v", h,
@@ -471,11 +475,13 @@ func (h *htlcSuccessResolver) resolveRemoteCommitOutput(immediate bool) (
 	}
 
 	// Calculate the budget for this sweep.
+	budgetCfg := h.budget()
 	budget := calculateBudget(
 		btcutil.Amount(inp.SignDesc().Output.Value),
-		h.Budget.DeadlineHTLCRatio,
-		h.Budget.DeadlineHTLC,
+		budgetCfg.DeadlineHTLCRatio,
+		budgetCfg.DeadlineHTLC,
 	)
+	budget = h.allocateBudget(inp.OutPoint(), budget)
 
 	deadline := fn.Some(int32(h.htlc.RefundTimeout))
 
@@ -764,11 +764,13 @@ func (h *htlcTimeoutResolver) handleCommitSpend(
 			uint32(commitSpend.SpendingHeight), h.htlc.RHash,
 		)
 		// Calculate the budget for this sweep.
+		budgetCfg := h.budget()
 		budget := calculateBudget(
 			btcutil.Amount(inp.SignDesc().Output.Value),
-			h.Budget.NoDeadlineHTLCRatio,
-			h.Budget.NoDeadlineHTLC,
+			budgetCfg.NoDeadlineHTLCRatio,
+			budgetCfg.NoDeadlineHTLC,
 		)
+		budget = h.allocateBudget(inp.OutPoint(), budget)
 
 		log.Infof("%T(%x): offering second-level timeout tx output to "+
 			"sweeper with no deadline and budget=%v at height=%v",
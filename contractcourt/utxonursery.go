@@ -208,6 +208,18 @@ type NurseryConfig struct {
 
 	// Budget is the configured budget for the nursery.
 	Budget *BudgetConfig
+
+	// FetchBudget returns the budget config used when sweeping the outputs
+	// of the given channel, which takes per-channel budgets into account.
+	// If nil, Budget is used.
+	FetchBudget func(chanPoint wire.OutPoint) BudgetConfig
+
+	// AllocateBudget allocates the budget of an output of the given channel
+	// that is offered to the sweeper, and returns the budget that should
+	// be used to sweep it, which is capped at the rest of the channel's
+	// total budget. If nil, the budget is used as is.
+	AllocateBudget func(chanPoint, op wire.OutPoint,
+		budget btcutil.Amount) btcutil.Amount
 }
 
 // UtxoNursery is a system dedicated to incubating time-locked outputs created
@@ -825,26 +837,35 @@ func (u *UtxoNursery) graduateClass(classHeight uint32) error {
 func (u *UtxoNursery) decideDeadlineAndBudget(k kidOutput) (fn.Option[int32],
 	btcutil.Amount) {
 
+	budgetCfg := *u.cfg.Budget
+	if u.cfg.FetchBudget != nil {
+		budgetCfg = u.cfg.FetchBudget(k.originChanPoint)
+	}
+
 	// Assume this is a to_local output and use a None deadline.
 	deadline := fn.None[int32]()
+	budget := calculateBudget(
+		k.amt, budgetCfg.ToLocalRatio, budgetCfg.ToLocal,
+	)
 
-	// Exit early if this is not HTLC.
-	if !k.isHtlc {
-		budget := calculateBudget(
-			k.amt, u.cfg.Budget.ToLocalRatio, u.cfg.Budget.ToLocal,
+	// Otherwise it's the first-level HTLC output, we'll use the
+	// time-sensitive settings for it.
+	if k.isHtlc {
+		deadline = k.deadlineHeight
+		budget = calculateBudget(
+			k.amt, budgetCfg.DeadlineHTLCRatio,
+			budgetCfg.DeadlineHTLC,
 		)
-
-		return deadline, budget
 	}
 
-	// Otherwise it's the first-level HTLC output, we'll use the
-	// time-sensitive settings for it.
-	budget := calculateBudget(
-		k.amt, u.cfg.Budget.DeadlineHTLCRatio,
-		u.cfg.Budget.DeadlineHTLC,
-	)
+	// Cap the budget at the rest of the channel's total budget.
+	if u.cfg.AllocateBudget != nil {
+		budget = u.cfg.AllocateBudget(
+			k.originChanPoint, k.outpoint, budget,
+		)
+	}
 
-	return k.deadlineHeight, budget
+	return deadline, budget
 }
 
 // sweepMatureOutputs generates and broadcasts the transaction that transfers
@@ -372,6 +372,62 @@ func TestKidOutputSerialization(t *testing.T) {
 	}
 }
 
+// TestDecideDeadlineAndBudget checks that the nursery uses the budget config
+// of the output's channel, and caps the budget at the rest of the channel's
+// total budget.
+func TestDecideDeadlineAndBudget(t *testing.T) {
+	t.Parallel()
+
+	chanPoint := outPoints[0]
+	var allocated []wire.OutPoint
+	u := NewUtxoNursery(&NurseryConfig{
+		Budget: DefaultBudgetConfig(),
+		FetchBudget: func(op wire.OutPoint) BudgetConfig {
+			require.Equal(t, chanPoint, op)
+
+			return BudgetConfig{
+				ToLocal:           1_000,
+				DeadlineHTLCRatio: 0.1,
+			}
+		},
+		AllocateBudget: func(chanPoint, op wire.OutPoint,
+			budget btcutil.Amount) btcutil.Amount {
+
+			allocated = append(allocated, op)
+
+			return budget - 100
+		},
+	})
+
+	// The to_local output uses the channel's cap and has no deadline.
+	toLocal := kidOutput{
+		breachedOutput: breachedOutput{
+			amt:      10_000,
+			outpoint: outPoints[1],
+		},
+		originChanPoint: chanPoint,
+	}
+	deadline, budget := u.decideDeadlineAndBudget(toLocal)
+	require.Equal(t, fn.None[int32](), deadline)
+	require.EqualValues(t, 900, budget)
+
+	// The HTLC output uses the channel's ratio and its deadline.
+	htlc := kidOutput{
+		breachedOutput: breachedOutput{
+			amt:      10_000,
+			outpoint: outPoints[2],
+		},
+		originChanPoint: chanPoint,
+		isHtlc:          true,
+		deadlineHeight:  fn.Some(int32(100)),
+	}
+	deadline, budget = u.decideDeadlineAndBudget(htlc)
+	require.Equal(t, fn.Some(int32(100)), deadline)
+	require.EqualValues(t, 900, budget)
+
+	require.Equal(t, []wire.OutPoint{outPoints[1], outPoints[2]}, allocated)
+}
+
 func TestBabyOutputSerialization(t *testing.T) {
 	t.Parallel()
 
@@ -525,6 +525,10 @@ type Config struct {
 	// is enabled.
 	EnableUpfrontShutdown bool
 
+	// CheckShutdownScript returns an error if the funds of a channel must
+	// not be paid out to the given user provided upfront shutdown script.
+	CheckShutdownScript func(lnwire.DeliveryAddress) error
+
 	// MaxAnchorsCommitFeeRate is the max commitment fee rate we'll use as
 	// the initiator for channels of the anchor type.
 	MaxAnchorsCommitFeeRate chainfee.SatPerKWeight
@@ -609,6 +613,11 @@ type Manager struct {
 
 	handleChannelReadyBarriers *lnutils.SyncMap[lnwire.ChannelID, struct{}]
 
+	// psbtFundingScripts is the set of funding output scripts of the PSBT
+	// funding flows that are waiting for the funding transaction to be
+	// created and signed externally.
+	psbtFundingScripts lnutils.SyncMap[string, struct{}]
+
 	quit chan struct{}
 	wg   sync.WaitGroup
 }
@@ -1691,7 +1700,14 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 	// Check whether the peer supports upfront shutdown, and get a new
 	// wallet address if our node is configured to set shutdown addresses by
 	// default. We use the upfront shutdown script provided by our channel
-	// acceptor (if any) in lieu of user input.
+	// acceptor (if any) in lieu of user input, as long as it is allowed.
+	err = f.cfg.CheckShutdownScript(acceptorResp.UpfrontShutdown)
+	if err != nil {
+		log.Errorf("Unacceptable upfront shutdown script: %v", err)
+		f.failFundingFlow(peer, cid, err)
+		return
+	}
+
 	shutdown, err := getUpfrontShutdownScript(
 		f.cfg.EnableUpfrontShutdown, peer, acceptorResp.UpfrontShutdown,
 		f.selectShutdownScript,
@@ -2190,6 +2206,16 @@ func (f *Manager) waitForPsbt(intent *chanfunding.PsbtIntent,
 		f.failFundingFlow(resCtx.peer, cid, cause)
 	}
 
+	// The funding output has to be created by the external wallet or
+	// through the wallet kit, so we track its script for as long as we're
+	// waiting for the funding transaction.
+	_, fundingOutput, err := intent.FundingOutput()
+	if err == nil {
+		script := string(fundingOutput.PkScript)
+		f.psbtFundingScripts.Store(script, struct{}{})
+		defer f.psbtFundingScripts.Delete(script)
+	}
+
 	// We'll now wait until the intent has received the final and complete
 	// funding transaction. If the channel is closed without any error being
 	// sent, we know everything's going as expected.
@@ -2243,6 +2269,13 @@ func (f *Manager) waitForPsbt(intent *chanfunding.PsbtIntent,
 	}
 }
 
+// IsPsbtFundingScript returns true if the script is the funding output script
+// of a PSBT funding flow that is waiting for its funding transaction.
+func (f *Manager) IsPsbtFundingScript(script []byte) bool {
+	_, ok := f.psbtFundingScripts.Load(string(script))
+	return ok
+}
+
 // continueFundingAccept continues the channel funding flow once our
 // contribution is finalized, the channel output is known and the funding
 // transaction is signed.
@@ -4514,7 +4547,13 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 	// Check whether the peer supports upfront shutdown, and get an address
 	// which should be used (either a user specified address or a new
 	// address from the wallet if our node is configured to set shutdown
-	// address by default).
+	// address by default). A user specified address must be allowed to
+	// receive the funds of the channel.
+	if err := f.cfg.CheckShutdownScript(msg.ShutdownScript); err != nil {
+		msg.Err <- err
+		return
+	}
+
 	shutdown, err := getUpfrontShutdownScript(
 		f.cfg.EnableUpfrontShutdown, msg.Peer, msg.ShutdownScript,
 		f.selectShutdownScript,
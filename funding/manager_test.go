@@ -561,6 +561,9 @@ func createTestFundingManager(t *testing.T, privKey *btcec.PrivateKey,
 		IsSweeperOutpoint: func(wire.OutPoint) bool {
 			return false
 		},
+		CheckShutdownScript: func(lnwire.DeliveryAddress) error {
+			return nil
+		},
 	}
 
 	for _, op := range options {
@@ -670,6 +673,7 @@ func recreateAliceFundingManager(t *testing.T, alice *testNode) {
 		OpenChannelPredicate:  chainedAcceptor,
 		DeleteAliasEdge:       oldCfg.DeleteAliasEdge,
 		AliasManager:          oldCfg.AliasManager,
+		CheckShutdownScript:   oldCfg.CheckShutdownScript,
 	})
 	require.NoError(t, err, "failed recreating aliceFundingManager")
 
@@ -208,3 +208,7 @@ replace google.golang.org/protobuf => github.com/lightninglabs/protobuf-go-hex-d
 go 1.21.4
 
 retract v0.0.2
+
+// This replace is needed until the sqldb module with the new invoice
+// amendment, hodl policy and metadata queries is tagged.
+replace github.com/lightningnetwork/lnd/sqldb => ./sqldb
@@ -0,0 +1,315 @@
+package lnd
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/autopilot"
+	"github.com/lightningnetwork/lnd/lnrpc"
+)
+
+// maxFinishedGraphAnalyses is the maximum number of finished graph analysis
+// jobs whose results are kept in memory.
+const maxFinishedGraphAnalyses = 5
+
+var (
+	// errGraphAnalysisRunning is returned if a graph analysis is requested
+	// while another one is still running.
+	errGraphAnalysisRunning = errors.New("a graph analysis is already " +
+		"running")
+
+	// errGraphAnalysisNotFound is returned if the status of an unknown
+	// graph analysis job is requested.
+	errGraphAnalysisNotFound = errors.New("graph analysis job not found")
+
+	// errGraphAnalyzerShuttingDown is returned if a graph analysis is
+	// requested after the analyzer was stopped.
+	errGraphAnalyzerShuttingDown = errors.New("graph analyzer shutting " +
+		"down")
+)
+
+// graphAnalysisJob is a graph analysis that is either running or finished.
+type graphAnalysisJob struct {
+	id        uint64
+	metrics   []lnrpc.NodeMetricType
+	startTime time.Time
+
+	// The fields below are protected by the mutex of the analyzer.
+	progress float64
+	endTime  time.Time
+	err      error
+	result   *lnrpc.GraphAnalysisResponse
+}
+
+// graphAnalyzer runs graph analysis jobs in the background, so that the
+// computation of expensive metrics doesn't block an RPC call. Only a single
+// job runs at a time and the results of the last finished jobs are kept
+// until they are queried.
+type graphAnalyzer struct {
+	graph   autopilot.ChannelGraph
+	workers int
+
+	stopped sync.Once
+	quit    chan struct{}
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	nextID   uint64
+	running  bool
+	jobs     map[uint64]*graphAnalysisJob
+	finished []uint64
+}
+
+// newGraphAnalyzer returns a new graph analyzer for the given graph.
+func newGraphAnalyzer(graph autopilot.ChannelGraph,
+	workers int) *graphAnalyzer {
+
+	return &graphAnalyzer{
+		graph:   graph,
+		workers: workers,
+		quit:    make(chan struct{}),
+		jobs:    make(map[uint64]*graphAnalysisJob),
+	}
+}
+
+// stop aborts a running job and waits for it to exit. No new jobs can be
+// started afterwards.
+func (a *graphAnalyzer) stop() {
+	a.stopped.Do(func() {
+		// The quit channel is closed while holding the mutex, so that
+		// no job can be started after we wait for the running one.
+		a.mu.Lock()
+		close(a.quit)
+		a.mu.Unlock()
+
+		a.wg.Wait()
+	})
+}
+
+// start launches a job that computes the given metrics and returns its ID.
+// If no metrics are given, all metrics are computed.
+func (a *graphAnalyzer) start(metrics []lnrpc.NodeMetricType) (uint64,
+	error) {
+
+	if len(metrics) == 0 {
+		metrics = []lnrpc.NodeMetricType{
+			lnrpc.NodeMetricType_BETWEENNESS_CENTRALITY,
+			lnrpc.NodeMetricType_EIGENVECTOR_CENTRALITY,
+			lnrpc.NodeMetricType_ARTICULATION_POINTS,
+		}
+	}
+
+	for _, metric := range metrics {
+		switch metric {
+		case lnrpc.NodeMetricType_BETWEENNESS_CENTRALITY,
+			lnrpc.NodeMetricType_EIGENVECTOR_CENTRALITY,
+			lnrpc.NodeMetricType_ARTICULATION_POINTS:
+
+		default:
+			return 0, fmt.Errorf("unsupported node metric %v",
+				metric)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	select {
+	case <-a.quit:
+		return 0, errGraphAnalyzerShuttingDown
+	default:
+	}
+
+	if a.running {
+		return 0, errGraphAnalysisRunning
+	}
+
+	a.nextID++
+	job := &graphAnalysisJob{
+		id:        a.nextID,
+		metrics:   metrics,
+		startTime: time.Now(),
+	}
+	a.jobs[job.id] = job
+	a.running = true
+
+	a.wg.Add(1)
+	go a.run(job)
+
+	return job.id, nil
+}
+
+// status returns the current state of the job with the given ID.
+func (a *graphAnalyzer) status(id uint64) (*lnrpc.GraphAnalysisResponse,
+	error) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	job, ok := a.jobs[id]
+	if !ok {
+		return nil, errGraphAnalysisNotFound
+	}
+
+	const (
+		running = lnrpc.GraphAnalysisState_GRAPH_ANALYSIS_RUNNING
+		failed  = lnrpc.GraphAnalysisState_GRAPH_ANALYSIS_FAILED
+	)
+
+	switch {
+	case job.err != nil:
+		return &lnrpc.GraphAnalysisResponse{
+			JobId:     job.id,
+			State:     failed,
+			Progress:  job.progress,
+			Error:     job.err.Error(),
+			StartTime: job.startTime.Unix(),
+			EndTime:   job.endTime.Unix(),
+		}, nil
+
+	case job.result != nil:
+		return job.result, nil
+
+	default:
+		return &lnrpc.GraphAnalysisResponse{
+			JobId:     job.id,
+			State:     running,
+			Progress:  job.progress,
+			StartTime: job.startTime.Unix(),
+		}, nil
+	}
+}
+
+// run computes the metrics of a job and stores the result.
+//
+// NOTE: This MUST be run as a goroutine.
+func (a *graphAnalyzer) run(job *graphAnalysisJob) {
+	defer a.wg.Done()
+
+	rpcsLog.Infof("Starting graph analysis %v of %v", job.id, job.metrics)
+
+	result, err := a.analyze(job)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	job.endTime = time.Now()
+	job.err = err
+	if err == nil {
+		job.progress = 1
+		result.JobId = job.id
+		result.State = lnrpc.GraphAnalysisState_GRAPH_ANALYSIS_SUCCEEDED
+		result.Progress = 1
+		result.StartTime = job.startTime.Unix()
+		result.EndTime = job.endTime.Unix()
+		job.result = result
+	}
+	a.running = false
+
+	rpcsLog.Infof("Finished graph analysis %v after %v (err=%v)", job.id,
+		job.endTime.Sub(job.startTime), err)
+
+	// Evict the oldest results once too many are kept.
+	a.finished = append(a.finished, job.id)
+	if len(a.finished) > maxFinishedGraphAnalyses {
+		delete(a.jobs, a.finished[0])
+		a.finished = a.finished[1:]
+	}
+}
+
+// analyze computes the metrics of a job over a snapshot of the graph.
+func (a *graphAnalyzer) analyze(
+	job *graphAnalysisJob) (*lnrpc.GraphAnalysisResponse, error) {
+
+	graph, err := autopilot.NewSimpleGraph(a.graph)
+	if err != nil {
+		return nil, err
+	}
+
+	var numChannels int
+	for _, neighbors := range graph.Adj {
+		numChannels += len(neighbors)
+	}
+
+	result := &lnrpc.GraphAnalysisResponse{
+		NumNodes: uint32(len(graph.Nodes)),
+
+		// Every channel is part of the adjacency list of both its
+		// nodes.
+		NumChannels: uint32(numChannels / 2),
+	}
+
+	// Every metric counts as an equal share of the progress of the job.
+	numMetrics := float64(len(job.metrics))
+	for i, metric := range job.metrics {
+		select {
+		case <-a.quit:
+			return nil, errGraphAnalyzerShuttingDown
+		default:
+		}
+
+		progress := func(p float64) {
+			a.mu.Lock()
+			job.progress = (float64(i) + p) / numMetrics
+			a.mu.Unlock()
+		}
+
+		switch metric {
+		case lnrpc.NodeMetricType_BETWEENNESS_CENTRALITY:
+			centrality, err :=
+				autopilot.NewBetweennessCentralityMetric(
+					a.workers,
+				)
+			if err != nil {
+				return nil, err
+			}
+			err = centrality.RefreshGraph(graph, progress, a.quit)
+			if err != nil {
+				return nil, err
+			}
+
+			result.BetweennessCentrality = floatMetrics(centrality)
+
+		case lnrpc.NodeMetricType_EIGENVECTOR_CENTRALITY:
+			centrality := autopilot.NewEigenvectorCentralityMetric()
+			err := centrality.RefreshGraph(graph, progress, a.quit)
+			if err != nil {
+				return nil, err
+			}
+
+			result.EigenvectorCentrality = floatMetrics(centrality)
+
+		case lnrpc.NodeMetricType_ARTICULATION_POINTS:
+			for _, node := range graph.ArticulationPoints() {
+				result.ArticulationPoints = append(
+					result.ArticulationPoints,
+					hex.EncodeToString(node[:]),
+				)
+			}
+		}
+
+		progress(1)
+	}
+
+	return result, nil
+}
+
+// floatMetrics converts the values of a node metric to their RPC
+// representation.
+func floatMetrics(metric autopilot.NodeMetric) map[string]*lnrpc.FloatMetric {
+	values := metric.GetMetric(false)
+	normalized := metric.GetMetric(true)
+
+	metrics := make(map[string]*lnrpc.FloatMetric, len(values))
+	for nodeID, value := range values {
+		metrics[hex.EncodeToString(nodeID[:])] = &lnrpc.FloatMetric{
+			Value:           value,
+			NormalizedValue: normalized[nodeID],
+		}
+	}
+
+	return metrics
+}
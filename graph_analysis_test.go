@@ -0,0 +1,78 @@
+package lnd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/autopilot"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingGraph is a channel graph without nodes that blocks while it is
+// read until it is released.
+type blockingGraph struct {
+	reading chan struct{}
+	release chan struct{}
+}
+
+// ForEachNode signals that the graph is read and blocks until the graph is
+// released.
+func (g *blockingGraph) ForEachNode(func(autopilot.Node) error) error {
+	close(g.reading)
+	<-g.release
+
+	return nil
+}
+
+// TestGraphAnalyzerStop tests that stopping the graph analyzer waits for a
+// running job to exit and that no new jobs can be started afterwards.
+func TestGraphAnalyzerStop(t *testing.T) {
+	t.Parallel()
+
+	graph := &blockingGraph{
+		reading: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	analyzer := newGraphAnalyzer(graph, 1)
+
+	jobID, err := analyzer.start(nil)
+	require.NoError(t, err)
+
+	select {
+	case <-graph.reading:
+	case <-time.After(time.Second):
+		t.Fatal("graph analysis didn't start")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		analyzer.stop()
+		close(stopped)
+	}()
+
+	// The analyzer must not stop while the job still reads the graph.
+	select {
+	case <-stopped:
+		t.Fatal("analyzer stopped while job is running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(graph.release)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("analyzer didn't stop")
+	}
+
+	// The job was aborted before computing any metric.
+	resp, err := analyzer.status(jobID)
+	require.NoError(t, err)
+	require.Equal(
+		t, lnrpc.GraphAnalysisState_GRAPH_ANALYSIS_FAILED, resp.State,
+	)
+
+	_, err = analyzer.start(nil)
+	require.ErrorIs(t, err, errGraphAnalyzerShuttingDown)
+}
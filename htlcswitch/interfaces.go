@@ -160,6 +160,11 @@ type ChannelUpdateHandler interface {
 	// will only ever be called once. If no CommitSig is owed in the
 	// argument's LinkDirection, then we will call this hook immediately.
 	OnCommitOnce(LinkDirection, func())
+
+	// UpdateCommitFeePolicy updates and persists the policy that governs
+	// the commitment fee rate of the channel. The new policy is applied
+	// with the next commitment fee update.
+	UpdateCommitFeePolicy(policy channeldb.CommitFeePolicy) error
 }
 
 // CommitHookID is a value that is used to uniquely identify hooks in the
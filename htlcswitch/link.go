@@ -63,6 +63,11 @@ const (
 	// a channel's commitment fee to be of its balance. This only applies to
 	// the initiator of the channel.
 	DefaultMaxLinkFeeAllocation float64 = 0.5
+
+	// DefaultCommitFeeConfTarget is the confirmation target in blocks used
+	// to estimate the commitment fee rate of channels whose commitment fee
+	// policy doesn't set one.
+	DefaultCommitFeeConfTarget = 3
 )
 
 // ExpectedFee computes the expected fee for a given htlc amount. The value
@@ -700,24 +705,64 @@ func (l *channelLink) IsUnadvertised() bool {
 	return state.ChannelFlags&lnwire.FFAnnounceChannel == 0
 }
 
+// UpdateCommitFeePolicy updates and persists the policy that governs the
+// commitment fee rate of the channel. The new policy is applied with the next
+// commitment fee update.
+//
+// NOTE: Part of the ChannelLink interface.
+func (l *channelLink) UpdateCommitFeePolicy(
+	policy channeldb.CommitFeePolicy) error {
+
+	return l.channel.State().SetCommitFeePolicy(policy)
+}
+
 // sampleNetworkFee samples the current fee rate on the network to get into the
-// chain in a timely manner. The returned value is expressed in fee-per-kw, as
-// this is the native rate used when computing the fee for commitment
-// transactions, and the second-level HTLC transactions.
-func (l *channelLink) sampleNetworkFee() (chainfee.SatPerKWeight, error) {
-	// We'll first query for the sat/kw recommended to be confirmed within 3
-	// blocks.
-	feePerKw, err := l.cfg.FeeEstimator.EstimateFeePerKW(3)
+// chain within the given number of blocks. The returned value is expressed in
+// fee-per-kw, as this is the native rate used when computing the fee for
+// commitment transactions, and the second-level HTLC transactions.
+func (l *channelLink) sampleNetworkFee(
+	confTarget uint32) (chainfee.SatPerKWeight, error) {
+
+	feePerKw, err := l.cfg.FeeEstimator.EstimateFeePerKW(confTarget)
 	if err != nil {
 		return 0, err
 	}
 
-	l.log.Debugf("sampled fee rate for 3 block conf: %v sat/kw",
-		int64(feePerKw))
+	l.log.Debugf("sampled fee rate for %v block conf: %v sat/kw",
+		confTarget, int64(feePerKw))
 
 	return feePerKw, nil
 }
 
+// applyCommitFeePolicy bounds the sampled network fee rate by the commitment
+// fee policy of a channel. It returns the bounded fee rate along with the
+// maximum commitment fee rate of anchor channels. The fee of the commitment
+// transaction of anchor channels can be bumped when it is broadcast, so their
+// floor never exceeds the maximum anchor commitment fee rate.
+func applyCommitFeePolicy(policy channeldb.CommitFeePolicy, netFee,
+	maxAnchorFee chainfee.SatPerKWeight,
+	hasAnchors bool) (chainfee.SatPerKWeight, chainfee.SatPerKWeight) {
+
+	if policy.MaxFeeRate != 0 {
+		maxAnchorFee = policy.MaxFeeRate
+	}
+
+	minFee := policy.MinFeeRate
+	if hasAnchors && minFee > maxAnchorFee {
+		minFee = maxAnchorFee
+	}
+
+	switch {
+	case netFee < minFee:
+		netFee = minFee
+
+	case policy.MaxFeeRate != 0 && netFee > policy.MaxFeeRate:
+		netFee = policy.MaxFeeRate
+	}
+
+	return netFee, maxAnchorFee
+}
+
 // shouldAdjustCommitFee returns true if we should update our commitment fee to
 // match that of the network fee. We'll only update our commitment fee if the
 // network fee is +/- 10% to our commitment fee or if our current commitment
@@ -1327,9 +1372,16 @@ func (l *channelLink) htlcManager() {
 			}
 
 			// If we are the initiator, then we'll sample the
-			// current fee rate to get into the chain within 3
-			// blocks.
-			netFee, err := l.sampleNetworkFee()
+			// current fee rate to get into the chain within the
+			// confirmation target of the channel's commitment fee
+			// policy, or 3 blocks by default.
+			policy := l.channel.State().CommitFeePolicy()
+			confTarget := policy.ConfTarget
+			if confTarget == 0 {
+				confTarget = DefaultCommitFeeConfTarget
+			}
+
+			netFee, err := l.sampleNetworkFee(confTarget)
 			if err != nil {
 				l.log.Errorf("unable to sample network fee: %v",
 					err)
@@ -1338,9 +1390,13 @@ func (l *channelLink) htlcManager() {
 
 			minRelayFee := l.cfg.FeeEstimator.RelayFeePerKW()
 
+			netFee, maxAnchorFee := applyCommitFeePolicy(
+				policy, netFee, l.cfg.MaxAnchorsCommitFeeRate,
+				l.channel.State().ChanType.HasAnchors(),
+			)
+
 			newCommitFee := l.channel.IdealCommitFeeRate(
-				netFee, minRelayFee,
-				l.cfg.MaxAnchorsCommitFeeRate,
+				netFee, minRelayFee, maxAnchorFee,
 				l.cfg.MaxFeeAllocation,
 			)
 
@@ -4214,6 +4214,93 @@ func TestShouldAdjustCommitFee(t *testing.T) {
 	}
 }
 
+// TestApplyCommitFeePolicy tests that the sampled network fee rate is bounded
+// by the commitment fee policy of a channel.
+func TestApplyCommitFeePolicy(t *testing.T) {
+	t.Parallel()
+
+	const maxAnchorFee = chainfee.SatPerKWeight(2500)
+
+	tests := []struct {
+		name           string
+		policy         channeldb.CommitFeePolicy
+		netFee         chainfee.SatPerKWeight
+		hasAnchors     bool
+		expectedFee    chainfee.SatPerKWeight
+		expectedMaxFee chainfee.SatPerKWeight
+	}{
+		{
+			name:           "no policy",
+			netFee:         1000,
+			expectedFee:    1000,
+			expectedMaxFee: maxAnchorFee,
+		},
+		{
+			name: "raised to floor",
+			policy: channeldb.CommitFeePolicy{
+				MinFeeRate: 2000,
+			},
+			netFee:         1000,
+			expectedFee:    2000,
+			expectedMaxFee: maxAnchorFee,
+		},
+		{
+			name: "capped at max",
+			policy: channeldb.CommitFeePolicy{
+				MaxFeeRate: 1500,
+			},
+			netFee:         3000,
+			expectedFee:    1500,
+			expectedMaxFee: 1500,
+		},
+		{
+			name: "legacy floor above max anchor fee",
+			policy: channeldb.CommitFeePolicy{
+				MinFeeRate: 4000,
+			},
+			netFee:         1000,
+			expectedFee:    4000,
+			expectedMaxFee: maxAnchorFee,
+		},
+		{
+			name: "anchor floor capped at max anchor fee",
+			policy: channeldb.CommitFeePolicy{
+				MinFeeRate: 4000,
+			},
+			netFee:         1000,
+			hasAnchors:     true,
+			expectedFee:    maxAnchorFee,
+			expectedMaxFee: maxAnchorFee,
+		},
+		{
+			name: "anchor max fee replaces global max",
+			policy: channeldb.CommitFeePolicy{
+				MinFeeRate: 4000,
+				MaxFeeRate: 5000,
+			},
+			netFee:         1000,
+			hasAnchors:     true,
+			expectedFee:    4000,
+			expectedMaxFee: 5000,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fee, maxFee := applyCommitFeePolicy(
+				test.policy, test.netFee, maxAnchorFee,
+				test.hasAnchors,
+			)
+			require.Equal(t, test.expectedFee, fee)
+			require.Equal(t, test.expectedMaxFee, maxFee)
+		})
+	}
+}
+
 // TestChannelLinkShutdownDuringForward asserts that a link can be fully
 // stopped when it is trying to send synchronously through the switch. The
 // specific case this can occur is when a link forwards incoming Adds. We test
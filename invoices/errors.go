@@ -18,6 +18,12 @@ var (
 	// accepted.
 	ErrInvoiceAlreadyAccepted = errors.New("invoice already accepted")
 
+	// ErrInvoiceAmendmentNotAllowed is returned when an amendment changes
+	// more than the expiry of an invoice with accepted htlcs or shortens
+	// its expiry.
+	ErrInvoiceAmendmentNotAllowed = errors.New("invoice with accepted " +
+		"htlcs can only have its expiry extended")
+
 	// ErrInvoiceStillOpen is returned when the invoice is still open.
 	ErrInvoiceStillOpen = errors.New("invoice still open")
 
@@ -71,6 +77,10 @@ var (
 	// found.
 	ErrInvoiceNotFound = errors.New("unable to locate invoice")
 
+	// ErrInvoiceNotAMP is returned when an AMP specific operation targets
+	// an invoice that isn't an AMP invoice.
+	ErrInvoiceNotAMP = errors.New("invoice is not an AMP invoice")
+
 	// ErrNoInvoicesCreated is returned when we don't have invoices in
 	// our database to return.
 	ErrNoInvoicesCreated = errors.New("there are no existing invoices")
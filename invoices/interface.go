@@ -47,6 +47,21 @@ type InvoiceDB interface {
 	FetchPendingInvoices(ctx context.Context) (map[lntypes.Hash]Invoice,
 		error)
 
+	// ForEachPendingInvoice calls the passed callback for every invoice
+	// that has not yet been settled or canceled, without loading all of
+	// them into memory at once. The reset closure is called before the
+	// iteration starts and may be called again if the iteration is
+	// retried.
+	ForEachPendingInvoice(ctx context.Context,
+		cb func(lntypes.Hash, *Invoice) error, reset func()) error
+
+	// FetchInvoiceExpiries returns up to limit expiries of open invoices
+	// that are ordered after the passed one. The expiries are read from an
+	// index that is ordered by the expiry and, to break ties, by the
+	// payment hash, so that only the returned expiries are loaded.
+	FetchInvoiceExpiries(ctx context.Context, after InvoiceExpiry,
+		limit int) ([]InvoiceExpiry, error)
+
 	// QueryInvoices allows a caller to query the invoice database for
 	// invoices within the specified add index range.
 	QueryInvoices(ctx context.Context, q InvoiceQuery) (InvoiceSlice, error)
@@ -134,6 +149,30 @@ type InvoiceQuery struct {
 	// CreationDateEnd, if set, filters out all invoices with a creation
 	// date less than or equal to it.
 	CreationDateEnd int64
+
+	// MetadataKey, if set, filters out all invoices without a metadata
+	// entry for this key.
+	MetadataKey string
+
+	// MetadataValue, if set, filters out all invoices whose metadata entry
+	// for MetadataKey doesn't have this value. It is ignored if
+	// MetadataKey isn't set.
+	MetadataValue string
+}
+
+// MatchesMetadata returns true if the given invoice metadata satisfies the
+// metadata filter of the query.
+func (q *InvoiceQuery) MatchesMetadata(metadata map[string]string) bool {
+	if q.MetadataKey == "" {
+		return true
+	}
+
+	value, ok := metadata[q.MetadataKey]
+	if !ok {
+		return false
+	}
+
+	return q.MetadataValue == "" || value == q.MetadataValue
 }
 
 // InvoiceSlice is the response to a invoice query. It includes the original
@@ -161,6 +200,18 @@ type InvoiceSlice struct {
 	LastIndexOffset uint64
 }
 
+// InvoiceExpiry is an entry of the index of open invoices by their expiry.
+type InvoiceExpiry struct {
+	// PaymentHash is the payment hash of the invoice.
+	PaymentHash lntypes.Hash
+
+	// Expiry is the time at which the invoice expires.
+	Expiry time.Time
+
+	// Keysend is true if the invoice has no payment request.
+	Keysend bool
+}
+
 // CircuitKey is a tuple of channel ID and HTLC ID, used to uniquely identify
 // HTLCs in a circuit.
 type CircuitKey = models.CircuitKey
@@ -195,6 +246,10 @@ type InvoiceUpdater interface {
 	UpdateAmpState(setID [32]byte, newState InvoiceStateAMP,
 		circuitKey models.CircuitKey) error
 
+	// AmendInvoice replaces the expiry, memo and payment request of the
+	// invoice.
+	AmendInvoice(amendment *InvoiceAmendment) error
+
 	// Finalize finalizes the update before it is written to the database.
 	Finalize(updateType UpdateType) error
 }
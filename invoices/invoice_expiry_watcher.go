@@ -1,7 +1,9 @@
 package invoices
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,7 +12,6 @@ import (
 	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/queue"
-	"github.com/lightningnetwork/lnd/zpay32"
 )
 
 // invoiceExpiry is a vanity interface for different invoice expiry types
@@ -35,6 +36,27 @@ func (e invoiceExpiryTs) Less(other queue.PriorityQueueItem) bool {
 	return e.Expiry.Before(other.(*invoiceExpiryTs).Expiry)
 }
 
+// before returns true if the entry is ordered before the other one. Entries
+// are ordered by their expiry and, to break ties, by their payment hash. This
+// gives a total order that allows loading the timestamp expiries from the
+// database window by window.
+func (e *invoiceExpiryTs) before(other *invoiceExpiryTs) bool {
+	if !e.Expiry.Equal(other.Expiry) {
+		return e.Expiry.Before(other.Expiry)
+	}
+
+	return bytes.Compare(e.PaymentHash[:], other.PaymentHash[:]) < 0
+}
+
+// expiryLoader loads up to limit timestamp expiries of open invoices that are
+// ordered after the passed one, sorted in ascending order.
+type expiryLoader func(after *invoiceExpiryTs,
+	limit int) ([]*invoiceExpiryTs, error)
+
+// expiryLoadRetryInterval is the time we wait before we try again to load the
+// next window of timestamp expiries if loading it failed.
+const expiryLoadRetryInterval = time.Minute
+
 // Compile time assertion that invoiceExpiryHeight implements invoiceExpiry.
 var _ invoiceExpiry = (*invoiceExpiryHeight)(nil)
 
@@ -64,6 +86,10 @@ func (b invoiceExpiryHeight) expired(currentHeight, delta uint32) bool {
 // InvoiceExpiryWatcher and will end up in the watching queue as well.
 // If any of the watched invoices expire, they'll be removed from the watching
 // queue and will be cancelled through InvoiceRegistry.CancelInvoice().
+//
+// If lazy loading is enabled, only a window of the open invoices that expire
+// next is kept in the timestamp queue. Once the window is exhausted, the next
+// one is loaded from the database.
 type InvoiceExpiryWatcher struct {
 	sync.Mutex
 	started bool
@@ -94,6 +120,30 @@ type InvoiceExpiryWatcher struct {
 	// the next invoice to expire.
 	timestampExpiryQueue queue.PriorityQueue
 
+	// timestampExpiries holds the latest timestamp expiry of each invoice
+	// in the timestampExpiryQueue. If the expiry of an invoice is amended,
+	// the queue holds an outdated entry for it that needs to be skipped.
+	timestampExpiries map[lntypes.Hash]time.Time
+
+	// loadExpiries loads the next window of timestamp expiries from the
+	// database. If nil, the timestamp expiries of all open invoices are
+	// kept in memory.
+	loadExpiries expiryLoader
+
+	// maxTimestampExpiries is the number of timestamp expiries that are
+	// loaded from the database at once if lazy loading is enabled.
+	maxTimestampExpiries int
+
+	// horizon is the last timestamp expiry of the loaded window. Timestamp
+	// expiries ordered after it aren't kept in memory and are loaded from
+	// the database once the window is exhausted. If nil, the timestamp
+	// expiries of all open invoices are in memory.
+	horizon *invoiceExpiryTs
+
+	// loadRetry is set if loading the next window of timestamp expiries
+	// failed and fires once loading it should be retried.
+	loadRetry <-chan time.Time
+
 	// blockExpiryQueue holds blockExpiry items and is used to find the
 	// next invoice to expire based on block height. Only hold invoices
 	// with active htlcs are added to this queue, because they require
@@ -119,16 +169,34 @@ func NewInvoiceExpiryWatcher(clock clock.Clock,
 	notifier chainntnfs.ChainNotifier) *InvoiceExpiryWatcher {
 
 	return &InvoiceExpiryWatcher{
-		clock:            clock,
-		notifier:         notifier,
-		blockExpiryDelta: expiryDelta,
-		currentHeight:    startHeight,
-		currentHash:      startHash,
-		newInvoices:      make(chan []invoiceExpiry),
-		quit:             make(chan struct{}),
+		clock:             clock,
+		notifier:          notifier,
+		blockExpiryDelta:  expiryDelta,
+		currentHeight:     startHeight,
+		currentHash:       startHash,
+		timestampExpiries: make(map[lntypes.Hash]time.Time),
+		newInvoices:       make(chan []invoiceExpiry),
+		quit:              make(chan struct{}),
 	}
 }
 
+// enableLazyLoading makes the InvoiceExpiryWatcher keep at most about
+// maxExpiries timestamp expiries in memory, loading the next ones through the
+// passed loader once they are needed. It must be called before Start.
+func (ew *InvoiceExpiryWatcher) enableLazyLoading(maxExpiries int,
+	loadExpiries expiryLoader) {
+
+	ew.Lock()
+	defer ew.Unlock()
+
+	ew.loadExpiries = loadExpiries
+	ew.maxTimestampExpiries = maxExpiries
+
+	// Nothing is loaded yet, so the window ends before the first possible
+	// expiry.
+	ew.horizon = &invoiceExpiryTs{}
+}
+
 // Start starts the subscription handler and the main loop. Start() will
 // return with error if InvoiceExpiryWatcher is already started. Start()
 // expects a cancellation function passed that will be use to cancel expired
@@ -227,15 +295,9 @@ func makeTimestampExpiry(paymentHash lntypes.Hash,
 		return nil
 	}
 
-	realExpiry := invoice.Terms.Expiry
-	if realExpiry == 0 {
-		realExpiry = zpay32.DefaultInvoiceExpiry
-	}
-
-	expiry := invoice.CreationDate.Add(realExpiry)
 	return &invoiceExpiryTs{
 		PaymentHash: paymentHash,
-		Expiry:      expiry,
+		Expiry:      invoice.ExpiresAt(),
 		Keysend:     len(invoice.PaymentRequest) == 0,
 	}
 }
@@ -311,6 +373,27 @@ func (ew *InvoiceExpiryWatcher) cancelNextExpiredInvoice() {
 			return
 		}
 
+		// Skip the entry if the expiry of the invoice was extended
+		// after it was queued.
+		latest, ok := ew.timestampExpiries[top.PaymentHash]
+		if ok && latest.After(top.Expiry) {
+			ew.timestampExpiryQueue.Pop()
+
+			// If the expiry was extended beyond the loaded window,
+			// there's no newer entry in the queue and the invoice
+			// is loaded again with the next window.
+			if ew.beyondHorizon(&invoiceExpiryTs{
+				PaymentHash: top.PaymentHash,
+				Expiry:      latest,
+			}) {
+
+				delete(ew.timestampExpiries, top.PaymentHash)
+			}
+
+			return
+		}
+		delete(ew.timestampExpiries, top.PaymentHash)
+
 		// Don't force-cancel already accepted invoices. An exception to
 		// this are auto-generated keysend invoices. Because those move
 		// to the Accepted state directly after being opened, the expiry
@@ -370,10 +453,28 @@ func (ew *InvoiceExpiryWatcher) pushInvoices(invoices []invoiceExpiry) {
 		// itself is non-nil.
 		switch expiry := inv.(type) {
 		case *invoiceExpiryTs:
-			if expiry != nil {
-				ew.timestampExpiryQueue.Push(expiry)
+			if expiry == nil {
+				continue
 			}
 
+			// Invoices that expire after the loaded window are
+			// loaded from the database with a later window. If the
+			// invoice is queued already, we'll still record its new
+			// expiry so that the outdated entry is skipped.
+			hash := expiry.PaymentHash
+			if ew.beyondHorizon(expiry) {
+				_, ok := ew.timestampExpiries[hash]
+				if ok {
+					ew.timestampExpiries[hash] =
+						expiry.Expiry
+				}
+
+				continue
+			}
+
+			ew.timestampExpiryQueue.Push(expiry)
+			ew.timestampExpiries[hash] = expiry.Expiry
+
 		case *invoiceExpiryHeight:
 			if expiry != nil {
 				ew.blockExpiryQueue.Push(expiry)
@@ -385,6 +486,105 @@ func (ew *InvoiceExpiryWatcher) pushInvoices(invoices []invoiceExpiry) {
 	}
 }
 
+// beyondHorizon returns true if the passed timestamp expiry is ordered after
+// the loaded window.
+func (ew *InvoiceExpiryWatcher) beyondHorizon(expiry *invoiceExpiryTs) bool {
+	return ew.horizon != nil && ew.horizon.before(expiry)
+}
+
+// shrinkTimestampExpiries drops the timestamp expiries that were added beyond
+// maxTimestampExpiries by new invoices expiring within the loaded window. The
+// dropped expiries are loaded from the database again with a later window.
+func (ew *InvoiceExpiryWatcher) shrinkTimestampExpiries() {
+	if ew.loadExpiries == nil ||
+		ew.timestampExpiryQueue.Len() <= 2*ew.maxTimestampExpiries {
+
+		return
+	}
+
+	valid := make([]*invoiceExpiryTs, 0, ew.timestampExpiryQueue.Len())
+	for !ew.timestampExpiryQueue.Empty() {
+		expiry := ew.timestampExpiryQueue.Pop().(*invoiceExpiryTs)
+
+		// Skip the outdated entries of amended invoices. If the
+		// expiry was extended beyond the loaded window, the invoice is
+		// loaded again with a later window.
+		latest, ok := ew.timestampExpiries[expiry.PaymentHash]
+		if !ok || !latest.Equal(expiry.Expiry) {
+			if ok && ew.beyondHorizon(&invoiceExpiryTs{
+				PaymentHash: expiry.PaymentHash,
+				Expiry:      latest,
+			}) {
+
+				delete(ew.timestampExpiries, expiry.PaymentHash)
+			}
+
+			continue
+		}
+
+		valid = append(valid, expiry)
+	}
+
+	// The queue pops entries with equal expiries in arbitrary order, so
+	// we'll order them by payment hash too before cutting the window.
+	sort.Slice(valid, func(i, j int) bool {
+		return valid[i].before(valid[j])
+	})
+
+	kept := valid
+	if len(kept) > ew.maxTimestampExpiries {
+		kept = valid[:ew.maxTimestampExpiries]
+		for _, expiry := range valid[ew.maxTimestampExpiries:] {
+			delete(ew.timestampExpiries, expiry.PaymentHash)
+		}
+
+		ew.horizon = kept[len(kept)-1]
+	}
+
+	for _, expiry := range kept {
+		ew.timestampExpiryQueue.Push(expiry)
+	}
+
+	log.Debugf("Shrunk timestamp expiry window to %d invoices",
+		len(kept))
+}
+
+// loadNextTimestampExpiries loads the next window of timestamp expiries from
+// the database once the loaded one is exhausted.
+func (ew *InvoiceExpiryWatcher) loadNextTimestampExpiries() {
+	if ew.horizon == nil || !ew.timestampExpiryQueue.Empty() ||
+		ew.loadRetry != nil {
+
+		return
+	}
+
+	expiries, err := ew.loadExpiries(ew.horizon, ew.maxTimestampExpiries)
+	if err != nil {
+		log.Errorf("Unable to load invoice expiries, retrying in "+
+			"%v: %v", expiryLoadRetryInterval, err)
+
+		ew.loadRetry = ew.clock.TickAfter(expiryLoadRetryInterval)
+
+		return
+	}
+
+	// If the window isn't full, all remaining open invoices are in
+	// memory now.
+	if len(expiries) < ew.maxTimestampExpiries {
+		ew.horizon = nil
+	} else {
+		ew.horizon = expiries[len(expiries)-1]
+	}
+
+	for _, expiry := range expiries {
+		ew.timestampExpiryQueue.Push(expiry)
+		ew.timestampExpiries[expiry.PaymentHash] = expiry.Expiry
+	}
+
+	log.Debugf("Loaded %d invoice expiries into the expiry watcher",
+		len(expiries))
+}
+
 // mainLoop is a goroutine that receives new invoices and handles cancellation
 // of expired invoices.
 func (ew *InvoiceExpiryWatcher) mainLoop(blockNtfns *chainntnfs.BlockEpochEvent) {
@@ -402,11 +602,16 @@ func (ew *InvoiceExpiryWatcher) mainLoop(blockNtfns *chainntnfs.BlockEpochEvent)
 		// Cancel any invoices that may have expired.
 		cancelNext()
 
+		// Load the next window of invoice expiries if the loaded one
+		// is exhausted.
+		ew.loadNextTimestampExpiries()
+
 		select {
 		case newInvoices := <-ew.newInvoices:
 			// Take newly forwarded invoices with higher priority
 			// in order to not block the newInvoices channel.
 			ew.pushInvoices(newInvoices)
+			ew.shrinkTimestampExpiries()
 			continue
 
 		default:
@@ -420,8 +625,14 @@ func (ew *InvoiceExpiryWatcher) mainLoop(blockNtfns *chainntnfs.BlockEpochEvent)
 				cancelNext = ew.cancelNextHeightExpiredInvoice
 				continue
 
+			// Retry loading the next window of invoice expiries.
+			case <-ew.loadRetry:
+				ew.loadRetry = nil
+				continue
+
 			case newInvoices := <-ew.newInvoices:
 				ew.pushInvoices(newInvoices)
+				ew.shrinkTimestampExpiries()
 
 			// Consume new blocks.
 			case block, ok := <-blockNtfns.Epochs:
@@ -1,6 +1,9 @@
 package invoices
 
 import (
+	"container/heap"
+	"errors"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -225,6 +228,43 @@ func TestAcceptedHodlNotExpired(t *testing.T) {
 	test.assertCanceled(t, tsExpires.PaymentHash)
 }
 
+// TestExtendedExpiry tests that an invoice whose expiry was extended after it
+// was added to the expiry watcher is only canceled at its new expiry.
+func TestExtendedExpiry(t *testing.T) {
+	t.Parallel()
+
+	creationDate := testTime
+	expiry := time.Hour
+
+	test := setupHodlExpiry(
+		t, creationDate, expiry, 0, ContractOpen, nil,
+	)
+	defer test.watcher.Stop()
+
+	// Extend the expiry of the invoice by queueing it again.
+	test.watcher.AddInvoices(&invoiceExpiryTs{
+		PaymentHash: test.hash,
+		Expiry:      creationDate.Add(2 * expiry),
+	})
+
+	// Add another invoice that expires at the original expiry time as a
+	// control value.
+	tsExpires := &invoiceExpiryTs{
+		PaymentHash: lntypes.Hash{1, 2, 3},
+		Expiry:      creationDate.Add(expiry),
+	}
+	test.watcher.AddInvoices(tsExpires)
+
+	// Once the original expiry has passed, only the control invoice is
+	// expected to be canceled.
+	test.mockClock.SetTime(creationDate.Add(expiry + 1))
+	test.assertCanceled(t, tsExpires.PaymentHash)
+
+	// The invoice is canceled once its extended expiry has passed.
+	test.mockClock.SetTime(creationDate.Add(2*expiry + 1))
+	test.assertCanceled(t, test.hash)
+}
+
 // TestHeightAlreadyExpired tests the case where we add an invoice with htlcs
 // that have already expired to the expiry watcher.
 func TestHeightAlreadyExpired(t *testing.T) {
@@ -290,3 +330,353 @@ func TestExpiryHeightArrives(t *testing.T) {
 	test.announceBlock(t, htlc2-delta)
 	test.assertCanceled(t, test.hash)
 }
+
+// expiryWindow selects the limit first timestamp expiries ordered after a
+// given one from a stream of expiries, without holding more than limit
+// expiries in memory. It implements heap.Interface as a max-heap so that the
+// last selected expiry can be evicted efficiently.
+type expiryWindow struct {
+	after   *invoiceExpiryTs
+	limit   int
+	entries []*invoiceExpiryTs
+}
+
+// newExpiryWindow creates an expiry window that selects the limit first
+// timestamp expiries ordered after the passed one.
+func newExpiryWindow(after *invoiceExpiryTs, limit int) *expiryWindow {
+	return &expiryWindow{
+		after: after,
+		limit: limit,
+	}
+}
+
+// Len returns the number of selected expiries.
+//
+// NOTE: Part of the heap.Interface interface.
+func (w *expiryWindow) Len() int { return len(w.entries) }
+
+// Less orders the last expiry to the top of the heap.
+//
+// NOTE: Part of the heap.Interface interface.
+func (w *expiryWindow) Less(i, j int) bool {
+	return w.entries[j].before(w.entries[i])
+}
+
+// Swap swaps two expiries.
+//
+// NOTE: Part of the heap.Interface interface.
+func (w *expiryWindow) Swap(i, j int) {
+	w.entries[i], w.entries[j] = w.entries[j], w.entries[i]
+}
+
+// Push adds an expiry to the heap.
+//
+// NOTE: Part of the heap.Interface interface.
+func (w *expiryWindow) Push(x interface{}) {
+	w.entries = append(w.entries, x.(*invoiceExpiryTs))
+}
+
+// Pop removes the last expiry from the heap.
+//
+// NOTE: Part of the heap.Interface interface.
+func (w *expiryWindow) Pop() interface{} {
+	n := len(w.entries)
+	last := w.entries[n-1]
+	w.entries[n-1] = nil
+	w.entries = w.entries[:n-1]
+
+	return last
+}
+
+// add offers an expiry to the window. It is selected if it is ordered after
+// the start of the window and before the last selected expiry, or if the
+// window isn't full yet.
+func (w *expiryWindow) add(expiry *invoiceExpiryTs) {
+	if expiry == nil || !w.after.before(expiry) {
+		return
+	}
+
+	if w.Len() < w.limit {
+		heap.Push(w, expiry)
+		return
+	}
+
+	if !expiry.before(w.entries[0]) {
+		return
+	}
+
+	w.entries[0] = expiry
+	heap.Fix(w, 0)
+}
+
+// sorted returns the selected expiries in ascending order.
+func (w *expiryWindow) sorted() []*invoiceExpiryTs {
+	sort.Slice(w.entries, func(i, j int) bool {
+		return w.entries[i].before(w.entries[j])
+	})
+
+	return w.entries
+}
+
+// TestExpiryWindow tests that the expiry window selects the first timestamp
+// expiries ordered after the start of the window, breaking ties by payment
+// hash.
+func TestExpiryWindow(t *testing.T) {
+	t.Parallel()
+
+	after := &invoiceExpiryTs{
+		PaymentHash: lntypes.Hash{5},
+		Expiry:      testTime,
+	}
+	window := newExpiryWindow(after, 3)
+
+	inOneHour := testTime.Add(time.Hour)
+	inTwoHours := testTime.Add(2 * time.Hour)
+	expiries := []*invoiceExpiryTs{
+		{PaymentHash: lntypes.Hash{7}, Expiry: inOneHour},
+		{PaymentHash: lntypes.Hash{4}, Expiry: testTime},
+		{PaymentHash: lntypes.Hash{1}, Expiry: inTwoHours},
+		{PaymentHash: lntypes.Hash{6}, Expiry: testTime},
+		{PaymentHash: lntypes.Hash{5}, Expiry: testTime},
+		{PaymentHash: lntypes.Hash{3}, Expiry: inOneHour},
+		nil,
+	}
+	for _, expiry := range expiries {
+		window.add(expiry)
+	}
+
+	require.Equal(t, []*invoiceExpiryTs{expiries[3], expiries[5],
+		expiries[0]}, window.sorted())
+}
+
+// TestInvoiceExpiryLazyLoading tests that an expiry watcher with lazy loading
+// enabled cancels all expired invoices window by window, and that invoices
+// expiring after the loaded window aren't kept in memory.
+func TestInvoiceExpiryLazyLoading(t *testing.T) {
+	t.Parallel()
+
+	const (
+		numInvoices = 10
+		windowSize  = 3
+	)
+
+	testData := generateInvoiceExpiryTestData(
+		t, testTime, 0, numInvoices, 0,
+	)
+
+	// The database holds the timestamp expiries of the open invoices.
+	var dbMtx sync.Mutex
+	db := make(map[lntypes.Hash]*invoiceExpiryTs)
+	for paymentHash, invoice := range testData.expiredInvoices {
+		db[paymentHash] = makeTimestampExpiry(paymentHash, invoice)
+	}
+
+	var numLoads int
+	load := func(after *invoiceExpiryTs,
+		limit int) ([]*invoiceExpiryTs, error) {
+
+		dbMtx.Lock()
+		defer dbMtx.Unlock()
+
+		numLoads++
+		window := newExpiryWindow(after, limit)
+		for _, expiry := range db {
+			window.add(expiry)
+		}
+
+		return window.sorted(), nil
+	}
+
+	watcher := NewInvoiceExpiryWatcher(
+		clock.NewTestClock(testTime), 0, uint32(testCurrentHeight), nil,
+		newMockNotifier(),
+	)
+	watcher.enableLazyLoading(windowSize, load)
+
+	canceled := make(chan lntypes.Hash, numInvoices)
+	cancel := func(paymentHash lntypes.Hash, _ bool) error {
+		dbMtx.Lock()
+		defer dbMtx.Unlock()
+
+		delete(db, paymentHash)
+		canceled <- paymentHash
+
+		return nil
+	}
+	require.NoError(t, watcher.Start(cancel))
+	defer watcher.Stop()
+
+	for i := 0; i < numInvoices; i++ {
+		select {
+		case paymentHash := <-canceled:
+			require.Contains(
+				t, testData.expiredInvoices, paymentHash,
+			)
+
+		case <-time.After(testTimeout):
+			t.Fatalf("invoice %d not canceled", i)
+		}
+	}
+
+	// Every invoice is canceled once.
+	select {
+	case paymentHash := <-canceled:
+		t.Fatalf("unexpected cancellation of %v", paymentHash)
+
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The invoices were loaded window by window, with the last window not
+	// being full.
+	dbMtx.Lock()
+	require.Empty(t, db)
+	require.Equal(t, numInvoices/windowSize+1, numLoads)
+	dbMtx.Unlock()
+}
+
+// TestInvoiceExpiryLoadRetry tests that the expiry watcher retries loading the
+// next window of timestamp expiries if loading it failed.
+func TestInvoiceExpiryLoadRetry(t *testing.T) {
+	t.Parallel()
+
+	testData := generateInvoiceExpiryTestData(t, testTime, 0, 1, 0)
+
+	var (
+		dbMtx    sync.Mutex
+		numLoads int
+	)
+	load := func(after *invoiceExpiryTs,
+		limit int) ([]*invoiceExpiryTs, error) {
+
+		dbMtx.Lock()
+		defer dbMtx.Unlock()
+
+		numLoads++
+		if numLoads == 1 {
+			return nil, errors.New("database unavailable")
+		}
+
+		var expiries []*invoiceExpiryTs
+		for paymentHash, invoice := range testData.expiredInvoices {
+			expiries = append(expiries, makeTimestampExpiry(
+				paymentHash, invoice,
+			))
+		}
+
+		return expiries, nil
+	}
+
+	tickSignal := make(chan time.Duration, 10)
+	testClock := clock.NewTestClockWithTickSignal(testTime, tickSignal)
+	watcher := NewInvoiceExpiryWatcher(
+		testClock, 0, uint32(testCurrentHeight), nil, newMockNotifier(),
+	)
+	watcher.enableLazyLoading(2, load)
+
+	canceled := make(chan lntypes.Hash, 1)
+	cancel := func(paymentHash lntypes.Hash, _ bool) error {
+		canceled <- paymentHash
+		return nil
+	}
+	require.NoError(t, watcher.Start(cancel))
+	defer watcher.Stop()
+
+	// The first load fails, so a retry is scheduled.
+	select {
+	case duration := <-tickSignal:
+		require.Equal(t, expiryLoadRetryInterval, duration)
+
+	case <-time.After(testTimeout):
+		t.Fatalf("load retry not scheduled")
+	}
+
+	// Once the retry interval passed, the expiries are loaded and the
+	// expired invoice is canceled.
+	testClock.SetTime(testTime.Add(expiryLoadRetryInterval))
+
+	select {
+	case paymentHash := <-canceled:
+		require.Contains(t, testData.expiredInvoices, paymentHash)
+
+	case <-time.After(testTimeout):
+		t.Fatalf("invoice not canceled")
+	}
+
+	dbMtx.Lock()
+	require.Equal(t, 2, numLoads)
+	dbMtx.Unlock()
+}
+
+// TestInvoiceExpiryHorizon tests that timestamp expiries beyond the loaded
+// window are only kept in memory once their window is loaded.
+func TestInvoiceExpiryHorizon(t *testing.T) {
+	t.Parallel()
+
+	inOneHour := testTime.Add(time.Hour)
+	db := []*invoiceExpiryTs{
+		{PaymentHash: lntypes.Hash{1}, Expiry: inOneHour},
+		{PaymentHash: lntypes.Hash{2}, Expiry: inOneHour},
+		{PaymentHash: lntypes.Hash{3}, Expiry: inOneHour},
+	}
+	load := func(after *invoiceExpiryTs,
+		limit int) ([]*invoiceExpiryTs, error) {
+
+		window := newExpiryWindow(after, limit)
+		for _, expiry := range db {
+			window.add(expiry)
+		}
+
+		return window.sorted(), nil
+	}
+
+	testClock := clock.NewTestClock(testTime)
+	watcher := NewInvoiceExpiryWatcher(
+		testClock, 0, uint32(testCurrentHeight), nil, newMockNotifier(),
+	)
+	watcher.enableLazyLoading(2, load)
+
+	watcher.loadNextTimestampExpiries()
+	require.Equal(t, 2, watcher.timestampExpiryQueue.Len())
+	require.Equal(t, db[1], watcher.horizon)
+
+	// An invoice expiring after the window isn't queued, while one that
+	// expires within it is.
+	watcher.pushInvoices([]invoiceExpiry{
+		&invoiceExpiryTs{
+			PaymentHash: lntypes.Hash{4},
+			Expiry:      testTime.Add(2 * time.Hour),
+		},
+		&invoiceExpiryTs{
+			PaymentHash: lntypes.Hash{5},
+			Expiry:      testTime,
+		},
+	})
+	require.Equal(t, 3, watcher.timestampExpiryQueue.Len())
+	require.NotContains(t, watcher.timestampExpiries, lntypes.Hash{4})
+
+	// Extending the expiry of a queued invoice beyond the window records
+	// its new expiry, so that the queued entry is skipped and the map
+	// entry is dropped once it reaches the top of the queue.
+	watcher.pushInvoices([]invoiceExpiry{
+		&invoiceExpiryTs{
+			PaymentHash: lntypes.Hash{5},
+			Expiry:      testTime.Add(2 * time.Hour),
+		},
+	})
+	require.Equal(t, 3, watcher.timestampExpiryQueue.Len())
+
+	testClock.SetTime(testTime.Add(time.Minute))
+	watcher.cancelNextExpiredInvoice()
+	require.Equal(t, 2, watcher.timestampExpiryQueue.Len())
+	require.NotContains(t, watcher.timestampExpiries, lntypes.Hash{5})
+
+	// The next window is only loaded once the current one is exhausted.
+	watcher.loadNextTimestampExpiries()
+	require.Equal(t, 2, watcher.timestampExpiryQueue.Len())
+
+	watcher.timestampExpiryQueue.Pop()
+	watcher.timestampExpiryQueue.Pop()
+	watcher.loadNextTimestampExpiries()
+	require.Equal(t, 1, watcher.timestampExpiryQueue.Len())
+	require.Nil(t, watcher.horizon)
+}
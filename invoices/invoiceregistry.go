@@ -74,6 +74,16 @@ type RegistryConfig struct {
 	// KeysendHoldTime indicates for how long we want to accept and hold
 	// spontaneous keysend payments.
 	KeysendHoldTime time.Duration
+
+	// KeysendPolicy holds the rules that spontaneous keysend payments must
+	// satisfy. If nil, all keysend payments are accepted.
+	KeysendPolicy *KeysendPolicy
+
+	// ExpiryWatcherWindow is the number of open invoices that expire next
+	// whose expiry is tracked in memory. The expiries of the other open
+	// invoices are loaded from the database once the window is exhausted.
+	// If zero, the expiries of all open invoices are kept in memory.
+	ExpiryWatcherWindow int
 }
 
 // htlcReleaseEvent describes an htlc auto-release event. It is used to release
@@ -97,6 +107,24 @@ func (r *htlcReleaseEvent) Less(other queue.PriorityQueueItem) bool {
 	return r.releaseTime.Before(other.(*htlcReleaseEvent).releaseTime)
 }
 
+// hodlTimeoutEvent describes the automatic resolution of a hodl invoice by its
+// hodl policy.
+type hodlTimeoutEvent struct {
+	// hash is the payment hash of the invoice to resolve.
+	hash lntypes.Hash
+
+	// deadline is the time at which to resolve the invoice.
+	deadline time.Time
+}
+
+// Less is used to order PriorityQueueItem's by their deadline such that items
+// with the earliest deadline are at the top of the queue.
+//
+// NOTE: Part of the queue.PriorityQueueItem interface.
+func (h *hodlTimeoutEvent) Less(other queue.PriorityQueueItem) bool {
+	return h.deadline.Before(other.(*hodlTimeoutEvent).deadline)
+}
+
 // InvoiceRegistry is a central registry of all the outstanding invoices
 // created by the daemon. The registry is a thin wrapper around a map in order
 // to ensure that all updates/reads are thread safe.
@@ -144,6 +172,14 @@ type InvoiceRegistry struct {
 	// auto-released.
 	htlcAutoReleaseChan chan *htlcReleaseEvent
 
+	// hodlTimeoutChan contains the hodl invoices that need to be resolved
+	// by their hodl policy.
+	hodlTimeoutChan chan *hodlTimeoutEvent
+
+	// keysendLimiter enforces the rate limit of the keysend policy. It is
+	// nil if keysend payments aren't rate limited.
+	keysendLimiter *keysendRateLimiter
+
 	expiryWatcher *InvoiceExpiryWatcher
 
 	wg   sync.WaitGroup
@@ -159,6 +195,15 @@ func NewRegistry(idb InvoiceDB, expiryWatcher *InvoiceExpiryWatcher,
 
 	notificationClients := make(map[uint32]*InvoiceSubscription)
 	singleNotificationClients := make(map[uint32]*SingleInvoiceSubscription)
+
+	var keysendLimiter *keysendRateLimiter
+	if cfg.KeysendPolicy != nil && cfg.KeysendPolicy.RateLimit > 0 {
+		keysendLimiter = newKeysendRateLimiter(
+			cfg.KeysendPolicy.RateLimit,
+			cfg.KeysendPolicy.RateLimitInterval,
+		)
+	}
+
 	return &InvoiceRegistry{
 		idb:                       idb,
 		notificationClients:       notificationClients,
@@ -172,6 +217,8 @@ func NewRegistry(idb InvoiceDB, expiryWatcher *InvoiceExpiryWatcher,
 		),
 		cfg:                 cfg,
 		htlcAutoReleaseChan: make(chan *htlcReleaseEvent),
+		hodlTimeoutChan:     make(chan *hodlTimeoutEvent),
+		keysendLimiter:      keysendLimiter,
 		expiryWatcher:       expiryWatcher,
 		quit:                make(chan struct{}),
 	}
@@ -179,19 +226,44 @@ func NewRegistry(idb InvoiceDB, expiryWatcher *InvoiceExpiryWatcher,
 
 // scanInvoicesOnStart will scan all invoices on start and add active invoices
 // to the invoice expiry watcher while also attempting to delete all canceled
-// invoices.
+// invoices. If the expiry watcher loads timestamp expiries lazily, only the
+// height expiries of accepted invoices are added here.
 func (i *InvoiceRegistry) scanInvoicesOnStart(ctx context.Context) error {
-	pendingInvoices, err := i.idb.FetchPendingInvoices(ctx)
+	lazyExpiries := i.cfg.ExpiryWatcherWindow > 0
+
+	var (
+		pending       []invoiceExpiry
+		hodlDeadlines map[lntypes.Hash]time.Time
+	)
+	err := i.idb.ForEachPendingInvoice(ctx,
+		func(paymentHash lntypes.Hash, invoice *Invoice) error {
+			expiryRef := makeInvoiceExpiry(paymentHash, invoice)
+			_, isTimestamp := expiryRef.(*invoiceExpiryTs)
+			if expiryRef != nil && !(lazyExpiries && isTimestamp) {
+				pending = append(pending, expiryRef)
+			}
+
+			// Resume the hodl policy of invoices that were already
+			// held before the restart.
+			deadline, ok := hodlDeadline(invoice)
+			if ok {
+				hodlDeadlines[paymentHash] = deadline
+			}
+
+			return nil
+		}, func() {
+			pending = nil
+			hodlDeadlines = make(map[lntypes.Hash]time.Time)
+		},
+	)
 	if err != nil {
 		return err
 	}
 
-	var pending []invoiceExpiry
-	for paymentHash, invoice := range pendingInvoices {
-		invoice := invoice
-		expiryRef := makeInvoiceExpiry(paymentHash, &invoice)
-		if expiryRef != nil {
-			pending = append(pending, expiryRef)
+	for paymentHash, deadline := range hodlDeadlines {
+		err := i.startHodlTimer(paymentHash, deadline)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -211,10 +283,38 @@ func (i *InvoiceRegistry) scanInvoicesOnStart(ctx context.Context) error {
 	return nil
 }
 
+// loadInvoiceExpiries loads up to limit timestamp expiries of open invoices
+// that are ordered after the passed one from the expiry index of the
+// database.
+func (i *InvoiceRegistry) loadInvoiceExpiries(after *invoiceExpiryTs,
+	limit int) ([]*invoiceExpiryTs, error) {
+
+	entries, err := i.idb.FetchInvoiceExpiries(
+		context.Background(), InvoiceExpiry(*after), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	expiries := make([]*invoiceExpiryTs, 0, len(entries))
+	for _, entry := range entries {
+		expiry := invoiceExpiryTs(entry)
+		expiries = append(expiries, &expiry)
+	}
+
+	return expiries, nil
+}
+
 // Start starts the registry and all goroutines it needs to carry out its task.
 func (i *InvoiceRegistry) Start() error {
 	// Start InvoiceExpiryWatcher and prepopulate it with existing active
 	// invoices.
+	if i.cfg.ExpiryWatcherWindow > 0 {
+		i.expiryWatcher.enableLazyLoading(
+			i.cfg.ExpiryWatcherWindow, i.loadInvoiceExpiries,
+		)
+	}
+
 	err := i.expiryWatcher.Start(func(hash lntypes.Hash, force bool) error {
 		return i.cancelInvoiceImpl(context.Background(), hash, force)
 	})
@@ -276,6 +376,9 @@ func (i *InvoiceRegistry) invoiceEventLoop() {
 	// Set up a heap for htlc auto-releases.
 	autoReleaseHeap := &queue.PriorityQueue{}
 
+	// Set up a heap for hodl invoices that are resolved by their policy.
+	hodlTimeoutHeap := &queue.PriorityQueue{}
+
 	for {
 		// If there is something to release, set up a release tick
 		// channel.
@@ -285,6 +388,12 @@ func (i *InvoiceRegistry) invoiceEventLoop() {
 			nextReleaseTick = i.tickAt(head.releaseTime)
 		}
 
+		var nextHodlTimeoutTick <-chan time.Time
+		if hodlTimeoutHeap.Len() > 0 {
+			head := hodlTimeoutHeap.Top().(*hodlTimeoutEvent)
+			nextHodlTimeoutTick = i.tickAt(head.deadline)
+		}
+
 		select {
 		// A sub-systems has just modified the invoice state, so we'll
 		// dispatch notifications to all registered clients.
@@ -322,6 +431,26 @@ func (i *InvoiceRegistry) invoiceEventLoop() {
 				log.Errorf("HTLC timer: %v", err)
 			}
 
+		// A hodl invoice with a policy was accepted.
+		case event := <-i.hodlTimeoutChan:
+			log.Debugf("Scheduling hodl policy for invoice %v "+
+				"at %v", event.hash, event.deadline)
+
+			hodlTimeoutHeap.Push(event)
+
+		// The hodl invoice at the top of the heap was held for too
+		// long. Resolving it notifies clients, which is done by this
+		// loop, so we resolve it in a separate goroutine.
+		case <-nextHodlTimeoutTick:
+			event := hodlTimeoutHeap.Pop().(*hodlTimeoutEvent)
+
+			i.wg.Add(1)
+			go func() {
+				defer i.wg.Done()
+
+				i.resolveHodlTimeout(event.hash)
+			}()
+
 		case <-i.quit:
 			return
 		}
@@ -358,6 +487,19 @@ func (i *InvoiceRegistry) dispatchToSingleClients(event *invoiceEvent) {
 func (i *InvoiceRegistry) dispatchToClients(event *invoiceEvent) {
 	invoice := event.invoice
 
+	// AMP invoices never settle as a whole, so we'll notify the clients
+	// of the settle index of the sub-payment that was just settled. This
+	// allows them to resume their subscription from it.
+	if event.setID != nil {
+		var err error
+		invoice, err = ampSettleInvoice(invoice, *event.setID)
+		if err != nil {
+			log.Errorf("Unable to dispatch amp settle event: %v",
+				err)
+			return
+		}
+	}
+
 	clients := i.copyClients()
 	for clientID, client := range clients {
 		// Before we dispatch this event, we'll check
@@ -374,6 +516,11 @@ func (i *InvoiceRegistry) dispatchToClients(event *invoiceEvent) {
 			client.settleIndex >= invoice.SettleIndex:
 			continue
 
+		// The same applies to the settle events of AMP sub-payments.
+		case event.setID != nil &&
+			client.settleIndex >= invoice.SettleIndex:
+			continue
+
 		// Similarly, if we've already sent this add to
 		// the client then we can skip this one, but only if this isn't
 		// an AMP invoice. AMP invoices always remain in the settle
@@ -431,14 +578,13 @@ func (i *InvoiceRegistry) dispatchToClients(event *invoiceEvent) {
 		case invState == ContractOpen && event.setID == nil:
 			client.addIndex = invoice.AddIndex
 
-		// If this is an AMP invoice, then we'll need to use the set ID
-		// to keep track of the settle index of the client. AMP
-		// invoices never go to the open state, but if a setID is
-		// passed, then we know it was just settled and will track the
-		// highest settle index so far.
+		// If this is an AMP invoice, then the invoice carries the
+		// settle index of the sub-payment of the set ID. AMP invoices
+		// never go to the settled state, but if a setID is passed,
+		// then we know it was just settled and will track the highest
+		// settle index so far.
 		case invState == ContractOpen && event.setID != nil:
-			setID := *event.setID
-			client.settleIndex = invoice.AMPState[setID].SettleIndex
+			client.settleIndex = invoice.SettleIndex
 
 		default:
 			log.Errorf("unexpected invoice state: %v",
@@ -479,15 +625,51 @@ func (i *InvoiceRegistry) deliverBacklogEvents(ctx context.Context,
 		}
 	}
 
+	// AMP invoices are returned once for every sub-payment that was
+	// settled since the client's settle index, ordered by settle index. We
+	// track the settle index of the last sub-payment per invoice to map
+	// each of these events to its sub-payment.
+	lastAMPSettleIndex := make(map[uint64]uint64)
+
 	for _, settleEvent := range settleEvents {
 		// We re-bind the loop variable to ensure we don't hold onto
 		// the loop reference causing is to point to the same item.
 		settleEvent := settleEvent
 
-		select {
-		case client.ntfnQueue.ChanIn() <- &invoiceEvent{
+		event := &invoiceEvent{
 			invoice: &settleEvent,
-		}:
+		}
+
+		if settleEvent.IsAMP() {
+			addIndex := settleEvent.AddIndex
+			lastIndex, ok := lastAMPSettleIndex[addIndex]
+			if !ok {
+				lastIndex = client.settleIndex
+			}
+
+			setID, ok := nextAMPSettlement(&settleEvent, lastIndex)
+			if !ok {
+				log.Warnf("No amp sub-payment settled after "+
+					"settle_index=%v for invoice with "+
+					"add_index=%v", lastIndex, addIndex)
+
+				continue
+			}
+
+			invoice, err := ampSettleInvoice(&settleEvent, setID)
+			if err != nil {
+				return err
+			}
+			lastAMPSettleIndex[addIndex] = invoice.SettleIndex
+
+			event = &invoiceEvent{
+				invoice: invoice,
+				setID:   (*[32]byte)(&setID),
+			}
+		}
+
+		select {
+		case client.ntfnQueue.ChanIn() <- event:
 		case <-i.quit:
 			return ErrShuttingDown
 		}
@@ -496,6 +678,51 @@ func (i *InvoiceRegistry) deliverBacklogEvents(ctx context.Context,
 	return nil
 }
 
+// nextAMPSettlement returns the set ID of the sub-payment of an AMP invoice
+// with the lowest settle index greater than the given one.
+func nextAMPSettlement(invoice *Invoice, settleIndex uint64) (SetID, bool) {
+	var (
+		nextSetID  SetID
+		nextIndex  uint64
+		foundIndex bool
+	)
+	for setID, ampState := range invoice.AMPState {
+		if ampState.State != HtlcStateSettled ||
+			ampState.SettleIndex <= settleIndex {
+
+			continue
+		}
+
+		if !foundIndex || ampState.SettleIndex < nextIndex {
+			nextSetID = setID
+			nextIndex = ampState.SettleIndex
+			foundIndex = true
+		}
+	}
+
+	return nextSetID, foundIndex
+}
+
+// ampSettleInvoice returns a copy of an AMP invoice that carries the settle
+// index and settle date of the settled sub-payment with the given set ID.
+func ampSettleInvoice(invoice *Invoice, setID SetID) (*Invoice, error) {
+	ampState, ok := invoice.AMPState[setID]
+	if !ok || ampState.State != HtlcStateSettled {
+		return nil, fmt.Errorf("set id %x of invoice with "+
+			"add_index=%v not settled", setID[:], invoice.AddIndex)
+	}
+
+	settleInvoice, err := CopyInvoice(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	settleInvoice.SettleIndex = ampState.SettleIndex
+	settleInvoice.SettleDate = ampState.SettleDate
+
+	return settleInvoice, nil
+}
+
 // deliverSingleBacklogEvents will attempt to query the invoice database to
 // retrieve the current invoice state and deliver this to the subscriber. Single
 // invoice subscribers will always receive the current state right after
@@ -616,6 +843,87 @@ func (i *InvoiceRegistry) startHtlcTimer(invoiceRef InvoiceRef,
 	}
 }
 
+// startHodlTimer schedules the resolution of a hodl invoice by its hodl policy
+// via the invoice registry main loop.
+func (i *InvoiceRegistry) startHodlTimer(hash lntypes.Hash,
+	deadline time.Time) error {
+
+	event := &hodlTimeoutEvent{
+		hash:     hash,
+		deadline: deadline,
+	}
+
+	select {
+	case i.hodlTimeoutChan <- event:
+		return nil
+
+	case <-i.quit:
+		return ErrShuttingDown
+	}
+}
+
+// hodlDeadline returns the time at which the hodl policy of an accepted hodl
+// invoice resolves it. False is returned if the invoice isn't held or has no
+// hodl policy.
+func hodlDeadline(invoice *Invoice) (time.Time, bool) {
+	if invoice.State != ContractAccepted || invoice.HodlPolicy == nil {
+		return time.Time{}, false
+	}
+
+	return invoice.HodlPolicy.deadline(invoice)
+}
+
+// resolveHodlTimeout applies the hodl policy of an invoice that was held for
+// longer than its hold duration. Invoices that were resolved by the
+// application in the meantime are left untouched.
+func (i *InvoiceRegistry) resolveHodlTimeout(hash lntypes.Hash) {
+	ctx := context.Background()
+	invoice, err := i.idb.LookupInvoice(ctx, InvoiceRefByHash(hash))
+	if err != nil {
+		log.Errorf("Unable to look up held invoice %v: %v", hash, err)
+		return
+	}
+
+	deadline, ok := hodlDeadline(&invoice)
+	if !ok {
+		return
+	}
+
+	// The invoice may have been held again by an htlc set that arrived
+	// after the one we scheduled the deadline for.
+	if deadline.After(i.cfg.Clock.Now()) {
+		err := i.startHodlTimer(hash, deadline)
+		if err != nil && !errors.Is(err, ErrShuttingDown) {
+			log.Errorf("Unable to reschedule hodl policy for "+
+				"invoice %v: %v", hash, err)
+		}
+
+		return
+	}
+
+	policy := invoice.HodlPolicy
+	log.Infof("Invoice %v held for longer than %v, applying action: %v",
+		hash, policy.HoldDuration, policy.Action)
+
+	switch policy.Action {
+	case HodlTimeoutSettle:
+		err = i.SettleHodlInvoice(ctx, *policy.Preimage)
+
+	default:
+		err = i.cancelInvoiceImpl(ctx, hash, true)
+	}
+
+	switch {
+	// The application resolved the invoice after we looked it up.
+	case errors.Is(err, ErrInvoiceAlreadySettled),
+		errors.Is(err, ErrInvoiceAlreadyCanceled):
+
+	case err != nil:
+		log.Errorf("Unable to apply hodl policy to invoice %v: %v",
+			hash, err)
+	}
+}
+
 // cancelSingleHtlc cancels a single accepted htlc on an invoice. It takes
 // a resolution result which will be used to notify subscribed links and
 // resolvers of the details of the htlc cancellation.
@@ -772,6 +1080,11 @@ func (i *InvoiceRegistry) processKeySend(ctx invoiceUpdateCtx) error {
 		return errors.New("final expiry too soon")
 	}
 
+	memo, err := i.checkKeysendPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
 	// The invoice database indexes all invoices by payment address, however
 	// legacy keysend payment do not have one. In order to avoid a new
 	// payment type on-disk wrt. to indexing, we'll continue to insert a
@@ -784,6 +1097,7 @@ func (i *InvoiceRegistry) processKeySend(ctx invoiceUpdateCtx) error {
 	// Create placeholder invoice.
 	invoice := &Invoice{
 		CreationDate: i.cfg.Clock.Now(),
+		Memo:         memo,
 		Terms: ContractTerm{
 			FinalCltvDelta:  finalCltvDelta,
 			Value:           amt,
@@ -808,6 +1122,53 @@ func (i *InvoiceRegistry) processKeySend(ctx invoiceUpdateCtx) error {
 	return nil
 }
 
+// checkKeysendPolicy checks a keysend htlc against the configured keysend
+// policy and returns the memo to store on the keysend invoice. Replays of
+// htlcs for which an invoice was already created aren't checked again, as the
+// policy may have changed in the meantime and the htlc may already be settled.
+func (i *InvoiceRegistry) checkKeysendPolicy(
+	ctx invoiceUpdateCtx) ([]byte, error) {
+
+	policy := i.cfg.KeysendPolicy
+	if policy == nil {
+		return nil, nil
+	}
+
+	_, err := i.idb.LookupInvoice(
+		context.Background(), InvoiceRefByHash(ctx.hash),
+	)
+	switch {
+	case err == nil:
+		return nil, nil
+
+	case !errors.Is(err, ErrInvoiceNotFound) &&
+		!errors.Is(err, ErrNoInvoicesCreated):
+
+		return nil, err
+	}
+
+	if ctx.amtPaid < policy.MinAmt {
+		return nil, fmt.Errorf("keysend amount %v below minimum %v",
+			ctx.amtPaid, policy.MinAmt)
+	}
+
+	if err := policy.checkRecords(ctx.customRecords); err != nil {
+		return nil, err
+	}
+
+	// The rate limit is checked last, so that only payments that pass
+	// all other rules count towards the limit.
+	if i.keysendLimiter != nil {
+		chanID := ctx.circuitKey.ChanID
+		if !i.keysendLimiter.allow(chanID, i.cfg.Clock.Now()) {
+			return nil, fmt.Errorf("keysend rate limit exceeded "+
+				"for channel %v", chanID)
+		}
+	}
+
+	return policy.extractMemo(ctx.customRecords), nil
+}
+
 // processAMP just-in-time inserts an invoice if this htlc is a keysend
 // htlc.
 func (i *InvoiceRegistry) processAMP(ctx invoiceUpdateCtx) error {
@@ -968,6 +1329,15 @@ func (i *InvoiceRegistry) NotifyExitHopHtlc(rHash lntypes.Hash,
 			}
 		}
 
+		// Resolve the invoice by its hodl policy if it is held for too
+		// long.
+		if !r.hodlDeadline.IsZero() {
+			err := i.startHodlTimer(ctx.hash, r.hodlDeadline)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		// We return a nil resolution because htlc acceptances are
 		// represented as nil resolutions externally.
 		// TODO(carla) update calling code to handle accept resolutions.
@@ -1174,6 +1544,12 @@ func (i *InvoiceRegistry) notifyExitHopHtlcLocked(
 		// expiry height could change.
 		if res.outcome == resultAccepted {
 			invoiceToExpire = makeInvoiceExpiry(ctx.hash, invoice)
+
+			// Schedule the hodl policy, if any, outside the lock.
+			deadline, ok := hodlDeadline(invoice)
+			if ok {
+				res.hodlDeadline = deadline
+			}
 		}
 
 		i.hodlSubscribe(hodlChan, ctx.circuitKey)
@@ -1262,6 +1638,48 @@ func (i *InvoiceRegistry) SettleHodlInvoice(ctx context.Context,
 	return nil
 }
 
+// AmendInvoice replaces the expiry, memo and payment request of an unsettled
+// invoice. The amendment is derived from the current state of the invoice by
+// the passed callback, which is invoked within the database transaction. The
+// amended invoice is returned.
+func (i *InvoiceRegistry) AmendInvoice(ctx context.Context,
+	payHash lntypes.Hash, amend InvoiceAmendCallback) (*Invoice, error) {
+
+	i.Lock()
+
+	ref := InvoiceRefByHash(payHash)
+	log.Debugf("Invoice%v: amending invoice", ref)
+
+	updateInvoice := func(invoice *Invoice) (*InvoiceUpdateDesc, error) {
+		amendment, err := amend(invoice)
+		if err != nil {
+			return nil, err
+		}
+
+		return &InvoiceUpdateDesc{
+			UpdateType: AmendInvoiceUpdate,
+			Amendment:  amendment,
+		}, nil
+	}
+
+	invoice, err := i.idb.UpdateInvoice(ctx, ref, nil, updateInvoice)
+	i.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Invoice%v: amended, expiry=%v", ref, invoice.Terms.Expiry)
+
+	// Queue the invoice with its new expiry. A previous entry with an
+	// earlier expiry is skipped by the expiry watcher. Like in AddInvoice,
+	// this must happen without holding the registry lock.
+	if expiry := makeTimestampExpiry(payHash, invoice); expiry != nil {
+		i.expiryWatcher.AddInvoices(expiry)
+	}
+
+	return invoice, nil
+}
+
 // CancelInvoice attempts to cancel the invoice corresponding to the passed
 // payment hash.
 func (i *InvoiceRegistry) CancelInvoice(ctx context.Context,
@@ -1354,8 +1772,9 @@ func (i *InvoiceRegistry) cancelInvoiceImpl(ctx context.Context,
 	i.notifyClients(payHash, invoice, nil)
 
 	// Attempt to also delete the invoice if requested through the registry
-	// config.
-	if i.cfg.GcCanceledInvoicesOnTheFly {
+	// config. AMP invoices that were already paid are kept, so that the
+	// payments received against them remain on record.
+	if i.cfg.GcCanceledInvoicesOnTheFly && !hasSettledAMPSets(invoice) {
 		// Assemble the delete reference and attempt to delete through
 		// the invocice from the DB.
 		deleteRef := InvoiceDeleteRef{
@@ -1380,6 +1799,37 @@ func (i *InvoiceRegistry) cancelInvoiceImpl(ctx context.Context,
 	return nil
 }
 
+// hasSettledAMPSets returns true if at least one AMP sub-invoice of the
+// invoice was settled.
+func hasSettledAMPSets(invoice *Invoice) bool {
+	for _, ampState := range invoice.AMPState {
+		if ampState.State == HtlcStateSettled {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DisableAMPReuse stops a reusable AMP invoice from accepting further
+// payments. The invoice is canceled, which fails back the htlcs of sets that
+// are still in flight, while the sub-invoices that were settled remain
+// recorded on the invoice.
+func (i *InvoiceRegistry) DisableAMPReuse(ctx context.Context,
+	payHash lntypes.Hash) error {
+
+	invoice, err := i.idb.LookupInvoice(ctx, InvoiceRefByHash(payHash))
+	if err != nil {
+		return err
+	}
+
+	if !invoice.IsAMP() {
+		return ErrInvoiceNotAMP
+	}
+
+	return i.cancelInvoiceImpl(ctx, payHash, true)
+}
+
 // notifyClients notifies all currently registered invoice notification clients
 // of a newly added/settled invoice.
 func (i *InvoiceRegistry) notifyClients(hash lntypes.Hash,
@@ -57,6 +57,14 @@ func TestInvoiceRegistry(t *testing.T) {
 			name: "CancelHoldInvoice",
 			test: testCancelHoldInvoice,
 		},
+		{
+			name: "AmendInvoice",
+			test: testAmendInvoice,
+		},
+		{
+			name: "HodlPolicy",
+			test: testHodlPolicy,
+		},
 		{
 			name: "UnknownInvoice",
 			test: testUnknownInvoice,
@@ -69,6 +77,10 @@ func TestInvoiceRegistry(t *testing.T) {
 			name: "HoldKeysend",
 			test: testHoldKeysend,
 		},
+		{
+			name: "KeysendPolicy",
+			test: testKeysendPolicy,
+		},
 		{
 			name: "MppPayment",
 			test: testMppPayment,
@@ -81,6 +93,10 @@ func TestInvoiceRegistry(t *testing.T) {
 			name: "InvoiceExpiryWithRegistry",
 			test: testInvoiceExpiryWithRegistry,
 		},
+		{
+			name: "InvoiceExpiryWithRegistryWindow",
+			test: testInvoiceExpiryWithRegistryWindow,
+		},
 		{
 			name: "OldInvoiceRemovalOnStart",
 			test: testOldInvoiceRemovalOnStart,
@@ -109,6 +125,14 @@ func TestInvoiceRegistry(t *testing.T) {
 			name: "SpontaneousAmpPayment",
 			test: testSpontaneousAmpPayment,
 		},
+		{
+			name: "DisableAMPReuse",
+			test: testDisableAMPReuse,
+		},
+		{
+			name: "AMPSettleSubscriptionResume",
+			test: testAMPSettleSubscriptionResume,
+		},
 	}
 
 	makeKeyValueDB := func(t *testing.T) (invpkg.InvoiceDB,
@@ -754,6 +778,193 @@ func testCancelHoldInvoice(t *testing.T,
 	require.Equal(t, testCurrentHeight, failResolution.AcceptHeight)
 }
 
+// testAmendInvoice tests that the expiry and memo of an unsettled invoice can
+// be amended and that only extending the expiry is allowed once htlcs have
+// been accepted.
+func testAmendInvoice(t *testing.T,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
+	t.Parallel()
+	defer timeout()()
+
+	idb, testClock := makeDB(t)
+
+	cfg := invpkg.RegistryConfig{
+		FinalCltvRejectDelta: testFinalCltvRejectDelta,
+		Clock:                testClock,
+	}
+	expiryWatcher := invpkg.NewInvoiceExpiryWatcher(
+		cfg.Clock, 0, uint32(testCurrentHeight), nil, newMockNotifier(),
+	)
+	registry := invpkg.NewRegistry(idb, expiryWatcher, &cfg)
+
+	require.NoError(t, registry.Start())
+	t.Cleanup(func() {
+		require.NoError(t, registry.Stop())
+	})
+
+	ctxb := context.Background()
+
+	invoice := newInvoice(t, true)
+	_, err := registry.AddInvoice(ctxb, invoice, testInvoicePaymentHash)
+	require.NoError(t, err)
+
+	amend := func(memo string, expiry time.Duration) error {
+		_, err := registry.AmendInvoice(
+			ctxb, testInvoicePaymentHash,
+			func(*invpkg.Invoice) (*invpkg.InvoiceAmendment,
+				error) {
+
+				return &invpkg.InvoiceAmendment{
+					Memo:   []byte(memo),
+					Expiry: expiry,
+				}, nil
+			},
+		)
+
+		return err
+	}
+
+	// An open invoice can be amended freely. The expiries used in this
+	// test are short enough to fit into the expiry column of the SQL
+	// store.
+	require.NoError(t, amend("amended", 200*time.Millisecond))
+
+	dbInvoice, err := registry.LookupInvoice(ctxb, testInvoicePaymentHash)
+	require.NoError(t, err)
+	require.Equal(t, []byte("amended"), dbInvoice.Memo)
+	require.Equal(t, 200*time.Millisecond, dbInvoice.Terms.Expiry)
+
+	// Invoices without a payment request can't gain one.
+	_, err = registry.AmendInvoice(
+		ctxb, testInvoicePaymentHash,
+		func(*invpkg.Invoice) (*invpkg.InvoiceAmendment, error) {
+			return &invpkg.InvoiceAmendment{
+				Expiry:         time.Second,
+				PaymentRequest: []byte("lnbc1"),
+			}, nil
+		},
+	)
+	require.Error(t, err)
+
+	// Accept an htlc for the hold invoice.
+	hodlChan := make(chan interface{}, 1)
+	resolution, err := registry.NotifyExitHopHtlc(
+		testInvoicePaymentHash, testInvoiceAmount, testHtlcExpiry,
+		testCurrentHeight, getCircuitKey(0), hodlChan, testPayload,
+	)
+	require.NoError(t, err)
+	require.Nil(t, resolution)
+
+	// With an accepted htlc, the memo can't be changed and the expiry
+	// can't be shortened anymore.
+	err = amend("changed", 300*time.Millisecond)
+	require.ErrorIs(t, err, invpkg.ErrInvoiceAmendmentNotAllowed)
+
+	err = amend("amended", 100*time.Millisecond)
+	require.ErrorIs(t, err, invpkg.ErrInvoiceAmendmentNotAllowed)
+
+	// Extending the expiry is still possible.
+	require.NoError(t, amend("amended", 300*time.Millisecond))
+
+	dbInvoice, err = registry.LookupInvoice(ctxb, testInvoicePaymentHash)
+	require.NoError(t, err)
+	require.Equal(t, 300*time.Millisecond, dbInvoice.Terms.Expiry)
+
+	// Canceled invoices can't be amended.
+	require.NoError(t, registry.CancelInvoice(ctxb, testInvoicePaymentHash))
+
+	err = amend("amended", 400*time.Millisecond)
+	require.ErrorIs(t, err, invpkg.ErrInvoiceAlreadyCanceled)
+}
+
+// testHodlPolicy tests that a hodl invoice with a hodl policy is resolved
+// automatically once it was held for longer than the policy's hold duration.
+func testHodlPolicy(t *testing.T,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
+	t.Parallel()
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+		testHodlPolicyImpl(t, invpkg.HodlTimeoutCancel, makeDB)
+	})
+
+	t.Run("settle", func(t *testing.T) {
+		t.Parallel()
+		testHodlPolicyImpl(t, invpkg.HodlTimeoutSettle, makeDB)
+	})
+}
+
+func testHodlPolicyImpl(t *testing.T, action invpkg.HodlTimeoutAction,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
+	defer timeout()()
+
+	ctx := newTestContext(t, nil, makeDB)
+	ctxb := context.Background()
+
+	policy := &invpkg.HodlPolicy{
+		HoldDuration: time.Minute,
+		Action:       action,
+	}
+	if action == invpkg.HodlTimeoutSettle {
+		preimage := testInvoicePreimage
+		policy.Preimage = &preimage
+	}
+
+	invoice := newInvoice(t, true)
+	invoice.HodlPolicy = policy
+	_, err := ctx.registry.AddInvoice(ctxb, invoice, testInvoicePaymentHash)
+	require.NoError(t, err)
+
+	// Policies that don't match the invoice can't be added.
+	invalid := newInvoice(t, true)
+	invalid.HodlPolicy = &invpkg.HodlPolicy{
+		HoldDuration: time.Minute,
+		Action:       invpkg.HodlTimeoutSettle,
+	}
+	_, err = ctx.registry.AddInvoice(ctxb, invalid, lntypes.Hash{1})
+	require.Error(t, err)
+
+	// Accept an htlc for the hold invoice.
+	hodlChan := make(chan interface{}, 1)
+	resolution, err := ctx.registry.NotifyExitHopHtlc(
+		testInvoicePaymentHash, testInvoiceAmount, testHtlcExpiry,
+		testCurrentHeight, getCircuitKey(0), hodlChan, testPayload,
+	)
+	require.NoError(t, err)
+	require.Nil(t, resolution)
+
+	dbInvoice, err := ctx.registry.LookupInvoice(
+		ctxb, testInvoicePaymentHash,
+	)
+	require.NoError(t, err)
+	require.Equal(t, invpkg.ContractAccepted, dbInvoice.State)
+	require.Equal(t, policy, dbInvoice.HodlPolicy)
+
+	// Once the hold duration has passed, the invoice is resolved without
+	// the application.
+	ctx.clock.SetTime(ctx.clock.Now().Add(policy.HoldDuration))
+
+	htlcResolution, _ := (<-hodlChan).(invpkg.HtlcResolution)
+	require.NotNil(t, htlcResolution)
+
+	expectedState := invpkg.ContractCanceled
+	if action == invpkg.HodlTimeoutSettle {
+		checkSettleResolution(t, htlcResolution, testInvoicePreimage)
+		expectedState = invpkg.ContractSettled
+	} else {
+		checkFailResolution(t, htlcResolution, invpkg.ResultCanceled)
+	}
+
+	dbInvoice, err = ctx.registry.LookupInvoice(
+		ctxb, testInvoicePaymentHash,
+	)
+	require.NoError(t, err)
+	require.Equal(t, expectedState, dbInvoice.State)
+}
+
 // testUnknownInvoice tests that invoice registry returns an error when the
 // invoice is unknown. This is to guard against returning a cancel htlc
 // resolution for forwarded htlcs. In the link, NotifyExitHopHtlc is only called
@@ -1021,6 +1232,112 @@ func testHoldKeysendImpl(t *testing.T, timeoutKeysend bool,
 	require.Equal(t, settledInvoice.State, invpkg.ContractSettled)
 }
 
+// testKeysendPolicy tests that keysend payments are checked against the
+// configured keysend policy.
+func testKeysendPolicy(t *testing.T,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
+	t.Parallel()
+	defer timeout()()
+
+	const memoRecord = 100001
+
+	cfg := defaultRegistryConfig()
+	cfg.AcceptKeySend = true
+	cfg.KeysendPolicy = &invpkg.KeysendPolicy{
+		MinAmt: 1000,
+		AllowedRecords: []invpkg.RecordRange{
+			{Start: 100000, End: 100010},
+		},
+		RateLimit:         2,
+		RateLimitInterval: time.Minute,
+		MemoRecord:        memoRecord,
+	}
+	ctx := newTestContext(t, &cfg, makeDB)
+
+	hodlChan := make(chan interface{}, 1)
+	expiry := uint32(testCurrentHeight + 20)
+
+	var nextPreimage byte
+	pay := func(preimage lntypes.Preimage, amt lnwire.MilliSatoshi,
+		records map[uint64][]byte,
+		key invpkg.CircuitKey) invpkg.HtlcResolution {
+
+		customRecords := map[uint64][]byte{
+			record.KeySendType: preimage[:],
+		}
+		for recordType, value := range records {
+			customRecords[recordType] = value
+		}
+
+		resolution, err := ctx.registry.NotifyExitHopHtlc(
+			preimage.Hash(), amt, expiry, testCurrentHeight, key,
+			hodlChan, &mockPayload{customRecords: customRecords},
+		)
+		require.NoError(t, err)
+
+		return resolution
+	}
+	newPreimage := func() lntypes.Preimage {
+		nextPreimage++
+		return lntypes.Preimage{nextPreimage}
+	}
+
+	// Payments below the minimum amount are rejected.
+	resolution := pay(newPreimage(), 999, nil, getCircuitKey(1))
+	checkFailResolution(t, resolution, invpkg.ResultKeySendError)
+
+	// Payments carrying custom records outside of the allowed ranges are
+	// rejected.
+	resolution = pay(
+		newPreimage(), 1000, map[uint64][]byte{200000: {1}},
+		getCircuitKey(2),
+	)
+	checkFailResolution(t, resolution, invpkg.ResultKeySendError)
+
+	// A payment satisfying the policy is settled and its memo record is
+	// stored as the memo of the invoice.
+	preimage := newPreimage()
+	records := map[uint64][]byte{memoRecord: []byte("thanks")}
+	resolution = pay(preimage, 1000, records, getCircuitKey(3))
+	checkSettleResolution(t, resolution, preimage)
+
+	inv, err := ctx.registry.LookupInvoice(
+		context.Background(), preimage.Hash(),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []byte("thanks"), inv.Memo)
+
+	// A replay of the payment isn't checked against the policy again.
+	resolution = pay(preimage, 1000, records, getCircuitKey(3))
+	checkSettleResolution(t, resolution, preimage)
+
+	// The second payment through the channel within the interval is
+	// accepted, the third one is rate limited.
+	preimage = newPreimage()
+	resolution = pay(preimage, 1000, nil, getCircuitKey(4))
+	checkSettleResolution(t, resolution, preimage)
+
+	resolution = pay(newPreimage(), 1000, nil, getCircuitKey(5))
+	checkFailResolution(t, resolution, invpkg.ResultKeySendError)
+
+	// Payments through other channels aren't affected by the limit.
+	otherKey := getCircuitKey(6)
+	otherKey.ChanID.TxIndex++
+
+	preimage = newPreimage()
+	resolution = pay(preimage, 1000, nil, otherKey)
+	checkSettleResolution(t, resolution, preimage)
+
+	// Once the interval has passed, payments through the channel are
+	// accepted again.
+	ctx.clock.SetTime(ctx.clock.Now().Add(time.Minute))
+
+	preimage = newPreimage()
+	resolution = pay(preimage, 1000, nil, getCircuitKey(7))
+	checkSettleResolution(t, resolution, preimage)
+}
+
 // testMppPayment tests settling of an invoice with multiple partial payments.
 // It covers the case where there is a mpp timeout before the whole invoice is
 // paid and the case where the invoice is settled in time.
@@ -1204,11 +1521,30 @@ func testInvoiceExpiryWithRegistry(t *testing.T,
 	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
 
 	t.Parallel()
+	testInvoiceExpiryWithRegistryImpl(t, 0, makeDB)
+}
+
+// testInvoiceExpiryWithRegistryWindow tests that invoices are canceled after
+// expiration if the expiry watcher only tracks a window of the open invoices
+// and loads the others from the database.
+func testInvoiceExpiryWithRegistryWindow(t *testing.T,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
+	t.Parallel()
+	testInvoiceExpiryWithRegistryImpl(t, 2, makeDB)
+}
+
+// testInvoiceExpiryWithRegistryImpl runs the invoice expiry test with the
+// given expiry watcher window.
+func testInvoiceExpiryWithRegistryImpl(t *testing.T, window int,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
 	idb, testClock := makeDB(t)
 
 	cfg := invpkg.RegistryConfig{
 		FinalCltvRejectDelta: testFinalCltvRejectDelta,
 		Clock:                testClock,
+		ExpiryWatcherWindow:  window,
 	}
 
 	expiryWatcher := invpkg.NewInvoiceExpiryWatcher(
@@ -2095,3 +2431,208 @@ func testSpontaneousAmpPaymentImpl(
 		}
 	}
 }
+
+// testDisableAMPReuse tests that a reusable AMP invoice stops accepting
+// payments once its reuse is disabled, while the payments received before
+// remain recorded.
+func testDisableAMPReuse(t *testing.T,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
+	t.Parallel()
+	defer timeout()()
+
+	cfg := defaultRegistryConfig()
+	cfg.GcCanceledInvoicesOnTheFly = true
+	ctx := newTestContext(t, &cfg, makeDB)
+	ctxb := context.Background()
+
+	const amt = lnwire.MilliSatoshi(1000)
+
+	var payAddr, payHash, setID [32]byte
+	_, err := rand.Read(payAddr[:])
+	require.NoError(t, err)
+	_, err = rand.Read(payHash[:])
+	require.NoError(t, err)
+	_, err = rand.Read(setID[:])
+	require.NoError(t, err)
+
+	invoice := &invpkg.Invoice{
+		Terms: invpkg.ContractTerm{
+			Value:       amt,
+			Expiry:      time.Hour,
+			PaymentAddr: payAddr,
+			Features:    ampFeatures.Clone(),
+		},
+		CreationDate: testInvoiceCreationDate,
+	}
+	_, err = ctx.registry.AddInvoice(ctxb, invoice, payHash)
+	require.NoError(t, err)
+
+	// pay sends a single shard AMP payment with the given set id.
+	pay := func(setID [32]byte, htlcID uint64) invpkg.HtlcResolution {
+		sharer, err := amp.NewSeedSharer()
+		require.NoError(t, err)
+		child := sharer.Child(0)
+
+		payload := &mockPayload{
+			mpp: record.NewMPP(amt, payAddr),
+			amp: record.NewAMP(child.Share, setID, 0),
+		}
+
+		resolution, err := ctx.registry.NotifyExitHopHtlc(
+			child.Hash, amt, testHtlcExpiry, testCurrentHeight,
+			getCircuitKey(htlcID), make(chan interface{}, 1),
+			payload,
+		)
+		require.NoError(t, err)
+		require.NotNil(t, resolution)
+
+		return resolution
+	}
+
+	resolution := pay(setID, 0)
+	_, ok := resolution.(*invpkg.HtlcSettleResolution)
+	require.True(t, ok)
+
+	// Reuse can only be disabled for AMP invoices.
+	hodlInvoice := newInvoice(t, true)
+	_, err = ctx.registry.AddInvoice(
+		ctxb, hodlInvoice, testInvoicePaymentHash,
+	)
+	require.NoError(t, err)
+
+	err = ctx.registry.DisableAMPReuse(ctxb, testInvoicePaymentHash)
+	require.ErrorIs(t, err, invpkg.ErrInvoiceNotAMP)
+
+	require.NoError(t, ctx.registry.DisableAMPReuse(ctxb, payHash))
+
+	// The invoice is canceled, but it wasn't garbage collected and still
+	// records the settled payment.
+	dbInvoice, err := ctx.registry.LookupInvoice(ctxb, payHash)
+	require.NoError(t, err)
+	require.Equal(t, invpkg.ContractCanceled, dbInvoice.State)
+	require.Len(t, dbInvoice.AMPState, 1)
+	require.Equal(
+		t, invpkg.HtlcStateSettled, dbInvoice.AMPState[setID].State,
+	)
+	require.Equal(t, amt, dbInvoice.AMPState[setID].AmtPaid)
+
+	// Further payments are rejected.
+	var newSetID [32]byte
+	_, err = rand.Read(newSetID[:])
+	require.NoError(t, err)
+
+	resolution = pay(newSetID, 1)
+	checkFailResolution(t, resolution, invpkg.ResultInvoiceNotOpen)
+}
+
+// testAMPSettleSubscriptionResume tests that settle notifications of AMP
+// sub-payments carry the settle index of the sub-payment, and that a
+// subscription resumed from such an index only receives the sub-payments
+// settled afterwards.
+func testAMPSettleSubscriptionResume(t *testing.T,
+	makeDB func(t *testing.T) (invpkg.InvoiceDB, *clock.TestClock)) {
+
+	t.Parallel()
+	defer timeout()()
+
+	cfg := defaultRegistryConfig()
+	ctx := newTestContext(t, &cfg, makeDB)
+	ctxb := context.Background()
+
+	allSubscriptions, err := ctx.registry.SubscribeNotifications(ctxb, 0, 0)
+	require.NoError(t, err)
+	defer allSubscriptions.Cancel()
+
+	const amt = lnwire.MilliSatoshi(1000)
+
+	var payAddr, payHash [32]byte
+	_, err = rand.Read(payAddr[:])
+	require.NoError(t, err)
+	_, err = rand.Read(payHash[:])
+	require.NoError(t, err)
+
+	invoice := &invpkg.Invoice{
+		Terms: invpkg.ContractTerm{
+			Value:       amt,
+			Expiry:      time.Hour,
+			PaymentAddr: payAddr,
+			Features:    ampFeatures.Clone(),
+		},
+		CreationDate: testInvoiceCreationDate,
+	}
+	_, err = ctx.registry.AddInvoice(ctxb, invoice, payHash)
+	require.NoError(t, err)
+
+	newInvoice := <-allSubscriptions.NewInvoices
+	require.Equal(t, invpkg.ContractOpen, newInvoice.State)
+
+	// pay settles a single shard AMP payment with a new set id and
+	// returns the set id along with the settle notification.
+	pay := func(htlcID uint64) ([32]byte, *invpkg.Invoice) {
+		var setID [32]byte
+		_, err := rand.Read(setID[:])
+		require.NoError(t, err)
+
+		sharer, err := amp.NewSeedSharer()
+		require.NoError(t, err)
+		child := sharer.Child(0)
+
+		payload := &mockPayload{
+			mpp: record.NewMPP(amt, payAddr),
+			amp: record.NewAMP(child.Share, setID, 0),
+		}
+
+		resolution, err := ctx.registry.NotifyExitHopHtlc(
+			child.Hash, amt, testHtlcExpiry, testCurrentHeight,
+			getCircuitKey(htlcID), make(chan interface{}, 1),
+			payload,
+		)
+		require.NoError(t, err)
+		require.IsType(t, &invpkg.HtlcSettleResolution{}, resolution)
+
+		settledInvoice := <-allSubscriptions.SettledInvoices
+		require.Equal(
+			t, invpkg.HtlcStateSettled,
+			settledInvoice.AMPState[setID].State,
+		)
+		require.Equal(
+			t, settledInvoice.AMPState[setID].SettleIndex,
+			settledInvoice.SettleIndex,
+		)
+
+		return setID, settledInvoice
+	}
+
+	_, firstSettle := pay(0)
+	secondSetID, secondSettle := pay(1)
+	require.Greater(t, secondSettle.SettleIndex, firstSettle.SettleIndex)
+
+	// Resuming from the settle index of the first sub-payment only
+	// delivers the second one.
+	resumedSubscription, err := ctx.registry.SubscribeNotifications(
+		ctxb, 0, firstSettle.SettleIndex,
+	)
+	require.NoError(t, err)
+	defer resumedSubscription.Cancel()
+
+	backlogSettle := <-resumedSubscription.SettledInvoices
+	require.Equal(t, secondSettle.SettleIndex, backlogSettle.SettleIndex)
+	require.Equal(
+		t, invpkg.HtlcStateSettled,
+		backlogSettle.AMPState[secondSetID].State,
+	)
+
+	// Sub-payments settled after resuming are delivered as usual.
+	_, thirdSettle := pay(2)
+	liveSettle := <-resumedSubscription.SettledInvoices
+	require.Equal(t, thirdSettle.SettleIndex, liveSettle.SettleIndex)
+
+	select {
+	case inv := <-resumedSubscription.SettledInvoices:
+		t.Fatalf("unexpected settle ntfn with settle_index=%v",
+			inv.SettleIndex)
+
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/record"
+	"github.com/lightningnetwork/lnd/zpay32"
 )
 
 const (
@@ -22,6 +23,16 @@ const (
 	// TODO(halseth): determine the max length payment request when field
 	// lengths are final.
 	MaxPaymentRequestSize = 4096
+
+	// MaxMetadataEntries is the maximum number of metadata entries that
+	// can be attached to an invoice.
+	MaxMetadataEntries = 32
+
+	// MaxMetadataKeySize is the maximum size of a metadata key.
+	MaxMetadataKeySize = 64
+
+	// MaxMetadataValueSize is the maximum size of a metadata value.
+	MaxMetadataValueSize = 1024
 )
 
 var (
@@ -419,6 +430,123 @@ type Invoice struct {
 	// HodlInvoice indicates whether the invoice should be held in the
 	// Accepted state or be settled right away.
 	HodlInvoice bool
+
+	// HodlPolicy is an optional policy that resolves a hodl invoice
+	// automatically if it is held in the Accepted state for too long.
+	HodlPolicy *HodlPolicy
+
+	// Metadata holds arbitrary key-value pairs attached to the invoice by
+	// its creator, for example to correlate it with an order ID. The
+	// metadata is only stored locally and not part of the payment request.
+	Metadata map[string]string
+}
+
+// HodlTimeoutAction describes how a hodl invoice is resolved once its hold
+// duration has passed.
+type HodlTimeoutAction uint8
+
+const (
+	// HodlTimeoutCancel cancels the invoice and fails back its htlcs.
+	HodlTimeoutCancel HodlTimeoutAction = 0
+
+	// HodlTimeoutSettle settles the invoice with the preimage of the
+	// policy.
+	HodlTimeoutSettle HodlTimeoutAction = 1
+)
+
+// String returns a human readable identifier for the action.
+func (a HodlTimeoutAction) String() string {
+	switch a {
+	case HodlTimeoutCancel:
+		return "Cancel"
+
+	case HodlTimeoutSettle:
+		return "Settle"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// HodlPolicy describes how long a hodl invoice may be held in the Accepted
+// state before it is resolved without the help of the application. It
+// protects our channels from htlcs being held until they force close because
+// the application holding the invoice became unresponsive.
+type HodlPolicy struct {
+	// HoldDuration is the maximum time the invoice is held after its htlc
+	// set was accepted.
+	HoldDuration time.Duration
+
+	// Action is the resolution applied once the hold duration has passed.
+	Action HodlTimeoutAction
+
+	// Preimage is the preimage used to settle the invoice. It is only set
+	// if Action is HodlTimeoutSettle.
+	Preimage *lntypes.Preimage
+}
+
+// Validate checks that the policy is consistent with the hash of the invoice
+// it is attached to.
+func (p *HodlPolicy) Validate(hash lntypes.Hash) error {
+	if p.HoldDuration <= 0 {
+		return errors.New("hodl policy hold duration must be positive")
+	}
+
+	switch p.Action {
+	case HodlTimeoutCancel:
+		if p.Preimage != nil {
+			return errors.New("hodl policy with cancel action " +
+				"must not have a preimage")
+		}
+
+	case HodlTimeoutSettle:
+		if p.Preimage == nil {
+			return errors.New("hodl policy with settle action " +
+				"requires a preimage")
+		}
+
+		if !p.Preimage.Matches(hash) {
+			return ErrInvoicePreimageMismatch
+		}
+
+	default:
+		return fmt.Errorf("unknown hodl timeout action: %v", p.Action)
+	}
+
+	return nil
+}
+
+// deadline returns the time at which the policy resolves the invoice, based
+// on the latest accept time of its accepted htlcs. False is returned if the
+// invoice has no accepted htlcs.
+func (p *HodlPolicy) deadline(invoice *Invoice) (time.Time, bool) {
+	var acceptTime time.Time
+	for _, htlc := range invoice.Htlcs {
+		if htlc.State != HtlcStateAccepted {
+			continue
+		}
+
+		if htlc.AcceptTime.After(acceptTime) {
+			acceptTime = htlc.AcceptTime
+		}
+	}
+
+	if acceptTime.IsZero() {
+		return time.Time{}, false
+	}
+
+	return acceptTime.Add(p.HoldDuration), true
+}
+
+// copy returns a deep copy of the policy.
+func (p *HodlPolicy) copy() *HodlPolicy {
+	policy := *p
+	if p.Preimage != nil {
+		preimage := *p.Preimage
+		policy.Preimage = &preimage
+	}
+
+	return &policy
 }
 
 // HTLCSet returns the set of HTLCs belonging to setID and in the provided
@@ -478,6 +606,17 @@ func (i *Invoice) IsKeysend() bool {
 	return len(i.PaymentRequest) == 0 && !i.IsAMP()
 }
 
+// ExpiresAt returns the time at which the invoice expires. Invoices without an
+// expiry expire after the default expiry of BOLT 11 invoices.
+func (i *Invoice) ExpiresAt() time.Time {
+	expiry := i.Terms.Expiry
+	if expiry == 0 {
+		expiry = zpay32.DefaultInvoiceExpiry
+	}
+
+	return i.CreationDate.Add(expiry)
+}
+
 // IsAMP returns true if the invoice is an AMP invoice.
 func (i *Invoice) IsAMP() bool {
 	if i.Terms.Features == nil {
@@ -679,6 +818,10 @@ const (
 	// CancelInvoiceUpdate indicates that this update is trying to cancel
 	// an invoice.
 	CancelInvoiceUpdate
+
+	// AmendInvoiceUpdate indicates that this update replaces the expiry,
+	// memo and payment request of an unsettled invoice.
+	AmendInvoiceUpdate
 )
 
 // String returns a human readable string for the UpdateType.
@@ -696,6 +839,9 @@ func (u UpdateType) String() string {
 	case CancelInvoiceUpdate:
 		return "CancelInvoiceUpdate"
 
+	case AmendInvoiceUpdate:
+		return "AmendInvoiceUpdate"
+
 	default:
 		return fmt.Sprintf("unknown invoice update type: %d", u)
 	}
@@ -720,10 +866,31 @@ type InvoiceUpdateDesc struct {
 	// entire HTLC set each timee an HTLC is to be cancelled.
 	SetID *SetID
 
+	// Amendment describes the new expiry, memo and payment request of the
+	// invoice. It is only used for AmendInvoiceUpdate.
+	Amendment *InvoiceAmendment
+
 	// UpdateType indicates what type of update is being applied.
 	UpdateType UpdateType
 }
 
+// InvoiceAmendment describes the fields of an unsettled invoice that can be
+// changed after it has been created. All fields replace the current values of
+// the invoice. The payment hash and payment address of the invoice remain the
+// same, so that payment requests that were already shared stay payable.
+type InvoiceAmendment struct {
+	// Memo is the new memo of the invoice.
+	Memo []byte
+
+	// Expiry is the new expiry of the invoice relative to its creation
+	// date.
+	Expiry time.Duration
+
+	// PaymentRequest is the new encoded payment request of the invoice. It
+	// must be empty for invoices without a payment request.
+	PaymentRequest []byte
+}
+
 // InvoiceStateUpdateDesc describes an invoice-level state transition.
 type InvoiceStateUpdateDesc struct {
 	// NewState is the new state that this invoice should progress to.
@@ -747,6 +914,10 @@ type InvoiceStateUpdateDesc struct {
 // invoice.
 type InvoiceUpdateCallback = func(invoice *Invoice) (*InvoiceUpdateDesc, error)
 
+// InvoiceAmendCallback is a callback used in the db transaction to derive the
+// amendment of an invoice from its current state.
+type InvoiceAmendCallback = func(invoice *Invoice) (*InvoiceAmendment, error)
+
 // ValidateInvoice assures the invoice passes the checks for all the relevant
 // constraints.
 func ValidateInvoice(i *Invoice, paymentHash lntypes.Hash) error {
@@ -777,6 +948,20 @@ func ValidateInvoice(i *Invoice, paymentHash lntypes.Hash) error {
 		return errors.New("this invoice must have a preimage")
 	}
 
+	if i.HodlPolicy != nil {
+		if !i.HodlInvoice {
+			return errors.New("hodl policy requires a hodl invoice")
+		}
+
+		if err := i.HodlPolicy.Validate(paymentHash); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidateMetadata(i.Metadata); err != nil {
+		return err
+	}
+
 	if len(i.Htlcs) > 0 {
 		return ErrInvoiceHasHtlcs
 	}
@@ -784,6 +969,33 @@ func ValidateInvoice(i *Invoice, paymentHash lntypes.Hash) error {
 	return nil
 }
 
+// ValidateMetadata checks that the metadata of an invoice doesn't exceed the
+// size limits and has no empty keys.
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataEntries {
+		return fmt.Errorf("max number of metadata entries is %v, "+
+			"%v were provided", MaxMetadataEntries, len(metadata))
+	}
+
+	for key, value := range metadata {
+		if len(key) == 0 {
+			return errors.New("metadata key must not be empty")
+		}
+		if len(key) > MaxMetadataKeySize {
+			return fmt.Errorf("max length of a metadata key is "+
+				"%v, key of length %v was provided",
+				MaxMetadataKeySize, len(key))
+		}
+		if len(value) > MaxMetadataValueSize {
+			return fmt.Errorf("max length of a metadata value is "+
+				"%v, value of length %v was provided",
+				MaxMetadataValueSize, len(value))
+		}
+	}
+
+	return nil
+}
+
 // requiresPreimage returns true if the invoice requires a preimage to be valid.
 func (i *Invoice) requiresPreimage() bool {
 	// AMP invoices and hodl invoices are allowed to have no preimage
@@ -800,6 +1012,18 @@ func (i *Invoice) IsPending() bool {
 	return i.State == ContractOpen || i.State == ContractAccepted
 }
 
+// HasAcceptedHtlcs returns true if the invoice has htlcs that are neither
+// settled nor canceled.
+func (i *Invoice) HasAcceptedHtlcs() bool {
+	for _, htlc := range i.Htlcs {
+		if htlc.State == HtlcStateAccepted {
+			return true
+		}
+	}
+
+	return false
+}
+
 // copySlice allocates a new slice and copies the source into it.
 func copySlice(src []byte) []byte {
 	dest := make([]byte, len(src))
@@ -833,6 +1057,17 @@ func CopyInvoice(src *Invoice) (*Invoice, error) {
 		dest.Terms.PaymentPreimage = &preimage
 	}
 
+	if src.HodlPolicy != nil {
+		dest.HodlPolicy = src.HodlPolicy.copy()
+	}
+
+	if src.Metadata != nil {
+		dest.Metadata = make(map[string]string, len(src.Metadata))
+		for k, v := range src.Metadata {
+			dest.Metadata[k] = v
+		}
+	}
+
 	for k, v := range src.Htlcs {
 		dest.Htlcs[k] = v.Copy()
 	}
@@ -1,11 +1,13 @@
 package invoices_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -167,6 +169,10 @@ func TestInvoices(t *testing.T) {
 			name: "FetchPendingInvoices",
 			test: testFetchPendingInvoices,
 		},
+		{
+			name: "FetchInvoiceExpiries",
+			test: testFetchInvoiceExpiries,
+		},
 		{
 			name: "DuplicateSettleInvoice",
 			test: testDuplicateSettleInvoice,
@@ -211,6 +217,10 @@ func TestInvoices(t *testing.T) {
 			name: "AddInvoiceInvalidFeatureDeps",
 			test: testAddInvoiceInvalidFeatureDeps,
 		},
+		{
+			name: "InvoiceMetadata",
+			test: testInvoiceMetadata,
+		},
 	}
 
 	makeKeyValueDB := func(t *testing.T) invpkg.InvoiceDB {
@@ -1284,6 +1294,138 @@ func testFetchPendingInvoices(t *testing.T,
 	require.Equal(t, pendingInvoices, pending)
 }
 
+// testFetchInvoiceExpiries tests that the expiry index holds the open
+// invoices ordered by their expiry and payment hash, and that it is updated
+// when invoices are settled, canceled, amended or deleted.
+func testFetchInvoiceExpiries(t *testing.T,
+	makeDB func(t *testing.T) invpkg.InvoiceDB) {
+
+	t.Parallel()
+	db := makeDB(t)
+
+	ctxb := context.Background()
+
+	// Make sure that an empty database has no expiries.
+	expiries, err := db.FetchInvoiceExpiries(
+		ctxb, invpkg.InvoiceExpiry{}, 10,
+	)
+	require.NoError(t, err)
+	require.Empty(t, expiries)
+
+	// Add invoices of which every two expire at the same time.
+	const numInvoices = 8
+	var (
+		invoices []*invpkg.Invoice
+		hashes   []lntypes.Hash
+		addIndex []uint64
+	)
+	for i := 0; i < numInvoices; i++ {
+		invoice, err := randInvoice(lnwire.MilliSatoshi(i + 1))
+		require.NoError(t, err)
+
+		invoice.CreationDate = invoice.CreationDate.Add(
+			time.Duration(i/2) * time.Second,
+		)
+
+		paymentHash := invoice.Terms.PaymentPreimage.Hash()
+		idx, err := db.AddInvoice(ctxb, invoice, paymentHash)
+		require.NoError(t, err)
+
+		invoices = append(invoices, invoice)
+		hashes = append(hashes, paymentHash)
+		addIndex = append(addIndex, idx)
+	}
+
+	// Settle the first, cancel the second and delete the third invoice.
+	_, err = db.UpdateInvoice(
+		ctxb, invpkg.InvoiceRefByHash(hashes[0]), nil,
+		getUpdateInvoice(0, invoices[0].Terms.Value),
+	)
+	require.NoError(t, err)
+
+	_, err = db.UpdateInvoice(
+		ctxb, invpkg.InvoiceRefByHash(hashes[1]), nil,
+		func(*invpkg.Invoice) (*invpkg.InvoiceUpdateDesc, error) {
+			return &invpkg.InvoiceUpdateDesc{
+				UpdateType: invpkg.CancelInvoiceUpdate,
+				State: &invpkg.InvoiceStateUpdateDesc{
+					NewState: invpkg.ContractCanceled,
+				},
+			}, nil
+		},
+	)
+	require.NoError(t, err)
+
+	err = db.DeleteInvoice(ctxb, []invpkg.InvoiceDeleteRef{{
+		PayHash:  hashes[2],
+		PayAddr:  &invoices[2].Terms.PaymentAddr,
+		AddIndex: addIndex[2],
+	}})
+	require.NoError(t, err)
+
+	// Extend the expiry of the fourth invoice, so it expires last.
+	_, err = db.UpdateInvoice(
+		ctxb, invpkg.InvoiceRefByHash(hashes[3]), nil,
+		func(invoice *invpkg.Invoice) (*invpkg.InvoiceUpdateDesc,
+			error) {
+
+			return &invpkg.InvoiceUpdateDesc{
+				UpdateType: invpkg.AmendInvoiceUpdate,
+				Amendment: &invpkg.InvoiceAmendment{
+					Memo:           invoice.Memo,
+					Expiry:         time.Minute,
+					PaymentRequest: invoice.PaymentRequest,
+				},
+			}, nil
+		},
+	)
+	require.NoError(t, err)
+	invoices[3].Terms.Expiry = time.Minute
+
+	var expected []invpkg.InvoiceExpiry
+	for i := 3; i < numInvoices; i++ {
+		expected = append(expected, invpkg.InvoiceExpiry{
+			PaymentHash: hashes[i],
+			Expiry:      invoices[i].ExpiresAt(),
+			Keysend:     len(invoices[i].PaymentRequest) == 0,
+		})
+	}
+	sort.Slice(expected, func(i, j int) bool {
+		if !expected[i].Expiry.Equal(expected[j].Expiry) {
+			return expected[i].Expiry.Before(expected[j].Expiry)
+		}
+
+		return bytes.Compare(
+			expected[i].PaymentHash[:], expected[j].PaymentHash[:],
+		) < 0
+	})
+
+	// Load the expiries in windows of two, starting after the last
+	// expiry of the previous window.
+	var (
+		loaded []invpkg.InvoiceExpiry
+		after  invpkg.InvoiceExpiry
+	)
+	for {
+		expiries, err := db.FetchInvoiceExpiries(ctxb, after, 2)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(expiries), 2)
+
+		loaded = append(loaded, expiries...)
+		if len(expiries) < 2 {
+			break
+		}
+		after = expiries[len(expiries)-1]
+	}
+
+	require.Len(t, loaded, len(expected))
+	for i := range expected {
+		require.Equal(t, expected[i].PaymentHash, loaded[i].PaymentHash)
+		require.True(t, expected[i].Expiry.Equal(loaded[i].Expiry))
+		require.Equal(t, expected[i].Keysend, loaded[i].Keysend)
+	}
+}
+
 // testDuplicateSettleInvoice tests that if we add a new invoice and settle it
 // twice, then the second time we also receive the invoice that we settled as a
 // return argument.
@@ -2750,3 +2892,71 @@ func testAddInvoiceInvalidFeatureDeps(t *testing.T,
 		lnwire.PaymentAddrOptional,
 	))
 }
+
+// testInvoiceMetadata asserts that invoice metadata is stored along with the
+// invoice and that invoices can be queried by their metadata.
+func testInvoiceMetadata(t *testing.T,
+	makeDB func(t *testing.T) invpkg.InvoiceDB) {
+
+	t.Parallel()
+	db := makeDB(t)
+	ctxb := context.Background()
+
+	metadata := []map[string]string{
+		{"order": "1", "shop": "books"},
+		{"order": "2"},
+		nil,
+	}
+
+	for _, m := range metadata {
+		invoice, err := randInvoice(500)
+		require.NoError(t, err)
+
+		invoice.Metadata = m
+
+		hash := invoice.Terms.PaymentPreimage.Hash()
+		_, err = db.AddInvoice(ctxb, invoice, hash)
+		require.NoError(t, err)
+
+		dbInvoice, err := db.LookupInvoice(
+			ctxb, invpkg.InvoiceRefByHash(hash),
+		)
+		require.NoError(t, err)
+		require.Equal(t, m, dbInvoice.Metadata)
+	}
+
+	queryMetadata := func(key, value string) []map[string]string {
+		resp, err := db.QueryInvoices(ctxb, invpkg.InvoiceQuery{
+			NumMaxInvoices: math.MaxUint64,
+			MetadataKey:    key,
+			MetadataValue:  value,
+		})
+		require.NoError(t, err)
+
+		var result []map[string]string
+		for _, invoice := range resp.Invoices {
+			result = append(result, invoice.Metadata)
+		}
+
+		return result
+	}
+
+	require.Equal(t, metadata, queryMetadata("", ""))
+	require.Equal(t, metadata[:2], queryMetadata("order", ""))
+	require.Equal(t, metadata[1:2], queryMetadata("order", "2"))
+	require.Equal(t, metadata[:1], queryMetadata("shop", "books"))
+	require.Empty(t, queryMetadata("shop", "games"))
+	require.Empty(t, queryMetadata("customer", ""))
+
+	// Invoices with too much metadata are rejected.
+	invoice, err := randInvoice(500)
+	require.NoError(t, err)
+
+	invoice.Metadata = map[string]string{
+		"order": strings.Repeat("x", invpkg.MaxMetadataValueSize+1),
+	}
+
+	hash := invoice.Terms.PaymentPreimage.Hash()
+	_, err = db.AddInvoice(ctxb, invoice, hash)
+	require.Error(t, err)
+}
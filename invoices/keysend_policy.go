@@ -0,0 +1,161 @@
+package invoices
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/record"
+)
+
+// RecordRange is an inclusive range of custom record types.
+type RecordRange struct {
+	// Start is the first record type of the range.
+	Start uint64
+
+	// End is the last record type of the range.
+	End uint64
+}
+
+// Contains returns true if the given record type lies within the range.
+func (r RecordRange) Contains(recordType uint64) bool {
+	return recordType >= r.Start && recordType <= r.End
+}
+
+// KeysendPolicy defines the rules that a spontaneous keysend payment must
+// satisfy before an invoice is created for it.
+type KeysendPolicy struct {
+	// MinAmt is the minimum amount of a keysend payment.
+	MinAmt lnwire.MilliSatoshi
+
+	// AllowedRecords are the custom record type ranges that a keysend
+	// payment may carry, apart from the keysend record itself. If empty,
+	// all custom records are allowed.
+	AllowedRecords []RecordRange
+
+	// RateLimit is the maximum number of keysend payments accepted
+	// through the same incoming channel within RateLimitInterval. The
+	// sender of a keysend payment is unknown to us, so the incoming
+	// channel is the closest approximation of the sender we have. Zero
+	// disables rate limiting.
+	RateLimit uint32
+
+	// RateLimitInterval is the sliding window over which RateLimit is
+	// enforced.
+	RateLimitInterval time.Duration
+
+	// MemoRecord is the custom record type whose value is stored as the
+	// memo of the keysend invoice. Zero disables memo extraction.
+	MemoRecord uint64
+}
+
+// checkRecords verifies that all custom records of a keysend payment lie
+// within the allowed ranges.
+func (p *KeysendPolicy) checkRecords(records record.CustomSet) error {
+	if len(p.AllowedRecords) == 0 {
+		return nil
+	}
+
+	for recordType := range records {
+		if recordType == record.KeySendType {
+			continue
+		}
+
+		allowed := false
+		for _, r := range p.AllowedRecords {
+			if r.Contains(recordType) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Errorf("custom record %v not allowed",
+				recordType)
+		}
+	}
+
+	return nil
+}
+
+// extractMemo returns the memo carried in the memo record of a keysend
+// payment. Values that are not valid utf-8 are ignored and values that
+// exceed the maximum memo size are truncated.
+func (p *KeysendPolicy) extractMemo(records record.CustomSet) []byte {
+	if p.MemoRecord == 0 {
+		return nil
+	}
+
+	memo, ok := records[p.MemoRecord]
+	if !ok || !utf8.Valid(memo) {
+		return nil
+	}
+
+	if len(memo) > MaxMemoSize {
+		memo = memo[:MaxMemoSize]
+
+		// Don't cut a multi-byte character in half.
+		for len(memo) > 0 && !utf8.Valid(memo) {
+			memo = memo[:len(memo)-1]
+		}
+	}
+
+	memoCopy := make([]byte, len(memo))
+	copy(memoCopy, memo)
+
+	return memoCopy
+}
+
+// keysendRateLimiter keeps track of the keysend payments accepted per
+// incoming channel within a sliding window.
+type keysendRateLimiter struct {
+	sync.Mutex
+
+	limit    uint32
+	interval time.Duration
+
+	// accepted holds the accept times of the keysend payments within the
+	// current window per incoming channel.
+	accepted map[lnwire.ShortChannelID][]time.Time
+}
+
+// newKeysendRateLimiter creates a rate limiter that allows limit keysend
+// payments per incoming channel within the given interval.
+func newKeysendRateLimiter(limit uint32,
+	interval time.Duration) *keysendRateLimiter {
+
+	return &keysendRateLimiter{
+		limit:    limit,
+		interval: interval,
+		accepted: make(map[lnwire.ShortChannelID][]time.Time),
+	}
+}
+
+// allow returns true and records the payment if another keysend payment
+// through the given channel is allowed at the given time.
+func (l *keysendRateLimiter) allow(chanID lnwire.ShortChannelID,
+	now time.Time) bool {
+
+	l.Lock()
+	defer l.Unlock()
+
+	// Drop the payments that fell out of the window. The accept times
+	// are ordered, so we only need to find the first one that is still
+	// within the window.
+	times := l.accepted[chanID]
+	cutoff := now.Add(-l.interval)
+	for len(times) > 0 && !times[0].After(cutoff) {
+		times = times[1:]
+	}
+
+	if uint32(len(times)) >= l.limit {
+		l.accepted[chanID] = times
+		return false
+	}
+
+	l.accepted[chanID] = append(times, now)
+
+	return true
+}
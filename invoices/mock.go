@@ -56,6 +56,22 @@ func (m *MockInvoiceDB) FetchPendingInvoices(ctx context.Context) (
 	return args.Get(0).(map[lntypes.Hash]Invoice), args.Error(1)
 }
 
+func (m *MockInvoiceDB) ForEachPendingInvoice(ctx context.Context,
+	cb func(lntypes.Hash, *Invoice) error, reset func()) error {
+
+	args := m.Called(ctx, cb, reset)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceDB) FetchInvoiceExpiries(ctx context.Context,
+	after InvoiceExpiry, limit int) ([]InvoiceExpiry, error) {
+
+	args := m.Called(ctx, after, limit)
+	expiries, _ := args.Get(0).([]InvoiceExpiry)
+
+	return expiries, args.Error(1)
+}
+
 func (m *MockInvoiceDB) QueryInvoices(q InvoiceQuery) (InvoiceSlice, error) {
 	args := m.Called(q)
 	invoiceSlice, _ := args.Get(0).(InvoiceSlice)
@@ -100,6 +100,11 @@ type htlcAcceptResolution struct {
 	// acceptTime is the time at which this htlc was accepted.
 	acceptTime time.Time
 
+	// hodlDeadline is the time at which the hodl policy of the invoice
+	// resolves it. It is only set once the htlc set of a hodl invoice with
+	// a policy was accepted.
+	hodlDeadline time.Time
+
 	// outcome indicates the outcome of the invoice registry update.
 	outcome acceptResolutionResult
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/lightningnetwork/lnd/channeldb/models"
@@ -40,6 +41,24 @@ type SQLInvoiceQueries interface { //nolint:interfacebloat
 	InsertInvoiceHTLCCustomRecord(ctx context.Context,
 		arg sqlc.InsertInvoiceHTLCCustomRecordParams) error
 
+	InsertInvoiceHodlPolicy(ctx context.Context,
+		arg sqlc.InsertInvoiceHodlPolicyParams) error
+
+	InsertInvoiceMetadata(ctx context.Context,
+		arg sqlc.InsertInvoiceMetadataParams) error
+
+	InsertInvoiceExpiry(ctx context.Context,
+		arg sqlc.InsertInvoiceExpiryParams) error
+
+	DeleteInvoiceExpiry(ctx context.Context, invoiceID int64) error
+
+	FetchInvoiceExpiries(ctx context.Context,
+		arg sqlc.FetchInvoiceExpiriesParams) (
+		[]sqlc.FetchInvoiceExpiriesRow, error)
+
+	FetchUnindexedOpenInvoices(ctx context.Context,
+		numLimit int32) ([]sqlc.Invoice, error)
+
 	FilterInvoices(ctx context.Context,
 		arg sqlc.FilterInvoicesParams) ([]sqlc.Invoice, error)
 
@@ -55,12 +74,21 @@ type SQLInvoiceQueries interface { //nolint:interfacebloat
 	GetInvoiceHTLCs(ctx context.Context,
 		invoiceID int64) ([]sqlc.InvoiceHtlc, error)
 
+	GetInvoiceHodlPolicy(ctx context.Context,
+		invoiceID int64) (sqlc.InvoiceHodlPolicy, error)
+
+	GetInvoiceMetadata(ctx context.Context,
+		invoiceID int64) ([]sqlc.InvoiceMetadatum, error)
+
 	UpdateInvoiceState(ctx context.Context,
 		arg sqlc.UpdateInvoiceStateParams) (sql.Result, error)
 
 	UpdateInvoiceAmountPaid(ctx context.Context,
 		arg sqlc.UpdateInvoiceAmountPaidParams) (sql.Result, error)
 
+	AmendInvoice(ctx context.Context,
+		arg sqlc.AmendInvoiceParams) (sql.Result, error)
+
 	NextInvoiceSettleIndex(ctx context.Context) (int64, error)
 
 	UpdateInvoiceHTLC(ctx context.Context,
@@ -152,6 +180,10 @@ type BatchedSQLInvoiceQueries interface {
 type SQLStore struct {
 	db    BatchedSQLInvoiceQueries
 	clock clock.Clock
+
+	// expiriesIndexed is set once the open invoices that were added
+	// before the expiry index existed have been added to it.
+	expiriesIndexed atomic.Bool
 }
 
 // NewSQLStore creates a new SQLStore instance given a open
@@ -254,6 +286,52 @@ func (i *SQLStore) AddInvoice(ctx context.Context,
 			}
 		}
 
+		if policy := newInvoice.HodlPolicy; policy != nil {
+			var preimage []byte
+			if policy.Preimage != nil {
+				preimage = policy.Preimage[:]
+			}
+
+			params := sqlc.InsertInvoiceHodlPolicyParams{
+				InvoiceID:    invoiceID,
+				HoldDuration: int64(policy.HoldDuration),
+				Action:       int16(policy.Action),
+				Preimage:     preimage,
+			}
+
+			err := db.InsertInvoiceHodlPolicy(ctx, params)
+			if err != nil {
+				return fmt.Errorf("unable to insert invoice "+
+					"hodl policy: %w", err)
+			}
+		}
+
+		for key, value := range newInvoice.Metadata {
+			params := sqlc.InsertInvoiceMetadataParams{
+				InvoiceID: invoiceID,
+				Key:       key,
+				Value:     value,
+			}
+
+			err := db.InsertInvoiceMetadata(ctx, params)
+			if err != nil {
+				return fmt.Errorf("unable to insert invoice "+
+					"metadata: %w", err)
+			}
+		}
+
+		// If the invoice is open, add it to the expiry index, so it
+		// is canceled once it expires.
+		if expiry := invoiceExpiryParams(newInvoice); expiry != nil {
+			expiry.InvoiceID = invoiceID
+
+			err := db.InsertInvoiceExpiry(ctx, *expiry)
+			if err != nil {
+				return fmt.Errorf("unable to insert invoice "+
+					"expiry: %w", err)
+			}
+		}
+
 		// Finally add a new event for this invoice.
 		return db.OnInvoiceCreated(ctx, sqlc.OnInvoiceCreatedParams{
 			AddedAt:   newInvoice.CreationDate.UTC(),
@@ -615,6 +693,27 @@ func (i *SQLStore) FetchPendingInvoices(ctx context.Context) (
 
 	var invoices map[lntypes.Hash]Invoice
 
+	err := i.ForEachPendingInvoice(ctx,
+		func(hash lntypes.Hash, invoice *Invoice) error {
+			invoices[hash] = *invoice
+			return nil
+		}, func() {
+			invoices = make(map[lntypes.Hash]Invoice)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// ForEachPendingInvoice calls the passed callback for every invoice that is
+// currently in a "pending" state. The invoices are fetched in pages, so the
+// pending invoices don't need to fit into memory at once.
+func (i *SQLStore) ForEachPendingInvoice(ctx context.Context,
+	cb func(lntypes.Hash, *Invoice) error, reset func()) error {
+
 	readTxOpt := NewSQLInvoiceQueryReadTx()
 	err := i.db.ExecTx(ctx, &readTxOpt, func(db SQLInvoiceQueries) error {
 		limit := queryPaginationLimit
@@ -642,20 +741,170 @@ func (i *SQLStore) FetchPendingInvoices(ctx context.Context) (
 					return 0, err
 				}
 
-				invoices[*hash] = *invoice
+				if err := cb(*hash, invoice); err != nil {
+					return 0, err
+				}
 			}
 
 			return len(rows), nil
 		}, limit)
+	}, reset)
+	if err != nil {
+		return fmt.Errorf("unable to fetch pending invoices: %w", err)
+	}
+
+	return nil
+}
+
+// FetchInvoiceExpiries returns up to limit expiries of open invoices that are
+// ordered after the passed one. The expiries are ordered by the time the
+// invoices expire and, to break ties, by their payment hash.
+func (i *SQLStore) FetchInvoiceExpiries(ctx context.Context,
+	after InvoiceExpiry, limit int) ([]InvoiceExpiry, error) {
+
+	if err := i.indexInvoiceExpiries(ctx); err != nil {
+		return nil, fmt.Errorf("unable to index invoice expiries: %w",
+			err)
+	}
+
+	var expiries []InvoiceExpiry
+
+	readTxOpt := NewSQLInvoiceQueryReadTx()
+	err := i.db.ExecTx(ctx, &readTxOpt, func(db SQLInvoiceQueries) error {
+		rows, err := db.FetchInvoiceExpiries(
+			ctx, sqlc.FetchInvoiceExpiriesParams{
+				ExpiresAfter: expiryUnixNano(after.Expiry),
+				HashAfter:    after.PaymentHash[:],
+				NumLimit:     int32(limit),
+			},
+		)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		for _, row := range rows {
+			hash, err := lntypes.MakeHash(row.Hash)
+			if err != nil {
+				return err
+			}
+
+			expiries = append(expiries, InvoiceExpiry{
+				PaymentHash: hash,
+				Expiry:      time.Unix(0, row.ExpiresAt),
+				Keysend:     row.IsKeysend,
+			})
+		}
+
+		return nil
 	}, func() {
-		invoices = make(map[lntypes.Hash]Invoice)
+		expiries = nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to fetch pending invoices: %w",
+		return nil, fmt.Errorf("unable to fetch invoice expiries: %w",
 			err)
 	}
 
-	return invoices, nil
+	return expiries, nil
+}
+
+// indexInvoiceExpiries adds the open invoices that were added before the
+// expiry index existed to the index. This only needs to be done once, as the
+// index is kept up to date with every added or updated invoice afterwards.
+func (i *SQLStore) indexInvoiceExpiries(ctx context.Context) error {
+	if i.expiriesIndexed.Load() {
+		return nil
+	}
+
+	var writeTxOpt SQLInvoiceQueriesTxOptions
+	err := i.db.ExecTx(ctx, &writeTxOpt, func(db SQLInvoiceQueries) error {
+		for {
+			rows, err := db.FetchUnindexedOpenInvoices(
+				ctx, queryPaginationLimit,
+			)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			for _, row := range rows {
+				_, invoice, err := unmarshalInvoice(row)
+				if err != nil {
+					return err
+				}
+
+				expiry := invoiceExpiryParams(invoice)
+				if expiry == nil {
+					continue
+				}
+
+				err = db.InsertInvoiceExpiry(ctx, *expiry)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(rows) < queryPaginationLimit {
+				return nil
+			}
+		}
+	}, func() {})
+	if err != nil {
+		return err
+	}
+
+	i.expiriesIndexed.Store(true)
+
+	return nil
+}
+
+// expiryUnixNano returns the expiry of an invoice in unix nano as it is
+// stored in the expiry index. Expiries before the unix epoch are ordered
+// first.
+func expiryUnixNano(expiry time.Time) int64 {
+	if !expiry.After(time.Unix(0, 0)) {
+		return 0
+	}
+
+	return expiry.UnixNano()
+}
+
+// invoiceExpiryParams returns the entry of an invoice in the expiry index, or
+// nil if the invoice isn't open and therefore not part of the index.
+func invoiceExpiryParams(invoice *Invoice) *sqlc.InsertInvoiceExpiryParams {
+	if invoice.State != ContractOpen {
+		return nil
+	}
+
+	return &sqlc.InsertInvoiceExpiryParams{
+		InvoiceID: int64(invoice.AddIndex),
+		ExpiresAt: expiryUnixNano(invoice.ExpiresAt()),
+		IsKeysend: len(invoice.PaymentRequest) == 0,
+	}
+}
+
+// updateInvoiceExpiry replaces the entry of an invoice in the expiry index
+// after an update changed its state or its expiry.
+func updateInvoiceExpiry(ctx context.Context, db SQLInvoiceQueries,
+	oldExpiry *sqlc.InsertInvoiceExpiryParams, invoice *Invoice) error {
+
+	newExpiry := invoiceExpiryParams(invoice)
+	switch {
+	case oldExpiry == nil && newExpiry == nil:
+		return nil
+
+	case oldExpiry != nil && newExpiry != nil && *oldExpiry == *newExpiry:
+		return nil
+	}
+
+	err := db.DeleteInvoiceExpiry(ctx, int64(invoice.AddIndex))
+	if err != nil {
+		return err
+	}
+
+	if newExpiry == nil {
+		return nil
+	}
+
+	return db.InsertInvoiceExpiry(ctx, *newExpiry)
 }
 
 // InvoicesSettledSince can be used by callers to catch up any settled invoices
@@ -900,6 +1149,18 @@ func (i *SQLStore) QueryInvoices(ctx context.Context,
 				)
 			}
 
+			if q.MetadataKey != "" {
+				params.MetadataKey = sqldb.SQLStr(
+					q.MetadataKey,
+				)
+
+				if q.MetadataValue != "" {
+					params.MetadataValue = sqldb.SQLStr(
+						q.MetadataValue,
+					)
+				}
+			}
+
 			rows, err := db.FilterInvoices(ctx, params)
 			if err != nil && !errors.Is(err, sql.ErrNoRows) {
 				return 0, fmt.Errorf("unable to get invoices "+
@@ -1211,6 +1472,28 @@ func (s *sqlInvoiceUpdater) UpdateInvoiceAmtPaid(
 	return err
 }
 
+// AmendInvoice replaces the expiry, memo and payment request of the invoice.
+func (s *sqlInvoiceUpdater) AmendInvoice(amendment *InvoiceAmendment) error {
+	var paymentRequestHash []byte
+	if len(amendment.PaymentRequest) > 0 {
+		h := sha256.New()
+		h.Write(amendment.PaymentRequest)
+		paymentRequestHash = h.Sum(nil)
+	}
+
+	_, err := s.db.AmendInvoice(s.ctx, sqlc.AmendInvoiceParams{
+		ID:     int64(s.invoice.AddIndex),
+		Memo:   sqldb.SQLStr(string(amendment.Memo)),
+		Expiry: int32(amendment.Expiry),
+		PaymentRequest: sqldb.SQLStr(
+			string(amendment.PaymentRequest),
+		),
+		PaymentRequestHash: paymentRequestHash,
+	})
+
+	return err
+}
+
 // UpdateAmpState updates the state of the AMP sub invoice identified by the
 // setID.
 func (s *sqlInvoiceUpdater) UpdateAmpState(setID [32]byte,
@@ -1233,6 +1516,13 @@ func (s *sqlInvoiceUpdater) UpdateAmpState(setID [32]byte,
 		// If the invoice is settled, we'll also update the settle time.
 		settledAt = sqldb.SQLTime(s.updateTime.UTC())
 
+		// Reflect the settle index and time in the in-memory AMP state
+		// as well, so that the notified invoice carries them.
+		ampState := s.invoice.AMPState[setID]
+		ampState.SettleIndex = uint64(nextSettleIndex)
+		ampState.SettleDate = s.updateTime
+		s.invoice.AMPState[setID] = ampState
+
 		err = s.db.OnAMPSubInvoiceSettled(
 			s.ctx, sqlc.OnAMPSubInvoiceSettledParams{
 				AddedAt:   s.updateTime.UTC(),
@@ -1307,12 +1597,19 @@ func (i *SQLStore) UpdateInvoice(ctx context.Context, ref InvoiceRef,
 			updateTime: updateTime,
 		}
 
+		// The invoice is updated in place, so we'll need to remember
+		// its entry in the expiry index before the update.
+		oldExpiry := invoiceExpiryParams(invoice)
+
 		payHash := ref.PayHash()
 		updatedInvoice, err = UpdateInvoice(
 			payHash, invoice, updateTime, callback, updater,
 		)
+		if err != nil {
+			return err
+		}
 
-		return err
+		return updateInvoiceExpiry(ctx, db, oldExpiry, updatedInvoice)
 	}, func() {})
 	if txErr != nil {
 		// If the invoice is already settled, we'll return the
@@ -1428,6 +1725,19 @@ func fetchInvoiceData(ctx context.Context, db SQLInvoiceQueries,
 
 	invoice.Terms.Features = features
 
+	// Only hodl invoices can have a hodl policy.
+	if invoice.HodlInvoice {
+		invoice.HodlPolicy, err = getInvoiceHodlPolicy(ctx, db, row.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	invoice.Metadata, err = getInvoiceMetadata(ctx, db, row.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// If this is an AMP invoice, we'll need fetch the AMP state along
 	// with the HTLCs (if requested).
 	if invoice.IsAMP() {
@@ -1465,6 +1775,60 @@ func fetchInvoiceData(ctx context.Context, db SQLInvoiceQueries,
 	return hash, invoice, nil
 }
 
+// getInvoiceHodlPolicy fetches the hodl policy for the given invoice id. Nil
+// is returned if the invoice has no hodl policy.
+func getInvoiceHodlPolicy(ctx context.Context, db SQLInvoiceQueries,
+	invoiceID int64) (*HodlPolicy, error) {
+
+	row, err := db.GetInvoiceHodlPolicy(ctx, invoiceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+
+	case err != nil:
+		return nil, fmt.Errorf("unable to get invoice hodl policy: %w",
+			err)
+	}
+
+	policy := &HodlPolicy{
+		HoldDuration: time.Duration(row.HoldDuration),
+		Action:       HodlTimeoutAction(row.Action),
+	}
+
+	if len(row.Preimage) > 0 {
+		preimage, err := lntypes.MakePreimage(row.Preimage)
+		if err != nil {
+			return nil, err
+		}
+		policy.Preimage = &preimage
+	}
+
+	return policy, nil
+}
+
+// getInvoiceMetadata fetches the metadata for the given invoice id. Nil is
+// returned if the invoice has no metadata.
+func getInvoiceMetadata(ctx context.Context, db SQLInvoiceQueries,
+	invoiceID int64) (map[string]string, error) {
+
+	rows, err := db.GetInvoiceMetadata(ctx, invoiceID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unable to get invoice metadata: %w",
+			err)
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string, len(rows))
+	for _, row := range rows {
+		metadata[row.Key] = row.Value
+	}
+
+	return metadata, nil
+}
+
 // getInvoiceFeatures fetches the invoice features for the given invoice id.
 func getInvoiceFeatures(ctx context.Context, db SQLInvoiceQueries,
 	invoiceID int64) (*lnwire.FeatureVector, error) {
@@ -1,6 +1,7 @@
 package invoices
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"time"
@@ -144,6 +145,12 @@ func UpdateInvoice(hash *lntypes.Hash, invoice *Invoice,
 			return nil, err
 		}
 
+	case AmendInvoiceUpdate:
+		err := amendInvoice(invoice, update.Amendment, updater)
+		if err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, fmt.Errorf("unknown update type: %s",
 			update.UpdateType)
@@ -156,6 +163,65 @@ func UpdateInvoice(hash *lntypes.Hash, invoice *Invoice,
 	return invoice, nil
 }
 
+// amendInvoice replaces the expiry, memo and payment request of an unsettled
+// invoice. Once htlcs have been accepted, the payer has committed to the terms
+// of the invoice and the amendment may only extend the expiry.
+func amendInvoice(invoice *Invoice, amendment *InvoiceAmendment,
+	updater InvoiceUpdater) error {
+
+	if amendment == nil {
+		return errors.New("missing invoice amendment")
+	}
+
+	switch invoice.State {
+	case ContractSettled:
+		return ErrInvoiceAlreadySettled
+
+	case ContractCanceled:
+		return ErrInvoiceAlreadyCanceled
+	}
+
+	switch {
+	case amendment.Expiry <= 0:
+		return fmt.Errorf("invalid invoice expiry %v",
+			amendment.Expiry)
+
+	case len(amendment.Memo) > MaxMemoSize:
+		return fmt.Errorf("max length a memo is %v, and invoice "+
+			"of length %v was provided", MaxMemoSize,
+			len(amendment.Memo))
+
+	case len(amendment.PaymentRequest) > MaxPaymentRequestSize:
+		return fmt.Errorf("max length of payment request is %v, "+
+			"length provided was %v", MaxPaymentRequestSize,
+			len(amendment.PaymentRequest))
+
+	// Invoices without a payment request, such as keysend invoices, can't
+	// gain one and the other way round.
+	case (len(invoice.PaymentRequest) == 0) !=
+		(len(amendment.PaymentRequest) == 0):
+
+		return errors.New("payment request can't be added or removed")
+	}
+
+	if invoice.HasAcceptedHtlcs() &&
+		(amendment.Expiry < invoice.Terms.Expiry ||
+			!bytes.Equal(amendment.Memo, invoice.Memo)) {
+
+		return ErrInvoiceAmendmentNotAllowed
+	}
+
+	if err := updater.AmendInvoice(amendment); err != nil {
+		return err
+	}
+
+	invoice.Memo = amendment.Memo
+	invoice.Terms.Expiry = amendment.Expiry
+	invoice.PaymentRequest = amendment.PaymentRequest
+
+	return nil
+}
+
 // cancelHTLCs tries to cancel the htlcs in the given InvoiceUpdateDesc.
 //
 // NOTE: cancelHTLCs updates will only use the `CancelHtlcs` field in the
@@ -552,6 +618,12 @@ func cancelInvoice(invoice *Invoice, hash *lntypes.Hash,
 	invoice.State = ContractCanceled
 
 	for key, htlc := range invoice.Htlcs {
+		// The htlcs of AMP sub-invoices that were already settled stay
+		// settled.
+		if invoiceIsAMP && htlc.State == HtlcStateSettled {
+			continue
+		}
+
 		canceled, _, err := getUpdatedHtlcState(
 			htlc, ContractCanceled, setID,
 		)
@@ -34,6 +34,11 @@ type Caches struct {
 	// entry.
 	ChannelCacheSize int `long:"channel-cache-size" description:"Maximum number of entries contained in the channel cache, which is used to reduce memory allocations from gossip queries from peers. Each entry requires roughly 2Kb."`
 
+	// GraphSnapshotSize is the maximum number of channels that are kept in
+	// the per-node snapshots of the in-memory graph cache. Memory usage is
+	// roughly 150b per channel.
+	GraphSnapshotSize int `long:"graph-snapshot-size" description:"Maximum number of channels that are kept in the per-node snapshots of the in-memory graph cache, which are used to speed up path finding. Each channel requires roughly 150 bytes. Setting the value to zero disables the snapshots, so the channels of a node are copied on every lookup."`
+
 	// RPCGraphCacheDuration is used to control the flush interval of the
 	// channel graph cache.
 	RPCGraphCacheDuration time.Duration `long:"rpc-graph-cache-duration" description:"The period of time expressed as a duration (1s, 1m, 1h, etc) that the RPC response to DescribeGraph should be cached for."`
@@ -50,6 +55,10 @@ func (c *Caches) Validate() error {
 		return fmt.Errorf("channel cache size %d is less than min: %d",
 			c.ChannelCacheSize, MinChannelCacheSize)
 	}
+	if c.GraphSnapshotSize < 0 {
+		return fmt.Errorf("graph snapshot size %d must not be negative",
+			c.GraphSnapshotSize)
+	}
 
 	return nil
 }
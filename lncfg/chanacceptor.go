@@ -0,0 +1,85 @@
+package lncfg
+
+import (
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// ChanAcceptor holds the configuration options for the built-in channel
+// acceptor rules engine.
+//
+//nolint:lll
+type ChanAcceptor struct {
+	Active bool `long:"active" description:"If true, inbound channel requests are evaluated against the rules of the built-in channel acceptor, in addition to any channel acceptor connected over RPC."`
+
+	MinChanSize int64 `long:"minchansize" description:"The smallest size (in satoshis) of inbound channels that are accepted by the rules engine. Set to 0 to disable."`
+
+	AllowedPeersRaw []string `long:"allowpeer" description:"A hex-encoded pubkey of a peer that may open channels to us. If set, channels from all other peers are rejected. The flag can be specified multiple times to add multiple peers."`
+
+	AllowedPeers map[route.Vertex]struct{}
+
+	DeniedPeersRaw []string `long:"denypeer" description:"A hex-encoded pubkey of a peer whose channels are always rejected. The flag can be specified multiple times to add multiple peers."`
+
+	DeniedPeers map[route.Vertex]struct{}
+
+	MaxChansPerPeer int `long:"maxchansperpeer" description:"The maximum number of pending and open channels that we accept from a single peer. Set to 0 to disable."`
+
+	RequireAnchors bool `long:"requireanchors" description:"If true, only inbound channels that explicitly negotiate a channel type with anchor outputs are accepted."`
+
+	ZeroConfPeersRaw []string `long:"zeroconfpeer" description:"A hex-encoded pubkey of a peer that may open zero-conf channels to us. If set, zero-conf channels of all other peers are rejected. The flag can be specified multiple times to add multiple peers."`
+
+	ZeroConfPeers map[route.Vertex]struct{}
+}
+
+// Parse validates the rules and parses the pubkeys of the peer lists.
+func (c *ChanAcceptor) Parse() error {
+	if c.MinChanSize < 0 {
+		return fmt.Errorf("chanacceptor.minchansize must be " +
+			"non-negative")
+	}
+
+	if c.MaxChansPerPeer < 0 {
+		return fmt.Errorf("chanacceptor.maxchansperpeer must be " +
+			"non-negative")
+	}
+
+	var err error
+	c.AllowedPeers, err = parsePeers(c.AllowedPeersRaw)
+	if err != nil {
+		return fmt.Errorf("invalid chanacceptor.allowpeer: %w", err)
+	}
+
+	c.DeniedPeers, err = parsePeers(c.DeniedPeersRaw)
+	if err != nil {
+		return fmt.Errorf("invalid chanacceptor.denypeer: %w", err)
+	}
+
+	c.ZeroConfPeers, err = parsePeers(c.ZeroConfPeersRaw)
+	if err != nil {
+		return fmt.Errorf("invalid chanacceptor.zeroconfpeer: %w", err)
+	}
+
+	for peer := range c.DeniedPeers {
+		if _, ok := c.AllowedPeers[peer]; ok {
+			return fmt.Errorf("peer %v is both allowed and denied",
+				peer)
+		}
+	}
+
+	return nil
+}
+
+// parsePeers parses a list of hex-encoded pubkeys into a set of vertices.
+func parsePeers(pubkeys []string) (map[route.Vertex]struct{}, error) {
+	peers := make(map[route.Vertex]struct{}, len(pubkeys))
+	for _, pubkeyStr := range pubkeys {
+		vertex, err := route.NewVertexFromStr(pubkeyStr)
+		if err != nil {
+			return nil, err
+		}
+		peers[vertex] = struct{}{}
+	}
+
+	return peers, nil
+}
@@ -1,14 +1,62 @@
 package lncfg
 
+import (
+	"fmt"
+	"math"
+)
+
 // DefaultHoldInvoiceExpiryDelta defines the number of blocks before the expiry
 // height of a hold invoice's htlc that lnd will automatically cancel the
 // invoice to prevent the channel from force closing. This value *must* be
 // greater than DefaultIncomingBroadcastDelta to prevent force closes.
 const DefaultHoldInvoiceExpiryDelta = DefaultIncomingBroadcastDelta + 2
 
+// DefaultExpiryWatcherWindow is the default number of open invoices whose
+// expiry is tracked in memory by the invoice expiry watcher.
+const DefaultExpiryWatcherWindow = 10000
+
 // Invoices holds the configuration options for invoices.
 //
 //nolint:lll
 type Invoices struct {
 	HoldExpiryDelta uint32 `long:"holdexpirydelta" description:"The number of blocks before a hold invoice's htlc expires that the invoice should be canceled to prevent a force close. Force closes will not be prevented if this value is not greater than DefaultIncomingBroadcastDelta."`
+
+	HopHintCapacityWeight float64 `long:"hophintcapacityweight" description:"The weight of the channel capacity when ranking private channels for the selection of hop hints."`
+
+	HopHintBalanceWeight float64 `long:"hophintbalanceweight" description:"The weight of the remote balance when ranking private channels for the selection of hop hints."`
+
+	HopHintUptimeWeight float64 `long:"hophintuptimeweight" description:"The weight of the peer uptime when ranking private channels for the selection of hop hints."`
+
+	ExpiryWatcherWindow int `long:"expirywatcherwindow" description:"The number of open invoices expiring next whose expiry is tracked in memory. The expiries of the remaining open invoices are loaded from the database once the tracked ones are exhausted. Set to 0 to track the expiries of all open invoices in memory."`
+}
+
+// Validate checks that the hop hint weights are non-negative and that at
+// least one of them is positive, and that the expiry watcher window isn't
+// negative.
+func (i *Invoices) Validate() error {
+	if i.ExpiryWatcherWindow < 0 {
+		return fmt.Errorf("invalid expiry watcher window %v, must "+
+			"not be negative", i.ExpiryWatcherWindow)
+	}
+
+	weights := []float64{
+		i.HopHintCapacityWeight, i.HopHintBalanceWeight,
+		i.HopHintUptimeWeight,
+	}
+
+	var sum float64
+	for _, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 0) {
+			return fmt.Errorf("invalid hop hint weight %v, "+
+				"weights must be non-negative", w)
+		}
+		sum += w
+	}
+
+	if sum == 0 {
+		return fmt.Errorf("at least one hop hint weight must be " +
+			"positive")
+	}
+
+	return nil
 }
@@ -0,0 +1,115 @@
+package lncfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/record"
+)
+
+// DefaultKeysendRateLimitInterval is the default interval over which the
+// keysend rate limit is enforced.
+const DefaultKeysendRateLimitInterval = time.Minute
+
+// Keysend holds the configuration options for the policy that inbound keysend
+// payments must satisfy.
+//
+//nolint:lll
+type Keysend struct {
+	MinAmtMsat uint64 `long:"minamtmsat" description:"The minimum amount in millisatoshis of an inbound keysend payment."`
+
+	AllowedRecordsRaw []string `long:"allowedrecords" description:"A custom record type, or an inclusive range of custom record types in the form start-end, that inbound keysend payments may carry. The flag can be specified multiple times. If not set, all custom records are allowed."`
+
+	AllowedRecords []invoices.RecordRange
+
+	RateLimit uint32 `long:"ratelimit" description:"The maximum number of keysend payments accepted through the same incoming channel within ratelimitinterval. As the sender of a keysend payment is unknown, the incoming channel is used to tell senders apart. Zero disables rate limiting."`
+
+	RateLimitInterval time.Duration `long:"ratelimitinterval" description:"The interval over which the keysend rate limit is enforced."`
+
+	MemoRecord uint64 `long:"memorecord" description:"A custom record type whose utf-8 value is stored as the memo of the keysend invoice. Zero disables memo extraction."`
+}
+
+// Validate parses the allowed custom record ranges and checks that the
+// keysend policy is consistent.
+func (k *Keysend) Validate() error {
+	allowedRecords := make(
+		[]invoices.RecordRange, 0, len(k.AllowedRecordsRaw),
+	)
+	for _, rangeStr := range k.AllowedRecordsRaw {
+		r, err := parseRecordRange(rangeStr)
+		if err != nil {
+			return err
+		}
+		allowedRecords = append(allowedRecords, r)
+	}
+
+	k.AllowedRecords = allowedRecords
+
+	if k.RateLimit > 0 && k.RateLimitInterval <= 0 {
+		return fmt.Errorf("keysend rate limit requires a positive " +
+			"interval")
+	}
+
+	if k.MemoRecord != 0 && k.MemoRecord < record.CustomTypeStart {
+		return fmt.Errorf("keysend memo record %v is not a custom "+
+			"record type", k.MemoRecord)
+	}
+
+	return nil
+}
+
+// Policy returns the keysend policy that the invoice registry enforces, or
+// nil if no rules are configured.
+func (k *Keysend) Policy() *invoices.KeysendPolicy {
+	if k.MinAmtMsat == 0 && len(k.AllowedRecords) == 0 &&
+		k.RateLimit == 0 && k.MemoRecord == 0 {
+
+		return nil
+	}
+
+	return &invoices.KeysendPolicy{
+		MinAmt:            lnwire.MilliSatoshi(k.MinAmtMsat),
+		AllowedRecords:    k.AllowedRecords,
+		RateLimit:         k.RateLimit,
+		RateLimitInterval: k.RateLimitInterval,
+		MemoRecord:        k.MemoRecord,
+	}
+}
+
+// parseRecordRange parses a single custom record type or an inclusive range
+// of custom record types in the form start-end.
+func parseRecordRange(rangeStr string) (invoices.RecordRange, error) {
+	startStr, endStr, isRange := strings.Cut(rangeStr, "-")
+	if !isRange {
+		endStr = startStr
+	}
+
+	start, err := strconv.ParseUint(strings.TrimSpace(startStr), 10, 64)
+	if err != nil {
+		return invoices.RecordRange{}, fmt.Errorf("invalid custom "+
+			"record range %q: %w", rangeStr, err)
+	}
+
+	end, err := strconv.ParseUint(strings.TrimSpace(endStr), 10, 64)
+	if err != nil {
+		return invoices.RecordRange{}, fmt.Errorf("invalid custom "+
+			"record range %q: %w", rangeStr, err)
+	}
+
+	switch {
+	case start < record.CustomTypeStart:
+		return invoices.RecordRange{}, fmt.Errorf("custom record "+
+			"range %q starts below %v", rangeStr,
+			record.CustomTypeStart)
+
+	case end < start:
+		return invoices.RecordRange{}, fmt.Errorf("custom record "+
+			"range %q ends before it starts", rangeStr)
+	}
+
+	return invoices.RecordRange{Start: start, End: end}, nil
+}
@@ -0,0 +1,46 @@
+package lncfg
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultRGSListen is the default address the rapid gossip sync server
+	// listens on.
+	DefaultRGSListen = "localhost:10019"
+
+	// minRGSSnapshotInterval is the minimum interval at which the rapid
+	// gossip sync server collects the graph.
+	minRGSSnapshotInterval = time.Minute
+)
+
+// RapidGossipSync holds the configuration of the rapid gossip sync server.
+//
+//nolint:lll
+type RapidGossipSync struct {
+	Active bool `long:"active" description:"If the rapid gossip sync server should be active or not. The server serves snapshots of the public channel graph that light clients can bootstrap their graph from."`
+
+	Listen string `long:"listen" description:"The address the rapid gossip sync server listens on for HTTP requests. Snapshots are served under /snapshot/<last_sync_timestamp>."`
+
+	SnapshotInterval time.Duration `long:"snapshotinterval" description:"The interval at which the graph is collected for new snapshots. Requests in between are served from a cache, so channel updates are delayed by up to this interval."`
+}
+
+// Validate checks that the rapid gossip sync config is consistent.
+func (r *RapidGossipSync) Validate() error {
+	if !r.Active {
+		return nil
+	}
+
+	if r.Listen == "" {
+		return fmt.Errorf("rapid gossip sync server requires a " +
+			"listen address")
+	}
+
+	if r.SnapshotInterval < minRGSSnapshotInterval {
+		return fmt.Errorf("rapid gossip sync snapshot interval must "+
+			"be at least %v", minRGSSnapshotInterval)
+	}
+
+	return nil
+}
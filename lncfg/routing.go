@@ -1,5 +1,10 @@
 package lncfg
 
+import (
+	"fmt"
+	"time"
+)
+
 // Routing holds the configuration options for routing.
 //
 //nolint:lll
@@ -7,4 +12,21 @@ type Routing struct {
 	AssumeChannelValid bool `long:"assumechanvalid" description:"DEPRECATED: Skip checking channel spentness during graph validation. This speedup comes at the risk of using an unvalidated view of the network for routing. (default: false)" hidden:"true"`
 
 	StrictZombiePruning bool `long:"strictgraphpruning" description:"If true, then the graph will be pruned more aggressively for zombies. In practice this means that edges with a single stale edge will be considered a zombie."`
+
+	ZombieExpiry time.Duration `long:"zombieexpiry" description:"The duration after which a channel without a new channel update is considered a zombie and pruned from the graph."`
+
+	ZombiePruneInterval time.Duration `long:"zombiepruneinterval" description:"The interval in which the graph is examined for zombie channels."`
+}
+
+// Validate checks that the zombie pruning parameters are sane.
+func (r *Routing) Validate() error {
+	if r.ZombieExpiry <= 0 {
+		return fmt.Errorf("zombie expiry must be positive")
+	}
+
+	if r.ZombiePruneInterval <= 0 {
+		return fmt.Errorf("zombie prune interval must be positive")
+	}
+
+	return nil
 }
@@ -18,6 +18,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	proxy "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/lightningnetwork/lnd/autopilot"
@@ -574,6 +575,42 @@ func Main(cfg *Config, lisCfg ListenerCfg, implCfg *ImplementationCfg,
 		multiAcceptor = chanacceptor.NewChainedAcceptor()
 	}
 
+	// The per-peer channel policies are always in place, as they're
+	// managed at runtime over RPC.
+	peerPolicies, err := chanacceptor.NewPeerPolicyAcceptor(
+		dbs.ChanStateDB,
+	)
+	if err != nil {
+		return mkErr("unable to load peer channel policies: %v", err)
+	}
+	multiAcceptor.AddAcceptor(peerPolicies)
+
+	// If the built-in rules engine is active, every inbound channel
+	// request has to pass its rules in addition to those of any RPC
+	// acceptor.
+	if cfg.ChanAcceptor.Active {
+		chanStateDB := dbs.ChanStateDB.ChannelStateDB()
+		numChannels := func(peer *btcec.PublicKey) (int, error) {
+			chans, err := chanStateDB.FetchOpenChannels(peer)
+			return len(chans), err
+		}
+
+		acceptorCfg := cfg.ChanAcceptor
+		multiAcceptor.AddAcceptor(chanacceptor.NewRulesAcceptor(
+			&chanacceptor.RulesConfig{
+				MinChanSize: btcutil.Amount(
+					acceptorCfg.MinChanSize,
+				),
+				AllowedPeers:    acceptorCfg.AllowedPeers,
+				DeniedPeers:     acceptorCfg.DeniedPeers,
+				MaxChansPerPeer: acceptorCfg.MaxChansPerPeer,
+				RequireAnchors:  acceptorCfg.RequireAnchors,
+				ZeroConfPeers:   acceptorCfg.ZeroConfPeers,
+				NumChannels:     numChannels,
+			},
+		))
+	}
+
 	// Set up the core server which will listen for incoming peer
 	// connections.
 	server, err := newServer(
@@ -616,6 +653,7 @@ func Main(cfg *Config, lisCfg ListenerCfg, implCfg *ImplementationCfg,
 	err = rpcServer.addDeps(
 		server, interceptorChain.MacaroonService(), cfg.SubRPCServers,
 		atplManager, server.invoices, tower, multiAcceptor,
+		peerPolicies,
 	)
 	if err != nil {
 		return mkErr("unable to add deps to RPC server: %v", err)
@@ -683,6 +721,14 @@ func Main(cfg *Config, lisCfg ListenerCfg, implCfg *ImplementationCfg,
 	// We transition the server state to Active, as the server is up.
 	interceptorChain.SetServerActive()
 
+	// With the server up, scheduled channel policy updates can be
+	// propagated to the network, so we start applying them now.
+	if err := rpcServer.policyScheduler.Start(); err != nil {
+		return mkErr("unable to start policy update scheduler: %v",
+			err)
+	}
+	defer rpcServer.policyScheduler.Stop()
+
 	// Now that the server has started, if the autopilot mode is currently
 	// active, then we'll start the autopilot agent immediately. It will be
 	// stopped together with the autopilot service.
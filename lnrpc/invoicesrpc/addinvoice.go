@@ -23,6 +23,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/netann"
 	"github.com/lightningnetwork/lnd/routing"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/lightningnetwork/lnd/zpay32"
 )
 
@@ -84,6 +85,61 @@ type AddInvoiceConfig struct {
 	// GetAlias allows the peer's alias SCID to be retrieved for private
 	// option_scid_alias channels.
 	GetAlias func(lnwire.ChannelID) (lnwire.ShortChannelID, error)
+
+	// HopHintScoring holds the weights that are used to rank private
+	// channels when selecting hop hints.
+	HopHintScoring HopHintScoring
+
+	// ChannelUptime returns the fraction of the monitored lifetime of a
+	// channel during which its peer was online. It is only required if
+	// the uptime is taken into account when selecting hop hints.
+	ChannelUptime func(chanPoint wire.OutPoint,
+		peer route.Vertex) (float64, error)
+}
+
+// HopHintScoring holds the weights of the criteria that are used to rank our
+// private channels when selecting hop hints for an invoice. Each criterion is
+// normalized to the range [0, 1] before it is weighted. The capacity and the
+// remote balance are taken relative to the largest one among all candidates,
+// the uptime is the fraction of the channel lifetime the peer was online.
+type HopHintScoring struct {
+	// CapacityWeight is the weight of the channel capacity.
+	CapacityWeight float64
+
+	// RemoteBalanceWeight is the weight of the remote balance, which is
+	// our inbound liquidity in the channel.
+	RemoteBalanceWeight float64
+
+	// UptimeWeight is the weight of the uptime of the channel peer.
+	UptimeWeight float64
+}
+
+// DefaultHopHintScoring returns the default hop hint scoring, which ranks the
+// channels by their remote balance only.
+func DefaultHopHintScoring() HopHintScoring {
+	return HopHintScoring{
+		RemoteBalanceWeight: 1,
+	}
+}
+
+// Validate checks that none of the weights is negative.
+func (s HopHintScoring) Validate() error {
+	weights := []float64{
+		s.CapacityWeight, s.RemoteBalanceWeight, s.UptimeWeight,
+	}
+	for _, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 0) {
+			return fmt.Errorf("invalid hop hint weight %v, "+
+				"weights must be non-negative", w)
+		}
+	}
+
+	return nil
+}
+
+// isZero returns true if all weights are zero.
+func (s HopHintScoring) isZero() bool {
+	return s == HopHintScoring{}
 }
 
 // AddInvoiceData contains the required data to create a new invoice.
@@ -129,6 +185,14 @@ type AddInvoiceData struct {
 	// immediately upon receiving the payment.
 	HodlInvoice bool
 
+	// HodlPolicy optionally resolves a hodl invoice automatically if it is
+	// held for too long. It can only be set if HodlInvoice is true.
+	HodlPolicy *invoices.HodlPolicy
+
+	// Metadata holds arbitrary key-value pairs that are stored along with
+	// the invoice.
+	Metadata map[string]string
+
 	// Amp signals whether or not to create an AMP invoice.
 	//
 	// NOTE: Preimage should always be set to nil when this value is true.
@@ -137,6 +201,10 @@ type AddInvoiceData struct {
 	// RouteHints are optional route hints that can each be individually
 	// used to assist in reaching the invoice's destination.
 	RouteHints [][]zpay32.HopHint
+
+	// HopHintChannels are the short channel ids of our channels that are
+	// always included as hop hints, independent of the Private flag.
+	HopHintChannels []uint64
 }
 
 // paymentHashAndPreimage returns the payment hash and preimage for this invoice
@@ -381,13 +449,14 @@ func AddInvoice(ctx context.Context, cfg *AddInvoiceConfig,
 
 	// We make sure that the given invoice routing hints number is within
 	// the valid range
-	if len(invoice.RouteHints) > maxHopHints {
+	numHints := len(invoice.RouteHints) + len(invoice.HopHintChannels)
+	if numHints > maxHopHints {
 		return nil, nil, fmt.Errorf("number of routing hints must "+
 			"not exceed maximum of %v", maxHopHints)
 	}
 
 	// Include route hints if needed.
-	if len(invoice.RouteHints) > 0 || invoice.Private {
+	if numHints > 0 || invoice.Private {
 		// Validate provided hop hints.
 		for _, hint := range invoice.RouteHints {
 			if len(hint) == 0 {
@@ -396,12 +465,13 @@ func AddInvoice(ctx context.Context, cfg *AddInvoiceConfig,
 			}
 		}
 
-		totalHopHints := len(invoice.RouteHints)
+		totalHopHints := numHints
 		if invoice.Private {
 			totalHopHints = maxHopHints
 		}
 
 		hopHintsCfg := newSelectHopHintsCfg(cfg, totalHopHints)
+		hopHintsCfg.PinnedChannels = invoice.HopHintChannels
 		hopHints, err := PopulateHopHints(
 			hopHintsCfg, amtMSat, invoice.RouteHints,
 		)
@@ -470,6 +540,8 @@ func AddInvoice(ctx context.Context, cfg *AddInvoiceConfig,
 			Features:        invoiceFeatures,
 		},
 		HodlInvoice: invoice.HodlInvoice,
+		HodlPolicy:  invoice.HodlPolicy,
+		Metadata:    invoice.Metadata,
 	}
 
 	log.Tracef("[addinvoice] adding new invoice %v",
@@ -646,6 +718,21 @@ type SelectHopHintsCfg struct {
 
 	// MaxHopHints is the maximum number of hop hints we are interested in.
 	MaxHopHints int
+
+	// PinnedChannels are the short channel ids of channels that must be
+	// included as hop hints. Other than the automatically selected
+	// channels, they may be public channels.
+	PinnedChannels []uint64
+
+	// Scoring holds the weights that are used to rank the private
+	// channels. If all weights are zero, the default scoring is used.
+	Scoring HopHintScoring
+
+	// ChannelUptime returns the fraction of the monitored lifetime of a
+	// channel during which its peer was online. It is only called if the
+	// uptime weight is non-zero.
+	ChannelUptime func(chanPoint wire.OutPoint,
+		peer route.Vertex) (float64, error)
 }
 
 func newSelectHopHintsCfg(invoicesCfg *AddInvoiceConfig,
@@ -658,6 +745,8 @@ func newSelectHopHintsCfg(invoicesCfg *AddInvoiceConfig,
 		FetchChannelEdgesByID: invoicesCfg.Graph.FetchChannelEdgesByID,
 		GetAlias:              invoicesCfg.GetAlias,
 		MaxHopHints:           maxHopHints,
+		Scoring:               invoicesCfg.HopHintScoring,
+		ChannelUptime:         invoicesCfg.ChannelUptime,
 	}
 }
 
@@ -689,7 +778,7 @@ func sufficientHints(nHintsLeft int, currentAmount,
 
 // getPotentialHints returns a slice of open channels that should be considered
 // for the hopHint list in an invoice. The slice is sorted in descending order
-// based on the remote balance.
+// based on the score of the channels.
 func getPotentialHints(cfg *SelectHopHintsCfg) ([]*channeldb.OpenChannel,
 	error) {
 
@@ -708,17 +797,84 @@ func getPotentialHints(cfg *SelectHopHintsCfg) ([]*channeldb.OpenChannel,
 		}
 	}
 
-	// Sort the channels in descending remote balance.
-	compareRemoteBalance := func(i, j int) bool {
-		iBalance := privateChannels[i].LocalCommitment.RemoteBalance
-		jBalance := privateChannels[j].LocalCommitment.RemoteBalance
-		return iBalance > jBalance
-	}
-	sort.Slice(privateChannels, compareRemoteBalance)
+	// Sort the channels in descending score.
+	scores := scoreHopHintChannels(cfg, privateChannels)
+	sort.Sort(byScore{
+		channels: privateChannels,
+		scores:   scores,
+	})
 
 	return privateChannels, nil
 }
 
+// byScore sorts channels in descending order of their scores.
+type byScore struct {
+	channels []*channeldb.OpenChannel
+	scores   []float64
+}
+
+func (s byScore) Len() int {
+	return len(s.channels)
+}
+
+func (s byScore) Less(i, j int) bool {
+	return s.scores[i] > s.scores[j]
+}
+
+func (s byScore) Swap(i, j int) {
+	s.channels[i], s.channels[j] = s.channels[j], s.channels[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+}
+
+// scoreHopHintChannels returns the score of every channel according to the
+// hop hint scoring of the config.
+func scoreHopHintChannels(cfg *SelectHopHintsCfg,
+	channels []*channeldb.OpenChannel) []float64 {
+
+	scoring := cfg.Scoring
+	if scoring.isZero() {
+		scoring = DefaultHopHintScoring()
+	}
+
+	var maxCapacity btcutil.Amount
+	var maxBalance lnwire.MilliSatoshi
+	for _, c := range channels {
+		maxCapacity = max(maxCapacity, c.Capacity)
+		maxBalance = max(maxBalance, c.LocalCommitment.RemoteBalance)
+	}
+
+	scores := make([]float64, len(channels))
+	for i, c := range channels {
+		if maxCapacity > 0 {
+			scores[i] += scoring.CapacityWeight *
+				float64(c.Capacity) / float64(maxCapacity)
+		}
+
+		if maxBalance > 0 {
+			balance := c.LocalCommitment.RemoteBalance
+			scores[i] += scoring.RemoteBalanceWeight *
+				float64(balance) / float64(maxBalance)
+		}
+
+		if scoring.UptimeWeight == 0 || cfg.ChannelUptime == nil {
+			continue
+		}
+
+		uptime, err := cfg.ChannelUptime(
+			c.FundingOutpoint, route.NewVertex(c.IdentityPub),
+		)
+		if err != nil {
+			log.Debugf("Unable to get uptime of channel %v: %v",
+				c.FundingOutpoint, err)
+
+			continue
+		}
+		scores[i] += scoring.UptimeWeight * uptime
+	}
+
+	return scores
+}
+
 // shouldIncludeChannel returns true if the channel passes all the checks to
 // be a hopHint in a given invoice.
 func shouldIncludeChannel(cfg *SelectHopHintsCfg,
@@ -736,6 +892,15 @@ func shouldIncludeChannel(cfg *SelectHopHintsCfg,
 
 	hopHintInfo := newHopHintInfo(channel, cfg.IsChannelActive(chanID))
 
+	return channelHopHint(cfg, chanID, hopHintInfo, alreadyIncluded)
+}
+
+// channelHopHint returns the hop hint for a channel if the channel passes all
+// checks to be a hop hint.
+func channelHopHint(cfg *SelectHopHintsCfg, chanID lnwire.ChannelID,
+	hopHintInfo *HopHintInfo, alreadyIncluded map[uint64]bool) (
+	zpay32.HopHint, lnwire.MilliSatoshi, bool) {
+
 	// If this channel can't be a hop hint, then skip it.
 	edgePolicy, canBeHopHint := chanCanBeHopHint(hopHintInfo, cfg)
 	if edgePolicy == nil || !canBeHopHint {
@@ -815,17 +980,27 @@ func PopulateHopHints(cfg *SelectHopHintsCfg, amtMSat lnwire.MilliSatoshi,
 
 	hopHints := forcedHints
 
+	alreadyIncluded := make(map[uint64]bool)
+	for _, hopHint := range hopHints {
+		alreadyIncluded[hopHint[0].ChannelID] = true
+	}
+
+	// The pinned channels are added before any other channel is
+	// considered.
+	if len(cfg.PinnedChannels) > 0 {
+		pinnedHints, err := pinnedHopHints(cfg, alreadyIncluded)
+		if err != nil {
+			return nil, err
+		}
+		hopHints = append(hopHints, pinnedHints...)
+	}
+
 	// If we already have enough hints we don't need to add any more.
 	nHintsLeft := cfg.MaxHopHints - len(hopHints)
 	if nHintsLeft <= 0 {
 		return hopHints, nil
 	}
 
-	alreadyIncluded := make(map[uint64]bool)
-	for _, hopHint := range hopHints {
-		alreadyIncluded[hopHint[0].ChannelID] = true
-	}
-
 	potentialHints, err := getPotentialHints(cfg)
 	if err != nil {
 		return nil, err
@@ -840,3 +1015,65 @@ func PopulateHopHints(cfg *SelectHopHintsCfg, amtMSat lnwire.MilliSatoshi,
 	hopHints = append(hopHints, selectedHints...)
 	return hopHints, nil
 }
+
+// pinnedHopHints returns the hop hints for the pinned channels of the config.
+// An error is returned if a pinned channel isn't one of our open channels or
+// can't be used as a hop hint. The channels are added to the set of already
+// included channels.
+func pinnedHopHints(cfg *SelectHopHintsCfg,
+	alreadyIncluded map[uint64]bool) ([][]zpay32.HopHint, error) {
+
+	openChannels, err := cfg.FetchAllChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	hopHints := make([][]zpay32.HopHint, 0, len(cfg.PinnedChannels))
+	for _, scid := range cfg.PinnedChannels {
+		if alreadyIncluded[scid] {
+			continue
+		}
+
+		var channel *channeldb.OpenChannel
+		for _, c := range openChannels {
+			realScid := c.ShortChannelID
+			if c.IsZeroConf() {
+				realScid = c.ZeroConfRealScid()
+			}
+
+			if c.ShortChannelID.ToUint64() == scid ||
+				realScid.ToUint64() == scid {
+
+				channel = c
+				break
+			}
+		}
+		if channel == nil {
+			return nil, fmt.Errorf("pinned hop hint channel %v "+
+				"not found", scid)
+		}
+
+		chanID := lnwire.NewChanIDFromOutPoint(channel.FundingOutpoint)
+		hopHintInfo := newHopHintInfo(
+			channel, cfg.IsChannelActive(chanID),
+		)
+
+		// The caller explicitly asked to include this channel, so we
+		// also allow public channels.
+		hopHintInfo.IsPublic = false
+
+		hopHint, _, ok := channelHopHint(
+			cfg, chanID, hopHintInfo, alreadyIncluded,
+		)
+		if !ok {
+			return nil, fmt.Errorf("pinned channel %v can't be "+
+				"used as a hop hint", scid)
+		}
+
+		hopHints = append(hopHints, []zpay32.HopHint{hopHint})
+		alreadyIncluded[scid] = true
+		alreadyIncluded[hopHint.ChannelID] = true
+	}
+
+	return hopHints, nil
+}
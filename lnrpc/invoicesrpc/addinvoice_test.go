@@ -6,10 +6,12 @@ import (
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/lightningnetwork/lnd/zpay32"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -896,3 +898,156 @@ func TestPopulateHopHints(t *testing.T) {
 		})
 	}
 }
+
+// TestGetPotentialHintsScoring tests that private channels are ranked
+// according to the configured hop hint scoring.
+func TestGetPotentialHintsScoring(t *testing.T) {
+	t.Parallel()
+
+	newChannel := func(index uint32, capacity btcutil.Amount,
+		remoteBalance lnwire.MilliSatoshi) *channeldb.OpenChannel {
+
+		return &channeldb.OpenChannel{
+			FundingOutpoint: wire.OutPoint{Index: index},
+			Capacity:        capacity,
+			IdentityPub:     getTestPubKey(),
+			LocalCommitment: channeldb.ChannelCommitment{
+				RemoteBalance: remoteBalance,
+			},
+		}
+	}
+
+	// The first channel has the highest remote balance, the second one
+	// the highest capacity and the third one the best uptime.
+	channels := []*channeldb.OpenChannel{
+		newChannel(1, 1_000_000, 800_000_000),
+		newChannel(2, 5_000_000, 100_000_000),
+		newChannel(3, 2_000_000, 500_000_000),
+		{
+			FundingOutpoint: wire.OutPoint{Index: 4},
+			ChannelFlags:    lnwire.FFAnnounceChannel,
+		},
+	}
+	uptimes := map[uint32]float64{1: 0.2, 2: 0.5, 3: 1}
+
+	testCases := []struct {
+		name          string
+		scoring       HopHintScoring
+		expectedOrder []uint32
+	}{
+		{
+			name:          "default",
+			expectedOrder: []uint32{1, 3, 2},
+		},
+		{
+			name:          "capacity",
+			scoring:       HopHintScoring{CapacityWeight: 1},
+			expectedOrder: []uint32{2, 3, 1},
+		},
+		{
+			name:          "uptime",
+			scoring:       HopHintScoring{UptimeWeight: 1},
+			expectedOrder: []uint32{3, 2, 1},
+		},
+		{
+			name: "mixed",
+			scoring: HopHintScoring{
+				RemoteBalanceWeight: 1,
+				UptimeWeight:        1,
+			},
+			expectedOrder: []uint32{3, 1, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &SelectHopHintsCfg{
+				FetchAllChannels: func() (
+					[]*channeldb.OpenChannel, error) {
+
+					return append(
+						[]*channeldb.OpenChannel{},
+						channels...,
+					), nil
+				},
+				Scoring: tc.scoring,
+				ChannelUptime: func(chanPoint wire.OutPoint,
+					_ route.Vertex) (float64, error) {
+
+					return uptimes[chanPoint.Index], nil
+				},
+			}
+
+			potentialHints, err := getPotentialHints(cfg)
+			require.NoError(t, err)
+
+			order := make([]uint32, 0, len(potentialHints))
+			for _, c := range potentialHints {
+				order = append(order, c.FundingOutpoint.Index)
+			}
+			require.Equal(t, tc.expectedOrder, order)
+		})
+	}
+}
+
+// TestPopulateHopHintsPinned tests that pinned channels are always included
+// as hop hints, also if they are public.
+func TestPopulateHopHintsPinned(t *testing.T) {
+	t.Parallel()
+
+	publicOutpoint := wire.OutPoint{Index: 1}
+	privateOutpoint := wire.OutPoint{Index: 2}
+	allChannels := []*channeldb.OpenChannel{
+		{
+			FundingOutpoint: publicOutpoint,
+			ShortChannelID:  lnwire.NewShortChanIDFromInt(1),
+			IdentityPub:     getTestPubKey(),
+			ChannelFlags:    lnwire.FFAnnounceChannel,
+		},
+		{
+			FundingOutpoint: privateOutpoint,
+			ShortChannelID:  lnwire.NewShortChanIDFromInt(2),
+			IdentityPub:     getTestPubKey(),
+			LocalCommitment: channeldb.ChannelCommitment{
+				RemoteBalance: 10_000_000,
+			},
+		},
+	}
+
+	h := newHopHintsConfigMock(t)
+	h.Mock.On("FetchAllChannels").Return(allChannels, nil)
+	h.Mock.On("IsChannelActive", mock.Anything).Return(true)
+	h.Mock.On("IsPublicNode", mock.Anything).Return(true, nil)
+	h.Mock.On("FetchChannelEdgesByID", mock.Anything).Return(
+		&models.ChannelEdgeInfo{}, &models.ChannelEdgePolicy{},
+		&models.ChannelEdgePolicy{}, nil,
+	)
+
+	cfg := &SelectHopHintsCfg{
+		IsPublicNode:          h.IsPublicNode,
+		IsChannelActive:       h.IsChannelActive,
+		FetchChannelEdgesByID: h.FetchChannelEdgesByID,
+		GetAlias:              h.GetAlias,
+		FetchAllChannels:      h.FetchAllChannels,
+		MaxHopHints:           2,
+		PinnedChannels:        []uint64{1},
+	}
+
+	// The public channel is included because it is pinned, the private
+	// channel is selected as usual.
+	hopHints, err := PopulateHopHints(cfg, 1_000_000, nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, [][]zpay32.HopHint{
+		{{NodeID: getTestPubKey(), ChannelID: 1}},
+		{{NodeID: getTestPubKey(), ChannelID: 2}},
+	}, hopHints)
+
+	// Pinning an unknown channel fails.
+	cfg.PinnedChannels = []uint64{3}
+	_, err = PopulateHopHints(cfg, 1_000_000, nil)
+	require.ErrorContains(t, err, "not found")
+}
@@ -5,11 +5,13 @@ package invoicesrpc
 
 import (
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/macaroons"
 	"github.com/lightningnetwork/lnd/netann"
+	"github.com/lightningnetwork/lnd/routing/route"
 )
 
 // Config is the primary configuration struct for the invoices RPC server. It
@@ -64,4 +66,13 @@ type Config struct {
 	// GetAlias returns the peer's alias SCID if it exists given the
 	// 32-byte ChannelID.
 	GetAlias func(lnwire.ChannelID) (lnwire.ShortChannelID, error)
+
+	// HopHintScoring holds the weights that are used to rank private
+	// channels when selecting hop hints.
+	HopHintScoring HopHintScoring
+
+	// ChannelUptime returns the fraction of the monitored lifetime of a
+	// channel during which its peer was online.
+	ChannelUptime func(chanPoint wire.OutPoint,
+		peer route.Vertex) (float64, error)
 }
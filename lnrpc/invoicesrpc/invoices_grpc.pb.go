@@ -36,9 +36,31 @@ type InvoicesClient interface {
 	// SettleInvoice settles an accepted invoice. If the invoice is already
 	// settled, this call will succeed.
 	SettleInvoice(ctx context.Context, in *SettleInvoiceMsg, opts ...grpc.CallOption) (*SettleInvoiceResp, error)
+	// lncli: `updateinvoice`
+	// UpdateInvoice amends the expiry, memo and fallback address of an unsettled
+	// invoice. The invoice keeps its payment hash and payment address, so payment
+	// requests that were already shared remain payable. The returned payment
+	// request reflects the amended invoice. Once htlcs have been accepted, only
+	// the expiry of the invoice can be extended.
+	UpdateInvoice(ctx context.Context, in *UpdateInvoiceRequest, opts ...grpc.CallOption) (*UpdateInvoiceResponse, error)
 	// LookupInvoiceV2 attempts to look up at invoice. An invoice can be refrenced
 	// using either its payment hash, payment address, or set ID.
 	LookupInvoiceV2(ctx context.Context, in *LookupInvoiceMsg, opts ...grpc.CallOption) (*lnrpc.Invoice, error)
+	// lncli: `listamppayments`
+	// ListAMPPayments lists the individual payments that were received against a
+	// reusable AMP invoice. Each payment is identified by its set ID.
+	ListAMPPayments(ctx context.Context, in *ListAMPPaymentsRequest, opts ...grpc.CallOption) (*ListAMPPaymentsResponse, error)
+	// SubscribeAMPPayments returns a uni-directional stream (server -> client)
+	// of the payments received against a reusable AMP invoice. Initially all
+	// payments received so far are sent out, followed by every payment whose
+	// state changes. The stream ends once the invoice stops accepting payments.
+	SubscribeAMPPayments(ctx context.Context, in *SubscribeAMPPaymentsRequest, opts ...grpc.CallOption) (Invoices_SubscribeAMPPaymentsClient, error)
+	// lncli: `disableampreuse`
+	// DisableAMPReuse stops a reusable AMP invoice from accepting further
+	// payments. The invoice is canceled and the htlcs of payments that are still
+	// in flight are failed back, while the payments received so far remain
+	// listed.
+	DisableAMPReuse(ctx context.Context, in *DisableAMPReuseRequest, opts ...grpc.CallOption) (*DisableAMPReuseResponse, error)
 }
 
 type invoicesClient struct {
@@ -108,6 +130,15 @@ func (c *invoicesClient) SettleInvoice(ctx context.Context, in *SettleInvoiceMsg
 	return out, nil
 }
 
+func (c *invoicesClient) UpdateInvoice(ctx context.Context, in *UpdateInvoiceRequest, opts ...grpc.CallOption) (*UpdateInvoiceResponse, error) {
+	out := new(UpdateInvoiceResponse)
+	err := c.cc.Invoke(ctx, "/invoicesrpc.Invoices/UpdateInvoice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *invoicesClient) LookupInvoiceV2(ctx context.Context, in *LookupInvoiceMsg, opts ...grpc.CallOption) (*lnrpc.Invoice, error) {
 	out := new(lnrpc.Invoice)
 	err := c.cc.Invoke(ctx, "/invoicesrpc.Invoices/LookupInvoiceV2", in, out, opts...)
@@ -117,6 +148,56 @@ func (c *invoicesClient) LookupInvoiceV2(ctx context.Context, in *LookupInvoiceM
 	return out, nil
 }
 
+func (c *invoicesClient) ListAMPPayments(ctx context.Context, in *ListAMPPaymentsRequest, opts ...grpc.CallOption) (*ListAMPPaymentsResponse, error) {
+	out := new(ListAMPPaymentsResponse)
+	err := c.cc.Invoke(ctx, "/invoicesrpc.Invoices/ListAMPPayments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invoicesClient) SubscribeAMPPayments(ctx context.Context, in *SubscribeAMPPaymentsRequest, opts ...grpc.CallOption) (Invoices_SubscribeAMPPaymentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Invoices_ServiceDesc.Streams[1], "/invoicesrpc.Invoices/SubscribeAMPPayments", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &invoicesSubscribeAMPPaymentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Invoices_SubscribeAMPPaymentsClient interface {
+	Recv() (*AMPPayment, error)
+	grpc.ClientStream
+}
+
+type invoicesSubscribeAMPPaymentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *invoicesSubscribeAMPPaymentsClient) Recv() (*AMPPayment, error) {
+	m := new(AMPPayment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *invoicesClient) DisableAMPReuse(ctx context.Context, in *DisableAMPReuseRequest, opts ...grpc.CallOption) (*DisableAMPReuseResponse, error) {
+	out := new(DisableAMPReuseResponse)
+	err := c.cc.Invoke(ctx, "/invoicesrpc.Invoices/DisableAMPReuse", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InvoicesServer is the server API for Invoices service.
 // All implementations must embed UnimplementedInvoicesServer
 // for forward compatibility
@@ -138,9 +219,31 @@ type InvoicesServer interface {
 	// SettleInvoice settles an accepted invoice. If the invoice is already
 	// settled, this call will succeed.
 	SettleInvoice(context.Context, *SettleInvoiceMsg) (*SettleInvoiceResp, error)
+	// lncli: `updateinvoice`
+	// UpdateInvoice amends the expiry, memo and fallback address of an unsettled
+	// invoice. The invoice keeps its payment hash and payment address, so payment
+	// requests that were already shared remain payable. The returned payment
+	// request reflects the amended invoice. Once htlcs have been accepted, only
+	// the expiry of the invoice can be extended.
+	UpdateInvoice(context.Context, *UpdateInvoiceRequest) (*UpdateInvoiceResponse, error)
 	// LookupInvoiceV2 attempts to look up at invoice. An invoice can be refrenced
 	// using either its payment hash, payment address, or set ID.
 	LookupInvoiceV2(context.Context, *LookupInvoiceMsg) (*lnrpc.Invoice, error)
+	// lncli: `listamppayments`
+	// ListAMPPayments lists the individual payments that were received against a
+	// reusable AMP invoice. Each payment is identified by its set ID.
+	ListAMPPayments(context.Context, *ListAMPPaymentsRequest) (*ListAMPPaymentsResponse, error)
+	// SubscribeAMPPayments returns a uni-directional stream (server -> client)
+	// of the payments received against a reusable AMP invoice. Initially all
+	// payments received so far are sent out, followed by every payment whose
+	// state changes. The stream ends once the invoice stops accepting payments.
+	SubscribeAMPPayments(*SubscribeAMPPaymentsRequest, Invoices_SubscribeAMPPaymentsServer) error
+	// lncli: `disableampreuse`
+	// DisableAMPReuse stops a reusable AMP invoice from accepting further
+	// payments. The invoice is canceled and the htlcs of payments that are still
+	// in flight are failed back, while the payments received so far remain
+	// listed.
+	DisableAMPReuse(context.Context, *DisableAMPReuseRequest) (*DisableAMPReuseResponse, error)
 	mustEmbedUnimplementedInvoicesServer()
 }
 
@@ -160,9 +263,21 @@ func (UnimplementedInvoicesServer) AddHoldInvoice(context.Context, *AddHoldInvoi
 func (UnimplementedInvoicesServer) SettleInvoice(context.Context, *SettleInvoiceMsg) (*SettleInvoiceResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SettleInvoice not implemented")
 }
+func (UnimplementedInvoicesServer) UpdateInvoice(context.Context, *UpdateInvoiceRequest) (*UpdateInvoiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateInvoice not implemented")
+}
 func (UnimplementedInvoicesServer) LookupInvoiceV2(context.Context, *LookupInvoiceMsg) (*lnrpc.Invoice, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LookupInvoiceV2 not implemented")
 }
+func (UnimplementedInvoicesServer) ListAMPPayments(context.Context, *ListAMPPaymentsRequest) (*ListAMPPaymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAMPPayments not implemented")
+}
+func (UnimplementedInvoicesServer) SubscribeAMPPayments(*SubscribeAMPPaymentsRequest, Invoices_SubscribeAMPPaymentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAMPPayments not implemented")
+}
+func (UnimplementedInvoicesServer) DisableAMPReuse(context.Context, *DisableAMPReuseRequest) (*DisableAMPReuseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableAMPReuse not implemented")
+}
 func (UnimplementedInvoicesServer) mustEmbedUnimplementedInvoicesServer() {}
 
 // UnsafeInvoicesServer may be embedded to opt out of forward compatibility for this service.
@@ -251,6 +366,24 @@ func _Invoices_SettleInvoice_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Invoices_UpdateInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoicesServer).UpdateInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/invoicesrpc.Invoices/UpdateInvoice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoicesServer).UpdateInvoice(ctx, req.(*UpdateInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Invoices_LookupInvoiceV2_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LookupInvoiceMsg)
 	if err := dec(in); err != nil {
@@ -269,6 +402,63 @@ func _Invoices_LookupInvoiceV2_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Invoices_ListAMPPayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAMPPaymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoicesServer).ListAMPPayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/invoicesrpc.Invoices/ListAMPPayments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoicesServer).ListAMPPayments(ctx, req.(*ListAMPPaymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Invoices_SubscribeAMPPayments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeAMPPaymentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InvoicesServer).SubscribeAMPPayments(m, &invoicesSubscribeAMPPaymentsServer{stream})
+}
+
+type Invoices_SubscribeAMPPaymentsServer interface {
+	Send(*AMPPayment) error
+	grpc.ServerStream
+}
+
+type invoicesSubscribeAMPPaymentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *invoicesSubscribeAMPPaymentsServer) Send(m *AMPPayment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Invoices_DisableAMPReuse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableAMPReuseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InvoicesServer).DisableAMPReuse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/invoicesrpc.Invoices/DisableAMPReuse",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InvoicesServer).DisableAMPReuse(ctx, req.(*DisableAMPReuseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Invoices_ServiceDesc is the grpc.ServiceDesc for Invoices service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -288,10 +478,22 @@ var Invoices_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SettleInvoice",
 			Handler:    _Invoices_SettleInvoice_Handler,
 		},
+		{
+			MethodName: "UpdateInvoice",
+			Handler:    _Invoices_UpdateInvoice_Handler,
+		},
 		{
 			MethodName: "LookupInvoiceV2",
 			Handler:    _Invoices_LookupInvoiceV2_Handler,
 		},
+		{
+			MethodName: "ListAMPPayments",
+			Handler:    _Invoices_ListAMPPayments_Handler,
+		},
+		{
+			MethodName: "DisableAMPReuse",
+			Handler:    _Invoices_DisableAMPReuse_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -299,6 +501,11 @@ var Invoices_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Invoices_SubscribeSingleInvoice_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeAMPPayments",
+			Handler:       _Invoices_SubscribeAMPPayments_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "invoicesrpc/invoices.proto",
 }
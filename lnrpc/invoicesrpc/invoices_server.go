@@ -4,17 +4,22 @@
 package invoicesrpc
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/zpay32"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -61,10 +66,26 @@ var (
 			Entity: "invoices",
 			Action: "write",
 		}},
+		"/invoicesrpc.Invoices/UpdateInvoice": {{
+			Entity: "invoices",
+			Action: "write",
+		}},
 		"/invoicesrpc.Invoices/LookupInvoiceV2": {{
 			Entity: "invoices",
 			Action: "write",
 		}},
+		"/invoicesrpc.Invoices/ListAMPPayments": {{
+			Entity: "invoices",
+			Action: "read",
+		}},
+		"/invoicesrpc.Invoices/SubscribeAMPPayments": {{
+			Entity: "invoices",
+			Action: "read",
+		}},
+		"/invoicesrpc.Invoices/DisableAMPReuse": {{
+			Entity: "invoices",
+			Action: "write",
+		}},
 	}
 
 	// DefaultInvoicesMacFilename is the default name of the invoices
@@ -334,6 +355,8 @@ func (s *Server) AddHoldInvoice(ctx context.Context,
 		GenInvoiceFeatures:    s.cfg.GenInvoiceFeatures,
 		GenAmpInvoiceFeatures: s.cfg.GenAmpInvoiceFeatures,
 		GetAlias:              s.cfg.GetAlias,
+		HopHintScoring:        s.cfg.HopHintScoring,
+		ChannelUptime:         s.cfg.ChannelUptime,
 	}
 
 	hash, err := lntypes.MakeHash(invoice.Hash)
@@ -351,6 +374,12 @@ func (s *Server) AddHoldInvoice(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+
+	hodlPolicy, err := unmarshallHodlPolicy(invoice)
+	if err != nil {
+		return nil, err
+	}
+
 	addInvoiceData := &AddInvoiceData{
 		Memo:            invoice.Memo,
 		Hash:            &hash,
@@ -361,8 +390,11 @@ func (s *Server) AddHoldInvoice(ctx context.Context,
 		CltvExpiry:      invoice.CltvExpiry,
 		Private:         invoice.Private,
 		HodlInvoice:     true,
+		HodlPolicy:      hodlPolicy,
 		Preimage:        nil,
 		RouteHints:      routeHints,
+		HopHintChannels: invoice.HopHintChanIds,
+		Metadata:        invoice.Metadata,
 	}
 
 	_, dbInvoice, err := AddInvoice(ctx, addInvoiceCfg, addInvoiceData)
@@ -377,6 +409,180 @@ func (s *Server) AddHoldInvoice(ctx context.Context,
 	}, nil
 }
 
+// unmarshallHodlPolicy converts the hold timeout fields of an rpc hold invoice
+// request into a hodl policy. Nil is returned if no hold timeout is set.
+func unmarshallHodlPolicy(req *AddHoldInvoiceRequest) (*invoices.HodlPolicy,
+	error) {
+
+	if req.HoldTimeout == 0 {
+		if len(req.HoldTimeoutPreimage) > 0 ||
+			req.HoldTimeoutAction != HoldTimeoutAction_CANCEL {
+
+			return nil, errors.New("hold timeout action and " +
+				"preimage require a hold timeout")
+		}
+
+		return nil, nil
+	}
+
+	policy := &invoices.HodlPolicy{
+		HoldDuration: time.Duration(req.HoldTimeout) * time.Second,
+	}
+
+	switch req.HoldTimeoutAction {
+	case HoldTimeoutAction_CANCEL:
+		policy.Action = invoices.HodlTimeoutCancel
+
+	case HoldTimeoutAction_SETTLE:
+		policy.Action = invoices.HodlTimeoutSettle
+
+	default:
+		return nil, fmt.Errorf("unknown hold timeout action: %v",
+			req.HoldTimeoutAction)
+	}
+
+	if len(req.HoldTimeoutPreimage) > 0 {
+		preimage, err := lntypes.MakePreimage(req.HoldTimeoutPreimage)
+		if err != nil {
+			return nil, err
+		}
+		policy.Preimage = &preimage
+	}
+
+	// The policy is validated against the payment hash when the invoice is
+	// added.
+	return policy, nil
+}
+
+// UpdateInvoice amends the expiry, memo and fallback address of an unsettled
+// invoice. The payment request is re-encoded with the same payment hash,
+// payment address and timestamp, so previously shared payment requests remain
+// payable.
+func (s *Server) UpdateInvoice(ctx context.Context,
+	req *UpdateInvoiceRequest) (*UpdateInvoiceResponse, error) {
+
+	paymentHash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the same limits to the expiry as when adding an invoice.
+	maxExpiry := time.Hour * 24 * 365
+	switch {
+	case req.Expiry < 0:
+		return nil, fmt.Errorf("expiry of %v seconds must not be "+
+			"negative", req.Expiry)
+
+	case float64(req.Expiry) > maxExpiry.Seconds():
+		return nil, fmt.Errorf("expiry of %v seconds greater than "+
+			"max expiry of %v seconds", req.Expiry,
+			maxExpiry.Seconds())
+	}
+
+	var fallbackAddr btcutil.Address
+	if len(req.FallbackAddr) > 0 {
+		fallbackAddr, err = btcutil.DecodeAddress(
+			req.FallbackAddr, s.cfg.ChainParams,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback address: %w",
+				err)
+		}
+
+		if !fallbackAddr.IsForNet(s.cfg.ChainParams) {
+			return nil, fmt.Errorf("fallback address is not for "+
+				"%s", s.cfg.ChainParams.Name)
+		}
+	}
+
+	amend := func(invoice *invoices.Invoice) (*invoices.InvoiceAmendment,
+		error) {
+
+		amendment := &invoices.InvoiceAmendment{
+			Memo:           invoice.Memo,
+			Expiry:         invoice.Terms.Expiry,
+			PaymentRequest: invoice.PaymentRequest,
+		}
+		if len(req.Memo) > 0 {
+			amendment.Memo = []byte(req.Memo)
+		}
+		if req.Expiry > 0 {
+			amendment.Expiry = time.Duration(req.Expiry) *
+				time.Second
+		}
+
+		// Invoices without a payment request, such as keysend
+		// invoices, have nothing to re-encode.
+		if len(invoice.PaymentRequest) == 0 {
+			if fallbackAddr != nil {
+				return nil, errors.New("invoice without " +
+					"payment request can't have a " +
+					"fallback address")
+			}
+
+			return amendment, nil
+		}
+
+		payReq, err := zpay32.Decode(
+			string(invoice.PaymentRequest), s.cfg.ChainParams,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// The fallback address is only part of the payment request, so
+		// the invoice registry can't protect it from changes once the
+		// payer committed htlcs to the invoice.
+		if fallbackAddr != nil {
+			if invoice.HasAcceptedHtlcs() {
+				return nil,
+					invoices.ErrInvoiceAmendmentNotAllowed
+			}
+
+			payReq.FallbackAddr = fallbackAddr
+		}
+
+		// Invoices that use a description hash keep the memo out of
+		// the payment request.
+		if payReq.Description != nil {
+			memo := string(amendment.Memo)
+			payReq.Description = &memo
+		}
+		zpay32.Expiry(amendment.Expiry)(payReq)
+
+		// The destination was recovered from the signature when
+		// decoding. Our payment requests don't carry it explicitly.
+		payReq.Destination = nil
+
+		payReqString, err := payReq.Encode(zpay32.MessageSigner{
+			SignCompact: func(msg []byte) ([]byte, error) {
+				return s.cfg.NodeSigner.SignMessageCompact(
+					msg, false,
+				)
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		amendment.PaymentRequest = []byte(payReqString)
+
+		return amendment, nil
+	}
+
+	invoice, err := s.cfg.InvoiceRegistry.AmendInvoice(
+		ctx, paymentHash, amend,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Updated invoice %v", paymentHash)
+
+	return &UpdateInvoiceResponse{
+		PaymentRequest: string(invoice.PaymentRequest),
+	}, nil
+}
+
 // LookupInvoiceV2 attempts to look up at invoice. An invoice can be referenced
 // using either its payment hash, payment address, or set ID.
 func (s *Server) LookupInvoiceV2(ctx context.Context,
@@ -446,3 +652,195 @@ func (s *Server) LookupInvoiceV2(ctx context.Context,
 
 	return CreateRPCInvoice(&invoice, s.cfg.ChainParams)
 }
+
+// ListAMPPayments lists the individual payments that were received against a
+// reusable AMP invoice.
+func (s *Server) ListAMPPayments(ctx context.Context,
+	req *ListAMPPaymentsRequest) (*ListAMPPaymentsResponse, error) {
+
+	hash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.cfg.InvoiceRegistry.LookupInvoice(ctx, hash)
+	switch {
+	case errors.Is(err, invoices.ErrInvoiceNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+
+	case err != nil:
+		return nil, err
+	}
+
+	if !invoice.IsAMP() {
+		return nil, invoices.ErrInvoiceNotAMP
+	}
+
+	payments := make([]*AMPPayment, 0, len(invoice.AMPState))
+	for setID := range invoice.AMPState {
+		payment, err := createRPCAMPPayment(&invoice, setID)
+		if err != nil {
+			return nil, err
+		}
+
+		payments = append(payments, payment)
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		if payments[i].AcceptTime != payments[j].AcceptTime {
+			return payments[i].AcceptTime < payments[j].AcceptTime
+		}
+
+		return bytes.Compare(payments[i].SetId, payments[j].SetId) < 0
+	})
+
+	return &ListAMPPaymentsResponse{
+		Payments: payments,
+	}, nil
+}
+
+// SubscribeAMPPayments streams the payments received against a reusable AMP
+// invoice. The payments received so far are sent first, followed by every
+// payment whose state changes.
+func (s *Server) SubscribeAMPPayments(req *SubscribeAMPPaymentsRequest,
+	updateStream Invoices_SubscribeAMPPaymentsServer) error {
+
+	hash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return err
+	}
+
+	invoiceClient, err := s.cfg.InvoiceRegistry.SubscribeSingleInvoice(
+		updateStream.Context(), hash,
+	)
+	if err != nil {
+		return err
+	}
+	defer invoiceClient.Cancel()
+
+	log.Debugf("Created new AMP payment (pay_hash=%v) subscription", hash)
+
+	// Every invoice update carries the state of all its sub-invoices, so
+	// we keep track of the state we sent for each set ID to only send the
+	// payments that changed.
+	sentStates := make(map[invoices.SetID]invoices.HtlcState)
+	for {
+		select {
+		case invoice := <-invoiceClient.Updates:
+			if !invoice.IsAMP() {
+				return invoices.ErrInvoiceNotAMP
+			}
+
+			for setID, ampState := range invoice.AMPState {
+				state, ok := sentStates[setID]
+				if ok && state == ampState.State {
+					continue
+				}
+
+				payment, err := createRPCAMPPayment(
+					invoice, setID,
+				)
+				if err != nil {
+					return err
+				}
+
+				err = updateStream.Send(payment)
+				if err != nil {
+					return err
+				}
+				sentStates[setID] = ampState.State
+			}
+
+			// Once the invoice reached a terminal state, it doesn't
+			// accept any further payments.
+			if invoice.State.IsFinal() {
+				return nil
+			}
+
+		case <-updateStream.Context().Done():
+			return fmt.Errorf("AMP payment subscription for "+
+				"invoice(pay_hash=%v): %w", hash,
+				updateStream.Context().Err())
+
+		case <-s.quit:
+			return nil
+		}
+	}
+}
+
+// DisableAMPReuse stops a reusable AMP invoice from accepting further
+// payments.
+func (s *Server) DisableAMPReuse(ctx context.Context,
+	req *DisableAMPReuseRequest) (*DisableAMPReuseResponse, error) {
+
+	hash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.cfg.InvoiceRegistry.DisableAMPReuse(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Disabled reuse of AMP invoice %v", hash)
+
+	return &DisableAMPReuseResponse{}, nil
+}
+
+// createRPCAMPPayment converts the sub-invoice of an AMP invoice with the
+// given set ID into an rpc AMP payment.
+func createRPCAMPPayment(invoice *invoices.Invoice,
+	setID invoices.SetID) (*AMPPayment, error) {
+
+	ampState, ok := invoice.AMPState[setID]
+	if !ok {
+		return nil, fmt.Errorf("unknown set id %x", setID[:])
+	}
+
+	var state lnrpc.InvoiceHTLCState
+	switch ampState.State {
+	case invoices.HtlcStateAccepted:
+		state = lnrpc.InvoiceHTLCState_ACCEPTED
+
+	case invoices.HtlcStateSettled:
+		state = lnrpc.InvoiceHTLCState_SETTLED
+
+	case invoices.HtlcStateCanceled:
+		state = lnrpc.InvoiceHTLCState_CANCELED
+
+	default:
+		return nil, fmt.Errorf("unknown state %v", ampState.State)
+	}
+
+	payment := &AMPPayment{
+		SetId:       setID[:],
+		State:       state,
+		AmtPaidMsat: int64(ampState.AmtPaid),
+		NumHtlcs:    uint32(len(ampState.InvoiceKeys)),
+		SettleIndex: ampState.SettleIndex,
+	}
+
+	if ampState.State == invoices.HtlcStateSettled {
+		payment.SettleTime = ampState.SettleDate.Unix()
+	}
+
+	// The accept time of the payment is the one of its first htlc. Htlcs
+	// are only available if they were fetched along with the invoice.
+	var acceptTime time.Time
+	for key := range ampState.InvoiceKeys {
+		htlc, ok := invoice.Htlcs[key]
+		if !ok {
+			continue
+		}
+
+		if acceptTime.IsZero() || htlc.AcceptTime.Before(acceptTime) {
+			acceptTime = htlc.AcceptTime
+		}
+	}
+	if !acceptTime.IsZero() {
+		payment.AcceptTime = acceptTime.Unix()
+	}
+
+	return payment, nil
+}
@@ -168,6 +168,18 @@ type LightningClient interface {
 	// node operators to specify their own criteria for accepting inbound channels
 	// through a single persistent connection.
 	ChannelAcceptor(ctx context.Context, opts ...grpc.CallOption) (Lightning_ChannelAcceptorClient, error)
+	// lncli: `setpeerchannelpolicy`
+	// SetPeerChannelPolicy sets the policy that is applied to inbound channels
+	// opened by a specific peer. This allows accepting zero-conf channels and
+	// requiring scid-alias channels on a per-peer basis rather than globally.
+	// The policy is persisted across restarts. Setting a policy that neither
+	// allows zero-conf nor requires scid-alias channels removes the policy of the
+	// peer.
+	SetPeerChannelPolicy(ctx context.Context, in *SetPeerChannelPolicyRequest, opts ...grpc.CallOption) (*SetPeerChannelPolicyResponse, error)
+	// lncli: `listpeerchannelpolicies`
+	// ListPeerChannelPolicies returns the inbound channel policies of all peers
+	// that have one set.
+	ListPeerChannelPolicies(ctx context.Context, in *ListPeerChannelPoliciesRequest, opts ...grpc.CallOption) (*ListPeerChannelPoliciesResponse, error)
 	// lncli: `closechannel`
 	// CloseChannel attempts to close an active channel identified by its channel
 	// outpoint (ChannelPoint). The actions of this method can additionally be
@@ -177,6 +189,15 @@ type LightningClient interface {
 	// closure transaction is confirmed, or a manual fee rate. If neither are
 	// specified, then a default lax, block confirmation target is used.
 	CloseChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (Lightning_CloseChannelClient, error)
+	// lncli: `batchclosechannel`
+	// BatchCloseChannel attempts to cooperatively close a set of active channels
+	// identified by their channel outpoints. All closures share the same fee
+	// rate and maximum fee rate, and an optional fee budget caps the total fee
+	// of the batch. As the cooperative close protocol negotiates a separate
+	// closing transaction with each peer, every channel is closed by its own
+	// transaction. The progress of all closures is reported on a single stream,
+	// tagged by channel point.
+	BatchCloseChannel(ctx context.Context, in *BatchCloseChannelRequest, opts ...grpc.CallOption) (Lightning_BatchCloseChannelClient, error)
 	// lncli: `abandonchannel`
 	// AbandonChannel removes all channel state from the database except for a
 	// close summary. This method can be used to get rid of permanently unusable
@@ -254,6 +275,13 @@ type LightningClient interface {
 	// DeleteAllPayments deletes all outgoing payments from DB. Note that it will
 	// not attempt to delete In-Flight payments, since that would be unsafe.
 	DeleteAllPayments(ctx context.Context, in *DeleteAllPaymentsRequest, opts ...grpc.CallOption) (*DeleteAllPaymentsResponse, error)
+	// lncli: `prunefailedattempts`
+	// PruneFailedPaymentAttempts deletes the failed HTLC attempts of all
+	// payments that were created before a given age, while the payments
+	// themselves are kept. Payments that are in flight are skipped. If dry_run is
+	// set, nothing is deleted and only the attempts that would be pruned are
+	// reported.
+	PruneFailedPaymentAttempts(ctx context.Context, in *PruneFailedPaymentAttemptsRequest, opts ...grpc.CallOption) (*PruneFailedPaymentAttemptsResponse, error)
 	// lncli: `describegraph`
 	// DescribeGraph returns a description of the latest graph state from the
 	// point of view of the node. The graph information is partitioned into two
@@ -266,6 +294,16 @@ type LightningClient interface {
 	// GetNodeMetrics returns node metrics calculated from the graph. Currently
 	// the only supported metric is betweenness centrality of individual nodes.
 	GetNodeMetrics(ctx context.Context, in *NodeMetricsRequest, opts ...grpc.CallOption) (*NodeMetricsResponse, error)
+	// lncli: `startgraphanalysis`
+	// StartGraphAnalysis starts a background job that computes centrality and
+	// connectivity metrics over the local graph. Depending on the size of the
+	// graph, this may take several minutes. The progress and the results of the
+	// job can be queried with GetGraphAnalysis.
+	StartGraphAnalysis(ctx context.Context, in *StartGraphAnalysisRequest, opts ...grpc.CallOption) (*StartGraphAnalysisResponse, error)
+	// lncli: `getgraphanalysis`
+	// GetGraphAnalysis returns the progress of a graph analysis job and its
+	// results once it is done.
+	GetGraphAnalysis(ctx context.Context, in *GraphAnalysisRequest, opts ...grpc.CallOption) (*GraphAnalysisResponse, error)
 	// lncli: `getchaninfo`
 	// GetChanInfo returns the latest authenticated network announcement for the
 	// given channel identified by its channel ID: an 8-byte integer which
@@ -313,10 +351,52 @@ type LightningClient interface {
 	// FeeReport allows the caller to obtain a report detailing the current fee
 	// schedule enforced by the node globally for each channel.
 	FeeReport(ctx context.Context, in *FeeReportRequest, opts ...grpc.CallOption) (*FeeReportResponse, error)
+	// lncli: `peerfeereport`
+	// PeerFeeReport summarizes the fees that were actually paid to each peer
+	// for forwarding the settled HTLCs of our payments and compares them with
+	// the fees that the channel policies of the peer, including inbound fees,
+	// advertised when the HTLCs were sent. This helps to spot peers whose real
+	// cost diverges from their gossip.
+	PeerFeeReport(ctx context.Context, in *PeerFeeReportRequest, opts ...grpc.CallOption) (*PeerFeeReportResponse, error)
 	// lncli: `updatechanpolicy`
 	// UpdateChannelPolicy allows the caller to update the fee schedule and
 	// channel policies for all channels globally, or a particular channel.
 	UpdateChannelPolicy(ctx context.Context, in *PolicyUpdateRequest, opts ...grpc.CallOption) (*PolicyUpdateResponse, error)
+	// lncli: `schedulechanpolicy`
+	// SchedulePolicyUpdate schedules a channel policy update that is applied at
+	// a given time and optionally repeated at a fixed interval. This allows
+	// defining a time-based fee plan, e.g. different fees at weekends by
+	// scheduling two updates that repeat weekly. Scheduled updates are persisted
+	// across restarts.
+	SchedulePolicyUpdate(ctx context.Context, in *SchedulePolicyUpdateRequest, opts ...grpc.CallOption) (*ScheduledPolicyUpdate, error)
+	// lncli: `listchanpolicyschedule`
+	// ListScheduledPolicyUpdates returns all scheduled channel policy updates,
+	// ordered by the time they are applied next.
+	ListScheduledPolicyUpdates(ctx context.Context, in *ListScheduledPolicyUpdatesRequest, opts ...grpc.CallOption) (*ListScheduledPolicyUpdatesResponse, error)
+	// lncli: `cancelchanpolicyschedule`
+	// CancelScheduledPolicyUpdate removes a scheduled channel policy update.
+	CancelScheduledPolicyUpdate(ctx context.Context, in *CancelScheduledPolicyUpdateRequest, opts ...grpc.CallOption) (*CancelScheduledPolicyUpdateResponse, error)
+	// lncli: `updatecommitfeepolicy`
+	// UpdateCommitFeePolicy allows the caller to update the policy that governs
+	// the commitment fee rate for all channels globally, or a particular channel.
+	// The policy only takes effect for channels that we initiated, as only the
+	// initiator pays the commitment fee. The new policy is applied with the next
+	// commitment fee update.
+	UpdateCommitFeePolicy(ctx context.Context, in *CommitFeePolicyUpdateRequest, opts ...grpc.CallOption) (*CommitFeePolicyUpdateResponse, error)
+	// lncli: `setchansweepbudget`
+	// SetChannelSweepBudget sets the budget that limits the fees spent on
+	// sweeping the outputs of a particular channel after it has been force
+	// closed, including the CPFP of the commitment transaction and the sweeps of
+	// its HTLCs. Non-zero values take precedence over the global sweeper.budget
+	// config, while zero values fall back to it. An empty budget removes the
+	// channel's budget. The budget also applies to channels that are already
+	// being resolved, for all outputs that are offered to the sweeper from then
+	// on.
+	SetChannelSweepBudget(ctx context.Context, in *SetChannelSweepBudgetRequest, opts ...grpc.CallOption) (*SetChannelSweepBudgetResponse, error)
+	// lncli: `listchansweepbudgets`
+	// ListChannelSweepBudgets returns the budgets of all channels that have a
+	// per-channel sweep budget.
+	ListChannelSweepBudgets(ctx context.Context, in *ListChannelSweepBudgetsRequest, opts ...grpc.CallOption) (*ListChannelSweepBudgetsResponse, error)
 	// lncli: `fwdinghistory`
 	// ForwardingHistory allows the caller to query the htlcswitch for a record of
 	// all HTLCs forwarded within the target time range, and integer offset
@@ -783,6 +863,24 @@ func (x *lightningChannelAcceptorClient) Recv() (*ChannelAcceptRequest, error) {
 	return m, nil
 }
 
+func (c *lightningClient) SetPeerChannelPolicy(ctx context.Context, in *SetPeerChannelPolicyRequest, opts ...grpc.CallOption) (*SetPeerChannelPolicyResponse, error) {
+	out := new(SetPeerChannelPolicyResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/SetPeerChannelPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ListPeerChannelPolicies(ctx context.Context, in *ListPeerChannelPoliciesRequest, opts ...grpc.CallOption) (*ListPeerChannelPoliciesResponse, error) {
+	out := new(ListPeerChannelPoliciesResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ListPeerChannelPolicies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) CloseChannel(ctx context.Context, in *CloseChannelRequest, opts ...grpc.CallOption) (Lightning_CloseChannelClient, error) {
 	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[5], "/lnrpc.Lightning/CloseChannel", opts...)
 	if err != nil {
@@ -815,6 +913,38 @@ func (x *lightningCloseChannelClient) Recv() (*CloseStatusUpdate, error) {
 	return m, nil
 }
 
+func (c *lightningClient) BatchCloseChannel(ctx context.Context, in *BatchCloseChannelRequest, opts ...grpc.CallOption) (Lightning_BatchCloseChannelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[6], "/lnrpc.Lightning/BatchCloseChannel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lightningBatchCloseChannelClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Lightning_BatchCloseChannelClient interface {
+	Recv() (*BatchCloseStatusUpdate, error)
+	grpc.ClientStream
+}
+
+type lightningBatchCloseChannelClient struct {
+	grpc.ClientStream
+}
+
+func (x *lightningBatchCloseChannelClient) Recv() (*BatchCloseStatusUpdate, error) {
+	m := new(BatchCloseStatusUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *lightningClient) AbandonChannel(ctx context.Context, in *AbandonChannelRequest, opts ...grpc.CallOption) (*AbandonChannelResponse, error) {
 	out := new(AbandonChannelResponse)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/AbandonChannel", in, out, opts...)
@@ -826,7 +956,7 @@ func (c *lightningClient) AbandonChannel(ctx context.Context, in *AbandonChannel
 
 // Deprecated: Do not use.
 func (c *lightningClient) SendPayment(ctx context.Context, opts ...grpc.CallOption) (Lightning_SendPaymentClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[6], "/lnrpc.Lightning/SendPayment", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[7], "/lnrpc.Lightning/SendPayment", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -867,7 +997,7 @@ func (c *lightningClient) SendPaymentSync(ctx context.Context, in *SendRequest,
 
 // Deprecated: Do not use.
 func (c *lightningClient) SendToRoute(ctx context.Context, opts ...grpc.CallOption) (Lightning_SendToRouteClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[7], "/lnrpc.Lightning/SendToRoute", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[8], "/lnrpc.Lightning/SendToRoute", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -934,7 +1064,7 @@ func (c *lightningClient) LookupInvoice(ctx context.Context, in *PaymentHash, op
 }
 
 func (c *lightningClient) SubscribeInvoices(ctx context.Context, in *InvoiceSubscription, opts ...grpc.CallOption) (Lightning_SubscribeInvoicesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[8], "/lnrpc.Lightning/SubscribeInvoices", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[9], "/lnrpc.Lightning/SubscribeInvoices", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1001,6 +1131,15 @@ func (c *lightningClient) DeleteAllPayments(ctx context.Context, in *DeleteAllPa
 	return out, nil
 }
 
+func (c *lightningClient) PruneFailedPaymentAttempts(ctx context.Context, in *PruneFailedPaymentAttemptsRequest, opts ...grpc.CallOption) (*PruneFailedPaymentAttemptsResponse, error) {
+	out := new(PruneFailedPaymentAttemptsResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/PruneFailedPaymentAttempts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) DescribeGraph(ctx context.Context, in *ChannelGraphRequest, opts ...grpc.CallOption) (*ChannelGraph, error) {
 	out := new(ChannelGraph)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/DescribeGraph", in, out, opts...)
@@ -1019,6 +1158,24 @@ func (c *lightningClient) GetNodeMetrics(ctx context.Context, in *NodeMetricsReq
 	return out, nil
 }
 
+func (c *lightningClient) StartGraphAnalysis(ctx context.Context, in *StartGraphAnalysisRequest, opts ...grpc.CallOption) (*StartGraphAnalysisResponse, error) {
+	out := new(StartGraphAnalysisResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/StartGraphAnalysis", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) GetGraphAnalysis(ctx context.Context, in *GraphAnalysisRequest, opts ...grpc.CallOption) (*GraphAnalysisResponse, error) {
+	out := new(GraphAnalysisResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/GetGraphAnalysis", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) GetChanInfo(ctx context.Context, in *ChanInfoRequest, opts ...grpc.CallOption) (*ChannelEdge, error) {
 	out := new(ChannelEdge)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/GetChanInfo", in, out, opts...)
@@ -1065,7 +1222,7 @@ func (c *lightningClient) StopDaemon(ctx context.Context, in *StopRequest, opts
 }
 
 func (c *lightningClient) SubscribeChannelGraph(ctx context.Context, in *GraphTopologySubscription, opts ...grpc.CallOption) (Lightning_SubscribeChannelGraphClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[9], "/lnrpc.Lightning/SubscribeChannelGraph", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[10], "/lnrpc.Lightning/SubscribeChannelGraph", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1114,6 +1271,15 @@ func (c *lightningClient) FeeReport(ctx context.Context, in *FeeReportRequest, o
 	return out, nil
 }
 
+func (c *lightningClient) PeerFeeReport(ctx context.Context, in *PeerFeeReportRequest, opts ...grpc.CallOption) (*PeerFeeReportResponse, error) {
+	out := new(PeerFeeReportResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/PeerFeeReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) UpdateChannelPolicy(ctx context.Context, in *PolicyUpdateRequest, opts ...grpc.CallOption) (*PolicyUpdateResponse, error) {
 	out := new(PolicyUpdateResponse)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/UpdateChannelPolicy", in, out, opts...)
@@ -1123,6 +1289,60 @@ func (c *lightningClient) UpdateChannelPolicy(ctx context.Context, in *PolicyUpd
 	return out, nil
 }
 
+func (c *lightningClient) SchedulePolicyUpdate(ctx context.Context, in *SchedulePolicyUpdateRequest, opts ...grpc.CallOption) (*ScheduledPolicyUpdate, error) {
+	out := new(ScheduledPolicyUpdate)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/SchedulePolicyUpdate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ListScheduledPolicyUpdates(ctx context.Context, in *ListScheduledPolicyUpdatesRequest, opts ...grpc.CallOption) (*ListScheduledPolicyUpdatesResponse, error) {
+	out := new(ListScheduledPolicyUpdatesResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ListScheduledPolicyUpdates", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) CancelScheduledPolicyUpdate(ctx context.Context, in *CancelScheduledPolicyUpdateRequest, opts ...grpc.CallOption) (*CancelScheduledPolicyUpdateResponse, error) {
+	out := new(CancelScheduledPolicyUpdateResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/CancelScheduledPolicyUpdate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) UpdateCommitFeePolicy(ctx context.Context, in *CommitFeePolicyUpdateRequest, opts ...grpc.CallOption) (*CommitFeePolicyUpdateResponse, error) {
+	out := new(CommitFeePolicyUpdateResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/UpdateCommitFeePolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) SetChannelSweepBudget(ctx context.Context, in *SetChannelSweepBudgetRequest, opts ...grpc.CallOption) (*SetChannelSweepBudgetResponse, error) {
+	out := new(SetChannelSweepBudgetResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/SetChannelSweepBudget", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ListChannelSweepBudgets(ctx context.Context, in *ListChannelSweepBudgetsRequest, opts ...grpc.CallOption) (*ListChannelSweepBudgetsResponse, error) {
+	out := new(ListChannelSweepBudgetsResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ListChannelSweepBudgets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) ForwardingHistory(ctx context.Context, in *ForwardingHistoryRequest, opts ...grpc.CallOption) (*ForwardingHistoryResponse, error) {
 	out := new(ForwardingHistoryResponse)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ForwardingHistory", in, out, opts...)
@@ -1169,7 +1389,7 @@ func (c *lightningClient) RestoreChannelBackups(ctx context.Context, in *Restore
 }
 
 func (c *lightningClient) SubscribeChannelBackups(ctx context.Context, in *ChannelBackupSubscription, opts ...grpc.CallOption) (Lightning_SubscribeChannelBackupsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[10], "/lnrpc.Lightning/SubscribeChannelBackups", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[11], "/lnrpc.Lightning/SubscribeChannelBackups", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1246,7 +1466,7 @@ func (c *lightningClient) CheckMacaroonPermissions(ctx context.Context, in *Chec
 }
 
 func (c *lightningClient) RegisterRPCMiddleware(ctx context.Context, opts ...grpc.CallOption) (Lightning_RegisterRPCMiddlewareClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[11], "/lnrpc.Lightning/RegisterRPCMiddleware", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[12], "/lnrpc.Lightning/RegisterRPCMiddleware", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1286,7 +1506,7 @@ func (c *lightningClient) SendCustomMessage(ctx context.Context, in *SendCustomM
 }
 
 func (c *lightningClient) SubscribeCustomMessages(ctx context.Context, in *SubscribeCustomMessagesRequest, opts ...grpc.CallOption) (Lightning_SubscribeCustomMessagesClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[12], "/lnrpc.Lightning/SubscribeCustomMessages", opts...)
+	stream, err := c.cc.NewStream(ctx, &Lightning_ServiceDesc.Streams[13], "/lnrpc.Lightning/SubscribeCustomMessages", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1489,6 +1709,18 @@ type LightningServer interface {
 	// node operators to specify their own criteria for accepting inbound channels
 	// through a single persistent connection.
 	ChannelAcceptor(Lightning_ChannelAcceptorServer) error
+	// lncli: `setpeerchannelpolicy`
+	// SetPeerChannelPolicy sets the policy that is applied to inbound channels
+	// opened by a specific peer. This allows accepting zero-conf channels and
+	// requiring scid-alias channels on a per-peer basis rather than globally.
+	// The policy is persisted across restarts. Setting a policy that neither
+	// allows zero-conf nor requires scid-alias channels removes the policy of the
+	// peer.
+	SetPeerChannelPolicy(context.Context, *SetPeerChannelPolicyRequest) (*SetPeerChannelPolicyResponse, error)
+	// lncli: `listpeerchannelpolicies`
+	// ListPeerChannelPolicies returns the inbound channel policies of all peers
+	// that have one set.
+	ListPeerChannelPolicies(context.Context, *ListPeerChannelPoliciesRequest) (*ListPeerChannelPoliciesResponse, error)
 	// lncli: `closechannel`
 	// CloseChannel attempts to close an active channel identified by its channel
 	// outpoint (ChannelPoint). The actions of this method can additionally be
@@ -1498,6 +1730,15 @@ type LightningServer interface {
 	// closure transaction is confirmed, or a manual fee rate. If neither are
 	// specified, then a default lax, block confirmation target is used.
 	CloseChannel(*CloseChannelRequest, Lightning_CloseChannelServer) error
+	// lncli: `batchclosechannel`
+	// BatchCloseChannel attempts to cooperatively close a set of active channels
+	// identified by their channel outpoints. All closures share the same fee
+	// rate and maximum fee rate, and an optional fee budget caps the total fee
+	// of the batch. As the cooperative close protocol negotiates a separate
+	// closing transaction with each peer, every channel is closed by its own
+	// transaction. The progress of all closures is reported on a single stream,
+	// tagged by channel point.
+	BatchCloseChannel(*BatchCloseChannelRequest, Lightning_BatchCloseChannelServer) error
 	// lncli: `abandonchannel`
 	// AbandonChannel removes all channel state from the database except for a
 	// close summary. This method can be used to get rid of permanently unusable
@@ -1575,6 +1816,13 @@ type LightningServer interface {
 	// DeleteAllPayments deletes all outgoing payments from DB. Note that it will
 	// not attempt to delete In-Flight payments, since that would be unsafe.
 	DeleteAllPayments(context.Context, *DeleteAllPaymentsRequest) (*DeleteAllPaymentsResponse, error)
+	// lncli: `prunefailedattempts`
+	// PruneFailedPaymentAttempts deletes the failed HTLC attempts of all
+	// payments that were created before a given age, while the payments
+	// themselves are kept. Payments that are in flight are skipped. If dry_run is
+	// set, nothing is deleted and only the attempts that would be pruned are
+	// reported.
+	PruneFailedPaymentAttempts(context.Context, *PruneFailedPaymentAttemptsRequest) (*PruneFailedPaymentAttemptsResponse, error)
 	// lncli: `describegraph`
 	// DescribeGraph returns a description of the latest graph state from the
 	// point of view of the node. The graph information is partitioned into two
@@ -1587,6 +1835,16 @@ type LightningServer interface {
 	// GetNodeMetrics returns node metrics calculated from the graph. Currently
 	// the only supported metric is betweenness centrality of individual nodes.
 	GetNodeMetrics(context.Context, *NodeMetricsRequest) (*NodeMetricsResponse, error)
+	// lncli: `startgraphanalysis`
+	// StartGraphAnalysis starts a background job that computes centrality and
+	// connectivity metrics over the local graph. Depending on the size of the
+	// graph, this may take several minutes. The progress and the results of the
+	// job can be queried with GetGraphAnalysis.
+	StartGraphAnalysis(context.Context, *StartGraphAnalysisRequest) (*StartGraphAnalysisResponse, error)
+	// lncli: `getgraphanalysis`
+	// GetGraphAnalysis returns the progress of a graph analysis job and its
+	// results once it is done.
+	GetGraphAnalysis(context.Context, *GraphAnalysisRequest) (*GraphAnalysisResponse, error)
 	// lncli: `getchaninfo`
 	// GetChanInfo returns the latest authenticated network announcement for the
 	// given channel identified by its channel ID: an 8-byte integer which
@@ -1634,10 +1892,52 @@ type LightningServer interface {
 	// FeeReport allows the caller to obtain a report detailing the current fee
 	// schedule enforced by the node globally for each channel.
 	FeeReport(context.Context, *FeeReportRequest) (*FeeReportResponse, error)
+	// lncli: `peerfeereport`
+	// PeerFeeReport summarizes the fees that were actually paid to each peer
+	// for forwarding the settled HTLCs of our payments and compares them with
+	// the fees that the channel policies of the peer, including inbound fees,
+	// advertised when the HTLCs were sent. This helps to spot peers whose real
+	// cost diverges from their gossip.
+	PeerFeeReport(context.Context, *PeerFeeReportRequest) (*PeerFeeReportResponse, error)
 	// lncli: `updatechanpolicy`
 	// UpdateChannelPolicy allows the caller to update the fee schedule and
 	// channel policies for all channels globally, or a particular channel.
 	UpdateChannelPolicy(context.Context, *PolicyUpdateRequest) (*PolicyUpdateResponse, error)
+	// lncli: `schedulechanpolicy`
+	// SchedulePolicyUpdate schedules a channel policy update that is applied at
+	// a given time and optionally repeated at a fixed interval. This allows
+	// defining a time-based fee plan, e.g. different fees at weekends by
+	// scheduling two updates that repeat weekly. Scheduled updates are persisted
+	// across restarts.
+	SchedulePolicyUpdate(context.Context, *SchedulePolicyUpdateRequest) (*ScheduledPolicyUpdate, error)
+	// lncli: `listchanpolicyschedule`
+	// ListScheduledPolicyUpdates returns all scheduled channel policy updates,
+	// ordered by the time they are applied next.
+	ListScheduledPolicyUpdates(context.Context, *ListScheduledPolicyUpdatesRequest) (*ListScheduledPolicyUpdatesResponse, error)
+	// lncli: `cancelchanpolicyschedule`
+	// CancelScheduledPolicyUpdate removes a scheduled channel policy update.
+	CancelScheduledPolicyUpdate(context.Context, *CancelScheduledPolicyUpdateRequest) (*CancelScheduledPolicyUpdateResponse, error)
+	// lncli: `updatecommitfeepolicy`
+	// UpdateCommitFeePolicy allows the caller to update the policy that governs
+	// the commitment fee rate for all channels globally, or a particular channel.
+	// The policy only takes effect for channels that we initiated, as only the
+	// initiator pays the commitment fee. The new policy is applied with the next
+	// commitment fee update.
+	UpdateCommitFeePolicy(context.Context, *CommitFeePolicyUpdateRequest) (*CommitFeePolicyUpdateResponse, error)
+	// lncli: `setchansweepbudget`
+	// SetChannelSweepBudget sets the budget that limits the fees spent on
+	// sweeping the outputs of a particular channel after it has been force
+	// closed, including the CPFP of the commitment transaction and the sweeps of
+	// its HTLCs. Non-zero values take precedence over the global sweeper.budget
+	// config, while zero values fall back to it. An empty budget removes the
+	// channel's budget. The budget also applies to channels that are already
+	// being resolved, for all outputs that are offered to the sweeper from then
+	// on.
+	SetChannelSweepBudget(context.Context, *SetChannelSweepBudgetRequest) (*SetChannelSweepBudgetResponse, error)
+	// lncli: `listchansweepbudgets`
+	// ListChannelSweepBudgets returns the budgets of all channels that have a
+	// per-channel sweep budget.
+	ListChannelSweepBudgets(context.Context, *ListChannelSweepBudgetsRequest) (*ListChannelSweepBudgetsResponse, error)
 	// lncli: `fwdinghistory`
 	// ForwardingHistory allows the caller to query the htlcswitch for a record of
 	// all HTLCs forwarded within the target time range, and integer offset
@@ -1825,9 +2125,18 @@ func (UnimplementedLightningServer) FundingStateStep(context.Context, *FundingTr
 func (UnimplementedLightningServer) ChannelAcceptor(Lightning_ChannelAcceptorServer) error {
 	return status.Errorf(codes.Unimplemented, "method ChannelAcceptor not implemented")
 }
+func (UnimplementedLightningServer) SetPeerChannelPolicy(context.Context, *SetPeerChannelPolicyRequest) (*SetPeerChannelPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPeerChannelPolicy not implemented")
+}
+func (UnimplementedLightningServer) ListPeerChannelPolicies(context.Context, *ListPeerChannelPoliciesRequest) (*ListPeerChannelPoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPeerChannelPolicies not implemented")
+}
 func (UnimplementedLightningServer) CloseChannel(*CloseChannelRequest, Lightning_CloseChannelServer) error {
 	return status.Errorf(codes.Unimplemented, "method CloseChannel not implemented")
 }
+func (UnimplementedLightningServer) BatchCloseChannel(*BatchCloseChannelRequest, Lightning_BatchCloseChannelServer) error {
+	return status.Errorf(codes.Unimplemented, "method BatchCloseChannel not implemented")
+}
 func (UnimplementedLightningServer) AbandonChannel(context.Context, *AbandonChannelRequest) (*AbandonChannelResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AbandonChannel not implemented")
 }
@@ -1867,12 +2176,21 @@ func (UnimplementedLightningServer) DeletePayment(context.Context, *DeletePaymen
 func (UnimplementedLightningServer) DeleteAllPayments(context.Context, *DeleteAllPaymentsRequest) (*DeleteAllPaymentsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteAllPayments not implemented")
 }
+func (UnimplementedLightningServer) PruneFailedPaymentAttempts(context.Context, *PruneFailedPaymentAttemptsRequest) (*PruneFailedPaymentAttemptsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PruneFailedPaymentAttempts not implemented")
+}
 func (UnimplementedLightningServer) DescribeGraph(context.Context, *ChannelGraphRequest) (*ChannelGraph, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DescribeGraph not implemented")
 }
 func (UnimplementedLightningServer) GetNodeMetrics(context.Context, *NodeMetricsRequest) (*NodeMetricsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetNodeMetrics not implemented")
 }
+func (UnimplementedLightningServer) StartGraphAnalysis(context.Context, *StartGraphAnalysisRequest) (*StartGraphAnalysisResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartGraphAnalysis not implemented")
+}
+func (UnimplementedLightningServer) GetGraphAnalysis(context.Context, *GraphAnalysisRequest) (*GraphAnalysisResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGraphAnalysis not implemented")
+}
 func (UnimplementedLightningServer) GetChanInfo(context.Context, *ChanInfoRequest) (*ChannelEdge, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChanInfo not implemented")
 }
@@ -1897,9 +2215,30 @@ func (UnimplementedLightningServer) DebugLevel(context.Context, *DebugLevelReque
 func (UnimplementedLightningServer) FeeReport(context.Context, *FeeReportRequest) (*FeeReportResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FeeReport not implemented")
 }
+func (UnimplementedLightningServer) PeerFeeReport(context.Context, *PeerFeeReportRequest) (*PeerFeeReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PeerFeeReport not implemented")
+}
 func (UnimplementedLightningServer) UpdateChannelPolicy(context.Context, *PolicyUpdateRequest) (*PolicyUpdateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateChannelPolicy not implemented")
 }
+func (UnimplementedLightningServer) SchedulePolicyUpdate(context.Context, *SchedulePolicyUpdateRequest) (*ScheduledPolicyUpdate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SchedulePolicyUpdate not implemented")
+}
+func (UnimplementedLightningServer) ListScheduledPolicyUpdates(context.Context, *ListScheduledPolicyUpdatesRequest) (*ListScheduledPolicyUpdatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScheduledPolicyUpdates not implemented")
+}
+func (UnimplementedLightningServer) CancelScheduledPolicyUpdate(context.Context, *CancelScheduledPolicyUpdateRequest) (*CancelScheduledPolicyUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelScheduledPolicyUpdate not implemented")
+}
+func (UnimplementedLightningServer) UpdateCommitFeePolicy(context.Context, *CommitFeePolicyUpdateRequest) (*CommitFeePolicyUpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCommitFeePolicy not implemented")
+}
+func (UnimplementedLightningServer) SetChannelSweepBudget(context.Context, *SetChannelSweepBudgetRequest) (*SetChannelSweepBudgetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetChannelSweepBudget not implemented")
+}
+func (UnimplementedLightningServer) ListChannelSweepBudgets(context.Context, *ListChannelSweepBudgetsRequest) (*ListChannelSweepBudgetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChannelSweepBudgets not implemented")
+}
 func (UnimplementedLightningServer) ForwardingHistory(context.Context, *ForwardingHistoryRequest) (*ForwardingHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ForwardingHistory not implemented")
 }
@@ -2467,6 +2806,42 @@ func (x *lightningChannelAcceptorServer) Recv() (*ChannelAcceptResponse, error)
 	return m, nil
 }
 
+func _Lightning_SetPeerChannelPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPeerChannelPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).SetPeerChannelPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/SetPeerChannelPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).SetPeerChannelPolicy(ctx, req.(*SetPeerChannelPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ListPeerChannelPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPeerChannelPoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ListPeerChannelPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ListPeerChannelPolicies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ListPeerChannelPolicies(ctx, req.(*ListPeerChannelPoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_CloseChannel_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(CloseChannelRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -2488,6 +2863,27 @@ func (x *lightningCloseChannelServer) Send(m *CloseStatusUpdate) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Lightning_BatchCloseChannel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchCloseChannelRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LightningServer).BatchCloseChannel(m, &lightningBatchCloseChannelServer{stream})
+}
+
+type Lightning_BatchCloseChannelServer interface {
+	Send(*BatchCloseStatusUpdate) error
+	grpc.ServerStream
+}
+
+type lightningBatchCloseChannelServer struct {
+	grpc.ServerStream
+}
+
+func (x *lightningBatchCloseChannelServer) Send(m *BatchCloseStatusUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Lightning_AbandonChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AbandonChannelRequest)
 	if err := dec(in); err != nil {
@@ -2741,6 +3137,24 @@ func _Lightning_DeleteAllPayments_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_PruneFailedPaymentAttempts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneFailedPaymentAttemptsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).PruneFailedPaymentAttempts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/PruneFailedPaymentAttempts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).PruneFailedPaymentAttempts(ctx, req.(*PruneFailedPaymentAttemptsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_DescribeGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ChannelGraphRequest)
 	if err := dec(in); err != nil {
@@ -2777,6 +3191,42 @@ func _Lightning_GetNodeMetrics_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_StartGraphAnalysis_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartGraphAnalysisRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).StartGraphAnalysis(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/StartGraphAnalysis",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).StartGraphAnalysis(ctx, req.(*StartGraphAnalysisRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_GetGraphAnalysis_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GraphAnalysisRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).GetGraphAnalysis(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/GetGraphAnalysis",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).GetGraphAnalysis(ctx, req.(*GraphAnalysisRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_GetChanInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ChanInfoRequest)
 	if err := dec(in); err != nil {
@@ -2924,6 +3374,24 @@ func _Lightning_FeeReport_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_PeerFeeReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PeerFeeReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).PeerFeeReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/PeerFeeReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).PeerFeeReport(ctx, req.(*PeerFeeReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_UpdateChannelPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PolicyUpdateRequest)
 	if err := dec(in); err != nil {
@@ -2942,6 +3410,114 @@ func _Lightning_UpdateChannelPolicy_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_SchedulePolicyUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SchedulePolicyUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).SchedulePolicyUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/SchedulePolicyUpdate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).SchedulePolicyUpdate(ctx, req.(*SchedulePolicyUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ListScheduledPolicyUpdates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduledPolicyUpdatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ListScheduledPolicyUpdates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ListScheduledPolicyUpdates",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ListScheduledPolicyUpdates(ctx, req.(*ListScheduledPolicyUpdatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_CancelScheduledPolicyUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledPolicyUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).CancelScheduledPolicyUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/CancelScheduledPolicyUpdate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).CancelScheduledPolicyUpdate(ctx, req.(*CancelScheduledPolicyUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_UpdateCommitFeePolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitFeePolicyUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).UpdateCommitFeePolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/UpdateCommitFeePolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).UpdateCommitFeePolicy(ctx, req.(*CommitFeePolicyUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_SetChannelSweepBudget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetChannelSweepBudgetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).SetChannelSweepBudget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/SetChannelSweepBudget",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).SetChannelSweepBudget(ctx, req.(*SetChannelSweepBudgetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ListChannelSweepBudgets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChannelSweepBudgetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ListChannelSweepBudgets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ListChannelSweepBudgets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ListChannelSweepBudgets(ctx, req.(*ListChannelSweepBudgetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_ForwardingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ForwardingHistoryRequest)
 	if err := dec(in); err != nil {
@@ -3339,6 +3915,14 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FundingStateStep",
 			Handler:    _Lightning_FundingStateStep_Handler,
 		},
+		{
+			MethodName: "SetPeerChannelPolicy",
+			Handler:    _Lightning_SetPeerChannelPolicy_Handler,
+		},
+		{
+			MethodName: "ListPeerChannelPolicies",
+			Handler:    _Lightning_ListPeerChannelPolicies_Handler,
+		},
 		{
 			MethodName: "AbandonChannel",
 			Handler:    _Lightning_AbandonChannel_Handler,
@@ -3379,6 +3963,10 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAllPayments",
 			Handler:    _Lightning_DeleteAllPayments_Handler,
 		},
+		{
+			MethodName: "PruneFailedPaymentAttempts",
+			Handler:    _Lightning_PruneFailedPaymentAttempts_Handler,
+		},
 		{
 			MethodName: "DescribeGraph",
 			Handler:    _Lightning_DescribeGraph_Handler,
@@ -3387,6 +3975,14 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetNodeMetrics",
 			Handler:    _Lightning_GetNodeMetrics_Handler,
 		},
+		{
+			MethodName: "StartGraphAnalysis",
+			Handler:    _Lightning_StartGraphAnalysis_Handler,
+		},
+		{
+			MethodName: "GetGraphAnalysis",
+			Handler:    _Lightning_GetGraphAnalysis_Handler,
+		},
 		{
 			MethodName: "GetChanInfo",
 			Handler:    _Lightning_GetChanInfo_Handler,
@@ -3415,10 +4011,38 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FeeReport",
 			Handler:    _Lightning_FeeReport_Handler,
 		},
+		{
+			MethodName: "PeerFeeReport",
+			Handler:    _Lightning_PeerFeeReport_Handler,
+		},
 		{
 			MethodName: "UpdateChannelPolicy",
 			Handler:    _Lightning_UpdateChannelPolicy_Handler,
 		},
+		{
+			MethodName: "SchedulePolicyUpdate",
+			Handler:    _Lightning_SchedulePolicyUpdate_Handler,
+		},
+		{
+			MethodName: "ListScheduledPolicyUpdates",
+			Handler:    _Lightning_ListScheduledPolicyUpdates_Handler,
+		},
+		{
+			MethodName: "CancelScheduledPolicyUpdate",
+			Handler:    _Lightning_CancelScheduledPolicyUpdate_Handler,
+		},
+		{
+			MethodName: "UpdateCommitFeePolicy",
+			Handler:    _Lightning_UpdateCommitFeePolicy_Handler,
+		},
+		{
+			MethodName: "SetChannelSweepBudget",
+			Handler:    _Lightning_SetChannelSweepBudget_Handler,
+		},
+		{
+			MethodName: "ListChannelSweepBudgets",
+			Handler:    _Lightning_ListChannelSweepBudgets_Handler,
+		},
 		{
 			MethodName: "ForwardingHistory",
 			Handler:    _Lightning_ForwardingHistory_Handler,
@@ -3504,6 +4128,11 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Lightning_CloseChannel_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "BatchCloseChannel",
+			Handler:       _Lightning_BatchCloseChannel_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "SendPayment",
 			Handler:       _Lightning_SendPayment_Handler,
@@ -0,0 +1,297 @@
+package routerrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBatchPayments is the maximum number of payments of a single batch.
+const MaxBatchPayments = 1000
+
+var (
+	// errBatchTimeout is reported for payments of a batch that couldn't
+	// be started before the timeout of the batch expired.
+	errBatchTimeout = errors.New("batch timeout expired before the " +
+		"payment was started")
+
+	// errBatchCanceled is reported for payments of a batch whose result
+	// is unknown because the batch was canceled by the client.
+	errBatchCanceled = errors.New("batch canceled")
+)
+
+// batchBudget is the fee budget that is shared by the payments of a batch.
+// Every payment reserves a share of the unreserved budget that is
+// proportional to its amount when it is started. Once the payment is
+// finished, the part of its share that wasn't spent is returned to the
+// budget, so that it is available to the payments that are started later.
+type batchBudget struct {
+	// unreserved is the part of the budget that isn't reserved by a
+	// running payment.
+	unreserved lnwire.MilliSatoshi
+
+	// unstartedAmt is the total amount of the payments that weren't
+	// started yet.
+	unstartedAmt lnwire.MilliSatoshi
+
+	mu sync.Mutex
+}
+
+// newBatchBudget returns a budget of the given size that is shared by
+// payments of the given total amount.
+func newBatchBudget(budget,
+	totalAmt lnwire.MilliSatoshi) *batchBudget {
+
+	return &batchBudget{
+		unreserved:   budget,
+		unstartedAmt: totalAmt,
+	}
+}
+
+// reserve reserves the share of the unreserved budget for a payment of the
+// given amount that is about to be started.
+func (b *batchBudget) reserve(amt lnwire.MilliSatoshi) lnwire.MilliSatoshi {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	share := b.unreserved
+	if amt < b.unstartedAmt {
+		// We calculate in floating point to not overflow for large
+		// budgets and amounts.
+		share = lnwire.MilliSatoshi(
+			float64(b.unreserved) * float64(amt) /
+				float64(b.unstartedAmt),
+		)
+	}
+
+	b.unreserved -= share
+	b.unstartedAmt -= min(amt, b.unstartedAmt)
+
+	return share
+}
+
+// release returns the part of a reserved share that wasn't spent to the
+// budget.
+func (b *batchBudget) release(reserved, spent lnwire.MilliSatoshi) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if spent < reserved {
+		b.unreserved += reserved - spent
+	}
+}
+
+// SendPaymentBatch pays multiple invoices with a fee budget and a timeout
+// that are shared by all payments of the batch. At most the given number of
+// payments are in flight at the same time. The final result of every payment
+// is sent on the stream as soon as it is known.
+func (s *Server) SendPaymentBatch(req *SendPaymentBatchRequest,
+	stream Router_SendPaymentBatchServer) error {
+
+	switch {
+	case len(req.Payments) == 0:
+		return status.Error(codes.InvalidArgument, "no payments given")
+
+	case len(req.Payments) > MaxBatchPayments:
+		return status.Errorf(codes.InvalidArgument, "number of "+
+			"payments %v exceeds maximum of %v",
+			len(req.Payments), MaxBatchPayments)
+
+	case req.TimeoutSeconds <= 0:
+		return status.Error(codes.InvalidArgument, "timeout_seconds "+
+			"must be positive")
+
+	case req.FeeLimitMsat < 0:
+		return status.Error(codes.InvalidArgument, "fee_limit_msat "+
+			"must not be negative")
+	}
+
+	// Validate all payments before the first one is started, so that a
+	// batch is either started as a whole or not at all.
+	var (
+		intents  = make([]*routing.LightningPayment, len(req.Payments))
+		totalAmt lnwire.MilliSatoshi
+	)
+	for i, p := range req.Payments {
+		intent, err := s.cfg.RouterBackend.extractIntentFromSendRequest(
+			&SendPaymentRequest{
+				PaymentRequest: p.PaymentRequest,
+				AmtMsat:        p.AmtMsat,
+				TimeoutSeconds: req.TimeoutSeconds,
+			},
+		)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument,
+				"payment %v: %v", i, err)
+		}
+
+		intents[i] = intent
+		totalAmt += intent.Amount
+	}
+
+	maxParallel := int(req.MaxParallelPayments)
+	if maxParallel == 0 {
+		maxParallel = 1
+	}
+
+	var (
+		budget = newBatchBudget(
+			lnwire.MilliSatoshi(req.FeeLimitMsat), totalAmt,
+		)
+		deadline = time.Now().Add(
+			time.Duration(req.TimeoutSeconds) * time.Second,
+		)
+		ctx = stream.Context()
+		sem = make(chan struct{}, maxParallel)
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+	)
+
+	// send sends the result of a payment to the client. Results are sent
+	// from several goroutines, so they are serialized.
+	send := func(idx int, payment *lnrpc.Payment, err error) error {
+		result := &BatchPaymentResult{
+			Index:   uint32(idx),
+			Payment: payment,
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		return stream.Send(result)
+	}
+
+	timeout := time.NewTimer(time.Until(deadline))
+	defer timeout.Stop()
+
+	var sendErr error
+	for i, intent := range intents {
+		// Once the batch is canceled or timed out, the remaining
+		// payments are reported as not started.
+		var notStarted error
+		select {
+		case sem <- struct{}{}:
+
+		case <-timeout.C:
+			notStarted = errBatchTimeout
+
+		case <-ctx.Done():
+			notStarted = errBatchCanceled
+
+		case <-s.quit:
+			notStarted = errServerShuttingDown
+		}
+
+		if notStarted == nil && !time.Now().Before(deadline) {
+			<-sem
+			notStarted = errBatchTimeout
+		}
+
+		if notStarted != nil {
+			for j := i; j < len(intents); j++ {
+				if err := send(j, nil, notStarted); err != nil {
+					sendErr = err
+					break
+				}
+			}
+
+			break
+		}
+
+		// The payment may use the time that is left of the batch
+		// timeout and its share of the budget.
+		intent.PayAttemptTimeout = time.Until(deadline)
+		intent.FeeLimit = budget.reserve(intent.Amount)
+
+		wg.Add(1)
+		go func(idx int, intent *routing.LightningPayment) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			payment, err := s.sendBatchPayment(ctx, intent)
+
+			var spent lnwire.MilliSatoshi
+			if payment != nil {
+				_, spent = payment.SentAmt()
+			}
+			budget.release(intent.FeeLimit, spent)
+
+			var rpcPayment *lnrpc.Payment
+			if payment != nil {
+				rpcPayment, err = s.cfg.RouterBackend.
+					MarshallPayment(payment)
+			}
+
+			if err := send(idx, rpcPayment, err); err != nil {
+				log.Errorf("Unable to send batch payment "+
+					"result: %v", err)
+			}
+		}(i, intent)
+	}
+
+	wg.Wait()
+
+	return sendErr
+}
+
+// sendBatchPayment sends a payment of a batch and waits for its final state.
+// An error is returned if the payment couldn't be started.
+func (s *Server) sendBatchPayment(ctx context.Context,
+	payment *routing.LightningPayment) (*channeldb.MPPayment, error) {
+
+	paySession, shardTracker, err := s.cfg.Router.PreparePayment(payment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe to the payment before sending it to make sure we won't
+	// miss its final state.
+	sub, err := s.subscribePayment(payment.Identifier())
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Close()
+
+	s.cfg.Router.SendPaymentAsync(payment, paySession, shardTracker)
+
+	var last *channeldb.MPPayment
+	for {
+		select {
+		case item, ok := <-sub.Updates():
+			if !ok {
+				if last == nil {
+					return nil, fmt.Errorf("no update "+
+						"for payment %v",
+						payment.Identifier())
+				}
+
+				return last, nil
+			}
+
+			last = item.(*channeldb.MPPayment)
+			if last.Terminated() {
+				return last, nil
+			}
+
+		// The payment continues in the background if the batch is
+		// canceled, but its result isn't reported anymore.
+		case <-ctx.Done():
+			return nil, errBatchCanceled
+
+		case <-s.quit:
+			return nil, errServerShuttingDown
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package routerrpc
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBatchBudget asserts that the fee budget of a batch is shared
+// proportionally to the payment amounts and that unspent fees are made
+// available to the payments that are started later.
+func TestBatchBudget(t *testing.T) {
+	t.Parallel()
+
+	budget := newBatchBudget(1_000, 10_000)
+
+	// The first payment is entitled to a tenth of the budget.
+	share1 := budget.reserve(1_000)
+	require.EqualValues(t, 100, share1)
+
+	// The second payment is entitled to half of the remaining budget,
+	// as its amount is half of the amount that wasn't started yet.
+	share2 := budget.reserve(4_500)
+	require.EqualValues(t, 450, share2)
+
+	// The first payment only spends half of its share, which is returned
+	// to the budget.
+	budget.release(share1, 50)
+
+	// The last payment gets everything that is left.
+	share3 := budget.reserve(4_500)
+	require.EqualValues(t, 500, share3)
+
+	// Spending more than reserved doesn't return anything.
+	budget.release(share2, 500)
+	require.EqualValues(t, 0, budget.unreserved)
+
+	// A zero budget results in zero shares.
+	budget = newBatchBudget(0, 10_000)
+	require.Zero(t, budget.reserve(lnwire.MilliSatoshi(5_000)))
+}
@@ -56,6 +56,7 @@ func DefaultConfig() *Config {
 
 		AttemptCost:     routing.DefaultAttemptCost.ToSatoshis(),
 		AttemptCostPPM:  routing.DefaultAttemptCostPPM,
+		CltvRiskFactor:  routing.RiskFactorBillionths,
 		MaxMcHistory:    routing.DefaultMaxMcHistory,
 		McFlushInterval: routing.DefaultMcFlushInterval,
 		AprioriConfig: &AprioriConfig{
@@ -70,6 +71,14 @@ func DefaultConfig() *Config {
 			DecayTime:  routing.DefaultBimodalDecayTime,
 		},
 		FeeEstimationTimeout: routing.DefaultFeeEstimationTimeout,
+		RouteCacheTTL:        routing.DefaultRouteCacheTTL,
+		SplitStrategy:        routing.DefaultSplitter,
+		ProberConfig: &ProberConfig{
+			Interval:    DefaultProberInterval,
+			Amt:         DefaultProberAmt,
+			Timeout:     DefaultProberTimeout,
+			DailyBudget: DefaultProberDailyBudget,
+		},
 	}
 
 	return &Config{
@@ -84,6 +93,7 @@ func GetRoutingConfig(cfg *Config) *RoutingConfig {
 		MinRouteProbability:      cfg.MinRouteProbability,
 		AttemptCost:              cfg.AttemptCost,
 		AttemptCostPPM:           cfg.AttemptCostPPM,
+		CltvRiskFactor:           cfg.CltvRiskFactor,
 		MaxMcHistory:             cfg.MaxMcHistory,
 		McFlushInterval:          cfg.McFlushInterval,
 		AprioriConfig: &AprioriConfig{
@@ -98,5 +108,18 @@ func GetRoutingConfig(cfg *Config) *RoutingConfig {
 			DecayTime:  cfg.BimodalConfig.DecayTime,
 		},
 		FeeEstimationTimeout: cfg.FeeEstimationTimeout,
+		RouteCacheSize:       cfg.RouteCacheSize,
+		RouteCacheTTL:        cfg.RouteCacheTTL,
+		SplitStrategy:        cfg.SplitStrategy,
+		StalePaymentTimeout:  cfg.StalePaymentTimeout,
+		UseNodeScores:        cfg.UseNodeScores,
+		ProberConfig: &ProberConfig{
+			Enable:       cfg.ProberConfig.Enable,
+			Destinations: cfg.ProberConfig.Destinations,
+			Interval:     cfg.ProberConfig.Interval,
+			Amt:          cfg.ProberConfig.Amt,
+			Timeout:      cfg.ProberConfig.Timeout,
+			DailyBudget:  cfg.ProberConfig.DailyBudget,
+		},
 	}
 }
@@ -0,0 +1,138 @@
+package routerrpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// attemptUpdate is a state change of a single HTLC attempt.
+type attemptUpdate struct {
+	paymentHash lntypes.Hash
+	eventType   HtlcAttemptEvent_EventType
+	attempt     channeldb.HTLCAttempt
+}
+
+// attemptTracker derives attempt level events from the payment updates of
+// the control tower. It remembers the last known state of the attempts of
+// every payment that isn't terminated yet.
+type attemptTracker struct {
+	attempts map[lntypes.Hash]map[uint64]HtlcAttemptEvent_EventType
+}
+
+// newAttemptTracker returns a new, empty attempt tracker.
+func newAttemptTracker() *attemptTracker {
+	return &attemptTracker{
+		attempts: make(
+			map[lntypes.Hash]map[uint64]HtlcAttemptEvent_EventType,
+		),
+	}
+}
+
+// attemptState returns the event type that corresponds to the current state
+// of an attempt.
+func attemptState(htlc *channeldb.HTLCAttempt) HtlcAttemptEvent_EventType {
+	switch {
+	case htlc.Settle != nil:
+		return HtlcAttemptEvent_SETTLED
+
+	case htlc.Failure != nil:
+		return HtlcAttemptEvent_FAILED
+
+	default:
+		return HtlcAttemptEvent_LAUNCHED
+	}
+}
+
+// update returns the state changes of the attempts of the given payment since
+// its last update. For an attempt that wasn't seen before, only its current
+// state is reported.
+func (t *attemptTracker) update(
+	payment *channeldb.MPPayment) []attemptUpdate {
+
+	hash := payment.Info.PaymentIdentifier
+
+	known, ok := t.attempts[hash]
+	if !ok {
+		known = make(map[uint64]HtlcAttemptEvent_EventType)
+		t.attempts[hash] = known
+	}
+
+	var updates []attemptUpdate
+	for _, htlc := range payment.HTLCs {
+		state := attemptState(&htlc)
+
+		if prev, ok := known[htlc.AttemptID]; ok && prev == state {
+			continue
+		}
+		known[htlc.AttemptID] = state
+
+		updates = append(updates, attemptUpdate{
+			paymentHash: hash,
+			eventType:   state,
+			attempt:     htlc,
+		})
+	}
+
+	// Once the payment is terminated, none of its attempts will change
+	// anymore.
+	if payment.Terminated() {
+		delete(t.attempts, hash)
+	}
+
+	return updates
+}
+
+// SubscribeHtlcAttempts returns a stream that delivers an event whenever an
+// HTLC attempt of any payment is launched, settled or failed.
+func (s *Server) SubscribeHtlcAttempts(_ *SubscribeHtlcAttemptsRequest,
+	stream Router_SubscribeHtlcAttemptsServer) error {
+
+	subscription, err := s.cfg.RouterBackend.Tower.SubscribeAllPayments()
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	tracker := newAttemptTracker()
+	for {
+		select {
+		case item, ok := <-subscription.Updates():
+			if !ok {
+				return nil
+			}
+
+			payment := item.(*channeldb.MPPayment)
+			for _, update := range tracker.update(payment) {
+				attempt, err := s.cfg.RouterBackend.
+					MarshalHTLCAttempt(update.attempt)
+				if err != nil {
+					return err
+				}
+
+				err = stream.Send(&HtlcAttemptEvent{
+					PaymentHash: update.paymentHash[:],
+					EventType:   update.eventType,
+					Attempt:     attempt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+		case <-s.quit:
+			return errServerShuttingDown
+
+		case <-stream.Context().Done():
+			err := stream.Context().Err()
+			if errors.Is(err, context.Canceled) {
+				log.Debugf("SubscribeHtlcAttempts stream " +
+					"canceled")
+			}
+
+			return err
+		}
+	}
+}
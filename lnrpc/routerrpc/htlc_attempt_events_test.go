@@ -0,0 +1,123 @@
+package routerrpc
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAttemptTracker asserts that the attempt tracker reports every state
+// change of an attempt exactly once.
+func TestAttemptTracker(t *testing.T) {
+	t.Parallel()
+
+	hash := lntypes.Hash{1}
+	attempt := func(id uint64,
+		state HtlcAttemptEvent_EventType) channeldb.HTLCAttempt {
+
+		htlc := channeldb.HTLCAttempt{
+			HTLCAttemptInfo: channeldb.HTLCAttemptInfo{
+				AttemptID: id,
+			},
+		}
+
+		switch state {
+		case HtlcAttemptEvent_SETTLED:
+			htlc.Settle = &channeldb.HTLCSettleInfo{}
+
+		case HtlcAttemptEvent_FAILED:
+			htlc.Failure = &channeldb.HTLCFailInfo{}
+		}
+
+		return htlc
+	}
+
+	payment := func(status channeldb.PaymentStatus,
+		htlcs ...channeldb.HTLCAttempt) *channeldb.MPPayment {
+
+		return &channeldb.MPPayment{
+			Info: &channeldb.PaymentCreationInfo{
+				PaymentIdentifier: hash,
+			},
+			HTLCs:  htlcs,
+			Status: status,
+		}
+	}
+
+	// assertUpdates asserts that the update of the tracker with the given
+	// payment yields the expected events.
+	tracker := newAttemptTracker()
+	assertUpdates := func(p *channeldb.MPPayment,
+		expected map[uint64]HtlcAttemptEvent_EventType) {
+
+		t.Helper()
+
+		updates := tracker.update(p)
+		events := make(map[uint64]HtlcAttemptEvent_EventType)
+		for _, update := range updates {
+			require.Equal(t, hash, update.paymentHash)
+			events[update.attempt.AttemptID] = update.eventType
+		}
+		require.Len(t, updates, len(events))
+		require.Equal(t, expected, events)
+	}
+
+	// The payment is initiated without attempts.
+	assertUpdates(
+		payment(channeldb.StatusInitiated),
+		map[uint64]HtlcAttemptEvent_EventType{},
+	)
+
+	// The first attempt is launched.
+	assertUpdates(
+		payment(
+			channeldb.StatusInFlight,
+			attempt(0, HtlcAttemptEvent_LAUNCHED),
+		),
+		map[uint64]HtlcAttemptEvent_EventType{
+			0: HtlcAttemptEvent_LAUNCHED,
+		},
+	)
+
+	// The first attempt fails and a second one is launched.
+	assertUpdates(
+		payment(
+			channeldb.StatusInFlight,
+			attempt(0, HtlcAttemptEvent_FAILED),
+			attempt(1, HtlcAttemptEvent_LAUNCHED),
+		),
+		map[uint64]HtlcAttemptEvent_EventType{
+			0: HtlcAttemptEvent_FAILED,
+			1: HtlcAttemptEvent_LAUNCHED,
+		},
+	)
+
+	// The second attempt settles, which completes the payment.
+	assertUpdates(
+		payment(
+			channeldb.StatusSucceeded,
+			attempt(0, HtlcAttemptEvent_FAILED),
+			attempt(1, HtlcAttemptEvent_SETTLED),
+		),
+		map[uint64]HtlcAttemptEvent_EventType{
+			1: HtlcAttemptEvent_SETTLED,
+		},
+	)
+
+	// The terminated payment is no longer tracked.
+	require.Empty(t, tracker.attempts)
+
+	// An attempt of a payment that wasn't seen before is reported in its
+	// current state only.
+	assertUpdates(
+		payment(
+			channeldb.StatusInFlight,
+			attempt(0, HtlcAttemptEvent_FAILED),
+		),
+		map[uint64]HtlcAttemptEvent_EventType{
+			0: HtlcAttemptEvent_FAILED,
+		},
+	)
+}
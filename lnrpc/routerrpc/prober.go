@@ -0,0 +1,351 @@
+package routerrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+const (
+	// DefaultProberInterval is the default time between two rounds of
+	// probes.
+	DefaultProberInterval = 30 * time.Minute
+
+	// DefaultProberAmt is the default amount of a probe in satoshis.
+	DefaultProberAmt = 10_000
+
+	// DefaultProberTimeout is the default maximum time a single probe may
+	// take.
+	DefaultProberTimeout = time.Minute
+
+	// DefaultProberDailyBudget is the default maximum number of probes
+	// that are sent within 24 hours.
+	DefaultProberDailyBudget = 500
+
+	// proberBudgetWindow is the duration over which the probe budget is
+	// accounted.
+	proberBudgetWindow = 24 * time.Hour
+)
+
+// Validate checks that the prober config is consistent.
+func (c *ProberConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+
+	if len(c.Destinations) == 0 {
+		return errors.New("prober requires at least one destination")
+	}
+
+	if _, err := parseProberDestinations(c.Destinations); err != nil {
+		return err
+	}
+
+	switch {
+	case c.Interval <= 0:
+		return errors.New("prober interval must be positive")
+
+	case c.Amt <= 0:
+		return errors.New("prober amount must be positive")
+
+	case c.Timeout <= 0:
+		return errors.New("prober timeout must be positive")
+	}
+
+	return nil
+}
+
+// parseProberDestinations parses a list of hex encoded public keys.
+func parseProberDestinations(dests []string) ([]route.Vertex, error) {
+	vertices := make([]route.Vertex, 0, len(dests))
+	for _, dest := range dests {
+		vertex, err := route.NewVertexFromStr(dest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prober destination "+
+				"%v: %w", dest, err)
+		}
+		vertices = append(vertices, vertex)
+	}
+
+	return vertices, nil
+}
+
+// probeFunc sends a probe with the given payment hash, which the destination
+// can't settle, to the given destination.
+type probeFunc func(ctx context.Context, paymentHash lntypes.Hash,
+	dest route.Vertex, amt lnwire.MilliSatoshi,
+	timeout time.Duration) (*RouteFeeResponse, error)
+
+// deleteProbeFunc deletes the payment of a probe with the given payment hash.
+// It fails as long as the probe is still in flight.
+type deleteProbeFunc func(paymentHash lntypes.Hash) error
+
+// probeStats holds the statistics of the probes to a single destination.
+type probeStats struct {
+	attempts          uint64
+	successes         uint64
+	failures          uint64
+	lastProbe         time.Time
+	lastFeeMsat       int64
+	lastFailureReason lnrpc.PaymentFailureReason
+}
+
+// prober periodically probes a set of destinations within a budget. Every
+// probe carries a random payment hash, so that the destination can't settle
+// it. The results of the probes are recorded by mission control like the
+// results of any other payment, while the payments of the probes are deleted
+// once they are resolved.
+type prober struct {
+	cfg          *ProberConfig
+	destinations []route.Vertex
+	sendProbe    probeFunc
+	deleteProbe  deleteProbeFunc
+
+	// pendingDeletes holds the payment hashes of the probes whose payments
+	// couldn't be deleted yet, e.g. because they were still in flight
+	// when the probe timed out. Their deletion is retried every round.
+	pendingDeletes []lntypes.Hash
+
+	// now is expected to return the current time. It is supplied as an
+	// external function to enable deterministic unit tests.
+	now func() time.Time
+
+	// windowStart is the start of the current budget window and
+	// windowProbes the number of probes sent within it.
+	windowStart  time.Time
+	windowProbes uint32
+
+	stats map[route.Vertex]*probeStats
+
+	mu   sync.Mutex
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// newProber returns a new prober for the given config.
+func newProber(cfg *ProberConfig, sendProbe probeFunc,
+	deleteProbe deleteProbeFunc) (*prober, error) {
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	destinations, err := parseProberDestinations(cfg.Destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[route.Vertex]*probeStats, len(destinations))
+	for _, dest := range destinations {
+		stats[dest] = &probeStats{}
+	}
+
+	return &prober{
+		cfg:          cfg,
+		destinations: destinations,
+		sendProbe:    sendProbe,
+		deleteProbe:  deleteProbe,
+		now:          time.Now,
+		stats:        stats,
+		quit:         make(chan struct{}),
+	}, nil
+}
+
+// start launches the probe loop.
+func (p *prober) start() {
+	log.Infof("Starting payment prober for %v destinations every %v",
+		len(p.destinations), p.cfg.Interval)
+
+	p.wg.Add(1)
+	go p.run()
+}
+
+// stop stops the probe loop and waits for a probe in progress to return.
+func (p *prober) stop() {
+	close(p.quit)
+	p.wg.Wait()
+}
+
+// run sends a round of probes every interval until the prober is stopped.
+func (p *prober) run() {
+	defer p.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-p.quit
+		cancel()
+	}()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.probeRound(ctx)
+
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// probeRound probes all destinations once, as far as the budget allows.
+func (p *prober) probeRound(ctx context.Context) {
+	amt := lnwire.NewMSatFromSatoshis(p.cfg.Amt)
+
+	// Delete the payments of the probes of previous rounds that have been
+	// resolved in the meantime.
+	p.deleteProbes()
+
+	for _, dest := range p.destinations {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !p.takeBudget() {
+			log.Debugf("Probe budget of %v exhausted, skipping "+
+				"remaining destinations", p.cfg.DailyBudget)
+
+			return
+		}
+
+		paymentHash, err := randomProbeHash()
+		if err != nil {
+			log.Errorf("Unable to create probe: %v", err)
+
+			return
+		}
+
+		resp, err := p.sendProbe(
+			ctx, paymentHash, dest, amt, p.cfg.Timeout,
+		)
+		p.recordResult(dest, resp, err)
+
+		// Now that the result is recorded, the payment of the probe
+		// isn't needed anymore.
+		p.pendingDeletes = append(p.pendingDeletes, paymentHash)
+		p.deleteProbes()
+	}
+}
+
+// deleteProbes deletes the payments of all probes that are pending deletion,
+// and keeps those that can't be deleted yet for a later attempt.
+func (p *prober) deleteProbes() {
+	var remaining []lntypes.Hash
+	for _, paymentHash := range p.pendingDeletes {
+		if err := p.deleteProbe(paymentHash); err != nil {
+			log.Debugf("Unable to delete probe %v: %v",
+				paymentHash, err)
+
+			remaining = append(remaining, paymentHash)
+		}
+	}
+
+	p.pendingDeletes = remaining
+}
+
+// takeBudget returns true if another probe may be sent and accounts for it.
+func (p *prober) takeBudget() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if now.Sub(p.windowStart) >= proberBudgetWindow {
+		p.windowStart = now
+		p.windowProbes = 0
+	}
+
+	if p.cfg.DailyBudget > 0 && p.windowProbes >= p.cfg.DailyBudget {
+		return false
+	}
+	p.windowProbes++
+
+	return true
+}
+
+// recordResult updates the statistics of a destination with the outcome of a
+// probe. A probe is successful if it reached the destination.
+func (p *prober) recordResult(dest route.Vertex, resp *RouteFeeResponse,
+	err error) {
+
+	var (
+		reasonNone  = lnrpc.PaymentFailureReason_FAILURE_REASON_NONE
+		reasonError = lnrpc.PaymentFailureReason_FAILURE_REASON_ERROR
+	)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats[dest]
+	stats.attempts++
+	stats.lastProbe = p.now()
+
+	switch {
+	case err != nil:
+		log.Warnf("Probe to %v failed: %v", dest, err)
+
+		stats.failures++
+		stats.lastFailureReason = reasonError
+
+	case resp.FailureReason != reasonNone:
+		log.Debugf("Probe to %v failed: %v", dest, resp.FailureReason)
+
+		stats.failures++
+		stats.lastFailureReason = resp.FailureReason
+
+	default:
+		log.Debugf("Probe to %v reached the destination with a fee "+
+			"of %v msat", dest, resp.RoutingFeeMsat)
+
+		stats.successes++
+		stats.lastFeeMsat = resp.RoutingFeeMsat
+		stats.lastFailureReason = resp.FailureReason
+	}
+}
+
+// statsResponse returns the current statistics of the prober.
+func (p *prober) statsResponse() *GetProberStatsResponse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	resp := &GetProberStatsResponse{
+		Enabled:      true,
+		DailyBudget:  p.cfg.DailyBudget,
+		BudgetUsed:   p.windowProbes,
+		Destinations: make([]*ProbeDestinationStats, 0, len(p.stats)),
+	}
+
+	for _, dest := range p.destinations {
+		dest := dest
+		stats := p.stats[dest]
+
+		var lastProbe int64
+		if !stats.lastProbe.IsZero() {
+			lastProbe = stats.lastProbe.Unix()
+		}
+
+		resp.Destinations = append(
+			resp.Destinations, &ProbeDestinationStats{
+				PubKey:            dest[:],
+				Attempts:          stats.attempts,
+				Successes:         stats.successes,
+				Failures:          stats.failures,
+				LastProbeTime:     lastProbe,
+				LastFeeMsat:       stats.lastFeeMsat,
+				LastFailureReason: stats.lastFailureReason,
+			},
+		)
+	}
+
+	return resp
+}
@@ -0,0 +1,145 @@
+package routerrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProber tests that the prober probes its destinations within its budget
+// and keeps track of the results.
+func TestProber(t *testing.T) {
+	t.Parallel()
+
+	dest1 := route.Vertex{2, 1}
+	dest2 := route.Vertex{3, 2}
+
+	cfg := &ProberConfig{
+		Enable:       true,
+		Destinations: []string{dest1.String(), dest2.String()},
+		Interval:     time.Minute,
+		Amt:          1000,
+		Timeout:      time.Minute,
+		DailyBudget:  3,
+	}
+
+	var (
+		probed       []route.Vertex
+		probes       []lntypes.Hash
+		keepInFlight bool
+		inFlight     = make(map[lntypes.Hash]bool)
+		deletedProbe = make(map[lntypes.Hash]bool)
+	)
+	sendProbe := func(_ context.Context, paymentHash lntypes.Hash,
+		dest route.Vertex, amt lnwire.MilliSatoshi,
+		_ time.Duration) (*RouteFeeResponse, error) {
+
+		require.Equal(t, lnwire.MilliSatoshi(1_000_000), amt)
+		probed = append(probed, dest)
+		probes = append(probes, paymentHash)
+		inFlight[paymentHash] = keepInFlight
+
+		if dest == dest2 {
+			return nil, errors.New("no route")
+		}
+
+		return &RouteFeeResponse{
+			RoutingFeeMsat: 10,
+			FailureReason: lnrpc.
+				PaymentFailureReason_FAILURE_REASON_NONE,
+		}, nil
+	}
+
+	deleteProbe := func(paymentHash lntypes.Hash) error {
+		if inFlight[paymentHash] {
+			return errors.New("in flight")
+		}
+		deletedProbe[paymentHash] = true
+
+		return nil
+	}
+
+	p, err := newProber(cfg, sendProbe, deleteProbe)
+	require.NoError(t, err)
+
+	now := time.Unix(1_000_000, 0)
+	p.now = func() time.Time {
+		return now
+	}
+
+	// The first round probes both destinations and deletes their
+	// payments.
+	p.probeRound(context.Background())
+	require.Equal(t, []route.Vertex{dest1, dest2}, probed)
+	require.Len(t, probes, 2)
+	require.NotEqual(t, probes[0], probes[1])
+	require.True(t, deletedProbe[probes[0]])
+	require.True(t, deletedProbe[probes[1]])
+
+	// The second round is cut short by the budget. Its probe is still in
+	// flight when it returns, so its payment can't be deleted yet.
+	keepInFlight = true
+	p.probeRound(context.Background())
+	require.Equal(t, []route.Vertex{dest1, dest2, dest1}, probed)
+	require.False(t, deletedProbe[probes[2]])
+	require.Equal(t, []lntypes.Hash{probes[2]}, p.pendingDeletes)
+
+	stats := p.statsResponse()
+	require.EqualValues(t, 3, stats.BudgetUsed)
+	require.Len(t, stats.Destinations, 2)
+
+	require.Equal(t, dest1[:], stats.Destinations[0].PubKey)
+	require.EqualValues(t, 2, stats.Destinations[0].Attempts)
+	require.EqualValues(t, 2, stats.Destinations[0].Successes)
+	require.EqualValues(t, 10, stats.Destinations[0].LastFeeMsat)
+	require.Equal(t, now.Unix(), stats.Destinations[0].LastProbeTime)
+
+	require.EqualValues(t, 1, stats.Destinations[1].Attempts)
+	require.EqualValues(t, 1, stats.Destinations[1].Failures)
+	require.Equal(
+		t, lnrpc.PaymentFailureReason_FAILURE_REASON_ERROR,
+		stats.Destinations[1].LastFailureReason,
+	)
+
+	// Once the budget window passed, probing resumes, and the payment of
+	// the probe that has been resolved in the meantime is deleted.
+	keepInFlight = false
+	inFlight[probes[2]] = false
+	now = now.Add(proberBudgetWindow)
+	p.probeRound(context.Background())
+	require.Len(t, probed, 5)
+	require.True(t, deletedProbe[probes[2]])
+	require.Empty(t, p.pendingDeletes)
+}
+
+// TestProberConfigValidate tests the validation of the prober config.
+func TestProberConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	// A disabled prober doesn't need any further settings.
+	require.NoError(t, (&ProberConfig{}).Validate())
+
+	cfg := &ProberConfig{
+		Enable:   true,
+		Interval: time.Minute,
+		Amt:      1000,
+		Timeout:  time.Minute,
+	}
+	require.Error(t, cfg.Validate())
+
+	cfg.Destinations = []string{"invalid"}
+	require.Error(t, cfg.Validate())
+
+	cfg.Destinations = []string{route.Vertex{2}.String()}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Interval = 0
+	require.Error(t, cfg.Validate())
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	math "math"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -34,6 +36,10 @@ const (
 	// TODO(roasbeef): make this value dynamic based on expected number of
 	// attempts for given amount.
 	DefaultMaxParts = 16
+
+	// MaxNumRoutes is the maximum number of routes that can be requested
+	// in a single QueryRoutes call.
+	MaxNumRoutes = 16
 )
 
 // RouterBackend contains the backend implementation of the router rpc sub
@@ -62,6 +68,22 @@ type RouterBackend struct {
 
 	MissionControl MissionControl
 
+	// GetMissionControl returns the mission control instance of the given
+	// namespace. The namespace is created if it doesn't exist yet.
+	GetMissionControl func(namespace string) (MissionControl, error)
+
+	// ListMissionControlNamespaces returns the names of all known mission
+	// control namespaces, including the default one.
+	ListMissionControlNamespaces func() []string
+
+	// SetMissionControlConfig applies the given config to the mission
+	// control instances of all namespaces.
+	SetMissionControlConfig func(cfg *routing.MissionControlConfig) error
+
+	// NodeScores is the store of node scores that were imported from
+	// external sources.
+	NodeScores *routing.NodeScoreStore
+
 	// ActiveNetParams are the network parameters of the primary network
 	// that the route is operating on. This is necessary so we can ensure
 	// that we receive payment requests that send to destinations on our
@@ -72,6 +94,15 @@ type RouterBackend struct {
 	// payments.
 	Tower routing.ControlTower
 
+	// QueryPayments queries the payments that are stored in the database.
+	QueryPayments func(channeldb.PaymentsQuery) (channeldb.PaymentsResponse,
+		error)
+
+	// DeletePayment deletes the payment with the given payment hash from
+	// the database. If failedHtlcsOnly is set, only its failed HTLC
+	// attempts are deleted.
+	DeletePayment func(paymentHash lntypes.Hash, failedHtlcsOnly bool) error
+
 	// MaxTotalTimelock is the maximum total time lock a route is allowed to
 	// have.
 	MaxTotalTimelock uint32
@@ -108,15 +139,30 @@ type RouterBackend struct {
 
 // MissionControl defines the mission control dependencies of routerrpc.
 type MissionControl interface {
-	// GetProbability is expected to return the success probability of a
-	// payment from fromNode to toNode.
-	GetProbability(fromNode, toNode route.Vertex,
-		amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64
+	// MissionController exposes the probability estimates and the
+	// reporting of payment results that are used by the payment
+	// lifecycle.
+	routing.MissionController
 
 	// ResetHistory resets the history of MissionControl returning it to a
 	// state as if no payment attempts have been made.
 	ResetHistory() error
 
+	// ResetPairs resets the history of the given node pairs and of all
+	// pairs that have one of the given nodes as their source or
+	// destination. It returns the number of pairs that were reset.
+	ResetPairs(pairs []routing.DirectedNodePair,
+		nodes []route.Vertex) (int, error)
+
+	// SetBimodalPairOverrides sets the bimodal parameters of the given
+	// pairs and removes the overrides of the pairs to remove.
+	SetBimodalPairOverrides(overrides routing.BimodalPairOverrides,
+		remove []routing.DirectedNodePair) error
+
+	// GetBimodalPairOverrides returns the bimodal parameters that are set
+	// for single pairs.
+	GetBimodalPairOverrides() routing.BimodalPairOverrides
+
 	// GetHistorySnapshot takes a snapshot from the current mission control
 	// state and actual probability estimates.
 	GetHistorySnapshot() *routing.MissionControlSnapshot
@@ -131,6 +177,11 @@ type MissionControl interface {
 	GetPairHistorySnapshot(fromNode,
 		toNode route.Vertex) routing.TimedPairResult
 
+	// GetRouteProbability returns the success probability of the given
+	// route and the conditional success probability of every hop.
+	GetRouteProbability(rt *route.Route,
+		capacities []btcutil.Amount) (float64, []float64, error)
+
 	// GetConfig gets mission control's current config.
 	GetConfig() *routing.MissionControlConfig
 
@@ -139,44 +190,422 @@ type MissionControl interface {
 	SetConfig(cfg *routing.MissionControlConfig) error
 }
 
+// missionControl returns the mission control instance of the given
+// namespace. An empty namespace refers to the default namespace. If create is
+// false, an error is returned for namespaces that don't exist yet.
+func (r *RouterBackend) missionControl(namespace string,
+	create bool) (MissionControl, error) {
+
+	if namespace == "" ||
+		namespace == routing.DefaultMissionControlNamespace {
+
+		return r.MissionControl, nil
+	}
+
+	if !create {
+		var found bool
+		for _, ns := range r.ListMissionControlNamespaces() {
+			if ns == namespace {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown mission control "+
+				"namespace %v", namespace)
+		}
+	}
+
+	return r.GetMissionControl(namespace)
+}
+
 // QueryRoutes attempts to query the daemons' Channel Router for a possible
 // route to a target destination capable of carrying a specific amount of
 // satoshis within the route's flow. The returned route contains the full
 // details required to craft and send an HTLC, also including the necessary
 // information that should be present within the Sphinx packet encapsulated
-// within the HTLC.
-//
-// TODO(roasbeef): should return a slice of routes in reality * create separate
-// PR to send based on well formatted route
+// within the HTLC. If requested, multiple distinct routes are returned.
 func (r *RouterBackend) QueryRoutes(ctx context.Context,
 	in *lnrpc.QueryRoutesRequest) (*lnrpc.QueryRoutesResponse, error) {
 
+	if in.NumRoutes > MaxNumRoutes {
+		return nil, fmt.Errorf("number of routes %v exceeds maximum "+
+			"of %v", in.NumRoutes, MaxNumRoutes)
+	}
+
 	routeReq, err := r.parseQueryRoutesRequest(in)
 	if err != nil {
 		return nil, err
 	}
 
+	avoided, err := r.unmarshallAvoidedRoute(
+		in.AvoidRouteChanIds, in.MaxRouteOverlap, routeReq.Source,
+		routeReq.Target,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the statistics of all path finding searches if requested.
+	var stats *routing.PathFindingStats
+	if in.IncludeDiagnostics {
+		stats = &routing.PathFindingStats{}
+		routeReq.Restrictions.Stats = stats
+	}
+
 	// Query the channel router for a possible path to the destination that
 	// can carry `in.Amt` satoshis _including_ the total fee required on
 	// the route
-	route, successProb, err := r.FindRoute(routeReq)
+	routes, successProbs, err := r.findRoutes(
+		routeReq, avoided, int(in.NumRoutes),
+	)
 	if err != nil {
+		if stats != nil {
+			return nil, fmt.Errorf("%w (pathfinding diagnostics: "+
+				"%v)", err, stats)
+		}
+
 		return nil, err
 	}
 
+	routeResp := &lnrpc.QueryRoutesResponse{
+		SuccessProb:  successProbs[0],
+		SuccessProbs: successProbs,
+	}
+	if stats != nil {
+		routeResp.Diagnostics = marshallPathFindingStats(stats)
+	}
+
 	// For each valid route, we'll convert the result into the format
 	// required by the RPC system.
-	rpcRoute, err := r.MarshallRoute(route)
+	for _, route := range routes {
+		rpcRoute, err := r.MarshallRoute(route)
+		if err != nil {
+			return nil, err
+		}
+
+		routeResp.Routes = append(routeResp.Routes, rpcRoute)
+	}
+
+	return routeResp, nil
+}
+
+// marshallPathFindingStats converts path finding statistics into their RPC
+// representation.
+func marshallPathFindingStats(
+	stats *routing.PathFindingStats) *lnrpc.PathFindingDiagnostics {
+
+	pruneReasons := make(map[string]uint64, len(stats.Pruned))
+	for reason, count := range stats.Pruned {
+		pruneReasons[reason.String()] = uint64(count)
+	}
+
+	return &lnrpc.PathFindingDiagnostics{
+		NumSearches:   uint32(stats.NumSearches),
+		NodesVisited:  uint64(stats.NodesVisited),
+		EdgesExpanded: uint64(stats.EdgesExpanded),
+		EdgesRelaxed:  uint64(stats.EdgesRelaxed),
+		PruneReasons:  pruneReasons,
+		DurationUs:    stats.Duration.Microseconds(),
+	}
+}
+
+// findRoutes queries path finding for up to numRoutes distinct routes. If a
+// route to avoid is given, all routes diverge from it. After the first route
+// has been found, every following search excludes the intermediate nodes of
+// all routes found so far. If that fails, the search is retried only excluding
+// the node pairs that were used. The routes are returned ordered by their
+// total fees along with their success probabilities. An error is only
+// returned if not even a single route could be found.
+func (r *RouterBackend) findRoutes(routeReq *routing.RouteRequest,
+	avoided *avoidedRoute, numRoutes int) ([]*route.Route, []float64,
+	error) {
+
+	routeReq, firstRoute, firstProb, err := r.findDiverseRoute(
+		routeReq, avoided,
+	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	routeResp := &lnrpc.QueryRoutesResponse{
-		Routes:      []*lnrpc.Route{rpcRoute},
-		SuccessProb: successProb,
+	var (
+		routes       = []*route.Route{firstRoute}
+		successProbs = []float64{firstProb}
+	)
+
+	if numRoutes <= 1 {
+		return routes, successProbs, nil
 	}
 
-	return routeResp, nil
+	// Wrap the probability source of the request so that we can exclude
+	// the nodes and pairs of the routes we already found on top of the
+	// exclusions of the request itself. We copy the restrictions to not
+	// modify the caller's request.
+	var (
+		usedNodes    = make(map[route.Vertex]struct{})
+		usedPairs    = make(map[routing.DirectedNodePair]struct{})
+		excludeNodes bool
+	)
+
+	restrictions := *routeReq.Restrictions
+	probabilitySource := restrictions.ProbabilitySource
+	restrictions.ProbabilitySource = func(fromNode, toNode route.Vertex,
+		amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64 {
+
+		if _, ok := usedNodes[fromNode]; ok && excludeNodes {
+			return 0
+		}
+
+		pair := routing.NewDirectedNodePair(fromNode, toNode)
+		if _, ok := usedPairs[pair]; ok {
+			return 0
+		}
+
+		return probabilitySource(fromNode, toNode, amt, capacity)
+	}
+
+	req := *routeReq
+	req.Restrictions = &restrictions
+
+	addUsed := func(rt *route.Route) {
+		from := rt.SourcePubKey
+		for i, hop := range rt.Hops {
+			// The final hop is the destination which can't be
+			// avoided, so we only exclude intermediate nodes.
+			if i < len(rt.Hops)-1 {
+				usedNodes[hop.PubKeyBytes] = struct{}{}
+			}
+
+			pair := routing.NewDirectedNodePair(
+				from, hop.PubKeyBytes,
+			)
+			usedPairs[pair] = struct{}{}
+
+			from = hop.PubKeyBytes
+		}
+	}
+	addUsed(firstRoute)
+
+	for len(routes) < numRoutes {
+		// Prefer a route that is node-disjoint from the ones found so
+		// far.
+		excludeNodes = true
+		rt, prob, err := r.FindRoute(&req)
+		if err != nil {
+			log.Debugf("No node-disjoint route found for route "+
+				"%v: %v", len(routes), err)
+
+			excludeNodes = false
+			rt, prob, err = r.FindRoute(&req)
+		}
+		if err != nil {
+			log.Debugf("Unable to find more than %v route(s): %v",
+				len(routes), err)
+
+			break
+		}
+
+		routes = append(routes, rt)
+		successProbs = append(successProbs, prob)
+		addUsed(rt)
+	}
+
+	// Order the routes by their total fees, keeping the order in which
+	// they were found for routes with equal fees.
+	indices := make([]int, len(routes))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return routes[indices[i]].TotalFees() <
+			routes[indices[j]].TotalFees()
+	})
+
+	sortedRoutes := make([]*route.Route, len(routes))
+	sortedProbs := make([]float64, len(routes))
+	for i, idx := range indices {
+		sortedRoutes[i] = routes[idx]
+		sortedProbs[i] = successProbs[idx]
+	}
+
+	return sortedRoutes, sortedProbs, nil
+}
+
+// avoidedRoute is a previously used route that routes should diverge from.
+type avoidedRoute struct {
+	// chanIDs are the channels of the route.
+	chanIDs map[uint64]struct{}
+
+	// channels maps the channels of the route whose endpoints are known
+	// to the pairs of nodes they connect.
+	channels map[uint64][2]route.Vertex
+
+	// nodes are the intermediate nodes of the route.
+	nodes map[route.Vertex]struct{}
+
+	// maxOverlap is the maximum fraction of the channels and intermediate
+	// nodes of the route that another route may share with it.
+	maxOverlap float64
+}
+
+// unmarshallAvoidedRoute converts the channel IDs of a route to avoid into
+// an avoidedRoute. The endpoints of the channels other than the source and
+// target of the route request are considered intermediate nodes. Channels
+// that aren't known anymore are only avoided by their ID. If no channel IDs
+// are given, nil is returned.
+func (r *RouterBackend) unmarshallAvoidedRoute(chanIDs []uint64,
+	maxOverlap float64, source, target route.Vertex) (*avoidedRoute,
+	error) {
+
+	if len(chanIDs) == 0 {
+		return nil, nil
+	}
+
+	if !(maxOverlap >= 0 && maxOverlap <= 1) {
+		return nil, fmt.Errorf("max route overlap %v not in [0, 1]",
+			maxOverlap)
+	}
+
+	avoided := &avoidedRoute{
+		chanIDs:    make(map[uint64]struct{}, len(chanIDs)),
+		channels:   make(map[uint64][2]route.Vertex, len(chanIDs)),
+		nodes:      make(map[route.Vertex]struct{}),
+		maxOverlap: maxOverlap,
+	}
+	for _, chanID := range chanIDs {
+		avoided.chanIDs[chanID] = struct{}{}
+
+		node1, node2, err := r.FetchChannelEndpoints(chanID)
+		if err != nil {
+			log.Warnf("Avoid endpoints of channel %v skipped: %v",
+				chanID, err)
+
+			continue
+		}
+		avoided.channels[chanID] = [2]route.Vertex{node1, node2}
+
+		for _, node := range []route.Vertex{node1, node2} {
+			if node != source && node != target {
+				avoided.nodes[node] = struct{}{}
+			}
+		}
+	}
+
+	return avoided, nil
+}
+
+// overlap returns the fraction of the channels and intermediate nodes of the
+// avoided route that the given route shares with it, along with the shared
+// nodes and channels.
+func (a *avoidedRoute) overlap(rt *route.Route) (float64, []route.Vertex,
+	[]uint64) {
+
+	var (
+		sharedNodes []route.Vertex
+		sharedChans []uint64
+	)
+	for i, hop := range rt.Hops {
+		if _, ok := a.chanIDs[hop.ChannelID]; ok {
+			sharedChans = append(sharedChans, hop.ChannelID)
+		}
+
+		// The final hop is the destination which can't be avoided.
+		if i == len(rt.Hops)-1 {
+			continue
+		}
+		if _, ok := a.nodes[hop.PubKeyBytes]; ok {
+			sharedNodes = append(sharedNodes, hop.PubKeyBytes)
+		}
+	}
+
+	total := len(a.chanIDs) + len(a.nodes)
+	shared := len(sharedNodes) + len(sharedChans)
+
+	return float64(shared) / float64(total), sharedNodes, sharedChans
+}
+
+// findDiverseRoute finds a route that shares at most the allowed fraction of
+// the avoided route. As long as the route found shares too much, one of the
+// shared intermediate nodes or, if there are none, one of the shared channels
+// is excluded and the search is repeated. The returned route request carries
+// the exclusions, so that it can be used to search for further diverse
+// routes. If avoided is nil, the route request is returned unchanged.
+func (r *RouterBackend) findDiverseRoute(routeReq *routing.RouteRequest,
+	avoided *avoidedRoute) (*routing.RouteRequest, *route.Route, float64,
+	error) {
+
+	if avoided == nil {
+		rt, prob, err := r.FindRoute(routeReq)
+
+		return routeReq, rt, prob, err
+	}
+
+	// We copy the restrictions to not modify the caller's request.
+	var (
+		excludedNodes = make(map[route.Vertex]struct{})
+		excludedPairs = make(map[routing.DirectedNodePair]struct{})
+	)
+
+	restrictions := *routeReq.Restrictions
+	probabilitySource := restrictions.ProbabilitySource
+	restrictions.ProbabilitySource = func(fromNode, toNode route.Vertex,
+		amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64 {
+
+		if _, ok := excludedNodes[fromNode]; ok {
+			return 0
+		}
+
+		pair := routing.NewDirectedNodePair(fromNode, toNode)
+		if _, ok := excludedPairs[pair]; ok {
+			return 0
+		}
+
+		return probabilitySource(fromNode, toNode, amt, capacity)
+	}
+
+	req := *routeReq
+	req.Restrictions = &restrictions
+
+	for {
+		rt, prob, err := r.FindRoute(&req)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("unable to find route "+
+				"with an overlap of at most %v: %w",
+				avoided.maxOverlap, err)
+		}
+
+		overlap, sharedNodes, sharedChans := avoided.overlap(rt)
+		if overlap <= avoided.maxOverlap {
+			return &req, rt, prob, nil
+		}
+
+		log.Debugf("Route overlap %v exceeds %v, excluding shared "+
+			"nodes %v and channels %v", overlap,
+			avoided.maxOverlap, sharedNodes, sharedChans)
+
+		// Excluding a node also avoids all its channels, so we
+		// exclude shared nodes first. Every iteration excludes another
+		// element of the avoided route, so the loop terminates.
+		if len(sharedNodes) > 0 {
+			excludedNodes[sharedNodes[0]] = struct{}{}
+			continue
+		}
+
+		// A channel whose endpoints are unknown isn't part of the
+		// graph, so we don't expect it to be part of the route.
+		nodes, ok := avoided.channels[sharedChans[0]]
+		if !ok {
+			return nil, nil, 0, fmt.Errorf("unknown endpoints of "+
+				"shared channel %v", sharedChans[0])
+		}
+		excludedPairs[routing.NewDirectedNodePair(
+			nodes[0], nodes[1],
+		)] = struct{}{}
+		excludedPairs[routing.NewDirectedNodePair(
+			nodes[1], nodes[0],
+		)] = struct{}{}
+	}
 }
 
 func parsePubKey(key string) (route.Vertex, error) {
@@ -192,16 +621,15 @@ func (r *RouterBackend) parseIgnored(in *lnrpc.QueryRoutesRequest) (
 	map[route.Vertex]struct{}, map[routing.DirectedNodePair]struct{},
 	error) {
 
-	ignoredNodes := make(map[route.Vertex]struct{})
-	for _, ignorePubKey := range in.IgnoredNodes {
-		ignoreVertex, err := route.NewVertexFromBytes(ignorePubKey)
-		if err != nil {
-			return nil, nil, err
-		}
-		ignoredNodes[ignoreVertex] = struct{}{}
+	ignoredNodes, err := unmarshallIgnoredNodes(in.IgnoredNodes)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	ignoredPairs := make(map[routing.DirectedNodePair]struct{})
+	ignoredPairs, err := unmarshallIgnoredPairs(in.IgnoredPairs)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Convert deprecated ignoredEdges to pairs.
 	for _, ignoredEdge := range in.IgnoredEdges {
@@ -215,23 +643,135 @@ func (r *RouterBackend) parseIgnored(in *lnrpc.QueryRoutesRequest) (
 		ignoredPairs[pair] = struct{}{}
 	}
 
-	// Add ignored pairs to set.
-	for _, ignorePair := range in.IgnoredPairs {
+	return ignoredNodes, ignoredPairs, nil
+}
+
+// unmarshallOutgoingChanWeights converts the rpc outgoing channel weights into
+// a map of weights in the range (0, 1] by scaling them relative to the highest
+// weight. Unweighted channels implicitly have the highest weight, so they are
+// added with a weight of one.
+func unmarshallOutgoingChanWeights(unweighted []uint64,
+	rpcWeights []*OutgoingChannelWeight) (map[uint64]float64, error) {
+
+	var maxWeight float64
+	for _, w := range rpcWeights {
+		if !(w.Weight > 0) || math.IsInf(w.Weight, 0) {
+			return nil, fmt.Errorf("invalid weight %v for "+
+				"outgoing channel %v, weights must be "+
+				"positive", w.Weight, w.ChanId)
+		}
+
+		maxWeight = math.Max(maxWeight, w.Weight)
+	}
+
+	weights := make(map[uint64]float64, len(rpcWeights))
+	for _, chanID := range unweighted {
+		weights[chanID] = 1
+	}
+
+	for _, w := range rpcWeights {
+		if _, ok := weights[w.ChanId]; ok {
+			return nil, fmt.Errorf("outgoing channel %v specified "+
+				"more than once", w.ChanId)
+		}
+
+		weights[w.ChanId] = w.Weight / maxWeight
+	}
+
+	return weights, nil
+}
+
+// unmarshallIgnoredNodes converts a list of serialized node public keys into
+// a set of vertices.
+func unmarshallIgnoredNodes(
+	rpcNodes [][]byte) (map[route.Vertex]struct{}, error) {
+
+	ignoredNodes := make(map[route.Vertex]struct{})
+	for _, ignorePubKey := range rpcNodes {
+		ignoreVertex, err := route.NewVertexFromBytes(ignorePubKey)
+		if err != nil {
+			return nil, err
+		}
+		ignoredNodes[ignoreVertex] = struct{}{}
+	}
+
+	return ignoredNodes, nil
+}
+
+// unmarshallIgnoredPairs converts a list of rpc node pairs into a set of
+// directed node pairs.
+func unmarshallIgnoredPairs(rpcPairs []*lnrpc.NodePair) (
+	map[routing.DirectedNodePair]struct{}, error) {
+
+	ignoredPairs := make(map[routing.DirectedNodePair]struct{})
+	for _, ignorePair := range rpcPairs {
 		from, err := route.NewVertexFromBytes(ignorePair.From)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		to, err := route.NewVertexFromBytes(ignorePair.To)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		pair := routing.NewDirectedNodePair(from, to)
 		ignoredPairs[pair] = struct{}{}
 	}
 
-	return ignoredNodes, ignoredPairs, nil
+	return ignoredPairs, nil
+}
+
+// unmarshallFeeLimitPolicy returns the relative fee limit of a payment request
+// or nil if the fee limit isn't expressed relative to the amount.
+func unmarshallFeeLimitPolicy(
+	rpcPayReq *SendPaymentRequest) (*routing.FeeLimitPolicy, error) {
+
+	if rpcPayReq.FeeLimitPpm == 0 && rpcPayReq.FeeLimitMinMsat == 0 &&
+		rpcPayReq.FeeLimitMaxMsat == 0 {
+
+		return nil, nil
+	}
+
+	switch {
+	case rpcPayReq.FeeLimitSat != 0 || rpcPayReq.FeeLimitMsat != 0:
+		return nil, errors.New("a relative fee limit can't be " +
+			"combined with fee_limit_sat and fee_limit_msat")
+
+	case rpcPayReq.FeeLimitMinMsat < 0 || rpcPayReq.FeeLimitMaxMsat < 0:
+		return nil, errors.New("fee limit bounds must not be negative")
+	}
+
+	policy := &routing.FeeLimitPolicy{
+		PPM: rpcPayReq.FeeLimitPpm,
+		Min: lnwire.MilliSatoshi(rpcPayReq.FeeLimitMinMsat),
+		Max: lnwire.MilliSatoshi(rpcPayReq.FeeLimitMaxMsat),
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// unmarshallLastHops converts a list of rpc pubkeys into an ordered list of
+// last hop candidates.
+func unmarshallLastHops(rpcLastHops [][]byte) ([]route.Vertex, error) {
+	lastHops := make([]route.Vertex, 0, len(rpcLastHops))
+	for _, pubKey := range rpcLastHops {
+		lastHop, err := route.NewVertexFromBytes(pubKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(lastHops, lastHop) {
+			return nil, fmt.Errorf("last hop %v specified more "+
+				"than once", lastHop)
+		}
+		lastHops = append(lastHops, lastHop)
+	}
+
+	return lastHops, nil
 }
 
 func (r *RouterBackend) parseQueryRoutesRequest(in *lnrpc.QueryRoutesRequest) (
@@ -361,6 +901,22 @@ func (r *RouterBackend) parseQueryRoutesRequest(in *lnrpc.QueryRoutesRequest) (
 		return nil, err
 	}
 
+	estimator, err := UnmarshallEstimator(in.Estimator)
+	if err != nil {
+		return nil, err
+	}
+
+	// The namespace is only looked up if mission control is used. Querying
+	// routes only reads from mission control, so unknown namespaces are
+	// rejected instead of being created.
+	var mc MissionControl
+	if in.UseMissionControl {
+		mc, err = r.missionControl(in.MissionControlNamespace, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	restrictions := &routing.RestrictParams{
 		FeeLimit: feeLimit,
 		ProbabilitySource: func(fromNode, toNode route.Vertex,
@@ -383,14 +939,24 @@ func (r *RouterBackend) parseQueryRoutesRequest(in *lnrpc.QueryRoutesRequest) (
 				return 1
 			}
 
-			return r.MissionControl.GetProbability(
+			if estimator != nil {
+				return mc.GetProbabilityWithEstimator(
+					estimator, fromNode, toNode, amt,
+					capacity,
+				)
+			}
+
+			return mc.GetProbability(
 				fromNode, toNode, amt, capacity,
 			)
 		},
 		DestCustomRecords: record.CustomSet(in.DestCustomRecords),
 		CltvLimit:         cltvLimit,
+		MaxHops:           in.MaxHops,
 		DestFeatures:      destinationFeatures,
 		BlindedPayment:    blindedPmt,
+		Timeout: time.Duration(in.PathfindingTimeoutMs) *
+			time.Millisecond,
 	}
 
 	// Pass along an outgoing channel restriction if specified.
@@ -398,6 +964,37 @@ func (r *RouterBackend) parseQueryRoutesRequest(in *lnrpc.QueryRoutesRequest) (
 		restrictions.OutgoingChannelIDs = []uint64{in.OutgoingChanId}
 	}
 
+	// Pass along an incoming channel restriction if specified.
+	restrictions.IncomingChannelID = in.IncomingChanId
+
+	if in.MinChannelCapacity < 0 {
+		return nil, errors.New("min_channel_capacity must not be " +
+			"negative")
+	}
+	restrictions.MinChannelCapacity = btcutil.Amount(
+		in.MinChannelCapacity,
+	)
+
+	// A circular route leaves and returns to our own node. Both channels
+	// need to be given, otherwise path finding would happily return
+	// through the channel that the route leaves through.
+	if targetPubKey != nil && *targetPubKey == sourcePubKey {
+		switch {
+		case sourcePubKey != r.SelfNode:
+			return nil, errors.New("circular routes are only " +
+				"supported from our own node")
+
+		case in.OutgoingChanId == 0 || in.IncomingChanId == 0:
+			return nil, errors.New("circular routes require " +
+				"outgoing_chan_id and incoming_chan_id")
+
+		case in.OutgoingChanId == in.IncomingChanId:
+			return nil, errors.New("outgoing_chan_id and " +
+				"incoming_chan_id of a circular route must " +
+				"differ")
+		}
+	}
+
 	// Pass along a last hop restriction if specified.
 	if len(in.LastHopPubkey) > 0 {
 		lastHop, err := route.NewVertexFromBytes(
@@ -787,6 +1384,49 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 	}
 	payIntent.TimePref = rpcPayReq.TimePref
 
+	// Pass along the payment specific probability estimator, if any.
+	estimator, err := UnmarshallEstimator(rpcPayReq.Estimator)
+	if err != nil {
+		return nil, err
+	}
+	payIntent.Estimator = estimator
+
+	// Use the mission control namespace of the payment, if any. The
+	// router falls back to the default namespace if none is set.
+	if rpcPayReq.MissionControlNamespace != "" {
+		payIntent.MissionControl, err = r.missionControl(
+			rpcPayReq.MissionControlNamespace, true,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payIntent.MissionControlNamespace =
+			rpcPayReq.MissionControlNamespace
+	}
+
+	// Bound the duration of the individual route searches, if requested.
+	payIntent.PathFindingTimeout = time.Duration(
+		rpcPayReq.PathfindingTimeoutMs,
+	) * time.Millisecond
+
+	// Pass along the nodes and pairs to exclude from path finding.
+	if len(rpcPayReq.IgnoredNodes) > 0 {
+		payIntent.IgnoredNodes, err = unmarshallIgnoredNodes(
+			rpcPayReq.IgnoredNodes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignored node: %w", err)
+		}
+	}
+	if len(rpcPayReq.IgnoredPairs) > 0 {
+		payIntent.IgnoredPairs, err = unmarshallIgnoredPairs(
+			rpcPayReq.IgnoredPairs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignored pair: %w", err)
+		}
+	}
+
 	// Pass along restrictions on the outgoing channels that may be used.
 	payIntent.OutgoingChannelIDs = rpcPayReq.OutgoingChanIds
 
@@ -802,6 +1442,24 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 		)
 	}
 
+	// Add the weighted outgoing channels to the set of allowed channels.
+	if len(rpcPayReq.OutgoingChanWeights) > 0 {
+		weights, err := unmarshallOutgoingChanWeights(
+			payIntent.OutgoingChannelIDs,
+			rpcPayReq.OutgoingChanWeights,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, w := range rpcPayReq.OutgoingChanWeights {
+			payIntent.OutgoingChannelIDs = append(
+				payIntent.OutgoingChannelIDs, w.ChanId,
+			)
+		}
+		payIntent.OutgoingChannelWeights = weights
+	}
+
 	// Pass along a last hop restriction if specified.
 	if len(rpcPayReq.LastHopPubkey) > 0 {
 		lastHop, err := route.NewVertexFromBytes(
@@ -813,6 +1471,21 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 		payIntent.LastHop = &lastHop
 	}
 
+	// Pass along the last hop candidates if specified.
+	if len(rpcPayReq.LastHopPubkeys) > 0 {
+		if payIntent.LastHop != nil {
+			return nil, errors.New("last_hop_pubkey and " +
+				"last_hop_pubkeys are mutually exclusive")
+		}
+
+		lastHops, err := unmarshallLastHops(rpcPayReq.LastHopPubkeys)
+		if err != nil {
+			return nil, err
+		}
+		payIntent.LastHops = lastHops
+		payIntent.JointLastHops = rpcPayReq.JointLastHops
+	}
+
 	// Take the CLTV limit from the request if set, otherwise use the max.
 	cltvLimit, err := ValidateCLTVLimit(
 		uint32(rpcPayReq.CltvLimit), r.MaxTotalTimelock,
@@ -821,6 +1494,20 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 		return nil, err
 	}
 	payIntent.CltvLimit = cltvLimit
+	payIntent.MaxTotalCltv = rpcPayReq.MaxTotalCltv
+	payIntent.MaxHops = rpcPayReq.MaxHops
+	payIntent.LocalBalanceAware = rpcPayReq.LocalBalanceAware
+
+	if rpcPayReq.MinChannelCapacity < 0 {
+		return nil, errors.New("min_channel_capacity must not be " +
+			"negative")
+	}
+	payIntent.MinChannelCapacity = btcutil.Amount(
+		rpcPayReq.MinChannelCapacity,
+	)
+	payIntent.LatencyPenalty = lnwire.MilliSatoshi(
+		rpcPayReq.LatencyPenaltyMsat,
+	)
 
 	// Attempt to parse the max parts value set by the user, if this value
 	// isn't set, then we'll use the current default value for this
@@ -847,6 +1534,13 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 		return nil, err
 	}
 
+	// A relative fee limit is resolved by the router once the payment is
+	// prepared.
+	payIntent.FeeLimitPolicy, err = unmarshallFeeLimitPolicy(rpcPayReq)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set payment attempt timeout.
 	if rpcPayReq.TimeoutSeconds == 0 {
 		return nil, errors.New("timeout_seconds must be specified")
@@ -1134,9 +1828,83 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 		return nil, errors.New("self-payments not allowed")
 	}
 
+	// Pass along a shard splitter if the payment doesn't use the default.
+	shardSplitter, err := unmarshallSplitStrategy(rpcPayReq.SplitStrategy)
+	if err != nil {
+		return nil, err
+	}
+	payIntent.ShardSplitter = shardSplitter
+
+	// Pass along the route prefix if specified.
+	if len(rpcPayReq.RoutePrefix) > 0 {
+		routePrefix, err := r.unmarshallRoutePrefix(
+			rpcPayReq.RoutePrefix, payIntent.Target,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payIntent.RoutePrefix = routePrefix
+	}
+
 	return payIntent, nil
 }
 
+// unmarshallSplitStrategy returns the shard splitter for the given rpc split
+// strategy, or nil if the default strategy should be used.
+func unmarshallSplitStrategy(strategy SplitStrategy) (routing.ShardSplitter,
+	error) {
+
+	switch strategy {
+	case SplitStrategy_SPLIT_STRATEGY_DEFAULT:
+		return nil, nil
+
+	case SplitStrategy_SPLIT_STRATEGY_HALVING:
+		return &routing.HalvingSplitter{}, nil
+
+	case SplitStrategy_SPLIT_STRATEGY_CAPACITY:
+		return &routing.CapacitySplitter{}, nil
+
+	case SplitStrategy_SPLIT_STRATEGY_PROBABILITY:
+		return &routing.ProbabilitySplitter{}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown split strategy %v", strategy)
+	}
+}
+
+// unmarshallRoutePrefix converts a list of rpc pubkeys into a route prefix.
+// The prefix must neither contain our own node, the target nor any node more
+// than once.
+func (r *RouterBackend) unmarshallRoutePrefix(rpcPrefix [][]byte,
+	target route.Vertex) ([]route.Vertex, error) {
+
+	routePrefix := make([]route.Vertex, 0, len(rpcPrefix))
+	for _, pubKey := range rpcPrefix {
+		node, err := route.NewVertexFromBytes(pubKey)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case node == r.SelfNode:
+			return nil, errors.New("route prefix must not " +
+				"contain our own node")
+
+		case node == target:
+			return nil, errors.New("route prefix must not " +
+				"contain the destination")
+
+		case slices.Contains(routePrefix, node):
+			return nil, fmt.Errorf("node %v specified more than "+
+				"once in route prefix", node)
+		}
+
+		routePrefix = append(routePrefix, node)
+	}
+
+	return routePrefix, nil
+}
+
 // unmarshallRouteHints unmarshalls a list of route hints.
 func unmarshallRouteHints(rpcRouteHints []*lnrpc.RouteHint) (
 	[][]zpay32.HopHint, error) {
@@ -1685,7 +2453,62 @@ func marshallPaymentFailureReason(reason *channeldb.FailureReason) (
 
 	case channeldb.FailureReasonInsufficientBalance:
 		return lnrpc.PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE, nil
+
+	case channeldb.FailureReasonCanceled:
+		return lnrpc.PaymentFailureReason_FAILURE_REASON_CANCELED, nil
 	}
 
 	return 0, errors.New("unknown failure reason")
 }
+
+// UnmarshallEstimator creates a probability estimator from the given rpc
+// estimator config. If no config is given, nil is returned.
+func UnmarshallEstimator(cfg *lnrpc.EstimatorConfig) (routing.Estimator,
+	error) {
+
+	if cfg == nil {
+		return nil, nil
+	}
+
+	switch c := cfg.Config.(type) {
+	case *lnrpc.EstimatorConfig_Apriori:
+		apriori := c.Apriori
+		estimator, err := routing.NewAprioriEstimator(
+			routing.AprioriConfig{
+				PenaltyHalfLife: time.Duration(
+					apriori.HalfLifeSeconds,
+				) * time.Second,
+				AprioriHopProbability: apriori.HopProbability,
+				AprioriWeight:         apriori.Weight,
+				CapacityFraction:      apriori.CapacityFraction,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return estimator, nil
+
+	case *lnrpc.EstimatorConfig_Bimodal:
+		estimator, err := routing.NewBimodalEstimator(
+			routing.BimodalConfig{
+				BimodalNodeWeight: c.Bimodal.NodeWeight,
+				BimodalScaleMsat: lnwire.MilliSatoshi(
+					c.Bimodal.ScaleMsat,
+				),
+				BimodalDecayTime: time.Duration(
+					c.Bimodal.DecayTime,
+				) * time.Second,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return estimator, nil
+
+	default:
+		return nil, errors.New("estimator config must specify either " +
+			"apriori or bimodal parameters")
+	}
+}
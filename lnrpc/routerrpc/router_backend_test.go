@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/lightningnetwork/lnd/lnrpc"
@@ -101,6 +103,10 @@ func testQueryRoutes(t *testing.T, useMissionControl bool, useMsat bool,
 		OutgoingChanId:    outgoingChan,
 		DestFeatures:      []lnrpc.FeatureBit{lnrpc.FeatureBit_MPP_OPT},
 		RouteHints:        rpcRouteHints,
+
+		PathfindingTimeoutMs: 250,
+		MaxHops:              6,
+		MinChannelCapacity:   1000000,
 	}
 
 	amtSat := int64(100000)
@@ -141,6 +147,18 @@ func testQueryRoutes(t *testing.T, useMissionControl bool, useMsat bool,
 			t.Fatal("unexpected fee limit")
 		}
 
+		if restrictions.Timeout != 250*time.Millisecond {
+			t.Fatal("unexpected path finding timeout")
+		}
+
+		if restrictions.MaxHops != 6 {
+			t.Fatal("unexpected max hops")
+		}
+
+		if restrictions.MinChannelCapacity != 1000000 {
+			t.Fatal("unexpected min channel capacity")
+		}
+
 		if restrictions.ProbabilitySource(route.Vertex{2},
 			route.Vertex{1}, 0, 0,
 		) != 0 {
@@ -241,6 +259,290 @@ func testQueryRoutes(t *testing.T, useMissionControl bool, useMsat bool,
 	}
 }
 
+// TestQueryRoutesCircular asserts that circular routes to self require both
+// an outgoing and an incoming channel, which are passed onto path finding.
+func TestQueryRoutesCircular(t *testing.T) {
+	selfKey := hex.EncodeToString(sourceKey[:])
+
+	backend := &RouterBackend{
+		SelfNode:         sourceKey,
+		MaxTotalTimelock: 1000,
+	}
+
+	testCases := []struct {
+		name        string
+		source      string
+		outgoing    uint64
+		incoming    uint64
+		expectedErr string
+	}{{
+		name:     "valid",
+		outgoing: 1,
+		incoming: 2,
+	}, {
+		name:        "missing incoming channel",
+		outgoing:    1,
+		expectedErr: "require outgoing_chan_id and incoming_chan_id",
+	}, {
+		name:        "missing outgoing channel",
+		incoming:    2,
+		expectedErr: "require outgoing_chan_id and incoming_chan_id",
+	}, {
+		name:        "same channel",
+		outgoing:    1,
+		incoming:    1,
+		expectedErr: "must differ",
+	}, {
+		name:        "foreign source",
+		source:      destKey,
+		outgoing:    1,
+		incoming:    2,
+		expectedErr: "only supported from our own node",
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			target := selfKey
+			if tc.source != "" {
+				target = tc.source
+			}
+
+			req, err := backend.parseQueryRoutesRequest(
+				&lnrpc.QueryRoutesRequest{
+					PubKey:         target,
+					SourcePubKey:   tc.source,
+					Amt:            1000,
+					OutgoingChanId: tc.outgoing,
+					IncomingChanId: tc.incoming,
+				},
+			)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			restrictions := req.Restrictions
+			require.Equal(t, sourceKey, req.Source)
+			require.Equal(t, sourceKey, req.Target)
+			require.Equal(
+				t, []uint64{tc.outgoing},
+				restrictions.OutgoingChannelIDs,
+			)
+			require.Equal(
+				t, tc.incoming, restrictions.IncomingChannelID,
+			)
+		})
+	}
+}
+
+// TestQueryRoutesMultiple asserts that multiple distinct routes are returned
+// when requested, preferring node-disjoint routes and ordering them by fees.
+func TestQueryRoutesMultiple(t *testing.T) {
+	var (
+		dest  = route.Vertex{2}
+		hopA  = route.Vertex{20}
+		hopB  = route.Vertex{21}
+		hopC  = route.Vertex{22}
+		found []route.Vertex
+	)
+
+	newRoute := func(source, hop route.Vertex,
+		fee lnwire.MilliSatoshi) *route.Route {
+
+		rt, err := route.NewRouteFromHops(
+			1000+fee, 144, source, []*route.Hop{
+				{
+					PubKeyBytes:  hop,
+					AmtToForward: 1000,
+				},
+				{
+					PubKeyBytes:  dest,
+					AmtToForward: 1000,
+				},
+			},
+		)
+		require.NoError(t, err)
+
+		return rt
+	}
+
+	// The mock path finding returns the first candidate route whose pairs
+	// aren't excluded through the probability source.
+	findRoute := func(req *routing.RouteRequest) (*route.Route, float64,
+		error) {
+
+		probSource := req.Restrictions.ProbabilitySource
+		candidates := []struct {
+			hop  route.Vertex
+			fee  lnwire.MilliSatoshi
+			prob float64
+		}{
+			{hopA, 30, 0.9},
+			{hopB, 10, 0.8},
+			{hopC, 20, 0.7},
+		}
+
+		for _, c := range candidates {
+			if probSource(req.Source, c.hop, 0, 0) == 0 ||
+				probSource(c.hop, dest, 0, 0) == 0 {
+
+				continue
+			}
+
+			found = append(found, c.hop)
+
+			return newRoute(req.Source, c.hop, c.fee), c.prob, nil
+		}
+
+		return nil, 0, errors.New("no route")
+	}
+
+	backend := &RouterBackend{
+		FindRoute:        findRoute,
+		SelfNode:         sourceKey,
+		MaxTotalTimelock: 1000,
+		FetchChannelCapacity: func(chanID uint64) (
+			btcutil.Amount, error) {
+
+			return 1, nil
+		},
+		MissionControl: &mockMissionControl{},
+	}
+
+	request := &lnrpc.QueryRoutesRequest{
+		PubKey:    hex.EncodeToString(dest[:]),
+		Amt:       1,
+		NumRoutes: 5,
+	}
+
+	// With no route left after the third one, only three routes are
+	// returned, ordered by their fees.
+	resp, err := backend.QueryRoutes(context.Background(), request)
+	require.NoError(t, err)
+	require.Equal(t, []route.Vertex{hopA, hopB, hopC}, found)
+	require.Len(t, resp.Routes, 3)
+	require.EqualValues(t, 10, resp.Routes[0].TotalFeesMsat)
+	require.EqualValues(t, 20, resp.Routes[1].TotalFeesMsat)
+	require.EqualValues(t, 30, resp.Routes[2].TotalFeesMsat)
+	require.Equal(t, []float64{0.8, 0.7, 0.9}, resp.SuccessProbs)
+	require.Equal(t, 0.8, resp.SuccessProb)
+
+	// Requesting too many routes is rejected.
+	request.NumRoutes = MaxNumRoutes + 1
+	_, err = backend.QueryRoutes(context.Background(), request)
+	require.Error(t, err)
+}
+
+// TestQueryRoutesDiverse asserts that the returned routes diverge from the
+// route to avoid as far as requested.
+func TestQueryRoutesDiverse(t *testing.T) {
+	var (
+		dest = route.Vertex{2}
+		hopA = route.Vertex{20}
+		hopB = route.Vertex{21}
+	)
+
+	// The route to avoid goes through hop A over channels 1 and 2.
+	endpoints := map[uint64][2]route.Vertex{
+		1: {sourceKey, hopA},
+		2: {hopA, dest},
+	}
+
+	// The mock path finding returns the first candidate route whose pairs
+	// aren't excluded through the probability source. The first candidate
+	// shares hop A with the route to avoid, but not its channels.
+	findRoute := func(req *routing.RouteRequest) (*route.Route, float64,
+		error) {
+
+		probSource := req.Restrictions.ProbabilitySource
+		candidates := []struct {
+			hop     route.Vertex
+			chanIDs [2]uint64
+		}{
+			{hopA, [2]uint64{10, 11}},
+			{hopB, [2]uint64{3, 4}},
+		}
+
+		for _, c := range candidates {
+			if probSource(req.Source, c.hop, 0, 0) == 0 ||
+				probSource(c.hop, dest, 0, 0) == 0 {
+
+				continue
+			}
+
+			rt, err := route.NewRouteFromHops(
+				1000, 144, req.Source, []*route.Hop{
+					{
+						PubKeyBytes:  c.hop,
+						ChannelID:    c.chanIDs[0],
+						AmtToForward: 1000,
+					},
+					{
+						PubKeyBytes:  dest,
+						ChannelID:    c.chanIDs[1],
+						AmtToForward: 1000,
+					},
+				},
+			)
+			require.NoError(t, err)
+
+			return rt, 1, nil
+		}
+
+		return nil, 0, errors.New("no route")
+	}
+
+	backend := &RouterBackend{
+		FindRoute:        findRoute,
+		SelfNode:         sourceKey,
+		MaxTotalTimelock: 1000,
+		FetchChannelCapacity: func(chanID uint64) (
+			btcutil.Amount, error) {
+
+			return 1, nil
+		},
+		FetchChannelEndpoints: func(chanID uint64) (route.Vertex,
+			route.Vertex, error) {
+
+			nodes, ok := endpoints[chanID]
+			if !ok {
+				return route.Vertex{}, route.Vertex{},
+					errors.New("unknown channel")
+			}
+
+			return nodes[0], nodes[1], nil
+		},
+		MissionControl: &mockMissionControl{},
+	}
+
+	request := &lnrpc.QueryRoutesRequest{
+		PubKey:            hex.EncodeToString(dest[:]),
+		Amt:               1,
+		AvoidRouteChanIds: []uint64{1, 2},
+	}
+
+	// Without any overlap allowed, hop A is excluded.
+	resp, err := backend.QueryRoutes(context.Background(), request)
+	require.NoError(t, err)
+	require.Len(t, resp.Routes, 1)
+	require.EqualValues(t, 3, resp.Routes[0].Hops[0].ChanId)
+
+	// Sharing hop A is an overlap of one third of the avoided route,
+	// which is acceptable if half of the route may be shared.
+	request.MaxRouteOverlap = 0.5
+	resp, err = backend.QueryRoutes(context.Background(), request)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, resp.Routes[0].Hops[0].ChanId)
+
+	// Overlaps outside of [0, 1] are rejected.
+	request.MaxRouteOverlap = 1.5
+	_, err = backend.QueryRoutes(context.Background(), request)
+	require.Error(t, err)
+}
+
 type mockMissionControl struct {
 	MissionControl
 }
@@ -279,6 +581,85 @@ type unmarshalMPPTest struct {
 	outcome recordParseOutcome
 }
 
+// TestUnmarshallEstimator checks that rpc estimator configs are converted into
+// the corresponding probability estimators.
+func TestUnmarshallEstimator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cfg          *lnrpc.EstimatorConfig
+		expectedType routing.Estimator
+		expectErr    bool
+	}{
+		{
+			name: "no config",
+		},
+		{
+			name: "apriori",
+			cfg: &lnrpc.EstimatorConfig{
+				Config: &lnrpc.EstimatorConfig_Apriori{
+					Apriori: &lnrpc.AprioriEstimatorConfig{
+						HalfLifeSeconds:  3600,
+						HopProbability:   0.6,
+						Weight:           0.5,
+						CapacityFraction: 0.9999,
+					},
+				},
+			},
+			expectedType: &routing.AprioriEstimator{},
+		},
+		{
+			name: "invalid apriori",
+			cfg: &lnrpc.EstimatorConfig{
+				Config: &lnrpc.EstimatorConfig_Apriori{
+					Apriori: &lnrpc.AprioriEstimatorConfig{
+						HopProbability: 2,
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "bimodal",
+			cfg: &lnrpc.EstimatorConfig{
+				Config: &lnrpc.EstimatorConfig_Bimodal{
+					Bimodal: &lnrpc.BimodalEstimatorConfig{
+						NodeWeight: 0.2,
+						ScaleMsat:  300_000_000,
+						DecayTime:  3600,
+					},
+				},
+			},
+			expectedType: &routing.BimodalEstimator{},
+		},
+		{
+			name:      "empty config",
+			cfg:       &lnrpc.EstimatorConfig{},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			estimator, err := UnmarshallEstimator(test.cfg)
+			if test.expectErr {
+				require.Error(t, err)
+				require.Nil(t, estimator)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.IsType(t, test.expectedType, estimator)
+		})
+	}
+}
+
 // TestUnmarshalMPP checks both positive and negative cases of UnmarshalMPP to
 // assert that an MPP record is only returned when both fields are properly
 // specified. It also asserts that zero-values for both inputs is also valid,
@@ -475,3 +856,136 @@ func testUnmarshalAMP(t *testing.T, test unmarshalAMPTest) {
 		t.Fatalf("test case has non-standard outcome")
 	}
 }
+
+// TestMissionControlNamespaceLookup tests that mission control namespaces are
+// resolved correctly and that unknown namespaces are only created if
+// requested.
+func TestMissionControlNamespaceLookup(t *testing.T) {
+	t.Parallel()
+
+	defaultMc := &mockMissionControl{}
+	namespaces := map[string]MissionControl{
+		"rebalance": &mockMissionControl{},
+	}
+
+	backend := &RouterBackend{
+		MissionControl: defaultMc,
+		GetMissionControl: func(namespace string) (MissionControl,
+			error) {
+
+			if _, ok := namespaces[namespace]; !ok {
+				namespaces[namespace] = &mockMissionControl{}
+			}
+
+			return namespaces[namespace], nil
+		},
+		ListMissionControlNamespaces: func() []string {
+			names := []string{
+				routing.DefaultMissionControlNamespace,
+			}
+			for name := range namespaces {
+				names = append(names, name)
+			}
+
+			return names
+		},
+	}
+
+	// Both the empty and the explicit default namespace resolve to the
+	// default mission control.
+	mc, err := backend.missionControl("", false)
+	require.NoError(t, err)
+	require.Same(t, defaultMc, mc)
+
+	mc, err = backend.missionControl(
+		routing.DefaultMissionControlNamespace, false,
+	)
+	require.NoError(t, err)
+	require.Same(t, defaultMc, mc)
+
+	// Existing namespaces are found without creating them.
+	mc, err = backend.missionControl("rebalance", false)
+	require.NoError(t, err)
+	require.Same(t, namespaces["rebalance"], mc)
+
+	// Unknown namespaces are only created if requested.
+	_, err = backend.missionControl("wallet", false)
+	require.ErrorContains(t, err, "unknown mission control namespace")
+	require.NotContains(t, namespaces, "wallet")
+
+	mc, err = backend.missionControl("wallet", true)
+	require.NoError(t, err)
+	require.Same(t, namespaces["wallet"], mc)
+}
+
+// TestUnmarshallOutgoingChanWeights tests the conversion of rpc outgoing
+// channel weights into relative weights.
+func TestUnmarshallOutgoingChanWeights(t *testing.T) {
+	t.Parallel()
+
+	weights, err := unmarshallOutgoingChanWeights(
+		[]uint64{1}, []*OutgoingChannelWeight{
+			{ChanId: 2, Weight: 4},
+			{ChanId: 3, Weight: 1},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[uint64]float64{1: 1, 2: 1, 3: 0.25}, weights)
+
+	// Weights must be positive.
+	_, err = unmarshallOutgoingChanWeights(
+		nil, []*OutgoingChannelWeight{{ChanId: 2, Weight: 0}},
+	)
+	require.ErrorContains(t, err, "weights must be positive")
+
+	// A channel can't be both unweighted and weighted.
+	_, err = unmarshallOutgoingChanWeights(
+		[]uint64{2}, []*OutgoingChannelWeight{{ChanId: 2, Weight: 1}},
+	)
+	require.ErrorContains(t, err, "specified more than once")
+}
+
+// TestUnmarshallFeeLimitPolicy tests the conversion of a relative rpc fee
+// limit.
+func TestUnmarshallFeeLimitPolicy(t *testing.T) {
+	t.Parallel()
+
+	// Without any of the relative fields, there is no policy.
+	policy, err := unmarshallFeeLimitPolicy(
+		&SendPaymentRequest{FeeLimitSat: 10},
+	)
+	require.NoError(t, err)
+	require.Nil(t, policy)
+
+	policy, err = unmarshallFeeLimitPolicy(&SendPaymentRequest{
+		FeeLimitPpm:     1000,
+		FeeLimitMinMsat: 2000,
+		FeeLimitMaxMsat: 50_000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &routing.FeeLimitPolicy{
+		PPM: 1000,
+		Min: 2000,
+		Max: 50_000,
+	}, policy)
+
+	// The relative fee limit can't be combined with an absolute one.
+	_, err = unmarshallFeeLimitPolicy(&SendPaymentRequest{
+		FeeLimitPpm:  1000,
+		FeeLimitMsat: 2000,
+	})
+	require.ErrorContains(t, err, "can't be combined")
+
+	// The bounds must be consistent.
+	_, err = unmarshallFeeLimitPolicy(&SendPaymentRequest{
+		FeeLimitPpm:     1000,
+		FeeLimitMinMsat: 2000,
+		FeeLimitMaxMsat: 1000,
+	})
+	require.ErrorContains(t, err, "below minimum fee limit")
+
+	_, err = unmarshallFeeLimitPolicy(&SendPaymentRequest{
+		FeeLimitMinMsat: -1,
+	})
+	require.ErrorContains(t, err, "must not be negative")
+}
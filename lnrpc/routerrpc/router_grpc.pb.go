@@ -37,6 +37,26 @@ type RouterClient interface {
 	// payment attempt make sure to subscribe to this method before initiating any
 	// payments.
 	TrackPayments(ctx context.Context, in *TrackPaymentsRequest, opts ...grpc.CallOption) (Router_TrackPaymentsClient, error)
+	// lncli: `cancelpayment`
+	// CancelPayment stops an in-flight payment from launching new HTLC attempts.
+	// The attempts that are still in flight are left to resolve, after which the
+	// payment fails with FAILURE_REASON_CANCELED. A payment that already has a
+	// settled HTLC can't be canceled anymore, because the receiver revealed the
+	// preimage and is expected to settle the remaining HTLCs too.
+	CancelPayment(ctx context.Context, in *CancelPaymentRequest, opts ...grpc.CallOption) (*CancelPaymentResponse, error)
+	// lncli: `updatepaymentlimits`
+	// UpdatePaymentLimits adjusts the limits of a payment that is currently in
+	// flight. The new limits are applied before the next HTLC attempt is
+	// launched, attempts that are already in flight are not affected. Together
+	// with TrackPaymentV2, this allows a client that lost its SendPaymentV2
+	// stream to reattach to the payment and to take control of it again.
+	UpdatePaymentLimits(ctx context.Context, in *UpdatePaymentLimitsRequest, opts ...grpc.CallOption) (*UpdatePaymentLimitsResponse, error)
+	// lncli: `abandonstalepayments`
+	// AbandonStalePayments fails all payments that are in flight without any
+	// outstanding HTLCs and were created before the given minimum age. Such
+	// payments can't make progress on their own anymore. Payments that are
+	// currently sent are left alone.
+	AbandonStalePayments(ctx context.Context, in *AbandonStalePaymentsRequest, opts ...grpc.CallOption) (*AbandonStalePaymentsResponse, error)
 	// EstimateRouteFee allows callers to obtain a lower bound w.r.t how much it
 	// may cost to send an HTLC to the target end destination.
 	EstimateRouteFee(ctx context.Context, in *RouteFeeRequest, opts ...grpc.CallOption) (*RouteFeeResponse, error)
@@ -57,10 +77,25 @@ type RouterClient interface {
 	// ResetMissionControl clears all mission control state and starts with a clean
 	// slate.
 	ResetMissionControl(ctx context.Context, in *ResetMissionControlRequest, opts ...grpc.CallOption) (*ResetMissionControlResponse, error)
+	// lncli: `resetmcpairs`
+	// ResetMissionControlPairs clears the mission control state of the given node
+	// pairs and of all pairs that have one of the given nodes as their source or
+	// destination. The state of all other pairs is preserved.
+	ResetMissionControlPairs(ctx context.Context, in *ResetMissionControlPairsRequest, opts ...grpc.CallOption) (*ResetMissionControlPairsResponse, error)
 	// lncli: `querymc`
 	// QueryMissionControl exposes the internal mission control state to callers.
 	// It is a development feature.
 	QueryMissionControl(ctx context.Context, in *QueryMissionControlRequest, opts ...grpc.CallOption) (*QueryMissionControlResponse, error)
+	// lncli: `listmcnamespaces`
+	// ListMissionControlNamespaces returns the names of all mission control
+	// namespaces. Every namespace keeps its own isolated history of payment
+	// results.
+	ListMissionControlNamespaces(ctx context.Context, in *ListMissionControlNamespacesRequest, opts ...grpc.CallOption) (*ListMissionControlNamespacesResponse, error)
+	// lncli: `getproberstats`
+	// GetProberStats returns the statistics of the background payment prober
+	// that periodically probes the configured destinations to keep mission
+	// control up to date.
+	GetProberStats(ctx context.Context, in *GetProberStatsRequest, opts ...grpc.CallOption) (*GetProberStatsResponse, error)
 	// lncli: `importmc`
 	// XImportMissionControl is an experimental API that imports the state provided
 	// to the internal mission control's state, using all results which are more
@@ -74,12 +109,32 @@ type RouterClient interface {
 	// SetMissionControlConfig will set mission control's config, if the config
 	// provided is valid.
 	SetMissionControlConfig(ctx context.Context, in *SetMissionControlConfigRequest, opts ...grpc.CallOption) (*SetMissionControlConfigResponse, error)
+	// lncli: `subscribemccfg`
+	// SubscribeMissionControlConfig streams mission control's config every time
+	// it is changed with SetMissionControlConfig. The currently active config is
+	// sent first.
+	SubscribeMissionControlConfig(ctx context.Context, in *SubscribeMissionControlConfigRequest, opts ...grpc.CallOption) (Router_SubscribeMissionControlConfigClient, error)
+	// lncli: `setmcpairoverrides`
+	// SetBimodalPairOverrides sets bimodal estimator parameters for single node
+	// pairs that replace the global ones, for example for channels to known
+	// liquidity drains or sources. The overrides only apply if the bimodal
+	// estimator is used and aren't persisted across restarts.
+	SetBimodalPairOverrides(ctx context.Context, in *SetBimodalPairOverridesRequest, opts ...grpc.CallOption) (*SetBimodalPairOverridesResponse, error)
+	// lncli: `listmcpairoverrides`
+	// ListBimodalPairOverrides returns the bimodal estimator parameters that are
+	// set for single node pairs.
+	ListBimodalPairOverrides(ctx context.Context, in *ListBimodalPairOverridesRequest, opts ...grpc.CallOption) (*ListBimodalPairOverridesResponse, error)
 	// lncli: `queryprob`
 	// Deprecated. QueryProbability returns the current success probability
 	// estimate for a given node pair and amount. The call returns a zero success
 	// probability if no channel is available or if the amount violates min/max
 	// HTLC constraints.
 	QueryProbability(ctx context.Context, in *QueryProbabilityRequest, opts ...grpc.CallOption) (*QueryProbabilityResponse, error)
+	// lncli: `queryrouteprob`
+	// QueryRouteProbability returns the current end-to-end success probability
+	// estimate for the given route. Node pairs that are used more than once are
+	// conditioned on the amounts of their earlier uses.
+	QueryRouteProbability(ctx context.Context, in *QueryRouteProbabilityRequest, opts ...grpc.CallOption) (*QueryRouteProbabilityResponse, error)
 	// lncli: `buildroute`
 	// BuildRoute builds a fully specified route based on a list of hop public
 	// keys. It retrieves the relevant channel policies from the graph in order to
@@ -116,6 +171,47 @@ type RouterClient interface {
 	// channel to stay disabled until a subsequent manual request of either
 	// "enable" or "auto".
 	UpdateChanStatus(ctx context.Context, in *UpdateChanStatusRequest, opts ...grpc.CallOption) (*UpdateChanStatusResponse, error)
+	// lncli: `listzombiechannels`
+	// ListZombieChannels lists all channels of the zombie index. Zombie channels
+	// are ignored by path finding and their updates are rejected.
+	ListZombieChannels(ctx context.Context, in *ListZombieChannelsRequest, opts ...grpc.CallOption) (*ListZombieChannelsResponse, error)
+	// lncli: `resurrectzombiechannel`
+	// ResurrectZombieChannel removes a channel from the zombie index. This is
+	// useful if a live channel was falsely marked as a zombie and is therefore
+	// hidden from path finding. If the channel was removed from the graph, it is
+	// added back, unless its funding output was spent.
+	ResurrectZombieChannel(ctx context.Context, in *ResurrectZombieChannelRequest, opts ...grpc.CallOption) (*ResurrectZombieChannelResponse, error)
+	// lncli: `prunezombiechannels`
+	// PruneZombieChannels examines the graph for zombie channels and prunes them
+	// right away instead of waiting for the next prune interval.
+	PruneZombieChannels(ctx context.Context, in *PruneZombieChannelsRequest, opts ...grpc.CallOption) (*PruneZombieChannelsResponse, error)
+	// lncli: `importnodescores`
+	// ImportNodeScores imports per-node scores from an external source, for
+	// example a scoring oracle. If enabled with routerrpc.usenodescores, path
+	// finding multiplies the success probability of a channel with the score of
+	// the node that forwards over it. If several sources score a node, the lowest
+	// unexpired score is used.
+	ImportNodeScores(ctx context.Context, in *ImportNodeScoresRequest, opts ...grpc.CallOption) (*ImportNodeScoresResponse, error)
+	// lncli: `querynodescores`
+	// QueryNodeScores returns all imported node scores that didn't expire yet
+	// along with their source.
+	QueryNodeScores(ctx context.Context, in *QueryNodeScoresRequest, opts ...grpc.CallOption) (*QueryNodeScoresResponse, error)
+	// lncli: `sendpaymentbatch`
+	// SendPaymentBatch pays multiple payment requests with a routing fee budget
+	// and a timeout that are shared by all payments of the batch. The payments
+	// are sent with bounded concurrency and the final result of every payment is
+	// returned on the stream as soon as it is known. Every payment is limited to
+	// a share of the unused budget that is proportional to its amount, and the
+	// fees that a finished payment didn't spend are made available to the
+	// payments that are started after it.
+	SendPaymentBatch(ctx context.Context, in *SendPaymentBatchRequest, opts ...grpc.CallOption) (Router_SendPaymentBatchClient, error)
+	// lncli: `subscribehtlcattempts`
+	// SubscribeHtlcAttempts returns a stream that delivers an event whenever an
+	// HTLC attempt of any payment is launched, settled or failed. Failure events
+	// carry the index of the node that reported the failure and the decoded
+	// failure message. At the start of the stream, the current state of the
+	// attempts of all payments that are in flight is delivered.
+	SubscribeHtlcAttempts(ctx context.Context, in *SubscribeHtlcAttemptsRequest, opts ...grpc.CallOption) (Router_SubscribeHtlcAttemptsClient, error)
 }
 
 type routerClient struct {
@@ -222,6 +318,33 @@ func (x *routerTrackPaymentsClient) Recv() (*lnrpc.Payment, error) {
 	return m, nil
 }
 
+func (c *routerClient) CancelPayment(ctx context.Context, in *CancelPaymentRequest, opts ...grpc.CallOption) (*CancelPaymentResponse, error) {
+	out := new(CancelPaymentResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/CancelPayment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) UpdatePaymentLimits(ctx context.Context, in *UpdatePaymentLimitsRequest, opts ...grpc.CallOption) (*UpdatePaymentLimitsResponse, error) {
+	out := new(UpdatePaymentLimitsResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/UpdatePaymentLimits", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) AbandonStalePayments(ctx context.Context, in *AbandonStalePaymentsRequest, opts ...grpc.CallOption) (*AbandonStalePaymentsResponse, error) {
+	out := new(AbandonStalePaymentsResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/AbandonStalePayments", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *routerClient) EstimateRouteFee(ctx context.Context, in *RouteFeeRequest, opts ...grpc.CallOption) (*RouteFeeResponse, error) {
 	out := new(RouteFeeResponse)
 	err := c.cc.Invoke(ctx, "/routerrpc.Router/EstimateRouteFee", in, out, opts...)
@@ -259,6 +382,15 @@ func (c *routerClient) ResetMissionControl(ctx context.Context, in *ResetMission
 	return out, nil
 }
 
+func (c *routerClient) ResetMissionControlPairs(ctx context.Context, in *ResetMissionControlPairsRequest, opts ...grpc.CallOption) (*ResetMissionControlPairsResponse, error) {
+	out := new(ResetMissionControlPairsResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/ResetMissionControlPairs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *routerClient) QueryMissionControl(ctx context.Context, in *QueryMissionControlRequest, opts ...grpc.CallOption) (*QueryMissionControlResponse, error) {
 	out := new(QueryMissionControlResponse)
 	err := c.cc.Invoke(ctx, "/routerrpc.Router/QueryMissionControl", in, out, opts...)
@@ -268,6 +400,24 @@ func (c *routerClient) QueryMissionControl(ctx context.Context, in *QueryMission
 	return out, nil
 }
 
+func (c *routerClient) ListMissionControlNamespaces(ctx context.Context, in *ListMissionControlNamespacesRequest, opts ...grpc.CallOption) (*ListMissionControlNamespacesResponse, error) {
+	out := new(ListMissionControlNamespacesResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/ListMissionControlNamespaces", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) GetProberStats(ctx context.Context, in *GetProberStatsRequest, opts ...grpc.CallOption) (*GetProberStatsResponse, error) {
+	out := new(GetProberStatsResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/GetProberStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *routerClient) XImportMissionControl(ctx context.Context, in *XImportMissionControlRequest, opts ...grpc.CallOption) (*XImportMissionControlResponse, error) {
 	out := new(XImportMissionControlResponse)
 	err := c.cc.Invoke(ctx, "/routerrpc.Router/XImportMissionControl", in, out, opts...)
@@ -295,6 +445,56 @@ func (c *routerClient) SetMissionControlConfig(ctx context.Context, in *SetMissi
 	return out, nil
 }
 
+func (c *routerClient) SubscribeMissionControlConfig(ctx context.Context, in *SubscribeMissionControlConfigRequest, opts ...grpc.CallOption) (Router_SubscribeMissionControlConfigClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[3], "/routerrpc.Router/SubscribeMissionControlConfig", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerSubscribeMissionControlConfigClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Router_SubscribeMissionControlConfigClient interface {
+	Recv() (*MissionControlConfigEvent, error)
+	grpc.ClientStream
+}
+
+type routerSubscribeMissionControlConfigClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerSubscribeMissionControlConfigClient) Recv() (*MissionControlConfigEvent, error) {
+	m := new(MissionControlConfigEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *routerClient) SetBimodalPairOverrides(ctx context.Context, in *SetBimodalPairOverridesRequest, opts ...grpc.CallOption) (*SetBimodalPairOverridesResponse, error) {
+	out := new(SetBimodalPairOverridesResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/SetBimodalPairOverrides", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) ListBimodalPairOverrides(ctx context.Context, in *ListBimodalPairOverridesRequest, opts ...grpc.CallOption) (*ListBimodalPairOverridesResponse, error) {
+	out := new(ListBimodalPairOverridesResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/ListBimodalPairOverrides", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *routerClient) QueryProbability(ctx context.Context, in *QueryProbabilityRequest, opts ...grpc.CallOption) (*QueryProbabilityResponse, error) {
 	out := new(QueryProbabilityResponse)
 	err := c.cc.Invoke(ctx, "/routerrpc.Router/QueryProbability", in, out, opts...)
@@ -304,6 +504,15 @@ func (c *routerClient) QueryProbability(ctx context.Context, in *QueryProbabilit
 	return out, nil
 }
 
+func (c *routerClient) QueryRouteProbability(ctx context.Context, in *QueryRouteProbabilityRequest, opts ...grpc.CallOption) (*QueryRouteProbabilityResponse, error) {
+	out := new(QueryRouteProbabilityResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/QueryRouteProbability", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *routerClient) BuildRoute(ctx context.Context, in *BuildRouteRequest, opts ...grpc.CallOption) (*BuildRouteResponse, error) {
 	out := new(BuildRouteResponse)
 	err := c.cc.Invoke(ctx, "/routerrpc.Router/BuildRoute", in, out, opts...)
@@ -314,7 +523,7 @@ func (c *routerClient) BuildRoute(ctx context.Context, in *BuildRouteRequest, op
 }
 
 func (c *routerClient) SubscribeHtlcEvents(ctx context.Context, in *SubscribeHtlcEventsRequest, opts ...grpc.CallOption) (Router_SubscribeHtlcEventsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[3], "/routerrpc.Router/SubscribeHtlcEvents", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[4], "/routerrpc.Router/SubscribeHtlcEvents", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -347,7 +556,7 @@ func (x *routerSubscribeHtlcEventsClient) Recv() (*HtlcEvent, error) {
 
 // Deprecated: Do not use.
 func (c *routerClient) SendPayment(ctx context.Context, in *SendPaymentRequest, opts ...grpc.CallOption) (Router_SendPaymentClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[4], "/routerrpc.Router/SendPayment", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[5], "/routerrpc.Router/SendPayment", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -380,7 +589,7 @@ func (x *routerSendPaymentClient) Recv() (*PaymentStatus, error) {
 
 // Deprecated: Do not use.
 func (c *routerClient) TrackPayment(ctx context.Context, in *TrackPaymentRequest, opts ...grpc.CallOption) (Router_TrackPaymentClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[5], "/routerrpc.Router/TrackPayment", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[6], "/routerrpc.Router/TrackPayment", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -412,7 +621,7 @@ func (x *routerTrackPaymentClient) Recv() (*PaymentStatus, error) {
 }
 
 func (c *routerClient) HtlcInterceptor(ctx context.Context, opts ...grpc.CallOption) (Router_HtlcInterceptorClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[6], "/routerrpc.Router/HtlcInterceptor", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[7], "/routerrpc.Router/HtlcInterceptor", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -451,6 +660,115 @@ func (c *routerClient) UpdateChanStatus(ctx context.Context, in *UpdateChanStatu
 	return out, nil
 }
 
+func (c *routerClient) ListZombieChannels(ctx context.Context, in *ListZombieChannelsRequest, opts ...grpc.CallOption) (*ListZombieChannelsResponse, error) {
+	out := new(ListZombieChannelsResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/ListZombieChannels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) ResurrectZombieChannel(ctx context.Context, in *ResurrectZombieChannelRequest, opts ...grpc.CallOption) (*ResurrectZombieChannelResponse, error) {
+	out := new(ResurrectZombieChannelResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/ResurrectZombieChannel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) PruneZombieChannels(ctx context.Context, in *PruneZombieChannelsRequest, opts ...grpc.CallOption) (*PruneZombieChannelsResponse, error) {
+	out := new(PruneZombieChannelsResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/PruneZombieChannels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) ImportNodeScores(ctx context.Context, in *ImportNodeScoresRequest, opts ...grpc.CallOption) (*ImportNodeScoresResponse, error) {
+	out := new(ImportNodeScoresResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/ImportNodeScores", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) QueryNodeScores(ctx context.Context, in *QueryNodeScoresRequest, opts ...grpc.CallOption) (*QueryNodeScoresResponse, error) {
+	out := new(QueryNodeScoresResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/QueryNodeScores", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) SendPaymentBatch(ctx context.Context, in *SendPaymentBatchRequest, opts ...grpc.CallOption) (Router_SendPaymentBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[8], "/routerrpc.Router/SendPaymentBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerSendPaymentBatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Router_SendPaymentBatchClient interface {
+	Recv() (*BatchPaymentResult, error)
+	grpc.ClientStream
+}
+
+type routerSendPaymentBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerSendPaymentBatchClient) Recv() (*BatchPaymentResult, error) {
+	m := new(BatchPaymentResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *routerClient) SubscribeHtlcAttempts(ctx context.Context, in *SubscribeHtlcAttemptsRequest, opts ...grpc.CallOption) (Router_SubscribeHtlcAttemptsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[9], "/routerrpc.Router/SubscribeHtlcAttempts", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerSubscribeHtlcAttemptsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Router_SubscribeHtlcAttemptsClient interface {
+	Recv() (*HtlcAttemptEvent, error)
+	grpc.ClientStream
+}
+
+type routerSubscribeHtlcAttemptsClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerSubscribeHtlcAttemptsClient) Recv() (*HtlcAttemptEvent, error) {
+	m := new(HtlcAttemptEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RouterServer is the server API for Router service.
 // All implementations must embed UnimplementedRouterServer
 // for forward compatibility
@@ -473,6 +791,26 @@ type RouterServer interface {
 	// payment attempt make sure to subscribe to this method before initiating any
 	// payments.
 	TrackPayments(*TrackPaymentsRequest, Router_TrackPaymentsServer) error
+	// lncli: `cancelpayment`
+	// CancelPayment stops an in-flight payment from launching new HTLC attempts.
+	// The attempts that are still in flight are left to resolve, after which the
+	// payment fails with FAILURE_REASON_CANCELED. A payment that already has a
+	// settled HTLC can't be canceled anymore, because the receiver revealed the
+	// preimage and is expected to settle the remaining HTLCs too.
+	CancelPayment(context.Context, *CancelPaymentRequest) (*CancelPaymentResponse, error)
+	// lncli: `updatepaymentlimits`
+	// UpdatePaymentLimits adjusts the limits of a payment that is currently in
+	// flight. The new limits are applied before the next HTLC attempt is
+	// launched, attempts that are already in flight are not affected. Together
+	// with TrackPaymentV2, this allows a client that lost its SendPaymentV2
+	// stream to reattach to the payment and to take control of it again.
+	UpdatePaymentLimits(context.Context, *UpdatePaymentLimitsRequest) (*UpdatePaymentLimitsResponse, error)
+	// lncli: `abandonstalepayments`
+	// AbandonStalePayments fails all payments that are in flight without any
+	// outstanding HTLCs and were created before the given minimum age. Such
+	// payments can't make progress on their own anymore. Payments that are
+	// currently sent are left alone.
+	AbandonStalePayments(context.Context, *AbandonStalePaymentsRequest) (*AbandonStalePaymentsResponse, error)
 	// EstimateRouteFee allows callers to obtain a lower bound w.r.t how much it
 	// may cost to send an HTLC to the target end destination.
 	EstimateRouteFee(context.Context, *RouteFeeRequest) (*RouteFeeResponse, error)
@@ -493,10 +831,25 @@ type RouterServer interface {
 	// ResetMissionControl clears all mission control state and starts with a clean
 	// slate.
 	ResetMissionControl(context.Context, *ResetMissionControlRequest) (*ResetMissionControlResponse, error)
+	// lncli: `resetmcpairs`
+	// ResetMissionControlPairs clears the mission control state of the given node
+	// pairs and of all pairs that have one of the given nodes as their source or
+	// destination. The state of all other pairs is preserved.
+	ResetMissionControlPairs(context.Context, *ResetMissionControlPairsRequest) (*ResetMissionControlPairsResponse, error)
 	// lncli: `querymc`
 	// QueryMissionControl exposes the internal mission control state to callers.
 	// It is a development feature.
 	QueryMissionControl(context.Context, *QueryMissionControlRequest) (*QueryMissionControlResponse, error)
+	// lncli: `listmcnamespaces`
+	// ListMissionControlNamespaces returns the names of all mission control
+	// namespaces. Every namespace keeps its own isolated history of payment
+	// results.
+	ListMissionControlNamespaces(context.Context, *ListMissionControlNamespacesRequest) (*ListMissionControlNamespacesResponse, error)
+	// lncli: `getproberstats`
+	// GetProberStats returns the statistics of the background payment prober
+	// that periodically probes the configured destinations to keep mission
+	// control up to date.
+	GetProberStats(context.Context, *GetProberStatsRequest) (*GetProberStatsResponse, error)
 	// lncli: `importmc`
 	// XImportMissionControl is an experimental API that imports the state provided
 	// to the internal mission control's state, using all results which are more
@@ -510,12 +863,32 @@ type RouterServer interface {
 	// SetMissionControlConfig will set mission control's config, if the config
 	// provided is valid.
 	SetMissionControlConfig(context.Context, *SetMissionControlConfigRequest) (*SetMissionControlConfigResponse, error)
+	// lncli: `subscribemccfg`
+	// SubscribeMissionControlConfig streams mission control's config every time
+	// it is changed with SetMissionControlConfig. The currently active config is
+	// sent first.
+	SubscribeMissionControlConfig(*SubscribeMissionControlConfigRequest, Router_SubscribeMissionControlConfigServer) error
+	// lncli: `setmcpairoverrides`
+	// SetBimodalPairOverrides sets bimodal estimator parameters for single node
+	// pairs that replace the global ones, for example for channels to known
+	// liquidity drains or sources. The overrides only apply if the bimodal
+	// estimator is used and aren't persisted across restarts.
+	SetBimodalPairOverrides(context.Context, *SetBimodalPairOverridesRequest) (*SetBimodalPairOverridesResponse, error)
+	// lncli: `listmcpairoverrides`
+	// ListBimodalPairOverrides returns the bimodal estimator parameters that are
+	// set for single node pairs.
+	ListBimodalPairOverrides(context.Context, *ListBimodalPairOverridesRequest) (*ListBimodalPairOverridesResponse, error)
 	// lncli: `queryprob`
 	// Deprecated. QueryProbability returns the current success probability
 	// estimate for a given node pair and amount. The call returns a zero success
 	// probability if no channel is available or if the amount violates min/max
 	// HTLC constraints.
 	QueryProbability(context.Context, *QueryProbabilityRequest) (*QueryProbabilityResponse, error)
+	// lncli: `queryrouteprob`
+	// QueryRouteProbability returns the current end-to-end success probability
+	// estimate for the given route. Node pairs that are used more than once are
+	// conditioned on the amounts of their earlier uses.
+	QueryRouteProbability(context.Context, *QueryRouteProbabilityRequest) (*QueryRouteProbabilityResponse, error)
 	// lncli: `buildroute`
 	// BuildRoute builds a fully specified route based on a list of hop public
 	// keys. It retrieves the relevant channel policies from the graph in order to
@@ -552,6 +925,47 @@ type RouterServer interface {
 	// channel to stay disabled until a subsequent manual request of either
 	// "enable" or "auto".
 	UpdateChanStatus(context.Context, *UpdateChanStatusRequest) (*UpdateChanStatusResponse, error)
+	// lncli: `listzombiechannels`
+	// ListZombieChannels lists all channels of the zombie index. Zombie channels
+	// are ignored by path finding and their updates are rejected.
+	ListZombieChannels(context.Context, *ListZombieChannelsRequest) (*ListZombieChannelsResponse, error)
+	// lncli: `resurrectzombiechannel`
+	// ResurrectZombieChannel removes a channel from the zombie index. This is
+	// useful if a live channel was falsely marked as a zombie and is therefore
+	// hidden from path finding. If the channel was removed from the graph, it is
+	// added back, unless its funding output was spent.
+	ResurrectZombieChannel(context.Context, *ResurrectZombieChannelRequest) (*ResurrectZombieChannelResponse, error)
+	// lncli: `prunezombiechannels`
+	// PruneZombieChannels examines the graph for zombie channels and prunes them
+	// right away instead of waiting for the next prune interval.
+	PruneZombieChannels(context.Context, *PruneZombieChannelsRequest) (*PruneZombieChannelsResponse, error)
+	// lncli: `importnodescores`
+	// ImportNodeScores imports per-node scores from an external source, for
+	// example a scoring oracle. If enabled with routerrpc.usenodescores, path
+	// finding multiplies the success probability of a channel with the score of
+	// the node that forwards over it. If several sources score a node, the lowest
+	// unexpired score is used.
+	ImportNodeScores(context.Context, *ImportNodeScoresRequest) (*ImportNodeScoresResponse, error)
+	// lncli: `querynodescores`
+	// QueryNodeScores returns all imported node scores that didn't expire yet
+	// along with their source.
+	QueryNodeScores(context.Context, *QueryNodeScoresRequest) (*QueryNodeScoresResponse, error)
+	// lncli: `sendpaymentbatch`
+	// SendPaymentBatch pays multiple payment requests with a routing fee budget
+	// and a timeout that are shared by all payments of the batch. The payments
+	// are sent with bounded concurrency and the final result of every payment is
+	// returned on the stream as soon as it is known. Every payment is limited to
+	// a share of the unused budget that is proportional to its amount, and the
+	// fees that a finished payment didn't spend are made available to the
+	// payments that are started after it.
+	SendPaymentBatch(*SendPaymentBatchRequest, Router_SendPaymentBatchServer) error
+	// lncli: `subscribehtlcattempts`
+	// SubscribeHtlcAttempts returns a stream that delivers an event whenever an
+	// HTLC attempt of any payment is launched, settled or failed. Failure events
+	// carry the index of the node that reported the failure and the decoded
+	// failure message. At the start of the stream, the current state of the
+	// attempts of all payments that are in flight is delivered.
+	SubscribeHtlcAttempts(*SubscribeHtlcAttemptsRequest, Router_SubscribeHtlcAttemptsServer) error
 	mustEmbedUnimplementedRouterServer()
 }
 
@@ -568,6 +982,15 @@ func (UnimplementedRouterServer) TrackPaymentV2(*TrackPaymentRequest, Router_Tra
 func (UnimplementedRouterServer) TrackPayments(*TrackPaymentsRequest, Router_TrackPaymentsServer) error {
 	return status.Errorf(codes.Unimplemented, "method TrackPayments not implemented")
 }
+func (UnimplementedRouterServer) CancelPayment(context.Context, *CancelPaymentRequest) (*CancelPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelPayment not implemented")
+}
+func (UnimplementedRouterServer) UpdatePaymentLimits(context.Context, *UpdatePaymentLimitsRequest) (*UpdatePaymentLimitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePaymentLimits not implemented")
+}
+func (UnimplementedRouterServer) AbandonStalePayments(context.Context, *AbandonStalePaymentsRequest) (*AbandonStalePaymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AbandonStalePayments not implemented")
+}
 func (UnimplementedRouterServer) EstimateRouteFee(context.Context, *RouteFeeRequest) (*RouteFeeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method EstimateRouteFee not implemented")
 }
@@ -580,9 +1003,18 @@ func (UnimplementedRouterServer) SendToRouteV2(context.Context, *SendToRouteRequ
 func (UnimplementedRouterServer) ResetMissionControl(context.Context, *ResetMissionControlRequest) (*ResetMissionControlResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ResetMissionControl not implemented")
 }
+func (UnimplementedRouterServer) ResetMissionControlPairs(context.Context, *ResetMissionControlPairsRequest) (*ResetMissionControlPairsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetMissionControlPairs not implemented")
+}
 func (UnimplementedRouterServer) QueryMissionControl(context.Context, *QueryMissionControlRequest) (*QueryMissionControlResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryMissionControl not implemented")
 }
+func (UnimplementedRouterServer) ListMissionControlNamespaces(context.Context, *ListMissionControlNamespacesRequest) (*ListMissionControlNamespacesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMissionControlNamespaces not implemented")
+}
+func (UnimplementedRouterServer) GetProberStats(context.Context, *GetProberStatsRequest) (*GetProberStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProberStats not implemented")
+}
 func (UnimplementedRouterServer) XImportMissionControl(context.Context, *XImportMissionControlRequest) (*XImportMissionControlResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method XImportMissionControl not implemented")
 }
@@ -592,9 +1024,21 @@ func (UnimplementedRouterServer) GetMissionControlConfig(context.Context, *GetMi
 func (UnimplementedRouterServer) SetMissionControlConfig(context.Context, *SetMissionControlConfigRequest) (*SetMissionControlConfigResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetMissionControlConfig not implemented")
 }
+func (UnimplementedRouterServer) SubscribeMissionControlConfig(*SubscribeMissionControlConfigRequest, Router_SubscribeMissionControlConfigServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeMissionControlConfig not implemented")
+}
+func (UnimplementedRouterServer) SetBimodalPairOverrides(context.Context, *SetBimodalPairOverridesRequest) (*SetBimodalPairOverridesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBimodalPairOverrides not implemented")
+}
+func (UnimplementedRouterServer) ListBimodalPairOverrides(context.Context, *ListBimodalPairOverridesRequest) (*ListBimodalPairOverridesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBimodalPairOverrides not implemented")
+}
 func (UnimplementedRouterServer) QueryProbability(context.Context, *QueryProbabilityRequest) (*QueryProbabilityResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryProbability not implemented")
 }
+func (UnimplementedRouterServer) QueryRouteProbability(context.Context, *QueryRouteProbabilityRequest) (*QueryRouteProbabilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryRouteProbability not implemented")
+}
 func (UnimplementedRouterServer) BuildRoute(context.Context, *BuildRouteRequest) (*BuildRouteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BuildRoute not implemented")
 }
@@ -613,6 +1057,27 @@ func (UnimplementedRouterServer) HtlcInterceptor(Router_HtlcInterceptorServer) e
 func (UnimplementedRouterServer) UpdateChanStatus(context.Context, *UpdateChanStatusRequest) (*UpdateChanStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateChanStatus not implemented")
 }
+func (UnimplementedRouterServer) ListZombieChannels(context.Context, *ListZombieChannelsRequest) (*ListZombieChannelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListZombieChannels not implemented")
+}
+func (UnimplementedRouterServer) ResurrectZombieChannel(context.Context, *ResurrectZombieChannelRequest) (*ResurrectZombieChannelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResurrectZombieChannel not implemented")
+}
+func (UnimplementedRouterServer) PruneZombieChannels(context.Context, *PruneZombieChannelsRequest) (*PruneZombieChannelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PruneZombieChannels not implemented")
+}
+func (UnimplementedRouterServer) ImportNodeScores(context.Context, *ImportNodeScoresRequest) (*ImportNodeScoresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportNodeScores not implemented")
+}
+func (UnimplementedRouterServer) QueryNodeScores(context.Context, *QueryNodeScoresRequest) (*QueryNodeScoresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryNodeScores not implemented")
+}
+func (UnimplementedRouterServer) SendPaymentBatch(*SendPaymentBatchRequest, Router_SendPaymentBatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method SendPaymentBatch not implemented")
+}
+func (UnimplementedRouterServer) SubscribeHtlcAttempts(*SubscribeHtlcAttemptsRequest, Router_SubscribeHtlcAttemptsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeHtlcAttempts not implemented")
+}
 func (UnimplementedRouterServer) mustEmbedUnimplementedRouterServer() {}
 
 // UnsafeRouterServer may be embedded to opt out of forward compatibility for this service.
@@ -689,6 +1154,60 @@ func (x *routerTrackPaymentsServer) Send(m *lnrpc.Payment) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Router_CancelPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).CancelPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/CancelPayment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).CancelPayment(ctx, req.(*CancelPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_UpdatePaymentLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePaymentLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).UpdatePaymentLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/UpdatePaymentLimits",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).UpdatePaymentLimits(ctx, req.(*UpdatePaymentLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_AbandonStalePayments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbandonStalePaymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).AbandonStalePayments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/AbandonStalePayments",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).AbandonStalePayments(ctx, req.(*AbandonStalePaymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Router_EstimateRouteFee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RouteFeeRequest)
 	if err := dec(in); err != nil {
@@ -761,6 +1280,24 @@ func _Router_ResetMissionControl_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Router_ResetMissionControlPairs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetMissionControlPairsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).ResetMissionControlPairs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/ResetMissionControlPairs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).ResetMissionControlPairs(ctx, req.(*ResetMissionControlPairsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Router_QueryMissionControl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(QueryMissionControlRequest)
 	if err := dec(in); err != nil {
@@ -779,6 +1316,42 @@ func _Router_QueryMissionControl_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Router_ListMissionControlNamespaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMissionControlNamespacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).ListMissionControlNamespaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/ListMissionControlNamespaces",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).ListMissionControlNamespaces(ctx, req.(*ListMissionControlNamespacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_GetProberStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProberStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).GetProberStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/GetProberStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).GetProberStats(ctx, req.(*GetProberStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Router_XImportMissionControl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(XImportMissionControlRequest)
 	if err := dec(in); err != nil {
@@ -833,6 +1406,63 @@ func _Router_SetMissionControlConfig_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Router_SubscribeMissionControlConfig_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeMissionControlConfigRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RouterServer).SubscribeMissionControlConfig(m, &routerSubscribeMissionControlConfigServer{stream})
+}
+
+type Router_SubscribeMissionControlConfigServer interface {
+	Send(*MissionControlConfigEvent) error
+	grpc.ServerStream
+}
+
+type routerSubscribeMissionControlConfigServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerSubscribeMissionControlConfigServer) Send(m *MissionControlConfigEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Router_SetBimodalPairOverrides_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBimodalPairOverridesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).SetBimodalPairOverrides(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/SetBimodalPairOverrides",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).SetBimodalPairOverrides(ctx, req.(*SetBimodalPairOverridesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_ListBimodalPairOverrides_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBimodalPairOverridesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).ListBimodalPairOverrides(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/ListBimodalPairOverrides",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).ListBimodalPairOverrides(ctx, req.(*ListBimodalPairOverridesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Router_QueryProbability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(QueryProbabilityRequest)
 	if err := dec(in); err != nil {
@@ -851,6 +1481,24 @@ func _Router_QueryProbability_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Router_QueryRouteProbability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRouteProbabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).QueryRouteProbability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/QueryRouteProbability",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).QueryRouteProbability(ctx, req.(*QueryRouteProbabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Router_BuildRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BuildRouteRequest)
 	if err := dec(in); err != nil {
@@ -976,6 +1624,138 @@ func _Router_UpdateChanStatus_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Router_ListZombieChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListZombieChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).ListZombieChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/ListZombieChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).ListZombieChannels(ctx, req.(*ListZombieChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_ResurrectZombieChannel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResurrectZombieChannelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).ResurrectZombieChannel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/ResurrectZombieChannel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).ResurrectZombieChannel(ctx, req.(*ResurrectZombieChannelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_PruneZombieChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PruneZombieChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).PruneZombieChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/PruneZombieChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).PruneZombieChannels(ctx, req.(*PruneZombieChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_ImportNodeScores_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportNodeScoresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).ImportNodeScores(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/ImportNodeScores",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).ImportNodeScores(ctx, req.(*ImportNodeScoresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_QueryNodeScores_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryNodeScoresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).QueryNodeScores(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/QueryNodeScores",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).QueryNodeScores(ctx, req.(*QueryNodeScoresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_SendPaymentBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SendPaymentBatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RouterServer).SendPaymentBatch(m, &routerSendPaymentBatchServer{stream})
+}
+
+type Router_SendPaymentBatchServer interface {
+	Send(*BatchPaymentResult) error
+	grpc.ServerStream
+}
+
+type routerSendPaymentBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerSendPaymentBatchServer) Send(m *BatchPaymentResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Router_SubscribeHtlcAttempts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeHtlcAttemptsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RouterServer).SubscribeHtlcAttempts(m, &routerSubscribeHtlcAttemptsServer{stream})
+}
+
+type Router_SubscribeHtlcAttemptsServer interface {
+	Send(*HtlcAttemptEvent) error
+	grpc.ServerStream
+}
+
+type routerSubscribeHtlcAttemptsServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerSubscribeHtlcAttemptsServer) Send(m *HtlcAttemptEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Router_ServiceDesc is the grpc.ServiceDesc for Router service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -983,6 +1763,18 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "routerrpc.Router",
 	HandlerType: (*RouterServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CancelPayment",
+			Handler:    _Router_CancelPayment_Handler,
+		},
+		{
+			MethodName: "UpdatePaymentLimits",
+			Handler:    _Router_UpdatePaymentLimits_Handler,
+		},
+		{
+			MethodName: "AbandonStalePayments",
+			Handler:    _Router_AbandonStalePayments_Handler,
+		},
 		{
 			MethodName: "EstimateRouteFee",
 			Handler:    _Router_EstimateRouteFee_Handler,
@@ -999,10 +1791,22 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ResetMissionControl",
 			Handler:    _Router_ResetMissionControl_Handler,
 		},
+		{
+			MethodName: "ResetMissionControlPairs",
+			Handler:    _Router_ResetMissionControlPairs_Handler,
+		},
 		{
 			MethodName: "QueryMissionControl",
 			Handler:    _Router_QueryMissionControl_Handler,
 		},
+		{
+			MethodName: "ListMissionControlNamespaces",
+			Handler:    _Router_ListMissionControlNamespaces_Handler,
+		},
+		{
+			MethodName: "GetProberStats",
+			Handler:    _Router_GetProberStats_Handler,
+		},
 		{
 			MethodName: "XImportMissionControl",
 			Handler:    _Router_XImportMissionControl_Handler,
@@ -1015,10 +1819,22 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetMissionControlConfig",
 			Handler:    _Router_SetMissionControlConfig_Handler,
 		},
+		{
+			MethodName: "SetBimodalPairOverrides",
+			Handler:    _Router_SetBimodalPairOverrides_Handler,
+		},
+		{
+			MethodName: "ListBimodalPairOverrides",
+			Handler:    _Router_ListBimodalPairOverrides_Handler,
+		},
 		{
 			MethodName: "QueryProbability",
 			Handler:    _Router_QueryProbability_Handler,
 		},
+		{
+			MethodName: "QueryRouteProbability",
+			Handler:    _Router_QueryRouteProbability_Handler,
+		},
 		{
 			MethodName: "BuildRoute",
 			Handler:    _Router_BuildRoute_Handler,
@@ -1027,6 +1843,26 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateChanStatus",
 			Handler:    _Router_UpdateChanStatus_Handler,
 		},
+		{
+			MethodName: "ListZombieChannels",
+			Handler:    _Router_ListZombieChannels_Handler,
+		},
+		{
+			MethodName: "ResurrectZombieChannel",
+			Handler:    _Router_ResurrectZombieChannel_Handler,
+		},
+		{
+			MethodName: "PruneZombieChannels",
+			Handler:    _Router_PruneZombieChannels_Handler,
+		},
+		{
+			MethodName: "ImportNodeScores",
+			Handler:    _Router_ImportNodeScores_Handler,
+		},
+		{
+			MethodName: "QueryNodeScores",
+			Handler:    _Router_QueryNodeScores_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1044,6 +1880,11 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Router_TrackPayments_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeMissionControlConfig",
+			Handler:       _Router_SubscribeMissionControlConfig_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "SubscribeHtlcEvents",
 			Handler:       _Router_SubscribeHtlcEvents_Handler,
@@ -1065,6 +1906,16 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "SendPaymentBatch",
+			Handler:       _Router_SendPaymentBatch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeHtlcAttempts",
+			Handler:       _Router_SubscribeHtlcAttempts_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "routerrpc/router.proto",
 }
@@ -6,8 +6,10 @@ import (
 	crand "crypto/rand"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -20,8 +22,10 @@ import (
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/record"
 	"github.com/lightningnetwork/lnd/routing"
 	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/subscribe"
 	"github.com/lightningnetwork/lnd/zpay32"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -89,6 +93,18 @@ var (
 			Entity: "offchain",
 			Action: "read",
 		}},
+		"/routerrpc.Router/CancelPayment": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/UpdatePaymentLimits": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/AbandonStalePayments": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
 		"/routerrpc.Router/EstimateRouteFee": {{
 			Entity: "offchain",
 			Action: "read",
@@ -97,6 +113,14 @@ var (
 			Entity: "offchain",
 			Action: "read",
 		}},
+		"/routerrpc.Router/ListMissionControlNamespaces": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/routerrpc.Router/GetProberStats": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 		"/routerrpc.Router/XImportMissionControl": {{
 			Entity: "offchain",
 			Action: "write",
@@ -109,14 +133,34 @@ var (
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/routerrpc.Router/SubscribeMissionControlConfig": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 		"/routerrpc.Router/QueryProbability": {{
 			Entity: "offchain",
 			Action: "read",
 		}},
+		"/routerrpc.Router/QueryRouteProbability": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 		"/routerrpc.Router/ResetMissionControl": {{
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/routerrpc.Router/ResetMissionControlPairs": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/SetBimodalPairOverrides": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/ListBimodalPairOverrides": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 		"/routerrpc.Router/BuildRoute": {{
 			Entity: "offchain",
 			Action: "read",
@@ -141,6 +185,34 @@ var (
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/routerrpc.Router/ListZombieChannels": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/routerrpc.Router/ResurrectZombieChannel": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/PruneZombieChannels": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/ImportNodeScores": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/QueryNodeScores": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/routerrpc.Router/SendPaymentBatch": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/SubscribeHtlcAttempts": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 	}
 
 	// DefaultRouterMacFilename is the default name of the router macaroon
@@ -170,6 +242,14 @@ type Server struct {
 
 	cfg *Config
 
+	// mcCfgUpdates notifies subscribers about changes of the mission
+	// control config.
+	mcCfgUpdates *subscribe.Server
+
+	// prober is the background payment prober. It is nil if the prober is
+	// disabled.
+	prober *prober
+
 	quit chan struct{}
 }
 
@@ -223,8 +303,20 @@ func New(cfg *Config) (*Server, lnrpc.MacaroonPerms, error) {
 	}
 
 	routerServer := &Server{
-		cfg:  cfg,
-		quit: make(chan struct{}),
+		cfg:          cfg,
+		mcCfgUpdates: subscribe.NewServer(),
+		quit:         make(chan struct{}),
+	}
+
+	if cfg.ProberConfig != nil && cfg.ProberConfig.Enable {
+		p, err := newProber(
+			cfg.ProberConfig, routerServer.probeNode,
+			routerServer.deleteProbePayment,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		routerServer.prober = p
 	}
 
 	return routerServer, macPermissions, nil
@@ -238,6 +330,14 @@ func (s *Server) Start() error {
 		return nil
 	}
 
+	if err := s.mcCfgUpdates.Start(); err != nil {
+		return err
+	}
+
+	if s.prober != nil {
+		s.prober.start()
+	}
+
 	return nil
 }
 
@@ -250,7 +350,12 @@ func (s *Server) Stop() error {
 	}
 
 	close(s.quit)
-	return nil
+
+	if s.prober != nil {
+		s.prober.stop()
+	}
+
+	return s.mcCfgUpdates.Stop()
 }
 
 // Name returns a unique string representation of the sub-server. This can be
@@ -480,13 +585,9 @@ func (s *Server) probePaymentRequest(ctx context.Context, paymentRequest string,
 			"greater than 0")
 	}
 
-	// Generate random payment hash, so we can be sure that the target of
-	// the probe payment doesn't have the preimage to settle the htlc.
-	var paymentHash lntypes.Hash
-	_, err = crand.Read(paymentHash[:])
+	paymentHash, err := randomProbeHash()
 	if err != nil {
-		return nil, fmt.Errorf("cannot generate random probe "+
-			"preimage: %w", err)
+		return nil, err
 	}
 
 	amtMsat := int64(*payReq.MilliSat)
@@ -678,6 +779,52 @@ func maxLspCltvDelta(routeHints [][]zpay32.HopHint) uint16 {
 	return maxCltvDelta
 }
 
+// randomProbeHash returns a random payment hash, so we can be sure that the
+// target of a probe payment doesn't have the preimage to settle the htlc.
+func randomProbeHash() (lntypes.Hash, error) {
+	var paymentHash lntypes.Hash
+	_, err := crand.Read(paymentHash[:])
+	if err != nil {
+		return paymentHash, fmt.Errorf("cannot generate random probe "+
+			"preimage: %w", err)
+	}
+
+	return paymentHash, nil
+}
+
+// probeNode sends a single probe payment with a random payment hash, which the
+// destination can't settle, and returns the fee and time lock of the route
+// that reached it.
+func (s *Server) probeNode(ctx context.Context, paymentHash lntypes.Hash,
+	dest route.Vertex, amt lnwire.MilliSatoshi,
+	timeout time.Duration) (*RouteFeeResponse, error) {
+
+	return s.sendProbePayment(ctx, &SendPaymentRequest{
+		TimeoutSeconds: int32(timeout.Seconds()),
+		Dest:           dest[:],
+		MaxParts:       1,
+		AmtMsat:        int64(amt),
+		PaymentHash:    paymentHash[:],
+		FeeLimitSat:    routeFeeLimitSat,
+	})
+}
+
+// deleteProbePayment deletes the payment of a probe that has been resolved. A
+// probe that never got initiated, e.g. because its payment request was
+// invalid, has no payment to delete.
+func (s *Server) deleteProbePayment(paymentHash lntypes.Hash) error {
+	_, err := s.cfg.RouterBackend.Tower.FetchPayment(paymentHash)
+	switch {
+	case errors.Is(err, channeldb.ErrPaymentNotInitiated):
+		return nil
+
+	case err != nil:
+		return err
+	}
+
+	return s.cfg.RouterBackend.DeletePayment(paymentHash, false)
+}
+
 // probePaymentStream is a custom implementation of the grpc.ServerStream
 // interface. It is used to send payment status updates to the caller on the
 // stream channel.
@@ -842,9 +989,17 @@ func (s *Server) SendToRouteV2(ctx context.Context,
 	// the attempt was already initiated before the error happened. In that
 	// case, we give precedence to the attempt information as stored in the
 	// db.
-	if req.SkipTempErr {
+	switch {
+	case req.MissionControlNamespace != "":
+		attempt, err = s.cfg.Router.SendToRouteWithMissionControl(
+			hash, route, req.MissionControlNamespace,
+			req.SkipTempErr,
+		)
+
+	case req.SkipTempErr:
 		attempt, err = s.cfg.Router.SendToRouteSkipTempErr(hash, route)
-	} else {
+
+	default:
 		attempt, err = s.cfg.Router.SendToRoute(hash, route)
 	}
 	if attempt != nil {
@@ -879,14 +1034,180 @@ func (s *Server) SendToRouteV2(ctx context.Context,
 func (s *Server) ResetMissionControl(ctx context.Context,
 	req *ResetMissionControlRequest) (*ResetMissionControlResponse, error) {
 
-	err := s.cfg.RouterBackend.MissionControl.ResetHistory()
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, false,
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := mc.ResetHistory(); err != nil {
+		return nil, err
+	}
+
 	return &ResetMissionControlResponse{}, nil
 }
 
+// ResetMissionControlPairs clears the mission control state of the given node
+// pairs and nodes, while preserving the state of all other pairs.
+func (s *Server) ResetMissionControlPairs(ctx context.Context,
+	req *ResetMissionControlPairsRequest) (
+	*ResetMissionControlPairsResponse, error) {
+
+	if len(req.Pairs) == 0 && len(req.Nodes) == 0 {
+		return nil, errors.New("at least one pair or node required " +
+			"for reset")
+	}
+
+	pairs := make([]routing.DirectedNodePair, 0, len(req.Pairs))
+	for _, rpcPair := range req.Pairs {
+		from, err := route.NewVertexFromBytes(rpcPair.From)
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := route.NewVertexFromBytes(rpcPair.To)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, routing.NewDirectedNodePair(from, to))
+	}
+
+	nodes := make([]route.Vertex, 0, len(req.Nodes))
+	for _, rpcNode := range req.Nodes {
+		node, err := route.NewVertexFromBytes(rpcNode)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	numReset, err := mc.ResetPairs(pairs, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResetMissionControlPairsResponse{
+		NumPairsReset: uint64(numReset),
+	}, nil
+}
+
+// SetBimodalPairOverrides sets bimodal estimator parameters for single node
+// pairs that replace the global ones.
+func (s *Server) SetBimodalPairOverrides(ctx context.Context,
+	req *SetBimodalPairOverridesRequest) (*SetBimodalPairOverridesResponse,
+	error) {
+
+	if len(req.Overrides) == 0 && len(req.Remove) == 0 {
+		return nil, errors.New("at least one override to set or " +
+			"remove required")
+	}
+
+	overrides := make(routing.BimodalPairOverrides, len(req.Overrides))
+	for _, rpcOverride := range req.Overrides {
+		from, err := route.NewVertexFromBytes(rpcOverride.NodeFrom)
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := route.NewVertexFromBytes(rpcOverride.NodeTo)
+		if err != nil {
+			return nil, err
+		}
+
+		if from == to {
+			return nil, fmt.Errorf("pair %v -> %v: source and "+
+				"destination node must differ", from, to)
+		}
+
+		pair := routing.NewDirectedNodePair(from, to)
+		overrides[pair] = routing.BimodalPairOverride{
+			NodeWeight: rpcOverride.NodeWeight,
+			ScaleMsat: lnwire.MilliSatoshi(
+				rpcOverride.ScaleMsat,
+			),
+		}
+	}
+
+	remove := make([]routing.DirectedNodePair, 0, len(req.Remove))
+	for _, rpcPair := range req.Remove {
+		from, err := route.NewVertexFromBytes(rpcPair.From)
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := route.NewVertexFromBytes(rpcPair.To)
+		if err != nil {
+			return nil, err
+		}
+
+		remove = append(remove, routing.NewDirectedNodePair(from, to))
+	}
+
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, true,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mc.SetBimodalPairOverrides(overrides, remove)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &SetBimodalPairOverridesResponse{}, nil
+}
+
+// ListBimodalPairOverrides returns the bimodal estimator parameters that are
+// set for single node pairs.
+func (s *Server) ListBimodalPairOverrides(ctx context.Context,
+	req *ListBimodalPairOverridesRequest) (
+	*ListBimodalPairOverridesResponse, error) {
+
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := mc.GetBimodalPairOverrides()
+
+	resp := &ListBimodalPairOverridesResponse{
+		Overrides: make([]*BimodalPairOverride, 0, len(overrides)),
+	}
+	for pair, override := range overrides {
+		from, to := pair.From, pair.To
+		resp.Overrides = append(resp.Overrides, &BimodalPairOverride{
+			NodeFrom:   from[:],
+			NodeTo:     to[:],
+			NodeWeight: override.NodeWeight,
+			ScaleMsat:  uint64(override.ScaleMsat),
+		})
+	}
+
+	sort.Slice(resp.Overrides, func(i, j int) bool {
+		a, b := resp.Overrides[i], resp.Overrides[j]
+		if c := bytes.Compare(a.NodeFrom, b.NodeFrom); c != 0 {
+			return c < 0
+		}
+
+		return bytes.Compare(a.NodeTo, b.NodeTo) < 0
+	})
+
+	return resp, nil
+}
+
 // GetMissionControlConfig returns our current mission control config.
 func (s *Server) GetMissionControlConfig(ctx context.Context,
 	req *GetMissionControlConfigRequest) (*GetMissionControlConfigResponse,
@@ -894,19 +1215,32 @@ func (s *Server) GetMissionControlConfig(ctx context.Context,
 
 	// Query the current mission control config.
 	cfg := s.cfg.RouterBackend.MissionControl.GetConfig()
-	resp := &GetMissionControlConfigResponse{
-		Config: &MissionControlConfig{
-			MaximumPaymentResults: uint32(cfg.MaxMcHistory),
-			MinimumFailureRelaxInterval: uint64(
-				cfg.MinFailureRelaxInterval.Seconds(),
-			),
-		},
+	rpcCfg, err := marshallMissionControlConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetMissionControlConfigResponse{
+		Config: rpcCfg,
+	}, nil
+}
+
+// marshallMissionControlConfig converts a mission control config to its rpc
+// representation.
+func marshallMissionControlConfig(
+	cfg *routing.MissionControlConfig) (*MissionControlConfig, error) {
+
+	rpcCfg := &MissionControlConfig{
+		MaximumPaymentResults: uint32(cfg.MaxMcHistory),
+		MinimumFailureRelaxInterval: uint64(
+			cfg.MinFailureRelaxInterval.Seconds(),
+		),
 	}
 
 	// We only populate fields based on the current estimator.
 	switch v := cfg.Estimator.Config().(type) {
 	case routing.AprioriConfig:
-		resp.Config.Model = MissionControlConfig_APRIORI
+		rpcCfg.Model = MissionControlConfig_APRIORI
 		aCfg := AprioriParameters{
 			HalfLifeSeconds:  uint64(v.PenaltyHalfLife.Seconds()),
 			HopProbability:   v.AprioriHopProbability,
@@ -915,25 +1249,23 @@ func (s *Server) GetMissionControlConfig(ctx context.Context,
 		}
 
 		// Populate deprecated fields.
-		resp.Config.HalfLifeSeconds = uint64(
-			v.PenaltyHalfLife.Seconds(),
-		)
-		resp.Config.HopProbability = float32(v.AprioriHopProbability)
-		resp.Config.Weight = float32(v.AprioriWeight)
+		rpcCfg.HalfLifeSeconds = uint64(v.PenaltyHalfLife.Seconds())
+		rpcCfg.HopProbability = float32(v.AprioriHopProbability)
+		rpcCfg.Weight = float32(v.AprioriWeight)
 
-		resp.Config.EstimatorConfig = &MissionControlConfig_Apriori{
+		rpcCfg.EstimatorConfig = &MissionControlConfig_Apriori{
 			Apriori: &aCfg,
 		}
 
 	case routing.BimodalConfig:
-		resp.Config.Model = MissionControlConfig_BIMODAL
+		rpcCfg.Model = MissionControlConfig_BIMODAL
 		bCfg := BimodalParameters{
 			NodeWeight: v.BimodalNodeWeight,
 			ScaleMsat:  uint64(v.BimodalScaleMsat),
 			DecayTime:  uint64(v.BimodalDecayTime.Seconds()),
 		}
 
-		resp.Config.EstimatorConfig = &MissionControlConfig_Bimodal{
+		rpcCfg.EstimatorConfig = &MissionControlConfig_Bimodal{
 			Bimodal: &bCfg,
 		}
 
@@ -941,7 +1273,7 @@ func (s *Server) GetMissionControlConfig(ctx context.Context,
 		return nil, fmt.Errorf("unknown estimator config type %T", v)
 	}
 
-	return resp, nil
+	return rpcCfg, nil
 }
 
 // SetMissionControlConfig sets parameters in the mission control config.
@@ -949,6 +1281,10 @@ func (s *Server) SetMissionControlConfig(ctx context.Context,
 	req *SetMissionControlConfigRequest) (*SetMissionControlConfigResponse,
 	error) {
 
+	if req.Config == nil {
+		return nil, errors.New("mission control config required")
+	}
+
 	mcCfg := &routing.MissionControlConfig{
 		MaxMcHistory: int(req.Config.MaximumPaymentResults),
 		MinFailureRelaxInterval: time.Duration(
@@ -1026,69 +1362,306 @@ func (s *Server) SetMissionControlConfig(ctx context.Context,
 			req.Config.Model)
 	}
 
-	return &SetMissionControlConfigResponse{},
-		s.cfg.RouterBackend.MissionControl.SetConfig(mcCfg)
-}
-
-// QueryMissionControl exposes the internal mission control state to callers. It
-// is a development feature.
-func (s *Server) QueryMissionControl(ctx context.Context,
-	req *QueryMissionControlRequest) (*QueryMissionControlResponse, error) {
-
-	snapshot := s.cfg.RouterBackend.MissionControl.GetHistorySnapshot()
-
-	rpcPairs := make([]*PairHistory, 0, len(snapshot.Pairs))
-	for _, p := range snapshot.Pairs {
-		// Prevent binding to loop variable.
-		pair := p
-
-		rpcPair := PairHistory{
-			NodeFrom: pair.Pair.From[:],
-			NodeTo:   pair.Pair.To[:],
-			History:  toRPCPairData(&pair.TimedPairResult),
-		}
+	err := s.cfg.RouterBackend.SetMissionControlConfig(mcCfg)
+	if err != nil {
+		return nil, err
+	}
 
-		rpcPairs = append(rpcPairs, &rpcPair)
+	// Notify subscribers about the config that is now active.
+	rpcCfg, err := marshallMissionControlConfig(
+		s.cfg.RouterBackend.MissionControl.GetConfig(),
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	response := QueryMissionControlResponse{
-		Pairs: rpcPairs,
+	err = s.mcCfgUpdates.SendUpdate(&MissionControlConfigEvent{
+		Config:    rpcCfg,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		log.Errorf("Unable to notify about mission control config "+
+			"update: %v", err)
 	}
 
-	return &response, nil
+	return &SetMissionControlConfigResponse{}, nil
 }
 
-// toRPCPairData marshalls mission control pair data to the rpc struct.
-func toRPCPairData(data *routing.TimedPairResult) *PairData {
-	rpcData := PairData{
-		FailAmtSat:     int64(data.FailAmt.ToSatoshis()),
-		FailAmtMsat:    int64(data.FailAmt),
-		SuccessAmtSat:  int64(data.SuccessAmt.ToSatoshis()),
-		SuccessAmtMsat: int64(data.SuccessAmt),
-	}
+// SubscribeMissionControlConfig streams mission control's config every time it
+// is changed. The currently active config is sent first.
+func (s *Server) SubscribeMissionControlConfig(
+	_ *SubscribeMissionControlConfigRequest,
+	stream Router_SubscribeMissionControlConfigServer) error {
 
-	if !data.FailTime.IsZero() {
-		rpcData.FailTime = data.FailTime.Unix()
+	client, err := s.mcCfgUpdates.Subscribe()
+	if err != nil {
+		return err
 	}
+	defer client.Cancel()
 
-	if !data.SuccessTime.IsZero() {
-		rpcData.SuccessTime = data.SuccessTime.Unix()
+	// Send out the currently active config, so that the caller knows the
+	// point from which changes will be transmitted.
+	rpcCfg, err := marshallMissionControlConfig(
+		s.cfg.RouterBackend.MissionControl.GetConfig(),
+	)
+	if err != nil {
+		return err
 	}
 
-	return &rpcData
-}
-
-// XImportMissionControl imports the state provided to our internal mission
-// control. Only entries that are fresher than our existing state will be used.
-func (s *Server) XImportMissionControl(ctx context.Context,
-	req *XImportMissionControlRequest) (*XImportMissionControlResponse,
-	error) {
-
-	if len(req.Pairs) == 0 {
-		return nil, errors.New("at least one pair required for import")
+	err = stream.Send(&MissionControlConfigEvent{
+		Config: rpcCfg,
+	})
+	if err != nil {
+		return err
 	}
 
-	snapshot := &routing.MissionControlSnapshot{
+	for {
+		select {
+		case update := <-client.Updates():
+			event, ok := update.(*MissionControlConfigEvent)
+			if !ok {
+				return fmt.Errorf("unexpected mission control "+
+					"config update %T", update)
+			}
+
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+
+		// If the stream's context is cancelled, return an error.
+		case <-stream.Context().Done():
+			log.Debugf("mission control config stream cancelled")
+			return stream.Context().Err()
+
+		// If the subscribe client terminates, exit with an error.
+		case <-client.Quit():
+			return errors.New("mission control config " +
+				"subscription terminated")
+
+		// If the server has been signalled to shut down, exit.
+		case <-s.quit:
+			return errServerShuttingDown
+		}
+	}
+}
+
+// QueryMissionControl exposes the internal mission control state to callers. It
+// is a development feature.
+func (s *Server) QueryMissionControl(ctx context.Context,
+	req *QueryMissionControlRequest) (*QueryMissionControlResponse, error) {
+
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := mc.GetHistorySnapshot()
+
+	pairs, err := filterPairSnapshots(snapshot.Pairs, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cut out the requested page. The index offset is capped at the
+	// number of matching pairs, so that it can be passed back as is.
+	totalNumPairs := uint64(len(pairs))
+	start := req.IndexOffset
+	if start > totalNumPairs {
+		start = totalNumPairs
+	}
+	end := totalNumPairs
+	if req.MaxPairs != 0 && start+req.MaxPairs < end {
+		end = start + req.MaxPairs
+	}
+	pairs = pairs[start:end]
+
+	rpcPairs := make([]*PairHistory, 0, len(pairs))
+	for _, p := range pairs {
+		// Prevent binding to loop variable.
+		pair := p
+
+		rpcPair := PairHistory{
+			NodeFrom: pair.Pair.From[:],
+			NodeTo:   pair.Pair.To[:],
+			History:  toRPCPairData(&pair.TimedPairResult),
+		}
+
+		rpcPairs = append(rpcPairs, &rpcPair)
+	}
+
+	response := QueryMissionControlResponse{
+		Pairs:           rpcPairs,
+		LastIndexOffset: end,
+		TotalNumPairs:   totalNumPairs,
+	}
+
+	return &response, nil
+}
+
+// filterPairSnapshots returns the pair snapshots that match the filters of the
+// given request, ordered by their source and destination node.
+func filterPairSnapshots(pairs []routing.MissionControlPairSnapshot,
+	req *QueryMissionControlRequest) ([]routing.MissionControlPairSnapshot,
+	error) {
+
+	var (
+		node       route.Vertex
+		filterNode = len(req.Node) > 0
+		err        error
+	)
+	if filterNode {
+		node, err = route.NewVertexFromBytes(req.Node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.MinObservationTime < 0 {
+		return nil, errors.New("min observation time must not be " +
+			"negative")
+	}
+	minTime := time.Unix(req.MinObservationTime, 0)
+
+	if req.MinAmtMsat < 0 {
+		return nil, errors.New("min amount must not be negative")
+	}
+	minAmt := lnwire.MilliSatoshi(req.MinAmtMsat)
+
+	filtered := make([]routing.MissionControlPairSnapshot, 0, len(pairs))
+	for _, pair := range pairs {
+		if filterNode && pair.Pair.From != node &&
+			pair.Pair.To != node {
+
+			continue
+		}
+
+		if req.MinObservationTime != 0 &&
+			pair.FailTime.Before(minTime) &&
+			pair.SuccessTime.Before(minTime) {
+
+			continue
+		}
+
+		if minAmt != 0 && pair.FailAmt < minAmt &&
+			pair.SuccessAmt < minAmt {
+
+			continue
+		}
+
+		filtered = append(filtered, pair)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		a, b := filtered[i].Pair, filtered[j].Pair
+		if cmp := bytes.Compare(a.From[:], b.From[:]); cmp != 0 {
+			return cmp < 0
+		}
+
+		return bytes.Compare(a.To[:], b.To[:]) < 0
+	})
+
+	return filtered, nil
+}
+
+// QueryRouteProbability returns the current end-to-end success probability
+// estimate for the given route.
+func (s *Server) QueryRouteProbability(_ context.Context,
+	req *QueryRouteProbabilityRequest) (*QueryRouteProbabilityResponse,
+	error) {
+
+	if req.Route == nil {
+		return nil, errors.New("route required")
+	}
+
+	rt, err := s.cfg.RouterBackend.UnmarshallRoute(req.Route)
+	if err != nil {
+		return nil, err
+	}
+
+	capacities := make([]btcutil.Amount, len(rt.Hops))
+	for i, hop := range rt.Hops {
+		capacities[i], err = s.cfg.RouterBackend.FetchChannelCapacity(
+			hop.ChannelID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch capacity of "+
+				"channel %v: %w", hop.ChannelID, err)
+		}
+	}
+
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	prob, hopProbs, err := mc.GetRouteProbability(rt, capacities)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueryRouteProbabilityResponse{
+		Probability:      prob,
+		HopProbabilities: hopProbs,
+	}, nil
+}
+
+// ListMissionControlNamespaces returns the names of all mission control
+// namespaces.
+func (s *Server) ListMissionControlNamespaces(_ context.Context,
+	_ *ListMissionControlNamespacesRequest) (
+	*ListMissionControlNamespacesResponse, error) {
+
+	return &ListMissionControlNamespacesResponse{
+		Namespaces: s.cfg.RouterBackend.ListMissionControlNamespaces(),
+	}, nil
+}
+
+// toRPCPairData marshalls mission control pair data to the rpc struct.
+func toRPCPairData(data *routing.TimedPairResult) *PairData {
+	rpcData := PairData{
+		FailAmtSat:     int64(data.FailAmt.ToSatoshis()),
+		FailAmtMsat:    int64(data.FailAmt),
+		SuccessAmtSat:  int64(data.SuccessAmt.ToSatoshis()),
+		SuccessAmtMsat: int64(data.SuccessAmt),
+	}
+
+	if !data.FailTime.IsZero() {
+		rpcData.FailTime = data.FailTime.Unix()
+	}
+
+	if !data.SuccessTime.IsZero() {
+		rpcData.SuccessTime = data.SuccessTime.Unix()
+	}
+
+	return &rpcData
+}
+
+// GetProberStats returns the statistics of the background payment prober.
+func (s *Server) GetProberStats(_ context.Context,
+	_ *GetProberStatsRequest) (*GetProberStatsResponse, error) {
+
+	if s.prober == nil {
+		return &GetProberStatsResponse{}, nil
+	}
+
+	return s.prober.statsResponse(), nil
+}
+
+// XImportMissionControl imports the state provided to our internal mission
+// control. Only entries that are fresher than our existing state will be used.
+func (s *Server) XImportMissionControl(ctx context.Context,
+	req *XImportMissionControlRequest) (*XImportMissionControlResponse,
+	error) {
+
+	if len(req.Pairs) == 0 {
+		return nil, errors.New("at least one pair required for import")
+	}
+
+	snapshot := &routing.MissionControlSnapshot{
 		Pairs: make(
 			[]routing.MissionControlPairSnapshot, len(req.Pairs),
 		),
@@ -1103,13 +1676,18 @@ func (s *Server) XImportMissionControl(ctx context.Context,
 		snapshot.Pairs[i] = *pairSnapshot
 	}
 
-	err := s.cfg.RouterBackend.MissionControl.ImportHistory(
-		snapshot, req.Force,
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, true,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	err = mc.ImportHistory(snapshot, req.Force)
+	if err != nil {
+		return nil, err
+	}
+
 	return &XImportMissionControlResponse{}, nil
 }
 
@@ -1242,6 +1820,124 @@ func getMsatPairValue(msatValue lnwire.MilliSatoshi,
 		satValue)
 }
 
+// CancelPayment stops an in-flight payment from launching new HTLC attempts.
+// The payment fails once its remaining attempts resolved.
+func (s *Server) CancelPayment(_ context.Context,
+	req *CancelPaymentRequest) (*CancelPaymentResponse, error) {
+
+	paymentHash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = s.cfg.Router.CancelPayment(paymentHash)
+	switch {
+	case errors.Is(err, channeldb.ErrPaymentNotInitiated):
+		return nil, status.Error(codes.NotFound, err.Error())
+
+	case errors.Is(err, channeldb.ErrPaymentAlreadySucceeded),
+		errors.Is(err, channeldb.ErrPaymentAlreadyFailed),
+		errors.Is(err, channeldb.ErrPaymentPendingSettled),
+		errors.Is(err, channeldb.ErrPaymentPendingFailed):
+
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+
+	case err != nil:
+		return nil, err
+	}
+
+	return &CancelPaymentResponse{}, nil
+}
+
+// UpdatePaymentLimits adjusts the limits of a payment that is currently in
+// flight.
+func (s *Server) UpdatePaymentLimits(_ context.Context,
+	req *UpdatePaymentLimitsRequest) (*UpdatePaymentLimitsResponse,
+	error) {
+
+	paymentHash, err := lntypes.MakeHash(req.PaymentHash)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if req.FeeLimitMsat < 0 || req.TimeoutSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "fee limit "+
+			"and timeout must not be negative")
+	}
+
+	limits := &routing.PaymentLimits{}
+	if req.FeeLimitMsat != 0 {
+		feeLimit := lnwire.MilliSatoshi(req.FeeLimitMsat)
+		limits.FeeLimit = &feeLimit
+	}
+	if req.CltvLimit != 0 {
+		// Like for a new payment, the cltv limit must not exceed the
+		// maximum total time lock.
+		cltvLimit, err := ValidateCLTVLimit(
+			req.CltvLimit, s.cfg.RouterBackend.MaxTotalTimelock,
+		)
+		if err != nil {
+			return nil, status.Error(
+				codes.InvalidArgument, err.Error(),
+			)
+		}
+		limits.CltvLimit = &cltvLimit
+	}
+	if req.TimeoutSeconds != 0 {
+		timeout := time.Second * time.Duration(req.TimeoutSeconds)
+		limits.Timeout = &timeout
+	}
+
+	if limits.FeeLimit == nil && limits.CltvLimit == nil &&
+		limits.Timeout == nil {
+
+		return nil, status.Error(codes.InvalidArgument, "no limits "+
+			"to update")
+	}
+
+	err = s.cfg.Router.UpdatePaymentLimits(paymentHash, limits)
+	if errors.Is(err, routing.ErrPaymentNotActive) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdatePaymentLimitsResponse{}, nil
+}
+
+// AbandonStalePayments fails all payments that are in flight without any
+// outstanding HTLCs and are older than the given minimum age.
+func (s *Server) AbandonStalePayments(_ context.Context,
+	req *AbandonStalePaymentsRequest) (*AbandonStalePaymentsResponse,
+	error) {
+
+	minAge := s.cfg.StalePaymentTimeout
+	if req.MinAgeSeconds != 0 {
+		minAge = time.Duration(req.MinAgeSeconds) * time.Second
+	}
+
+	if minAge == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no minimum "+
+			"age given and no stale payment timeout configured")
+	}
+
+	abandoned, err := s.cfg.Router.AbandonStalePayments(minAge)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &AbandonStalePaymentsResponse{
+		PaymentHashes: make([][]byte, 0, len(abandoned)),
+	}
+	for _, hash := range abandoned {
+		hash := hash
+		resp.PaymentHashes = append(resp.PaymentHashes, hash[:])
+	}
+
+	return resp, nil
+}
+
 // TrackPaymentV2 returns a stream of payment state updates. The stream is
 // closed when the payment completes.
 func (s *Server) TrackPaymentV2(request *TrackPaymentRequest,
@@ -1288,7 +1984,8 @@ func (s *Server) trackPayment(subscription routing.ControlTowerSubscriber,
 	noInflightUpdates bool) error {
 
 	err := s.trackPaymentStream(
-		stream.Context(), subscription, noInflightUpdates, stream.Send,
+		stream.Context(), subscription, noInflightUpdates, nil,
+		stream.Send,
 	)
 
 	// If the context is canceled, we don't return an error.
@@ -1311,18 +2008,32 @@ func (s *Server) TrackPayments(request *TrackPaymentsRequest,
 
 	log.Debug("TrackPayments called")
 
+	filter, err := newPaymentFilter(request)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	router := s.cfg.RouterBackend
 
-	// Subscribe to payments.
+	// Subscribe to payments before the backfill, so that no update is
+	// missed.
 	subscription, err := router.Tower.SubscribeAllPayments()
 	if err != nil {
 		return err
 	}
 
+	if request.Backfill {
+		err := s.backfillPayments(request, filter, stream.Send)
+		if err != nil {
+			subscription.Close()
+			return err
+		}
+	}
+
 	// Stream updates to the client.
 	err = s.trackPaymentStream(
 		stream.Context(), subscription, request.NoInflightUpdates,
-		stream.Send,
+		filter, stream.Send,
 	)
 
 	if errors.Is(err, context.Canceled) {
@@ -1332,9 +2043,93 @@ func (s *Server) TrackPayments(request *TrackPaymentsRequest,
 	return err
 }
 
-// trackPaymentStream streams payment updates to the client.
+// newPaymentFilter returns a function that reports whether a payment matches
+// the filters of the given request.
+func newPaymentFilter(req *TrackPaymentsRequest) (func(*lnrpc.Payment) bool,
+	error) {
+
+	switch {
+	case req.CreationDateEnd != 0 &&
+		req.CreationDateStart > req.CreationDateEnd:
+
+		return nil, fmt.Errorf("creation date start %v after creation "+
+			"date end %v", req.CreationDateStart,
+			req.CreationDateEnd)
+
+	case req.MinAmtMsat < 0:
+		return nil, errors.New("min amount must not be negative")
+	}
+
+	statuses := make(map[lnrpc.Payment_PaymentStatus]struct{})
+	for _, s := range req.StatusFilter {
+		statuses[s] = struct{}{}
+	}
+
+	return func(payment *lnrpc.Payment) bool {
+		if len(statuses) > 0 {
+			if _, ok := statuses[payment.Status]; !ok {
+				return false
+			}
+		}
+
+		created := time.Unix(0, payment.CreationTimeNs).Unix()
+		if created < int64(req.CreationDateStart) {
+			return false
+		}
+		if req.CreationDateEnd != 0 &&
+			created > int64(req.CreationDateEnd) {
+
+			return false
+		}
+
+		return payment.ValueMsat >= req.MinAmtMsat
+	}, nil
+}
+
+// backfillPayments sends the final state of all stored payments that
+// completed and match the filter. Payments that are in flight are skipped, as
+// their current state is delivered by the subscription to all payments.
+func (s *Server) backfillPayments(req *TrackPaymentsRequest,
+	filter func(*lnrpc.Payment) bool,
+	send func(*lnrpc.Payment) error) error {
+
+	resp, err := s.cfg.RouterBackend.QueryPayments(channeldb.PaymentsQuery{
+		MaxPayments:       math.MaxUint64,
+		IncludeIncomplete: true,
+		CreationDateStart: int64(req.CreationDateStart),
+		CreationDateEnd:   int64(req.CreationDateEnd),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range resp.Payments {
+		if !payment.Terminated() {
+			continue
+		}
+
+		rpcPayment, err := s.cfg.RouterBackend.MarshallPayment(payment)
+		if err != nil {
+			return err
+		}
+
+		if !filter(rpcPayment) {
+			continue
+		}
+
+		if err := send(rpcPayment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trackPaymentStream streams payment updates to the client. Only updates of
+// payments that match the filter are sent, unless the filter is nil.
 func (s *Server) trackPaymentStream(context context.Context,
 	subscription routing.ControlTowerSubscriber, noInflightUpdates bool,
+	filter func(*lnrpc.Payment) bool,
 	send func(*lnrpc.Payment) error) error {
 
 	defer subscription.Close()
@@ -1369,6 +2164,10 @@ func (s *Server) trackPaymentStream(context context.Context,
 				return err
 			}
 
+			if filter != nil && !filter(rpcPayment) {
+				continue
+			}
+
 			// Send event to the client.
 			err = send(rpcPayment)
 			if err != nil {
@@ -1384,10 +2183,23 @@ func (s *Server) trackPaymentStream(context context.Context,
 	}
 }
 
-// BuildRoute builds a route from a list of hop addresses.
+// BuildRoute builds a route from a list of hop addresses or a list of short
+// channel ids.
 func (s *Server) BuildRoute(ctx context.Context,
 	req *BuildRouteRequest) (*BuildRouteResponse, error) {
 
+	if len(req.ChanIds) > 0 {
+		switch {
+		case len(req.HopPubkeys) > 0:
+			return nil, errors.New("hop_pubkeys and chan_ids are " +
+				"mutually exclusive")
+
+		case req.OutgoingChanId != 0:
+			return nil, errors.New("outgoing_chan_id can't be " +
+				"combined with chan_ids")
+		}
+	}
+
 	// Unmarshall hop list.
 	hops := make([]route.Vertex, len(req.HopPubkeys))
 	for i, pubkeyBytes := range req.HopPubkeys {
@@ -1425,13 +2237,34 @@ func (s *Server) BuildRoute(ctx context.Context,
 	}
 
 	// Build the route and return it to the caller.
-	route, err := s.cfg.Router.BuildRoute(
-		amt, hops, outgoingChan, req.FinalCltvDelta, payAddr,
+	var (
+		route *route.Route
+		err   error
 	)
+	if len(req.ChanIds) > 0 {
+		route, err = s.cfg.Router.BuildRouteFromChannels(
+			amt, req.ChanIds, req.FinalCltvDelta, payAddr,
+		)
+	} else {
+		route, err = s.cfg.Router.BuildRoute(
+			amt, hops, outgoingChan, req.FinalCltvDelta, payAddr,
+		)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	err = addHopCustomRecords(route, req.HopCustomRecords)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// The custom records of the hops must leave room for the other
+	// payloads of the route.
+	if err := route.CheckPayloadSize(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	rpcRoute, err := s.cfg.RouterBackend.MarshallRoute(route)
 	if err != nil {
 		return nil, err
@@ -1444,6 +2277,35 @@ func (s *Server) BuildRoute(ctx context.Context,
 	return routeResp, nil
 }
 
+// addHopCustomRecords attaches the given custom records to the hops of the
+// route. The records of a hop are merged with the ones it already carries.
+func addHopCustomRecords(rt *route.Route,
+	rpcRecords []*HopCustomRecords) error {
+
+	for _, hopRecords := range rpcRecords {
+		idx := int(hopRecords.HopIndex)
+		if idx >= len(rt.Hops) {
+			return fmt.Errorf("hop index %v out of range for "+
+				"route with %v hops", idx, len(rt.Hops))
+		}
+
+		records := record.CustomSet(hopRecords.CustomRecords)
+		if err := records.Validate(); err != nil {
+			return fmt.Errorf("hop %v: %w", idx, err)
+		}
+
+		hop := rt.Hops[idx]
+		if hop.CustomRecords == nil {
+			hop.CustomRecords = make(record.CustomSet, len(records))
+		}
+		for key, value := range records {
+			hop.CustomRecords[key] = value
+		}
+	}
+
+	return nil
+}
+
 // SubscribeHtlcEvents creates a uni-directional stream from the server to
 // the client which delivers a stream of htlc events.
 func (s *Server) SubscribeHtlcEvents(req *SubscribeHtlcEventsRequest,
@@ -1554,3 +2416,118 @@ func (s *Server) UpdateChanStatus(ctx context.Context,
 	}
 	return &UpdateChanStatusResponse{}, nil
 }
+
+// ListZombieChannels lists all channels of the zombie index.
+func (s *Server) ListZombieChannels(_ context.Context,
+	_ *ListZombieChannelsRequest) (*ListZombieChannelsResponse, error) {
+
+	zombies, err := s.cfg.Router.ZombieChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListZombieChannelsResponse{
+		Channels: make([]*ZombieChannel, 0, len(zombies)),
+	}
+	for _, zombie := range zombies {
+		channel := &ZombieChannel{
+			ChanId: zombie.ChannelID,
+		}
+		if zombie.Node1 != (route.Vertex{}) {
+			channel.Node1Pub = zombie.Node1[:]
+		}
+		if zombie.Node2 != (route.Vertex{}) {
+			channel.Node2Pub = zombie.Node2[:]
+		}
+
+		resp.Channels = append(resp.Channels, channel)
+	}
+
+	return resp, nil
+}
+
+// ResurrectZombieChannel removes a channel from the zombie index.
+func (s *Server) ResurrectZombieChannel(_ context.Context,
+	req *ResurrectZombieChannelRequest) (*ResurrectZombieChannelResponse,
+	error) {
+
+	err := s.cfg.Router.ResurrectZombieChannel(req.ChanId)
+	if errors.Is(err, channeldb.ErrZombieEdgeNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResurrectZombieChannelResponse{}, nil
+}
+
+// PruneZombieChannels examines the graph for zombie channels and prunes them
+// right away.
+func (s *Server) PruneZombieChannels(_ context.Context,
+	_ *PruneZombieChannelsRequest) (*PruneZombieChannelsResponse, error) {
+
+	pruned, err := s.cfg.Router.PruneZombieChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PruneZombieChannelsResponse{
+		ChanIds: pruned,
+	}, nil
+}
+
+// ImportNodeScores imports per-node scores from an external source.
+func (s *Server) ImportNodeScores(_ context.Context,
+	req *ImportNodeScoresRequest) (*ImportNodeScoresResponse, error) {
+
+	scores := make(map[route.Vertex]float64, len(req.Scores))
+	for _, score := range req.Scores {
+		node, err := route.NewVertexFromBytes(score.PubKey)
+		if err != nil {
+			return nil, status.Error(
+				codes.InvalidArgument, err.Error(),
+			)
+		}
+
+		scores[node] = score.Score
+	}
+
+	ttl := time.Duration(req.TtlSeconds) * time.Second
+	err := s.cfg.RouterBackend.NodeScores.Import(
+		req.Source, scores, ttl, req.Replace,
+	)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &ImportNodeScoresResponse{}, nil
+}
+
+// QueryNodeScores returns all imported node scores that didn't expire yet.
+func (s *Server) QueryNodeScores(_ context.Context,
+	_ *QueryNodeScoresRequest) (*QueryNodeScoresResponse, error) {
+
+	scores := s.cfg.RouterBackend.NodeScores.Scores()
+
+	resp := &QueryNodeScoresResponse{
+		Scores: make([]*NodeScoreEntry, 0, len(scores)),
+	}
+	for _, score := range scores {
+		score := score
+
+		entry := &NodeScoreEntry{
+			PubKey:     score.Node[:],
+			Score:      score.Score,
+			Source:     score.Source,
+			ImportTime: score.ImportTime.Unix(),
+		}
+		if !score.Expiry.IsZero() {
+			entry.ExpiryTime = score.Expiry.Unix()
+		}
+
+		resp.Scores = append(resp.Scores, entry)
+	}
+
+	return resp, nil
+}
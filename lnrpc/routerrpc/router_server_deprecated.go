@@ -46,6 +46,10 @@ func (i *legacyTrackPaymentServer) Send(p *lnrpc.Payment) error {
 		case lnrpc.PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE:
 			state = PaymentState_FAILED_INSUFFICIENT_BALANCE
 
+		// The deprecated states don't distinguish canceled payments.
+		case lnrpc.PaymentFailureReason_FAILURE_REASON_CANCELED:
+			state = PaymentState_FAILED_ERROR
+
 		default:
 			return fmt.Errorf("unknown failure reason %v",
 				p.FailureReason)
@@ -138,9 +142,15 @@ func (s *Server) QueryProbability(_ context.Context,
 
 	amt := lnwire.MilliSatoshi(req.AmtMsat)
 
+	mc, err := s.cfg.RouterBackend.missionControl(
+		req.MissionControlNamespace, false,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// Compute the probability.
 	var prob float64
-	mc := s.cfg.RouterBackend.MissionControl
 	capacity, err := s.cfg.RouterBackend.FetchAmountPairCapacity(
 		fromNode, toNode, amt,
 	)
@@ -7,13 +7,19 @@ import (
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/queue"
+	"github.com/lightningnetwork/lnd/record"
 	"github.com/lightningnetwork/lnd/routing"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/subscribe"
 	"github.com/lightningnetwork/lnd/zpay32"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type streamMock struct {
@@ -218,6 +224,114 @@ func TestTrackPaymentsNoInflightUpdates(t *testing.T) {
 	require.Equal(t, lnrpc.Payment_SUCCEEDED, payment.Status)
 }
 
+// TestTrackPaymentsFilters tests that only the payments that match the
+// filters of the request are streamed back, and that completed payments are
+// backfilled if requested.
+func TestTrackPaymentsFilters(t *testing.T) {
+	request := &TrackPaymentsRequest{
+		StatusFilter: []lnrpc.Payment_PaymentStatus{
+			lnrpc.Payment_SUCCEEDED,
+		},
+		MinAmtMsat: 500,
+		Backfill:   true,
+	}
+	towerMock := makeControlTowerMock()
+
+	streamCtx, cancelStream := context.WithCancel(context.Background())
+	stream := makeStreamMock(streamCtx)
+	defer cancelStream()
+
+	payment := func(value lnwire.MilliSatoshi,
+		status channeldb.PaymentStatus) *channeldb.MPPayment {
+
+		return &channeldb.MPPayment{
+			Info: &channeldb.PaymentCreationInfo{
+				Value: value,
+			},
+			Status: status,
+		}
+	}
+
+	stored := []*channeldb.MPPayment{
+		payment(1_000, channeldb.StatusSucceeded),
+		payment(1_000, channeldb.StatusFailed),
+		payment(100, channeldb.StatusSucceeded),
+
+		// In-flight payments are not backfilled, as they are
+		// delivered by the subscription.
+		payment(2_000, channeldb.StatusInFlight),
+	}
+
+	server := &Server{
+		cfg: &Config{
+			RouterBackend: &RouterBackend{
+				Tower: towerMock,
+				QueryPayments: func(channeldb.PaymentsQuery) (
+					channeldb.PaymentsResponse, error) {
+
+					return channeldb.PaymentsResponse{
+						Payments: stored,
+					}, nil
+				},
+			},
+		},
+	}
+
+	go func() {
+		err := server.TrackPayments(request, stream)
+		require.Equal(t, context.Canceled, err)
+	}()
+
+	towerMock.queue.ChanIn() <- payment(2_000, channeldb.StatusInFlight)
+	towerMock.queue.ChanIn() <- payment(200, channeldb.StatusSucceeded)
+	towerMock.queue.ChanIn() <- payment(2_000, channeldb.StatusSucceeded)
+
+	// Only the backfilled payment and the last update match the filters.
+	for _, value := range []int64{1_000, 2_000} {
+		select {
+		case p := <-stream.sentFromServer:
+			require.Equal(t, lnrpc.Payment_SUCCEEDED, p.Status)
+			require.Equal(t, value, p.ValueMsat)
+
+		case <-time.After(time.Second):
+			require.FailNow(t, "deadline exceeded.")
+		}
+	}
+
+	select {
+	case p := <-stream.sentFromServer:
+		require.FailNow(t, "unexpected payment", p)
+
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestNewPaymentFilter tests that invalid payment filters are rejected and
+// that the creation date range is applied in seconds.
+func TestNewPaymentFilter(t *testing.T) {
+	_, err := newPaymentFilter(&TrackPaymentsRequest{
+		CreationDateStart: 10,
+		CreationDateEnd:   5,
+	})
+	require.Error(t, err)
+
+	_, err = newPaymentFilter(&TrackPaymentsRequest{
+		MinAmtMsat: -1,
+	})
+	require.Error(t, err)
+
+	filter, err := newPaymentFilter(&TrackPaymentsRequest{
+		CreationDateStart: 10,
+		CreationDateEnd:   20,
+	})
+	require.NoError(t, err)
+
+	require.True(t, filter(&lnrpc.Payment{CreationTimeNs: 15e9}))
+	require.True(t, filter(&lnrpc.Payment{CreationTimeNs: 20e9 + 1}))
+	require.False(t, filter(&lnrpc.Payment{CreationTimeNs: 9e9}))
+	require.False(t, filter(&lnrpc.Payment{CreationTimeNs: 21e9}))
+}
+
 // TestIsLsp tests the isLSP heuristic. Combinations of different route hints
 // with different fees and cltv deltas are tested to ensure that the heuristic
 // correctly identifies whether a route leads to an LSP or not.
@@ -400,3 +514,423 @@ func TestIsLsp(t *testing.T) {
 		})
 	}
 }
+
+// snapshotMissionControl is a mission control mock that returns a fixed
+// history snapshot.
+type snapshotMissionControl struct {
+	MissionControl
+
+	snapshot *routing.MissionControlSnapshot
+}
+
+func (m *snapshotMissionControl) GetHistorySnapshot() *routing.
+	MissionControlSnapshot {
+
+	return m.snapshot
+}
+
+// TestQueryMissionControlFilters tests the filtering and paging of the pairs
+// returned by QueryMissionControl.
+func TestQueryMissionControlFilters(t *testing.T) {
+	t.Parallel()
+
+	var nodeA, nodeB, nodeC route.Vertex
+	nodeA[0], nodeB[0], nodeC[0] = 1, 2, 3
+
+	now := time.Unix(1000, 0)
+	pair := func(from, to route.Vertex, ts time.Time,
+		amt lnwire.MilliSatoshi) routing.MissionControlPairSnapshot {
+
+		return routing.MissionControlPairSnapshot{
+			Pair: routing.NewDirectedNodePair(from, to),
+			TimedPairResult: routing.TimedPairResult{
+				SuccessTime: ts,
+				SuccessAmt:  amt,
+			},
+		}
+	}
+
+	// The pairs are intentionally not sorted.
+	mc := &snapshotMissionControl{
+		snapshot: &routing.MissionControlSnapshot{
+			Pairs: []routing.MissionControlPairSnapshot{
+				pair(nodeC, nodeA, now, 3000),
+				pair(nodeA, nodeC, now.Add(-time.Hour), 1000),
+				pair(nodeB, nodeC, now, 2000),
+				pair(nodeA, nodeB, now, 5000),
+			},
+		},
+	}
+
+	server := &Server{
+		cfg: &Config{
+			RouterBackend: &RouterBackend{
+				MissionControl: mc,
+			},
+		},
+	}
+
+	query := func(req *QueryMissionControlRequest) [][2]route.Vertex {
+		resp, err := server.QueryMissionControl(
+			context.Background(), req,
+		)
+		require.NoError(t, err)
+
+		pairs := make([][2]route.Vertex, 0, len(resp.Pairs))
+		for _, p := range resp.Pairs {
+			var from, to route.Vertex
+			copy(from[:], p.NodeFrom)
+			copy(to[:], p.NodeTo)
+			pairs = append(pairs, [2]route.Vertex{from, to})
+		}
+
+		return pairs
+	}
+
+	// Without filters, all pairs are returned in sorted order.
+	require.Equal(t, [][2]route.Vertex{
+		{nodeA, nodeB}, {nodeA, nodeC}, {nodeB, nodeC}, {nodeC, nodeA},
+	}, query(&QueryMissionControlRequest{}))
+
+	// Filter by node.
+	require.Equal(t, [][2]route.Vertex{
+		{nodeA, nodeB}, {nodeB, nodeC},
+	}, query(&QueryMissionControlRequest{Node: nodeB[:]}))
+
+	// Filter by observation time and amount.
+	require.Equal(t, [][2]route.Vertex{
+		{nodeA, nodeB}, {nodeC, nodeA},
+	}, query(&QueryMissionControlRequest{
+		MinObservationTime: now.Unix(),
+		MinAmtMsat:         2500,
+	}))
+
+	// Page through the results.
+	resp, err := server.QueryMissionControl(
+		context.Background(), &QueryMissionControlRequest{
+			IndexOffset: 1,
+			MaxPairs:    2,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, resp.Pairs, 2)
+	require.EqualValues(t, 3, resp.LastIndexOffset)
+	require.EqualValues(t, 4, resp.TotalNumPairs)
+
+	require.Equal(t, [][2]route.Vertex{
+		{nodeC, nodeA},
+	}, query(&QueryMissionControlRequest{
+		IndexOffset: resp.LastIndexOffset,
+		MaxPairs:    2,
+	}))
+
+	// An offset beyond the last pair returns an empty page.
+	require.Empty(t, query(&QueryMissionControlRequest{IndexOffset: 10}))
+}
+
+// configMissionControl is a mission control mock that stores its config.
+type configMissionControl struct {
+	MissionControl
+
+	cfg *routing.MissionControlConfig
+}
+
+func (m *configMissionControl) GetConfig() *routing.MissionControlConfig {
+	return m.cfg
+}
+
+func (m *configMissionControl) SetConfig(
+	cfg *routing.MissionControlConfig) error {
+
+	m.cfg = cfg
+	return nil
+}
+
+type mcCfgStreamMock struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *MissionControlConfigEvent
+}
+
+func (m *mcCfgStreamMock) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mcCfgStreamMock) Send(e *MissionControlConfigEvent) error {
+	m.events <- e
+	return nil
+}
+
+// TestSubscribeMissionControlConfig tests that subscribers are notified about
+// mission control config changes.
+func TestSubscribeMissionControlConfig(t *testing.T) {
+	t.Parallel()
+
+	estimator, err := routing.NewAprioriEstimator(
+		routing.DefaultAprioriConfig(),
+	)
+	require.NoError(t, err)
+
+	mc := &configMissionControl{
+		cfg: &routing.MissionControlConfig{Estimator: estimator},
+	}
+
+	server := &Server{
+		cfg: &Config{
+			RouterBackend: &RouterBackend{
+				MissionControl:          mc,
+				SetMissionControlConfig: mc.SetConfig,
+			},
+		},
+		mcCfgUpdates: subscribe.NewServer(),
+		quit:         make(chan struct{}),
+	}
+	require.NoError(t, server.Start())
+	t.Cleanup(func() {
+		require.NoError(t, server.Stop())
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &mcCfgStreamMock{
+		ctx:    ctx,
+		events: make(chan *MissionControlConfigEvent, 10),
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.SubscribeMissionControlConfig(
+			&SubscribeMissionControlConfigRequest{}, stream,
+		)
+	}()
+
+	// The currently active config is sent first.
+	select {
+	case event := <-stream.events:
+		require.Equal(t, MissionControlConfig_APRIORI,
+			event.Config.Model)
+		require.Zero(t, event.Timestamp)
+
+	case <-time.After(time.Second):
+		t.Fatal("expected initial config event")
+	}
+
+	// An invalid config is rejected and must not be announced.
+	_, err = server.SetMissionControlConfig(
+		context.Background(), &SetMissionControlConfigRequest{
+			Config: &MissionControlConfig{
+				Model: MissionControlConfig_BIMODAL,
+				EstimatorConfig: &MissionControlConfig_Bimodal{
+					Bimodal: &BimodalParameters{
+						NodeWeight: 2,
+					},
+				},
+			},
+		},
+	)
+	require.Error(t, err)
+
+	_, err = server.SetMissionControlConfig(
+		context.Background(), &SetMissionControlConfigRequest{
+			Config: &MissionControlConfig{
+				Model: MissionControlConfig_BIMODAL,
+				EstimatorConfig: &MissionControlConfig_Bimodal{
+					Bimodal: &BimodalParameters{
+						NodeWeight: 0.3,
+						ScaleMsat:  1000,
+						DecayTime:  3600,
+					},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	select {
+	case event := <-stream.events:
+		require.Equal(t, MissionControlConfig_BIMODAL,
+			event.Config.Model)
+		require.Equal(t, 0.3, event.Config.GetBimodal().NodeWeight)
+		require.NotZero(t, event.Timestamp)
+
+	case <-time.After(time.Second):
+		t.Fatal("expected config update event")
+	}
+
+	cancel()
+	select {
+	case err := <-errChan:
+		require.ErrorIs(t, err, context.Canceled)
+
+	case <-time.After(time.Second):
+		t.Fatal("expected subscription to return")
+	}
+}
+
+// TestAddHopCustomRecords tests that custom records are attached to the
+// requested hops of a route.
+func TestAddHopCustomRecords(t *testing.T) {
+	t.Parallel()
+
+	rt := &route.Route{
+		Hops: []*route.Hop{
+			{PubKeyBytes: route.Vertex{1}},
+			{
+				PubKeyBytes: route.Vertex{2},
+				CustomRecords: record.CustomSet{
+					70000: []byte{1},
+				},
+			},
+		},
+	}
+
+	err := addHopCustomRecords(rt, []*HopCustomRecords{{
+		HopIndex:      0,
+		CustomRecords: map[uint64][]byte{65536: {2}},
+	}, {
+		HopIndex:      1,
+		CustomRecords: map[uint64][]byte{65537: {3}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, record.CustomSet{65536: {2}}, rt.Hops[0].CustomRecords)
+	require.Equal(t, record.CustomSet{
+		65537: {3},
+		70000: {1},
+	}, rt.Hops[1].CustomRecords)
+
+	// Records outside of the custom range are rejected.
+	err = addHopCustomRecords(rt, []*HopCustomRecords{{
+		HopIndex:      0,
+		CustomRecords: map[uint64][]byte{1: {2}},
+	}})
+	require.Error(t, err)
+
+	// Hops that aren't part of the route are rejected.
+	err = addHopCustomRecords(rt, []*HopCustomRecords{{
+		HopIndex:      2,
+		CustomRecords: map[uint64][]byte{65536: {2}},
+	}})
+	require.Error(t, err)
+}
+
+// TestResurrectZombieChannel tests that a zombie channel that was removed from
+// the graph is added back and that unknown zombies are reported as not found.
+func TestResurrectZombieChannel(t *testing.T) {
+	t.Parallel()
+
+	db, err := channeldb.MakeTestDB(t)
+	require.NoError(t, err)
+	graph := db.ChannelGraph()
+
+	var nodes [2]route.Vertex
+	for i := range nodes {
+		priv, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+		copy(nodes[i][:], priv.PubKey().SerializeCompressed())
+	}
+
+	require.NoError(t, graph.SetSourceNode(&channeldb.LightningNode{
+		HaveNodeAnnouncement: true,
+		PubKeyBytes:          nodes[0],
+		LastUpdate:           time.Unix(1, 0),
+		Features:             lnwire.EmptyFeatureVector(),
+	}))
+
+	const chanID = 100
+	edge := &models.ChannelEdgeInfo{
+		ChannelID:        chanID,
+		NodeKey1Bytes:    nodes[0],
+		NodeKey2Bytes:    nodes[1],
+		BitcoinKey1Bytes: nodes[0],
+		BitcoinKey2Bytes: nodes[1],
+		Capacity:         100000,
+	}
+	require.NoError(t, graph.AddChannelEdge(edge))
+	require.NoError(t, graph.DeleteChannelEdges(false, true, chanID))
+
+	router, err := routing.New(routing.Config{
+		Graph:              graph,
+		AssumeChannelValid: true,
+	})
+	require.NoError(t, err)
+
+	server := &Server{cfg: &Config{Router: router}}
+
+	_, err = server.ResurrectZombieChannel(
+		context.Background(),
+		&ResurrectZombieChannelRequest{ChanId: chanID},
+	)
+	require.NoError(t, err)
+
+	_, _, exists, isZombie, err := graph.HasChannelEdge(chanID)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.False(t, isZombie)
+
+	resp, err := server.ListZombieChannels(
+		context.Background(), &ListZombieChannelsRequest{},
+	)
+	require.NoError(t, err)
+	require.Empty(t, resp.Channels)
+
+	_, err = server.ResurrectZombieChannel(
+		context.Background(),
+		&ResurrectZombieChannelRequest{ChanId: chanID},
+	)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// TestUpdatePaymentLimitsCltvLimit tests that an updated cltv limit is
+// validated against the maximum total time lock like the limit of a new
+// payment.
+func TestUpdatePaymentLimitsCltvLimit(t *testing.T) {
+	t.Parallel()
+
+	db, err := channeldb.MakeTestDB(t)
+	require.NoError(t, err)
+	graph := db.ChannelGraph()
+
+	priv, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var source route.Vertex
+	copy(source[:], priv.PubKey().SerializeCompressed())
+	require.NoError(t, graph.SetSourceNode(&channeldb.LightningNode{
+		HaveNodeAnnouncement: true,
+		PubKeyBytes:          source,
+		LastUpdate:           time.Unix(1, 0),
+		Features:             lnwire.EmptyFeatureVector(),
+	}))
+
+	router, err := routing.New(routing.Config{
+		Graph: graph,
+	})
+	require.NoError(t, err)
+
+	server := &Server{cfg: &Config{
+		Router: router,
+		RouterBackend: &RouterBackend{
+			MaxTotalTimelock: 1000,
+		},
+	}}
+
+	paymentHash := make([]byte, 32)
+
+	// A cltv limit beyond the maximum total time lock is rejected.
+	_, err = server.UpdatePaymentLimits(
+		context.Background(), &UpdatePaymentLimitsRequest{
+			PaymentHash: paymentHash,
+			CltvLimit:   1001,
+		},
+	)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// A valid cltv limit is passed on to the router, which doesn't know
+	// the payment.
+	_, err = server.UpdatePaymentLimits(
+		context.Background(), &UpdatePaymentLimitsRequest{
+			PaymentHash: paymentHash,
+			CltvLimit:   1000,
+		},
+	)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
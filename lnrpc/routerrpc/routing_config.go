@@ -28,6 +28,11 @@ type RoutingConfig struct {
 	// expressed in parts per million of the total payment amount.
 	AttemptCostPPM int64 `long:"attemptcostppm" description:"The proportional (virtual) cost in sats of a failed payment attempt expressed in parts per million of the total payment amount"`
 
+	// CltvRiskFactor is the weight of the time lock of a route relative to
+	// its fees in path finding. It is expressed in billionths of msat per
+	// msat sent through a channel per time lock delta block.
+	CltvRiskFactor int64 `long:"cltvriskfactor" description:"The weight of the time lock of a route relative to its fees in path finding, expressed in billionths of msat per msat sent through a channel per time lock delta block. Higher values favor routes with a shorter worst case lockup over cheaper ones"`
+
 	// MaxMcHistory defines the maximum number of payment results that
 	// are held on disk by mission control.
 	MaxMcHistory int `long:"maxmchistory" description:"the maximum number of payment results that are held on disk by mission control"`
@@ -44,6 +49,31 @@ type RoutingConfig struct {
 
 	// FeeEstimationTimeout is the maximum time to wait for routing fees to be estimated.
 	FeeEstimationTimeout time.Duration `long:"fee-estimation-timeout" description:"the maximum time to wait for routing fees to be estimated by payment probes"`
+
+	// RouteCacheSize is the maximum number of recently found paths that
+	// are cached for reuse by payments to the same destinations. A value
+	// of zero disables the route cache.
+	RouteCacheSize int `long:"routecachesize" description:"the maximum number of recently found paths that are cached for reuse by payments to the same destination, 0 disables the cache"`
+
+	// RouteCacheTTL is the maximum duration for which a cached path is
+	// reused.
+	RouteCacheTTL time.Duration `long:"routecachettl" description:"the maximum duration for which a cached path is reused"`
+
+	// SplitStrategy is the default strategy to split payments into shards
+	// if no route can be found for the full amount.
+	SplitStrategy string `long:"splitstrategy" choice:"halving" choice:"capacity" choice:"probability" description:"The default strategy to split payments into shards if no route can be found for the full amount."`
+
+	// StalePaymentTimeout is the age after which payments that are in
+	// flight without any outstanding HTLCs are failed. A value of zero
+	// disables the periodic sweep.
+	StalePaymentTimeout time.Duration `long:"stalepaymenttimeout" description:"the age after which payments that are in flight without any outstanding HTLCs are periodically failed, 0 disables the sweep"`
+
+	// UseNodeScores indicates whether node scores that are imported from
+	// external sources are taken into account in pathfinding.
+	UseNodeScores bool `long:"usenodescores" description:"if set, the success probabilities of pathfinding are multiplied with the node scores that were imported from external sources via ImportNodeScores"`
+
+	// ProberConfig defines parameters for the background payment prober.
+	ProberConfig *ProberConfig `group:"prober" namespace:"prober" description:"configuration for the background payment prober"`
 }
 
 // AprioriConfig defines parameters for the apriori probability.
@@ -89,3 +119,28 @@ type BimodalConfig struct {
 	// time for previous successes or failures.
 	DecayTime time.Duration `long:"decaytime" description:"Describes the information decay of knowledge about previous successes and failures in channels."`
 }
+
+// ProberConfig defines parameters for the background payment prober.
+//
+//nolint:lll
+type ProberConfig struct {
+	// Enable activates the background payment prober.
+	Enable bool `long:"enable" description:"If set, probes that can't be settled are periodically sent to the configured destinations to keep mission control up to date."`
+
+	// Destinations are the hex encoded public keys of the nodes to probe.
+	Destinations []string `long:"destination" description:"The public key of a node to probe. Can be specified multiple times."`
+
+	// Interval is the time between two rounds of probes.
+	Interval time.Duration `long:"interval" description:"The time between two rounds of probes to all destinations."`
+
+	// Amt is the amount of each probe.
+	Amt btcutil.Amount `long:"amt" description:"The amount in satoshis of each probe."`
+
+	// Timeout is the maximum time a single probe may take.
+	Timeout time.Duration `long:"timeout" description:"The maximum time a single probe may take."`
+
+	// DailyBudget is the maximum number of probes that are sent within 24
+	// hours. A value of zero means that the number of probes is not
+	// limited.
+	DailyBudget uint32 `long:"dailybudget" description:"The maximum number of probes sent within 24 hours, 0 means unlimited."`
+}
@@ -43,6 +43,8 @@ import (
 	"github.com/lightningnetwork/lnd/macaroons"
 	"github.com/lightningnetwork/lnd/sweep"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gopkg.in/macaroon-bakery.v2/bakery"
 )
 
@@ -671,6 +673,22 @@ func (w *WalletKit) PublishTransaction(ctx context.Context,
 		return nil, err
 	}
 
+	// A transaction that spends any of our wallet's coins may only pay out
+	// to the allowed addresses. We don't restrict publishing transactions
+	// of other wallets.
+	for _, txIn := range tx.TxIn {
+		_, err := w.cfg.Wallet.FetchInputInfo(&txIn.PreviousOutPoint)
+		if err != nil {
+			continue
+		}
+
+		if err := w.checkSendOutputs(tx.TxOut); err != nil {
+			return nil, err
+		}
+
+		break
+	}
+
 	err = w.cfg.Wallet.PublishTransaction(tx, label)
 	if err != nil {
 		return nil, err
@@ -764,6 +782,12 @@ func (w *WalletKit) SendOutputs(ctx context.Context,
 		totalOutputValue += output.Value
 	}
 
+	// Funds may only be sent to the allowed addresses if the node
+	// restricts its payout addresses.
+	if err := w.checkSendOutputs(outputsToCreate); err != nil {
+		return nil, err
+	}
+
 	// Then, we'll extract the minimum number of confirmations that each
 	// output we use to fund the transaction should satisfy.
 	minConfs, err := lnrpc.ExtractMinConfs(
@@ -900,6 +924,7 @@ func (w *WalletKit) PendingSweeps(ctx context.Context,
 			Budget:               uint64(inp.Params.Budget),
 			DeadlineHeight:       inp.DeadlineHeight,
 			RequestedSatPerVbyte: startingFeeRate,
+			Deferred:             inp.Deferred,
 		}
 		rpcPendingSweeps = append(rpcPendingSweeps, ps)
 	}
@@ -1533,6 +1558,12 @@ func (w *WalletKit) fundPsbtInternalWallet(account string,
 	feeSatPerKW chainfee.SatPerKWeight,
 	strategy base.CoinSelectionStrategy) (*FundPsbtResponse, error) {
 
+	// We won't fund a transaction that pays out to an address we aren't
+	// allowed to send funds to.
+	if err := w.checkSendOutputs(packet.UnsignedTx.TxOut); err != nil {
+		return nil, err
+	}
+
 	// The RPC parsing part is now over. Several of the following operations
 	// require us to hold the global coin selection lock, so we do the rest
 	// of the tasks while holding the lock. The result is a list of locked
@@ -1666,6 +1697,12 @@ func (w *WalletKit) fundPsbtCoinSelect(account string, changeIndex int32,
 	feeRate chainfee.SatPerKWeight, strategy base.CoinSelectionStrategy) (
 	*FundPsbtResponse, error) {
 
+	// We won't fund a transaction that pays out to an address we aren't
+	// allowed to send funds to.
+	if err := w.checkSendOutputs(packet.UnsignedTx.TxOut); err != nil {
+		return nil, err
+	}
+
 	// We want to make sure we don't select any inputs that are already
 	// specified in the template. To do that, we require those inputs to
 	// either not belong to this lnd at all or to be already locked through
@@ -2074,6 +2111,12 @@ func (w *WalletKit) SignPsbt(_ context.Context, req *SignPsbtRequest) (
 		}
 	}
 
+	// Our signatures must not authorize paying out to an address we
+	// aren't allowed to send funds to.
+	if err := w.checkSendOutputs(packet.UnsignedTx.TxOut); err != nil {
+		return nil, err
+	}
+
 	// Let the wallet do the heavy lifting. This will sign all inputs that
 	// we have the UTXO for. If some inputs can't be signed and don't have
 	// witness data attached, they will just be skipped.
@@ -2130,6 +2173,12 @@ func (w *WalletKit) FinalizePsbt(_ context.Context,
 		return nil, fmt.Errorf("PSBT is already fully signed")
 	}
 
+	// Our signatures must not authorize paying out to an address we
+	// aren't allowed to send funds to.
+	if err := w.checkSendOutputs(packet.UnsignedTx.TxOut); err != nil {
+		return nil, err
+	}
+
 	// Let the wallet do the heavy lifting. This will sign all inputs that
 	// we have the UTXO for. If some inputs can't be signed and don't have
 	// witness data attached, this will fail.
@@ -2163,6 +2212,22 @@ func (w *WalletKit) FinalizePsbt(_ context.Context,
 	}, nil
 }
 
+// checkSendOutputs returns a PermissionDenied error if any of the outputs pays
+// to a script that the node doesn't allow wallet funds to be sent to.
+func (w *WalletKit) checkSendOutputs(outputs []*wire.TxOut) error {
+	if w.cfg.CheckSendScript == nil {
+		return nil
+	}
+
+	for _, output := range outputs {
+		if err := w.cfg.CheckSendScript(output.PkScript); err != nil {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+
+	return nil
+}
+
 // marshalWalletAccount converts the properties of an account into its RPC
 // representation.
 func marshalWalletAccount(internalScope waddrmgr.KeyScope,
@@ -5,6 +5,7 @@ package walletrpc
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -13,16 +14,21 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcwallet/wallet"
 	"github.com/lightningnetwork/lnd/input"
+	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
 	"github.com/lightningnetwork/lnd/lntest/mock"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwallet/chanfunding"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // TestWitnessTypeMapping tests that the two witness type enums in the `input`
@@ -631,3 +637,139 @@ func TestFundPsbtCoinSelect(t *testing.T) {
 		})
 	}
 }
+
+// TestSendScriptAllowlist tests that every RPC that lets wallet funds be paid
+// out rejects outputs that the node isn't allowed to send funds to.
+func TestSendScriptAllowlist(t *testing.T) {
+	t.Parallel()
+
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	params := &chaincfg.RegressionNetParams
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(privKey.PubKey().SerializeCompressed()), params,
+	)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	errNotAllowed := fmt.Errorf("not allowed")
+	walletMock := &mock.WalletController{
+		RootKey:               privKey,
+		PublishedTransactions: make(chan *wire.MsgTx, 1),
+	}
+	rpcServer, _, err := New(&Config{
+		Wallet:                walletMock,
+		CoinSelectionLocker:   &mockCoinSelectionLocker{},
+		CoinSelectionStrategy: wallet.CoinSelectionLargest,
+		ChainParams:           params,
+		CheckSendScript: func(script lnwire.DeliveryAddress) error {
+			if bytes.Equal(script, pkScript) {
+				return errNotAllowed
+			}
+
+			return nil
+		},
+	})
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{})
+	tx.AddTxOut(wire.NewTxOut(100_000, pkScript))
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	require.NoError(t, err)
+	packet.Inputs[0].WitnessUtxo = wire.NewTxOut(200_000, pkScript)
+
+	var packetBuf bytes.Buffer
+	require.NoError(t, packet.Serialize(&packetBuf))
+	rawPacket := packetBuf.Bytes()
+
+	var txBuf bytes.Buffer
+	require.NoError(t, tx.Serialize(&txBuf))
+
+	requireNotAllowed := func(t *testing.T, err error) {
+		require.Error(t, err)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		require.ErrorContains(t, err, errNotAllowed.Error())
+	}
+
+	t.Run("send outputs", func(t *testing.T) {
+		_, err := rpcServer.SendOutputs(
+			context.Background(), &SendOutputsRequest{
+				SatPerKw: 1000,
+				Outputs: []*signrpc.TxOut{{
+					Value:    100_000,
+					PkScript: pkScript,
+				}},
+			},
+		)
+		requireNotAllowed(t, err)
+	})
+
+	t.Run("fund psbt raw template", func(t *testing.T) {
+		_, err := rpcServer.FundPsbt(
+			context.Background(), &FundPsbtRequest{
+				Template: &FundPsbtRequest_Raw{
+					Raw: &TxTemplate{
+						Outputs: map[string]uint64{
+							addr.String(): 100_000,
+						},
+					},
+				},
+				Fees: &FundPsbtRequest_SatPerVbyte{
+					SatPerVbyte: 10,
+				},
+			},
+		)
+		requireNotAllowed(t, err)
+	})
+
+	t.Run("fund psbt coin select", func(t *testing.T) {
+		_, err := rpcServer.FundPsbt(
+			context.Background(), &FundPsbtRequest{
+				Template: &FundPsbtRequest_CoinSelect{
+					CoinSelect: &PsbtCoinSelect{
+						Psbt: rawPacket,
+						ChangeOutput: &PsbtCoinSelect_Add{
+							Add: true,
+						},
+					},
+				},
+				Fees: &FundPsbtRequest_SatPerVbyte{
+					SatPerVbyte: 10,
+				},
+			},
+		)
+		requireNotAllowed(t, err)
+	})
+
+	t.Run("sign psbt", func(t *testing.T) {
+		_, err := rpcServer.SignPsbt(
+			context.Background(), &SignPsbtRequest{
+				FundedPsbt: rawPacket,
+			},
+		)
+		requireNotAllowed(t, err)
+	})
+
+	t.Run("finalize psbt", func(t *testing.T) {
+		_, err := rpcServer.FinalizePsbt(
+			context.Background(), &FinalizePsbtRequest{
+				FundedPsbt: rawPacket,
+			},
+		)
+		requireNotAllowed(t, err)
+	})
+
+	t.Run("publish transaction", func(t *testing.T) {
+		_, err := rpcServer.PublishTransaction(
+			context.Background(), &Transaction{
+				TxHex: txBuf.Bytes(),
+			},
+		)
+		requireNotAllowed(t, err)
+		require.Empty(t, walletMock.PublishedTransactions)
+	})
+}
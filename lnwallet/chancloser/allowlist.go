@@ -0,0 +1,100 @@
+package chancloser
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// ErrDeliveryAddrNotAllowed is returned when a delivery address is neither
+// part of the delivery allowlist nor owned by our wallet.
+var ErrDeliveryAddrNotAllowed = errors.New("delivery address is not in " +
+	"the allowlist of close addresses")
+
+// DeliveryAllowlist restricts the external addresses that channel and wallet
+// funds may be paid out to on cooperative close and when sending on-chain.
+// Addresses derived from the default account of our own wallet are always
+// allowed, so the funds of channels that are closed without an explicit
+// delivery address are unaffected. Addresses of imported keys and accounts
+// are not considered our own, as they can be added through the RPC.
+type DeliveryAllowlist struct {
+	// scripts is the set of allowed output scripts.
+	scripts map[string]struct{}
+
+	// params are the parameters of the active chain.
+	params *chaincfg.Params
+
+	// addressInfo returns the information about an address if it is
+	// known to our wallet.
+	addressInfo func(btcutil.Address) (waddrmgr.ManagedAddress, error)
+}
+
+// NewDeliveryAllowlist creates a DeliveryAllowlist from the given addresses.
+// If no addresses are given, all delivery addresses are allowed.
+func NewDeliveryAllowlist(addresses []string, params *chaincfg.Params,
+	addressInfo func(btcutil.Address) (waddrmgr.ManagedAddress, error)) (
+	*DeliveryAllowlist, error) {
+
+	scripts := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		script, err := ParseUpfrontShutdownAddress(address, params)
+		if err != nil {
+			return nil, err
+		}
+		if len(script) == 0 {
+			return nil, fmt.Errorf("empty close address")
+		}
+
+		scripts[string(script)] = struct{}{}
+	}
+
+	return &DeliveryAllowlist{
+		scripts:     scripts,
+		params:      params,
+		addressInfo: addressInfo,
+	}, nil
+}
+
+// Active returns true if the allowlist restricts the delivery addresses.
+func (d *DeliveryAllowlist) Active() bool {
+	return len(d.scripts) != 0
+}
+
+// Check returns ErrDeliveryAddrNotAllowed if the allowlist is active and the
+// delivery script pays out to an address that is neither part of it nor
+// derived from the default account of our wallet. An empty script, which
+// denotes that a fresh wallet address is used, is always allowed.
+func (d *DeliveryAllowlist) Check(script lnwire.DeliveryAddress) error {
+	if len(d.scripts) == 0 || len(script) == 0 {
+		return nil
+	}
+
+	if _, ok := d.scripts[string(script)]; ok {
+		return nil
+	}
+
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(script, d.params)
+	if err == nil && len(addrs) == 1 && d.isWalletAddress(addrs[0]) {
+		return nil
+	}
+
+	return ErrDeliveryAddrNotAllowed
+}
+
+// isWalletAddress returns true if the address is derived from the default
+// account of our wallet. Imported addresses and addresses of imported
+// accounts are rejected, as they may be watch-only.
+func (d *DeliveryAllowlist) isWalletAddress(addr btcutil.Address) bool {
+	info, err := d.addressInfo(addr)
+	if err != nil {
+		return false
+	}
+
+	return !info.Imported() &&
+		info.InternalAccount() == waddrmgr.DefaultAccountNum
+}
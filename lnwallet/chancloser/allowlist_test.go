@@ -0,0 +1,119 @@
+package chancloser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/stretchr/testify/require"
+)
+
+// mockManagedAddress is a waddrmgr.ManagedAddress that only implements the
+// methods used by the DeliveryAllowlist.
+type mockManagedAddress struct {
+	waddrmgr.ManagedAddress
+
+	imported bool
+	account  uint32
+}
+
+func (m *mockManagedAddress) Imported() bool {
+	return m.imported
+}
+
+func (m *mockManagedAddress) InternalAccount() uint32 {
+	return m.account
+}
+
+// TestDeliveryAllowlist tests that the DeliveryAllowlist only allows delivery
+// addresses that are part of the allowlist or derived from the default
+// account of our wallet.
+func TestDeliveryAllowlist(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.TestNet3Params
+
+	newAddr := func(b byte) btcutil.Address {
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(
+			bytes.Repeat([]byte{b}, 20), params,
+		)
+		require.NoError(t, err)
+
+		return addr
+	}
+	allowedAddr, ourAddr, otherAddr := newAddr(1), newAddr(2), newAddr(3)
+	importedAddr, importedAccountAddr := newAddr(4), newAddr(5)
+
+	addressInfo := func(addr btcutil.Address) (waddrmgr.ManagedAddress,
+		error) {
+
+		switch addr.String() {
+		case ourAddr.String():
+			return &mockManagedAddress{}, nil
+
+		case importedAddr.String():
+			return &mockManagedAddress{
+				imported: true,
+				account:  waddrmgr.ImportedAddrAccount,
+			}, nil
+
+		case importedAccountAddr.String():
+			return &mockManagedAddress{account: 1}, nil
+		}
+
+		return nil, errors.New("address not found")
+	}
+
+	script := func(addr btcutil.Address) []byte {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		require.NoError(t, err)
+
+		return pkScript
+	}
+
+	// Without any addresses, the allowlist is inactive.
+	allowlist, err := NewDeliveryAllowlist(nil, params, addressInfo)
+	require.NoError(t, err)
+	require.False(t, allowlist.Active())
+	require.NoError(t, allowlist.Check(script(otherAddr)))
+
+	allowlist, err = NewDeliveryAllowlist(
+		[]string{allowedAddr.String()}, params, addressInfo,
+	)
+	require.NoError(t, err)
+	require.True(t, allowlist.Active())
+
+	require.NoError(t, allowlist.Check(nil))
+	require.NoError(t, allowlist.Check(script(allowedAddr)))
+	require.NoError(t, allowlist.Check(script(ourAddr)))
+	require.ErrorIs(
+		t, allowlist.Check(script(otherAddr)),
+		ErrDeliveryAddrNotAllowed,
+	)
+
+	// Watch-only addresses that were imported into the wallet through the
+	// RPC must not bypass the allowlist.
+	require.ErrorIs(
+		t, allowlist.Check(script(importedAddr)),
+		ErrDeliveryAddrNotAllowed,
+	)
+	require.ErrorIs(
+		t, allowlist.Check(script(importedAccountAddr)),
+		ErrDeliveryAddrNotAllowed,
+	)
+
+	// Addresses of other networks are rejected.
+	mainnetAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		bytes.Repeat([]byte{1}, 20), &chaincfg.MainNetParams,
+	)
+	require.NoError(t, err)
+
+	_, err = NewDeliveryAllowlist(
+		[]string{mainnetAddr.String()}, params, addressInfo,
+	)
+	require.Error(t, err)
+}
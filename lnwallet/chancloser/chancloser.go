@@ -245,6 +245,29 @@ func calcCoopCloseFee(chanType channeldb.ChannelType,
 	return idealFeeRate.FeeForWeight(totalWeight)
 }
 
+// maxDeliveryScriptSize is the size of the largest delivery script that can
+// be used in a co-op close, which is a witness program of 40 bytes.
+const maxDeliveryScriptSize = 42
+
+// MaxCoopCloseFeeRate returns the highest fee rate at which the co-op close
+// transaction of a channel of the given type doesn't pay more than the given
+// fee, regardless of the delivery scripts of both parties.
+func MaxCoopCloseFeeRate(chanType channeldb.ChannelType,
+	fee btcutil.Amount) chainfee.SatPerKWeight {
+
+	// A transaction that pays to the largest possible delivery scripts
+	// has the largest weight. At that weight, the fee rate still keeps
+	// the fee within the limit.
+	maxOutput := &wire.TxOut{
+		PkScript: make([]byte, maxDeliveryScriptSize),
+	}
+	maxFee := calcCoopCloseFee(
+		chanType, maxOutput, maxOutput, chainfee.SatPerKWeight(1000),
+	)
+
+	return chainfee.SatPerKWeight(fee * 1000 / maxFee)
+}
+
 // SimpleCoopFeeEstimator is the default co-op close fee estimator. It assumes
 // a normal segwit v0 channel, and that no outputs on the closing transaction
 // are dust.
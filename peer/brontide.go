@@ -332,6 +332,12 @@ type Config struct {
 	// closure initiated by the remote peer.
 	CoopCloseTargetConfs uint32
 
+	// CheckDeliveryScript, if set, returns an error if our funds must not
+	// be paid out to the given delivery script on cooperative close. It
+	// is used to recheck the upfront shutdown script of a channel when it
+	// is closed.
+	CheckDeliveryScript func(lnwire.DeliveryAddress) error
+
 	// ServerPubKey is the serialized, compressed public key of our lnd node.
 	// It is used to determine which policy (channel edge) to pass to the
 	// ChannelLink.
@@ -2708,6 +2714,11 @@ func (p *Brontide) fetchActiveChanCloser(chanID lnwire.ChannelID) (
 	// TODO: Expose option to allow upfront shutdown script from watch-only
 	// accounts.
 	deliveryScript := channel.LocalUpfrontShutdownScript()
+	if err := p.checkDeliveryScript(deliveryScript); err != nil {
+		p.log.Errorf("cannot close channel %v to upfront shutdown "+
+			"script: %v", channel.ChannelPoint(), err)
+		return nil, err
+	}
 	if len(deliveryScript) == 0 {
 		var err error
 		deliveryScript, err = p.genDeliveryScript()
@@ -2943,6 +2954,12 @@ func (p *Brontide) restartCoopClose(lnChan *lnwallet.LightningChannel) (
 
 	case errors.Is(err, channeldb.ErrNoShutdownInfo):
 		deliveryScript = c.LocalShutdownScript
+		if err := p.checkDeliveryScript(deliveryScript); err != nil {
+			p.log.Errorf("cannot close channel %v to upfront "+
+				"shutdown script: %v", c.FundingOutpoint, err)
+
+			return nil, err
+		}
 		if len(deliveryScript) == 0 {
 			var err error
 			deliveryScript, err = p.genDeliveryScript()
@@ -2995,6 +3012,16 @@ func (p *Brontide) restartCoopClose(lnChan *lnwallet.LightningChannel) (
 	return shutdownMsg, nil
 }
 
+// checkDeliveryScript returns an error if our funds must not be paid out to
+// the given delivery script on cooperative close.
+func (p *Brontide) checkDeliveryScript(script lnwire.DeliveryAddress) error {
+	if p.cfg.CheckDeliveryScript == nil {
+		return nil
+	}
+
+	return p.cfg.CheckDeliveryScript(script)
+}
+
 // createChanCloser constructs a ChanCloser from the passed parameters and is
 // used to de-duplicate code.
 func (p *Brontide) createChanCloser(channel *lnwallet.LightningChannel,
@@ -3081,6 +3108,16 @@ func (p *Brontide) handleLocalCloseReq(req *htlcswitch.ChanClose) {
 			return
 		}
 
+		// The upfront shutdown script may have been negotiated before
+		// the allowlist of close addresses was set, so we check it
+		// again before paying out to it.
+		if err := p.checkDeliveryScript(deliveryScript); err != nil {
+			p.log.Errorf("cannot close channel %v: %v",
+				req.ChanPoint, err)
+			req.Err <- err
+			return
+		}
+
 		// If neither an upfront address or a user set address was
 		// provided, generate a fresh script.
 		if len(deliveryScript) == 0 {
@@ -773,6 +773,10 @@ func TestCustomShutdownScript(t *testing.T) {
 		// message.
 		expectedScript lnwire.DeliveryAddress
 
+		// checkScript is the check of delivery scripts that is set
+		// on the peer, if any.
+		checkScript func(lnwire.DeliveryAddress) error
+
 		// expectedError is the error we expect, if any.
 		expectedError error
 	}{
@@ -803,6 +807,14 @@ func TestCustomShutdownScript(t *testing.T) {
 			userCloseScript: []byte("different addr"),
 			expectedError:   chancloser.ErrUpfrontShutdownScriptMismatch,
 		},
+		{
+			name:   "Shutdown set, script not allowed",
+			update: setShutdown,
+			checkScript: func(lnwire.DeliveryAddress) error {
+				return chancloser.ErrDeliveryAddrNotAllowed
+			},
+			expectedError: chancloser.ErrDeliveryAddrNotAllowed,
+		},
 	}
 
 	for _, test := range tests {
@@ -828,6 +840,8 @@ func TestCustomShutdownScript(t *testing.T) {
 			mockLink := newMockUpdateHandler(chanID)
 			mockSwitch.links = append(mockSwitch.links, mockLink)
 
+			alicePeer.cfg.CheckDeliveryScript = test.checkScript
+
 			// Request initiator to cooperatively close the channel,
 			// with a specified delivery address.
 			updateChan := make(chan interface{}, 1)
@@ -862,6 +876,11 @@ func TestCustomShutdownScript(t *testing.T) {
 				return
 			}
 
+			if test.expectedError != nil {
+				t.Fatalf("expected error: %v",
+					test.expectedError)
+			}
+
 			// Check that we have received a shutdown message.
 			shutdownMsg, ok := msg.(*lnwire.Shutdown)
 			if !ok {
@@ -465,6 +465,13 @@ func (m *mockUpdateHandler) OnCommitOnce(
 	hook()
 }
 
+// UpdateCommitFeePolicy currently does nothing.
+func (m *mockUpdateHandler) UpdateCommitFeePolicy(
+	_ channeldb.CommitFeePolicy) error {
+
+	return nil
+}
+
 func newMockConn(t *testing.T, expectedMessages int) *mockMessageConn {
 	return &mockMessageConn{
 		t:               t,
@@ -0,0 +1,264 @@
+package lnd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+const (
+	// defaultPeerFeeReportWindow is the time range that the peer fee
+	// report covers if no start time is given.
+	defaultPeerFeeReportWindow = 30 * 24 * time.Hour
+
+	// peerFeeReportPageSize is the maximum number of payments that are
+	// loaded at once while building the peer fee report.
+	peerFeeReportPageSize = 1000
+)
+
+// peerFeeStats accumulates the fees that were paid to a single peer.
+type peerFeeStats struct {
+	numHtlcs           uint64
+	amtForwarded       lnwire.MilliSatoshi
+	feePaid            lnwire.MilliSatoshi
+	advertisedFee      lnwire.MilliSatoshi
+	numUnknownPolicies uint64
+}
+
+// fetchPolicyFunc returns the policy that the given node advertises for
+// forwarding over the channel with the given ID. It returns nil if the policy
+// isn't known.
+type fetchPolicyFunc func(chanID uint64,
+	from route.Vertex) (*models.ChannelEdgePolicy, error)
+
+// queryPaymentsFunc returns the payments that match the given query.
+type queryPaymentsFunc func(
+	channeldb.PaymentsQuery) (channeldb.PaymentsResponse, error)
+
+// buildPeerFeeReport compares the fees that were paid to the first hops of
+// the settled HTLCs of the payments that match the query with the fees that
+// the policies of the first hops advertised when the HTLCs were sent. The
+// payments are loaded in pages of the query's maximum number of payments.
+func buildPeerFeeReport(query channeldb.PaymentsQuery,
+	queryPayments queryPaymentsFunc,
+	fetchPolicy fetchPolicyFunc) ([]*lnrpc.PeerFeeInsight, error) {
+
+	stats := make(map[route.Vertex]*peerFeeStats)
+	for {
+		resp, err := queryPayments(query)
+		if err != nil {
+			return nil, err
+		}
+
+		err = addPeerFeeStats(stats, resp.Payments, fetchPolicy)
+		if err != nil {
+			return nil, err
+		}
+
+		// A page that isn't full is the last one.
+		if uint64(len(resp.Payments)) < query.MaxPayments {
+			break
+		}
+		query.IndexOffset = resp.LastIndexOffset
+	}
+
+	insights := make([]*lnrpc.PeerFeeInsight, 0, len(stats))
+	for peer, peerStats := range stats {
+		insight := &lnrpc.PeerFeeInsight{
+			PubKey:             peer.String(),
+			NumHtlcs:           peerStats.numHtlcs,
+			AmtForwardedMsat:   uint64(peerStats.amtForwarded),
+			FeePaidMsat:        uint64(peerStats.feePaid),
+			AdvertisedFeeMsat:  uint64(peerStats.advertisedFee),
+			NumUnknownPolicies: peerStats.numUnknownPolicies,
+		}
+
+		if peerStats.amtForwarded > 0 {
+			amt := float64(peerStats.amtForwarded)
+			insight.EffectiveFeeRatePpm =
+				float64(peerStats.feePaid) / amt * 1e6
+			insight.AdvertisedFeeRatePpm =
+				float64(peerStats.advertisedFee) / amt * 1e6
+			insight.DivergencePpm = insight.EffectiveFeeRatePpm -
+				insight.AdvertisedFeeRatePpm
+		}
+
+		insights = append(insights, insight)
+	}
+
+	sort.Slice(insights, func(i, j int) bool {
+		if insights[i].DivergencePpm != insights[j].DivergencePpm {
+			return insights[i].DivergencePpm >
+				insights[j].DivergencePpm
+		}
+
+		return insights[i].PubKey < insights[j].PubKey
+	})
+
+	return insights, nil
+}
+
+// addPeerFeeStats adds the settled HTLCs of the given payments to the stats of
+// the peers that forwarded them. The advertised fee of an HTLC consists of the
+// outbound fee of the peer's outgoing channel and the inbound fee of its
+// incoming channel. We only know the latest policies, so an HTLC is counted as
+// unknown if any of them was updated after the HTLC was sent.
+func addPeerFeeStats(stats map[route.Vertex]*peerFeeStats,
+	payments []*channeldb.MPPayment, fetchPolicy fetchPolicyFunc) error {
+
+	for _, payment := range payments {
+		for _, htlc := range payment.HTLCs {
+			if htlc.Settle == nil {
+				continue
+			}
+
+			// Only peers that forward the HTLC to another node are
+			// paid a fee. We also skip blinded paths, as the
+			// amounts within them aren't known.
+			rt := htlc.Route
+			if len(rt.Hops) < 2 || rt.Hops[0].AmtToForward == 0 {
+				continue
+			}
+
+			peer := rt.Hops[0].PubKeyBytes
+			peerStats, ok := stats[peer]
+			if !ok {
+				peerStats = &peerFeeStats{}
+				stats[peer] = peerStats
+			}
+
+			inChanID, outChanID := rt.Hops[0].ChannelID,
+				rt.Hops[1].ChannelID
+
+			inPolicy, err := fetchPolicy(inChanID, peer)
+			if err != nil {
+				return err
+			}
+			outPolicy, err := fetchPolicy(outChanID, peer)
+			if err != nil {
+				return err
+			}
+
+			if !policyKnownAt(outPolicy, htlc.AttemptTime) ||
+				!policyKnownAt(inPolicy, htlc.AttemptTime) {
+
+				peerStats.numUnknownPolicies++
+				continue
+			}
+
+			amt := rt.Hops[0].AmtToForward
+			peerStats.numHtlcs++
+			peerStats.amtForwarded += amt
+			peerStats.feePaid += rt.HopFee(0)
+			peerStats.advertisedFee += advertisedHopFee(
+				inPolicy, outPolicy, amt,
+			)
+		}
+	}
+
+	return nil
+}
+
+// policyKnownAt returns true if the policy is known and was already in effect
+// at the given time.
+func policyKnownAt(policy *models.ChannelEdgePolicy, t time.Time) bool {
+	return policy != nil && !policy.LastUpdate.After(t)
+}
+
+// advertisedHopFee returns the fee that a node advertises for forwarding the
+// amount from the channel of the inbound policy to the channel of the outbound
+// policy. Like a forwarding node, we never let inbound discounts turn the
+// total fee negative.
+func advertisedHopFee(inPolicy, outPolicy *models.ChannelEdgePolicy,
+	amt lnwire.MilliSatoshi) lnwire.MilliSatoshi {
+
+	outboundFee := outPolicy.ComputeFee(amt)
+
+	var inboundFee lnwire.Fee
+	_, err := inPolicy.ExtraOpaqueData.ExtractRecords(&inboundFee)
+	if err != nil {
+		return outboundFee
+	}
+
+	fee := models.NewInboundFeeFromWire(inboundFee)
+	totalFee := int64(outboundFee) + fee.CalcFee(amt+outboundFee)
+	if totalFee < 0 {
+		return 0
+	}
+
+	return lnwire.MilliSatoshi(totalFee)
+}
+
+// PeerFeeReport summarizes the fees that were actually paid to each peer for
+// forwarding the settled HTLCs of our payments and compares them with the
+// fees that the channel policies of the peer advertised when the HTLCs were
+// sent. Without a
+// start time, the report covers the default window before the end time.
+func (r *rpcServer) PeerFeeReport(_ context.Context,
+	req *lnrpc.PeerFeeReportRequest) (*lnrpc.PeerFeeReportResponse, error) {
+
+	startTime, endTime := int64(req.StartTime), int64(req.EndTime)
+	if startTime == 0 {
+		reference := time.Now()
+		if endTime != 0 {
+			reference = time.Unix(endTime, 0)
+		}
+
+		startTime = reference.Add(-defaultPeerFeeReportWindow).Unix()
+	}
+
+	if endTime != 0 && startTime >= endTime {
+		return nil, fmt.Errorf("start time(%v) must be before end "+
+			"time(%v)", startTime, endTime)
+	}
+
+	query := channeldb.PaymentsQuery{
+		MaxPayments:       peerFeeReportPageSize,
+		IncludeIncomplete: true,
+		CreationDateStart: startTime,
+		CreationDateEnd:   endTime,
+	}
+
+	graph := r.server.graphDB
+	fetchPolicy := func(chanID uint64,
+		from route.Vertex) (*models.ChannelEdgePolicy, error) {
+
+		info, policy1, policy2, err := graph.FetchChannelEdgesByID(
+			chanID,
+		)
+		switch {
+		case errors.Is(err, channeldb.ErrEdgeNotFound),
+			errors.Is(err, channeldb.ErrZombieEdge):
+
+			return nil, nil
+
+		case err != nil:
+			return nil, err
+		}
+
+		if info.NodeKey1Bytes == from {
+			return policy1, nil
+		}
+
+		return policy2, nil
+	}
+
+	insights, err := buildPeerFeeReport(
+		query, r.server.miscDB.QueryPayments, fetchPolicy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.PeerFeeReportResponse{
+		Peers: insights,
+	}, nil
+}
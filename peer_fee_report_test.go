@@ -0,0 +1,155 @@
+package lnd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildPeerFeeReport tests that the fees paid to the first hops of
+// settled HTLCs are compared with the policies that were advertised when the
+// HTLCs were sent.
+func TestBuildPeerFeeReport(t *testing.T) {
+	t.Parallel()
+
+	var (
+		peerA = route.Vertex{1}
+		peerB = route.Vertex{2}
+		dest  = route.Vertex{3}
+
+		attemptTime = time.Unix(1_700_000_000, 0)
+	)
+
+	// newHtlc returns an HTLC that pays the given fee to the peer for
+	// forwarding 1M msat to the destination over the given channel. The
+	// incoming channel of the peer is the one that shares its ID with the
+	// peer's first byte.
+	newHtlc := func(peer route.Vertex, chanID uint64,
+		fee lnwire.MilliSatoshi, settled bool) channeldb.HTLCAttempt {
+
+		htlc := channeldb.HTLCAttempt{
+			HTLCAttemptInfo: channeldb.HTLCAttemptInfo{
+				Route: route.Route{
+					TotalAmount: 1_000_000 + fee,
+					Hops: []*route.Hop{{
+						PubKeyBytes:  peer,
+						ChannelID:    uint64(peer[0]),
+						AmtToForward: 1_000_000,
+					}, {
+						PubKeyBytes:  dest,
+						ChannelID:    chanID,
+						AmtToForward: 1_000_000,
+					}},
+				},
+				AttemptTime: attemptTime,
+			},
+		}
+		if settled {
+			htlc.Settle = &channeldb.HTLCSettleInfo{}
+		}
+
+		return htlc
+	}
+
+	payments := []*channeldb.MPPayment{{
+		HTLCs: []channeldb.HTLCAttempt{
+			newHtlc(peerA, 10, 2000, true),
+			newHtlc(peerB, 20, 1000, true),
+
+			// Failed HTLCs aren't taken into account.
+			newHtlc(peerB, 20, 5000, false),
+		},
+	}, {
+		HTLCs: []channeldb.HTLCAttempt{
+			newHtlc(peerA, 10, 2000, true),
+
+			// The policy of the channel is unknown.
+			newHtlc(peerA, 30, 2000, true),
+
+			// The policy was updated after the HTLC was sent.
+			newHtlc(peerB, 40, 1000, true),
+		},
+	}}
+
+	// Peer B grants an inbound discount of 500 ppm on its incoming
+	// channel.
+	var inboundDiscount lnwire.ExtraOpaqueData
+	require.NoError(t, inboundDiscount.PackRecords(&lnwire.Fee{
+		FeeRate: -500,
+	}))
+
+	// Both peers advertise an outbound fee rate of 1000 ppm, so peer A
+	// charged twice as much as it advertises. Peer B charged the outbound
+	// fee but didn't grant its inbound discount.
+	fetchPolicy := func(chanID uint64,
+		from route.Vertex) (*models.ChannelEdgePolicy, error) {
+
+		policy := &models.ChannelEdgePolicy{
+			FeeProportionalMillionths: 1000,
+			LastUpdate:                attemptTime.Add(-time.Hour),
+		}
+
+		switch chanID {
+		case 30:
+			return nil, nil
+
+		case 40:
+			policy.LastUpdate = attemptTime.Add(time.Hour)
+
+		case uint64(peerB[0]):
+			policy.ExtraOpaqueData = inboundDiscount
+		}
+
+		return policy, nil
+	}
+
+	// The payments are queried one by one to exercise the paging.
+	var numQueries int
+	queryPayments := func(query channeldb.PaymentsQuery) (
+		channeldb.PaymentsResponse, error) {
+
+		numQueries++
+
+		offset := min(query.IndexOffset, uint64(len(payments)))
+		end := min(offset+query.MaxPayments, uint64(len(payments)))
+
+		return channeldb.PaymentsResponse{
+			Payments:        payments[offset:end],
+			LastIndexOffset: end,
+		}, nil
+	}
+
+	insights, err := buildPeerFeeReport(
+		channeldb.PaymentsQuery{MaxPayments: 1}, queryPayments,
+		fetchPolicy,
+	)
+	require.NoError(t, err)
+	require.Equal(t, len(payments)+1, numQueries)
+	require.Equal(t, []*lnrpc.PeerFeeInsight{{
+		PubKey:               peerA.String(),
+		NumHtlcs:             2,
+		AmtForwardedMsat:     2_000_000,
+		FeePaidMsat:          4000,
+		AdvertisedFeeMsat:    2000,
+		EffectiveFeeRatePpm:  2000,
+		AdvertisedFeeRatePpm: 1000,
+		DivergencePpm:        1000,
+		NumUnknownPolicies:   1,
+	}, {
+		PubKey:               peerB.String(),
+		NumHtlcs:             1,
+		AmtForwardedMsat:     1_000_000,
+		FeePaidMsat:          1000,
+		AdvertisedFeeMsat:    500,
+		EffectiveFeeRatePpm:  1000,
+		AdvertisedFeeRatePpm: 500,
+		DivergencePpm:        500,
+		NumUnknownPolicies:   1,
+	}}, insights)
+}
@@ -0,0 +1,370 @@
+package rgs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+)
+
+const (
+	// snapshotPath is the path under which snapshots are served. It is
+	// followed by the timestamp of the last sync of the client, which is
+	// zero for the initial sync.
+	snapshotPath = "/snapshot/"
+
+	// shutdownTimeout is the maximum time that requests in progress are
+	// given to complete on shutdown.
+	shutdownTimeout = 5 * time.Second
+
+	// DefaultSnapshotInterval is the default interval at which the graph
+	// is collected for new snapshots.
+	DefaultSnapshotInterval = time.Hour
+
+	// maxDeltaIntervals is the number of past collections of the graph
+	// that deltas can be served for. Clients that synced before the
+	// oldest of them receive a full snapshot.
+	maxDeltaIntervals = 24
+)
+
+// Graph is the source of the channels that are included in the snapshots.
+type Graph interface {
+	// ForEachChannel iterates through all channels of the graph and calls
+	// the callback with the channel and its policies.
+	ForEachChannel(cb func(*models.ChannelEdgeInfo,
+		*models.ChannelEdgePolicy,
+		*models.ChannelEdgePolicy) error) error
+}
+
+// Config holds the configuration of the rapid gossip sync server.
+type Config struct {
+	// Graph is the channel graph that snapshots are generated from.
+	Graph Graph
+
+	// ChainHash is the genesis hash of the chain that the graph belongs
+	// to.
+	ChainHash chainhash.Hash
+
+	// ListenAddr is the address that the server accepts HTTP connections
+	// on.
+	ListenAddr string
+
+	// SnapshotInterval is the interval at which the graph is collected
+	// for new snapshots. Requests in between are served from the cache,
+	// so that clients can't make the server walk the graph on every
+	// request.
+	SnapshotInterval time.Duration
+
+	// Now is expected to return the current time. It is supplied as an
+	// external function to enable deterministic unit tests.
+	Now func() time.Time
+}
+
+// policyKey identifies the policy of a channel in one direction.
+type policyKey struct {
+	chanID    uint64
+	direction int
+}
+
+// seenPolicy records when the server first saw a version of a policy.
+type seenPolicy struct {
+	lastUpdate time.Time
+	seen       uint32
+}
+
+// Server serves rapid gossip sync snapshots of the local graph over HTTP. The
+// snapshots are compatible with the rapid gossip sync format of LDK, which
+// allows light clients to bootstrap their graph from the operator's own node
+// instead of a third party server.
+type Server struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *Config
+
+	srv *http.Server
+
+	// channels are the public channels of the graph as of the last
+	// collection, which happened at collectedAt.
+	channels    []*Channel
+	collectedAt time.Time
+
+	// collections holds the unix times of the last collections of the
+	// graph, oldest first. They are the timestamps that clients pass
+	// back to request deltas.
+	collections []uint32
+
+	// seen records when the current policies were first seen.
+	seen map[policyKey]seenPolicy
+
+	// cache holds the snapshots that were generated from the current
+	// collection of the graph, keyed by the timestamp they were
+	// requested for.
+	cache map[uint32][]byte
+
+	mu sync.Mutex
+
+	wg sync.WaitGroup
+}
+
+// NewServer returns a new rapid gossip sync server for the given config.
+func NewServer(cfg *Config) *Server {
+	s := &Server{
+		cfg:   cfg,
+		seen:  make(map[policyKey]seenPolicy),
+		cache: make(map[uint32][]byte),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(snapshotPath, s.handleSnapshot)
+
+	s.srv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// Start starts serving snapshots.
+func (s *Server) Start() error {
+	var startErr error
+	s.started.Do(func() {
+		lis, err := net.Listen("tcp", s.cfg.ListenAddr)
+		if err != nil {
+			startErr = fmt.Errorf("unable to listen on %v: %w",
+				s.cfg.ListenAddr, err)
+
+			return
+		}
+
+		log.Infof("Rapid gossip sync server listening on %v",
+			lis.Addr())
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			err := s.srv.Serve(lis)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("Rapid gossip sync server "+
+					"failed: %v", err)
+			}
+		}()
+	})
+
+	return startErr
+}
+
+// Stop shuts down the server and waits for the requests in progress.
+func (s *Server) Stop() error {
+	var err error
+	s.stopped.Do(func() {
+		log.Info("Rapid gossip sync server shutting down...")
+		defer log.Debug("Rapid gossip sync server shutdown complete")
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), shutdownTimeout,
+		)
+		defer cancel()
+
+		err = s.srv.Shutdown(ctx)
+		s.wg.Wait()
+	})
+
+	return err
+}
+
+// Snapshot returns a snapshot that contains all channel updates that the
+// server saw after the given timestamp, along with the timestamp stored in it.
+// The graph is collected at most once per snapshot interval and the snapshots
+// generated from it are cached.
+func (s *Server) Snapshot(since uint32) ([]byte, uint32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := s.cfg.SnapshotInterval
+	if interval == 0 {
+		interval = DefaultSnapshotInterval
+	}
+
+	now := s.cfg.Now()
+	if s.collectedAt.IsZero() || now.Sub(s.collectedAt) >= interval {
+		if err := s.collect(now); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// Only timestamps of previous collections are handed out to clients.
+	// Any other timestamp is rounded down to the closest collection
+	// before it, which keeps the number of cached snapshots bounded. If
+	// there is none, the client receives a full snapshot.
+	since = s.roundSince(since)
+
+	if snapshot, ok := s.cache[since]; ok {
+		return snapshot, s.timestamp(), nil
+	}
+
+	var b bytes.Buffer
+	err := WriteSnapshot(
+		&b, s.cfg.ChainHash, s.channels, since, s.timestamp(),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.cache[since] = b.Bytes()
+
+	return b.Bytes(), s.timestamp(), nil
+}
+
+// timestamp returns the unix time of the last collection of the graph.
+//
+// NOTE: The mutex must be held.
+func (s *Server) timestamp() uint32 {
+	return s.collections[len(s.collections)-1]
+}
+
+// roundSince rounds the given timestamp down to the closest collection of the
+// graph. Zero is returned if there is none.
+//
+// NOTE: The mutex must be held.
+func (s *Server) roundSince(since uint32) uint32 {
+	for i := len(s.collections) - 1; i >= 0; i-- {
+		if s.collections[i] <= since {
+			return s.collections[i]
+		}
+	}
+
+	return 0
+}
+
+// collect walks the graph and records the time at which the policies that
+// changed since the last collection were seen. As the collections aren't
+// persisted, clients that synced before a restart receive a full snapshot.
+//
+// NOTE: The mutex must be held.
+func (s *Server) collect(now time.Time) error {
+	// The collection times must increase, as they are handed out to
+	// clients as their sync timestamp.
+	timestamp := uint32(now.Unix())
+	if len(s.collections) > 0 && timestamp <= s.timestamp() {
+		timestamp = s.timestamp() + 1
+	}
+
+	var channels []*Channel
+	seen := make(map[policyKey]seenPolicy, len(s.seen))
+	err := s.cfg.Graph.ForEachChannel(func(info *models.ChannelEdgeInfo,
+		p1, p2 *models.ChannelEdgePolicy) error {
+
+		// Private channels are never part of a snapshot.
+		if info.AuthProof == nil {
+			return nil
+		}
+
+		c := &Channel{
+			Info:     info,
+			Policies: [2]*models.ChannelEdgePolicy{p1, p2},
+		}
+		for i, p := range c.Policies {
+			if p == nil {
+				continue
+			}
+
+			key := policyKey{chanID: info.ChannelID, direction: i}
+			prev, ok := s.seen[key]
+
+			c.Seen[i] = timestamp
+			if ok && prev.lastUpdate.Equal(p.LastUpdate) {
+				c.Seen[i] = prev.seen
+			}
+
+			seen[key] = seenPolicy{
+				lastUpdate: p.LastUpdate,
+				seen:       c.Seen[i],
+			}
+		}
+		channels = append(channels, c)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.channels = channels
+	s.seen = seen
+	s.collectedAt = now
+	s.cache = make(map[uint32][]byte)
+
+	s.collections = append(s.collections, timestamp)
+	if len(s.collections) > maxDeltaIntervals {
+		s.collections = s.collections[1:]
+	}
+
+	log.Debugf("Collected %v channels for rapid gossip sync snapshots",
+		len(channels))
+
+	return nil
+}
+
+// handleSnapshot serves the snapshot for the timestamp in the request path.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(
+			w, "method not allowed", http.StatusMethodNotAllowed,
+		)
+
+		return
+	}
+
+	since, err := parseSince(strings.TrimPrefix(r.URL.Path, snapshotPath))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	snapshot, latestSeen, err := s.Snapshot(since)
+	if err != nil {
+		log.Errorf("Unable to generate snapshot: %v", err)
+		http.Error(
+			w, "unable to generate snapshot",
+			http.StatusInternalServerError,
+		)
+
+		return
+	}
+
+	log.Debugf("Serving snapshot since %v of %v bytes with timestamp %v",
+		since, len(snapshot), latestSeen)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(snapshot)))
+	if _, err := w.Write(snapshot); err != nil {
+		log.Debugf("Unable to write snapshot: %v", err)
+	}
+}
+
+// parseSince parses the timestamp of the last sync of a client.
+func parseSince(s string) (uint32, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	since, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sync timestamp %q", s)
+	}
+
+	return uint32(since), nil
+}
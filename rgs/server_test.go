@@ -0,0 +1,109 @@
+package rgs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// mockGraph is a graph of public channels that counts how often it is
+// walked.
+type mockGraph struct {
+	channels []*Channel
+	walks    int
+}
+
+// ForEachChannel calls the callback for every channel of the graph.
+func (m *mockGraph) ForEachChannel(cb func(*models.ChannelEdgeInfo,
+	*models.ChannelEdgePolicy, *models.ChannelEdgePolicy) error) error {
+
+	m.walks++
+	for _, c := range m.channels {
+		err := cb(c.Info, c.Policies[0], c.Policies[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TestServerSnapshot tests that the server walks the graph at most once per
+// snapshot interval and keys deltas on the time it saw the updates.
+func TestServerSnapshot(t *testing.T) {
+	t.Parallel()
+
+	graph := &mockGraph{
+		channels: []*Channel{{
+			Info: &models.ChannelEdgeInfo{
+				ChannelID:     100,
+				NodeKey1Bytes: route.Vertex{2, 1},
+				NodeKey2Bytes: route.Vertex{2, 2},
+				AuthProof:     &models.ChannelAuthProof{},
+			},
+			Policies: [2]*models.ChannelEdgePolicy{
+				// The timestamp of the update lies in the
+				// future.
+				testPolicy(100, 0, 1_000_000),
+			},
+		}},
+	}
+
+	now := time.Unix(10_000, 0)
+	s := NewServer(&Config{
+		Graph:            graph,
+		ChainHash:        chainhash.Hash{1},
+		SnapshotInterval: time.Hour,
+		Now: func() time.Time {
+			return now
+		},
+	})
+
+	// The full snapshot carries the time of the collection rather than
+	// the timestamp of the update.
+	snapshot, timestamp, err := s.Snapshot(0)
+	require.NoError(t, err)
+	require.EqualValues(t, 10_000, timestamp)
+	require.Len(t, decodeSnapshot(t, snapshot).updates, 1)
+	require.Equal(t, 1, graph.walks)
+
+	// Within the interval, requests are served from the cache, and any
+	// timestamp is rounded down to the last collection.
+	now = now.Add(time.Minute)
+	for _, since := range []uint32{0, 10_000, 10_001, 1_000_001} {
+		_, _, err := s.Snapshot(since)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, graph.walks)
+
+	// Timestamps before the first collection receive a full snapshot.
+	snapshot, _, err = s.Snapshot(5_000)
+	require.NoError(t, err)
+	require.Len(t, decodeSnapshot(t, snapshot).chanIDs, 1)
+
+	// An update with a timestamp in the past that is received later on is
+	// still included in the delta of a client that synced before.
+	graph.channels[0].Policies[1] = testPolicy(
+		100, lnwire.ChanUpdateDirection, 500,
+	)
+	now = now.Add(time.Hour)
+
+	snapshot, timestamp, err = s.Snapshot(10_000)
+	require.NoError(t, err)
+	require.EqualValues(t, 10_000+60+3600, timestamp)
+	require.Equal(t, 2, graph.walks)
+
+	delta := decodeSnapshot(t, snapshot)
+	require.Len(t, delta.updates, 1)
+	require.Equal(t, updateFlagDirection, int(delta.updates[0].flags))
+
+	// A client that synced after the update doesn't receive it again.
+	snapshot, _, err = s.Snapshot(timestamp)
+	require.NoError(t, err)
+	require.Empty(t, decodeSnapshot(t, snapshot).updates)
+}
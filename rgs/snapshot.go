@@ -0,0 +1,295 @@
+package rgs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/tlv"
+)
+
+// snapshotVersion is the version of the rapid gossip sync format that is
+// produced.
+const snapshotVersion = 1
+
+// snapshotPrefix is the magic prefix of every snapshot.
+var snapshotPrefix = []byte{'L', 'D', 'K', snapshotVersion}
+
+// The flags of a channel update in a snapshot. They signal which fields of the
+// update deviate from the defaults and are therefore included.
+const (
+	updateFlagDirection = 1 << 0
+	updateFlagDisabled  = 1 << 1
+	updateFlagMaxHtlc   = 1 << 2
+	updateFlagFeeRate   = 1 << 3
+	updateFlagFeeBase   = 1 << 4
+	updateFlagMinHtlc   = 1 << 5
+	updateFlagCltvDelta = 1 << 6
+)
+
+// Channel is a public channel of the graph together with its policies.
+type Channel struct {
+	// Info is the announcement information of the channel.
+	Info *models.ChannelEdgeInfo
+
+	// Policies are the policies of the channel indexed by direction. A
+	// policy is nil if it is unknown.
+	Policies [2]*models.ChannelEdgePolicy
+
+	// Seen holds the unix times at which the server first saw the current
+	// policies, indexed by direction. Deltas are keyed on them rather than
+	// on the timestamps of the updates, which are chosen by the channel
+	// peers and may lie in the past or in the future.
+	Seen [2]uint32
+}
+
+// updateDefaults are the field values of channel updates that are left out
+// of the individual updates of a snapshot.
+type updateDefaults struct {
+	cltvDelta uint16
+	minHtlc   lnwire.MilliSatoshi
+	feeBase   lnwire.MilliSatoshi
+	feeRate   lnwire.MilliSatoshi
+	maxHtlc   lnwire.MilliSatoshi
+}
+
+// mostCommon returns the value that occurs most often in the given slice. Ties
+// are broken in favor of the smaller value, so that the result is
+// deterministic.
+func mostCommon[T uint16 | lnwire.MilliSatoshi](values []T) T {
+	counts := make(map[T]int, len(values))
+	var (
+		best      T
+		bestCount int
+	)
+	for _, v := range values {
+		counts[v]++
+
+		count := counts[v]
+		if count > bestCount || (count == bestCount && v < best) {
+			best = v
+			bestCount = count
+		}
+	}
+
+	return best
+}
+
+// newUpdateDefaults picks the most common value of every field as the default,
+// which minimizes the size of the snapshot.
+func newUpdateDefaults(policies []*models.ChannelEdgePolicy) updateDefaults {
+	var (
+		cltvDeltas = make([]uint16, 0, len(policies))
+		minHtlcs   = make([]lnwire.MilliSatoshi, 0, len(policies))
+		feeBases   = make([]lnwire.MilliSatoshi, 0, len(policies))
+		feeRates   = make([]lnwire.MilliSatoshi, 0, len(policies))
+		maxHtlcs   = make([]lnwire.MilliSatoshi, 0, len(policies))
+	)
+	for _, p := range policies {
+		cltvDeltas = append(cltvDeltas, p.TimeLockDelta)
+		minHtlcs = append(minHtlcs, p.MinHTLC)
+		feeBases = append(feeBases, p.FeeBaseMSat)
+		feeRates = append(feeRates, p.FeeProportionalMillionths)
+		maxHtlcs = append(maxHtlcs, p.MaxHTLC)
+	}
+
+	return updateDefaults{
+		cltvDelta: mostCommon(cltvDeltas),
+		minHtlc:   mostCommon(minHtlcs),
+		feeBase:   mostCommon(feeBases),
+		feeRate:   mostCommon(feeRates),
+		maxHtlc:   mostCommon(maxHtlcs),
+	}
+}
+
+// WriteSnapshot writes a rapid gossip sync snapshot of the given channels to
+// w. Only the channel updates that were seen after the given timestamp are
+// included, together with the announcements of their channels. A zero
+// timestamp produces a full snapshot that also contains the announcements of
+// channels without any known policy. The given timestamp is the time at which
+// the channels were collected. It is stored in the snapshot and the client
+// passes it back to request the next delta, so it must not lie before any of
+// the seen times of the channels.
+func WriteSnapshot(w io.Writer, chainHash chainhash.Hash, channels []*Channel,
+	since, timestamp uint32) error {
+
+	// Collect the channels and updates that go into the snapshot, sorted
+	// by channel id as the ids are delta encoded.
+	sorted := make([]*Channel, len(channels))
+	copy(sorted, channels)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Info.ChannelID < sorted[j].Info.ChannelID
+	})
+
+	var (
+		included []*Channel
+		updates  []*models.ChannelEdgePolicy
+	)
+	for _, c := range sorted {
+		var numUpdates int
+		for i, p := range c.Policies {
+			if p == nil {
+				continue
+			}
+
+			if since != 0 && c.Seen[i] <= since {
+				continue
+			}
+
+			updates = append(updates, p)
+			numUpdates++
+		}
+
+		if numUpdates > 0 || since == 0 {
+			included = append(included, c)
+		}
+	}
+
+	// Assign an index to every node in the order of their first
+	// appearance.
+	var (
+		nodeIDs     []route.Vertex
+		nodeIndices = make(map[route.Vertex]uint64)
+	)
+	addNode := func(node route.Vertex) {
+		if _, ok := nodeIndices[node]; ok {
+			return
+		}
+		nodeIndices[node] = uint64(len(nodeIDs))
+		nodeIDs = append(nodeIDs, node)
+	}
+	for _, c := range included {
+		addNode(c.Info.NodeKey1Bytes)
+		addNode(c.Info.NodeKey2Bytes)
+	}
+
+	var (
+		b       bytes.Buffer
+		scratch [8]byte
+	)
+	writeBigSize := func(v uint64) error {
+		return tlv.WriteVarInt(&b, v, &scratch)
+	}
+
+	b.Write(snapshotPrefix)
+	b.Write(chainHash[:])
+	b.Write(binary.BigEndian.AppendUint32(nil, timestamp))
+
+	b.Write(binary.BigEndian.AppendUint32(nil, uint32(len(nodeIDs))))
+	for _, node := range nodeIDs {
+		b.Write(node[:])
+	}
+
+	// The channel announcements carry the channel features, the channel id
+	// as delta to the previous one and the indices of both nodes.
+	b.Write(binary.BigEndian.AppendUint32(nil, uint32(len(included))))
+	var prevChanID uint64
+	for _, c := range included {
+		// The stored features are already serialized with their length
+		// prefix, as expected by the snapshot format.
+		if len(c.Info.Features) >= 2 {
+			b.Write(c.Info.Features)
+		} else {
+			b.Write([]byte{0, 0})
+		}
+
+		err := writeBigSize(c.Info.ChannelID - prevChanID)
+		if err != nil {
+			return err
+		}
+		prevChanID = c.Info.ChannelID
+
+		err = writeBigSize(nodeIndices[c.Info.NodeKey1Bytes])
+		if err != nil {
+			return err
+		}
+		err = writeBigSize(nodeIndices[c.Info.NodeKey2Bytes])
+		if err != nil {
+			return err
+		}
+	}
+
+	b.Write(binary.BigEndian.AppendUint32(nil, uint32(len(updates))))
+	if len(updates) == 0 {
+		_, err := w.Write(b.Bytes())
+
+		return err
+	}
+
+	defaults := newUpdateDefaults(updates)
+	b.Write(binary.BigEndian.AppendUint16(nil, defaults.cltvDelta))
+	b.Write(binary.BigEndian.AppendUint64(nil, uint64(defaults.minHtlc)))
+	b.Write(binary.BigEndian.AppendUint32(nil, uint32(defaults.feeBase)))
+	b.Write(binary.BigEndian.AppendUint32(nil, uint32(defaults.feeRate)))
+	b.Write(binary.BigEndian.AppendUint64(nil, uint64(defaults.maxHtlc)))
+
+	// Every update only carries the fields that deviate from the defaults.
+	// The updates are complete rather than incremental, so that clients
+	// that never saw a previous version of them can apply them.
+	prevChanID = 0
+	for _, p := range updates {
+		if err := writeBigSize(p.ChannelID - prevChanID); err != nil {
+			return err
+		}
+		prevChanID = p.ChannelID
+
+		var flags byte
+		if p.ChannelFlags&lnwire.ChanUpdateDirection != 0 {
+			flags |= updateFlagDirection
+		}
+		if p.IsDisabled() {
+			flags |= updateFlagDisabled
+		}
+		if p.TimeLockDelta != defaults.cltvDelta {
+			flags |= updateFlagCltvDelta
+		}
+		if p.MinHTLC != defaults.minHtlc {
+			flags |= updateFlagMinHtlc
+		}
+		if p.FeeBaseMSat != defaults.feeBase {
+			flags |= updateFlagFeeBase
+		}
+		if p.FeeProportionalMillionths != defaults.feeRate {
+			flags |= updateFlagFeeRate
+		}
+		if p.MaxHTLC != defaults.maxHtlc {
+			flags |= updateFlagMaxHtlc
+		}
+		b.WriteByte(flags)
+
+		if flags&updateFlagCltvDelta != 0 {
+			b.Write(binary.BigEndian.AppendUint16(
+				nil, p.TimeLockDelta,
+			))
+		}
+		if flags&updateFlagMinHtlc != 0 {
+			b.Write(binary.BigEndian.AppendUint64(
+				nil, uint64(p.MinHTLC),
+			))
+		}
+		if flags&updateFlagFeeBase != 0 {
+			b.Write(binary.BigEndian.AppendUint32(
+				nil, uint32(p.FeeBaseMSat),
+			))
+		}
+		if flags&updateFlagFeeRate != 0 {
+			b.Write(binary.BigEndian.AppendUint32(
+				nil, uint32(p.FeeProportionalMillionths),
+			))
+		}
+		if flags&updateFlagMaxHtlc != 0 {
+			b.Write(binary.BigEndian.AppendUint64(
+				nil, uint64(p.MaxHTLC),
+			))
+		}
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
@@ -0,0 +1,264 @@
+package rgs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/tlv"
+	"github.com/stretchr/testify/require"
+)
+
+// decodedUpdate is a channel update as read back from a snapshot.
+type decodedUpdate struct {
+	chanID    uint64
+	flags     byte
+	cltvDelta uint16
+	minHtlc   uint64
+	feeBase   uint32
+	feeRate   uint32
+	maxHtlc   uint64
+}
+
+// decodedSnapshot is the content of a snapshot as read back by a client.
+type decodedSnapshot struct {
+	chainHash  chainhash.Hash
+	latestSeen uint32
+	nodes      []route.Vertex
+	chanIDs    []uint64
+	chanNodes  [][2]uint64
+	updates    []decodedUpdate
+}
+
+// decodeSnapshot parses a snapshot the way a rapid gossip sync client does.
+func decodeSnapshot(t *testing.T, b []byte) *decodedSnapshot {
+	t.Helper()
+
+	r := bytes.NewReader(b)
+	var scratch [8]byte
+
+	readN := func(n int) []byte {
+		buf := make([]byte, n)
+		_, err := io.ReadFull(r, buf)
+		require.NoError(t, err)
+
+		return buf
+	}
+	readU16 := func() uint16 {
+		return binary.BigEndian.Uint16(readN(2))
+	}
+	readU32 := func() uint32 {
+		return binary.BigEndian.Uint32(readN(4))
+	}
+	readU64 := func() uint64 {
+		return binary.BigEndian.Uint64(readN(8))
+	}
+	readBigSize := func() uint64 {
+		v, err := tlv.ReadVarInt(r, &scratch)
+		require.NoError(t, err)
+
+		return v
+	}
+
+	require.Equal(t, snapshotPrefix, readN(4))
+
+	s := &decodedSnapshot{}
+	copy(s.chainHash[:], readN(32))
+	s.latestSeen = readU32()
+
+	numNodes := readU32()
+	for i := uint32(0); i < numNodes; i++ {
+		var node route.Vertex
+		copy(node[:], readN(33))
+		s.nodes = append(s.nodes, node)
+	}
+
+	var chanID uint64
+	numAnns := readU32()
+	for i := uint32(0); i < numAnns; i++ {
+		readN(int(readU16()))
+
+		chanID += readBigSize()
+		s.chanIDs = append(s.chanIDs, chanID)
+		s.chanNodes = append(
+			s.chanNodes, [2]uint64{readBigSize(), readBigSize()},
+		)
+	}
+
+	numUpdates := readU32()
+	if numUpdates == 0 {
+		require.Zero(t, r.Len())
+
+		return s
+	}
+
+	defaults := decodedUpdate{
+		cltvDelta: readU16(),
+		minHtlc:   readU64(),
+		feeBase:   readU32(),
+		feeRate:   readU32(),
+		maxHtlc:   readU64(),
+	}
+
+	chanID = 0
+	for i := uint32(0); i < numUpdates; i++ {
+		u := defaults
+		chanID += readBigSize()
+		u.chanID = chanID
+		u.flags = readN(1)[0]
+
+		if u.flags&updateFlagCltvDelta != 0 {
+			u.cltvDelta = readU16()
+		}
+		if u.flags&updateFlagMinHtlc != 0 {
+			u.minHtlc = readU64()
+		}
+		if u.flags&updateFlagFeeBase != 0 {
+			u.feeBase = readU32()
+		}
+		if u.flags&updateFlagFeeRate != 0 {
+			u.feeRate = readU32()
+		}
+		if u.flags&updateFlagMaxHtlc != 0 {
+			u.maxHtlc = readU64()
+		}
+		s.updates = append(s.updates, u)
+	}
+	require.Zero(t, r.Len())
+
+	return s
+}
+
+// testPolicy returns a channel policy with common values.
+func testPolicy(chanID uint64, direction lnwire.ChanUpdateChanFlags,
+	lastUpdate int64) *models.ChannelEdgePolicy {
+
+	return &models.ChannelEdgePolicy{
+		ChannelID:                 chanID,
+		ChannelFlags:              direction,
+		LastUpdate:                time.Unix(lastUpdate, 0),
+		TimeLockDelta:             40,
+		MinHTLC:                   1000,
+		MaxHTLC:                   1_000_000,
+		FeeBaseMSat:               1000,
+		FeeProportionalMillionths: 1,
+	}
+}
+
+// TestWriteSnapshot tests that full and delta snapshots contain the expected
+// channels and updates.
+func TestWriteSnapshot(t *testing.T) {
+	t.Parallel()
+
+	var (
+		chainHash = chainhash.Hash{1, 2, 3}
+		nodeA     = route.Vertex{2, 1}
+		nodeB     = route.Vertex{2, 2}
+		nodeC     = route.Vertex{2, 3}
+		timestamp = uint32(5000)
+	)
+
+	// The second policy of channel 200 deviates from the defaults
+	// and is disabled.
+	deviating := testPolicy(200, lnwire.ChanUpdateDirection, 2000)
+	deviating.TimeLockDelta = 144
+	deviating.FeeBaseMSat = 2000
+	deviating.ChannelFlags |= lnwire.ChanUpdateDisabled
+
+	channels := []*Channel{
+		{
+			Info: &models.ChannelEdgeInfo{
+				ChannelID:     300,
+				NodeKey1Bytes: nodeB,
+				NodeKey2Bytes: nodeC,
+			},
+		},
+		{
+			Info: &models.ChannelEdgeInfo{
+				ChannelID:     200,
+				NodeKey1Bytes: nodeA,
+				NodeKey2Bytes: nodeB,
+			},
+			Policies: [2]*models.ChannelEdgePolicy{
+				testPolicy(200, 0, 1000),
+				deviating,
+			},
+			Seen: [2]uint32{1000, 2000},
+		},
+	}
+
+	// A full snapshot contains all channels, including the one without
+	// any policy.
+	var b bytes.Buffer
+	err := WriteSnapshot(&b, chainHash, channels, 0, timestamp)
+	require.NoError(t, err)
+
+	s := decodeSnapshot(t, b.Bytes())
+	require.Equal(t, chainHash, s.chainHash)
+	require.Equal(t, timestamp, s.latestSeen)
+	require.Equal(t, []route.Vertex{nodeA, nodeB, nodeC}, s.nodes)
+	require.Equal(t, []uint64{200, 300}, s.chanIDs)
+	require.Equal(t, [][2]uint64{{0, 1}, {1, 2}}, s.chanNodes)
+
+	require.Len(t, s.updates, 2)
+	require.Equal(t, decodedUpdate{
+		chanID:    200,
+		cltvDelta: 40,
+		minHtlc:   1000,
+		feeBase:   1000,
+		feeRate:   1,
+		maxHtlc:   1_000_000,
+	}, s.updates[0])
+	require.Equal(t, decodedUpdate{
+		chanID: 200,
+		flags: updateFlagDirection | updateFlagDisabled |
+			updateFlagCltvDelta | updateFlagFeeBase,
+		cltvDelta: 144,
+		minHtlc:   1000,
+		feeBase:   2000,
+		feeRate:   1,
+		maxHtlc:   1_000_000,
+	}, s.updates[1])
+
+	// A delta only contains the update that was seen later and the
+	// announcement of its channel.
+	b.Reset()
+	err = WriteSnapshot(&b, chainHash, channels, 1500, timestamp)
+	require.NoError(t, err)
+
+	s = decodeSnapshot(t, b.Bytes())
+	require.Equal(t, timestamp, s.latestSeen)
+	require.Equal(t, []route.Vertex{nodeA, nodeB}, s.nodes)
+	require.Equal(t, []uint64{200}, s.chanIDs)
+	require.Len(t, s.updates, 1)
+	require.Equal(t, uint64(200), s.updates[0].chanID)
+	require.Equal(t, uint16(144), s.updates[0].cltvDelta)
+
+	// Deltas are keyed on the seen times, not on the timestamps of the
+	// updates.
+	b.Reset()
+	channels[1].Seen = [2]uint32{1000, 1200}
+	err = WriteSnapshot(&b, chainHash, channels, 1100, timestamp)
+	require.NoError(t, err)
+
+	s = decodeSnapshot(t, b.Bytes())
+	require.Len(t, s.updates, 1)
+	require.Equal(t, uint16(144), s.updates[0].cltvDelta)
+
+	// Without newer updates, the snapshot is empty.
+	b.Reset()
+	err = WriteSnapshot(&b, chainHash, channels, 2000, timestamp)
+	require.NoError(t, err)
+
+	s = decodeSnapshot(t, b.Bytes())
+	require.Equal(t, timestamp, s.latestSeen)
+	require.Empty(t, s.nodes)
+	require.Empty(t, s.chanIDs)
+	require.Empty(t, s.updates)
+}
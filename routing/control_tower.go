@@ -86,6 +86,17 @@ type ControlTower interface {
 	// payment.
 	FailPayment(lntypes.Hash, channeldb.FailureReason) error
 
+	// CancelPayment marks a payment as failed with the cancel reason if it
+	// still allows new attempts to be registered. The attempts in flight
+	// are left to resolve.
+	CancelPayment(lntypes.Hash) error
+
+	// FailIdlePayment transitions a payment into the Failed state with the
+	// given reason if it doesn't have any attempts in flight. The check
+	// and the update are atomic, otherwise ErrPaymentHTLCsInFlight is
+	// returned.
+	FailIdlePayment(lntypes.Hash, channeldb.FailureReason) error
+
 	// FetchInFlightPayments returns all payments with status InFlight.
 	FetchInFlightPayments() ([]*channeldb.MPPayment, error)
 
@@ -299,6 +310,42 @@ func (p *controlTower) FailPayment(paymentHash lntypes.Hash,
 	return nil
 }
 
+// CancelPayment marks a payment as failed with the cancel reason if it still
+// allows new attempts to be registered.
+func (p *controlTower) CancelPayment(paymentHash lntypes.Hash) error {
+	p.paymentsMtx.Lock(paymentHash)
+	defer p.paymentsMtx.Unlock(paymentHash)
+
+	payment, err := p.db.Cancel(paymentHash)
+	if err != nil {
+		return err
+	}
+
+	// Notify subscribers of the cancel event.
+	p.notifySubscribers(paymentHash, payment)
+
+	return nil
+}
+
+// FailIdlePayment transitions a payment into the Failed state with the given
+// reason if it doesn't have any attempts in flight.
+func (p *controlTower) FailIdlePayment(paymentHash lntypes.Hash,
+	reason channeldb.FailureReason) error {
+
+	p.paymentsMtx.Lock(paymentHash)
+	defer p.paymentsMtx.Unlock(paymentHash)
+
+	payment, err := p.db.FailIdle(paymentHash, reason)
+	if err != nil {
+		return err
+	}
+
+	// Notify subscribers of the fail event.
+	p.notifySubscribers(paymentHash, payment)
+
+	return nil
+}
+
 // FetchInFlightPayments returns all payments with status InFlight.
 func (p *controlTower) FetchInFlightPayments() ([]*channeldb.MPPayment, error) {
 	return p.db.FetchInFlightPayments()
@@ -0,0 +1,56 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// FeeLimitPolicy expresses the fee limit of a payment as a proportion of the
+// payment amount that is bounded by an absolute floor and ceiling.
+type FeeLimitPolicy struct {
+	// PPM is the fee limit in parts per million of the payment amount.
+	PPM uint32
+
+	// Min is the lower bound of the fee limit. It keeps small payments
+	// from ending up with a limit that no route can satisfy.
+	Min lnwire.MilliSatoshi
+
+	// Max is the upper bound of the fee limit. If zero, the fee limit
+	// isn't capped.
+	Max lnwire.MilliSatoshi
+}
+
+// Validate checks that the bounds of the policy are consistent.
+func (p *FeeLimitPolicy) Validate() error {
+	if p.Max != 0 && p.Max < p.Min {
+		return fmt.Errorf("maximum fee limit %v is below minimum fee "+
+			"limit %v", p.Max, p.Min)
+	}
+
+	return nil
+}
+
+// FeeLimit returns the fee limit for a payment of the given amount.
+func (p *FeeLimitPolicy) FeeLimit(amt lnwire.MilliSatoshi) lnwire.MilliSatoshi {
+	// Split the amount to prevent the multiplication from overflowing for
+	// large amounts.
+	ppm := lnwire.MilliSatoshi(p.PPM)
+	limit := amt/1_000_000*ppm + amt%1_000_000*ppm/1_000_000
+
+	limit = max(limit, p.Min)
+	if p.Max != 0 {
+		limit = min(limit, p.Max)
+	}
+
+	return limit
+}
+
+// String returns a human-readable representation of the policy.
+func (p *FeeLimitPolicy) String() string {
+	if p.Max == 0 {
+		return fmt.Sprintf("%v ppm, min %v", p.PPM, p.Min)
+	}
+
+	return fmt.Sprintf("%v ppm, min %v, max %v", p.PPM, p.Min, p.Max)
+}
@@ -0,0 +1,65 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFeeLimitPolicy tests that the fee limit is derived from the amount and
+// bounded by the floor and ceiling of the policy.
+func TestFeeLimitPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		policy   FeeLimitPolicy
+		amt      lnwire.MilliSatoshi
+		expected lnwire.MilliSatoshi
+	}{{
+		name:     "proportional",
+		policy:   FeeLimitPolicy{PPM: 1000, Min: 1000, Max: 50_000},
+		amt:      10_000_000,
+		expected: 10_000,
+	}, {
+		name:     "floor",
+		policy:   FeeLimitPolicy{PPM: 1000, Min: 1000, Max: 50_000},
+		amt:      100_000,
+		expected: 1000,
+	}, {
+		name:     "ceiling",
+		policy:   FeeLimitPolicy{PPM: 1000, Min: 1000, Max: 50_000},
+		amt:      100_000_000,
+		expected: 50_000,
+	}, {
+		name:     "no ceiling",
+		policy:   FeeLimitPolicy{PPM: 1000},
+		amt:      100_000_000,
+		expected: 100_000,
+	}, {
+		name:     "fixed",
+		policy:   FeeLimitPolicy{Min: 5000, Max: 5000},
+		amt:      100_000_000,
+		expected: 5000,
+	}, {
+		name:     "no overflow",
+		policy:   FeeLimitPolicy{PPM: 1_000_000},
+		amt:      lnwire.MaxMilliSatoshi,
+		expected: lnwire.MaxMilliSatoshi,
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.NoError(t, tc.policy.Validate())
+			require.Equal(t, tc.expected, tc.policy.FeeLimit(tc.amt))
+		})
+	}
+
+	invalid := FeeLimitPolicy{PPM: 1000, Min: 2000, Max: 1000}
+	require.Error(t, invalid.Validate())
+}
@@ -49,6 +49,24 @@ type nodeWithDist struct {
 	// routingInfoSize is the total size requirement for the payloads field
 	// in the onion packet from this hop towards the final destination.
 	routingInfoSize uint64
+
+	// hops is the number of hops of the path from this node to the
+	// target. It is only tracked if the number of hops is limited and zero
+	// otherwise.
+	hops uint32
+}
+
+// nodeLabel identifies an entry of the path finding search. If the number of
+// hops is limited, a node can have one entry per hop count, so that a cheaper
+// but longer path doesn't hide a shorter path that stays within the limit.
+type nodeLabel struct {
+	node route.Vertex
+	hops uint32
+}
+
+// label returns the label of the search entry.
+func (n *nodeWithDist) label() nodeLabel {
+	return nodeLabel{node: n.node, hops: n.hops}
 }
 
 // distanceHeap is a min-distance heap that's used within our path finding
@@ -56,18 +74,18 @@ type nodeWithDist struct {
 type distanceHeap struct {
 	nodes []*nodeWithDist
 
-	// pubkeyIndices maps public keys of nodes to their respective index in
+	// labelIndices maps the labels of nodes to their respective index in
 	// the heap. This is used as a way to avoid db lookups by using heap.Fix
 	// instead of having duplicate entries on the heap.
-	pubkeyIndices map[route.Vertex]int
+	labelIndices map[nodeLabel]int
 }
 
 // newDistanceHeap initializes a new distance heap. This is required because
-// we must initialize the pubkeyIndices map for path-finding optimizations.
+// we must initialize the labelIndices map for path-finding optimizations.
 func newDistanceHeap(numNodes int) distanceHeap {
 	distHeap := distanceHeap{
-		pubkeyIndices: make(map[route.Vertex]int, numNodes),
-		nodes:         make([]*nodeWithDist, 0, numNodes),
+		labelIndices: make(map[nodeLabel]int, numNodes),
+		nodes:        make([]*nodeWithDist, 0, numNodes),
 	}
 
 	return distHeap
@@ -96,8 +114,8 @@ func (d *distanceHeap) Less(i, j int) bool {
 // NOTE: This is part of the heap.Interface implementation.
 func (d *distanceHeap) Swap(i, j int) {
 	d.nodes[i], d.nodes[j] = d.nodes[j], d.nodes[i]
-	d.pubkeyIndices[d.nodes[i].node] = i
-	d.pubkeyIndices[d.nodes[j].node] = j
+	d.labelIndices[d.nodes[i].label()] = i
+	d.labelIndices[d.nodes[j].label()] = j
 }
 
 // Push pushes the passed item onto the priority queue.
@@ -106,7 +124,7 @@ func (d *distanceHeap) Swap(i, j int) {
 func (d *distanceHeap) Push(x interface{}) {
 	n := x.(*nodeWithDist)
 	d.nodes = append(d.nodes, n)
-	d.pubkeyIndices[n.node] = len(d.nodes) - 1
+	d.labelIndices[n.label()] = len(d.nodes) - 1
 }
 
 // Pop removes the highest priority item (according to Less) from the priority
@@ -118,17 +136,17 @@ func (d *distanceHeap) Pop() interface{} {
 	x := d.nodes[n-1]
 	d.nodes[n-1] = nil
 	d.nodes = d.nodes[0 : n-1]
-	delete(d.pubkeyIndices, x.node)
+	delete(d.labelIndices, x.label())
 	return x
 }
 
 // PushOrFix attempts to adjust the position of a given node in the heap.
-// If the vertex already exists in the heap, then we must call heap.Fix to
+// If the label already exists in the heap, then we must call heap.Fix to
 // modify its position and reorder the heap. If the vertex does not already
 // exist in the heap, then it is pushed onto the heap. Otherwise, we will end
 // up performing more db lookups on the same node in the pathfinding algorithm.
 func (d *distanceHeap) PushOrFix(dist *nodeWithDist) {
-	index, ok := d.pubkeyIndices[dist.node]
+	index, ok := d.labelIndices[dist.label()]
 	if !ok {
 		heap.Push(d, dist)
 		return
@@ -61,11 +61,11 @@ func TestHeapOrdering(t *testing.T) {
 		poppedEntries = append(poppedEntries, e)
 	}
 
-	// Assert that the pubkeyIndices map is empty after popping all of the
+	// Assert that the labelIndices map is empty after popping all of the
 	// items off of it.
-	if len(nodeHeap.pubkeyIndices) != 0 {
-		t.Fatalf("there are still %d pubkeys in the pubkeyIndices map",
-			len(nodeHeap.pubkeyIndices))
+	if len(nodeHeap.labelIndices) != 0 {
+		t.Fatalf("there are still %d labels in the labelIndices map",
+			len(nodeHeap.labelIndices))
 	}
 
 	// Finally, ensure that the items popped from the heap and the items we
@@ -257,7 +257,9 @@ func (c *integratedRoutingContext) testPayment(maxParts uint32,
 		if success {
 			inFlightHtlcs++
 
-			err := mc.ReportPaymentSuccess(pid, route)
+			err := mc.ReportPaymentSuccess(
+				pid, route, time.Now(),
+			)
 			if err != nil {
 				c.t.Fatal(err)
 			}
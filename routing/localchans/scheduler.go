@@ -0,0 +1,527 @@
+package localchans
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// MinScheduleInterval is the smallest interval at which a scheduled policy
+// update can be repeated. Every update results in a new channel update being
+// broadcast to the network, so we don't want to flood it.
+const MinScheduleInterval = time.Hour
+
+var (
+	// scheduledUpdatesBucket is the top level bucket that stores the
+	// scheduled channel policy updates, keyed by their ID.
+	scheduledUpdatesBucket = []byte("scheduled-policy-updates")
+
+	// scheduledUpdateErrorsBucket is the top level bucket that stores the
+	// error of the last failed attempt to apply a scheduled update, keyed
+	// by the ID of the update.
+	scheduledUpdateErrorsBucket = []byte("scheduled-policy-update-errors")
+
+	// byteOrder is the byte order used to serialize scheduled updates.
+	byteOrder = binary.BigEndian
+
+	// ErrScheduledUpdateNotFound is returned when a scheduled policy
+	// update with the given ID doesn't exist.
+	ErrScheduledUpdateNotFound = errors.New("scheduled policy update " +
+		"not found")
+)
+
+// ScheduledUpdate is a channel policy update that is applied at a given time,
+// and optionally repeated at a fixed interval.
+type ScheduledUpdate struct {
+	// ID uniquely identifies the scheduled update.
+	ID uint64
+
+	// Request is the policy update that is applied.
+	Request *lnrpc.PolicyUpdateRequest
+
+	// NextRun is the time at which the update is applied next.
+	NextRun time.Time
+
+	// Interval is the interval at which the update is repeated. If zero,
+	// the update is only applied once.
+	Interval time.Duration
+
+	// LastError is the error of the last attempt to apply the update, if
+	// it failed. A one-off update that failed isn't applied again, but
+	// is kept with its error until it is canceled.
+	LastError string
+}
+
+// copy returns a deep copy of the scheduled update.
+func (s *ScheduledUpdate) copy() *ScheduledUpdate {
+	req, _ := proto.Clone(s.Request).(*lnrpc.PolicyUpdateRequest)
+
+	return &ScheduledUpdate{
+		ID:        s.ID,
+		Request:   req,
+		NextRun:   s.NextRun,
+		Interval:  s.Interval,
+		LastError: s.LastError,
+	}
+}
+
+// pending returns whether the update is still going to be applied. This is
+// the case for all repeating updates and for one-off updates that haven't
+// failed.
+func (s *ScheduledUpdate) pending() bool {
+	return s.Interval != 0 || s.LastError == ""
+}
+
+// SchedulerConfig holds the dependencies of the Scheduler.
+type SchedulerConfig struct {
+	// DB is the database the scheduled updates are persisted in.
+	DB kvdb.Backend
+
+	// Clock is the time source used to determine when updates are due.
+	Clock clock.Clock
+
+	// ApplyUpdate applies the given channel policy update.
+	ApplyUpdate func(*lnrpc.PolicyUpdateRequest) error
+}
+
+// Scheduler applies channel policy updates according to a time-based plan.
+// This allows, for example, charging different fees at weekends by
+// scheduling two weekly repeating updates.
+type Scheduler struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *SchedulerConfig
+
+	// updates holds all scheduled updates, keyed by their ID.
+	updates map[uint64]*ScheduledUpdate
+	mtx     sync.Mutex
+
+	// scheduleChanged is signaled whenever an update is added or
+	// canceled, so the main loop can re-evaluate when to wake up next.
+	scheduleChanged chan struct{}
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// NewScheduler creates a new Scheduler and loads all scheduled updates from
+// the database.
+func NewScheduler(cfg *SchedulerConfig) (*Scheduler, error) {
+	updates := make(map[uint64]*ScheduledUpdate)
+	err := kvdb.Update(cfg.DB, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(scheduledUpdatesBucket)
+		if err != nil {
+			return err
+		}
+
+		errBucket, err := tx.CreateTopLevelBucket(
+			scheduledUpdateErrorsBucket,
+		)
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			update, err := deserializeScheduledUpdate(k, v)
+			if err != nil {
+				return err
+			}
+			update.LastError = string(errBucket.Get(k))
+			updates[update.ID] = update
+
+			return nil
+		})
+	}, func() {
+		updates = make(map[uint64]*ScheduledUpdate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Scheduler{
+		cfg:             cfg,
+		updates:         updates,
+		scheduleChanged: make(chan struct{}, 1),
+		quit:            make(chan struct{}),
+	}, nil
+}
+
+// Start launches the main loop of the scheduler. Updates that became due
+// while the scheduler wasn't running are applied right away.
+func (s *Scheduler) Start() error {
+	s.started.Do(func() {
+		log.Infof("Policy update scheduler starting with %v scheduled "+
+			"updates", len(s.updates))
+
+		s.wg.Add(1)
+		go s.mainLoop()
+	})
+
+	return nil
+}
+
+// Stop signals the main loop to exit and waits for it to do so.
+func (s *Scheduler) Stop() error {
+	s.stopped.Do(func() {
+		log.Info("Policy update scheduler shutting down...")
+		defer log.Debug("Policy update scheduler shutdown complete")
+
+		close(s.quit)
+		s.wg.Wait()
+	})
+
+	return nil
+}
+
+// Schedule adds a new policy update that is first applied at the given start
+// time. If interval is non-zero, the update is repeated at that interval.
+// For repeating updates, a start time in the past denotes the first
+// occurrence of the plan, and the update is next applied at the first
+// occurrence after the current time.
+func (s *Scheduler) Schedule(req *lnrpc.PolicyUpdateRequest, start time.Time,
+	interval time.Duration) (*ScheduledUpdate, error) {
+
+	if interval != 0 && interval < MinScheduleInterval {
+		return nil, fmt.Errorf("interval of %v is too small, minimum "+
+			"is %v", interval, MinScheduleInterval)
+	}
+
+	now := s.cfg.Clock.Now()
+	nextRun := start
+	if nextRun.Before(now) {
+		if interval == 0 {
+			return nil, fmt.Errorf("start time %v is in the past",
+				start)
+		}
+
+		nextRun = nextRunAfter(start, interval, now)
+	}
+
+	// We truncate the times to seconds as that's the resolution we
+	// persist them with.
+	update := (&ScheduledUpdate{
+		Request:  req,
+		NextRun:  time.Unix(nextRun.Unix(), 0),
+		Interval: interval.Truncate(time.Second),
+	}).copy()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	err := kvdb.Update(s.cfg.DB, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(scheduledUpdatesBucket)
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		update.ID = id
+
+		return putScheduledUpdate(bucket, update)
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	s.updates[update.ID] = update
+	s.signalScheduleChanged()
+
+	log.Infof("Scheduled policy update %v, next_run=%v, interval=%v",
+		update.ID, update.NextRun, update.Interval)
+
+	return update.copy(), nil
+}
+
+// Cancel removes the scheduled update with the given ID.
+func (s *Scheduler) Cancel(id uint64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, ok := s.updates[id]; !ok {
+		return ErrScheduledUpdateNotFound
+	}
+
+	if err := s.deleteUpdate(id); err != nil {
+		return err
+	}
+	s.signalScheduleChanged()
+
+	log.Infof("Canceled scheduled policy update %v", id)
+
+	return nil
+}
+
+// ScheduledUpdates returns all scheduled updates, ordered by the time they
+// are applied next.
+func (s *Scheduler) ScheduledUpdates() []*ScheduledUpdate {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	updates := make([]*ScheduledUpdate, 0, len(s.updates))
+	for _, update := range s.updates {
+		updates = append(updates, update.copy())
+	}
+	sortScheduledUpdates(updates)
+
+	return updates
+}
+
+// signalScheduleChanged wakes up the main loop without blocking.
+func (s *Scheduler) signalScheduleChanged() {
+	select {
+	case s.scheduleChanged <- struct{}{}:
+	default:
+	}
+}
+
+// mainLoop applies the scheduled updates once they are due.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *Scheduler) mainLoop() {
+	defer s.wg.Done()
+
+	for {
+		// Wake up once the next update is due. Without any scheduled
+		// updates, we only wait for the schedule to change.
+		var nextTick <-chan time.Time
+		if nextRun, ok := s.nextRun(); ok {
+			nextTick = s.cfg.Clock.TickAfter(
+				nextRun.Sub(s.cfg.Clock.Now()),
+			)
+		}
+
+		select {
+		case <-nextTick:
+			s.applyDueUpdates()
+
+		case <-s.scheduleChanged:
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// nextRun returns the earliest time at which a scheduled update is due, if
+// there is any.
+func (s *Scheduler) nextRun() (time.Time, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var (
+		next  time.Time
+		found bool
+	)
+	for _, update := range s.updates {
+		if !update.pending() {
+			continue
+		}
+
+		if !found || update.NextRun.Before(next) {
+			next = update.NextRun
+			found = true
+		}
+	}
+
+	return next, found
+}
+
+// applyDueUpdates applies all updates that are due, in the order of their
+// scheduled time. Repeating updates are rescheduled and one-off updates are
+// removed afterwards. If a repeating update missed several occurrences, it is
+// applied only once. The updates are applied without holding the mutex, so
+// the schedule can be listed and changed in the meantime.
+func (s *Scheduler) applyDueUpdates() {
+	now := s.cfg.Clock.Now()
+
+	for _, update := range s.dueUpdates(now) {
+		log.Infof("Applying scheduled policy update %v", update.ID)
+
+		// A failed update doesn't affect the schedule of a repeating
+		// update, we'll retry at its next occurrence.
+		var lastErr string
+		if err := s.cfg.ApplyUpdate(update.Request); err != nil {
+			log.Errorf("Unable to apply scheduled policy update "+
+				"%v: %v", update.ID, err)
+
+			lastErr = err.Error()
+		}
+
+		err := s.completeUpdate(update.ID, lastErr, now)
+		if err != nil {
+			log.Errorf("Unable to update schedule of policy "+
+				"update %v: %v", update.ID, err)
+		}
+	}
+}
+
+// dueUpdates returns copies of the pending updates that are due at the given
+// time, ordered by their scheduled time.
+func (s *Scheduler) dueUpdates(now time.Time) []*ScheduledUpdate {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var due []*ScheduledUpdate
+	for _, update := range s.updates {
+		if update.pending() && !update.NextRun.After(now) {
+			due = append(due, update.copy())
+		}
+	}
+	sortScheduledUpdates(due)
+
+	return due
+}
+
+// completeUpdate updates the schedule after an attempt to apply the update
+// with the given ID. A one-off update is removed if it succeeded and kept
+// with its error otherwise. A repeating update is rescheduled. If the update
+// was canceled in the meantime, nothing is done.
+func (s *Scheduler) completeUpdate(id uint64, lastErr string,
+	now time.Time) error {
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	update, ok := s.updates[id]
+	if !ok {
+		return nil
+	}
+
+	if update.Interval == 0 && lastErr == "" {
+		return s.deleteUpdate(id)
+	}
+
+	// We move the in-memory schedule forward even if we fail to persist
+	// it, so we don't apply the same update over and over.
+	if update.Interval != 0 {
+		update.NextRun = nextRunAfter(
+			update.NextRun, update.Interval, now,
+		)
+	}
+	update.LastError = lastErr
+
+	return kvdb.Update(s.cfg.DB, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(scheduledUpdatesBucket)
+		if err := putScheduledUpdate(bucket, update); err != nil {
+			return err
+		}
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], id)
+
+		errBucket := tx.ReadWriteBucket(scheduledUpdateErrorsBucket)
+		if lastErr == "" {
+			return errBucket.Delete(key[:])
+		}
+
+		return errBucket.Put(key[:], []byte(lastErr))
+	}, func() {})
+}
+
+// deleteUpdate removes the scheduled update with the given ID.
+//
+// NOTE: The caller MUST hold the mutex.
+func (s *Scheduler) deleteUpdate(id uint64) error {
+	delete(s.updates, id)
+
+	return kvdb.Update(s.cfg.DB, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(scheduledUpdatesBucket)
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], id)
+
+		if err := bucket.Delete(key[:]); err != nil {
+			return err
+		}
+
+		errBucket := tx.ReadWriteBucket(scheduledUpdateErrorsBucket)
+
+		return errBucket.Delete(key[:])
+	}, func() {})
+}
+
+// nextRunAfter returns the first occurrence of a plan that starts at the
+// given time and repeats at the given interval, which is after now.
+func nextRunAfter(start time.Time, interval time.Duration,
+	now time.Time) time.Time {
+
+	if start.After(now) {
+		return start
+	}
+
+	missed := now.Sub(start)/interval + 1
+
+	return start.Add(missed * interval)
+}
+
+// sortScheduledUpdates sorts the updates by their next run and ID.
+func sortScheduledUpdates(updates []*ScheduledUpdate) {
+	sort.Slice(updates, func(i, j int) bool {
+		if !updates[i].NextRun.Equal(updates[j].NextRun) {
+			return updates[i].NextRun.Before(updates[j].NextRun)
+		}
+
+		return updates[i].ID < updates[j].ID
+	})
+}
+
+// putScheduledUpdate serializes the scheduled update into the bucket. The
+// value consists of the unix time of the next run, the interval in seconds
+// and the serialized policy update request.
+func putScheduledUpdate(bucket kvdb.RwBucket, update *ScheduledUpdate) error {
+	reqBytes, err := proto.Marshal(update.Request)
+	if err != nil {
+		return err
+	}
+
+	var key [8]byte
+	byteOrder.PutUint64(key[:], update.ID)
+
+	var b bytes.Buffer
+	err = binary.Write(&b, byteOrder, update.NextRun.Unix())
+	if err != nil {
+		return err
+	}
+	err = binary.Write(&b, byteOrder, uint64(update.Interval/time.Second))
+	if err != nil {
+		return err
+	}
+	if _, err := b.Write(reqBytes); err != nil {
+		return err
+	}
+
+	return bucket.Put(key[:], b.Bytes())
+}
+
+// deserializeScheduledUpdate decodes a scheduled update stored under the
+// given key.
+func deserializeScheduledUpdate(k, v []byte) (*ScheduledUpdate, error) {
+	if len(k) != 8 || len(v) < 16 {
+		return nil, fmt.Errorf("invalid scheduled policy update")
+	}
+
+	req := &lnrpc.PolicyUpdateRequest{}
+	if err := proto.Unmarshal(v[16:], req); err != nil {
+		return nil, err
+	}
+
+	nextRun := int64(byteOrder.Uint64(v[:8]))
+	interval := time.Duration(byteOrder.Uint64(v[8:16])) * time.Second
+
+	return &ScheduledUpdate{
+		ID:       byteOrder.Uint64(k),
+		Request:  req,
+		NextRun:  time.Unix(nextRun, 0),
+		Interval: interval,
+	}, nil
+}
@@ -0,0 +1,303 @@
+package localchans
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/clock"
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/stretchr/testify/require"
+)
+
+const applyTimeout = 5 * time.Second
+
+// schedulerHarness bundles a Scheduler with the dependencies that allow the
+// test to control time and observe the applied updates.
+type schedulerHarness struct {
+	t         *testing.T
+	db        kvdb.Backend
+	clock     *clock.TestClock
+	applied   chan *lnrpc.PolicyUpdateRequest
+	scheduler *Scheduler
+}
+
+func newSchedulerHarness(t *testing.T, db kvdb.Backend,
+	testClock *clock.TestClock) *schedulerHarness {
+
+	h := &schedulerHarness{
+		t:       t,
+		db:      db,
+		clock:   testClock,
+		applied: make(chan *lnrpc.PolicyUpdateRequest, 10),
+	}
+
+	scheduler, err := NewScheduler(&SchedulerConfig{
+		DB:    db,
+		Clock: testClock,
+		ApplyUpdate: func(req *lnrpc.PolicyUpdateRequest) error {
+			h.applied <- req
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, scheduler.Start())
+	t.Cleanup(func() {
+		require.NoError(t, scheduler.Stop())
+	})
+	h.scheduler = scheduler
+
+	return h
+}
+
+// assertApplied asserts that the update with the given base fee is applied.
+func (h *schedulerHarness) assertApplied(baseFee int64) {
+	select {
+	case req := <-h.applied:
+		require.Equal(h.t, baseFee, req.BaseFeeMsat)
+
+	case <-time.After(applyTimeout):
+		h.t.Fatalf("update with base fee %v not applied", baseFee)
+	}
+}
+
+// assertNotApplied asserts that no update is applied.
+func (h *schedulerHarness) assertNotApplied() {
+	select {
+	case req := <-h.applied:
+		h.t.Fatalf("unexpected update with base fee %v applied",
+			req.BaseFeeMsat)
+
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestScheduler tests that scheduled policy updates are applied at their
+// scheduled times, that repeating updates are rescheduled and that the
+// schedule survives a restart.
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	db, cleanup, err := kvdb.GetTestBackend(t.TempDir(), "scheduler")
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	start := time.Unix(1_700_000_000, 0)
+	testClock := clock.NewTestClock(start)
+	h := newSchedulerHarness(t, db, testClock)
+
+	policy := func(baseFee int64) *lnrpc.PolicyUpdateRequest {
+		return &lnrpc.PolicyUpdateRequest{
+			Scope: &lnrpc.PolicyUpdateRequest_Global{
+				Global: true,
+			},
+			BaseFeeMsat: baseFee,
+		}
+	}
+
+	// One-off updates can't be scheduled in the past, and repeating
+	// updates must respect the minimum interval.
+	_, err = h.scheduler.Schedule(policy(1), start.Add(-time.Second), 0)
+	require.Error(t, err)
+
+	_, err = h.scheduler.Schedule(policy(1), start, time.Minute)
+	require.Error(t, err)
+
+	// Schedule a one-off update in an hour and a daily update whose plan
+	// started a day and a half ago, so it is next due in twelve hours.
+	oneOff, err := h.scheduler.Schedule(
+		policy(1), start.Add(time.Hour), 0,
+	)
+	require.NoError(t, err)
+
+	daily, err := h.scheduler.Schedule(
+		policy(2), start.Add(-36*time.Hour), 24*time.Hour,
+	)
+	require.NoError(t, err)
+	require.Equal(t, start.Add(12*time.Hour), daily.NextRun)
+
+	require.Equal(
+		t, []*ScheduledUpdate{oneOff, daily},
+		h.scheduler.ScheduledUpdates(),
+	)
+
+	// Nothing is applied before the first update is due.
+	h.assertNotApplied()
+
+	testClock.SetTime(start.Add(time.Hour))
+	h.assertApplied(1)
+
+	// The one-off update is removed once it has been applied.
+	require.Eventually(t, func() bool {
+		return len(h.scheduler.ScheduledUpdates()) == 1
+	}, applyTimeout, 10*time.Millisecond)
+
+	testClock.SetTime(start.Add(12 * time.Hour))
+	h.assertApplied(2)
+
+	// The daily update is rescheduled for the next day.
+	require.Eventually(t, func() bool {
+		updates := h.scheduler.ScheduledUpdates()
+
+		return len(updates) == 1 &&
+			updates[0].NextRun.Equal(start.Add(36*time.Hour))
+	}, applyTimeout, 10*time.Millisecond)
+
+	// Restart the scheduler after the daily update missed two
+	// occurrences. It is applied once right away and then rescheduled
+	// for the next occurrence.
+	require.NoError(t, h.scheduler.Stop())
+
+	testClock.SetTime(start.Add(70 * time.Hour))
+	h = newSchedulerHarness(t, db, testClock)
+	h.assertApplied(2)
+	h.assertNotApplied()
+
+	require.Eventually(t, func() bool {
+		updates := h.scheduler.ScheduledUpdates()
+
+		return len(updates) == 1 &&
+			updates[0].NextRun.Equal(start.Add(84*time.Hour))
+	}, applyTimeout, 10*time.Millisecond)
+
+	// Finally, cancel the daily update. It isn't applied anymore.
+	require.ErrorIs(
+		t, h.scheduler.Cancel(oneOff.ID), ErrScheduledUpdateNotFound,
+	)
+	require.NoError(t, h.scheduler.Cancel(daily.ID))
+	require.Empty(t, h.scheduler.ScheduledUpdates())
+
+	testClock.SetTime(start.Add(84 * time.Hour))
+	h.assertNotApplied()
+}
+
+// TestSchedulerFailedUpdate tests that the schedule can be accessed while an
+// update is applied and that a failed one-off update is kept with its error
+// instead of being applied again.
+func TestSchedulerFailedUpdate(t *testing.T) {
+	t.Parallel()
+
+	db, cleanup, err := kvdb.GetTestBackend(t.TempDir(), "scheduler")
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	start := time.Unix(1_700_000_000, 0)
+	testClock := clock.NewTestClock(start)
+
+	applying := make(chan struct{}, 1)
+	applyResult := make(chan error)
+	newScheduler := func() *Scheduler {
+		scheduler, err := NewScheduler(&SchedulerConfig{
+			DB:    db,
+			Clock: testClock,
+			ApplyUpdate: func(*lnrpc.PolicyUpdateRequest) error {
+				applying <- struct{}{}
+				return <-applyResult
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, scheduler.Start())
+
+		return scheduler
+	}
+	scheduler := newScheduler()
+
+	update, err := scheduler.Schedule(
+		&lnrpc.PolicyUpdateRequest{}, start.Add(time.Hour), 0,
+	)
+	require.NoError(t, err)
+
+	testClock.SetTime(start.Add(time.Hour))
+	select {
+	case <-applying:
+	case <-time.After(applyTimeout):
+		t.Fatalf("update not applied")
+	}
+
+	// The schedule isn't locked while the update is applied.
+	require.Equal(
+		t, []*ScheduledUpdate{update}, scheduler.ScheduledUpdates(),
+	)
+
+	applyResult <- errors.New("channel not found")
+
+	require.Eventually(t, func() bool {
+		updates := scheduler.ScheduledUpdates()
+
+		return len(updates) == 1 &&
+			updates[0].LastError == "channel not found"
+	}, applyTimeout, 10*time.Millisecond)
+
+	// The failed update isn't applied again, also not after a restart.
+	testClock.SetTime(start.Add(2 * time.Hour))
+	require.NoError(t, scheduler.Stop())
+
+	scheduler = newScheduler()
+	t.Cleanup(func() {
+		require.NoError(t, scheduler.Stop())
+	})
+
+	select {
+	case <-applying:
+		t.Fatalf("failed update applied again")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	updates := scheduler.ScheduledUpdates()
+	require.Len(t, updates, 1)
+	require.Equal(t, "channel not found", updates[0].LastError)
+
+	// Canceling the update removes it together with its error.
+	require.NoError(t, scheduler.Cancel(update.ID))
+	require.Empty(t, scheduler.ScheduledUpdates())
+}
+
+// TestNextRunAfter tests the calculation of the next occurrence of a
+// repeating plan.
+func TestNextRunAfter(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+	interval := time.Hour
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "before start",
+			now:  start.Add(-time.Minute),
+			want: start,
+		},
+		{
+			name: "at start",
+			now:  start,
+			want: start.Add(interval),
+		},
+		{
+			name: "within first interval",
+			now:  start.Add(time.Minute),
+			want: start.Add(interval),
+		},
+		{
+			name: "at occurrence",
+			now:  start.Add(3 * interval),
+			want: start.Add(4 * interval),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(
+				t, test.want,
+				nextRunAfter(start, interval, test.now),
+			)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package routing
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -58,6 +59,10 @@ const (
 	// MissionControlConfig for further explanation.
 	DefaultAprioriWeight = 0.5
 
+	// DefaultMissionControlNamespace is the name of the mission control
+	// namespace that is used if no namespace is specified explicitly.
+	DefaultMissionControlNamespace = "default"
+
 	// DefaultMinFailureRelaxInterval is the default minimum time that must
 	// have passed since the previously recorded failure before the failure
 	// amount may be raised.
@@ -67,6 +72,16 @@ const (
 	// FeeEstimationTimeout. It defines the maximum duration that the
 	// probing fee estimation is allowed to take.
 	DefaultFeeEstimationTimeout = time.Minute
+
+	// latencySmoothing is the weight of a new latency sample in the moving
+	// average of a node pair's resolution latency.
+	latencySmoothing = 0.2
+
+	// maxLatencySample is the maximum resolution latency of a successful
+	// attempt that is taken into account. Longer latencies are most likely
+	// caused by a restart while the htlc was in flight or by a hold
+	// invoice and say little about the speed of the route.
+	maxLatencySample = time.Minute
 )
 
 var (
@@ -106,10 +121,19 @@ type MissionControl struct {
 
 	store *missionControlStore
 
+	// resets holds the times at which the history of node pairs was
+	// reset. Stored results that predate the reset of a pair aren't
+	// applied to that pair when the state is rebuilt.
+	resets *pairResets
+
 	// estimator is the probability estimator that is used with the payment
 	// results that mission control collects.
 	estimator Estimator
 
+	// bimodalOverrides holds the bimodal parameters that replace the
+	// global ones for single pairs. They are only kept in memory.
+	bimodalOverrides BimodalPairOverrides
+
 	sync.Mutex
 
 	// TODO(roasbeef): further counters, if vertex continually unavailable,
@@ -205,19 +229,28 @@ type paymentResult struct {
 	failure            lnwire.FailureMessage
 }
 
-// NewMissionControl returns a new instance of missionControl.
+// NewMissionControl returns a new instance of missionControl that operates on
+// the default namespace.
 func NewMissionControl(db kvdb.Backend, self route.Vertex,
 	cfg *MissionControlConfig) (*MissionControl, error) {
 
-	log.Debugf("Instantiating mission control with config: %v, %v", cfg,
-		cfg.Estimator)
+	return newMissionControl(db, self, DefaultMissionControlNamespace, cfg)
+}
+
+// newMissionControl returns a new instance of missionControl that keeps its
+// payment results in the given namespace.
+func newMissionControl(db kvdb.Backend, self route.Vertex, namespace string,
+	cfg *MissionControlConfig) (*MissionControl, error) {
+
+	log.Debugf("Instantiating mission control for namespace %v with "+
+		"config: %v, %v", namespace, cfg, cfg.Estimator)
 
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
 
 	store, err := newMissionControlStore(
-		db, cfg.MaxMcHistory, cfg.McFlushInterval,
+		db, namespace, cfg.MaxMcHistory, cfg.McFlushInterval,
 	)
 	if err != nil {
 		return nil, err
@@ -228,6 +261,7 @@ func NewMissionControl(db kvdb.Backend, self route.Vertex,
 		now:       time.Now,
 		selfNode:  self,
 		store:     store,
+		resets:    newPairResets(),
 		estimator: cfg.Estimator,
 	}
 
@@ -257,6 +291,12 @@ func (m *MissionControl) init() error {
 
 	start := time.Now()
 
+	resets, err := m.store.fetchResets()
+	if err != nil {
+		return err
+	}
+	m.resets = resets
+
 	results, err := m.store.fetchAll()
 	if err != nil {
 		return err
@@ -322,12 +362,61 @@ func (m *MissionControl) ResetHistory() error {
 	}
 
 	m.state.resetHistory()
+	m.resets = newPairResets()
 
 	log.Debugf("Mission control history cleared")
 
 	return nil
 }
 
+// ResetPairs resets the history of the given node pairs and of all pairs that
+// have one of the given nodes as their source or destination, while keeping
+// the history of all other pairs. The reset is persisted, so that stored
+// payment results that predate it aren't applied to the reset pairs when the
+// state is rebuilt after a restart. Other pairs of these results are kept. It
+// returns the number of pairs that were reset.
+func (m *MissionControl) ResetPairs(pairs []DirectedNodePair,
+	nodes []route.Vertex) (int, error) {
+
+	pairSet := make(map[DirectedNodePair]struct{}, len(pairs))
+	for _, pair := range pairs {
+		pairSet[pair] = struct{}{}
+	}
+
+	nodeSet := make(map[route.Vertex]struct{}, len(nodes))
+	for _, node := range nodes {
+		nodeSet[node] = struct{}{}
+	}
+
+	match := func(pair DirectedNodePair) bool {
+		if _, ok := pairSet[pair]; ok {
+			return true
+		}
+
+		_, ok := nodeSet[pair.From]
+		if !ok {
+			_, ok = nodeSet[pair.To]
+		}
+
+		return ok
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	resetTime := m.now()
+	if err := m.store.addResets(pairs, nodes, resetTime); err != nil {
+		return 0, err
+	}
+	m.resets.add(pairs, nodes, resetTime)
+
+	numPairs := m.state.resetPairs(match)
+
+	log.Debugf("Mission control history of %v pairs reset", numPairs)
+
+	return numPairs, nil
+}
+
 // GetProbability is expected to return the success probability of a payment
 // from fromNode along edge.
 func (m *MissionControl) GetProbability(fromNode, toNode route.Vertex,
@@ -336,17 +425,152 @@ func (m *MissionControl) GetProbability(fromNode, toNode route.Vertex,
 	m.Lock()
 	defer m.Unlock()
 
+	return m.getProbability(m.estimator, fromNode, toNode, amt, capacity)
+}
+
+// GetRouteProbability returns the success probability of the given route. The
+// capacities of the channels of the route are passed in the same order as the
+// hops. Next to the total probability, the conditional success probability of
+// every hop is returned. If the same directed node pair is used more than
+// once, the later hops are conditioned on the liquidity that is already used
+// by the earlier ones.
+func (m *MissionControl) GetRouteProbability(rt *route.Route,
+	capacities []btcutil.Amount) (float64, []float64, error) {
+
+	if len(capacities) != len(rt.Hops) {
+		return 0, nil, fmt.Errorf("expected %v capacities, got %v",
+			len(rt.Hops), len(capacities))
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	var (
+		total    = 1.0
+		hopProbs = make([]float64, len(rt.Hops))
+		usedAmts = make(map[DirectedNodePair]lnwire.MilliSatoshi)
+	)
+	for i := range rt.Hops {
+		pair, amt := getPair(rt, i)
+		capacity := capacities[i]
+
+		// The probability that the pair can carry the total amount
+		// given that it can carry the amount of the earlier hops is
+		// P(total) / P(earlier).
+		prevAmt := usedAmts[pair]
+		prob := m.getProbability(
+			m.estimator, pair.From, pair.To, prevAmt+amt, capacity,
+		)
+		if prevAmt > 0 {
+			prevProb := m.getProbability(
+				m.estimator, pair.From, pair.To, prevAmt,
+				capacity,
+			)
+
+			if prevProb == 0 {
+				prob = 0
+			} else {
+				prob = math.Min(prob/prevProb, 1)
+			}
+		}
+		usedAmts[pair] = prevAmt + amt
+
+		hopProbs[i] = prob
+		total *= prob
+	}
+
+	return total, hopProbs, nil
+}
+
+// GetProbabilityWithEstimator returns the success probability of a payment
+// from fromNode along edge, using the given estimator instead of the one that
+// mission control is configured with.
+func (m *MissionControl) GetProbabilityWithEstimator(estimator Estimator,
+	fromNode, toNode route.Vertex, amt lnwire.MilliSatoshi,
+	capacity btcutil.Amount) float64 {
+
+	m.Lock()
+	defer m.Unlock()
+
+	return m.getProbability(estimator, fromNode, toNode, amt, capacity)
+}
+
+// getProbability estimates the success probability of a payment from fromNode
+// along edge with the given estimator.
+//
+// NOTE: The caller must hold the mission control lock.
+func (m *MissionControl) getProbability(estimator Estimator, fromNode,
+	toNode route.Vertex, amt lnwire.MilliSatoshi,
+	capacity btcutil.Amount) float64 {
+
 	now := m.now()
 	results, _ := m.state.getLastPairResult(fromNode)
 
 	// Use a distinct probability estimation function for local channels.
 	if fromNode == m.selfNode {
-		return m.estimator.LocalPairProbability(now, results, toNode)
+		return estimator.LocalPairProbability(now, results, toNode)
 	}
 
-	return m.estimator.PairProbability(
-		now, results, toNode, amt, capacity,
-	)
+	// Use the parameters that were set for this pair if the probability is
+	// estimated with the bimodal model.
+	bimodal, ok := estimator.(*BimodalEstimator)
+	if ok {
+		pair := NewDirectedNodePair(fromNode, toNode)
+		if override, ok := m.bimodalOverrides[pair]; ok {
+			estimator = bimodal.withPairOverride(override)
+		}
+	}
+
+	return estimator.PairProbability(now, results, toNode, amt, capacity)
+}
+
+// SetBimodalPairOverrides sets the bimodal parameters of the given pairs,
+// replacing a previous override of the same pair, and removes the overrides of
+// the pairs to remove. The overrides only apply if mission control uses the
+// bimodal estimator and aren't persisted across restarts.
+func (m *MissionControl) SetBimodalPairOverrides(
+	overrides BimodalPairOverrides, remove []DirectedNodePair) error {
+
+	for pair, override := range overrides {
+		if err := override.Validate(); err != nil {
+			return fmt.Errorf("pair %v -> %v: %w", pair.From,
+				pair.To, err)
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if m.bimodalOverrides == nil {
+		m.bimodalOverrides = make(BimodalPairOverrides)
+	}
+
+	for _, pair := range remove {
+		delete(m.bimodalOverrides, pair)
+	}
+
+	for pair, override := range overrides {
+		m.bimodalOverrides[pair] = override
+	}
+
+	log.Debugf("Set %v and removed %v bimodal pair overrides",
+		len(overrides), len(remove))
+
+	return nil
+}
+
+// GetBimodalPairOverrides returns a copy of the bimodal parameters that are
+// set for single pairs.
+func (m *MissionControl) GetBimodalPairOverrides() BimodalPairOverrides {
+	m.Lock()
+	defer m.Unlock()
+
+	overrides := make(BimodalPairOverrides, len(m.bimodalOverrides))
+	for pair, override := range m.bimodalOverrides {
+		overrides[pair] = override
+	}
+
+	return overrides
 }
 
 // GetHistorySnapshot takes a snapshot from the current mission control state
@@ -428,14 +652,15 @@ func (m *MissionControl) ReportPaymentFail(paymentID uint64, rt *route.Route,
 }
 
 // ReportPaymentSuccess reports a successful payment to mission control as input
-// for future probability estimates.
+// for future probability estimates. The attempt time is the time at which the
+// htlc was sent out and is used to track the resolution latency of the route.
 func (m *MissionControl) ReportPaymentSuccess(paymentID uint64,
-	rt *route.Route) error {
+	rt *route.Route, attemptTime time.Time) error {
 
 	timestamp := m.now()
 
 	result := &paymentResult{
-		timeFwd:   timestamp,
+		timeFwd:   attemptTime,
 		timeReply: timestamp,
 		id:        paymentID,
 		success:   true,
@@ -475,7 +700,14 @@ func (m *MissionControl) applyPaymentResult(
 		result.failure,
 	)
 
-	if i.policyFailure != nil {
+	// Pairs that were reset after the result was received must not be
+	// affected by it. This only applies to stored results that are
+	// applied when the state is rebuilt.
+	isReset := func(pair DirectedNodePair) bool {
+		return m.resets.isReset(pair, result.timeReply)
+	}
+
+	if i.policyFailure != nil && !isReset(*i.policyFailure) {
 		if m.state.requestSecondChance(
 			result.timeReply,
 			i.policyFailure.From, i.policyFailure.To,
@@ -511,6 +743,10 @@ func (m *MissionControl) applyPaymentResult(
 	for pair, pairResult := range i.pairResults {
 		pairResult := pairResult
 
+		if isReset(pair) {
+			continue
+		}
+
 		if pairResult.success {
 			log.Debugf("Reporting pair success to Mission "+
 				"Control: pair=%v, amt=%v",
@@ -526,5 +762,50 @@ func (m *MissionControl) applyPaymentResult(
 		)
 	}
 
+	if result.success {
+		m.applyLatency(result)
+	}
+
 	return i.finalFailureReason
 }
+
+// applyLatency attributes the resolution latency of a successful payment
+// attempt to the node pairs of its route. As it is unknown which hop held on
+// to the htlc, the latency is split evenly across all pairs.
+func (m *MissionControl) applyLatency(result *paymentResult) {
+	latency := result.timeReply.Sub(result.timeFwd)
+	if latency <= 0 || latency > maxLatencySample {
+		return
+	}
+
+	hops := result.route.Hops
+	if len(hops) == 0 {
+		return
+	}
+
+	share := latency / time.Duration(len(hops))
+
+	from := result.route.SourcePubKey
+	for _, hop := range hops {
+		pair := NewDirectedNodePair(from, hop.PubKeyBytes)
+		if !m.resets.isReset(pair, result.timeReply) {
+			m.state.addPairLatency(pair, share)
+		}
+
+		from = hop.PubKeyBytes
+	}
+}
+
+// GetPairLatency returns the average resolution latency that mission control
+// attributes to the given node pair. Zero is returned for pairs without
+// successful payment attempts.
+func (m *MissionControl) GetPairLatency(fromNode,
+	toNode route.Vertex) time.Duration {
+
+	m.Lock()
+	defer m.Unlock()
+
+	latency, _ := m.state.getPairLatency(fromNode, toNode)
+
+	return latency
+}
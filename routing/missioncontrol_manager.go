@@ -0,0 +1,229 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+const (
+	// MaxMissionControlNamespaceLen is the maximum length of a mission
+	// control namespace name.
+	MaxMissionControlNamespaceLen = 64
+
+	// MaxMissionControlNamespaces is the maximum number of mission control
+	// namespaces, including the default one. Every namespace holds its own
+	// results in memory and on disk, so their number must be bounded.
+	MaxMissionControlNamespaces = 32
+)
+
+var (
+	// ErrInvalidMcNamespace is returned when a mission control namespace
+	// name is empty, too long or contains unsupported characters.
+	ErrInvalidMcNamespace = fmt.Errorf("mission control namespace must "+
+		"consist of 1 to %d alphanumeric characters, dashes or "+
+		"underscores", MaxMissionControlNamespaceLen)
+
+	// ErrTooManyMcNamespaces is returned when a new mission control
+	// namespace would exceed the maximum number of namespaces.
+	ErrTooManyMcNamespaces = fmt.Errorf("maximum number of %d mission "+
+		"control namespaces reached", MaxMissionControlNamespaces)
+)
+
+// MissionControlManager manages a set of isolated mission control instances,
+// one for every namespace. All namespaces share the same configuration, but
+// each of them keeps its own history of payment results. This allows
+// different applications that send payments from the same node, like a
+// rebalancer and a wallet, to not influence each other's path finding.
+type MissionControlManager struct {
+	db       kvdb.Backend
+	selfNode route.Vertex
+
+	// cfg is the config that is applied to all namespaces.
+	cfg *MissionControlConfig
+
+	// mcs holds the mission control instance of every known namespace.
+	mcs map[string]*MissionControl
+
+	// tickersRunning indicates whether the store tickers of the mission
+	// control instances have been started. Namespaces that are created
+	// later on have their ticker started right away.
+	tickersRunning bool
+
+	mu sync.Mutex
+}
+
+// NewMissionControlManager returns a new mission control manager. It
+// instantiates the default namespace and all namespaces that were persisted
+// to the database previously.
+func NewMissionControlManager(db kvdb.Backend, self route.Vertex,
+	cfg *MissionControlConfig) (*MissionControlManager, error) {
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	m := &MissionControlManager{
+		db:       db,
+		selfNode: self,
+		cfg:      cfg,
+		mcs:      make(map[string]*MissionControl),
+	}
+
+	namespaces, err := fetchNamespaces(db)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces = append(namespaces, DefaultMissionControlNamespace)
+	for _, namespace := range namespaces {
+		mc, err := newMissionControl(db, self, namespace, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		m.mcs[namespace] = mc
+	}
+
+	return m, nil
+}
+
+// ValidateMissionControlNamespace checks that the given namespace name is
+// non-empty, not too long and only consists of alphanumeric characters,
+// dashes or underscores.
+func ValidateMissionControlNamespace(namespace string) error {
+	if len(namespace) == 0 ||
+		len(namespace) > MaxMissionControlNamespaceLen {
+
+		return ErrInvalidMcNamespace
+	}
+
+	for _, c := range namespace {
+		switch {
+		case c >= 'a' && c <= 'z':
+		case c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9':
+		case c == '-' || c == '_':
+
+		default:
+			return ErrInvalidMcNamespace
+		}
+	}
+
+	return nil
+}
+
+// GetNamespacedStore returns the mission control instance of the given
+// namespace. If the namespace doesn't exist yet, it is created, unless the
+// maximum number of namespaces has been reached.
+func (m *MissionControlManager) GetNamespacedStore(
+	namespace string) (*MissionControl, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if mc, ok := m.mcs[namespace]; ok {
+		return mc, nil
+	}
+
+	if err := ValidateMissionControlNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	if len(m.mcs) >= MaxMissionControlNamespaces {
+		return nil, ErrTooManyMcNamespaces
+	}
+
+	log.Infof("Creating mission control namespace %v", namespace)
+
+	mc, err := newMissionControl(m.db, m.selfNode, namespace, m.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.tickersRunning {
+		mc.RunStoreTicker()
+	}
+
+	m.mcs[namespace] = mc
+
+	return mc, nil
+}
+
+// ListNamespaces returns the sorted names of all known mission control
+// namespaces, including the default one.
+func (m *MissionControlManager) ListNamespaces() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	namespaces := make([]string, 0, len(m.mcs))
+	for namespace := range m.mcs {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// SetConfig validates the config provided and applies it to all namespaces.
+// Namespaces that are created later on will use the new config as well.
+func (m *MissionControlManager) SetConfig(cfg *MissionControlConfig) error {
+	if cfg == nil {
+		return errors.New("nil mission control config")
+	}
+
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, mc := range m.mcs {
+		if err := mc.SetConfig(cfg); err != nil {
+			return err
+		}
+	}
+
+	// The flush interval can't be changed at runtime, so we keep the one
+	// that the store tickers were started with.
+	newCfg := *cfg
+	newCfg.McFlushInterval = m.cfg.McFlushInterval
+	m.cfg = &newCfg
+
+	return nil
+}
+
+// RunStoreTickers runs the store tickers of all namespaces.
+func (m *MissionControlManager) RunStoreTickers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.tickersRunning {
+		return
+	}
+
+	for _, mc := range m.mcs {
+		mc.RunStoreTicker()
+	}
+	m.tickersRunning = true
+}
+
+// StopStoreTickers stops the store tickers of all namespaces.
+func (m *MissionControlManager) StopStoreTickers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.tickersRunning {
+		return
+	}
+
+	for _, mc := range m.mcs {
+		mc.StopStoreTicker()
+	}
+	m.tickersRunning = false
+}
@@ -0,0 +1,123 @@
+package routing
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMissionControlManagerNamespaces tests that the results of different
+// mission control namespaces are kept isolated, also across restarts.
+func TestMissionControlManagerNamespaces(t *testing.T) {
+	file, err := os.CreateTemp("", "*.db")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, file.Close())
+		require.NoError(t, os.Remove(file.Name()))
+	})
+
+	db, err := kvdb.Open(
+		kvdb.BoltBackendName, file.Name(), true, kvdb.DefaultDBTimeout,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	estimator, err := NewAprioriEstimator(AprioriConfig{
+		PenaltyHalfLife:       testPenaltyHalfLife,
+		AprioriHopProbability: testAprioriHopProbability,
+		AprioriWeight:         testAprioriWeight,
+		CapacityFraction:      testCapacityFraction,
+	})
+	require.NoError(t, err)
+
+	cfg := &MissionControlConfig{Estimator: estimator}
+	newManager := func() *MissionControlManager {
+		m, err := NewMissionControlManager(db, mcTestSelf, cfg)
+		require.NoError(t, err)
+
+		return m
+	}
+
+	m := newManager()
+	require.Equal(
+		t, []string{DefaultMissionControlNamespace}, m.ListNamespaces(),
+	)
+
+	// Invalid namespace names are rejected.
+	_, err = m.GetNamespacedStore("")
+	require.ErrorIs(t, err, ErrInvalidMcNamespace)
+	_, err = m.GetNamespacedStore("no spaces")
+	require.ErrorIs(t, err, ErrInvalidMcNamespace)
+
+	defaultMc, err := m.GetNamespacedStore(DefaultMissionControlNamespace)
+	require.NoError(t, err)
+
+	rebalanceMc, err := m.GetNamespacedStore("rebalance")
+	require.NoError(t, err)
+
+	getP := func(mc *MissionControl) float64 {
+		return mc.GetProbability(
+			mcTestNode1, mcTestNode2, 1000, testCapacity,
+		)
+	}
+
+	// A failure reported to the rebalance namespace must not affect the
+	// default namespace.
+	mcTestRoute.Hops[0].AmtToForward = 1000
+	errorSourceIdx := 1
+	_, err = rebalanceMc.ReportPaymentFail(
+		0, mcTestRoute, &errorSourceIdx,
+		lnwire.NewTemporaryChannelFailure(nil),
+	)
+	require.NoError(t, err)
+
+	require.InDelta(t, 0, getP(rebalanceMc), 0.001)
+	require.InDelta(t, testAprioriHopProbability, getP(defaultMc), 0.001)
+
+	// After a restart, the namespace and its results are restored.
+	require.NoError(t, rebalanceMc.store.storeResults())
+
+	m = newManager()
+	require.Equal(
+		t, []string{DefaultMissionControlNamespace, "rebalance"},
+		m.ListNamespaces(),
+	)
+
+	defaultMc, err = m.GetNamespacedStore(DefaultMissionControlNamespace)
+	require.NoError(t, err)
+
+	rebalanceMc, err = m.GetNamespacedStore("rebalance")
+	require.NoError(t, err)
+
+	require.InDelta(t, 0, getP(rebalanceMc), 0.001)
+	require.InDelta(t, testAprioriHopProbability, getP(defaultMc), 0.001)
+
+	// Resetting the namespace clears its history, also on disk.
+	require.NoError(t, rebalanceMc.ResetHistory())
+	require.InDelta(
+		t, testAprioriHopProbability, getP(rebalanceMc), 0.001,
+	)
+
+	results, err := rebalanceMc.store.fetchAll()
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	// New namespaces can only be created up to the maximum number of
+	// namespaces, while existing ones can still be retrieved.
+	for i := len(m.ListNamespaces()); i < MaxMissionControlNamespaces; i++ {
+		_, err = m.GetNamespacedStore(fmt.Sprintf("ns-%d", i))
+		require.NoError(t, err)
+	}
+
+	_, err = m.GetNamespacedStore("one-too-many")
+	require.ErrorIs(t, err, ErrTooManyMcNamespaces)
+
+	_, err = m.GetNamespacedStore("rebalance")
+	require.NoError(t, err)
+}
@@ -20,6 +20,10 @@ type missionControlState struct {
 	// a directed node pair.
 	lastSecondChance map[DirectedNodePair]time.Time
 
+	// pairLatency tracks the moving average of the HTLC resolution latency
+	// that is attributed to each directed node pair.
+	pairLatency map[DirectedNodePair]time.Duration
+
 	// minFailureRelaxInterval is the minimum time that must have passed
 	// since the previously recorded failure before the failure amount may
 	// be raised.
@@ -34,6 +38,9 @@ func newMissionControlState(
 		lastPairResult:          make(map[route.Vertex]NodeResults),
 		lastSecondChance:        make(map[DirectedNodePair]time.Time),
 		minFailureRelaxInterval: minFailureRelaxInterval,
+		pairLatency: make(
+			map[DirectedNodePair]time.Duration,
+		),
 	}
 }
 
@@ -50,6 +57,69 @@ func (m *missionControlState) getLastPairResult(node route.Vertex) (NodeResults,
 func (m *missionControlState) resetHistory() {
 	m.lastPairResult = make(map[route.Vertex]NodeResults)
 	m.lastSecondChance = make(map[DirectedNodePair]time.Time)
+	m.pairLatency = make(map[DirectedNodePair]time.Duration)
+}
+
+// resetPairs removes the state of all node pairs for which the given match
+// function returns true. It returns the number of removed pairs.
+func (m *missionControlState) resetPairs(
+	match func(pair DirectedNodePair) bool) int {
+
+	var numReset int
+	for fromNode, nodePairs := range m.lastPairResult {
+		for toNode := range nodePairs {
+			pair := NewDirectedNodePair(fromNode, toNode)
+			if !match(pair) {
+				continue
+			}
+
+			delete(nodePairs, toNode)
+			numReset++
+		}
+
+		if len(nodePairs) == 0 {
+			delete(m.lastPairResult, fromNode)
+		}
+	}
+
+	for pair := range m.lastSecondChance {
+		if match(pair) {
+			delete(m.lastSecondChance, pair)
+		}
+	}
+
+	for pair := range m.pairLatency {
+		if match(pair) {
+			delete(m.pairLatency, pair)
+		}
+	}
+
+	return numReset
+}
+
+// getPairLatency returns the average resolution latency of the given node
+// pair. The boolean return value indicates whether a latency was recorded.
+func (m *missionControlState) getPairLatency(fromNode,
+	toNode route.Vertex) (time.Duration, bool) {
+
+	latency, ok := m.pairLatency[NewDirectedNodePair(fromNode, toNode)]
+	return latency, ok
+}
+
+// addPairLatency folds a latency sample into the moving average of the given
+// node pair. The first sample initializes the average.
+func (m *missionControlState) addPairLatency(pair DirectedNodePair,
+	latency time.Duration) {
+
+	current, ok := m.pairLatency[pair]
+	if !ok {
+		m.pairLatency[pair] = latency
+		return
+	}
+
+	m.pairLatency[pair] = current + time.Duration(
+		latencySmoothing*float64(latency-current),
+	)
 }
 
 // setLastPairResult stores a result for a node pair.
@@ -13,6 +13,7 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
 )
 
 var (
@@ -20,6 +21,19 @@ var (
 	// stored.
 	resultsKey = []byte("missioncontrol-results")
 
+	// namespacesKey is the fixed key of the top-level bucket that holds a
+	// nested results bucket for every mission control namespace other than
+	// the default one. The default namespace uses the results bucket above
+	// so that existing data doesn't need to be migrated.
+	namespacesKey = []byte("missioncontrol-namespaces")
+
+	// resetsKey is the fixed key of the top-level bucket that holds a
+	// nested bucket of pair and node resets for every mission control
+	// namespace. The stored results are kept on a reset, so the resets
+	// are needed to filter them when the state is rebuilt on startup. A
+	// reset is removed once it predates all stored results.
+	resetsKey = []byte("missioncontrol-resets")
+
 	// Big endian is the preferred byte order, due to cursor scans over
 	// integer keys iterating in order.
 	byteOrder = binary.BigEndian
@@ -43,6 +57,10 @@ type missionControlStore struct {
 	db      kvdb.Backend
 	queueMx sync.Mutex
 
+	// namespace is the mission control namespace the results of this
+	// store belong to.
+	namespace string
+
 	// queue stores all pending payment results not yet added to the store.
 	queue *list.List
 
@@ -63,8 +81,9 @@ type missionControlStore struct {
 	flushInterval time.Duration
 }
 
-func newMissionControlStore(db kvdb.Backend, maxRecords int,
-	flushInterval time.Duration) (*missionControlStore, error) {
+func newMissionControlStore(db kvdb.Backend, namespace string,
+	maxRecords int, flushInterval time.Duration) (*missionControlStore,
+	error) {
 
 	var (
 		keys    *list.List
@@ -73,7 +92,7 @@ func newMissionControlStore(db kvdb.Backend, maxRecords int,
 
 	// Create buckets if not yet existing.
 	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
-		resultsBucket, err := tx.CreateTopLevelBucket(resultsKey)
+		resultsBucket, err := createResultsBucket(tx, namespace)
 		if err != nil {
 			return fmt.Errorf("cannot create results bucket: %w",
 				err)
@@ -99,6 +118,7 @@ func newMissionControlStore(db kvdb.Backend, maxRecords int,
 	return &missionControlStore{
 		done:          make(chan struct{}),
 		db:            db,
+		namespace:     namespace,
 		queue:         list.New(),
 		keys:          keys,
 		keysMap:       keysMap,
@@ -107,17 +127,115 @@ func newMissionControlStore(db kvdb.Backend, maxRecords int,
 	}, nil
 }
 
+// createResultsBucket creates the results bucket of the given namespace if it
+// doesn't exist yet and returns it.
+func createResultsBucket(tx kvdb.RwTx,
+	namespace string) (kvdb.RwBucket, error) {
+
+	if namespace == DefaultMissionControlNamespace {
+		return tx.CreateTopLevelBucket(resultsKey)
+	}
+
+	namespaces, err := tx.CreateTopLevelBucket(namespacesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return namespaces.CreateBucketIfNotExists([]byte(namespace))
+}
+
+// readResultsBucket returns the results bucket of the given namespace or nil
+// if it doesn't exist.
+func readResultsBucket(tx kvdb.RTx, namespace string) kvdb.RBucket {
+	if namespace == DefaultMissionControlNamespace {
+		return tx.ReadBucket(resultsKey)
+	}
+
+	namespaces := tx.ReadBucket(namespacesKey)
+	if namespaces == nil {
+		return nil
+	}
+
+	return namespaces.NestedReadBucket([]byte(namespace))
+}
+
+// readWriteResultsBucket returns the results bucket of the given namespace or
+// nil if it doesn't exist.
+func readWriteResultsBucket(tx kvdb.RwTx, namespace string) kvdb.RwBucket {
+	if namespace == DefaultMissionControlNamespace {
+		return tx.ReadWriteBucket(resultsKey)
+	}
+
+	namespaces := tx.ReadWriteBucket(namespacesKey)
+	if namespaces == nil {
+		return nil
+	}
+
+	return namespaces.NestedReadWriteBucket([]byte(namespace))
+}
+
+// fetchNamespaces returns the names of all non-default mission control
+// namespaces that have been persisted to the db.
+func fetchNamespaces(db kvdb.Backend) ([]string, error) {
+	var namespaces []string
+
+	err := kvdb.View(db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(namespacesKey)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			// Only nested buckets have a nil value.
+			if v == nil {
+				namespaces = append(namespaces, string(k))
+			}
+
+			return nil
+		})
+	}, func() {
+		namespaces = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return namespaces, nil
+}
+
 // clear removes all results from the db.
 func (b *missionControlStore) clear() error {
 	b.queueMx.Lock()
 	defer b.queueMx.Unlock()
 
 	err := kvdb.Update(b.db, func(tx kvdb.RwTx) error {
-		if err := tx.DeleteTopLevelBucket(resultsKey); err != nil {
-			return err
+		if b.namespace == DefaultMissionControlNamespace {
+			err := tx.DeleteTopLevelBucket(resultsKey)
+			if err != nil {
+				return err
+			}
+		} else {
+			namespaces := tx.ReadWriteBucket(namespacesKey)
+			key := []byte(b.namespace)
+			err := namespaces.DeleteNestedBucket(key)
+			if err != nil {
+				return err
+			}
 		}
 
-		_, err := tx.CreateTopLevelBucket(resultsKey)
+		// The resets are obsolete once all results are removed.
+		resets := tx.ReadWriteBucket(resetsKey)
+		if resets != nil {
+			key := []byte(b.namespace)
+			if resets.NestedReadWriteBucket(key) != nil {
+				err := resets.DeleteNestedBucket(key)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err := createResultsBucket(tx, b.namespace)
 		return err
 	}, func() {})
 
@@ -129,12 +247,199 @@ func (b *missionControlStore) clear() error {
 	return nil
 }
 
+// pairResets holds the times at which the history of node pairs was reset,
+// either for single pairs or for all pairs of a node.
+type pairResets struct {
+	pairs map[DirectedNodePair]time.Time
+	nodes map[route.Vertex]time.Time
+}
+
+// newPairResets returns an empty set of pair resets.
+func newPairResets() *pairResets {
+	return &pairResets{
+		pairs: make(map[DirectedNodePair]time.Time),
+		nodes: make(map[route.Vertex]time.Time),
+	}
+}
+
+// isReset returns true if the history of the pair was reset at or after the
+// given time, so that a result with that timestamp must not be applied to it.
+func (p *pairResets) isReset(pair DirectedNodePair, timestamp time.Time) bool {
+	resetBefore := func(resetTime time.Time, ok bool) bool {
+		return ok && !timestamp.After(resetTime)
+	}
+
+	resetTime, ok := p.pairs[pair]
+	if resetBefore(resetTime, ok) {
+		return true
+	}
+
+	resetTime, ok = p.nodes[pair.From]
+	if resetBefore(resetTime, ok) {
+		return true
+	}
+
+	resetTime, ok = p.nodes[pair.To]
+
+	return resetBefore(resetTime, ok)
+}
+
+// add records a reset of the given pairs and nodes at the given time.
+func (p *pairResets) add(pairs []DirectedNodePair, nodes []route.Vertex,
+	resetTime time.Time) {
+
+	for _, pair := range pairs {
+		p.pairs[pair] = resetTime
+	}
+	for _, node := range nodes {
+		p.nodes[node] = resetTime
+	}
+}
+
+// addResets persists a reset of the given pairs and nodes at the given time.
+// A pair is stored as the concatenation of its nodes, a node by its key.
+func (b *missionControlStore) addResets(pairs []DirectedNodePair,
+	nodes []route.Vertex, resetTime time.Time) error {
+
+	var value [8]byte
+	byteOrder.PutUint64(value[:], uint64(resetTime.UnixNano()))
+
+	return kvdb.Update(b.db, func(tx kvdb.RwTx) error {
+		resets, err := tx.CreateTopLevelBucket(resetsKey)
+		if err != nil {
+			return err
+		}
+
+		bucket, err := resets.CreateBucketIfNotExists(
+			[]byte(b.namespace),
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, pair := range pairs {
+			var key [2 * route.VertexSize]byte
+			copy(key[:], pair.From[:])
+			copy(key[route.VertexSize:], pair.To[:])
+
+			if err := bucket.Put(key[:], value[:]); err != nil {
+				return err
+			}
+		}
+
+		for _, node := range nodes {
+			if err := bucket.Put(node[:], value[:]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
+
+// pruneResets deletes all resets of the namespace that happened before the
+// given time.
+func (b *missionControlStore) pruneResets(tx kvdb.RwTx,
+	before time.Time) error {
+
+	resetsBucket := tx.ReadWriteBucket(resetsKey)
+	if resetsBucket == nil {
+		return nil
+	}
+
+	bucket := resetsBucket.NestedReadWriteBucket([]byte(b.namespace))
+	if bucket == nil {
+		return nil
+	}
+
+	// Bolt doesn't allow the bucket to be modified while iterating over
+	// it, so we collect the keys first.
+	var obsolete [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		if len(v) != 8 {
+			return fmt.Errorf("invalid reset time")
+		}
+
+		if int64(byteOrder.Uint64(v)) < before.UnixNano() {
+			obsolete = append(obsolete, k)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range obsolete {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetchResets returns all pair and node resets of the namespace.
+func (b *missionControlStore) fetchResets() (*pairResets, error) {
+	var resets *pairResets
+
+	err := kvdb.View(b.db, func(tx kvdb.RTx) error {
+		resetsBucket := tx.ReadBucket(resetsKey)
+		if resetsBucket == nil {
+			return nil
+		}
+
+		bucket := resetsBucket.NestedReadBucket([]byte(b.namespace))
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			if len(v) != 8 {
+				return fmt.Errorf("invalid reset time")
+			}
+			resetTime := time.Unix(
+				0, int64(byteOrder.Uint64(v)),
+			).Local()
+
+			switch len(k) {
+			case route.VertexSize:
+				var node route.Vertex
+				copy(node[:], k)
+				resets.nodes[node] = resetTime
+
+			case 2 * route.VertexSize:
+				var pair DirectedNodePair
+				copy(pair.From[:], k[:route.VertexSize])
+				copy(pair.To[:], k[route.VertexSize:])
+				resets.pairs[pair] = resetTime
+
+			default:
+				return fmt.Errorf("invalid reset key %x", k)
+			}
+
+			return nil
+		})
+	}, func() {
+		resets = newPairResets()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resets, nil
+}
+
 // fetchAll returns all results currently stored in the database.
 func (b *missionControlStore) fetchAll() ([]*paymentResult, error) {
 	var results []*paymentResult
 
 	err := kvdb.View(b.db, func(tx kvdb.RTx) error {
-		resultBucket := tx.ReadBucket(resultsKey)
+		resultBucket := readResultsBucket(tx, b.namespace)
+		if resultBucket == nil {
+			return fmt.Errorf("results bucket of namespace %v not "+
+				"found", b.namespace)
+		}
 		results = make([]*paymentResult, 0)
 
 		return resultBucket.ForEach(func(k, v []byte) error {
@@ -313,7 +618,11 @@ func (b *missionControlStore) storeResults() error {
 	)
 
 	err := kvdb.Update(b.db, func(tx kvdb.RwTx) error {
-		bucket := tx.ReadWriteBucket(resultsKey)
+		bucket := readWriteResultsBucket(tx, b.namespace)
+		if bucket == nil {
+			return fmt.Errorf("results bucket of namespace %v not "+
+				"found", b.namespace)
+		}
 
 		for e := l.Front(); e != nil; e = e.Next() {
 			pr := e.Value.(*paymentResult)
@@ -340,6 +649,7 @@ func (b *missionControlStore) storeResults() error {
 		}
 
 		// Prune oldest entries.
+		var pruned bool
 		for {
 			if b.maxRecords == 0 || keys.Len() <= b.maxRecords {
 				break
@@ -354,9 +664,20 @@ func (b *missionControlStore) storeResults() error {
 
 			keys.Remove(front)
 			delete(keysMap, key)
+			pruned = true
 		}
 
-		return nil
+		// Resets only filter the results that were received before
+		// them, so the resets that predate the oldest remaining result
+		// aren't needed anymore.
+		if !pruned || keys.Len() == 0 {
+			return nil
+		}
+
+		oldestKey := []byte(keys.Front().Value.(string))
+		oldest := time.Unix(0, int64(byteOrder.Uint64(oldestKey)))
+
+		return b.pruneResets(tx, oldest)
 	}, func() {
 		keys = list.New()
 		keys.PushBackList(b.keys)
@@ -44,7 +44,9 @@ func TestMissionControlStore(t *testing.T) {
 		require.NoError(t, db.Close())
 	})
 
-	store, err := newMissionControlStore(db, testMaxRecords, time.Second)
+	store, err := newMissionControlStore(
+		db, DefaultMissionControlNamespace, testMaxRecords, time.Second,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -114,7 +116,9 @@ func TestMissionControlStore(t *testing.T) {
 	}
 
 	// Recreate store to test pruning.
-	store, err = newMissionControlStore(db, testMaxRecords, time.Second)
+	store, err = newMissionControlStore(
+		db, DefaultMissionControlNamespace, testMaxRecords, time.Second,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -148,3 +152,68 @@ func TestMissionControlStore(t *testing.T) {
 			spew.Sdump(results[1]))
 	}
 }
+
+// TestMissionControlStoreResetPruning tests that the resets which predate all
+// stored results are removed when the results are pruned.
+func TestMissionControlStoreResetPruning(t *testing.T) {
+	dbPath := t.TempDir() + "/mc.db"
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, dbPath, true, kvdb.DefaultDBTimeout,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	store, err := newMissionControlStore(
+		db, DefaultMissionControlNamespace, testMaxRecords, time.Second,
+	)
+	require.NoError(t, err)
+
+	var (
+		node = route.Vertex{2}
+		pair = NewDirectedNodePair(route.Vertex{1}, route.Vertex{2})
+	)
+	require.NoError(t, store.addResets(
+		nil, []route.Vertex{node}, testTime.Add(-time.Hour),
+	))
+	require.NoError(t, store.addResets(
+		[]DirectedNodePair{pair}, nil, testTime.Add(90*time.Minute),
+	))
+
+	newResult := func(id uint64, age time.Duration) *paymentResult {
+		return &paymentResult{
+			route: &route.Route{
+				SourcePubKey: route.Vertex{1},
+				Hops: []*route.Hop{{
+					PubKeyBytes:   node,
+					LegacyPayload: true,
+				}},
+			},
+			success:   true,
+			id:        id,
+			timeReply: testTime.Add(age),
+			timeFwd:   testTime.Add(age),
+		}
+	}
+
+	// As long as no result is pruned, all resets are kept.
+	store.AddResult(newResult(1, 0))
+	store.AddResult(newResult(2, time.Hour))
+	require.NoError(t, store.storeResults())
+
+	resets, err := store.fetchResets()
+	require.NoError(t, err)
+	require.Len(t, resets.nodes, 1)
+	require.Len(t, resets.pairs, 1)
+
+	// Pruning the oldest result leaves the result after one hour as the
+	// oldest one, so the node reset before it isn't needed anymore.
+	store.AddResult(newResult(3, 2*time.Hour))
+	require.NoError(t, store.storeResults())
+
+	resets, err = store.fetchResets()
+	require.NoError(t, err)
+	require.Empty(t, resets.nodes)
+	require.Contains(t, resets.pairs, pair)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/routing/route"
@@ -138,7 +139,15 @@ func (ctx *mcTestContext) reportFailure(amt lnwire.MilliSatoshi,
 
 // reportSuccess reports a success by using a test route.
 func (ctx *mcTestContext) reportSuccess() {
-	err := ctx.mc.ReportPaymentSuccess(ctx.pid, mcTestRoute)
+	ctx.reportSuccessAfter(0)
+}
+
+// reportSuccessAfter reports a success by using a test route that took the
+// given duration to resolve.
+func (ctx *mcTestContext) reportSuccessAfter(latency time.Duration) {
+	err := ctx.mc.ReportPaymentSuccess(
+		ctx.pid, mcTestRoute, ctx.now.Add(-latency),
+	)
 	if err != nil {
 		ctx.t.Fatal(err)
 	}
@@ -230,3 +239,277 @@ func TestMissionControlChannelUpdate(t *testing.T) {
 	)
 	ctx.expectP(100, 0)
 }
+
+// TestMissionControlEstimatorOverride tests that a probability estimator that
+// is passed in explicitly is used instead of the configured one, while
+// operating on the same history.
+func TestMissionControlEstimatorOverride(t *testing.T) {
+	ctx := createMcTestContext(t)
+
+	aCfg := AprioriConfig{
+		PenaltyHalfLife:       testPenaltyHalfLife,
+		AprioriHopProbability: 0.4,
+		AprioriWeight:         testAprioriWeight,
+		CapacityFraction:      testCapacityFraction,
+	}
+	estimator, err := NewAprioriEstimator(aCfg)
+	require.NoError(t, err)
+
+	getP := func() float64 {
+		return ctx.mc.GetProbabilityWithEstimator(
+			estimator, mcTestNode1, mcTestNode2, 1000,
+			testCapacity,
+		)
+	}
+
+	// Without any history, the a priori probability of the override is
+	// expected, while the configured estimator remains unchanged.
+	require.InDelta(t, 0.4, getP(), 0.001)
+	ctx.expectP(1000, testAprioriHopProbability)
+
+	// The history is shared between both estimators.
+	ctx.reportFailure(1000, lnwire.NewTemporaryChannelFailure(nil))
+	require.Zero(t, getP())
+	ctx.expectP(1000, 0)
+}
+
+// TestMissionControlResetPairs tests that the history of single pairs can be
+// reset while keeping the history of other pairs, also across restarts.
+func TestMissionControlResetPairs(t *testing.T) {
+	ctx := createMcTestContext(t)
+
+	// The failure penalizes the pair from node 1 to node 2 and records a
+	// success for the pair from us to node 1.
+	ctx.reportFailure(1000, lnwire.NewTemporaryChannelFailure(nil))
+	ctx.expectP(1000, 0)
+	require.Len(t, ctx.mc.GetHistorySnapshot().Pairs, 2)
+
+	// Resetting an unrelated node doesn't change anything.
+	numReset, err := ctx.mc.ResetPairs(nil, []route.Vertex{{99}})
+	require.NoError(t, err)
+	require.Zero(t, numReset)
+	ctx.expectP(1000, 0)
+
+	// Reset the failed pair only.
+	numReset, err = ctx.mc.ResetPairs(
+		[]DirectedNodePair{
+			NewDirectedNodePair(mcTestNode1, mcTestNode2),
+		}, nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, numReset)
+	ctx.expectP(1000, testAprioriHopProbability)
+
+	history := ctx.mc.GetHistorySnapshot()
+	require.Len(t, history.Pairs, 1)
+	require.Equal(t, mcTestSelf, history.Pairs[0].Pair.From)
+	require.Equal(t, mcTestNode1, history.Pairs[0].Pair.To)
+
+	// The reset must survive a restart, while the history of the other
+	// pair of the same payment result is kept.
+	ctx.restartMc()
+	ctx.expectP(1000, testAprioriHopProbability)
+	require.Equal(t, history, ctx.mc.GetHistorySnapshot())
+
+	// Results that are received after the reset apply to the pair again,
+	// also after a restart.
+	ctx.now = ctx.now.Add(time.Minute)
+	ctx.reportFailure(1000, lnwire.NewTemporaryChannelFailure(nil))
+	ctx.restartMc()
+	require.Len(t, ctx.mc.GetHistorySnapshot().Pairs, 2)
+
+	// Resetting by node covers pairs in both directions.
+	numReset, err = ctx.mc.ResetPairs(nil, []route.Vertex{mcTestNode1})
+	require.NoError(t, err)
+	require.Equal(t, 2, numReset)
+	require.Empty(t, ctx.mc.GetHistorySnapshot().Pairs)
+
+	ctx.restartMc()
+	require.Empty(t, ctx.mc.GetHistorySnapshot().Pairs)
+}
+
+// TestMissionControlPairLatency tests that the resolution latency of successful
+// attempts is attributed to the pairs of the route.
+func TestMissionControlPairLatency(t *testing.T) {
+	ctx := createMcTestContext(t)
+
+	// Without any successes, no latency is known.
+	require.Zero(t, ctx.mc.GetPairLatency(mcTestNode1, mcTestNode2))
+
+	// The latency of the two hop route is split evenly across its pairs.
+	ctx.reportSuccessAfter(4 * time.Second)
+	latency := ctx.mc.GetPairLatency(mcTestSelf, mcTestNode1)
+	require.Equal(t, 2*time.Second, latency)
+
+	latency = ctx.mc.GetPairLatency(mcTestNode1, mcTestNode2)
+	require.Equal(t, 2*time.Second, latency)
+
+	// A subsequent sample moves the average towards it.
+	ctx.pid++
+	ctx.reportSuccessAfter(14 * time.Second)
+	expected := 2*time.Second + time.Duration(
+		latencySmoothing*float64(5*time.Second),
+	)
+	require.Equal(
+		t, expected, ctx.mc.GetPairLatency(mcTestNode1, mcTestNode2),
+	)
+
+	// Excessive latencies are ignored.
+	ctx.pid++
+	ctx.reportSuccessAfter(2 * maxLatencySample)
+	require.Equal(
+		t, expected, ctx.mc.GetPairLatency(mcTestNode1, mcTestNode2),
+	)
+
+	// The latencies are reconstructed from the stored results.
+	ctx.restartMc()
+	require.Equal(
+		t, expected, ctx.mc.GetPairLatency(mcTestNode1, mcTestNode2),
+	)
+
+	// Resetting the pair clears its latency.
+	_, err := ctx.mc.ResetPairs(nil, []route.Vertex{mcTestNode2})
+	require.NoError(t, err)
+	require.Zero(t, ctx.mc.GetPairLatency(mcTestNode1, mcTestNode2))
+	require.Equal(
+		t, expected, ctx.mc.GetPairLatency(mcTestSelf, mcTestNode1),
+	)
+}
+
+// TestMissionControlBimodalPairOverrides tests that the bimodal parameters of
+// single pairs can be overridden.
+func TestMissionControlBimodalPairOverrides(t *testing.T) {
+	ctx := createMcTestContext(t)
+
+	estimator, err := NewBimodalEstimator(DefaultBimodalConfig())
+	require.NoError(t, err)
+
+	pair := NewDirectedNodePair(mcTestNode1, mcTestNode2)
+	override := BimodalPairOverride{
+		NodeWeight: 0.5,
+		ScaleMsat:  1000,
+	}
+	overridden, err := NewBimodalEstimator(BimodalConfig{
+		BimodalNodeWeight: override.NodeWeight,
+		BimodalScaleMsat:  override.ScaleMsat,
+		BimodalDecayTime:  DefaultBimodalDecayTime,
+	})
+	require.NoError(t, err)
+
+	const amt = lnwire.MilliSatoshi(20_000_000)
+	probability := func(from, to route.Vertex) float64 {
+		return ctx.mc.GetProbabilityWithEstimator(
+			estimator, from, to, amt, testCapacity,
+		)
+	}
+	defaultProb := probability(mcTestNode1, mcTestNode2)
+
+	// Invalid parameters are rejected.
+	err = ctx.mc.SetBimodalPairOverrides(BimodalPairOverrides{
+		pair: {NodeWeight: 2, ScaleMsat: 1000},
+	}, nil)
+	require.ErrorIs(t, err, ErrInvalidNodeWeight)
+
+	err = ctx.mc.SetBimodalPairOverrides(BimodalPairOverrides{
+		pair: {NodeWeight: 0.5},
+	}, nil)
+	require.ErrorIs(t, err, ErrInvalidScale)
+
+	// The override only applies to its pair.
+	err = ctx.mc.SetBimodalPairOverrides(BimodalPairOverrides{
+		pair: override,
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, BimodalPairOverrides{pair: override},
+		ctx.mc.GetBimodalPairOverrides())
+
+	expectedProb := overridden.PairProbability(
+		mcTestTime, nil, mcTestNode2, amt, testCapacity,
+	)
+	require.NotEqual(t, defaultProb, expectedProb)
+	require.Equal(t, expectedProb, probability(mcTestNode1, mcTestNode2))
+	require.Equal(t, defaultProb, probability(mcTestNode2, mcTestNode1))
+
+	// Removing the override restores the global parameters.
+	err = ctx.mc.SetBimodalPairOverrides(nil, []DirectedNodePair{pair})
+	require.NoError(t, err)
+	require.Empty(t, ctx.mc.GetBimodalPairOverrides())
+	require.Equal(t, defaultProb, probability(mcTestNode1, mcTestNode2))
+}
+
+// TestMissionControlRouteProbability tests the success probability estimate
+// of a full route.
+func TestMissionControlRouteProbability(t *testing.T) {
+	ctx := createMcTestContext(t)
+
+	capacities := []btcutil.Amount{testCapacity, testCapacity}
+
+	_, _, err := ctx.mc.GetRouteProbability(mcTestRoute, nil)
+	require.Error(t, err)
+
+	// The first hop is a local channel without history, the second one is
+	// estimated with the a priori probability.
+	prob, hopProbs, err := ctx.mc.GetRouteProbability(
+		mcTestRoute, capacities,
+	)
+	require.NoError(t, err)
+	require.Len(t, hopProbs, 2)
+	require.InDelta(t, prevSuccessProbability, hopProbs[0], 0.001)
+	require.InDelta(t, testAprioriHopProbability, hopProbs[1], 0.001)
+	require.InDelta(t, hopProbs[0]*hopProbs[1], prob, 1e-9)
+
+	// After a failure of the second pair, the route can't succeed.
+	ctx.reportFailure(1000, lnwire.NewTemporaryChannelFailure(nil))
+	prob, hopProbs, err = ctx.mc.GetRouteProbability(
+		mcTestRoute, capacities,
+	)
+	require.NoError(t, err)
+	require.Zero(t, hopProbs[1])
+	require.Zero(t, prob)
+}
+
+// TestMissionControlRouteProbabilityConditioning tests that a node pair that
+// is used twice within a route is conditioned on the amount of its first use.
+func TestMissionControlRouteProbabilityConditioning(t *testing.T) {
+	ctx := createMcTestContext(t)
+
+	// Use a bimodal estimator, so that the probability depends on the
+	// amount.
+	estimator, err := NewBimodalEstimator(BimodalConfig{
+		BimodalNodeWeight: 0.2,
+		BimodalScaleMsat:  10_000_000,
+		BimodalDecayTime:  time.Hour,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ctx.mc.SetConfig(&MissionControlConfig{
+		Estimator: estimator,
+	}))
+
+	// Route that crosses the pair from node 1 to node 2 twice.
+	rt := &route.Route{
+		SourcePubKey: mcTestSelf,
+		TotalAmount:  30_000_000,
+		Hops: []*route.Hop{
+			{PubKeyBytes: mcTestNode1, AmtToForward: 20_000_000},
+			{PubKeyBytes: mcTestNode2, AmtToForward: 20_000_000},
+			{PubKeyBytes: mcTestNode1, AmtToForward: 20_000_000},
+			{PubKeyBytes: mcTestNode2, AmtToForward: 20_000_000},
+		},
+	}
+	capacities := make([]btcutil.Amount, len(rt.Hops))
+	for i := range capacities {
+		capacities[i] = testCapacity
+	}
+
+	_, hopProbs, err := ctx.mc.GetRouteProbability(rt, capacities)
+	require.NoError(t, err)
+
+	// The second use of the pair needs the liquidity for both amounts, so
+	// the hop probabilities of both uses multiply up to the probability
+	// of the combined amount.
+	pCombined := ctx.mc.GetProbability(
+		mcTestNode1, mcTestNode2, 40_000_000, testCapacity,
+	)
+	require.InDelta(t, pCombined, hopProbs[1]*hopProbs[3], 1e-9)
+	require.Less(t, pCombined, hopProbs[1])
+}
@@ -3,6 +3,7 @@ package routing
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
@@ -137,17 +138,30 @@ func (m *mockMissionControlOld) ReportPaymentFail(
 }
 
 func (m *mockMissionControlOld) ReportPaymentSuccess(paymentID uint64,
-	rt *route.Route) error {
+	rt *route.Route, attemptTime time.Time) error {
 
 	return nil
 }
 
+func (m *mockMissionControlOld) GetPairLatency(fromNode,
+	toNode route.Vertex) time.Duration {
+
+	return 0
+}
+
 func (m *mockMissionControlOld) GetProbability(fromNode, toNode route.Vertex,
 	amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64 {
 
 	return 0
 }
 
+func (m *mockMissionControlOld) GetProbabilityWithEstimator(
+	estimator Estimator, fromNode, toNode route.Vertex,
+	amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64 {
+
+	return 0
+}
+
 type mockPaymentSessionOld struct {
 	routes []*route.Route
 
@@ -188,6 +202,8 @@ func (m *mockPaymentSessionOld) GetAdditionalEdgePolicy(_ *btcec.PublicKey,
 	return nil
 }
 
+func (m *mockPaymentSessionOld) UpdateCltvLimit(_ uint32) {}
+
 type mockPayerOld struct {
 	sendResult    chan error
 	paymentResult chan *htlcswitch.PaymentResult
@@ -493,6 +509,16 @@ func (m *mockControlTowerOld) FailPayment(phash lntypes.Hash,
 	return nil
 }
 
+func (m *mockControlTowerOld) CancelPayment(phash lntypes.Hash) error {
+	return m.FailPayment(phash, channeldb.FailureReasonCanceled)
+}
+
+func (m *mockControlTowerOld) FailIdlePayment(phash lntypes.Hash,
+	reason channeldb.FailureReason) error {
+
+	return m.FailPayment(phash, reason)
+}
+
 func (m *mockControlTowerOld) FetchPayment(phash lntypes.Hash) (
 	dbMPPayment, error) {
 
@@ -653,12 +679,19 @@ func (m *mockMissionControl) ReportPaymentFail(
 }
 
 func (m *mockMissionControl) ReportPaymentSuccess(paymentID uint64,
-	rt *route.Route) error {
+	rt *route.Route, attemptTime time.Time) error {
 
-	args := m.Called(paymentID, rt)
+	args := m.Called(paymentID, rt, attemptTime)
 	return args.Error(0)
 }
 
+func (m *mockMissionControl) GetPairLatency(fromNode,
+	toNode route.Vertex) time.Duration {
+
+	args := m.Called(fromNode, toNode)
+	return args.Get(0).(time.Duration)
+}
+
 func (m *mockMissionControl) GetProbability(fromNode, toNode route.Vertex,
 	amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64 {
 
@@ -666,6 +699,14 @@ func (m *mockMissionControl) GetProbability(fromNode, toNode route.Vertex,
 	return args.Get(0).(float64)
 }
 
+func (m *mockMissionControl) GetProbabilityWithEstimator(
+	estimator Estimator, fromNode, toNode route.Vertex,
+	amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64 {
+
+	args := m.Called(estimator, fromNode, toNode, amt, capacity)
+	return args.Get(0).(float64)
+}
+
 type mockPaymentSession struct {
 	mock.Mock
 }
@@ -699,6 +740,10 @@ func (m *mockPaymentSession) GetAdditionalEdgePolicy(pubKey *btcec.PublicKey,
 	return args.Get(0).(*models.CachedEdgePolicy)
 }
 
+func (m *mockPaymentSession) UpdateCltvLimit(cltvLimit uint32) {
+	m.Called(cltvLimit)
+}
+
 type mockControlTower struct {
 	mock.Mock
 }
@@ -758,6 +803,18 @@ func (m *mockControlTower) FailPayment(phash lntypes.Hash,
 	return args.Error(0)
 }
 
+func (m *mockControlTower) CancelPayment(phash lntypes.Hash) error {
+	args := m.Called(phash)
+	return args.Error(0)
+}
+
+func (m *mockControlTower) FailIdlePayment(phash lntypes.Hash,
+	reason channeldb.FailureReason) error {
+
+	args := m.Called(phash, reason)
+	return args.Error(0)
+}
+
 func (m *mockControlTower) FetchPayment(phash lntypes.Hash) (
 	dbMPPayment, error) {
 
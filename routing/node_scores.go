@@ -0,0 +1,344 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+var (
+	// ErrNoScoreSource is returned when node scores are imported without
+	// naming the source that they originate from.
+	ErrNoScoreSource = errors.New("node score source required")
+
+	// nodeScoresKey is the fixed key of the top-level bucket that holds
+	// the imported node scores. The scores are keyed by the node followed
+	// by their source.
+	nodeScoresKey = []byte("node-scores")
+)
+
+// nodeScoreValueLen is the length of a serialized node score, consisting of
+// the score, the import time and the expiry.
+const nodeScoreValueLen = 24
+
+// NodeScore is a score of a node that was imported from an external source,
+// for example a scoring oracle.
+type NodeScore struct {
+	// Node is the node that the score applies to.
+	Node route.Vertex
+
+	// Score is the score of the node in the [0,1] closed interval. It is
+	// used as a multiplier of the success probability of the channels
+	// that the node forwards over.
+	Score float64
+
+	// Source identifies the external source that the score originates
+	// from.
+	Source string
+
+	// ImportTime is the time at which the score was imported.
+	ImportTime time.Time
+
+	// Expiry is the time after which the score is no longer used. A zero
+	// expiry means that the score doesn't expire.
+	Expiry time.Time
+}
+
+// expired returns true if the score expired at the given time.
+func (s *NodeScore) expired(now time.Time) bool {
+	return !s.Expiry.IsZero() && !now.Before(s.Expiry)
+}
+
+// NodeScoreStore holds the node scores that were imported from external
+// sources. Scores from different sources are kept side by side. If several
+// sources score the same node, the lowest score is used. The scores are
+// persisted, so that they survive a restart until they expire.
+type NodeScoreStore struct {
+	db kvdb.Backend
+
+	// scores holds the scores of each node indexed by their source.
+	scores map[route.Vertex]map[string]*NodeScore
+
+	// now is expected to return the current time. It is supplied as an
+	// external function to enable deterministic unit tests.
+	now func() time.Time
+
+	mu sync.RWMutex
+}
+
+// NewNodeScoreStore returns a node score store that holds the scores that
+// are persisted in the given database. Expired scores are removed from the
+// database.
+func NewNodeScoreStore(db kvdb.Backend) (*NodeScoreStore, error) {
+	return newNodeScoreStore(db, time.Now)
+}
+
+// newNodeScoreStore returns a node score store that uses the given function
+// to determine the current time.
+func newNodeScoreStore(db kvdb.Backend,
+	now func() time.Time) (*NodeScoreStore, error) {
+
+	var scores map[route.Vertex]map[string]*NodeScore
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(nodeScoresKey)
+		if err != nil {
+			return err
+		}
+
+		var expired [][]byte
+		err = bucket.ForEach(func(k, v []byte) error {
+			score, err := deserializeNodeScore(k, v)
+			if err != nil {
+				return err
+			}
+
+			if score.expired(now()) {
+				expired = append(expired, k)
+				return nil
+			}
+
+			sources, ok := scores[score.Node]
+			if !ok {
+				sources = make(map[string]*NodeScore)
+				scores[score.Node] = sources
+			}
+			sources[score.Source] = score
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {
+		scores = make(map[route.Vertex]map[string]*NodeScore)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &NodeScoreStore{
+		db:     db,
+		scores: scores,
+		now:    now,
+	}, nil
+}
+
+// Import adds the given scores of a source to the store. Scores of the same
+// source for the same node are overwritten. If replace is set, all scores
+// that were previously imported from the source are removed first, so that
+// importing an empty set of scores clears a source. A zero ttl means that the
+// scores don't expire.
+func (s *NodeScoreStore) Import(source string, scores map[route.Vertex]float64,
+	ttl time.Duration, replace bool) error {
+
+	if source == "" {
+		return ErrNoScoreSource
+	}
+
+	for node, score := range scores {
+		if !(score >= 0 && score <= 1) {
+			return fmt.Errorf("score %v of node %v not in [0,1]",
+				score, node)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	var expiry time.Time
+	if ttl > 0 {
+		expiry = now.Add(ttl)
+	}
+
+	imported := make([]*NodeScore, 0, len(scores))
+	for node, score := range scores {
+		imported = append(imported, &NodeScore{
+			Node:       node,
+			Score:      score,
+			Source:     source,
+			ImportTime: now,
+			Expiry:     expiry,
+		})
+	}
+
+	// Persist the scores before the in-memory state is changed, so that
+	// both stay consistent if the update fails.
+	err := kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(nodeScoresKey)
+
+		if replace {
+			for node, sources := range s.scores {
+				if _, ok := sources[source]; !ok {
+					continue
+				}
+
+				err := bucket.Delete(nodeScoreKey(node, source))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, score := range imported {
+			err := bucket.Put(
+				nodeScoreKey(score.Node, source),
+				serializeNodeScore(score),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+	if err != nil {
+		return err
+	}
+
+	if replace {
+		for node, sources := range s.scores {
+			delete(sources, source)
+			if len(sources) == 0 {
+				delete(s.scores, node)
+			}
+		}
+	}
+
+	for _, score := range imported {
+		sources, ok := s.scores[score.Node]
+		if !ok {
+			sources = make(map[string]*NodeScore)
+			s.scores[score.Node] = sources
+		}
+
+		sources[source] = score
+	}
+
+	log.Infof("Imported %v node scores from source %v", len(scores),
+		source)
+
+	return nil
+}
+
+// Multiplier returns the factor that the success probability of channels
+// that the given node forwards over is multiplied with. It is the lowest
+// unexpired score of the node, or one if there is none.
+func (s *NodeScoreStore) Multiplier(node route.Vertex) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sources, ok := s.scores[node]
+	if !ok {
+		return 1
+	}
+
+	now := s.now()
+	multiplier := 1.0
+	for _, score := range sources {
+		if !score.expired(now) {
+			multiplier = min(multiplier, score.Score)
+		}
+	}
+
+	return multiplier
+}
+
+// Scores returns all scores that didn't expire yet, sorted by node and
+// source. Expired scores are removed from memory. They are removed from the
+// database on the next startup.
+func (s *NodeScoreStore) Scores() []NodeScore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	var scores []NodeScore
+	for node, sources := range s.scores {
+		for source, score := range sources {
+			if score.expired(now) {
+				delete(sources, source)
+				continue
+			}
+
+			scores = append(scores, *score)
+		}
+
+		if len(sources) == 0 {
+			delete(s.scores, node)
+		}
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Node != scores[j].Node {
+			return scores[i].Node.String() < scores[j].Node.String()
+		}
+
+		return scores[i].Source < scores[j].Source
+	})
+
+	return scores
+}
+
+// nodeScoreKey returns the database key of the score of a node from the given
+// source.
+func nodeScoreKey(node route.Vertex, source string) []byte {
+	key := make([]byte, 0, len(node)+len(source))
+	key = append(key, node[:]...)
+
+	return append(key, source...)
+}
+
+// serializeNodeScore returns the database value of a node score. It consists
+// of the score and the import and expiry times in unix nanoseconds. A zero
+// expiry is stored as zero.
+func serializeNodeScore(score *NodeScore) []byte {
+	var expiry int64
+	if !score.Expiry.IsZero() {
+		expiry = score.Expiry.UnixNano()
+	}
+
+	b := make([]byte, nodeScoreValueLen)
+	byteOrder.PutUint64(b[:8], math.Float64bits(score.Score))
+	byteOrder.PutUint64(b[8:16], uint64(score.ImportTime.UnixNano()))
+	byteOrder.PutUint64(b[16:], uint64(expiry))
+
+	return b
+}
+
+// deserializeNodeScore decodes a node score stored under the given key.
+func deserializeNodeScore(k, v []byte) (*NodeScore, error) {
+	if len(k) <= route.VertexSize || len(v) != nodeScoreValueLen {
+		return nil, fmt.Errorf("invalid node score")
+	}
+
+	score := &NodeScore{
+		Score:  math.Float64frombits(byteOrder.Uint64(v[:8])),
+		Source: string(k[route.VertexSize:]),
+		ImportTime: time.Unix(
+			0, int64(byteOrder.Uint64(v[8:16])),
+		),
+	}
+	copy(score.Node[:], k[:route.VertexSize])
+
+	if expiry := int64(byteOrder.Uint64(v[16:])); expiry != 0 {
+		score.Expiry = time.Unix(0, expiry)
+	}
+
+	return score, nil
+}
@@ -0,0 +1,104 @@
+package routing
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeScoreStore tests the import, expiry and combination of node scores
+// from several sources.
+func TestNodeScoreStore(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nodeA = route.Vertex{1}
+		nodeB = route.Vertex{2}
+		nodeC = route.Vertex{3}
+	)
+
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, filepath.Join(t.TempDir(), "scores.db"),
+		true, kvdb.DefaultDBTimeout,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	now := time.Unix(1000, 0)
+	clock := func() time.Time {
+		return now
+	}
+	store, err := newNodeScoreStore(db, clock)
+	require.NoError(t, err)
+
+	// Scores without a source or out of range are rejected.
+	err = store.Import("", map[route.Vertex]float64{nodeA: 0.5}, 0, false)
+	require.ErrorIs(t, err, ErrNoScoreSource)
+
+	err = store.Import(
+		"oracle", map[route.Vertex]float64{nodeA: 1.5}, 0, false,
+	)
+	require.Error(t, err)
+
+	// Nodes without a score aren't penalized.
+	require.Equal(t, 1.0, store.Multiplier(nodeA))
+
+	err = store.Import("oracle", map[route.Vertex]float64{
+		nodeA: 0.5,
+		nodeB: 0.8,
+	}, time.Hour, false)
+	require.NoError(t, err)
+
+	err = store.Import(
+		"other", map[route.Vertex]float64{nodeA: 0.2}, 0, false,
+	)
+	require.NoError(t, err)
+
+	// The lowest score of all sources is used.
+	require.Equal(t, 0.2, store.Multiplier(nodeA))
+	require.Equal(t, 0.8, store.Multiplier(nodeB))
+	require.Len(t, store.Scores(), 3)
+
+	// Replacing the scores of a source removes its previous scores, but
+	// leaves other sources untouched.
+	err = store.Import(
+		"other", map[route.Vertex]float64{nodeC: 0.1}, 0, true,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, store.Multiplier(nodeA))
+	require.Equal(t, 0.1, store.Multiplier(nodeC))
+
+	// The scores are restored from the database after a restart.
+	store, err = newNodeScoreStore(db, clock)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, store.Multiplier(nodeA))
+	require.Equal(t, 0.8, store.Multiplier(nodeB))
+	require.Equal(t, 0.1, store.Multiplier(nodeC))
+	require.Len(t, store.Scores(), 3)
+
+	// Once the scores of the oracle expire, they are no longer used nor
+	// listed.
+	now = now.Add(time.Hour)
+	require.Equal(t, 1.0, store.Multiplier(nodeA))
+	require.Equal(t, 1.0, store.Multiplier(nodeB))
+
+	scores := store.Scores()
+	expected := []NodeScore{{
+		Node:       nodeC,
+		Score:      0.1,
+		Source:     "other",
+		ImportTime: time.Unix(1000, 0),
+	}}
+	require.Equal(t, expected, scores)
+
+	// The expired scores are also gone after a restart.
+	store, err = newNodeScoreStore(db, clock)
+	require.NoError(t, err)
+	require.Equal(t, expected, store.Scores())
+}
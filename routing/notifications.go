@@ -14,6 +14,7 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
 )
 
 // TopologyClient represents an intent to receive notifications from the
@@ -191,8 +192,6 @@ func (t *TopologyChange) isEmpty() bool {
 // closed by monitoring the blockchain. Once a channel's funding point has been
 // spent, the channel will automatically be marked as closed by the
 // ChainNotifier.
-//
-// TODO(roasbeef): add nodes involved?
 type ClosedChanSummary struct {
 	// ChanID is the short-channel ID which uniquely identifies the
 	// channel.
@@ -208,6 +207,10 @@ type ClosedChanSummary struct {
 	// ChanPoint is the funding point, or the multi-sig utxo which
 	// previously represented the channel.
 	ChanPoint wire.OutPoint
+
+	// Node1 and Node2 are the nodes of the channel.
+	Node1 route.Vertex
+	Node2 route.Vertex
 }
 
 // createCloseSummaries takes in a slice of channels closed at the target block
@@ -222,12 +225,110 @@ func createCloseSummaries(blockHeight uint32,
 			Capacity:     closedChan.Capacity,
 			ClosedHeight: blockHeight,
 			ChanPoint:    closedChan.ChannelPoint,
+			Node1:        closedChan.NodeKey1Bytes,
+			Node2:        closedChan.NodeKey2Bytes,
 		}
 	}
 
 	return closeSummaries
 }
 
+// TopologyFilter selects the parts of a topology change that a client is
+// interested in. The zero value lets all updates pass.
+type TopologyFilter struct {
+	// Nodes restricts the updates to the given nodes, unless it is empty.
+	// Channel updates and closed channels pass if one of their nodes is
+	// in the set.
+	Nodes map[route.Vertex]struct{}
+
+	// PolicyUpdatesOnly drops node updates and closed channels.
+	PolicyUpdatesOnly bool
+
+	// MinCapacity drops channel updates and closed channels of channels
+	// with a smaller capacity.
+	MinCapacity btcutil.Amount
+}
+
+// hasNode returns true if the filter lets updates of the given node pass.
+func (f *TopologyFilter) hasNode(node route.Vertex) bool {
+	if len(f.Nodes) == 0 {
+		return true
+	}
+
+	_, ok := f.Nodes[node]
+
+	return ok
+}
+
+// hasKey returns true if the filter lets updates of the node with the given
+// public key pass.
+func (f *TopologyFilter) hasKey(key *btcec.PublicKey) bool {
+	if len(f.Nodes) == 0 {
+		return true
+	}
+
+	return key != nil && f.hasNode(route.NewVertex(key))
+}
+
+// Apply returns the parts of the topology change that pass the filter, or nil
+// if none does. The given change is left untouched, because it is shared
+// between all topology clients.
+func (f *TopologyFilter) Apply(change *TopologyChange) *TopologyChange {
+	filtered := &TopologyChange{}
+
+	if !f.PolicyUpdatesOnly {
+		for _, update := range change.NodeUpdates {
+			if !f.hasKey(update.IdentityKey) {
+				continue
+			}
+
+			filtered.NodeUpdates = append(
+				filtered.NodeUpdates, update,
+			)
+		}
+	}
+
+	for _, update := range change.ChannelEdgeUpdates {
+		if update.Capacity < f.MinCapacity {
+			continue
+		}
+
+		if !f.hasKey(update.AdvertisingNode) &&
+			!f.hasKey(update.ConnectingNode) {
+
+			continue
+		}
+
+		filtered.ChannelEdgeUpdates = append(
+			filtered.ChannelEdgeUpdates, update,
+		)
+	}
+
+	if !f.PolicyUpdatesOnly {
+		for _, closed := range change.ClosedChannels {
+			if closed.Capacity < f.MinCapacity {
+				continue
+			}
+
+			if !f.hasNode(closed.Node1) &&
+				!f.hasNode(closed.Node2) {
+
+				continue
+			}
+
+			filtered.ClosedChannels = append(
+				filtered.ClosedChannels, closed,
+			)
+		}
+	}
+
+	if filtered.isEmpty() {
+		return nil
+	}
+
+	return filtered
+}
+
 // NetworkNodeUpdate is an update for a  node within the Lightning Network. A
 // NetworkNodeUpdate is sent out either when a new node joins the network, or a
 // node broadcasts a new update with a newer time stamp that supersedes its
@@ -999,3 +999,81 @@ func TestEncodeHexColor(t *testing.T) {
 		}
 	}
 }
+
+// TestTopologyFilter tests that topology changes are filtered as requested.
+func TestTopologyFilter(t *testing.T) {
+	t.Parallel()
+
+	priv1, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	priv2, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	priv3, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	key1, key2, key3 := priv1.PubKey(), priv2.PubKey(), priv3.PubKey()
+
+	change := &TopologyChange{
+		NodeUpdates: []*NetworkNodeUpdate{
+			{IdentityKey: key1},
+			{IdentityKey: key3},
+		},
+		ChannelEdgeUpdates: []*ChannelEdgeUpdate{
+			{
+				ChanID:          1,
+				Capacity:        1000,
+				AdvertisingNode: key1,
+				ConnectingNode:  key2,
+			},
+			{
+				ChanID:          2,
+				Capacity:        5000,
+				AdvertisingNode: key2,
+				ConnectingNode:  key3,
+			},
+		},
+		ClosedChannels: []*ClosedChanSummary{
+			{
+				ChanID:   3,
+				Capacity: 5000,
+				Node1:    route.NewVertex(key1),
+				Node2:    route.NewVertex(key2),
+			},
+		},
+	}
+
+	// The zero filter lets everything pass.
+	require.Equal(t, change, (&TopologyFilter{}).Apply(change))
+
+	// Filter by node.
+	filtered := (&TopologyFilter{
+		Nodes: map[route.Vertex]struct{}{
+			route.NewVertex(key1): {},
+		},
+	}).Apply(change)
+	require.Len(t, filtered.NodeUpdates, 1)
+	require.Equal(t, key1, filtered.NodeUpdates[0].IdentityKey)
+	require.Len(t, filtered.ChannelEdgeUpdates, 1)
+	require.EqualValues(t, 1, filtered.ChannelEdgeUpdates[0].ChanID)
+	require.Len(t, filtered.ClosedChannels, 1)
+
+	// Filter by capacity and update type.
+	filtered = (&TopologyFilter{
+		PolicyUpdatesOnly: true,
+		MinCapacity:       2000,
+	}).Apply(change)
+	require.Empty(t, filtered.NodeUpdates)
+	require.Empty(t, filtered.ClosedChannels)
+	require.Len(t, filtered.ChannelEdgeUpdates, 1)
+	require.EqualValues(t, 2, filtered.ChannelEdgeUpdates[0].ChanID)
+
+	// If nothing passes the filter, nil is returned.
+	filter := &TopologyFilter{MinCapacity: 10_000}
+	require.Nil(t, filter.Apply(&TopologyChange{
+		ChannelEdgeUpdates: change.ChannelEdgeUpdates,
+	}))
+
+	// The original change is left untouched.
+	require.Len(t, change.NodeUpdates, 2)
+	require.Len(t, change.ChannelEdgeUpdates, 2)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
@@ -22,8 +23,8 @@ const (
 	// infinity is used as a starting distance in our shortest path search.
 	infinity = math.MaxInt64
 
-	// RiskFactorBillionths controls the influence of time lock delta
-	// of a channel on route selection. It is expressed as billionths
+	// RiskFactorBillionths is the default influence of the time lock
+	// delta of a channel on route selection. It is expressed as billionths
 	// of msat per msat sent through the channel per time lock delta
 	// block. See edgeWeight function below for more details.
 	// The chosen value is based on the previous incorrect weight function
@@ -31,8 +32,8 @@ const (
 	// diminishes the time lock penalty for all but the smallest amounts.
 	// To not change the behaviour of path finding too drastically, a
 	// relatively small value is chosen which is still big enough to give
-	// some effect with smaller time lock values. The value may need
-	// tweaking and/or be made configurable in the future.
+	// some effect with smaller time lock values. It can be tuned through
+	// the CltvRiskFactor of the path finding config.
 	RiskFactorBillionths = 15
 
 	// estimatedNodeCount is used to preallocate the path finding structures
@@ -347,16 +348,16 @@ func newRoute(sourceVertex route.Vertex,
 // for the shortest path within the channel graph between two nodes. Weight is
 // is the fee itself plus a time lock penalty added to it. This benefits
 // channels with shorter time lock deltas and shorter (hops) routes in general.
-// RiskFactor controls the influence of time lock on route selection. This is
-// currently a fixed value, but might be configurable in the future.
+// The risk factor controls the influence of time lock on route selection and
+// is expressed in billionths of msat per msat per time lock delta block.
 func edgeWeight(lockedAmt lnwire.MilliSatoshi, fee lnwire.MilliSatoshi,
-	timeLockDelta uint16) int64 {
+	timeLockDelta uint16, riskFactor int64) int64 {
 	// timeLockPenalty is the penalty for the time lock delta of this channel.
-	// It is controlled by RiskFactorBillionths and scales proportional
+	// It is controlled by the risk factor and scales proportional
 	// to the amount that will pass through channel. Rationale is that it if
 	// a twice as large amount gets locked up, it is twice as bad.
 	timeLockPenalty := int64(lockedAmt) * int64(timeLockDelta) *
-		RiskFactorBillionths / 1000000000
+		riskFactor / 1000000000
 
 	return int64(fee) + timeLockPenalty
 }
@@ -398,10 +399,66 @@ type RestrictParams struct {
 	// first hop. If nil, any channel may be used.
 	OutgoingChannelIDs []uint64
 
+	// OutgoingChannelWeights optionally assigns a weight in the range
+	// (0, 1] to outgoing channels. Path finding weighs a first hop channel
+	// as if its success probability was scaled by the weight, which biases
+	// the selection towards channels with a higher weight. The probability
+	// of the route itself isn't changed. Channels without a weight have a
+	// weight of one.
+	OutgoingChannelWeights map[uint64]float64
+
+	// LocalBalanceAware makes path finding favor first hop channels whose
+	// local balance leaves more room for the payment. A first hop is
+	// weighed as if its success probability was scaled by
+	// localBalanceFactor, so that channels that are almost depleted by the
+	// amount or by their pending HTLCs are only used if there is no better
+	// alternative.
+	LocalBalanceAware bool
+
+	// MinChannelCapacity prunes network channels with a capacity below
+	// this value from the search. Local channels and channels with an
+	// unknown capacity are kept. If zero, channels of any capacity may be
+	// used.
+	MinChannelCapacity btcutil.Amount
+
+	// LatencySource is a callback that is expected to return the average
+	// resolution latency of htlcs that traversed the node pair. It is only
+	// consulted if LatencyPenalty is non-zero.
+	LatencySource func(route.Vertex, route.Vertex) time.Duration
+
+	// LatencyPenalty is the cost per second of historical resolution
+	// latency that the payer assigns to each node pair of the path. It is
+	// added to the path weight like a fee, so that slow pairs are only
+	// used if faster alternatives are more expensive or less likely to
+	// succeed. If zero, latency is not taken into account.
+	LatencyPenalty lnwire.MilliSatoshi
+
 	// LastHop is the pubkey of the last node before the final destination
 	// is reached. If nil, any node may be used.
 	LastHop *route.Vertex
 
+	// LastHops is a set of acceptable last nodes before the final
+	// destination is reached. If empty, any node may be used.
+	LastHops []route.Vertex
+
+	// IncomingChannelID is the channel through which the final destination
+	// must be reached. Together with an outgoing channel, it pins both
+	// ends of a circular route to self. If zero, any channel may be used.
+	IncomingChannelID uint64
+
+	// RoutePrefix is an ordered list of nodes that the route must traverse
+	// directly after the source node. Path finding completes the route
+	// from the last node of the prefix to the destination. The prefix
+	// must neither contain the source nor the destination.
+	RoutePrefix []route.Vertex
+
+	// MaxHops is the maximum number of hops of the route. Like the
+	// maximum routing info size, it is enforced during the search, so
+	// that cheaper paths with too many hops are skipped in favor of
+	// shorter ones. If zero, only the routing info size limits the
+	// number of hops.
+	MaxHops uint32
+
 	// CltvLimit is the maximum time lock of the route excluding the final
 	// ctlv. After path finding is complete, the caller needs to increase
 	// all cltv expiry heights with the required final cltv delta.
@@ -433,6 +490,15 @@ type RestrictParams struct {
 	// BlindedPayment is necessary to determine the hop size of the
 	// last/exit hop.
 	BlindedPayment *BlindedPayment
+
+	// Timeout is the maximum duration of the path search. If it is
+	// exceeded, the search is aborted with errPathFindingTimeout. A zero
+	// value means that the search isn't time limited.
+	Timeout time.Duration
+
+	// Stats optionally collects statistics of the search. The statistics
+	// of all searches with the same restrictions are added up.
+	Stats *PathFindingStats
 }
 
 // PathFindingConfig defines global parameters that control the trade-off in
@@ -452,6 +518,29 @@ type PathFindingConfig struct {
 	// MinProbability defines the minimum success probability of the
 	// returned route.
 	MinProbability float64
+
+	// CltvRiskFactor is the weight of the time lock of a route relative to
+	// its fees in path finding. It is expressed in billionths of msat per
+	// msat sent through a channel per time lock delta block. Higher values
+	// favor routes that lock up the payment for a shorter worst case
+	// duration over cheaper ones.
+	CltvRiskFactor int64
+}
+
+// localBalanceFactor returns the factor that the success probability of a
+// first hop channel is scaled with when it is weighed, if path finding is local
+// balance aware. The
+// bandwidth of a channel is its local balance minus the amounts of its pending
+// HTLCs and the reserve. The factor approaches one for channels whose
+// bandwidth is large compared to the amount and drops to one half for a
+// channel that the amount would deplete. A channel without any bandwidth gets
+// a factor of zero.
+func localBalanceFactor(amt, bandwidth lnwire.MilliSatoshi) float64 {
+	if bandwidth == 0 {
+		return 0
+	}
+
+	return float64(bandwidth) / (float64(bandwidth) + float64(amt))
 }
 
 // getOutgoingBalance returns the maximum available balance in any of the
@@ -529,6 +618,8 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		timeElapsed := time.Since(start)
 		log.Debugf("Pathfinding perf metrics: nodes=%v, edges=%v, "+
 			"time=%v", nodesVisited, edgesExpanded, timeElapsed)
+
+		r.Stats.addSearch(nodesVisited, edgesExpanded, timeElapsed)
 	}()
 
 	// If no destination features are provided, we will load what features
@@ -628,8 +719,9 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 	// traversal.
 	nodeHeap := newDistanceHeap(estimatedNodeCount)
 
-	// Holds the current best distance for a given node.
-	distance := make(map[route.Vertex]*nodeWithDist, estimatedNodeCount)
+	// Holds the current best distance for a given node. If the number of
+	// hops is limited, the best distance is kept per hop count.
+	distance := make(map[nodeLabel]*nodeWithDist, estimatedNodeCount)
 
 	additionalEdgesWithSrc := make(map[route.Vertex][]*edgePolicyWithSource)
 	for vertex, additionalEdges := range g.additionalEdges {
@@ -750,8 +842,33 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 				edge.capacity, edgeProbability)
 		}))
 
+		// Determine the preference of the payer for its outgoing
+		// channels. A lower preference is weighed like a lower success
+		// probability, but it doesn't change the actual probability of
+		// the route.
+		preference := 1.0
+		if fromVertex == self {
+			chanID := edge.policy.ChannelID
+			if w, ok := r.OutgoingChannelWeights[chanID]; ok {
+				preference *= w
+			}
+
+			if r.LocalBalanceAware {
+				bandwidth, ok := g.bandwidthHints.
+					availableChanBandwidth(
+						chanID, amountToSend,
+					)
+				if ok {
+					preference *= localBalanceFactor(
+						amountToSend, bandwidth,
+					)
+				}
+			}
+		}
+
 		// If the probability is zero, there is no point in trying.
-		if edgeProbability == 0 {
+		if edgeProbability == 0 || preference == 0 {
+			r.Stats.prune(PruneZeroProbability)
 			return
 		}
 
@@ -781,6 +898,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 
 		// Check that we are within our CLTV limit.
 		if uint64(incomingCltv) > absoluteCltvLimit {
+			r.Stats.prune(PruneCltvLimit)
 			return
 		}
 
@@ -797,6 +915,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		// node would be added to the path.
 		totalFee := int64(netAmountToReceive) - int64(amt)
 		if totalFee > 0 && lnwire.MilliSatoshi(totalFee) > r.FeeLimit {
+			r.Stats.prune(PruneFeeLimit)
 			return
 		}
 
@@ -809,6 +928,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		// abandon this direction. Adding further nodes can only lower
 		// the probability more.
 		if probability < cfg.MinProbability {
+			r.Stats.prune(PruneMinProbability)
 			return
 		}
 
@@ -826,7 +946,19 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		// weight composed of the fee that this node will charge and
 		// the amount that will be locked for timeLockDelta blocks in
 		// the HTLC that is handed out to fromVertex.
-		weight := edgeWeight(netAmountToReceive, fee, timeLockDelta)
+		weight := edgeWeight(
+			netAmountToReceive, fee, timeLockDelta,
+			cfg.CltvRiskFactor,
+		)
+
+		// Penalize pairs that were slow to resolve htlcs in the past
+		// if the payer puts a price on latency.
+		if r.LatencyPenalty > 0 && r.LatencySource != nil {
+			latency := r.LatencySource(fromVertex, toNodeDist.node)
+			weight += int64(
+				latency.Seconds() * float64(r.LatencyPenalty),
+			)
+		}
 
 		// Compute the tentative weight to this new channel/edge
 		// which is the weight from our toNode to the target node
@@ -838,18 +970,34 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		// is to prevent a highly negative fee from cancelling out the
 		// extra factor. We don't want an always-failing node to attract
 		// traffic using a highly negative fee and escape penalization.
+		// The preference for the outgoing channel is only applied
+		// here, so that it affects the selection but not the
+		// probability that is checked against the lower bound.
 		tempDist := getProbabilityBasedDist(
-			tempWeight, probability,
+			tempWeight, probability*preference,
 			absoluteAttemptCost,
 		)
 
+		// Skip paths that would exceed the maximum number of hops. The
+		// hops are only counted if they are limited.
+		var hops uint32
+		if r.MaxHops != 0 {
+			hops = toNodeDist.hops + 1
+			if hops > r.MaxHops {
+				r.Stats.prune(PruneMaxHops)
+				return
+			}
+		}
+		label := nodeLabel{node: fromVertex, hops: hops}
+
 		// If there is already a best route stored, compare this
 		// candidate route with the best route so far.
-		current, ok := distance[fromVertex]
+		current, ok := distance[label]
 		if ok {
 			// If this route is worse than what we already found,
 			// skip this route.
 			if tempDist > current.dist {
+				r.Stats.prune(PruneNotBetter)
 				return
 			}
 
@@ -860,6 +1008,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			// endless loop.
 			probNotBetter := probability <= current.probability
 			if tempDist == current.dist && probNotBetter {
+				r.Stats.prune(PruneNotBetter)
 				return
 			}
 		}
@@ -896,6 +1045,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		routingInfoSize := toNodeDist.routingInfoSize + payloadSize
 		// Skip paths that would exceed the maximum routing info size.
 		if routingInfoSize > sphinx.MaxPayloadSize {
+			r.Stats.prune(PrunePayloadSize)
 			return
 		}
 
@@ -913,8 +1063,10 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			probability:       probability,
 			nextHop:           edge,
 			routingInfoSize:   routingInfoSize,
+			hops:              hops,
 		}
-		distance[fromVertex] = withDist
+		distance[label] = withDist
+		r.Stats.relax()
 
 		// Either push withDist onto the heap if the node
 		// represented by fromVertex is not already on the heap OR adjust
@@ -966,6 +1118,36 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		return fromFeatures, nil
 	}
 
+	// Set up the lookup maps for the route prefix. Every prefix node can
+	// only be reached from its predecessor in the prefix, and all but the
+	// last prefix node can only forward to their successor.
+	var prefixPrev, prefixNext map[route.Vertex]route.Vertex
+	if len(r.RoutePrefix) > 0 {
+		prefixPrev = make(map[route.Vertex]route.Vertex)
+		prefixNext = make(map[route.Vertex]route.Vertex)
+
+		prev := source
+		for _, node := range r.RoutePrefix {
+			prefixPrev[node] = prev
+			prefixNext[prev] = node
+			prev = node
+		}
+	}
+
+	// inPrefix returns false if the channel from fromNode to toNode can't
+	// be part of a route with the required prefix.
+	inPrefix := func(fromNode, toNode route.Vertex) bool {
+		if prev, ok := prefixPrev[toNode]; ok && prev != fromNode {
+			return false
+		}
+
+		if next, ok := prefixNext[fromNode]; ok && next != toNode {
+			return false
+		}
+
+		return true
+	}
+
 	routeToSelf := source == target
 	for {
 		nodesVisited++
@@ -978,6 +1160,9 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		u := newNodeEdgeUnifier(
 			self, pivot, !isExitHop, outgoingChanMap,
 		)
+		u.outChanWeights = r.OutgoingChannelWeights
+		u.minCapacity = r.MinChannelCapacity
+		u.stats = r.Stats
 
 		err := u.addGraphPolicies(g.graph)
 		if err != nil {
@@ -1026,6 +1211,33 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			if r.LastHop != nil &&
 				pivot == target && fromNode != *r.LastHop {
 
+				r.Stats.prune(PruneHopRestriction)
+				continue
+			}
+
+			// Apply the last hop candidates if set.
+			if len(r.LastHops) > 0 && pivot == target &&
+				!slices.Contains(r.LastHops, fromNode) {
+
+				r.Stats.prune(PruneHopRestriction)
+				continue
+			}
+
+			// Apply the incoming channel restriction if set. This
+			// drops all other channels between the last hop and
+			// the destination.
+			if r.IncomingChannelID != 0 && pivot == target &&
+				!edgeUnifier.restrictToChannel(
+					r.IncomingChannelID,
+				) {
+
+				r.Stats.prune(PruneHopRestriction)
+				continue
+			}
+
+			// Apply the route prefix if set.
+			if !inPrefix(fromNode, pivot) {
+				r.Stats.prune(PruneHopRestriction)
 				continue
 			}
 
@@ -1035,6 +1247,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			)
 
 			if edge == nil {
+				r.Stats.prune(PruneNoUsableChannel)
 				continue
 			}
 
@@ -1046,6 +1259,7 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 
 			// If there are no valid features, skip this node.
 			if fromFeatures == nil {
+				r.Stats.prune(PruneNoFeatures)
 				continue
 			}
 
@@ -1058,6 +1272,15 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			break
 		}
 
+		// The search runs backwards from the target, so there is no
+		// usable route until it completed. If we ran out of time, the
+		// search is aborted.
+		if r.Timeout > 0 && time.Since(start) > r.Timeout {
+			log.Debugf("Pathfinding aborted after %v", r.Timeout)
+
+			return nil, 0, errPathFindingTimeout
+		}
+
 		// Fetch the node within the smallest distance from our source
 		// from the heap.
 		partialPath = heap.Pop(&nodeHeap).(*nodeWithDist)
@@ -1070,24 +1293,24 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		}
 	}
 
+	// The search stops as soon as the source is popped from the heap, so
+	// the last popped entry is the best one of the source. If the source
+	// wasn't reached, we didn't find a path.
+	sourceDist := partialPath
+	if sourceDist.node != source || sourceDist.nextHop == nil {
+		return nil, 0, errNoPathFound
+	}
+
 	// Use the distance map to unravel the forward path from source to
 	// target.
 	var pathEdges []*unifiedEdge
-	currentNode := source
+	currentNodeWithDist := sourceDist
 	for {
-		// Determine the next hop forward using the next map.
-		currentNodeWithDist, ok := distance[currentNode]
-		if !ok {
-			// If the node doesn't have a next hop it means we
-			// didn't find a path.
-			return nil, 0, errNoPathFound
-		}
-
 		// Add the next hop to the list of path edges.
 		pathEdges = append(pathEdges, currentNodeWithDist.nextHop)
 
 		// Advance current node.
-		currentNode = currentNodeWithDist.nextHop.policy.ToNodePubKey()
+		currentNode := currentNodeWithDist.nextHop.policy.ToNodePubKey()
 
 		// Check stop condition at the end of this loop. This prevents
 		// breaking out too soon for self-payments that have target set
@@ -1095,6 +1318,19 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		if currentNode == target {
 			break
 		}
+
+		// Determine the next hop forward using the distance map. The
+		// next entry has one hop less if hops are counted.
+		label := nodeLabel{node: currentNode}
+		if r.MaxHops != 0 {
+			label.hops = currentNodeWithDist.hops - 1
+		}
+
+		var ok bool
+		currentNodeWithDist, ok = distance[label]
+		if !ok {
+			return nil, 0, errNoPathFound
+		}
 	}
 
 	// For the final hop, we'll set the node features to those determined
@@ -1108,13 +1344,20 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 	// route construction does not care where the features are actually
 	// taken from. In the future we may wish to do route construction within
 	// findPath, and avoid using ChannelEdgePolicy altogether.
-	pathEdges[len(pathEdges)-1].policy.ToNodeFeatures = features
+	//
+	// The policy is copied first, because it may be shared with the graph
+	// cache and other path finding calls.
+	lastEdge := *pathEdges[len(pathEdges)-1]
+	lastPolicy := *lastEdge.policy
+	lastPolicy.ToNodeFeatures = features
+	lastEdge.policy = &lastPolicy
+	pathEdges[len(pathEdges)-1] = &lastEdge
 
 	log.Debugf("Found route: probability=%v, hops=%v, fee=%v",
-		distance[source].probability, len(pathEdges),
-		distance[source].netAmountReceived-amt)
+		sourceDist.probability, len(pathEdges),
+		sourceDist.netAmountReceived-amt)
 
-	return pathEdges, distance[source].probability, nil
+	return pathEdges, sourceDist.probability, nil
 }
 
 // getProbabilityBasedDist converts a weight into a distance that takes into
@@ -0,0 +1,173 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PruneReason is the reason why path finding discarded a candidate edge.
+type PruneReason uint8
+
+const (
+	// PruneNoUsableChannel means that none of the channels between two
+	// nodes can carry the amount.
+	PruneNoUsableChannel PruneReason = iota
+
+	// PruneNoFeatures means that the features of the node aren't known.
+	PruneNoFeatures
+
+	// PruneHopRestriction means that the edge violates the last hop or
+	// route prefix restrictions.
+	PruneHopRestriction
+
+	// PruneZeroProbability means that the success probability of the edge
+	// is zero.
+	PruneZeroProbability
+
+	// PruneCltvLimit means that the edge exceeds the time lock limit.
+	PruneCltvLimit
+
+	// PruneFeeLimit means that the edge exceeds the fee limit.
+	PruneFeeLimit
+
+	// PruneMinProbability means that the probability of the path drops
+	// below the minimum probability.
+	PruneMinProbability
+
+	// PruneNotBetter means that a better path to the node is already
+	// known.
+	PruneNotBetter
+
+	// PrunePayloadSize means that the onion payload would exceed its
+	// maximum size.
+	PrunePayloadSize
+
+	// PruneMaxHops means that the path would exceed the maximum number of
+	// hops.
+	PruneMaxHops
+
+	// PruneMinCapacity means that the capacity of the channel is below the
+	// requested minimum channel capacity.
+	PruneMinCapacity
+)
+
+// String returns a human-readable name of the prune reason.
+func (r PruneReason) String() string {
+	switch r {
+	case PruneNoUsableChannel:
+		return "no_usable_channel"
+
+	case PruneNoFeatures:
+		return "no_features"
+
+	case PruneHopRestriction:
+		return "hop_restriction"
+
+	case PruneZeroProbability:
+		return "zero_probability"
+
+	case PruneCltvLimit:
+		return "cltv_limit"
+
+	case PruneFeeLimit:
+		return "fee_limit"
+
+	case PruneMinProbability:
+		return "min_probability"
+
+	case PruneNotBetter:
+		return "not_better"
+
+	case PrunePayloadSize:
+		return "payload_size"
+
+	case PruneMaxHops:
+		return "max_hops"
+
+	case PruneMinCapacity:
+		return "min_capacity"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(r))
+	}
+}
+
+// PathFindingStats collects statistics of path finding searches that help to
+// diagnose slow searches and over-aggressive pruning. The statistics of
+// several searches are summed up.
+type PathFindingStats struct {
+	// NumSearches is the number of searches.
+	NumSearches int
+
+	// NodesVisited is the number of nodes that were popped from the heap.
+	NodesVisited int
+
+	// EdgesExpanded is the number of edges that were evaluated. Edges that
+	// are pruned because there is no usable channel, because of missing
+	// features or because of hop restrictions aren't evaluated.
+	EdgesExpanded int
+
+	// EdgesRelaxed is the number of edges that improved the distance to a
+	// node.
+	EdgesRelaxed int
+
+	// Pruned counts the discarded edges by the reason they were discarded
+	// for.
+	Pruned map[PruneReason]int
+
+	// Duration is the total duration of the searches.
+	Duration time.Duration
+}
+
+// prune records an edge that was discarded for the given reason. It is a
+// no-op for nil stats, so that path finding doesn't need to check whether
+// statistics are requested.
+func (s *PathFindingStats) prune(reason PruneReason) {
+	if s == nil {
+		return
+	}
+
+	if s.Pruned == nil {
+		s.Pruned = make(map[PruneReason]int)
+	}
+	s.Pruned[reason]++
+}
+
+// relax records an edge that improved the distance to a node.
+func (s *PathFindingStats) relax() {
+	if s == nil {
+		return
+	}
+
+	s.EdgesRelaxed++
+}
+
+// addSearch adds the totals of a finished search.
+func (s *PathFindingStats) addSearch(nodesVisited, edgesExpanded int,
+	duration time.Duration) {
+
+	if s == nil {
+		return
+	}
+
+	s.NumSearches++
+	s.NodesVisited += nodesVisited
+	s.EdgesExpanded += edgesExpanded
+	s.Duration += duration
+}
+
+// String returns a summary of the statistics.
+func (s *PathFindingStats) String() string {
+	reasons := make([]string, 0, len(s.Pruned))
+	for reason, count := range s.Pruned {
+		reasons = append(reasons, fmt.Sprintf("%v=%v", reason, count))
+	}
+	sort.Strings(reasons)
+
+	return fmt.Sprintf("searches=%v, nodes=%v, edges=%v, relaxed=%v, "+
+		"pruned=[%v], time=%v", s.NumSearches, s.NodesVisited,
+		s.EdgesExpanded, s.EdgesRelaxed, strings.Join(reasons, " "),
+		s.Duration)
+}
@@ -169,6 +169,7 @@ func makeTestGraph(t *testing.T, useCache bool) (*channeldb.ChannelGraph,
 	graph, err := channeldb.NewChannelGraph(
 		backend, opts.RejectCacheSize, opts.ChannelCacheSize,
 		opts.BatchCommitInterval, opts.PreAllocCacheNumNodes,
+		opts.GraphSnapshotSize,
 		useCache, false,
 	)
 	if err != nil {
@@ -808,9 +809,21 @@ func TestPathFinding(t *testing.T) {
 	}, {
 		name: "restrict last hop",
 		fn:   runRestrictLastHop,
+	}, {
+		name: "route prefix",
+		fn:   runRoutePrefix,
 	}, {
 		name: "CLTV limit",
 		fn:   runCltvLimit,
+	}, {
+		name: "CLTV risk factor",
+		fn:   runCltvRiskFactor,
+	}, {
+		name: "max hops",
+		fn:   runMaxHops,
+	}, {
+		name: "max hops long cheapest path",
+		fn:   runMaxHopsLongCheapestPath,
 	}, {
 		name: "probability routing",
 		fn:   runProbabilityRouting,
@@ -829,6 +842,24 @@ func TestPathFinding(t *testing.T) {
 	}, {
 		name: "inbound fees",
 		fn:   runInboundFees,
+	}, {
+		name: "path finding timeout",
+		fn:   runFindPathTimeout,
+	}, {
+		name: "outgoing channel weights",
+		fn:   runOutgoingChannelWeights,
+	}, {
+		name: "local balance aware",
+		fn:   runLocalBalanceAware,
+	}, {
+		name: "path finding stats",
+		fn:   runPathFindingStats,
+	}, {
+		name: "min channel capacity",
+		fn:   runMinChannelCapacity,
+	}, {
+		name: "latency penalty",
+		fn:   runLatencyPenalty,
 	}}
 
 	// Run with graph cache enabled.
@@ -894,6 +925,369 @@ func runFindPathWithMetadata(t *testing.T, useCache bool) {
 	require.ErrorIs(t, errNoTlvPayload, err)
 }
 
+// runFindPathTimeout tests that a path search is aborted once it exceeds its
+// time limit.
+func runFindPathTimeout(t *testing.T, useCache bool) {
+	policy := &testChannelPolicy{
+		Expiry:  144,
+		FeeRate: 400,
+		MinHTLC: 1,
+		MaxHTLC: 100000000,
+	}
+	testChannels := []*testChannel{
+		symmetricTestChannel("alice", "bob", 100000, policy),
+		symmetricTestChannel("bob", "carol", 100000, policy),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "alice")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("carol")
+
+	// With a generous time limit, the path is found.
+	ctx.restrictParams.Timeout = time.Minute
+
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	require.Len(t, path, 2)
+
+	// A search that can't complete in time is aborted.
+	ctx.restrictParams.Timeout = time.Nanosecond
+
+	_, err = ctx.findPath(target, paymentAmt)
+	require.ErrorIs(t, err, errPathFindingTimeout)
+}
+
+// runOutgoingChannelWeights tests that weights on the outgoing channels bias
+// the first hop selection.
+func runOutgoingChannelWeights(t *testing.T, useCache bool) {
+	const (
+		chanSourceA  = 1
+		chanSourceB1 = 2
+		chanSourceB2 = 3
+		chanATarget  = 4
+		chanBTarget  = 5
+	)
+
+	// Set up a test graph with two possible paths from roasbeef to target.
+	// The path through a is the cheaper one. There are two channels from
+	// roasbeef to b.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceA),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+		}, chanATarget),
+		symmetricTestChannel("roasbeef", "b", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceB1),
+		symmetricTestChannel("roasbeef", "b", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceB2),
+		symmetricTestChannel("b", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 800,
+		}, chanBTarget),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+	ctx.pathFindingConfig.AttemptCost = lnwire.NewMSatFromSatoshis(100)
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	// Without weights, the cheapest path is taken.
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+
+	// A low weight on the channel to a makes the path through b more
+	// attractive. Of the two channels to b, the one with the higher weight
+	// is picked.
+	ctx.restrictParams.OutgoingChannelIDs = []uint64{
+		chanSourceA, chanSourceB1, chanSourceB2,
+	}
+	ctx.restrictParams.OutgoingChannelWeights = map[uint64]float64{
+		chanSourceA:  0.1,
+		chanSourceB1: 0.5,
+		chanSourceB2: 1,
+	}
+
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceB2, chanBTarget})
+
+	// Swapping the weights of the channels to b changes the channel.
+	ctx.restrictParams.OutgoingChannelWeights[chanSourceB1] = 1
+	ctx.restrictParams.OutgoingChannelWeights[chanSourceB2] = 0.5
+
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceB1, chanBTarget})
+
+	// The weight only affects the selection. A channel with a low weight
+	// is still used if its probability is above the lower bound.
+	ctx.restrictParams.OutgoingChannelIDs = []uint64{chanSourceA}
+	ctx.pathFindingConfig.MinProbability = 0.5
+
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+}
+
+// runLocalBalanceAware tests that local balance aware path finding avoids
+// first hop channels that the payment would almost deplete.
+func runLocalBalanceAware(t *testing.T, useCache bool) {
+	const (
+		chanSourceA = 1
+		chanSourceB = 2
+		chanATarget = 3
+		chanBTarget = 4
+	)
+
+	// Set up a test graph with two possible paths from roasbeef to target.
+	// The path through a is the cheaper one.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceA),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+		}, chanATarget),
+		symmetricTestChannel("roasbeef", "b", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceB),
+		symmetricTestChannel("b", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 800,
+		}, chanBTarget),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+	ctx.pathFindingConfig.AttemptCost = lnwire.NewMSatFromSatoshis(100)
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	// The channel to a has just enough balance left for the payment, while
+	// the channel to b has plenty.
+	ctx.bandwidthHints = &mockBandwidthHints{
+		hints: map[uint64]lnwire.MilliSatoshi{
+			chanSourceA: lnwire.NewMSatFromSatoshis(101),
+			chanSourceB: lnwire.NewMSatFromSatoshis(90000),
+		},
+	}
+
+	// By default, only the fees matter and the path through a is taken.
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+
+	// If path finding is local balance aware, the channel to b is
+	// preferred.
+	ctx.restrictParams.LocalBalanceAware = true
+
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceB, chanBTarget})
+
+	// The local balance doesn't lower the probability that is checked
+	// against the lower bound, so the channel to a can still be used.
+	ctx.restrictParams.OutgoingChannelIDs = []uint64{chanSourceA}
+	ctx.pathFindingConfig.MinProbability = 0.9
+
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+}
+
+// runPathFindingStats tests that path finding collects the statistics of its
+// searches.
+func runPathFindingStats(t *testing.T, useCache bool) {
+	const (
+		chanSourceA = 1
+		chanSourceB = 2
+		chanATarget = 3
+		chanBTarget = 4
+	)
+
+	// Set up a test graph with two possible paths from roasbeef to target.
+	// The path through b is too expensive for the fee limit.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceA),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+		}, chanATarget),
+		symmetricTestChannel("roasbeef", "b", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceB),
+		symmetricTestChannel("b", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 100000,
+		}, chanBTarget),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	stats := &PathFindingStats{}
+	ctx.restrictParams.Stats = stats
+	ctx.restrictParams.FeeLimit = lnwire.NewMSatFromSatoshis(1)
+
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+
+	require.Equal(t, 1, stats.NumSearches)
+	require.Equal(t, 2, stats.NodesVisited)
+	require.Equal(t, 3, stats.EdgesExpanded)
+	require.Equal(t, 2, stats.EdgesRelaxed)
+	require.Equal(t, map[PruneReason]int{
+		PruneFeeLimit: 1,
+	}, stats.Pruned)
+
+	// The statistics of a further search are added up.
+	_, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.NumSearches)
+	require.Equal(t, 4, stats.NodesVisited)
+}
+
+// runMinChannelCapacity tests that network channels below the capacity floor
+// are pruned from the search, while local channels are kept.
+func runMinChannelCapacity(t *testing.T, useCache bool) {
+	const (
+		chanSourceA = 1
+		chanSourceB = 2
+		chanATarget = 3
+		chanBTarget = 4
+	)
+
+	// Set up a test graph with two possible paths from roasbeef to target.
+	// The cheaper path through a uses a small channel. The local channels
+	// are small as well.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 50000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceA),
+		symmetricTestChannel("a", "target", 50000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+		}, chanATarget),
+		symmetricTestChannel("roasbeef", "b", 50000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceB),
+		symmetricTestChannel("b", "target", 1000000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 800,
+		}, chanBTarget),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	// Without a capacity floor, the cheaper path through a is taken.
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+
+	// With a capacity floor, the small channel from a to target is pruned.
+	// The small local channel to b is still used.
+	stats := &PathFindingStats{}
+	ctx.restrictParams.Stats = stats
+	ctx.restrictParams.MinChannelCapacity = 100000
+
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceB, chanBTarget})
+	require.Equal(t, 1, stats.Pruned[PruneMinCapacity])
+}
+
+// runLatencyPenalty tests that historically slow pairs are avoided if the
+// payer puts a price on latency.
+func runLatencyPenalty(t *testing.T, useCache bool) {
+	const (
+		chanSourceA = 1
+		chanSourceB = 2
+		chanATarget = 3
+		chanBTarget = 4
+	)
+
+	// Set up a test graph with two possible paths from roasbeef to target.
+	// The path through a is cheaper.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceA),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+		}, chanATarget),
+		symmetricTestChannel("roasbeef", "b", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, chanSourceB),
+		symmetricTestChannel("b", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 800,
+		}, chanBTarget),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+	nodeA := ctx.keyFromAlias("a")
+
+	// The pair from a to target has been slow in the past.
+	ctx.restrictParams.LatencySource = func(fromNode,
+		toNode route.Vertex) time.Duration {
+
+		if fromNode == nodeA && toNode == target {
+			return 10 * time.Second
+		}
+
+		return 0
+	}
+
+	// Without a latency penalty, the cheaper path through a is taken.
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+
+	// A small penalty doesn't outweigh the fee difference.
+	ctx.restrictParams.LatencyPenalty = 1
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceA, chanATarget})
+
+	// With a higher penalty, the faster path through b is preferred.
+	ctx.restrictParams.LatencyPenalty = 100
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{chanSourceB, chanBTarget})
+}
+
+// TestLocalBalanceFactor tests the scaling of the first hop probability by
+// the local balance of a channel.
+func TestLocalBalanceFactor(t *testing.T) {
+	t.Parallel()
+
+	require.Zero(t, localBalanceFactor(100, 0))
+	require.Equal(t, 0.5, localBalanceFactor(100, 100))
+	require.InDelta(t, 0.99, localBalanceFactor(100, 9900), 1e-9)
+	require.Equal(t, 1.0, localBalanceFactor(0, 100))
+}
+
 // runFindLowestFeePath tests that out of two routes with identical total
 // time lock values, the route with the lowest total fee should be returned.
 // The fee rates are chosen such that the test failed on the previous edge
@@ -2675,6 +3069,13 @@ func runRestrictLastHop(t *testing.T, useCache bool) {
 			Expiry:  144,
 			FeeRate: 800,
 		}, 4),
+		symmetricTestChannel("source", "c", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, 5),
+		symmetricTestChannel("c", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 1200,
+		}, 6),
 	}
 
 	ctx := newPathFindingTestContext(t, useCache, testChannels, "source")
@@ -2693,6 +3094,95 @@ func runRestrictLastHop(t *testing.T, useCache bool) {
 			"but channel %v was selected instead",
 			path[0].policy.ChannelID)
 	}
+
+	// With a set of last hop candidates, the lowest cost path through any
+	// of them is selected.
+	ctx.restrictParams.LastHop = nil
+	ctx.restrictParams.LastHops = []route.Vertex{
+		ctx.keyFromAlias("c"), lastHop,
+	}
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err, "unable to find path")
+	require.EqualValues(t, 3, path[0].policy.ChannelID)
+
+	ctx.restrictParams.LastHops = []route.Vertex{ctx.keyFromAlias("c")}
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err, "unable to find path")
+	require.EqualValues(t, 5, path[0].policy.ChannelID)
+}
+
+// runRoutePrefix asserts that a route prefix is obeyed by the path finding
+// algorithm.
+func runRoutePrefix(t *testing.T, useCache bool) {
+	// Set up a test graph in which the path via channel 1 and 2 is the
+	// lowest cost path. Node c is not connected to the source.
+	testChannels := []*testChannel{
+		symmetricTestChannel("source", "a", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, 1),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+		}, 2),
+		symmetricTestChannel("source", "b", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, 3),
+		symmetricTestChannel("b", "target", 100000, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 800,
+		}, 4),
+		symmetricTestChannel("a", "b", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, 5),
+		symmetricTestChannel("a", "c", 100000, &testChannelPolicy{
+			Expiry: 144,
+		}, 6),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "source")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	assertPath := func(expected ...uint64) {
+		t.Helper()
+
+		path, err := ctx.findPath(target, paymentAmt)
+		require.NoError(t, err, "unable to find path")
+
+		chanIDs := make([]uint64, 0, len(path))
+		for _, edge := range path {
+			chanIDs = append(chanIDs, edge.policy.ChannelID)
+		}
+		require.Equal(t, expected, chanIDs)
+	}
+
+	// Without a prefix, the lowest cost path is selected.
+	assertPath(1, 2)
+
+	// A single node prefix forces the route through b first.
+	ctx.restrictParams.RoutePrefix = []route.Vertex{ctx.keyFromAlias("b")}
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err, "unable to find path")
+	require.EqualValues(t, 3, path[0].policy.ChannelID)
+
+	// With a prefix of a and b, the route can't go back through a and
+	// has to continue from b to the target.
+	ctx.restrictParams.RoutePrefix = []route.Vertex{
+		ctx.keyFromAlias("a"), ctx.keyFromAlias("b"),
+	}
+	assertPath(1, 5, 4)
+
+	ctx.restrictParams.RoutePrefix = []route.Vertex{
+		ctx.keyFromAlias("b"), ctx.keyFromAlias("a"),
+	}
+	assertPath(3, 5, 2)
+
+	// A prefix that starts with a node that isn't our peer can't be
+	// satisfied.
+	ctx.restrictParams.RoutePrefix = []route.Vertex{ctx.keyFromAlias("c")}
+	_, err = ctx.findPath(target, paymentAmt)
+	require.ErrorIs(t, err, errNoPathFound)
 }
 
 // runCltvLimit asserts that a cltv limit is obeyed by the path finding
@@ -2783,6 +3273,149 @@ func testCltvLimit(t *testing.T, useCache bool, limit uint32,
 	}
 }
 
+// runCltvRiskFactor asserts that the cltv risk factor trades fees against the
+// total time lock of the route.
+func runCltvRiskFactor(t *testing.T, useCache bool) {
+	// The path through a is the cheapest, but has the highest time lock.
+	// The path through c and d is more expensive, but has the lowest total
+	// time lock.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 100000, &testChannelPolicy{}, 1),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:      144,
+			FeeBaseMsat: 10000,
+			MinHTLC:     1,
+		}),
+		symmetricTestChannel("roasbeef", "c", 100000, &testChannelPolicy{}, 2),
+		symmetricTestChannel("c", "d", 100000, &testChannelPolicy{
+			Expiry:      30,
+			FeeBaseMsat: 10000,
+			MinHTLC:     1,
+		}),
+		symmetricTestChannel("d", "target", 100000, &testChannelPolicy{
+			Expiry:      30,
+			FeeBaseMsat: 10000,
+			MinHTLC:     1,
+		}),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	// With the default risk factor, the cheapest path is selected.
+	ctx.pathFindingConfig.CltvRiskFactor = RiskFactorBillionths
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, path[0].policy.ChannelID)
+
+	// A high risk factor makes the time lock outweigh the fees.
+	ctx.pathFindingConfig.CltvRiskFactor = 10_000_000
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, path[0].policy.ChannelID)
+}
+
+// runMaxHops asserts that the maximum number of hops is enforced during the
+// path search.
+func runMaxHops(t *testing.T, useCache bool) {
+	// The path through a is more expensive than the path through c and
+	// d, but has one hop less.
+	testChannels := []*testChannel{
+		symmetricTestChannel("roasbeef", "a", 100000, &testChannelPolicy{}, 1),
+		symmetricTestChannel("a", "target", 100000, &testChannelPolicy{
+			Expiry:      40,
+			FeeBaseMsat: 50000,
+			MinHTLC:     1,
+		}),
+		symmetricTestChannel("roasbeef", "c", 100000, &testChannelPolicy{}, 2),
+		symmetricTestChannel("c", "d", 100000, &testChannelPolicy{
+			Expiry:      40,
+			FeeBaseMsat: 1000,
+			MinHTLC:     1,
+		}),
+		symmetricTestChannel("d", "target", 100000, &testChannelPolicy{
+			Expiry:      40,
+			FeeBaseMsat: 1000,
+			MinHTLC:     1,
+		}),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	// Without a limit, the cheapest path is selected.
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	require.Len(t, path, 3)
+
+	// Limiting the route to two hops forces the more expensive path.
+	ctx.restrictParams.MaxHops = 2
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	require.Len(t, path, 2)
+	require.EqualValues(t, 1, path[0].policy.ChannelID)
+
+	// There is no path with a single hop.
+	ctx.restrictParams.MaxHops = 1
+	_, err = ctx.findPath(target, paymentAmt)
+	require.ErrorIs(t, err, errNoPathFound)
+}
+
+// runMaxHopsLongCheapestPath asserts that a cheaper path to an intermediate
+// node that exceeds the maximum number of hops doesn't hide a shorter path to
+// that node.
+func runMaxHopsLongCheapestPath(t *testing.T, useCache bool) {
+	// From x, the direct channel to the target is more expensive than the
+	// path through p and q.
+	testChannels := []*testChannel{
+		symmetricTestChannel(
+			"roasbeef", "x", 100000, &testChannelPolicy{}, 1,
+		),
+		symmetricTestChannel("x", "target", 100000, &testChannelPolicy{
+			Expiry:      40,
+			FeeBaseMsat: 50000,
+			MinHTLC:     1,
+		}, 2),
+		symmetricTestChannel("x", "p", 100000, &testChannelPolicy{
+			Expiry:      40,
+			FeeBaseMsat: 1000,
+			MinHTLC:     1,
+		}, 3),
+		symmetricTestChannel("p", "q", 100000, &testChannelPolicy{
+			Expiry:      40,
+			FeeBaseMsat: 1000,
+			MinHTLC:     1,
+		}, 4),
+		symmetricTestChannel("q", "target", 100000, &testChannelPolicy{
+			Expiry:      40,
+			FeeBaseMsat: 1000,
+			MinHTLC:     1,
+		}, 5),
+	}
+
+	ctx := newPathFindingTestContext(t, useCache, testChannels, "roasbeef")
+
+	paymentAmt := lnwire.NewMSatFromSatoshis(100)
+	target := ctx.keyFromAlias("target")
+
+	// Without a limit, the cheapest path is selected.
+	path, err := ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{1, 3, 4, 5})
+
+	// The cheapest path from x to the target has three hops, which is too
+	// long if the whole route is limited to three hops. The direct
+	// channel from x must be used instead.
+	ctx.restrictParams.MaxHops = 3
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err)
+	ctx.assertPath(path, []uint64{1, 2})
+}
+
 // runProbabilityRouting asserts that path finding not only takes into account
 // fees but also success probability.
 func runProbabilityRouting(t *testing.T, useCache bool) {
@@ -3147,6 +3780,22 @@ func runRouteToSelf(t *testing.T, useCache bool) {
 	path, err = ctx.findPath(target, paymentAmt)
 	require.NoError(t, err, "unable to find path")
 	ctx.assertPath(path, []uint64{1, 3, 2})
+
+	// Pin both ends of the circular route by channel instead. Without the
+	// incoming channel restriction, the path would return via channel 1.
+	ctx.restrictParams.LastHop = nil
+	ctx.restrictParams.IncomingChannelID = 2
+
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err, "unable to find path")
+	ctx.assertPath(path, []uint64{1, 3, 2})
+
+	// An incoming channel that doesn't lead to the destination leaves no
+	// path.
+	ctx.restrictParams.IncomingChannelID = 3
+
+	_, err = ctx.findPath(target, paymentAmt)
+	require.ErrorIs(t, err, errNoPathFound)
 }
 
 // runInboundFees tests whether correct routes are built when inbound fees
@@ -3,6 +3,7 @@ package routing
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
@@ -21,6 +22,36 @@ import (
 // the payment lifecycle is exiting .
 var ErrPaymentLifecycleExiting = errors.New("payment lifecycle exiting")
 
+// PaymentLimits holds the limits of a payment that can be adjusted while the
+// payment is in flight. Limits that are nil are left unchanged.
+type PaymentLimits struct {
+	// FeeLimit is the maximum total fee that may be paid for the payment.
+	FeeLimit *lnwire.MilliSatoshi
+
+	// CltvLimit is the maximum total time lock of the routes that are
+	// tried from now on.
+	CltvLimit *uint32
+
+	// Timeout is the time after which no new attempts are launched,
+	// counted from the moment the limits are applied. A zero timeout
+	// removes the timeout of the payment.
+	Timeout *time.Duration
+}
+
+// merge overwrites the limits with the ones that are set in the given
+// limits.
+func (l *PaymentLimits) merge(other *PaymentLimits) {
+	if other.FeeLimit != nil {
+		l.FeeLimit = other.FeeLimit
+	}
+	if other.CltvLimit != nil {
+		l.CltvLimit = other.CltvLimit
+	}
+	if other.Timeout != nil {
+		l.Timeout = other.Timeout
+	}
+}
+
 // paymentLifecycle holds all information about the current state of a payment
 // needed to resume if from any point.
 type paymentLifecycle struct {
@@ -32,6 +63,10 @@ type paymentLifecycle struct {
 	timeoutChan   <-chan time.Time
 	currentHeight int32
 
+	// missionControl is the mission control instance that the results of
+	// the attempts are reported to.
+	missionControl MissionController
+
 	// quit is closed to signal the sub goroutines of the payment lifecycle
 	// to stop.
 	quit chan struct{}
@@ -47,6 +82,19 @@ type paymentLifecycle struct {
 	// except in unit test, where we use a much simpler resultCollector to
 	// decouple the test flow for the payment lifecycle.
 	resultCollector func(attempt *channeldb.HTLCAttempt)
+
+	// pendingLimits holds the limits that were updated while the payment
+	// is in flight. They are applied by the lifecycle loop before it
+	// launches the next attempt.
+	pendingLimits *PaymentLimits
+	limitsMtx     sync.Mutex
+
+	// cancelRequests receives the requests to cancel the payment. The
+	// result of the cancellation is sent back on the passed channel. The
+	// requests are handled by the lifecycle loop, so that it stops
+	// launching new attempts while it keeps collecting the results of the
+	// attempts in flight.
+	cancelRequests chan chan error
 }
 
 // newPaymentLifecycle initiates a new payment lifecycle and returns it.
@@ -62,8 +110,10 @@ func newPaymentLifecycle(r *ChannelRouter, feeLimit lnwire.MilliSatoshi,
 		paySession:      paySession,
 		shardTracker:    shardTracker,
 		currentHeight:   currentHeight,
+		missionControl:  r.cfg.MissionControl,
 		quit:            make(chan struct{}),
 		resultCollected: make(chan error, 1),
+		cancelRequests:  make(chan chan error, 1),
 	}
 
 	// Mount the result collector.
@@ -79,6 +129,102 @@ func newPaymentLifecycle(r *ChannelRouter, feeLimit lnwire.MilliSatoshi,
 	return p
 }
 
+// updateLimits schedules new limits to be applied to the payment.
+func (p *paymentLifecycle) updateLimits(limits *PaymentLimits) {
+	p.limitsMtx.Lock()
+	defer p.limitsMtx.Unlock()
+
+	if p.pendingLimits == nil {
+		p.pendingLimits = &PaymentLimits{}
+	}
+	p.pendingLimits.merge(limits)
+}
+
+// applyLimits applies the limits that were updated since the last iteration
+// of the lifecycle loop.
+func (p *paymentLifecycle) applyLimits() {
+	p.limitsMtx.Lock()
+	limits := p.pendingLimits
+	p.pendingLimits = nil
+	p.limitsMtx.Unlock()
+
+	if limits == nil {
+		return
+	}
+
+	if limits.FeeLimit != nil {
+		log.Infof("Updating fee limit of payment %v to %v",
+			p.identifier, *limits.FeeLimit)
+
+		p.feeLimit = *limits.FeeLimit
+	}
+
+	if limits.CltvLimit != nil && p.paySession != nil {
+		log.Infof("Updating cltv limit of payment %v to %v",
+			p.identifier, *limits.CltvLimit)
+
+		p.paySession.UpdateCltvLimit(*limits.CltvLimit)
+	}
+
+	if limits.Timeout != nil {
+		log.Infof("Updating timeout of payment %v to %v",
+			p.identifier, *limits.Timeout)
+
+		p.timeoutChan = nil
+		if *limits.Timeout != 0 {
+			p.timeoutChan = time.After(*limits.Timeout)
+		}
+	}
+}
+
+// cancel requests the lifecycle loop to cancel the payment and waits for the
+// result. It returns ErrPaymentLifecycleExiting if the lifecycle exited before
+// the request was handled.
+func (p *paymentLifecycle) cancel() error {
+	errChan := make(chan error, 1)
+
+	select {
+	case p.cancelRequests <- errChan:
+	case <-p.quit:
+		return ErrPaymentLifecycleExiting
+	}
+
+	select {
+	case err := <-errChan:
+		return err
+
+	// The request may still be buffered when the lifecycle exits, in
+	// which case it is never handled.
+	case <-p.quit:
+		select {
+		case err := <-errChan:
+			return err
+		default:
+			return ErrPaymentLifecycleExiting
+		}
+	}
+}
+
+// handleCancel cancels the payment and sends the result to the requester.
+func (p *paymentLifecycle) handleCancel(errChan chan error) {
+	log.Infof("Canceling payment %v", p.identifier)
+
+	errChan <- p.router.cfg.Control.CancelPayment(p.identifier)
+}
+
+// checkCancel handles a pending cancel request, if any. It returns true if a
+// request was handled.
+func (p *paymentLifecycle) checkCancel() bool {
+	select {
+	case errChan := <-p.cancelRequests:
+		p.handleCancel(errChan)
+		return true
+
+	default:
+		return false
+	}
+}
+
 // calcFeeBudget returns the available fee to be used for sending HTLC
 // attempts.
 func (p *paymentLifecycle) calcFeeBudget(
@@ -155,6 +301,11 @@ func (p *paymentLifecycle) decideNextStep(
 			log.Tracef("Received attempt result for payment %v",
 				p.identifier)
 
+		// A cancel request prevents new attempts. We re-evaluate the
+		// state afterwards and keep waiting for the results.
+		case errChan := <-p.cancelRequests:
+			p.handleCancel(errChan)
+
 		case <-p.router.quit:
 			return stepExit, ErrRouterShuttingDown
 		}
@@ -201,6 +352,10 @@ func (p *paymentLifecycle) resumePayment() ([32]byte, *route.Route, error) {
 	// critical error during path finding.
 lifecycle:
 	for {
+		// Handle a cancel request before the payment state is fetched,
+		// so that the state reflects it.
+		p.checkCancel()
+
 		// We update the payment state on every iteration. Since the
 		// payment state is affected by multiple goroutines (ie,
 		// collectResultAsync), it is NOT guaranteed that we always
@@ -211,6 +366,10 @@ lifecycle:
 			return exitWithErr(err)
 		}
 
+		// Apply the limits that were updated by the user in the
+		// meantime, before they are used for the next attempt.
+		p.applyLimits()
+
 		ps := payment.GetState()
 		remainingFees := p.calcFeeBudget(ps.FeesPaid)
 
@@ -277,6 +436,13 @@ lifecycle:
 			continue lifecycle
 		}
 
+		// The payment may have been canceled during path finding. In
+		// that case the route is discarded and the next iteration
+		// only waits for the attempts in flight.
+		if p.checkCancel() {
+			continue lifecycle
+		}
+
 		log.Tracef("Found route: %s", spew.Sdump(rt.Hops))
 
 		// We found a route to try, create a new HTLC attempt to try.
@@ -541,8 +707,8 @@ func (p *paymentLifecycle) collectResult(attempt *channeldb.HTLCAttempt) (
 		p.identifier, attempt.AttemptID)
 
 	// Report success to mission control.
-	err = p.router.cfg.MissionControl.ReportPaymentSuccess(
-		attempt.AttemptID, &attempt.Route,
+	err = p.missionControl.ReportPaymentSuccess(
+		attempt.AttemptID, &attempt.Route, attempt.AttemptTime,
 	)
 	if err != nil {
 		log.Errorf("Error reporting payment success to mc: %v", err)
@@ -755,7 +921,7 @@ func (p *paymentLifecycle) handleSwitchErr(attempt *channeldb.HTLCAttempt,
 		msg lnwire.FailureMessage) (*attemptResult, error) {
 
 		// Report outcome to mission control.
-		reason, err := p.router.cfg.MissionControl.ReportPaymentFail(
+		reason, err := p.missionControl.ReportPaymentFail(
 			attemptID, &attempt.Route, srcIdx, msg,
 		)
 		if err != nil {
@@ -326,6 +326,44 @@ func TestCheckTimeoutTimedOut(t *testing.T) {
 	ct.AssertExpectations(t)
 }
 
+// TestApplyLimits checks that the limits that are updated while a payment is
+// in flight are applied to the payment lifecycle.
+func TestApplyLimits(t *testing.T) {
+	t.Parallel()
+
+	p, m := newTestPaymentLifecycle(t)
+
+	// Without any updates, the limits are left unchanged.
+	p.applyLimits()
+	require.Equal(t, noFeeLimit, p.feeLimit)
+	require.Nil(t, p.timeoutChan)
+
+	// Updates are merged until they are applied.
+	feeLimit := lnwire.MilliSatoshi(1000)
+	cltvLimit := uint32(500)
+	timeout := time.Hour
+	p.updateLimits(&PaymentLimits{FeeLimit: &feeLimit})
+	p.updateLimits(&PaymentLimits{
+		CltvLimit: &cltvLimit,
+		Timeout:   &timeout,
+	})
+
+	m.paySession.On("UpdateCltvLimit", cltvLimit).Once()
+	p.applyLimits()
+
+	require.Equal(t, feeLimit, p.feeLimit)
+	require.NotNil(t, p.timeoutChan)
+	require.Nil(t, p.pendingLimits)
+
+	// A zero timeout removes the timeout.
+	timeout = 0
+	p.updateLimits(&PaymentLimits{Timeout: &timeout})
+	p.applyLimits()
+
+	require.Equal(t, feeLimit, p.feeLimit)
+	require.Nil(t, p.timeoutChan)
+}
+
 // TestCheckTimeoutOnRouterQuit checks that when the router has quit, an error
 // is returned from checkTimeout.
 func TestCheckTimeoutOnRouterQuit(t *testing.T) {
@@ -1062,6 +1100,110 @@ func TestResumePaymentSuccess(t *testing.T) {
 	require.Equal(t, 1, m.collectResultsCount)
 }
 
+// TestResumePaymentCancel checks that a payment that is canceled during path
+// finding doesn't launch the attempt for the found route, but still waits for
+// the result of the attempt in flight before it exits with the cancel reason.
+func TestResumePaymentCancel(t *testing.T) {
+	t.Parallel()
+
+	// Create a test paymentLifecycle with the initial two calls mocked.
+	p, m := setupTestPaymentLifecycle(t)
+
+	paymentAmt := lnwire.MilliSatoshi(10000)
+	rt := createDummyRoute(t, paymentAmt)
+
+	ps := &channeldb.MPPaymentState{
+		RemainingAmt:        paymentAmt,
+		NumAttemptsInFlight: 1,
+	}
+	m.payment.On("GetState").Return(ps)
+	m.payment.On("GetStatus").Return(channeldb.StatusInFlight)
+
+	// 1. The first iteration requests a route. Path finding blocks until
+	// the payment was canceled.
+	m.control.On("FetchPayment", p.identifier).Return(m.payment, nil).Once()
+	m.payment.On("AllowMoreAttempts").Return(true, nil).Once()
+
+	routeRequested := make(chan struct{})
+	releaseRoute := make(chan struct{})
+	m.paySession.On("RequestRoute",
+		paymentAmt, p.feeLimit, uint32(ps.NumAttemptsInFlight),
+		uint32(p.currentHeight),
+	).Return(rt, nil).Run(func(args mock.Arguments) {
+		close(routeRequested)
+		<-releaseRoute
+	}).Once()
+
+	// The cancel request is handled once the route was found. No attempt
+	// is registered for it.
+	m.control.On("CancelPayment", p.identifier).Return(nil).Once()
+
+	// 2. The second iteration waits for the result of the attempt in
+	// flight.
+	m.control.On("FetchPayment", p.identifier).Return(m.payment, nil).Once()
+	m.payment.On("AllowMoreAttempts").Return(false, nil).Once()
+	m.payment.On("NeedWaitAttempts").Return(true, nil).Once()
+
+	// 3. Once the result was collected, the lifecycle exits.
+	m.control.On("FetchPayment", p.identifier).Return(m.payment, nil).Once()
+	m.payment.On("AllowMoreAttempts").Return(false, nil).Once()
+	m.payment.On("NeedWaitAttempts").Return(false, nil).Once()
+	m.control.On("DeleteFailedAttempts", p.identifier).Return(nil).Once()
+
+	reason := channeldb.FailureReasonCanceled
+	m.payment.On("TerminalInfo").Return(nil, &reason).Once()
+
+	resultChan := make(chan error, 1)
+	go func() {
+		_, _, err := p.resumePayment()
+		resultChan <- err
+	}()
+
+	select {
+	case <-routeRequested:
+	case <-time.After(testTimeout):
+		require.Fail(t, "route not requested")
+	}
+
+	// Cancel the payment while path finding is in progress and release
+	// the route once the request is pending.
+	cancelErr := make(chan error, 1)
+	go func() {
+		cancelErr <- p.cancel()
+	}()
+	require.Eventually(t, func() bool {
+		return len(p.cancelRequests) == 1
+	}, testTimeout, 10*time.Millisecond)
+	close(releaseRoute)
+
+	select {
+	case err := <-cancelErr:
+		require.NoError(t, err)
+	case <-time.After(testTimeout):
+		require.Fail(t, "cancel not handled")
+	}
+
+	// The lifecycle keeps waiting for the attempt in flight.
+	select {
+	case err := <-resultChan:
+		require.Failf(t, "lifecycle exited early", "err: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Deliver the result of the attempt in flight.
+	p.resultCollected <- nil
+
+	select {
+	case err := <-resultChan:
+		require.ErrorIs(t, err, reason)
+	case <-time.After(testTimeout):
+		require.Fail(t, "timeout waiting for result")
+	}
+
+	// A cancel after the lifecycle exited is reported as such.
+	require.ErrorIs(t, p.cancel(), ErrPaymentLifecycleExiting)
+}
+
 // TestResumePaymentSuccessWithTwoAttempts checks a successful payment flow
 // with two HTLC attempts.
 //
@@ -1436,7 +1578,7 @@ func TestCollectResultExitOnSettleErr(t *testing.T) {
 	// Once the result is received, `ReportPaymentSuccess` should be
 	// called.
 	m.missionControl.On("ReportPaymentSuccess",
-		attempt.AttemptID, &attempt.Route,
+		attempt.AttemptID, &attempt.Route, attempt.AttemptTime,
 	).Return(nil).Once()
 
 	// Now mock an error being returned from `SettleAttempt`.
@@ -1483,7 +1625,7 @@ func TestCollectResultSuccess(t *testing.T) {
 	// Once the result is received, `ReportPaymentSuccess` should be
 	// called.
 	m.missionControl.On("ReportPaymentSuccess",
-		attempt.AttemptID, &attempt.Route,
+		attempt.AttemptID, &attempt.Route, attempt.AttemptTime,
 	).Return(nil).Once()
 
 	// Now the settled htlc being returned from `SettleAttempt`.
@@ -1531,7 +1673,7 @@ func TestCollectResultAsyncSuccess(t *testing.T) {
 	// Once the result is received, `ReportPaymentSuccess` should be
 	// called.
 	m.missionControl.On("ReportPaymentSuccess",
-		attempt.AttemptID, &attempt.Route,
+		attempt.AttemptID, &attempt.Route, attempt.AttemptTime,
 	).Return(nil).Once()
 
 	// Now the settled htlc being returned from `SettleAttempt`.
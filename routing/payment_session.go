@@ -2,8 +2,12 @@ package routing
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"sync"
 
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btclog"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/build"
@@ -64,6 +68,10 @@ const (
 	// errMissingDependentFeature is returned when the destination node
 	// misses a feature that a feature that we require depends on.
 	errMissingDependentFeature
+
+	// errPathFindingTimeout is returned when a path search exceeds its
+	// time limit.
+	errPathFindingTimeout
 )
 
 var (
@@ -71,6 +79,11 @@ var (
 	// further split the payment if no route is found. It is the minimum
 	// amount that we use as the shard size when splitting.
 	DefaultShardMinAmt = lnwire.NewMSatFromSatoshis(10000)
+
+	// DefaultMaxParallelShardSearches is the default number of shard
+	// amounts for which paths are searched concurrently once a payment
+	// needs to be split.
+	DefaultMaxParallelShardSearches = 4
 )
 
 // Error returns the string representation of the noRouteError.
@@ -97,6 +110,9 @@ func (e noRouteError) Error() string {
 	case errMissingDependentFeature:
 		return "missing dependent feature"
 
+	case errPathFindingTimeout:
+		return "path finding timeout exceeded"
+
 	default:
 		return "unknown no-route error"
 	}
@@ -111,7 +127,8 @@ func (e noRouteError) FailureReason() channeldb.FailureReason {
 		errNoPathFound,
 		errEmptyPaySession,
 		errUnknownRequiredFeature,
-		errMissingDependentFeature:
+		errMissingDependentFeature,
+		errPathFindingTimeout:
 
 		return channeldb.FailureReasonNoRoute
 
@@ -152,6 +169,10 @@ type PaymentSession interface {
 	// if nothing found.
 	GetAdditionalEdgePolicy(pubKey *btcec.PublicKey,
 		channelID uint64) *models.CachedEdgePolicy
+
+	// UpdateCltvLimit sets the maximum total time lock of the routes that
+	// are returned from now on.
+	UpdateCltvLimit(cltvLimit uint32)
 }
 
 // paymentSession is used during an HTLC routings session to prune the local
@@ -181,12 +202,28 @@ type paymentSession struct {
 
 	missionControl MissionController
 
+	// routeCache is an optional cache of recently found paths that is
+	// consulted before running a full graph search.
+	routeCache *RouteCache
+
+	// nodeScores is an optional store of externally imported node scores
+	// that the success probabilities of pathfinding are multiplied with.
+	nodeScores *NodeScoreStore
+
 	// minShardAmt is the amount beyond which we won't try to further split
 	// the payment if no route is found. If the maximum number of htlcs
 	// specified in the payment is one, under no circumstances splitting
 	// will happen and this value remains unused.
 	minShardAmt lnwire.MilliSatoshi
 
+	// maxParallelSearches is the maximum number of shard amounts for which
+	// paths are searched concurrently when the payment needs to be split.
+	maxParallelSearches int
+
+	// shardSplitter decides how the payment is split into shards if no
+	// path can be found for the full amount of an attempt.
+	shardSplitter ShardSplitter
+
 	// log is a payment session-specific logger.
 	log btclog.Logger
 }
@@ -215,6 +252,9 @@ func newPaymentSession(p *LightningPayment,
 		missionControl:    missionControl,
 		minShardAmt:       DefaultShardMinAmt,
 		log:               build.NewPrefixLog(logPrefix, log),
+
+		maxParallelSearches: DefaultMaxParallelShardSearches,
+		shardSplitter:       &HalvingSplitter{},
 	}, nil
 }
 
@@ -243,23 +283,83 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 	// finding. The optimal path is independent of the final cltv delta and
 	// the path finding algorithm is unaware of this value.
 	cltvLimit := p.payment.CltvLimit - uint32(finalCltvDelta)
+	if p.payment.MaxTotalCltv != 0 {
+		cltvLimit = min(cltvLimit, p.payment.MaxTotalCltv)
+	}
 
 	// TODO(roasbeef): sync logic amongst dist sys
 
+	// Use the payment's own probability estimator if one was set.
+	probabilitySource := p.missionControl.GetProbability
+	if p.payment.Estimator != nil {
+		probabilitySource = func(fromNode, toNode route.Vertex,
+			amt lnwire.MilliSatoshi,
+			capacity btcutil.Amount) float64 {
+
+			return p.missionControl.GetProbabilityWithEstimator(
+				p.payment.Estimator, fromNode, toNode, amt,
+				capacity,
+			)
+		}
+	}
+
+	// Weigh the probabilities with the node scores that were imported
+	// from external sources.
+	if p.nodeScores != nil {
+		source := probabilitySource
+		probabilitySource = func(fromNode, toNode route.Vertex,
+			amt lnwire.MilliSatoshi,
+			capacity btcutil.Amount) float64 {
+
+			return source(fromNode, toNode, amt, capacity) *
+				p.nodeScores.Multiplier(fromNode)
+		}
+	}
+
+	// Exclude the nodes and pairs that the payment asked to ignore. This
+	// only affects this payment and leaves mission control untouched.
+	if len(p.payment.IgnoredNodes) > 0 || len(p.payment.IgnoredPairs) > 0 {
+		source := probabilitySource
+		probabilitySource = func(fromNode, toNode route.Vertex,
+			amt lnwire.MilliSatoshi,
+			capacity btcutil.Amount) float64 {
+
+			if _, ok := p.payment.IgnoredNodes[fromNode]; ok {
+				return 0
+			}
+
+			pair := NewDirectedNodePair(fromNode, toNode)
+			if _, ok := p.payment.IgnoredPairs[pair]; ok {
+				return 0
+			}
+
+			return source(fromNode, toNode, amt, capacity)
+		}
+	}
+
 	// Taking into account this prune view, we'll attempt to locate a path
 	// to our destination, respecting the recommendations from
 	// MissionControl.
 	restrictions := &RestrictParams{
-		ProbabilitySource:  p.missionControl.GetProbability,
-		FeeLimit:           feeLimit,
-		OutgoingChannelIDs: p.payment.OutgoingChannelIDs,
-		LastHop:            p.payment.LastHop,
-		CltvLimit:          cltvLimit,
-		DestCustomRecords:  p.payment.DestCustomRecords,
-		DestFeatures:       p.payment.DestFeatures,
-		PaymentAddr:        p.payment.PaymentAddr,
-		Amp:                p.payment.amp,
-		Metadata:           p.payment.Metadata,
+		ProbabilitySource:      probabilitySource,
+		FeeLimit:               feeLimit,
+		OutgoingChannelIDs:     p.payment.OutgoingChannelIDs,
+		OutgoingChannelWeights: p.payment.OutgoingChannelWeights,
+		LastHop:                p.payment.LastHop,
+		LastHops:               p.payment.LastHops,
+		RoutePrefix:            p.payment.RoutePrefix,
+		CltvLimit:              cltvLimit,
+		MaxHops:                p.payment.MaxHops,
+		DestCustomRecords:      p.payment.DestCustomRecords,
+		DestFeatures:           p.payment.DestFeatures,
+		PaymentAddr:            p.payment.PaymentAddr,
+		Amp:                    p.payment.amp,
+		Metadata:               p.payment.Metadata,
+		Timeout:                p.payment.PathFindingTimeout,
+		LocalBalanceAware:      p.payment.LocalBalanceAware,
+		MinChannelCapacity:     p.payment.MinChannelCapacity,
+		LatencyPenalty:         p.payment.LatencyPenalty,
+		LatencySource:          p.missionControl.GetPairLatency,
 	}
 
 	finalHtlcExpiry := int32(height) + int32(finalCltvDelta)
@@ -276,6 +376,12 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 		maxAmt = *p.payment.MaxShardAmt
 	}
 
+	// amts holds the amounts that paths are searched for in the next
+	// iteration. Initially this is only the maximum amount. Once the
+	// payment needs to be split, paths for several smaller amounts are
+	// searched concurrently.
+	amts := []lnwire.MilliSatoshi{maxAmt}
+
 	for {
 		// Get a routing graph.
 		routingGraph, cleanup, err := p.getRoutingGraph()
@@ -291,23 +397,43 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 		// attempt, because concurrent payments may change balances.
 		bandwidthHints, err := p.getBandwidthHints(routingGraph)
 		if err != nil {
+			cleanup()
+
 			return nil, err
 		}
 
-		p.log.Debugf("pathfinding for amt=%v", maxAmt)
-
 		sourceVertex := routingGraph.sourceNode()
 
-		// Find a route for the current amount.
-		path, _, err := p.pathFinder(
-			&graphParams{
-				additionalEdges: p.additionalEdges,
-				bandwidthHints:  bandwidthHints,
-				graph:           routingGraph,
-			},
-			restrictions, &p.pathFindingConfig,
-			sourceVertex, p.payment.Target,
-			maxAmt, p.payment.TimePref, finalHtlcExpiry,
+		newFinalHop := func(amt lnwire.MilliSatoshi) finalHopParams {
+			return finalHopParams{
+				amt:         amt,
+				totalAmt:    p.payment.Amount,
+				cltvDelta:   finalCltvDelta,
+				records:     p.payment.DestCustomRecords,
+				paymentAddr: p.payment.PaymentAddr,
+				metadata:    p.payment.Metadata,
+			}
+		}
+
+		// If a path for a similar payment was found recently, we try to
+		// reuse it before running a full graph search.
+		if cacheKey, ok := p.routeCacheKey(sourceVertex, amts[0]); ok {
+			route := p.cachedRoute(
+				cacheKey, sourceVertex, height,
+				newFinalHop(amts[0]), restrictions,
+				bandwidthHints, finalHtlcExpiry,
+			)
+			if route != nil {
+				cleanup()
+
+				return route, nil
+			}
+		}
+
+		// Find a route for the largest possible amount.
+		path, amt, err := p.findPaths(
+			routingGraph, bandwidthHints, restrictions,
+			sourceVertex, amts, finalHtlcExpiry,
 		)
 
 		// Close routing graph.
@@ -351,11 +477,11 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 			}
 
 			// This is where the magic happens. If we can't find a
-			// route, try it for half the amount.
-			maxAmt /= 2
+			// route, try it for smaller amounts.
+			amts = p.splitAmounts(amt)
 
 			// Put a lower bound on the minimum shard size.
-			if maxAmt < p.minShardAmt {
+			if len(amts) == 0 {
 				p.log.Debugf("not splitting because minimum "+
 					"shard amount %v has been reached",
 					p.minShardAmt)
@@ -380,19 +506,15 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 			return nil, err
 		}
 
+		if cacheKey, ok := p.routeCacheKey(sourceVertex, amt); ok {
+			p.routeCache.add(cacheKey, path)
+		}
+
 		// With the next candidate path found, we'll attempt to turn
 		// this into a route by applying the time-lock and fee
 		// requirements.
 		route, err := newRoute(
-			sourceVertex, path, height,
-			finalHopParams{
-				amt:         maxAmt,
-				totalAmt:    p.payment.Amount,
-				cltvDelta:   finalCltvDelta,
-				records:     p.payment.DestCustomRecords,
-				paymentAddr: p.payment.PaymentAddr,
-				metadata:    p.payment.Metadata,
-			}, nil,
+			sourceVertex, path, height, newFinalHop(amt), nil,
 		)
 		if err != nil {
 			return nil, err
@@ -402,6 +524,248 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliSatoshi,
 	}
 }
 
+// splitAmounts returns the amounts to search paths for after path finding
+// failed for the given amount, as determined by the shard splitter of the
+// session. The amounts are returned in descending order.
+func (p *paymentSession) splitAmounts(
+	amt lnwire.MilliSatoshi) []lnwire.MilliSatoshi {
+
+	return p.shardSplitter.SplitAmounts(amt, SplitParams{
+		MinShardAmt:    p.minShardAmt,
+		MaxAmounts:     p.maxParallelSearches,
+		LocalBandwidth: p.localBandwidth,
+	})
+}
+
+// localBandwidth returns the available bandwidth of the local channels that
+// the payment may use, sorted in descending order.
+func (p *paymentSession) localBandwidth() []lnwire.MilliSatoshi {
+	graph, cleanup, err := p.getRoutingGraph()
+	if err != nil {
+		p.log.Errorf("Unable to get routing graph: %v", err)
+		return nil
+	}
+	defer cleanup()
+
+	bandwidthHints, err := p.getBandwidthHints(graph)
+	if err != nil {
+		p.log.Errorf("Unable to get bandwidth hints: %v", err)
+		return nil
+	}
+
+	var bandwidths []lnwire.MilliSatoshi
+	err = graph.forEachNodeChannel(graph.sourceNode(),
+		func(channel *channeldb.DirectedChannel) error {
+			chanID := channel.ChannelID
+			outgoingChans := p.payment.OutgoingChannelIDs
+			if len(outgoingChans) > 0 &&
+				!slices.Contains(outgoingChans, chanID) {
+
+				return nil
+			}
+
+			bandwidth, ok := bandwidthHints.availableChanBandwidth(
+				chanID, 0,
+			)
+			if ok && bandwidth > 0 {
+				bandwidths = append(bandwidths, bandwidth)
+			}
+
+			return nil
+		},
+	)
+	if err != nil {
+		p.log.Errorf("Unable to query local channels: %v", err)
+		return nil
+	}
+
+	sort.Slice(bandwidths, func(i, j int) bool {
+		return bandwidths[i] > bandwidths[j]
+	})
+
+	return bandwidths
+}
+
+// findPaths searches paths for the given amounts, which are expected to be
+// sorted in descending order. If there is more than one amount, the searches
+// run concurrently, each on its own read-only snapshot of the routing graph.
+// The shard splitter selects one of the amounts for which a path was found.
+// A path finding error other than errNoPathFound is only returned if it
+// occurred for a larger amount than all found paths, so that the result is
+// the same as if the amounts were tried one after another. If no path is
+// found for any of the amounts, errNoPathFound is returned along with the
+// smallest amount.
+func (p *paymentSession) findPaths(graph routingGraph,
+	bandwidthHints bandwidthHints, restrictions *RestrictParams,
+	source route.Vertex, amts []lnwire.MilliSatoshi,
+	finalHtlcExpiry int32) ([]*unifiedEdge, lnwire.MilliSatoshi, error) {
+
+	type result struct {
+		path        []*unifiedEdge
+		probability float64
+		err         error
+	}
+	results := make([]result, len(amts))
+
+	candidates := p.lastHopRestrictions(restrictions)
+
+	search := func(i int, graph routingGraph) {
+		p.log.Debugf("pathfinding for amt=%v", amts[i])
+
+		// Try the last hop candidates in order until a path is found.
+		for _, r := range candidates {
+			path, probability, err := p.pathFinder(
+				&graphParams{
+					additionalEdges: p.additionalEdges,
+					bandwidthHints:  bandwidthHints,
+					graph:           graph,
+				},
+				r, &p.pathFindingConfig,
+				source, p.payment.Target,
+				amts[i], p.payment.TimePref, finalHtlcExpiry,
+			)
+			results[i] = result{
+				path:        path,
+				probability: probability,
+				err:         err,
+			}
+
+			if err != errNoPathFound {
+				return
+			}
+		}
+	}
+
+	if len(amts) == 1 {
+		search(0, graph)
+	} else {
+		var wg sync.WaitGroup
+		for i := range amts {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				// A database transaction can't be shared
+				// between goroutines, so every search obtains
+				// its own routing graph.
+				graph, cleanup, err := p.getRoutingGraph()
+				if err != nil {
+					results[i] = result{err: err}
+					return
+				}
+				defer cleanup()
+
+				search(i, graph)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	var (
+		shards []ShardCandidate
+		found  []int
+	)
+	for i, res := range results {
+		if res.err == errNoPathFound {
+			continue
+		}
+
+		if res.err != nil {
+			if len(found) == 0 {
+				return nil, amts[i], res.err
+			}
+
+			break
+		}
+
+		shards = append(shards, ShardCandidate{
+			Amt:         amts[i],
+			Probability: res.probability,
+		})
+		found = append(found, i)
+	}
+
+	if len(found) == 0 {
+		return nil, amts[len(amts)-1], errNoPathFound
+	}
+
+	i := found[0]
+	if len(found) > 1 {
+		i = found[p.shardSplitter.SelectShard(shards)]
+	}
+
+	return results[i].path, amts[i], nil
+}
+
+// lastHopRestrictions returns the restrictions to run path finding with, in
+// the order in which they are tried. If the payment has an ordered list of last
+// hop candidates, there is one set of restrictions per candidate. Otherwise,
+// the given restrictions are returned as is.
+func (p *paymentSession) lastHopRestrictions(
+	restrictions *RestrictParams) []*RestrictParams {
+
+	if len(restrictions.LastHops) == 0 || p.payment.JointLastHops {
+		return []*RestrictParams{restrictions}
+	}
+
+	candidates := make([]*RestrictParams, 0, len(restrictions.LastHops))
+	for _, lastHop := range restrictions.LastHops {
+		lastHop := lastHop
+
+		r := *restrictions
+		r.LastHop = &lastHop
+		r.LastHops = nil
+		candidates = append(candidates, &r)
+	}
+
+	return candidates
+}
+
+// routeCacheKey returns the route cache key for an attempt of the given
+// amount. The boolean return value is false if there is no route cache or the
+// payment can't make use of it.
+func (p *paymentSession) routeCacheKey(source route.Vertex,
+	amt lnwire.MilliSatoshi) (routeCacheKey, bool) {
+
+	if p.routeCache == nil {
+		return routeCacheKey{}, false
+	}
+
+	return newRouteCacheKey(source, p.payment, amt)
+}
+
+// cachedRoute returns a route that is built from a cached path, or nil if
+// there is no cached path or it doesn't satisfy the restrictions of this
+// attempt anymore. A cached path that is no longer usable is removed from the
+// cache, so that the path found by the following graph search replaces it.
+func (p *paymentSession) cachedRoute(key routeCacheKey, source route.Vertex,
+	height uint32, finalHop finalHopParams, restrictions *RestrictParams,
+	bandwidthHints bandwidthHints, finalHtlcExpiry int32) *route.Route {
+
+	path := p.routeCache.get(key)
+	if path == nil {
+		return nil
+	}
+
+	rt, err := newRoute(source, path, height, finalHop, nil)
+	if err == nil && cachedRouteValid(
+		rt, path, restrictions, &p.pathFindingConfig, bandwidthHints,
+		finalHtlcExpiry,
+	) {
+
+		p.log.Debugf("Using cached path for amt=%v", finalHop.amt)
+
+		return rt
+	}
+
+	p.log.Debugf("Cached path for amt=%v no longer usable",
+		finalHop.amt)
+
+	p.routeCache.remove(key)
+
+	return nil
+}
+
 // UpdateAdditionalEdge updates the channel edge policy for a private edge. It
 // validates the message signature and checks it's up to date, then applies the
 // updates to the supplied policy. It returns a boolean to indicate whether
@@ -452,3 +816,11 @@ func (p *paymentSession) GetAdditionalEdgePolicy(pubKey *btcec.PublicKey,
 
 	return nil
 }
+
+// UpdateCltvLimit sets the maximum total time lock of the routes that are
+// returned from now on.
+//
+// NOTE: Part of the PaymentSession interface.
+func (p *paymentSession) UpdateCltvLimit(cltvLimit uint32) {
+	p.payment.CltvLimit = cltvLimit
+}
@@ -42,6 +42,20 @@ type SessionSource struct {
 	// PathFindingConfig defines global parameters that control the
 	// trade-off in path finding between fees and probability.
 	PathFindingConfig PathFindingConfig
+
+	// RouteCache is an optional cache of recently found paths. If set,
+	// payment sessions reuse a cached path to the same destination when
+	// it still satisfies the constraints of the payment attempt.
+	RouteCache *RouteCache
+
+	// ShardSplitter is the shard splitter that is used for payments that
+	// don't specify their own. If nil, amounts are halved.
+	ShardSplitter ShardSplitter
+
+	// NodeScores is an optional store of node scores imported from
+	// external sources. If set, the success probability of a channel is
+	// multiplied with the score of the node that forwards over it.
+	NodeScores *NodeScoreStore
 }
 
 // getRoutingGraph returns a routing graph and a clean-up function for
@@ -72,13 +86,28 @@ func (m *SessionSource) NewPaymentSession(p *LightningPayment) (
 		)
 	}
 
+	missionControl := m.MissionControl
+	if p.MissionControl != nil {
+		missionControl = p.MissionControl
+	}
+
 	session, err := newPaymentSession(
 		p, getBandwidthHints, m.getRoutingGraph,
-		m.MissionControl, m.PathFindingConfig,
+		missionControl, m.PathFindingConfig,
 	)
 	if err != nil {
 		return nil, err
 	}
+	session.routeCache = m.RouteCache
+	session.nodeScores = m.NodeScores
+
+	switch {
+	case p.ShardSplitter != nil:
+		session.shardSplitter = p.ShardSplitter
+
+	case m.ShardSplitter != nil:
+		session.shardSplitter = m.ShardSplitter
+	}
 
 	return session, nil
 }
@@ -1,6 +1,9 @@
 package routing
 
 import (
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -209,15 +212,17 @@ func TestRequestRoute(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// We expect find path to receive a cltv limit excluding the final cltv
+	// delta (including the block padding).
+	expectedCltvLimit := 22 - uint32(BlockPadding)
+
 	// Override pathfinder with a mock.
 	session.pathFinder = func(_ *graphParams, r *RestrictParams,
 		_ *PathFindingConfig, _, _ route.Vertex, _ lnwire.MilliSatoshi,
 		_ float64, _ int32) ([]*unifiedEdge, float64,
 		error) {
 
-		// We expect find path to receive a cltv limit excluding the
-		// final cltv delta (including the block padding).
-		if r.CltvLimit != 22-uint32(BlockPadding) {
+		if r.CltvLimit != expectedCltvLimit {
 			t.Fatal("wrong cltv limit")
 		}
 
@@ -250,6 +255,299 @@ func TestRequestRoute(t *testing.T) {
 		t.Fatalf("unexpected total time lock of %v",
 			route.TotalTimeLock)
 	}
+
+	// A maximum total cltv that is stricter than the cltv limit further
+	// restricts the time lock that the route may add.
+	payment.MaxTotalCltv = 5
+	expectedCltvLimit = 5
+
+	_, err = session.RequestRoute(
+		payment.Amount, payment.FeeLimit, 0, height,
+	)
+	require.NoError(t, err)
+}
+
+// TestRequestRouteParallelSplit tests that once a payment needs to be split,
+// paths for multiple shard amounts are searched concurrently and the largest
+// amount for which a path exists is used.
+func TestRequestRouteParallelSplit(t *testing.T) {
+	t.Parallel()
+
+	const height = 10
+
+	var paymentAddr [32]byte
+	payment := &LightningPayment{
+		CltvLimit:      500,
+		FinalCLTVDelta: 18,
+		Amount:         lnwire.NewMSatFromSatoshis(1_000_000),
+		FeeLimit:       lnwire.NewMSatFromSatoshis(1000),
+		MaxParts:       16,
+		PaymentAddr:    &paymentAddr,
+		DestFeatures: lnwire.NewFeatureVector(
+			lnwire.NewRawFeatureVector(
+				lnwire.TLVOnionPayloadOptional,
+				lnwire.PaymentAddrOptional,
+				lnwire.MPPOptional,
+			),
+			lnwire.Features,
+		),
+	}
+	require.NoError(t, payment.SetPaymentHash(lntypes.Hash{}))
+
+	var graphs atomic.Int32
+	session, err := newPaymentSession(
+		payment,
+		func(routingGraph) (bandwidthHints, error) {
+			return &mockBandwidthHints{}, nil
+		},
+		func() (routingGraph, func(), error) {
+			graphs.Add(1)
+			return &sessionGraph{}, func() {}, nil
+		},
+		&MissionControl{},
+		PathFindingConfig{},
+	)
+	require.NoError(t, err)
+	session.maxParallelSearches = 3
+
+	// Paths only exist for amounts up to a third of the payment amount.
+	maxPathAmt := payment.Amount / 3
+
+	var (
+		mu          sync.Mutex
+		searchedAmt []lnwire.MilliSatoshi
+	)
+	session.pathFinder = func(_ *graphParams, _ *RestrictParams,
+		_ *PathFindingConfig, _, _ route.Vertex,
+		amt lnwire.MilliSatoshi, _ float64, _ int32) ([]*unifiedEdge,
+		float64, error) {
+
+		mu.Lock()
+		searchedAmt = append(searchedAmt, amt)
+		mu.Unlock()
+
+		if amt > maxPathAmt {
+			return nil, 0, errNoPathFound
+		}
+
+		path := []*unifiedEdge{
+			{
+				policy: &models.CachedEdgePolicy{
+					ToNodePubKey: func() route.Vertex {
+						return route.Vertex{}
+					},
+					ToNodeFeatures: payment.DestFeatures,
+				},
+			},
+		}
+
+		return path, 1.0, nil
+	}
+
+	rt, err := session.RequestRoute(
+		payment.Amount, payment.FeeLimit, 0, height,
+	)
+	require.NoError(t, err)
+
+	// The full amount is tried first, followed by a single round of three
+	// concurrent searches for a half, a quarter and an eighth of the
+	// amount. The quarter is the largest amount with a path.
+	require.Equal(t, payment.Amount/4, rt.ReceiverAmt())
+	require.ElementsMatch(t, []lnwire.MilliSatoshi{
+		payment.Amount, payment.Amount / 2, payment.Amount / 4,
+		payment.Amount / 8,
+	}, searchedAmt)
+
+	// The initial search shares the routing graph of the iteration, while
+	// each of the concurrent searches uses its own snapshot.
+	require.EqualValues(t, 5, graphs.Load())
+}
+
+// TestRequestRouteIgnored tests that the nodes and pairs that a payment asks to
+// ignore are excluded from path finding.
+func TestRequestRouteIgnored(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ignoredNode = route.Vertex{1}
+		pairFrom    = route.Vertex{2}
+		pairTo      = route.Vertex{3}
+		otherNode   = route.Vertex{4}
+	)
+
+	payment := &LightningPayment{
+		CltvLimit:      500,
+		FinalCLTVDelta: 18,
+		Amount:         1000,
+		FeeLimit:       1000,
+		IgnoredNodes: map[route.Vertex]struct{}{
+			ignoredNode: {},
+		},
+		IgnoredPairs: map[DirectedNodePair]struct{}{
+			NewDirectedNodePair(pairFrom, pairTo): {},
+		},
+	}
+	require.NoError(t, payment.SetPaymentHash(lntypes.Hash{}))
+
+	session, err := newPaymentSession(
+		payment,
+		func(routingGraph) (bandwidthHints, error) {
+			return &mockBandwidthHints{}, nil
+		},
+		func() (routingGraph, func(), error) {
+			return &sessionGraph{}, func() {}, nil
+		},
+		&cacheTestMissionControl{probability: 0.5},
+		PathFindingConfig{},
+	)
+	require.NoError(t, err)
+
+	session.pathFinder = func(_ *graphParams, r *RestrictParams,
+		_ *PathFindingConfig, _, _ route.Vertex, _ lnwire.MilliSatoshi,
+		_ float64, _ int32) ([]*unifiedEdge, float64, error) {
+
+		p := func(from, to route.Vertex) float64 {
+			return r.ProbabilitySource(from, to, 1000, testCapacity)
+		}
+
+		require.Zero(t, p(ignoredNode, otherNode))
+		require.Zero(t, p(pairFrom, pairTo))
+		require.Equal(t, 0.5, p(pairTo, pairFrom))
+		require.Equal(t, 0.5, p(otherNode, ignoredNode))
+
+		return nil, 0, errNoPathFound
+	}
+
+	_, err = session.RequestRoute(
+		payment.Amount, payment.FeeLimit, 0, 10,
+	)
+	require.ErrorIs(t, err, errNoPathFound)
+}
+
+// TestRequestRouteLastHops tests that the last hop candidates of a payment
+// are tried in order, unless they are supposed to be considered jointly.
+func TestRequestRouteLastHops(t *testing.T) {
+	t.Parallel()
+
+	lastHops := []route.Vertex{{1}, {2}, {3}}
+
+	testCases := []struct {
+		name  string
+		joint bool
+
+		// pathAt is the index of the first last hop candidate that
+		// leads to a path.
+		pathAt int
+
+		expectedSearches [][]route.Vertex
+		expectedErr      error
+	}{
+		{
+			name:   "first candidate",
+			pathAt: 0,
+			expectedSearches: [][]route.Vertex{
+				{lastHops[0]},
+			},
+		},
+		{
+			name:   "fallback",
+			pathAt: 1,
+			expectedSearches: [][]route.Vertex{
+				{lastHops[0]}, {lastHops[1]},
+			},
+		},
+		{
+			name:   "no candidate",
+			pathAt: len(lastHops),
+			expectedSearches: [][]route.Vertex{
+				{lastHops[0]}, {lastHops[1]}, {lastHops[2]},
+			},
+			expectedErr: errNoPathFound,
+		},
+		{
+			name:   "joint",
+			joint:  true,
+			pathAt: 1,
+			expectedSearches: [][]route.Vertex{
+				lastHops,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			payment := &LightningPayment{
+				Target:         route.Vertex{4},
+				CltvLimit:      500,
+				FinalCLTVDelta: 18,
+				Amount:         1000,
+				FeeLimit:       1000,
+				LastHops:       lastHops,
+				JointLastHops:  tc.joint,
+			}
+			require.NoError(
+				t, payment.SetPaymentHash(lntypes.Hash{}),
+			)
+
+			session, err := newPaymentSession(
+				payment,
+				func(routingGraph) (bandwidthHints, error) {
+					return &mockBandwidthHints{}, nil
+				},
+				func() (routingGraph, func(), error) {
+					return &sessionGraph{}, func() {}, nil
+				},
+				&cacheTestMissionControl{probability: 0.5},
+				PathFindingConfig{},
+			)
+			require.NoError(t, err)
+
+			var searches [][]route.Vertex
+			session.pathFinder = func(_ *graphParams,
+				r *RestrictParams, _ *PathFindingConfig,
+				_, _ route.Vertex, _ lnwire.MilliSatoshi,
+				_ float64, _ int32) ([]*unifiedEdge, float64,
+				error) {
+
+				candidates := r.LastHops
+				if r.LastHop != nil {
+					require.Empty(t, r.LastHops)
+					candidates = []route.Vertex{*r.LastHop}
+				}
+				searches = append(searches, candidates)
+
+				for i := tc.pathAt; i < len(lastHops); i++ {
+					if slices.Contains(
+						candidates, lastHops[i],
+					) {
+
+						return newCacheTestPath(
+							uint64(i+1), 4,
+						), 1, nil
+					}
+				}
+
+				return nil, 0, errNoPathFound
+			}
+
+			rt, err := session.RequestRoute(
+				payment.Amount, payment.FeeLimit, 0, 10,
+			)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedSearches, searches)
+
+			if tc.expectedErr != nil {
+				return
+			}
+			require.Equal(
+				t, uint64(tc.pathAt+1), rt.Hops[0].ChannelID,
+			)
+		})
+	}
 }
 
 type sessionGraph struct {
@@ -107,6 +107,37 @@ func DefaultBimodalConfig() BimodalConfig {
 	}
 }
 
+// BimodalPairOverride holds bimodal parameters that replace the global ones
+// for a single directed node pair. Channels to known liquidity drains or
+// sources violate the assumption of the global scale and node weight, so that
+// they can be tuned individually.
+type BimodalPairOverride struct {
+	// NodeWeight replaces BimodalNodeWeight for the pair.
+	NodeWeight float64
+
+	// ScaleMsat replaces BimodalScaleMsat for the pair.
+	ScaleMsat lnwire.MilliSatoshi
+}
+
+// BimodalPairOverrides maps directed node pairs to the bimodal parameters that
+// are used for them.
+type BimodalPairOverrides map[DirectedNodePair]BimodalPairOverride
+
+// Validate checks that the override parameters are within the same bounds as
+// the global ones.
+func (o BimodalPairOverride) Validate() error {
+	if o.NodeWeight < 0 || o.NodeWeight > 1 {
+		return fmt.Errorf("%w: %v", ErrInvalidNodeWeight,
+			o.NodeWeight)
+	}
+
+	if o.ScaleMsat == 0 || o.ScaleMsat > BimodalScaleMsatMax {
+		return fmt.Errorf("%w: %v", ErrInvalidScale, o.ScaleMsat)
+	}
+
+	return nil
+}
+
 // BimodalEstimator returns node and pair probabilities based on historical
 // payment results based on a liquidity distribution model of the LN. The main
 // function is to estimate the direct channel probability based on a depleted
@@ -145,6 +176,20 @@ func (p *BimodalEstimator) String() string {
 		p.BimodalDecayTime, p.BimodalScaleMsat, p.BimodalNodeWeight)
 }
 
+// withPairOverride returns a copy of the estimator that uses the parameters of
+// the given pair override.
+func (p *BimodalEstimator) withPairOverride(
+	o BimodalPairOverride) *BimodalEstimator {
+
+	cfg := p.BimodalConfig
+	cfg.BimodalNodeWeight = o.NodeWeight
+	cfg.BimodalScaleMsat = o.ScaleMsat
+
+	return &BimodalEstimator{
+		BimodalConfig: cfg,
+	}
+}
+
 // PairProbability estimates the probability of successfully traversing to
 // toNode based on historical payment outcomes for the from node. Those outcomes
 // are passed in via the results parameter.
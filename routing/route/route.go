@@ -45,8 +45,41 @@ var (
 	// ErrUnexpectedField is returned if a tlv field is included when it
 	// should not be.
 	ErrUnexpectedField = errors.New("unexpected tlv included")
+
+	// ErrPayloadSizeExceeded is returned if the payloads of all hops of a
+	// route don't fit into the onion packet.
+	ErrPayloadSizeExceeded = errors.New("route payloads exceed onion size")
 )
 
+// ErrCustomRecordsTooLarge is returned if the custom records of the final hop
+// don't fit into the onion packet along with the payloads of the other hops
+// of the route.
+type ErrCustomRecordsTooLarge struct {
+	// RecordsSize is the number of bytes that the custom records take up
+	// in the payload of the final hop.
+	RecordsSize uint64
+
+	// Available is the number of bytes that the route leaves for the
+	// custom records of the final hop.
+	Available uint64
+
+	// NumHops is the number of hops of the route.
+	NumHops int
+}
+
+// Error returns a human readable string describing the error.
+func (e ErrCustomRecordsTooLarge) Error() string {
+	return fmt.Sprintf("custom records of %v bytes exceed the %v bytes "+
+		"left in the onion for a route of %v hops", e.RecordsSize,
+		e.Available, e.NumHops)
+}
+
+// Unwrap returns the generic payload size error, so that callers that don't
+// care about the custom records can still match it.
+func (e ErrCustomRecordsTooLarge) Unwrap() error {
+	return ErrPayloadSizeExceeded
+}
+
 // Vertex is a simple alias for the serialization of a compressed Bitcoin
 // public key.
 type Vertex [VertexSize]byte
@@ -592,6 +625,55 @@ func (r *Route) FinalHop() *Hop {
 	return r.Hops[len(r.Hops)-1]
 }
 
+// CheckPayloadSize verifies that the payloads of all hops fit into the onion
+// packet. If they don't because of the custom records of the final hop, an
+// ErrCustomRecordsTooLarge is returned that states the bytes that are left for
+// the records.
+func (r *Route) CheckPayloadSize() error {
+	// A route with too many hops can't be encoded regardless of the size
+	// of its payloads.
+	if len(r.Hops) > sphinx.NumMaxHops {
+		return ErrMaxRouteHopsExceeded
+	}
+
+	var total uint64
+	for i, hop := range r.Hops {
+		var nextChanID uint64
+		if i < len(r.Hops)-1 {
+			nextChanID = r.Hops[i+1].ChannelID
+		}
+
+		total += hop.PayloadSize(nextChanID)
+	}
+
+	if total <= sphinx.MaxPayloadSize {
+		return nil
+	}
+
+	finalHop := r.FinalHop()
+	if len(finalHop.CustomRecords) == 0 || finalHop.LegacyPayload {
+		return fmt.Errorf("%w: %v bytes exceed the maximum of %v bytes",
+			ErrPayloadSizeExceeded, total, sphinx.MaxPayloadSize)
+	}
+
+	// Determine the size of the custom records including the growth of
+	// the payload length prefix that they cause.
+	withoutRecords := *finalHop
+	withoutRecords.CustomRecords = nil
+	recordsSize := finalHop.PayloadSize(0) - withoutRecords.PayloadSize(0)
+
+	var available uint64
+	if base := total - recordsSize; base < sphinx.MaxPayloadSize {
+		available = sphinx.MaxPayloadSize - base
+	}
+
+	return ErrCustomRecordsTooLarge{
+		RecordsSize: recordsSize,
+		Available:   available,
+		NumHops:     len(r.Hops),
+	}
+}
+
 // NewRouteFromHops creates a new Route structure from the minimally required
 // information to perform the payment. It infers fee amounts and populates the
 // node, chan and prev/next hop maps.
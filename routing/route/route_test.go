@@ -430,3 +430,49 @@ func TestBlindedHopFee(t *testing.T) {
 	require.Equal(t, lnwire.MilliSatoshi(0), route.HopFee(3))
 	require.Equal(t, lnwire.MilliSatoshi(0), route.HopFee(4))
 }
+
+// TestCheckPayloadSize tests that oversized custom records of the final hop
+// are reported along with the bytes that the route leaves for them.
+func TestCheckPayloadSize(t *testing.T) {
+	t.Parallel()
+
+	newRoute := func(recordLen int) *Route {
+		return &Route{
+			Hops: []*Hop{{
+				PubKeyBytes:      testPubKeyBytes,
+				AmtToForward:     1500,
+				OutgoingTimeLock: 700000,
+				ChannelID:        63584534844,
+			}, {
+				PubKeyBytes:      testPubKeyBytes,
+				AmtToForward:     1000,
+				OutgoingTimeLock: 700000,
+				ChannelID:        51784534844,
+				CustomRecords: map[uint64][]byte{
+					65536: make([]byte, recordLen),
+				},
+			}},
+		}
+	}
+
+	// Small records fit into the onion.
+	require.NoError(t, newRoute(100).CheckPayloadSize())
+
+	// Records that are too large are reported with the remaining bytes.
+	err := newRoute(1300).CheckPayloadSize()
+	require.ErrorIs(t, err, ErrPayloadSizeExceeded)
+
+	var recordsErr ErrCustomRecordsTooLarge
+	require.ErrorAs(t, err, &recordsErr)
+	require.Equal(t, 2, recordsErr.NumHops)
+	require.Greater(t, recordsErr.RecordsSize, recordsErr.Available)
+
+	// A record that uses up exactly the remaining bytes fits. Apart from
+	// its value, the record takes up five bytes for its type, three bytes
+	// for its length and two more bytes for the longer payload length.
+	available := int(recordsErr.Available)
+	require.NoError(t, newRoute(available-10).CheckPayloadSize())
+	require.ErrorAs(
+		t, newRoute(available-9).CheckPayloadSize(), &recordsErr,
+	)
+}
@@ -0,0 +1,360 @@
+package routing
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/lightningnetwork/lnd/zpay32"
+)
+
+const (
+	// DefaultRouteCacheTTL is the default duration after which a cached
+	// path is no longer used, even if none of its channels was updated.
+	DefaultRouteCacheTTL = time.Minute
+)
+
+// routeCacheKey identifies the payment attempts that are allowed to share a
+// cached path. Attempts only share a path if they go to the same destination
+// with a similar amount and the same set of path finding constraints that
+// can't be re-checked cheaply on a cached path.
+type routeCacheKey struct {
+	source route.Vertex
+	target route.Vertex
+
+	// amtBucket is the bit length of the amount. All amounts within the
+	// same power of two share a bucket.
+	amtBucket int
+
+	timePref float64
+
+	// lastHop is the required last hop, or the zero vertex if there is
+	// none.
+	lastHop route.Vertex
+
+	// outgoingChans is the serialized, sorted set of allowed outgoing
+	// channels.
+	outgoingChans string
+
+	// routeHints is the hash of the route hints of the payment.
+	routeHints [sha256.Size]byte
+
+	mpp bool
+	amp bool
+}
+
+// routeCacheEntry is a path that is held in the route cache.
+type routeCacheEntry struct {
+	key     routeCacheKey
+	path    []*unifiedEdge
+	addedAt time.Time
+}
+
+// RouteCache is a bounded cache of recently found paths. It allows payers
+// that repeatedly pay the same destinations to skip a full graph search. A
+// cached path is dropped when one of its channels is updated or closed, when
+// it reaches its maximum age, or when it no longer satisfies the constraints
+// of a payment attempt, for example because mission control learned that one
+// of its channels is unlikely to succeed.
+type RouteCache struct {
+	maxSize int
+	ttl     time.Duration
+
+	// entries maps cache keys to their element in the lru list.
+	entries map[routeCacheKey]*list.Element
+
+	// lru holds the cached entries, the most recently used one in front.
+	lru *list.List
+
+	// chanIndex maps a channel id to the keys of all cached paths that
+	// traverse that channel.
+	chanIndex map[uint64]map[routeCacheKey]struct{}
+
+	// now is expected to return the current time. It is supplied as an
+	// external function to enable deterministic unit tests.
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewRouteCache returns a new route cache that holds at most maxSize paths,
+// each for at most the given ttl. A zero ttl selects the default.
+func NewRouteCache(maxSize int, ttl time.Duration) *RouteCache {
+	if ttl == 0 {
+		ttl = DefaultRouteCacheTTL
+	}
+
+	return &RouteCache{
+		maxSize:   maxSize,
+		ttl:       ttl,
+		entries:   make(map[routeCacheKey]*list.Element),
+		lru:       list.New(),
+		chanIndex: make(map[uint64]map[routeCacheKey]struct{}),
+		now:       time.Now,
+	}
+}
+
+// newRouteCacheKey returns the cache key for a payment attempt. The boolean
+// return value is false if the payment can't make use of the cache.
+func newRouteCacheKey(source route.Vertex, p *LightningPayment,
+	amt lnwire.MilliSatoshi) (routeCacheKey, bool) {
+
+	// Custom records and metadata take up space in the onion of the final
+	// hop, which limits the length of the path. Payments carrying them
+	// always get a fresh path. The same goes for payments that use their
+	// own estimator, channel weights, the local balance or a latency
+	// penalty and therefore weigh paths differently, for payments that
+	// exclude nodes, pairs or small channels and for payments with several
+	// last hop candidates or a route prefix.
+	if len(p.DestCustomRecords) > 0 || len(p.Metadata) > 0 ||
+		p.Estimator != nil || len(p.OutgoingChannelWeights) > 0 ||
+		p.LocalBalanceAware || p.MinChannelCapacity > 0 ||
+		p.LatencyPenalty > 0 || len(p.IgnoredNodes) > 0 ||
+		len(p.IgnoredPairs) > 0 || len(p.LastHops) > 0 ||
+		len(p.RoutePrefix) > 0 {
+
+		return routeCacheKey{}, false
+	}
+
+	key := routeCacheKey{
+		source:     source,
+		target:     p.Target,
+		amtBucket:  bits.Len64(uint64(amt)),
+		timePref:   p.TimePref,
+		routeHints: hashRouteHints(p.RouteHints),
+		mpp:        p.PaymentAddr != nil,
+		amp:        p.amp != nil,
+	}
+
+	if p.LastHop != nil {
+		key.lastHop = *p.LastHop
+	}
+
+	if len(p.OutgoingChannelIDs) > 0 {
+		chans := make([]uint64, len(p.OutgoingChannelIDs))
+		copy(chans, p.OutgoingChannelIDs)
+		sort.Slice(chans, func(i, j int) bool {
+			return chans[i] < chans[j]
+		})
+
+		var b []byte
+		for _, chanID := range chans {
+			b = binary.BigEndian.AppendUint64(b, chanID)
+		}
+		key.outgoingChans = string(b)
+	}
+
+	return key, true
+}
+
+// hashRouteHints returns a hash that commits to the given route hints.
+func hashRouteHints(routeHints [][]zpay32.HopHint) [sha256.Size]byte {
+	if len(routeHints) == 0 {
+		return [sha256.Size]byte{}
+	}
+
+	h := sha256.New()
+	for _, routeHint := range routeHints {
+		var b []byte
+		b = binary.BigEndian.AppendUint32(b, uint32(len(routeHint)))
+		for _, hopHint := range routeHint {
+			b = append(b, hopHint.NodeID.SerializeCompressed()...)
+			b = binary.BigEndian.AppendUint64(b, hopHint.ChannelID)
+			b = binary.BigEndian.AppendUint32(
+				b, hopHint.FeeBaseMSat,
+			)
+			b = binary.BigEndian.AppendUint32(
+				b, hopHint.FeeProportionalMillionths,
+			)
+			b = binary.BigEndian.AppendUint16(
+				b, hopHint.CLTVExpiryDelta,
+			)
+		}
+		_, _ = h.Write(b)
+	}
+
+	var hash [sha256.Size]byte
+	copy(hash[:], h.Sum(nil))
+
+	return hash
+}
+
+// get returns the cached path for the given key, or nil if there is none.
+func (c *RouteCache) get(key routeCacheKey) []*unifiedEdge {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := elem.Value.(*routeCacheEntry)
+	if c.now().Sub(entry.addedAt) > c.ttl {
+		c.removeElement(elem)
+
+		return nil
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return entry.path
+}
+
+// add adds a path to the cache, replacing any path that was cached for the
+// same key before. If the cache is full, the least recently used path is
+// evicted.
+func (c *RouteCache) add(key routeCacheKey, path []*unifiedEdge) {
+	if len(path) == 0 || c.maxSize <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+
+	for c.lru.Len() >= c.maxSize {
+		c.removeElement(c.lru.Back())
+	}
+
+	entry := &routeCacheEntry{
+		key:     key,
+		path:    path,
+		addedAt: c.now(),
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+
+	for _, edge := range path {
+		chanID := edge.policy.ChannelID
+		keys, ok := c.chanIndex[chanID]
+		if !ok {
+			keys = make(map[routeCacheKey]struct{})
+			c.chanIndex[chanID] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// remove removes the path for the given key from the cache.
+func (c *RouteCache) remove(key routeCacheKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// InvalidateChannels removes all cached paths that traverse one of the given
+// channels. It is called whenever the graph information of a channel changes.
+func (c *RouteCache) InvalidateChannels(chanIDs ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, chanID := range chanIDs {
+		for key := range c.chanIndex[chanID] {
+			if elem, ok := c.entries[key]; ok {
+				c.removeElement(elem)
+			}
+		}
+	}
+}
+
+// Len returns the number of cached paths.
+func (c *RouteCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// removeElement removes a cache entry and its channel index references.
+//
+// NOTE: The caller must hold the cache mutex.
+func (c *RouteCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*routeCacheEntry)
+
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+
+	for _, edge := range entry.path {
+		chanID := edge.policy.ChannelID
+		keys := c.chanIndex[chanID]
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.chanIndex, chanID)
+		}
+	}
+}
+
+// cachedRouteValid checks whether a route that was built from a cached path
+// still satisfies the restrictions of the current payment attempt. Because
+// the path was found for a different amount and possibly with a different
+// view of mission control, the limits that path finding would have enforced
+// are re-checked here.
+func cachedRouteValid(rt *route.Route, path []*unifiedEdge,
+	r *RestrictParams, cfg *PathFindingConfig, bandwidth bandwidthHints,
+	finalHtlcExpiry int32) bool {
+
+	if len(rt.Hops) != len(path) {
+		return false
+	}
+
+	if rt.TotalFees() > r.FeeLimit {
+		return false
+	}
+
+	if rt.TotalTimeLock-uint32(finalHtlcExpiry) > r.CltvLimit {
+		return false
+	}
+
+	if r.MaxHops != 0 && uint32(len(rt.Hops)) > r.MaxHops {
+		return false
+	}
+
+	probability := 1.0
+	for i, edge := range path {
+		// The amount that is sent across the channel towards hop i.
+		fromNode := rt.SourcePubKey
+		amt := rt.TotalAmount
+		if i > 0 {
+			fromNode = rt.Hops[i-1].PubKeyBytes
+			amt = rt.Hops[i-1].AmtToForward
+		}
+
+		if !edge.amtInRange(amt) {
+			return false
+		}
+
+		// For our own channels, the local balance is what matters.
+		// Path finding doesn't apply a probability to those.
+		if i == 0 {
+			chanID := rt.Hops[0].ChannelID
+			bw, ok := bandwidth.availableChanBandwidth(chanID, amt)
+			if ok && bw < amt {
+				return false
+			}
+
+			continue
+		}
+
+		p := r.ProbabilitySource(
+			fromNode, rt.Hops[i].PubKeyBytes, amt, edge.capacity,
+		)
+		if p == 0 {
+			return false
+		}
+		probability *= p
+	}
+
+	return probability >= cfg.MinProbability
+}
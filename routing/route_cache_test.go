@@ -0,0 +1,187 @@
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightningnetwork/lnd/channeldb/models"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// newCacheTestPath returns a path that traverses the given channels. Every
+// channel leads to a node that is identified by the channel id.
+func newCacheTestPath(chanIDs ...uint64) []*unifiedEdge {
+	path := make([]*unifiedEdge, 0, len(chanIDs))
+	for _, chanID := range chanIDs {
+		toNode := route.Vertex{byte(chanID)}
+		path = append(path, &unifiedEdge{
+			policy: &models.CachedEdgePolicy{
+				ChannelID:     chanID,
+				FeeBaseMSat:   10,
+				TimeLockDelta: 40,
+				ToNodePubKey: func() route.Vertex {
+					return toNode
+				},
+				ToNodeFeatures: lnwire.EmptyFeatureVector(),
+			},
+			capacity: testCapacity,
+		})
+	}
+
+	return path
+}
+
+// TestRouteCache tests the bookkeeping of the route cache.
+func TestRouteCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1000, 0)
+	cache := NewRouteCache(2, time.Minute)
+	cache.now = func() time.Time {
+		return now
+	}
+
+	key1 := routeCacheKey{target: route.Vertex{1}}
+	key2 := routeCacheKey{target: route.Vertex{2}}
+	key3 := routeCacheKey{target: route.Vertex{3}}
+
+	cache.add(key1, newCacheTestPath(1, 2))
+	cache.add(key2, newCacheTestPath(1, 3))
+	require.Len(t, cache.get(key1), 2)
+
+	// Adding a third path evicts the least recently used one, which is
+	// the second path.
+	cache.add(key3, newCacheTestPath(4))
+	require.Equal(t, 2, cache.Len())
+	require.Nil(t, cache.get(key2))
+	require.NotNil(t, cache.get(key1))
+	require.NotNil(t, cache.get(key3))
+
+	// An update of a channel invalidates all paths that traverse it.
+	cache.InvalidateChannels(2)
+	require.Nil(t, cache.get(key1))
+	require.NotNil(t, cache.get(key3))
+	require.NotContains(t, cache.chanIndex, uint64(1))
+
+	// Paths expire after the ttl.
+	now = now.Add(time.Minute + time.Second)
+	require.Nil(t, cache.get(key3))
+	require.Zero(t, cache.Len())
+	require.Empty(t, cache.chanIndex)
+}
+
+// cacheTestMissionControl is a mission control mock that returns a fixed
+// probability for all pairs.
+type cacheTestMissionControl struct {
+	MissionControl
+
+	probability float64
+}
+
+func (m *cacheTestMissionControl) GetProbability(_, _ route.Vertex,
+	_ lnwire.MilliSatoshi, _ btcutil.Amount) float64 {
+
+	return m.probability
+}
+
+// TestRequestRouteCached tests that payment sessions reuse cached paths as
+// long as they satisfy the constraints of the payment attempt.
+func TestRequestRouteCached(t *testing.T) {
+	t.Parallel()
+
+	const height = 10
+
+	payment := &LightningPayment{
+		Target:         route.Vertex{2},
+		CltvLimit:      500,
+		FinalCLTVDelta: 18,
+		Amount:         10_000,
+		FeeLimit:       1000,
+	}
+	require.NoError(t, payment.SetPaymentHash([32]byte{}))
+
+	mc := &cacheTestMissionControl{probability: 0.5}
+	bandwidth := &mockBandwidthHints{}
+	cache := NewRouteCache(10, time.Minute)
+
+	newSession := func() *paymentSession {
+		session, err := newPaymentSession(
+			payment,
+			func(routingGraph) (bandwidthHints, error) {
+				return bandwidth, nil
+			},
+			func() (routingGraph, func(), error) {
+				return &sessionGraph{}, func() {}, nil
+			},
+			mc, PathFindingConfig{MinProbability: 0.1},
+		)
+		require.NoError(t, err)
+		session.routeCache = cache
+
+		return session
+	}
+
+	var searches int
+	pathFinder := func(_ *graphParams, _ *RestrictParams,
+		_ *PathFindingConfig, _, _ route.Vertex, _ lnwire.MilliSatoshi,
+		_ float64, _ int32) ([]*unifiedEdge, float64, error) {
+
+		searches++
+
+		return newCacheTestPath(1, 2), 0.5, nil
+	}
+
+	requestRoute := func(amt lnwire.MilliSatoshi) *route.Route {
+		session := newSession()
+		session.pathFinder = pathFinder
+
+		rt, err := session.RequestRoute(
+			amt, payment.FeeLimit, 0, height,
+		)
+		require.NoError(t, err)
+
+		return rt
+	}
+
+	// The first payment runs a graph search, the second one to the same
+	// destination with a similar amount reuses the path.
+	requestRoute(10_000)
+	require.Equal(t, 1, searches)
+
+	rt := requestRoute(12_000)
+	require.Equal(t, 1, searches)
+	require.Equal(t, lnwire.MilliSatoshi(12_000), rt.ReceiverAmt())
+	require.Equal(t, uint64(2), rt.Hops[1].ChannelID)
+
+	// A payment with an amount in a different bucket runs a new search.
+	requestRoute(100_000)
+	require.Equal(t, 2, searches)
+
+	// If mission control no longer considers the path viable, it isn't
+	// reused.
+	mc.probability = 0.05
+	requestRoute(10_000)
+	require.Equal(t, 3, searches)
+
+	// The path found by the last search is reused again once mission
+	// control recovered.
+	mc.probability = 0.5
+	requestRoute(10_000)
+	require.Equal(t, 3, searches)
+
+	// Insufficient local balance on the first hop prevents reuse.
+	bandwidth.hints = map[uint64]lnwire.MilliSatoshi{1: 5000}
+	requestRoute(10_000)
+	require.Equal(t, 4, searches)
+	bandwidth.hints = nil
+
+	// An update of one of the channels invalidates the path.
+	cache.InvalidateChannels(2)
+	requestRoute(10_000)
+	require.Equal(t, 5, searches)
+	requestRoute(10_000)
+	require.Equal(t, 5, searches)
+}
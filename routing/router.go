@@ -51,6 +51,10 @@ const (
 	// if a channel should be pruned or not.
 	DefaultChannelPruneExpiry = time.Duration(time.Hour * 24 * 14)
 
+	// DefaultGraphPruneInterval is the default interval in which the graph
+	// is examined for zombie channels.
+	DefaultGraphPruneInterval = time.Hour
+
 	// DefaultFirstTimePruneDelay is the time we'll wait after startup
 	// before attempting to prune the graph for zombie channels. We don't
 	// do it immediately after startup to allow lnd to start up without
@@ -62,6 +66,10 @@ const (
 	// announcements.
 	defaultStatInterval = time.Minute
 
+	// stalePaymentSweepInterval is the interval in which payments that
+	// are stuck in flight are swept.
+	stalePaymentSweepInterval = 10 * time.Minute
+
 	// MinCLTVDelta is the minimum CLTV value accepted by LND for all
 	// timelock deltas. This includes both forwarding CLTV deltas set on
 	// channel updates, as well as final CLTV deltas used to create BOLT 11
@@ -124,6 +132,10 @@ var (
 	// ErrSkipTempErr is returned when a non-MPP is made yet the
 	// skipTempErr flag is set.
 	ErrSkipTempErr = errors.New("cannot skip temp error for non-MPP")
+
+	// ErrPaymentNotActive is returned when the limits of a payment are
+	// updated that isn't currently sent by the router.
+	ErrPaymentNotActive = errors.New("payment not active")
 )
 
 // ChannelGraphSource represents the source of information about the topology
@@ -265,13 +277,26 @@ type MissionController interface {
 		*channeldb.FailureReason, error)
 
 	// ReportPaymentSuccess reports a successful payment to mission control
-	// as input for future probability estimates.
-	ReportPaymentSuccess(attemptID uint64, rt *route.Route) error
+	// as input for future probability estimates. The attempt time is the
+	// time at which the htlc was sent out.
+	ReportPaymentSuccess(attemptID uint64, rt *route.Route,
+		attemptTime time.Time) error
+
+	// GetPairLatency returns the average resolution latency of successful
+	// payment attempts that is attributed to the given node pair.
+	GetPairLatency(fromNode, toNode route.Vertex) time.Duration
 
 	// GetProbability is expected to return the success probability of a
 	// payment from fromNode along edge.
 	GetProbability(fromNode, toNode route.Vertex,
 		amt lnwire.MilliSatoshi, capacity btcutil.Amount) float64
+
+	// GetProbabilityWithEstimator is expected to return the success
+	// probability of a payment from fromNode along edge, using the given
+	// estimator instead of the configured one.
+	GetProbabilityWithEstimator(estimator Estimator, fromNode,
+		toNode route.Vertex, amt lnwire.MilliSatoshi,
+		capacity btcutil.Amount) float64
 }
 
 // FeeSchema is the set fee configuration for a Lightning Node on the network.
@@ -355,6 +380,12 @@ type Config struct {
 	// gained to the next execution.
 	MissionControl MissionController
 
+	// GetMissionControl returns the mission control instance of the given
+	// namespace. It is used to report the results of payments that were
+	// sent with a mission control namespace. If nil, all results are
+	// reported to MissionControl.
+	GetMissionControl func(namespace string) (MissionController, error)
+
 	// SessionSource defines a source for the router to retrieve new payment
 	// sessions.
 	SessionSource PaymentSessionSource
@@ -410,6 +441,16 @@ type Config struct {
 	// IsAlias returns whether a passed ShortChannelID is an alias. This is
 	// only used for our local channels.
 	IsAlias func(scid lnwire.ShortChannelID) bool
+
+	// RouteCache is an optional cache of recently found paths. The router
+	// invalidates cached paths whenever one of their channels is updated
+	// or removed from the graph.
+	RouteCache *RouteCache
+
+	// StalePaymentTimeout is the age after which a payment that is in
+	// flight without any outstanding HTLCs is failed by the periodic
+	// sweep. A value of zero disables the sweep.
+	StalePaymentTimeout time.Duration
 }
 
 // EdgeLocator is a struct used to identify a specific edge.
@@ -481,6 +522,10 @@ type ChannelRouter struct {
 	// existing client.
 	ntfnClientUpdates chan *topologyClientUpdate
 
+	// activePayments maps the identifiers of the payments that are
+	// currently sent to their payment lifecycle.
+	activePayments *lnutils.SyncMap[lntypes.Hash, *paymentLifecycle]
+
 	// channelEdgeMtx is a mutex we use to make sure we process only one
 	// ChannelEdgePolicy at a time for a given channelID, to ensure
 	// consistency between the various database accesses.
@@ -527,6 +572,9 @@ func New(cfg Config) (*ChannelRouter, error) {
 		statTicker:        ticker.New(defaultStatInterval),
 		stats:             new(routerStats),
 		quit:              make(chan struct{}),
+		activePayments: &lnutils.SyncMap[
+			lntypes.Hash, *paymentLifecycle,
+		]{},
 	}
 
 	return r, nil
@@ -581,7 +629,7 @@ func (r *ChannelRouter) Start() error {
 			}
 
 			log.Info("Initial zombie prune starting")
-			if err := r.pruneZombieChans(); err != nil {
+			if _, err := r.pruneZombieChans(); err != nil {
 				log.Errorf("Unable to prune zombies: %v", err)
 			}
 		})
@@ -707,14 +755,27 @@ func (r *ChannelRouter) Start() error {
 			// result for the in-flight attempt is received.
 			paySession := r.cfg.SessionSource.NewPaymentSessionEmpty()
 
+			// Report the results to the mission control namespace
+			// that the payment was sent with.
+			mc, err := r.namespacedMissionControl(
+				payment.Info.MissionControlNamespace,
+			)
+			if err != nil {
+				log.Errorf("Unable to get mission control "+
+					"namespace %v of payment %v, using "+
+					"default: %v",
+					payment.Info.MissionControlNamespace,
+					payment.Info.PaymentIdentifier, err)
+			}
+
 			// We pass in a zero timeout value, to indicate we
 			// don't need it to timeout. It will stop immediately
 			// after the existing attempt has finished anyway. We
 			// also set a zero fee limit, as no more routes should
 			// be tried.
-			_, _, err := r.sendPayment(
+			_, _, err = r.sendPayment(
 				0, payment.Info.PaymentIdentifier, 0,
-				paySession, shardTracker,
+				paySession, shardTracker, mc,
 			)
 			if err != nil {
 				log.Errorf("Resuming payment %v failed: %v.",
@@ -730,6 +791,11 @@ func (r *ChannelRouter) Start() error {
 	r.wg.Add(1)
 	go r.networkHandler()
 
+	if r.cfg.StalePaymentTimeout > 0 {
+		r.wg.Add(1)
+		go r.stalePaymentSweeper()
+	}
+
 	return nil
 }
 
@@ -891,6 +957,7 @@ func (r *ChannelRouter) syncGraphWithChain() error {
 	if err != nil {
 		return err
 	}
+	r.invalidateRouteCache(closedChans...)
 
 	log.Infof("Graph pruning complete: %v channels were closed since "+
 		"height %v", len(closedChans), pruneHeight)
@@ -951,8 +1018,9 @@ func (r *ChannelRouter) IsZombieChannel(updateTime1,
 // been updated since our zombie horizon. If AssumeChannelValid is present,
 // we'll also consider channels zombies if *both* edges are disabled. This
 // usually signals that a channel has been closed on-chain. We do this
-// periodically to keep a healthy, lively routing table.
-func (r *ChannelRouter) pruneZombieChans() error {
+// periodically to keep a healthy, lively routing table. The IDs of the pruned
+// channels are returned.
+func (r *ChannelRouter) pruneZombieChans() ([]uint64, error) {
 	chansToPrune := make(map[uint64]struct{})
 	chanExpiry := r.cfg.ChannelPruneExpiry
 
@@ -1017,16 +1085,16 @@ func (r *ChannelRouter) pruneZombieChans() error {
 	if r.cfg.AssumeChannelValid {
 		disabledChanIDs, err := r.cfg.Graph.DisabledChannelIDs()
 		if err != nil {
-			return fmt.Errorf("unable to get disabled channels "+
-				"ids chans: %v", err)
+			return nil, fmt.Errorf("unable to get disabled "+
+				"channels ids chans: %v", err)
 		}
 
 		disabledEdges, err := r.cfg.Graph.FetchChanInfos(
 			nil, disabledChanIDs,
 		)
 		if err != nil {
-			return fmt.Errorf("unable to fetch disabled channels "+
-				"edges chans: %v", err)
+			return nil, fmt.Errorf("unable to fetch disabled "+
+				"channels edges chans: %v", err)
 		}
 
 		// Ensuring we won't prune our own channel from the graph.
@@ -1042,8 +1110,8 @@ func (r *ChannelRouter) pruneZombieChans() error {
 	endTime := time.Now().Add(-1 * chanExpiry)
 	oldEdges, err := r.cfg.Graph.ChanUpdatesInHorizon(startTime, endTime)
 	if err != nil {
-		return fmt.Errorf("unable to fetch expired channel updates "+
-			"chans: %v", err)
+		return nil, fmt.Errorf("unable to fetch expired channel "+
+			"updates chans: %v", err)
 	}
 
 	for _, u := range oldEdges {
@@ -1052,7 +1120,7 @@ func (r *ChannelRouter) pruneZombieChans() error {
 
 	log.Infof("Pruning %v zombie channels", len(chansToPrune))
 	if len(chansToPrune) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// With the set of zombie-like channels obtained, we'll do another pass
@@ -1065,20 +1133,180 @@ func (r *ChannelRouter) pruneZombieChans() error {
 	err = r.cfg.Graph.DeleteChannelEdges(
 		r.cfg.StrictZombiePruning, true, toPrune...,
 	)
-	if err != nil {
-		return fmt.Errorf("unable to delete zombie channels: %w", err)
+	switch {
+	// A channel may have been removed from the graph in the meantime, for
+	// example because it was closed. In that case, we delete the channels
+	// one by one and skip the ones that are gone.
+	case errors.Is(err, channeldb.ErrEdgeNotFound):
+		log.Debugf("Zombie channel vanished from the graph, pruning " +
+			"channels individually")
+
+		toPrune, err = r.deleteZombieChans(toPrune)
+		if err != nil {
+			return nil, err
+		}
+
+	case err != nil:
+		return nil, fmt.Errorf("unable to delete zombie channels: %w",
+			err)
+	}
+	if r.cfg.RouteCache != nil {
+		r.cfg.RouteCache.InvalidateChannels(toPrune...)
 	}
 
 	// With the channels pruned, we'll also attempt to prune any nodes that
 	// were a part of them.
 	err = r.cfg.Graph.PruneGraphNodes()
 	if err != nil && err != channeldb.ErrGraphNodesNotFound {
-		return fmt.Errorf("unable to prune graph nodes: %w", err)
+		return nil, fmt.Errorf("unable to prune graph nodes: %w", err)
+	}
+
+	return toPrune, nil
+}
+
+// deleteZombieChans deletes the given zombie channels from the graph one by
+// one, skipping the ones that aren't part of the graph anymore. It returns the
+// IDs of the channels that were deleted.
+func (r *ChannelRouter) deleteZombieChans(chanIDs []uint64) ([]uint64, error) {
+	deleted := make([]uint64, 0, len(chanIDs))
+	for _, chanID := range chanIDs {
+		err := r.cfg.Graph.DeleteChannelEdges(
+			r.cfg.StrictZombiePruning, true, chanID,
+		)
+		switch {
+		case errors.Is(err, channeldb.ErrEdgeNotFound):
+			continue
+
+		case err != nil:
+			return nil, fmt.Errorf("unable to delete zombie "+
+				"channel %v: %w", chanID, err)
+		}
+
+		deleted = append(deleted, chanID)
+	}
+
+	return deleted, nil
+}
+
+// ZombieChannel is a channel that is held in the zombie index of the graph.
+// Updates for such a channel are ignored and it isn't used for path finding.
+type ZombieChannel struct {
+	// ChannelID is the short channel ID of the channel.
+	ChannelID uint64
+
+	// Node1 and Node2 are the nodes of the channel. They may be zero if
+	// the channel was marked as a zombie without knowing its nodes.
+	Node1 route.Vertex
+	Node2 route.Vertex
+}
+
+// ZombieChannels returns all channels of the zombie index, sorted by their
+// channel ID.
+func (r *ChannelRouter) ZombieChannels() ([]ZombieChannel, error) {
+	var zombies []ZombieChannel
+	err := r.cfg.Graph.ForEachZombieEdge(func(chanID uint64, pubKey1,
+		pubKey2 [33]byte) error {
+
+		zombies = append(zombies, ZombieChannel{
+			ChannelID: chanID,
+			Node1:     pubKey1,
+			Node2:     pubKey2,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return zombies, nil
+}
+
+// ResurrectZombieChannel removes a channel from the zombie index. If the
+// channel was removed from the graph when it became a zombie, it is added back
+// from the information stored in the zombie index, after making sure that its
+// funding output is still unspent. The channel becomes available for path
+// finding once its policies are received again. Channels without stored
+// information are added back the next time their announcement is received.
+func (r *ChannelRouter) ResurrectZombieChannel(chanID uint64) error {
+	info, err := r.cfg.Graph.FetchZombieEdgeInfo(chanID)
+	switch {
+	case errors.Is(err, channeldb.ErrEdgeNotFound):
+		info = nil
+
+	case err != nil:
+		return err
+	}
+
+	var fundingPkScript []byte
+	if info != nil && !r.cfg.AssumeChannelValid {
+		fundingPkScript, err = makeFundingScript(
+			info.BitcoinKey1Bytes[:], info.BitcoinKey2Bytes[:],
+			info.Features,
+		)
+		if err != nil {
+			return err
+		}
+
+		// A closed channel must not be resurrected, as we wouldn't
+		// learn about its closure anymore.
+		scid := lnwire.NewShortChanIDFromInt(chanID)
+		_, err := r.cfg.Chain.GetUtxo(
+			&info.ChannelPoint, fundingPkScript, scid.BlockHeight,
+			r.quit,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch utxo for "+
+				"chan_id=%v, chan_point=%v: %w", chanID,
+				info.ChannelPoint, err)
+		}
+	}
+
+	if err := r.cfg.Graph.MarkEdgeLive(chanID); err != nil {
+		return err
 	}
 
+	if info == nil {
+		log.Infof("Resurrected zombie channel with ChannelID(%v), "+
+			"waiting for its announcement", chanID)
+
+		return nil
+	}
+
+	err = r.cfg.Graph.AddChannelEdge(info)
+	if err != nil && !errors.Is(err, channeldb.ErrEdgeAlreadyExist) {
+		return fmt.Errorf("unable to add edge: %w", err)
+	}
+
+	// Make sure that we are notified when the channel is closed.
+	if fundingPkScript != nil {
+		filterUpdate := []channeldb.EdgePoint{
+			{
+				FundingPkScript: fundingPkScript,
+				OutPoint:        info.ChannelPoint,
+			},
+		}
+		err = r.cfg.ChainView.UpdateFilter(
+			filterUpdate, atomic.LoadUint32(&r.bestHeight),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to update chain view: %w",
+				err)
+		}
+	}
+
+	log.Infof("Resurrected zombie channel with ChannelID(%v)", chanID)
+
 	return nil
 }
 
+// PruneZombieChannels examines the graph for zombie channels right away
+// instead of waiting for the next prune interval. It returns the IDs of the
+// channels that were pruned.
+func (r *ChannelRouter) PruneZombieChannels() ([]uint64, error) {
+	return r.pruneZombieChans()
+}
+
 // handleNetworkUpdate is responsible for processing the update message and
 // notifies topology changes, if any.
 //
@@ -1313,7 +1541,7 @@ func (r *ChannelRouter) networkHandler() {
 		// state of the known graph to filter out any zombie channels
 		// for pruning.
 		case <-graphPruneTicker.C:
-			if err := r.pruneZombieChans(); err != nil {
+			if _, err := r.pruneZombieChans(); err != nil {
 				log.Errorf("Unable to prune zombies: %v", err)
 			}
 
@@ -1431,6 +1659,7 @@ func (r *ChannelRouter) updateGraphWithClosedChannels(
 		log.Errorf("unable to prune routing table: %v", err)
 		return err
 	}
+	r.invalidateRouteCache(chansClosed...)
 
 	log.Infof("Block %v (height=%v) closed %v channels", chainUpdate.Hash,
 		blockHeight, len(chansClosed))
@@ -1484,6 +1713,22 @@ func (r *ChannelRouter) assertNodeAnnFreshness(node route.Vertex,
 	return nil
 }
 
+// invalidateRouteCache removes all cached paths that traverse one of the given
+// channels from the route cache, if there is one.
+func (r *ChannelRouter) invalidateRouteCache(
+	edges ...*models.ChannelEdgeInfo) {
+
+	if r.cfg.RouteCache == nil || len(edges) == 0 {
+		return
+	}
+
+	chanIDs := make([]uint64, 0, len(edges))
+	for _, edge := range edges {
+		chanIDs = append(chanIDs, edge.ChannelID)
+	}
+	r.cfg.RouteCache.InvalidateChannels(chanIDs...)
+}
+
 // addZombieEdge adds a channel that failed complete validation into the zombie
 // index so we can avoid having to re-validate it in the future.
 func (r *ChannelRouter) addZombieEdge(chanID uint64) error {
@@ -1835,6 +2080,12 @@ func (r *ChannelRouter) processUpdate(msg interface{},
 			return err
 		}
 
+		// Cached paths that traverse this channel were computed with
+		// the previous policy, so they can't be used anymore.
+		if r.cfg.RouteCache != nil {
+			r.cfg.RouteCache.InvalidateChannels(msg.ChannelID)
+		}
+
 		log.Tracef("New channel update applied: %v",
 			newLogClosure(func() string { return spew.Sdump(msg) }))
 		r.stats.incNumChannelUpdates()
@@ -2241,10 +2492,25 @@ type LightningPayment struct {
 	// if there isn't a route with lower fees than this limit.
 	FeeLimit lnwire.MilliSatoshi
 
+	// FeeLimitPolicy optionally expresses the fee limit relative to the
+	// amount. If set, FeeLimit is derived from it when the payment is
+	// prepared.
+	FeeLimitPolicy *FeeLimitPolicy
+
 	// CltvLimit is the maximum time lock that is allowed for attempts to
 	// complete this payment.
 	CltvLimit uint32
 
+	// MaxTotalCltv is the maximum time lock that the hops of a route are
+	// allowed to add on top of the final cltv delta. If zero, only the
+	// CltvLimit applies.
+	MaxTotalCltv uint32
+
+	// MaxHops is the maximum number of hops of the routes that are tried
+	// for this payment. If zero, the number of hops is only limited by the
+	// size of the onion.
+	MaxHops uint32
+
 	// paymentHash is the r-hash value to use within the HTLC extended to
 	// the first hop. This won't be set for AMP payments.
 	paymentHash *lntypes.Hash
@@ -2280,10 +2546,50 @@ type LightningPayment struct {
 	// first hop. If nil, any channel may be used.
 	OutgoingChannelIDs []uint64
 
+	// OutgoingChannelWeights optionally expresses the preference for the
+	// outgoing channels as a weight in the range (0, 1]. Channels with a
+	// higher weight are favored for the first hop.
+	OutgoingChannelWeights map[uint64]float64
+
+	// LocalBalanceAware makes path finding favor outgoing channels with a
+	// larger local balance relative to the amount of the attempt.
+	LocalBalanceAware bool
+
+	// MinChannelCapacity excludes network channels with a smaller capacity
+	// from path finding.
+	MinChannelCapacity btcutil.Amount
+
+	// LatencyPenalty is the cost per second of historical htlc resolution
+	// latency that path finding assigns to each node pair of the route.
+	// Payers with a deadline can use it to favor fast routes over cheap
+	// ones. If zero, latency is ignored.
+	LatencyPenalty lnwire.MilliSatoshi
+
 	// LastHop is the pubkey of the last node before the final destination
 	// is reached. If nil, any node may be used.
 	LastHop *route.Vertex
 
+	// LastHops is an ordered list of acceptable last nodes before the final
+	// destination is reached. Unless JointLastHops is set, path finding
+	// tries the candidates one after the other and only moves on to the
+	// next one if there is no path through the previous one. LastHop and
+	// LastHops are mutually exclusive.
+	LastHops []route.Vertex
+
+	// JointLastHops indicates that path finding considers all candidates
+	// in LastHops at once and picks the best path through any of them.
+	JointLastHops bool
+
+	// RoutePrefix is an ordered list of nodes that every route must
+	// traverse directly after our own node, for example to route through
+	// a specific peer and one of its peers. Path finding completes the
+	// routes from the last prefix node to the destination.
+	RoutePrefix []route.Vertex
+
+	// ShardSplitter is an optional shard splitter that overrides the
+	// default one for this payment.
+	ShardSplitter ShardSplitter
+
 	// DestFeatures specifies the set of features we assume the final node
 	// has for pathfinding. Typically these will be taken directly from an
 	// invoice, but they can also be manually supplied or assumed by the
@@ -2327,6 +2633,37 @@ type LightningPayment struct {
 	// Metadata is additional data that is sent along with the payment to
 	// the payee.
 	Metadata []byte
+
+	// Estimator is an optional probability estimator that is used for
+	// this payment instead of the one mission control is configured with.
+	Estimator Estimator
+
+	// MissionControl is an optional mission control instance that is
+	// used for this payment instead of the router's default one. This
+	// allows keeping the results of different kinds of payments isolated
+	// from each other.
+	//
+	// NOTE: MissionControlNamespace must be set to the namespace of the
+	// instance, so that the results of attempts that are resumed after a
+	// restart are reported to the same instance.
+	MissionControl MissionController
+
+	// MissionControlNamespace is the namespace of MissionControl. It is
+	// persisted with the payment.
+	MissionControlNamespace string
+
+	// PathFindingTimeout is the maximum duration of a single route search
+	// for this payment. A zero value means that route searches aren't
+	// time limited.
+	PathFindingTimeout time.Duration
+
+	// IgnoredNodes is an optional set of nodes that path finding won't
+	// route through for this payment.
+	IgnoredNodes map[route.Vertex]struct{}
+
+	// IgnoredPairs is an optional set of directed node pairs that path
+	// finding won't use for this payment.
+	IgnoredPairs map[DirectedNodePair]struct{}
 }
 
 // AMPOptions houses information that must be known in order to send an AMP
@@ -2392,6 +2729,7 @@ func (r *ChannelRouter) SendPayment(payment *LightningPayment) ([32]byte,
 	return r.sendPayment(
 		payment.FeeLimit, payment.Identifier(),
 		payment.PayAttemptTimeout, paySession, shardTracker,
+		payment.MissionControl,
 	)
 }
 
@@ -2412,6 +2750,7 @@ func (r *ChannelRouter) SendPaymentAsync(payment *LightningPayment,
 		_, _, err := r.sendPayment(
 			payment.FeeLimit, payment.Identifier(),
 			payment.PayAttemptTimeout, ps, st,
+			payment.MissionControl,
 		)
 		if err != nil {
 			log.Errorf("Payment %x failed: %v",
@@ -2420,6 +2759,150 @@ func (r *ChannelRouter) SendPaymentAsync(payment *LightningPayment,
 	}()
 }
 
+// CancelPayment stops the payment with the given identifier from launching new
+// HTLC attempts. The attempts that are still in flight are left to resolve,
+// after which the payment is failed with FailureReasonCanceled. A payment
+// that already has a settled HTLC can't be canceled anymore, because the
+// receiver revealed the preimage and will settle the remaining HTLCs too.
+func (r *ChannelRouter) CancelPayment(identifier lntypes.Hash) error {
+	// If the payment is currently sent, its lifecycle cancels it, so that
+	// no attempt is launched after the payment was canceled and the
+	// results of the attempts in flight are still collected.
+	if p, ok := r.activePayments.Load(identifier); ok {
+		err := p.cancel()
+		if !errors.Is(err, ErrPaymentLifecycleExiting) {
+			return err
+		}
+	}
+
+	log.Infof("Canceling payment %v", identifier)
+
+	// Otherwise, marking the payment as failed prevents a resumed payment
+	// lifecycle from registering new attempts. The check whether the
+	// payment can still be canceled is done atomically with the update.
+	return r.cfg.Control.CancelPayment(identifier)
+}
+
+// AbandonStalePayments fails all payments that were created more than
+// minAge ago and are still in flight, but have no outstanding HTLCs. Such
+// payments can't make progress on their own anymore, for example because
+// their payment lifecycle exited before the payment was resolved. Payments
+// that are currently sent by the router are left alone. The identifiers of
+// the failed payments are returned.
+func (r *ChannelRouter) AbandonStalePayments(
+	minAge time.Duration) ([]lntypes.Hash, error) {
+
+	payments, err := r.cfg.Control.FetchInFlightPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.cfg.Clock.Now()
+
+	var abandoned []lntypes.Hash
+	for _, payment := range payments {
+		identifier := payment.Info.PaymentIdentifier
+
+		if now.Sub(payment.Info.CreationTime) < minAge {
+			continue
+		}
+
+		if payment.GetStatus() != channeldb.StatusInFlight &&
+			payment.GetStatus() != channeldb.StatusInitiated {
+
+			continue
+		}
+
+		if len(payment.InFlightHTLCs()) > 0 {
+			continue
+		}
+
+		if _, ok := r.activePayments.Load(identifier); ok {
+			continue
+		}
+
+		// Without any HTLCs in flight, failing the payment moves it
+		// to the failed state right away. As an attempt may have been
+		// registered since we fetched the payment, the control tower
+		// checks again while holding the payment's lock.
+		err := r.cfg.Control.FailIdlePayment(
+			identifier, channeldb.FailureReasonTimeout,
+		)
+		switch {
+		case errors.Is(err, channeldb.ErrPaymentHTLCsInFlight):
+			log.Debugf("Not abandoning payment %v with HTLCs in "+
+				"flight", identifier)
+
+			continue
+
+		case err != nil:
+			log.Errorf("Unable to abandon payment %v: %v",
+				identifier, err)
+
+			continue
+		}
+
+		log.Infof("Abandoned stale payment %v created at %v",
+			identifier, payment.Info.CreationTime)
+
+		// Clean up the failed attempts of the payment, unless the
+		// user asked to keep them.
+		err = r.cfg.Control.DeleteFailedAttempts(identifier)
+		if err != nil {
+			log.Errorf("Unable to delete failed attempts of "+
+				"payment %v: %v", identifier, err)
+		}
+
+		abandoned = append(abandoned, identifier)
+	}
+
+	return abandoned, nil
+}
+
+// stalePaymentSweeper periodically abandons payments that are stuck in
+// flight.
+//
+// NOTE: This MUST be run as a goroutine.
+func (r *ChannelRouter) stalePaymentSweeper() {
+	defer r.wg.Done()
+
+	sweepTicker := time.NewTicker(stalePaymentSweepInterval)
+	defer sweepTicker.Stop()
+
+	for {
+		select {
+		case <-sweepTicker.C:
+			_, err := r.AbandonStalePayments(
+				r.cfg.StalePaymentTimeout,
+			)
+			if err != nil {
+				log.Errorf("Unable to abandon stale payments: "+
+					"%v", err)
+			}
+
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// UpdatePaymentLimits adjusts the limits of the payment with the given
+// identifier that is currently sent. The new limits are applied before the
+// next HTLC attempt is launched. Attempts that are already in flight are not
+// affected.
+func (r *ChannelRouter) UpdatePaymentLimits(identifier lntypes.Hash,
+	limits *PaymentLimits) error {
+
+	p, ok := r.activePayments.Load(identifier)
+	if !ok {
+		return ErrPaymentNotActive
+	}
+
+	p.updateLimits(limits)
+
+	return nil
+}
+
 // spewPayment returns a log closures that provides a spewed string
 // representation of the passed payment.
 func spewPayment(payment *LightningPayment) logClosure {
@@ -2441,11 +2924,76 @@ func spewPayment(payment *LightningPayment) logClosure {
 	})
 }
 
+// checkDestCustomRecords verifies that the custom records of a payment fit into
+// the onion of a route that only consists of the final hop. This is the
+// shortest possible route, longer routes leave less room for the records,
+// which path finding takes into account.
+func (r *ChannelRouter) checkDestCustomRecords(
+	payment *LightningPayment) error {
+
+	if len(payment.DestCustomRecords) == 0 {
+		return nil
+	}
+
+	_, height, err := r.cfg.Chain.GetBestBlock()
+	if err != nil {
+		return err
+	}
+
+	var mpp *record.MPP
+	if payment.PaymentAddr != nil {
+		mpp = record.NewMPP(payment.Amount, *payment.PaymentAddr)
+	}
+
+	// Like in path finding, the size of the AMP record is all that
+	// matters, so we use the AMP record dummy.
+	var amp *record.AMP
+	if payment.amp != nil {
+		amp = &record.MaxAmpPayLoadSize
+	}
+
+	finalHop := &route.Hop{
+		AmtToForward: payment.Amount,
+		OutgoingTimeLock: uint32(height) +
+			uint32(payment.FinalCLTVDelta),
+		CustomRecords: payment.DestCustomRecords,
+		MPP:           mpp,
+		AMP:           amp,
+		Metadata:      payment.Metadata,
+	}
+
+	rt := &route.Route{Hops: []*route.Hop{finalHop}}
+
+	return rt.CheckPayloadSize()
+}
+
 // PreparePayment creates the payment session and registers the payment with the
 // control tower.
 func (r *ChannelRouter) PreparePayment(payment *LightningPayment) (
 	PaymentSession, shards.ShardTracker, error) {
 
+	// Resolve a relative fee limit now that the amount of the payment is
+	// known.
+	if payment.FeeLimitPolicy != nil {
+		if err := payment.FeeLimitPolicy.Validate(); err != nil {
+			return nil, nil, err
+		}
+
+		payment.FeeLimit = payment.FeeLimitPolicy.FeeLimit(
+			payment.Amount,
+		)
+
+		log.Debugf("Fee limit of payment %x resolved to %v (%v)",
+			payment.Identifier(), payment.FeeLimit,
+			payment.FeeLimitPolicy)
+	}
+
+	// Reject custom records that can't be delivered before the payment is
+	// registered.
+	if err := r.checkDestCustomRecords(payment); err != nil {
+		return nil, nil, err
+	}
+
 	// Before starting the HTLC routing attempt, we'll create a fresh
 	// payment session which will report our errors back to mission
 	// control.
@@ -2463,6 +3011,8 @@ func (r *ChannelRouter) PreparePayment(payment *LightningPayment) (
 		Value:             payment.Amount,
 		CreationTime:      r.cfg.Clock.Now(),
 		PaymentRequest:    payment.PaymentRequest,
+
+		MissionControlNamespace: payment.MissionControlNamespace,
 	}
 
 	// Create a new ShardTracker that we'll use during the life cycle of
@@ -2497,7 +3047,7 @@ func (r *ChannelRouter) PreparePayment(payment *LightningPayment) (
 func (r *ChannelRouter) SendToRoute(htlcHash lntypes.Hash,
 	rt *route.Route) (*channeldb.HTLCAttempt, error) {
 
-	return r.sendToRoute(htlcHash, rt, false)
+	return r.sendToRoute(htlcHash, rt, false, "")
 }
 
 // SendToRouteSkipTempErr sends a payment using the provided route and fails
@@ -2505,7 +3055,36 @@ func (r *ChannelRouter) SendToRoute(htlcHash lntypes.Hash,
 func (r *ChannelRouter) SendToRouteSkipTempErr(htlcHash lntypes.Hash,
 	rt *route.Route) (*channeldb.HTLCAttempt, error) {
 
-	return r.sendToRoute(htlcHash, rt, true)
+	return r.sendToRoute(htlcHash, rt, true, "")
+}
+
+// SendToRouteWithMissionControl behaves like SendToRoute, or like
+// SendToRouteSkipTempErr if skipTempErr is true, but reports the result of the
+// attempt to the mission control instance of the given namespace instead of
+// the router's default one.
+func (r *ChannelRouter) SendToRouteWithMissionControl(htlcHash lntypes.Hash,
+	rt *route.Route, namespace string,
+	skipTempErr bool) (*channeldb.HTLCAttempt, error) {
+
+	return r.sendToRoute(htlcHash, rt, skipTempErr, namespace)
+}
+
+// namespacedMissionControl returns the mission control instance of the given
+// namespace. Nil is returned for the default namespace, which makes the
+// payment lifecycle use the router's default mission control.
+func (r *ChannelRouter) namespacedMissionControl(
+	namespace string) (MissionController, error) {
+
+	if namespace == "" || namespace == DefaultMissionControlNamespace {
+		return nil, nil
+	}
+
+	if r.cfg.GetMissionControl == nil {
+		return nil, fmt.Errorf("mission control namespaces not " +
+			"supported")
+	}
+
+	return r.cfg.GetMissionControl(namespace)
 }
 
 // sendToRoute attempts to send a payment with the given hash through the
@@ -2513,9 +3092,16 @@ func (r *ChannelRouter) SendToRouteSkipTempErr(htlcHash lntypes.Hash,
 // information as it is stored in the database. For a successful htlc, this
 // information will contain the preimage. If an error occurs after the attempt
 // was initiated, both return values will be non-nil. If skipTempErr is true,
-// the payment won't be failed unless a terminal error has occurred.
+// the payment won't be failed unless a terminal error has occurred. The result
+// is reported to the mission control instance of the given namespace, which
+// is persisted with the payment.
 func (r *ChannelRouter) sendToRoute(htlcHash lntypes.Hash, rt *route.Route,
-	skipTempErr bool) (*channeldb.HTLCAttempt, error) {
+	skipTempErr bool, namespace string) (*channeldb.HTLCAttempt, error) {
+
+	mc, err := r.namespacedMissionControl(namespace)
+	if err != nil {
+		return nil, err
+	}
 
 	// Calculate amount paid to receiver.
 	amt := rt.ReceiverAmt()
@@ -2535,6 +3121,13 @@ func (r *ChannelRouter) sendToRoute(htlcHash lntypes.Hash, rt *route.Route,
 		return nil, ErrSkipTempErr
 	}
 
+	// Make sure that the payloads fit into the onion before the payment is
+	// registered, so that oversized custom records are reported right
+	// away instead of failing the onion construction.
+	if err := rt.CheckPayloadSize(); err != nil {
+		return nil, err
+	}
+
 	// For non-AMP payments the overall payment identifier will be the same
 	// hash as used for this HTLC.
 	paymentIdentifier := htlcHash
@@ -2553,9 +3146,11 @@ func (r *ChannelRouter) sendToRoute(htlcHash lntypes.Hash, rt *route.Route,
 		Value:             amt,
 		CreationTime:      r.cfg.Clock.Now(),
 		PaymentRequest:    nil,
+
+		MissionControlNamespace: namespace,
 	}
 
-	err := r.cfg.Control.InitPayment(paymentIdentifier, info)
+	err = r.cfg.Control.InitPayment(paymentIdentifier, info)
 	switch {
 	// If this is an MPP attempt and the hash is already registered with
 	// the database, we can go on to launch the shard.
@@ -2588,6 +3183,9 @@ func (r *ChannelRouter) sendToRoute(htlcHash lntypes.Hash, rt *route.Route,
 	p := newPaymentLifecycle(
 		r, 0, paymentIdentifier, nil, shardTracker, 0, 0,
 	)
+	if mc != nil {
+		p.missionControl = mc
+	}
 
 	// We found a route to try, create a new HTLC attempt to try.
 	//
@@ -2679,11 +3277,12 @@ func (r *ChannelRouter) sendToRoute(htlcHash lntypes.Hash, rt *route.Route,
 // This method relies on the ControlTower's internal payment state machine to
 // carry out its execution. After restarts it is safe, and assumed, that the
 // router will call this method for every payment still in-flight according to
-// the ControlTower.
+// the ControlTower. If mc is nil, the results of the attempts are reported to
+// the router's default mission control.
 func (r *ChannelRouter) sendPayment(feeLimit lnwire.MilliSatoshi,
 	identifier lntypes.Hash, timeout time.Duration,
-	paySession PaymentSession,
-	shardTracker shards.ShardTracker) ([32]byte, *route.Route, error) {
+	paySession PaymentSession, shardTracker shards.ShardTracker,
+	mc MissionController) ([32]byte, *route.Route, error) {
 
 	// We'll also fetch the current block height so we can properly
 	// calculate the required HTLC time locks within the route.
@@ -2698,6 +3297,14 @@ func (r *ChannelRouter) sendPayment(feeLimit lnwire.MilliSatoshi,
 		r, feeLimit, identifier, paySession,
 		shardTracker, timeout, currentHeight,
 	)
+	if mc != nil {
+		p.missionControl = mc
+	}
+
+	// Make the lifecycle available to limit updates while the payment is
+	// in flight.
+	r.activePayments.Store(identifier, p)
+	defer r.activePayments.Delete(identifier)
 
 	return p.resumePayment()
 }
@@ -3060,6 +3667,20 @@ func (e ErrNoChannel) Error() string {
 		"node %v (%v)", e.position, e.fromNode)
 }
 
+// ErrHopChannel is returned when a route cannot be built because a channel
+// that was pinned for a hop can't carry the amount.
+type ErrHopChannel struct {
+	position int
+	chanID   uint64
+	reason   string
+}
+
+// Error returns a human readable string describing the error.
+func (e ErrHopChannel) Error() string {
+	return fmt.Sprintf("channel %v at position %v can't be used: %v",
+		lnwire.NewShortChanIDFromInt(e.chanID), e.position, e.reason)
+}
+
 // BuildRoute returns a fully specified route based on a list of pubkeys. If
 // amount is nil, the minimum routable amount is used. To force a specific
 // outgoing channel, use the outgoingChan parameter.
@@ -3077,6 +3698,84 @@ func (r *ChannelRouter) BuildRoute(amt *lnwire.MilliSatoshi,
 		}
 	}
 
+	return r.buildRoute(
+		amt, hops, nil, outgoingChans, finalCltvDelta, payAddr,
+	)
+}
+
+// BuildRouteFromChannels returns a fully specified route that uses exactly the
+// given channels, starting with a channel of our own node. If amount is nil,
+// the minimum routable amount is used. If the policy of one of the channels
+// doesn't allow to forward the amount, an ErrHopChannel is returned.
+func (r *ChannelRouter) BuildRouteFromChannels(amt *lnwire.MilliSatoshi,
+	chanIDs []uint64, finalCltvDelta int32,
+	payAddr *[32]byte) (*route.Route, error) {
+
+	log.Tracef("BuildRouteFromChannels called: chanIDs=%v, amt=%v",
+		chanIDs, amt)
+
+	hops, err := resolveHopChannels(
+		r.selfNode.PubKeyBytes, chanIDs, r.cachedGraph,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.buildRoute(
+		amt, hops, chanIDs, nil, finalCltvDelta, payAddr,
+	)
+}
+
+// resolveHopChannels walks the given channels starting at the source node and
+// returns the node that each of the channels leads to.
+func resolveHopChannels(source route.Vertex, chanIDs []uint64,
+	graph routingGraph) ([]route.Vertex, error) {
+
+	hops := make([]route.Vertex, 0, len(chanIDs))
+	fromNode := source
+	for i, chanID := range chanIDs {
+		var (
+			toNode route.Vertex
+			found  bool
+		)
+		err := graph.forEachNodeChannel(fromNode,
+			func(channel *channeldb.DirectedChannel) error {
+				if channel.ChannelID == chanID {
+					toNode = channel.OtherNode
+					found = true
+				}
+
+				return nil
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			return nil, ErrHopChannel{
+				position: i,
+				chanID:   chanID,
+				reason: fmt.Sprintf("not a channel of node "+
+					"%v", fromNode),
+			}
+		}
+
+		hops = append(hops, toNode)
+		fromNode = toNode
+	}
+
+	return hops, nil
+}
+
+// buildRoute returns a fully specified route along the given hops. If hopChans
+// is set, the hop at every position is restricted to the channel at the same
+// position.
+func (r *ChannelRouter) buildRoute(amt *lnwire.MilliSatoshi,
+	hops []route.Vertex, hopChans []uint64,
+	outgoingChans map[uint64]struct{}, finalCltvDelta int32,
+	payAddr *[32]byte) (*route.Route, error) {
+
 	// If no amount is specified, we need to build a route for the minimum
 	// amount that this route can carry.
 	useMinAmt := amt == nil
@@ -3112,8 +3811,8 @@ func (r *ChannelRouter) BuildRoute(amt *lnwire.MilliSatoshi,
 
 	sourceNode := r.selfNode.PubKeyBytes
 	unifiers, senderAmt, err := getRouteUnifiers(
-		sourceNode, hops, useMinAmt, runningAmt, outgoingChans,
-		r.cachedGraph, bandwidthHints,
+		sourceNode, hops, hopChans, useMinAmt, runningAmt,
+		outgoingChans, r.cachedGraph, bandwidthHints,
 	)
 	if err != nil {
 		return nil, err
@@ -3121,6 +3820,7 @@ func (r *ChannelRouter) BuildRoute(amt *lnwire.MilliSatoshi,
 
 	pathEdges, receiverAmt, err := getPathEdges(
 		sourceNode, senderAmt, unifiers, bandwidthHints, hops,
+		hopChans,
 	)
 	if err != nil {
 		return nil, err
@@ -3139,9 +3839,11 @@ func (r *ChannelRouter) BuildRoute(amt *lnwire.MilliSatoshi,
 	)
 }
 
-// getRouteUnifiers returns a list of edge unifiers for the given route.
+// getRouteUnifiers returns a list of edge unifiers for the given route. If
+// hopChans is set, the edge unifier of every hop only contains the channel at
+// the same position.
 func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
-	useMinAmt bool, runningAmt lnwire.MilliSatoshi,
+	hopChans []uint64, useMinAmt bool, runningAmt lnwire.MilliSatoshi,
 	outgoingChans map[uint64]struct{}, graph routingGraph,
 	bandwidthHints *bandwidthManager) ([]*edgeUnifier, lnwire.MilliSatoshi,
 	error) {
@@ -3177,8 +3879,20 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 
 		// Exit if there are no channels.
 		edgeUnifier, ok := u.edgeUnifiers[fromNode]
+		if ok && hopChans != nil {
+			ok = edgeUnifier.restrictToChannel(hopChans[i])
+		}
 		if !ok {
 			log.Errorf("Cannot find policy for node %v", fromNode)
+
+			if hopChans != nil {
+				return nil, 0, ErrHopChannel{
+					position: i,
+					chanID:   hopChans[i],
+					reason:   "no policy known",
+				}
+			}
+
 			return nil, 0, ErrNoChannel{
 				fromNode: fromNode,
 				position: i,
@@ -3199,6 +3913,16 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 			log.Errorf("Cannot find policy with amt=%v for node %v",
 				runningAmt, fromNode)
 
+			if hopChans != nil {
+				return nil, 0, ErrHopChannel{
+					position: i,
+					chanID:   hopChans[i],
+					reason: edgeUnifier.unusableReason(
+						runningAmt, bandwidthHints,
+					),
+				}
+			}
+
 			return nil, 0, ErrNoChannel{
 				fromNode: fromNode,
 				position: i,
@@ -3220,10 +3944,11 @@ func getRouteUnifiers(source route.Vertex, hops []route.Vertex,
 }
 
 // getPathEdges returns the edges that make up the path and the total amount,
-// including fees, to send the payment.
+// including fees, to send the payment. If hopChans is set, the channels that
+// the unifiers are restricted to are reported on failure.
 func getPathEdges(source route.Vertex, receiverAmt lnwire.MilliSatoshi,
 	unifiers []*edgeUnifier, bandwidthHints *bandwidthManager,
-	hops []route.Vertex) ([]*unifiedEdge,
+	hops []route.Vertex, hopChans []uint64) ([]*unifiedEdge,
 	lnwire.MilliSatoshi, error) {
 
 	// Now that we arrived at the start of the route and found out the route
@@ -3233,6 +3958,15 @@ func getPathEdges(source route.Vertex, receiverAmt lnwire.MilliSatoshi,
 	var pathEdges []*unifiedEdge
 	for i, unifier := range unifiers {
 		edge := unifier.getEdge(receiverAmt, bandwidthHints, 0)
+		if edge == nil && hopChans != nil {
+			return nil, 0, ErrHopChannel{
+				position: i,
+				chanID:   hopChans[i],
+				reason: unifier.unusableReason(
+					receiverAmt, bandwidthHints,
+				),
+			}
+		}
 		if edge == nil {
 			fromNode := source
 			if i > 0 {
@@ -25,6 +25,8 @@ import (
 	lnmock "github.com/lightningnetwork/lnd/lntest/mock"
 	"github.com/lightningnetwork/lnd/lntest/wait"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnutils"
+	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/record"
 	"github.com/lightningnetwork/lnd/routing/route"
@@ -2262,7 +2264,7 @@ func TestPruneChannelGraphStaleEdges(t *testing.T) {
 		assertChannelsPruned(t, ctx.graph, testChannels)
 
 		// Proceed to prune the channels - only the last one should be pruned.
-		if err := ctx.router.pruneZombieChans(); err != nil {
+		if _, err := ctx.router.pruneZombieChans(); err != nil {
 			t.Fatalf("unable to prune zombie channels: %v", err)
 		}
 
@@ -2278,6 +2280,72 @@ func TestPruneChannelGraphStaleEdges(t *testing.T) {
 	}
 }
 
+// TestResurrectZombieChannel tests that a zombie channel that was pruned from
+// the graph is added back when it is resurrected, unless its funding output
+// was spent.
+func TestResurrectZombieChannel(t *testing.T) {
+	t.Parallel()
+
+	staleTimestamp := time.Unix(0, 0)
+	testChannels := []*testChannel{
+		symmetricTestChannel("a", "b", 100000, &testChannelPolicy{
+			LastUpdate: time.Now(),
+		}, 1),
+		symmetricTestChannel("e", "f", 100000, &testChannelPolicy{
+			LastUpdate: staleTimestamp,
+		}, 2),
+	}
+
+	testGraph, err := createTestGraphFromChannels(
+		t, true, testChannels, "a",
+	)
+	require.NoError(t, err)
+
+	ctx := createTestCtxFromGraphInstance(t, 100, testGraph, false)
+
+	pruned, err := ctx.router.PruneZombieChannels()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, pruned)
+	assertChannelsPruned(t, ctx.graph, testChannels, 2)
+
+	// As long as the funding output is unknown to the chain, the channel
+	// can't be resurrected.
+	err = ctx.router.ResurrectZombieChannel(2)
+	require.ErrorIs(t, err, btcwallet.ErrOutputSpent)
+
+	_, _, _, isZombie, err := ctx.graph.HasChannelEdge(2)
+	require.NoError(t, err)
+	require.True(t, isZombie)
+
+	// Once the funding output is found, the channel is added back to the
+	// graph with its original information.
+	var fundingHash chainhash.Hash
+	fundingHash[len(fundingHash)-1] = 2
+	fundingPoint := wire.OutPoint{Hash: fundingHash}
+	ctx.chain.addUtxo(fundingPoint, &wire.TxOut{Value: 100000})
+
+	require.NoError(t, ctx.router.ResurrectZombieChannel(2))
+	assertChannelsPruned(t, ctx.graph, testChannels)
+
+	info, _, _, err := ctx.graph.FetchChannelEdgesByID(2)
+	require.NoError(t, err)
+	require.Equal(t, fundingPoint, info.ChannelPoint)
+
+	_, _, _, isZombie, err = ctx.graph.HasChannelEdge(2)
+	require.NoError(t, err)
+	require.False(t, isZombie)
+
+	// The channel is no zombie anymore.
+	err = ctx.router.ResurrectZombieChannel(2)
+	require.ErrorIs(t, err, channeldb.ErrZombieEdgeNotFound)
+
+	// Channels that vanished from the graph before they are pruned are
+	// skipped.
+	deleted, err := ctx.router.deleteZombieChans([]uint64{2, 3})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2}, deleted)
+}
+
 // TestPruneChannelGraphDoubleDisabled test that we can properly prune channels
 // with both edges disabled from our channel graph.
 func TestPruneChannelGraphDoubleDisabled(t *testing.T) {
@@ -2399,7 +2467,7 @@ func testPruneChannelGraphDoubleDisabled(t *testing.T, assumeValid bool) {
 		assertChannelsPruned(t, ctx.graph, testChannels, prunedChannel)
 	}
 
-	if err := ctx.router.pruneZombieChans(); err != nil {
+	if _, err := ctx.router.pruneZombieChans(); err != nil {
 		t.Fatalf("unable to prune zombie channels: %v", err)
 	}
 
@@ -3009,6 +3077,56 @@ func TestSendToRouteMaxHops(t *testing.T) {
 	}
 }
 
+// TestSendToRouteCustomRecordsTooLarge asserts that SendToRoute rejects
+// custom records that don't fit into the onion before the payment is
+// registered.
+func TestSendToRouteCustomRecordsTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// Setup a two node network.
+	chanCapSat := btcutil.Amount(100000)
+	testChannels := []*testChannel{
+		symmetricTestChannel("a", "b", chanCapSat, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+			MinHTLC: 1,
+			MaxHTLC: lnwire.NewMSatFromSatoshis(chanCapSat),
+		}, 1),
+	}
+
+	testGraph, err := createTestGraphFromChannels(t, true, testChannels, "a")
+	require.NoError(t, err, "unable to create graph")
+
+	const startingBlockHeight = 101
+
+	ctx := createTestCtxFromGraphInstance(
+		t, startingBlockHeight, testGraph, false,
+	)
+
+	// Create a single hop route with custom records that exceed the onion.
+	const payAmt = lnwire.MilliSatoshi(10000)
+	hops := []*route.Hop{{
+		ChannelID:        1,
+		PubKeyBytes:      ctx.aliases["b"],
+		AmtToForward:     payAmt,
+		OutgoingTimeLock: 200,
+		CustomRecords: record.CustomSet{
+			65536: make([]byte, 1400),
+		},
+	}}
+
+	rt, err := route.NewRouteFromHops(payAmt, 200, ctx.aliases["a"], hops)
+	require.NoError(t, err, "unable to create route")
+
+	var payHash lntypes.Hash
+	_, err = ctx.router.SendToRoute(payHash, rt)
+
+	var recordsErr route.ErrCustomRecordsTooLarge
+	require.ErrorAs(t, err, &recordsErr)
+	require.Equal(t, 1, recordsErr.NumHops)
+	require.Less(t, recordsErr.Available, recordsErr.RecordsSize)
+}
+
 // TestBuildRoute tests whether correct routes are built.
 func TestBuildRoute(t *testing.T) {
 	// Setup a three node network.
@@ -3163,6 +3281,35 @@ func TestBuildRoute(t *testing.T) {
 	if errNoChannel.fromNode != ctx.aliases["a"] {
 		t.Fatalf("unexpected no channel error node")
 	}
+
+	// Pin the channels of the route. The channels that would have been
+	// selected otherwise must not be used. The total amount is the amount
+	// to deliver plus the fee of channel 2 (5 sats).
+	rt, err = ctx.router.BuildRouteFromChannels(
+		&amt, []uint64{6, 2}, 40, &payAddr,
+	)
+	require.NoError(t, err)
+	checkHops(rt, []uint64{6, 2}, payAddr)
+	require.EqualValues(t, 105000, rt.TotalAmount)
+
+	// A channel that isn't a channel of the previous hop is rejected.
+	_, err = ctx.router.BuildRouteFromChannels(
+		&amt, []uint64{6, 4}, 40, nil,
+	)
+	var errHopChannel ErrHopChannel
+	require.ErrorAs(t, err, &errHopChannel)
+	require.Equal(t, 1, errHopChannel.position)
+	require.EqualValues(t, 4, errHopChannel.chanID)
+
+	// A channel whose policy can't carry the amount is reported with the
+	// reason. Channel 5 can't carry the amount plus the fee of channel 4.
+	_, err = ctx.router.BuildRouteFromChannels(
+		&amt, []uint64{5, 4}, 40, nil,
+	)
+	require.ErrorAs(t, err, &errHopChannel)
+	require.Equal(t, 0, errHopChannel.position)
+	require.EqualValues(t, 5, errHopChannel.chanID)
+	require.Contains(t, errHopChannel.reason, "exceeds max_htlc")
 }
 
 // TestGetPathEdges tests that the getPathEdges function returns the expected
@@ -3198,7 +3345,7 @@ func TestGetPathEdges(t *testing.T) {
 	for _, tc := range testCases {
 		pathEdges, amt, err := getPathEdges(
 			tc.sourceNode, tc.amt, tc.unifiers, tc.bandwidthHints,
-			tc.hops,
+			tc.hops, nil,
 		)
 
 		if tc.expectedErr != "" {
@@ -3518,7 +3665,7 @@ func TestSendToRouteSkipTempErrSuccess(t *testing.T) {
 	})
 
 	missionControl.On("ReportPaymentSuccess",
-		mock.Anything, rt,
+		mock.Anything, rt, mock.Anything,
 	).Return(nil)
 
 	// Mock the control tower to return the mocked payment.
@@ -3540,6 +3687,110 @@ func TestSendToRouteSkipTempErrSuccess(t *testing.T) {
 	payment.AssertExpectations(t)
 }
 
+// TestSendToRouteWithMissionControl checks that the result of a send to route
+// is reported to the mission control namespace of the request and that the
+// namespace is persisted with the payment.
+func TestSendToRouteWithMissionControl(t *testing.T) {
+	t.Parallel()
+
+	var (
+		payHash   lntypes.Hash
+		payAmt    = lnwire.MilliSatoshi(10000)
+		namespace = "rebalance"
+	)
+
+	preimage := lntypes.Preimage{1}
+	testAttempt := makeSettledAttempt(t, int(payAmt), preimage)
+
+	node, err := createTestNode()
+	require.NoError(t, err)
+
+	// Create a simple 1-hop route.
+	hops := []*route.Hop{
+		{
+			ChannelID:        1,
+			PubKeyBytes:      node.PubKeyBytes,
+			AmtToForward:     payAmt,
+			OutgoingTimeLock: 120,
+		},
+	}
+	rt, err := route.NewRouteFromHops(payAmt, 100, node.PubKeyBytes, hops)
+	require.NoError(t, err)
+
+	// Create mockers. The default mission control has no expectations,
+	// so any result reported to it fails the test.
+	controlTower := &mockControlTower{}
+	payer := &mockPaymentAttemptDispatcher{}
+	defaultMc := &mockMissionControl{}
+	namespacedMc := &mockMissionControl{}
+
+	// Create the router.
+	router := &ChannelRouter{cfg: &Config{
+		Control:        controlTower,
+		Payer:          payer,
+		MissionControl: defaultMc,
+		GetMissionControl: func(ns string) (MissionController, error) {
+			require.Equal(t, namespace, ns)
+
+			return namespacedMc, nil
+		},
+		Clock: clock.NewTestClock(time.Unix(1, 0)),
+		NextPaymentID: func() (uint64, error) {
+			return 0, nil
+		},
+	}}
+
+	// Register mockers with the expected method calls.
+	hasNamespace := func(info *channeldb.PaymentCreationInfo) bool {
+		return info.MissionControlNamespace == namespace
+	}
+	controlTower.On(
+		"InitPayment", payHash, mock.MatchedBy(hasNamespace),
+	).Return(nil)
+	controlTower.On("RegisterAttempt", payHash, mock.Anything).Return(nil)
+	controlTower.On("SettleAttempt",
+		payHash, mock.Anything, mock.Anything,
+	).Return(testAttempt, nil)
+
+	payer.On("SendHTLC",
+		mock.Anything, mock.Anything, mock.Anything,
+	).Return(nil)
+
+	// Create a buffered chan and it will be returned by GetAttemptResult.
+	resultChan := make(chan *htlcswitch.PaymentResult, 1)
+	payer.On("GetAttemptResult",
+		mock.Anything, mock.Anything, mock.Anything,
+	).Return(resultChan, nil).Run(func(_ mock.Arguments) {
+		// Send a successful payment result.
+		resultChan <- &htlcswitch.PaymentResult{}
+	})
+
+	namespacedMc.On("ReportPaymentSuccess",
+		mock.Anything, rt, mock.Anything,
+	).Return(nil)
+
+	// Mock the control tower to return the mocked payment.
+	payment := &mockMPPayment{}
+	controlTower.On("FetchPayment", payHash).Return(payment, nil).Once()
+
+	// Mock the payment to return nil failure reason.
+	payment.On("TerminalInfo").Return(nil, nil)
+
+	// Expect a successful send to route.
+	attempt, err := router.SendToRouteWithMissionControl(
+		payHash, rt, namespace, false,
+	)
+	require.NoError(t, err)
+	require.Equal(t, testAttempt, attempt)
+
+	// Assert the above methods are called as expected.
+	controlTower.AssertExpectations(t)
+	payer.AssertExpectations(t)
+	defaultMc.AssertExpectations(t)
+	namespacedMc.AssertExpectations(t)
+	payment.AssertExpectations(t)
+}
+
 // TestSendToRouteSkipTempErrNonMPP checks that an error is return when
 // skipping temp error for non-MPP.
 func TestSendToRouteSkipTempErrNonMPP(t *testing.T) {
@@ -3996,3 +4247,164 @@ func TestNewRouteRequest(t *testing.T) {
 		})
 	}
 }
+
+// TestCancelPayment tests that a canceled payment stops launching new attempts
+// and fails once its attempts in flight resolved.
+func TestCancelPayment(t *testing.T) {
+	t.Parallel()
+
+	db, err := initDB(t, false)
+	require.NoError(t, err)
+
+	control := NewControlTower(channeldb.NewPaymentControl(db))
+	router := &ChannelRouter{
+		cfg: &Config{
+			Control: control,
+		},
+		activePayments: &lnutils.SyncMap[
+			lntypes.Hash, *paymentLifecycle,
+		]{},
+	}
+
+	// Canceling an unknown payment fails.
+	info, attempt, preimg, err := genInfo()
+	require.NoError(t, err)
+
+	err = router.CancelPayment(info.PaymentIdentifier)
+	require.ErrorIs(t, err, channeldb.ErrPaymentNotInitiated)
+
+	// Initiate a payment with one attempt in flight and cancel it.
+	require.NoError(t, control.InitPayment(info.PaymentIdentifier, info))
+	require.NoError(t, control.RegisterAttempt(
+		info.PaymentIdentifier, attempt,
+	))
+	require.NoError(t, router.CancelPayment(info.PaymentIdentifier))
+
+	// The payment stays in flight until the attempt resolved, but no new
+	// attempts can be registered and it can't be canceled again.
+	payment, err := control.FetchPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusInFlight, payment.GetStatus())
+
+	err = router.CancelPayment(info.PaymentIdentifier)
+	require.ErrorIs(t, err, channeldb.ErrPaymentPendingFailed)
+
+	attempt2 := *attempt
+	attempt2.AttemptID = 2
+	err = control.RegisterAttempt(info.PaymentIdentifier, &attempt2)
+	require.ErrorIs(t, err, channeldb.ErrPaymentPendingFailed)
+
+	// Once the attempt failed, the payment is failed with the cancel
+	// reason.
+	_, err = control.FailAttempt(
+		info.PaymentIdentifier, attempt.AttemptID,
+		&channeldb.HTLCFailInfo{
+			Reason: channeldb.HTLCFailUnreadable,
+		},
+	)
+	require.NoError(t, err)
+
+	payment, err = control.FetchPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusFailed, payment.GetStatus())
+
+	_, reason := payment.TerminalInfo()
+	require.Equal(t, channeldb.FailureReasonCanceled, *reason)
+
+	err = router.CancelPayment(info.PaymentIdentifier)
+	require.ErrorIs(t, err, channeldb.ErrPaymentAlreadyFailed)
+
+	// A payment with a settled attempt can't be canceled.
+	info, attempt, preimg, err = genInfo()
+	require.NoError(t, err)
+	require.NoError(t, control.InitPayment(info.PaymentIdentifier, info))
+	require.NoError(t, control.RegisterAttempt(
+		info.PaymentIdentifier, attempt,
+	))
+	_, err = control.SettleAttempt(
+		info.PaymentIdentifier, attempt.AttemptID,
+		&channeldb.HTLCSettleInfo{Preimage: preimg},
+	)
+	require.NoError(t, err)
+
+	err = router.CancelPayment(info.PaymentIdentifier)
+	require.ErrorIs(t, err, channeldb.ErrPaymentAlreadySucceeded)
+}
+
+// TestAbandonStalePayments tests that payments that are in flight without any
+// outstanding HTLCs are failed once they are old enough.
+func TestAbandonStalePayments(t *testing.T) {
+	t.Parallel()
+
+	db, err := initDB(t, false)
+	require.NoError(t, err)
+
+	control := NewControlTower(channeldb.NewPaymentControl(db))
+	testClock := clock.NewTestClock(time.Now())
+	router := &ChannelRouter{
+		cfg: &Config{
+			Control: control,
+			Clock:   testClock,
+		},
+		activePayments: &lnutils.SyncMap[
+			lntypes.Hash, *paymentLifecycle,
+		]{},
+	}
+
+	// Create a payment without any attempts, one with an attempt in
+	// flight and one that is currently sent by the router.
+	initPayment := func() (*channeldb.PaymentCreationInfo,
+		*channeldb.HTLCAttemptInfo) {
+
+		info, attempt, _, err := genInfo()
+		require.NoError(t, err)
+		require.NoError(t, control.InitPayment(
+			info.PaymentIdentifier, info,
+		))
+
+		return info, attempt
+	}
+
+	stale, _ := initPayment()
+
+	inFlight, attempt := initPayment()
+	require.NoError(t, control.RegisterAttempt(
+		inFlight.PaymentIdentifier, attempt,
+	))
+
+	active, _ := initPayment()
+	router.activePayments.Store(
+		active.PaymentIdentifier, &paymentLifecycle{},
+	)
+
+	// The payments are too young to be abandoned.
+	abandoned, err := router.AbandonStalePayments(time.Hour)
+	require.NoError(t, err)
+	require.Empty(t, abandoned)
+
+	// Once they are old enough, only the stale payment is failed.
+	testClock.SetTime(time.Now().Add(2 * time.Hour))
+
+	abandoned, err = router.AbandonStalePayments(time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, []lntypes.Hash{stale.PaymentIdentifier}, abandoned)
+
+	payment, err := control.FetchPayment(stale.PaymentIdentifier)
+	require.NoError(t, err)
+	require.Equal(t, channeldb.StatusFailed, payment.GetStatus())
+
+	for _, info := range []*channeldb.PaymentCreationInfo{
+		inFlight, active,
+	} {
+		payment, err := control.FetchPayment(info.PaymentIdentifier)
+		require.NoError(t, err)
+		require.NotEqual(t, channeldb.StatusFailed, payment.GetStatus())
+	}
+
+	// A payment that got an attempt registered after it was fetched must
+	// not be failed either.
+	err = control.FailIdlePayment(
+		inFlight.PaymentIdentifier, channeldb.FailureReasonTimeout,
+	)
+	require.ErrorIs(t, err, channeldb.ErrPaymentHTLCsInFlight)
+}
@@ -0,0 +1,254 @@
+package routing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+const (
+	// HalvingSplitterName is the name of the shard splitter that halves
+	// the amount repeatedly.
+	HalvingSplitterName = "halving"
+
+	// CapacitySplitterName is the name of the shard splitter that takes
+	// the local channel balances into account.
+	CapacitySplitterName = "capacity"
+
+	// ProbabilitySplitterName is the name of the shard splitter that
+	// maximizes the expected amount delivered by a shard.
+	ProbabilitySplitterName = "probability"
+
+	// DefaultSplitter is the default shard splitter.
+	DefaultSplitter = HalvingSplitterName
+)
+
+// SplitParams holds the information that a shard splitter can base its
+// decision on.
+type SplitParams struct {
+	// MinShardAmt is the smallest amount that may be returned.
+	MinShardAmt lnwire.MilliSatoshi
+
+	// MaxAmounts is the maximum number of amounts that may be returned.
+	MaxAmounts int
+
+	// LocalBandwidth returns the available bandwidth of the local
+	// channels that the payment may use, sorted in descending order. It
+	// is a function, because querying the bandwidth requires a routing
+	// graph that not all splitters need.
+	LocalBandwidth func() []lnwire.MilliSatoshi
+}
+
+// ShardCandidate is a shard for which a path was found.
+type ShardCandidate struct {
+	// Amt is the amount of the shard.
+	Amt lnwire.MilliSatoshi
+
+	// Probability is the success probability of the path found for it.
+	Probability float64
+}
+
+// ShardSplitter decides how a payment is split into shards once no path can
+// be found for the full amount of a payment attempt.
+type ShardSplitter interface {
+	// SplitAmounts returns the amounts to search paths for after path
+	// finding failed for the given amount, in descending order. An empty
+	// result means that the amount isn't split any further.
+	SplitAmounts(amt lnwire.MilliSatoshi,
+		params SplitParams) []lnwire.MilliSatoshi
+
+	// SelectShard returns the index of the shard to send out next. The
+	// candidates are sorted by amount in descending order and there is at
+	// least one of them.
+	SelectShard(candidates []ShardCandidate) int
+
+	// String returns the name of the splitter.
+	String() string
+}
+
+// NewShardSplitter returns the shard splitter with the given name.
+func NewShardSplitter(name string) (ShardSplitter, error) {
+	switch name {
+	case HalvingSplitterName:
+		return &HalvingSplitter{}, nil
+
+	case CapacitySplitterName:
+		return &CapacitySplitter{}, nil
+
+	case ProbabilitySplitterName:
+		return &ProbabilitySplitter{}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown shard splitter %v", name)
+	}
+}
+
+// halvedAmounts halves the amount repeatedly until either the minimum shard
+// amount or the maximum number of amounts is reached.
+func halvedAmounts(amt lnwire.MilliSatoshi,
+	params SplitParams) []lnwire.MilliSatoshi {
+
+	var amts []lnwire.MilliSatoshi
+	for len(amts) < max(params.MaxAmounts, 1) {
+		amt /= 2
+		if amt < params.MinShardAmt {
+			break
+		}
+
+		amts = append(amts, amt)
+	}
+
+	return amts
+}
+
+// HalvingSplitter halves the amount of a payment attempt repeatedly and sends
+// out the largest shard for which a path is found.
+type HalvingSplitter struct{}
+
+// A compile time assertion to ensure HalvingSplitter meets the ShardSplitter
+// interface.
+var _ ShardSplitter = (*HalvingSplitter)(nil)
+
+// SplitAmounts returns the amounts to search paths for after path finding
+// failed for the given amount.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *HalvingSplitter) SplitAmounts(amt lnwire.MilliSatoshi,
+	params SplitParams) []lnwire.MilliSatoshi {
+
+	return halvedAmounts(amt, params)
+}
+
+// SelectShard returns the index of the shard to send out next.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *HalvingSplitter) SelectShard(_ []ShardCandidate) int {
+	return 0
+}
+
+// String returns the name of the splitter.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *HalvingSplitter) String() string {
+	return HalvingSplitterName
+}
+
+// CapacitySplitter tries shard amounts that match the available balances of
+// the local channels in addition to the halved amounts. This avoids shards
+// that are just a bit too large for the channel with the largest balance, so
+// that fewer shards are needed if the local liquidity is the bottleneck.
+type CapacitySplitter struct{}
+
+// A compile time assertion to ensure CapacitySplitter meets the ShardSplitter
+// interface.
+var _ ShardSplitter = (*CapacitySplitter)(nil)
+
+// SplitAmounts returns the amounts to search paths for after path finding
+// failed for the given amount.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *CapacitySplitter) SplitAmounts(amt lnwire.MilliSatoshi,
+	params SplitParams) []lnwire.MilliSatoshi {
+
+	candidates := make(map[lnwire.MilliSatoshi]struct{})
+	for _, bandwidth := range params.LocalBandwidth() {
+		if bandwidth < amt && bandwidth >= params.MinShardAmt {
+			candidates[bandwidth] = struct{}{}
+		}
+	}
+	for _, halved := range halvedAmounts(amt, params) {
+		candidates[halved] = struct{}{}
+	}
+
+	amts := make([]lnwire.MilliSatoshi, 0, len(candidates))
+	for candidate := range candidates {
+		amts = append(amts, candidate)
+	}
+	sort.Slice(amts, func(i, j int) bool {
+		return amts[i] > amts[j]
+	})
+
+	if len(amts) > max(params.MaxAmounts, 1) {
+		amts = amts[:max(params.MaxAmounts, 1)]
+	}
+
+	return amts
+}
+
+// SelectShard returns the index of the shard to send out next.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *CapacitySplitter) SelectShard(_ []ShardCandidate) int {
+	return 0
+}
+
+// String returns the name of the splitter.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *CapacitySplitter) String() string {
+	return CapacitySplitterName
+}
+
+// ProbabilitySplitter tries evenly spaced fractions of the amount and sends
+// out the shard with the highest expected amount to be delivered, which is
+// the product of the amount and the success probability of its path. Because
+// the success probability of a path usually drops with the amount, this
+// trades off larger shards against shards that are more likely to succeed.
+type ProbabilitySplitter struct{}
+
+// A compile time assertion to ensure ProbabilitySplitter meets the
+// ShardSplitter interface.
+var _ ShardSplitter = (*ProbabilitySplitter)(nil)
+
+// SplitAmounts returns the amounts to search paths for after path finding
+// failed for the given amount.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *ProbabilitySplitter) SplitAmounts(amt lnwire.MilliSatoshi,
+	params SplitParams) []lnwire.MilliSatoshi {
+
+	// With n amounts, the fractions n/(n+1), ..., 1/(n+1) of the amount
+	// are tried. A single amount falls back to halving.
+	n := max(params.MaxAmounts, 1)
+
+	var amts []lnwire.MilliSatoshi
+	for i := n; i > 0; i-- {
+		shardAmt := amt * lnwire.MilliSatoshi(i) /
+			lnwire.MilliSatoshi(n+1)
+
+		if shardAmt < params.MinShardAmt {
+			break
+		}
+
+		amts = append(amts, shardAmt)
+	}
+
+	return amts
+}
+
+// SelectShard returns the index of the shard to send out next.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *ProbabilitySplitter) SelectShard(candidates []ShardCandidate) int {
+	var (
+		best         int
+		bestExpected float64
+	)
+	for i, candidate := range candidates {
+		expected := float64(candidate.Amt) * candidate.Probability
+		if expected > bestExpected {
+			best = i
+			bestExpected = expected
+		}
+	}
+
+	return best
+}
+
+// String returns the name of the splitter.
+//
+// NOTE: This is part of the ShardSplitter interface.
+func (s *ProbabilitySplitter) String() string {
+	return ProbabilitySplitterName
+}
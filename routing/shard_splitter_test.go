@@ -0,0 +1,113 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShardSplitters tests the amounts returned by the shard splitters.
+func TestShardSplitters(t *testing.T) {
+	t.Parallel()
+
+	params := SplitParams{
+		MinShardAmt: 100,
+		MaxAmounts:  3,
+		LocalBandwidth: func() []lnwire.MilliSatoshi {
+			return []lnwire.MilliSatoshi{5000, 900, 500, 50}
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		splitter ShardSplitter
+		amt      lnwire.MilliSatoshi
+		expected []lnwire.MilliSatoshi
+	}{
+		{
+			name:     "halving",
+			splitter: &HalvingSplitter{},
+			amt:      1000,
+			expected: []lnwire.MilliSatoshi{500, 250, 125},
+		},
+		{
+			name:     "halving min shard amount",
+			splitter: &HalvingSplitter{},
+			amt:      300,
+			expected: []lnwire.MilliSatoshi{150},
+		},
+		{
+			// The balances that are lower than the amount are
+			// tried along with the halved amounts.
+			name:     "capacity",
+			splitter: &CapacitySplitter{},
+			amt:      1000,
+			expected: []lnwire.MilliSatoshi{900, 500, 250},
+		},
+		{
+			name:     "capacity min shard amount",
+			splitter: &CapacitySplitter{},
+			amt:      200,
+			expected: []lnwire.MilliSatoshi{100},
+		},
+		{
+			name:     "probability",
+			splitter: &ProbabilitySplitter{},
+			amt:      1000,
+			expected: []lnwire.MilliSatoshi{750, 500, 250},
+		},
+		{
+			name:     "probability min shard amount",
+			splitter: &ProbabilitySplitter{},
+			amt:      200,
+			expected: []lnwire.MilliSatoshi{150, 100},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			amts := tc.splitter.SplitAmounts(tc.amt, params)
+			require.Equal(t, tc.expected, amts)
+		})
+	}
+}
+
+// TestShardSplitterSelect tests the selection of the shard to send out.
+func TestShardSplitterSelect(t *testing.T) {
+	t.Parallel()
+
+	candidates := []ShardCandidate{
+		{Amt: 750, Probability: 0.2},
+		{Amt: 500, Probability: 0.5},
+		{Amt: 250, Probability: 0.9},
+	}
+
+	// The halving and capacity splitters send out the largest shard.
+	require.Zero(t, (&HalvingSplitter{}).SelectShard(candidates))
+	require.Zero(t, (&CapacitySplitter{}).SelectShard(candidates))
+
+	// The probability splitter maximizes the expected delivered amount.
+	require.Equal(t, 1, (&ProbabilitySplitter{}).SelectShard(candidates))
+}
+
+// TestNewShardSplitter tests that shard splitters are created by name.
+func TestNewShardSplitter(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{
+		HalvingSplitterName, CapacitySplitterName,
+		ProbabilitySplitterName,
+	} {
+		splitter, err := NewShardSplitter(name)
+		require.NoError(t, err)
+		require.Equal(t, name, splitter.String())
+	}
+
+	_, err := NewShardSplitter("unknown")
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package routing
 
 import (
+	"fmt"
 	"math"
 
 	"github.com/btcsuite/btcd/btcutil"
@@ -29,6 +30,17 @@ type nodeEdgeUnifier struct {
 	// outChanRestr is an optional outgoing channel restriction for the
 	// local channel to use.
 	outChanRestr map[uint64]struct{}
+
+	// outChanWeights is an optional set of weights that expresses the
+	// preference for the local channels to use.
+	outChanWeights map[uint64]float64
+
+	// minCapacity is an optional capacity floor for network channels.
+	minCapacity btcutil.Amount
+
+	// stats optionally collects the channels that are skipped because of
+	// the capacity floor.
+	stats *PathFindingStats
 }
 
 // newNodeEdgeUnifier instantiates a new nodeEdgeUnifier object. Channel
@@ -62,12 +74,22 @@ func (u *nodeEdgeUnifier) addPolicy(fromNode route.Vertex,
 		}
 	}
 
+	// Skip network channels that are too small. Channels of unknown
+	// capacity are kept, because we can't tell whether they are small.
+	if !localChan && capacity > 0 && capacity < u.minCapacity {
+		u.stats.prune(PruneMinCapacity)
+		return
+	}
+
 	// Update the edgeUnifiers map.
 	unifier, ok := u.edgeUnifiers[fromNode]
 	if !ok {
 		unifier = &edgeUnifier{
 			localChan: localChan,
 		}
+		if localChan {
+			unifier.outChanWeights = u.outChanWeights
+		}
 		u.edgeUnifiers[fromNode] = unifier
 	}
 
@@ -175,6 +197,11 @@ func (u *unifiedEdge) amtInRange(amt lnwire.MilliSatoshi) bool {
 type edgeUnifier struct {
 	edges     []*unifiedEdge
 	localChan bool
+
+	// outChanWeights is an optional set of weights for local channels.
+	// Local channels with a higher weight are picked over channels with a
+	// lower weight, regardless of their bandwidth.
+	outChanWeights map[uint64]float64
 }
 
 // getEdge returns the optimal unified edge to use for this connection given a
@@ -219,6 +246,7 @@ func (u *edgeUnifier) getEdgeLocal(netAmtReceived lnwire.MilliSatoshi,
 	var (
 		bestEdge     *unifiedEdge
 		maxBandwidth lnwire.MilliSatoshi
+		maxWeight    float64
 	)
 
 	for _, edge := range u.edges {
@@ -278,10 +306,27 @@ func (u *edgeUnifier) getEdgeLocal(netAmtReceived lnwire.MilliSatoshi,
 			continue
 		}
 
-		// We pick the local channel with the highest available
-		// bandwidth, to maximize the success probability. It can be
-		// that the channel state changes between querying the bandwidth
-		// hints and sending out the htlc.
+		// If the payer expressed a preference for its outgoing
+		// channels, we pick the channel with the highest weight.
+		weight := u.outChanWeight(edge.policy.ChannelID)
+		switch {
+		case weight < maxWeight:
+			log.Debugf("Skipped edge %v: not max weight, "+
+				"weight=%v, maxWeight=%v",
+				edge.policy.ChannelID, weight, maxWeight)
+
+			continue
+
+		case weight > maxWeight:
+			maxWeight = weight
+			maxBandwidth = 0
+		}
+
+		// Among channels of equal weight, we pick the local channel
+		// with the highest available bandwidth, to maximize the
+		// success probability. It can be that the channel state
+		// changes between querying the bandwidth hints and sending
+		// out the htlc.
 		if bandwidth < maxBandwidth {
 			log.Debugf("Skipped edge %v: not max bandwidth, "+
 				"bandwidth=%v, maxBandwidth=%v",
@@ -303,6 +348,16 @@ func (u *edgeUnifier) getEdgeLocal(netAmtReceived lnwire.MilliSatoshi,
 	return bestEdge
 }
 
+// outChanWeight returns the weight of the given local channel. Channels
+// without an explicit weight have a weight of one.
+func (u *edgeUnifier) outChanWeight(chanID uint64) float64 {
+	if weight, ok := u.outChanWeights[chanID]; ok {
+		return weight
+	}
+
+	return 1
+}
+
 // getEdgeNetwork returns the optimal unified edge to use for this connection
 // given a specific amount to send. The goal is to return a unified edge with a
 // policy that maximizes the probability of a successful forward in a non-strict
@@ -415,6 +470,69 @@ func (u *edgeUnifier) getEdgeNetwork(netAmtReceived lnwire.MilliSatoshi,
 	return &modifiedEdge
 }
 
+// restrictToChannel removes all edges except the one of the given channel. It
+// returns false if there is no edge for the channel.
+func (u *edgeUnifier) restrictToChannel(chanID uint64) bool {
+	for _, edge := range u.edges {
+		if edge.policy.ChannelID == chanID {
+			u.edges = []*unifiedEdge{edge}
+
+			return true
+		}
+	}
+
+	u.edges = nil
+
+	return false
+}
+
+// unusableReason returns why none of the edges can carry the given amount.
+// It is meant for unifiers that are restricted to a single channel, for which
+// the reason is precise.
+func (u *edgeUnifier) unusableReason(amt lnwire.MilliSatoshi,
+	bandwidthHints bandwidthHints) string {
+
+	if len(u.edges) != 1 {
+		return fmt.Sprintf("no channel can carry amount %v", amt)
+	}
+	edge := u.edges[0]
+	policy := edge.policy
+
+	switch {
+	case edge.capacity > 0 &&
+		amt > lnwire.NewMSatFromSatoshis(edge.capacity):
+
+		return fmt.Sprintf("amount %v exceeds capacity %v", amt,
+			edge.capacity)
+
+	case policy.MessageFlags.HasMaxHtlc() && amt > policy.MaxHTLC:
+		return fmt.Sprintf("amount %v exceeds max_htlc %v", amt,
+			policy.MaxHTLC)
+
+	case amt < policy.MinHTLC:
+		return fmt.Sprintf("amount %v is below min_htlc %v", amt,
+			policy.MinHTLC)
+	}
+
+	if !u.localChan {
+		if policy.ChannelFlags&lnwire.ChanUpdateDisabled != 0 {
+			return "channel is disabled"
+		}
+
+		return fmt.Sprintf("channel can't carry amount %v", amt)
+	}
+
+	bandwidth, ok := bandwidthHints.availableChanBandwidth(
+		policy.ChannelID, amt,
+	)
+	if ok && amt > bandwidth {
+		return fmt.Sprintf("amount %v exceeds local bandwidth %v", amt,
+			bandwidth)
+	}
+
+	return fmt.Sprintf("channel can't carry amount %v", amt)
+}
+
 // minAmt returns the minimum amount that can be forwarded on this connection.
 func (u *edgeUnifier) minAmt() lnwire.MilliSatoshi {
 	min := lnwire.MaxMilliSatoshi
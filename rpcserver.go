@@ -3,6 +3,7 @@ package lnd
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -43,6 +44,7 @@ import (
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/channeldb/models"
 	"github.com/lightningnetwork/lnd/channelnotifier"
+	"github.com/lightningnetwork/lnd/clock"
 	"github.com/lightningnetwork/lnd/contractcourt"
 	"github.com/lightningnetwork/lnd/discovery"
 	"github.com/lightningnetwork/lnd/feature"
@@ -72,6 +74,7 @@ import (
 	"github.com/lightningnetwork/lnd/peernotifier"
 	"github.com/lightningnetwork/lnd/record"
 	"github.com/lightningnetwork/lnd/routing"
+	"github.com/lightningnetwork/lnd/routing/localchans"
 	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/lightningnetwork/lnd/rpcperms"
 	"github.com/lightningnetwork/lnd/signal"
@@ -326,6 +329,14 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/lnrpc.Lightning/SetPeerChannelPolicy": {{
+			Entity: "peers",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/ListPeerChannelPolicies": {{
+			Entity: "peers",
+			Action: "read",
+		}},
 		"/lnrpc.Lightning/CloseChannel": {{
 			Entity: "onchain",
 			Action: "write",
@@ -333,6 +344,13 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/lnrpc.Lightning/BatchCloseChannel": {{
+			Entity: "onchain",
+			Action: "write",
+		}, {
+			Entity: "offchain",
+			Action: "write",
+		}},
 		"/lnrpc.Lightning/AbandonChannel": {{
 			Entity: "offchain",
 			Action: "write",
@@ -438,6 +456,14 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "info",
 			Action: "read",
 		}},
+		"/lnrpc.Lightning/StartGraphAnalysis": {{
+			Entity: "info",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/GetGraphAnalysis": {{
+			Entity: "info",
+			Action: "read",
+		}},
 		"/lnrpc.Lightning/GetChanInfo": {{
 			Entity: "info",
 			Action: "read",
@@ -474,6 +500,10 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/lnrpc.Lightning/PruneFailedPaymentAttempts": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
 		"/lnrpc.Lightning/DebugLevel": {{
 			Entity: "info",
 			Action: "write",
@@ -486,10 +516,38 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "offchain",
 			Action: "read",
 		}},
+		"/lnrpc.Lightning/PeerFeeReport": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 		"/lnrpc.Lightning/UpdateChannelPolicy": {{
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/lnrpc.Lightning/SchedulePolicyUpdate": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/ListScheduledPolicyUpdates": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/lnrpc.Lightning/CancelScheduledPolicyUpdate": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/UpdateCommitFeePolicy": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/SetChannelSweepBudget": {{
+			Entity: "onchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/ListChannelSweepBudgets": {{
+			Entity: "onchain",
+			Action: "read",
+		}},
 		"/lnrpc.Lightning/ForwardingHistory": {{
 			Entity: "offchain",
 			Action: "read",
@@ -606,6 +664,14 @@ type rpcServer struct {
 	// method.
 	chanPredicate chanacceptor.MultiplexAcceptor
 
+	// peerPolicies holds the per-peer inbound channel policies that can be
+	// managed with the SetPeerChannelPolicy method.
+	peerPolicies *chanacceptor.PeerPolicyAcceptor
+
+	// policyScheduler applies the channel policy updates that were
+	// scheduled with the SchedulePolicyUpdate method.
+	policyScheduler *localchans.Scheduler
+
 	quit chan struct{}
 
 	// macService is the macaroon service that we need to mint new
@@ -628,6 +694,9 @@ type rpcServer struct {
 	graphCache        sync.RWMutex
 	describeGraphResp *lnrpc.ChannelGraph
 	graphCacheEvictor *time.Timer
+
+	// graphAnalyzer runs graph analysis jobs in the background.
+	graphAnalyzer *graphAnalyzer
 }
 
 // A compile time check to ensure that rpcServer fully implements the
@@ -668,7 +737,8 @@ func newRPCServer(cfg *Config, interceptorChain *rpcperms.InterceptorChain,
 func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 	subServerCgs *subRPCServerConfigs, atpl *autopilot.Manager,
 	invoiceRegistry *invoices.InvoiceRegistry, tower *watchtower.Standalone,
-	chanPredicate chanacceptor.MultiplexAcceptor) error {
+	chanPredicate chanacceptor.MultiplexAcceptor,
+	peerPolicies *chanacceptor.PeerPolicyAcceptor) error {
 
 	// Set up router rpc backend.
 	selfNode, err := s.graphDB.SourceNode()
@@ -741,6 +811,21 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 		},
 		SetChannelAuto:     s.chanStatusMgr.RequestAuto,
 		UseStatusInitiated: subServerCgs.RouterRPC.UseStatusInitiated,
+		GetMissionControl: func(namespace string) (
+			routerrpc.MissionControl, error) {
+
+			mc, err := s.mcManager.GetNamespacedStore(namespace)
+			if err != nil {
+				return nil, err
+			}
+
+			return mc, nil
+		},
+		ListMissionControlNamespaces: s.mcManager.ListNamespaces,
+		SetMissionControlConfig:      s.mcManager.SetConfig,
+		NodeScores:                   s.nodeScores,
+		QueryPayments:                s.miscDB.QueryPayments,
+		DeletePayment:                s.miscDB.DeletePayment,
 	}
 
 	genInvoiceFeatures := func() *lnwire.FeatureVector {
@@ -772,6 +857,8 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 		genInvoiceFeatures, genAmpInvoiceFeatures,
 		s.getNodeAnnouncement, s.updateAndBrodcastSelfNode, parseAddr,
 		rpcsLog, s.aliasMgr.GetPeerAlias,
+		newHopHintScoring(r.cfg.Invoices), s.channelUptime,
+		s.checkSendScript,
 	)
 	if err != nil {
 		return err
@@ -834,12 +921,29 @@ func (r *rpcServer) addDeps(s *server, macService *macaroons.Service,
 		}
 	}
 
+	policyScheduler, err := localchans.NewScheduler(
+		&localchans.SchedulerConfig{
+			DB:          s.miscDB,
+			Clock:       clock.NewDefaultClock(),
+			ApplyUpdate: r.applyScheduledPolicyUpdate,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create policy update scheduler: "+
+			"%w", err)
+	}
+
 	// Finally, with all the set up complete, add the last dependencies to
 	// the rpc server.
 	r.server = s
 	r.subServers = subServers
 	r.routerBackend = routerBackend
+	r.graphAnalyzer = newGraphAnalyzer(
+		autopilot.ChannelGraphFromDatabase(graph), runtime.NumCPU(),
+	)
 	r.chanPredicate = chanPredicate
+	r.peerPolicies = peerPolicies
+	r.policyScheduler = policyScheduler
 	r.macService = macService
 	r.selfNode = selfNode.PubKeyBytes
 
@@ -973,6 +1077,10 @@ func (r *rpcServer) Stop() error {
 
 	close(r.quit)
 
+	// Wait for a running graph analysis to exit, as it reads from the
+	// graph database.
+	r.graphAnalyzer.stop()
+
 	// After we've signalled all of our active goroutines to exit, we'll
 	// then do the same to signal a graceful shutdown of all the sub
 	// servers.
@@ -1272,6 +1380,19 @@ func maybeUseDefaultConf(satPerByte int64, satPerVByte uint64,
 	return defaultNumBlocksEstimate
 }
 
+// checkSendOutputs returns a PermissionDenied error if any of the outputs pays
+// to a script that on-chain funds may not be sent to.
+func (r *rpcServer) checkSendOutputs(outputs []*wire.TxOut) error {
+	for _, output := range outputs {
+		err := r.server.checkSendScript(output.PkScript)
+		if err != nil {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+
+	return nil
+}
+
 // SendCoins executes a request to send coins to a particular address. Unlike
 // SendMany, this RPC call only allows creating a single output at a time.
 func (r *rpcServer) SendCoins(ctx context.Context,
@@ -1329,6 +1450,17 @@ func (r *rpcServer) SendCoins(ctx context.Context,
 		return nil, fmt.Errorf("cannot send coins to pubkeys")
 	}
 
+	// Sending coins is restricted to the allowlist of close addresses as
+	// well.
+	targetScript, err := txscript.PayToAddrScript(targetAddr)
+	if err != nil {
+		return nil, err
+	}
+	err = r.checkSendOutputs([]*wire.TxOut{{PkScript: targetScript}})
+	if err != nil {
+		return nil, err
+	}
+
 	label, err := labels.ValidateAPI(in.Label)
 	if err != nil {
 		return nil, err
@@ -1526,6 +1658,18 @@ func (r *rpcServer) SendMany(ctx context.Context,
 		return nil, err
 	}
 
+	// Make sure that none of the outputs pays to an address we aren't
+	// allowed to send coins to.
+	outputs, err := addrPairsToOutputs(
+		in.AddrToAmount, r.cfg.ActiveNetParams.Params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkSendOutputs(outputs); err != nil {
+		return nil, err
+	}
+
 	label, err := labels.ValidateAPI(in.Label)
 	if err != nil {
 		return nil, err
@@ -2706,30 +2850,9 @@ func (r *rpcServer) CloseChannel(in *lnrpc.CloseChannelRequest,
 				}
 			})
 	} else {
-		// If this is a frozen channel, then we only allow the co-op
-		// close to proceed if we were the responder to this channel if
-		// the absolute thaw height has not been met.
-		if channel.IsInitiator {
-			absoluteThawHeight, err := channel.AbsoluteThawHeight()
-			if err != nil {
-				return err
-			}
-			if uint32(bestHeight) < absoluteThawHeight {
-				return fmt.Errorf("cannot co-op close frozen "+
-					"channel as initiator until height=%v, "+
-					"(current_height=%v)",
-					absoluteThawHeight, bestHeight)
-			}
-		}
-
-		// If the link is not known by the switch, we cannot gracefully close
-		// the channel.
-		channelID := lnwire.NewChanIDFromOutPoint(*chanPoint)
-		if _, err := r.server.htlcSwitch.GetLink(channelID); err != nil {
-			rpcsLog.Debugf("Trying to non-force close offline channel with "+
-				"chan_point=%v", chanPoint)
-			return fmt.Errorf("unable to gracefully close channel while peer "+
-				"is offline (try force closing it instead): %v", err)
+		err := r.canCoopClose(channel, bestHeight, in.NoWait)
+		if err != nil {
+			return err
 		}
 
 		// Keep the old behavior prior to 0.18.0 - when the user
@@ -2753,45 +2876,19 @@ func (r *rpcServer) CloseChannel(in *lnrpc.CloseChannelRequest,
 		rpcsLog.Debugf("Target sat/kw for closing transaction: %v",
 			int64(feeRate))
 
-		// If the user hasn't specified NoWait, then before we attempt
-		// to close the channel we ensure there are no active HTLCs on
-		// the link.
-		if !in.NoWait && len(channel.ActiveHtlcs()) != 0 {
-			return fmt.Errorf("cannot co-op close channel " +
-				"with active htlcs")
+		// If a delivery address to close out to was specified, decode
+		// it.
+		deliveryScript, err := r.parseDeliveryAddress(
+			in.DeliveryAddress,
+		)
+		if err != nil {
+			return err
 		}
 
 		// Otherwise, the caller has requested a regular interactive
 		// cooperative channel closure. So we'll forward the request to
 		// the htlc switch which will handle the negotiation and
 		// broadcast details.
-
-		var deliveryScript lnwire.DeliveryAddress
-
-		// If a delivery address to close out to was specified, decode it.
-		if len(in.DeliveryAddress) > 0 {
-			// Decode the address provided.
-			addr, err := btcutil.DecodeAddress(
-				in.DeliveryAddress, r.cfg.ActiveNetParams.Params,
-			)
-			if err != nil {
-				return fmt.Errorf("invalid delivery address: "+
-					"%v", err)
-			}
-
-			if !addr.IsForNet(r.cfg.ActiveNetParams.Params) {
-				return fmt.Errorf("delivery address is not "+
-					"for %s",
-					r.cfg.ActiveNetParams.Params.Name)
-			}
-
-			// Create a script to pay out to the address provided.
-			deliveryScript, err = txscript.PayToAddrScript(addr)
-			if err != nil {
-				return err
-			}
-		}
-
 		maxFee := chainfee.SatPerKVByte(
 			in.MaxFeePerVbyte * 1000,
 		).FeePerKWeight()
@@ -2853,157 +2950,551 @@ out:
 	return nil
 }
 
-func createRPCCloseUpdate(update interface{}) (
-	*lnrpc.CloseStatusUpdate, error) {
+// batchCloseUpdate is a close status update or error for a single channel that
+// is being closed as part of a batch.
+type batchCloseUpdate struct {
+	// idx is the index of the channel within the batch close request.
+	idx int
 
-	switch u := update.(type) {
-	case *peer.ChannelCloseUpdate:
-		return &lnrpc.CloseStatusUpdate{
-			Update: &lnrpc.CloseStatusUpdate_ChanClose{
-				ChanClose: &lnrpc.ChannelCloseUpdate{
-					ClosingTxid: u.ClosingTxid,
-					Success:     u.Success,
-				},
-			},
-		}, nil
-	case *peer.PendingUpdate:
-		return &lnrpc.CloseStatusUpdate{
-			Update: &lnrpc.CloseStatusUpdate_ClosePending{
-				ClosePending: &lnrpc.PendingUpdate{
-					Txid:        u.Txid,
-					OutputIndex: u.OutputIndex,
-				},
-			},
-		}, nil
+	// update is the close status update received from the switch.
+	update interface{}
+
+	// err is set if the closure of the channel failed.
+	err error
+}
+
+// final returns true if no further updates will follow for the channel.
+func (b *batchCloseUpdate) final() bool {
+	if b.err != nil {
+		return true
 	}
 
-	return nil, errors.New("unknown close status update")
+	_, ok := b.update.(*peer.ChannelCloseUpdate)
+	return ok
 }
 
-// abandonChanFromGraph attempts to remove a channel from the channel graph. If
-// we can't find the chanID in the graph, then we assume it has already been
-// removed, and will return a nop.
-func abandonChanFromGraph(chanGraph *channeldb.ChannelGraph,
-	chanPoint *wire.OutPoint) error {
+// parseBatchCloseChanPoints converts the channel points of a batch close
+// request into outpoints, rejecting empty and duplicate entries.
+func parseBatchCloseChanPoints(
+	rpcChanPoints []*lnrpc.ChannelPoint) ([]*wire.OutPoint, error) {
 
-	// First, we'll obtain the channel ID. If we can't locate this, then
-	// it's the case that the channel may have already been removed from
-	// the graph, so we'll return a nil error.
-	chanID, err := chanGraph.ChannelID(chanPoint)
-	switch {
-	case errors.Is(err, channeldb.ErrEdgeNotFound):
-		return nil
-	case err != nil:
-		return err
+	if len(rpcChanPoints) == 0 {
+		return nil, fmt.Errorf("must specify at least one channel " +
+			"point")
 	}
 
-	// If the channel ID is still in the graph, then that means the channel
-	// is still open, so we'll now move to purge it from the graph.
-	return chanGraph.DeleteChannelEdges(false, true, chanID)
-}
+	chanPoints := make([]*wire.OutPoint, 0, len(rpcChanPoints))
+	seen := make(map[wire.OutPoint]struct{}, len(rpcChanPoints))
+	for _, rpcChanPoint := range rpcChanPoints {
+		if rpcChanPoint == nil {
+			return nil, fmt.Errorf("channel point must not be " +
+				"empty")
+		}
 
-// abandonChan removes a channel from the database, graph and contract court.
-func (r *rpcServer) abandonChan(chanPoint *wire.OutPoint,
-	bestHeight uint32) error {
+		txid, err := lnrpc.GetChanPointFundingTxid(rpcChanPoint)
+		if err != nil {
+			return nil, err
+		}
+		chanPoint := wire.NewOutPoint(txid, rpcChanPoint.OutputIndex)
 
-	// Before we remove the channel we cancel the rebroadcasting of the
-	// transaction. If this transaction does not exist in the rebroadcast
-	// queue anymore it is a noop.
-	txid, err := chainhash.NewHash(chanPoint.Hash[:])
-	if err != nil {
-		return err
+		if _, ok := seen[*chanPoint]; ok {
+			return nil, fmt.Errorf("duplicate channel point %v",
+				chanPoint)
+		}
+		seen[*chanPoint] = struct{}{}
+
+		chanPoints = append(chanPoints, chanPoint)
 	}
-	r.server.cc.Wallet.CancelRebroadcast(*txid)
 
-	// Abandoning a channel is a three-step process: remove from the open
-	// channel state, remove from the graph, remove from the contract
-	// court. Between any step it's possible that the users restarts the
-	// process all over again. As a result, each of the steps below are
-	// intended to be idempotent.
-	err = r.server.chanStateDB.AbandonChannel(chanPoint, bestHeight)
-	if err != nil {
-		return err
+	return chanPoints, nil
+}
+
+// BatchCloseChannel attempts to cooperatively close a set of active channels
+// using a common fee rate. The closures are negotiated concurrently, and the
+// updates of all of them are multiplexed onto the given stream.
+func (r *rpcServer) BatchCloseChannel(in *lnrpc.BatchCloseChannelRequest,
+	updateStream lnrpc.Lightning_BatchCloseChannelServer) error {
+
+	if !r.server.Started() {
+		return ErrServerNotActive
 	}
-	err = abandonChanFromGraph(r.server.graphDB, chanPoint)
+
+	chanPoints, err := parseBatchCloseChanPoints(in.ChannelPoints)
 	if err != nil {
 		return err
 	}
-	err = r.server.chainArb.ResolveContract(*chanPoint)
+
+	err = checkBatchDeliveryAddress(
+		in.DeliveryAddress, len(chanPoints), in.ReuseDeliveryAddress,
+	)
 	if err != nil {
 		return err
 	}
 
-	// If this channel was in the process of being closed, but didn't fully
-	// close, then it's possible that the nursery is hanging on to some
-	// state. To err on the side of caution, we'll now attempt to wipe any
-	// state for this channel from the nursery.
-	err = r.server.utxoNursery.RemoveChannel(chanPoint)
-	if err != nil && err != contractcourt.ErrContractNotFound {
+	rpcsLog.Tracef("[batchclosechannel] request for %d channels",
+		len(chanPoints))
+
+	_, bestHeight, err := r.server.cc.ChainIO.GetBestBlock()
+	if err != nil {
 		return err
 	}
 
-	// Finally, notify the backup listeners that the channel can be removed
-	// from any channel backups.
-	r.server.channelNotifier.NotifyClosedChannelEvent(*chanPoint)
+	// Before kicking off any closure, we make sure that every channel in
+	// the batch can be closed cooperatively, so an invalid request doesn't
+	// leave us with only part of the batch being closed.
+	channels := make([]*channeldb.OpenChannel, len(chanPoints))
+	for i, chanPoint := range chanPoints {
+		channel, err := r.server.chanStateDB.FetchChannel(
+			nil, *chanPoint,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch channel %v: %w",
+				chanPoint, err)
+		}
+		channels[i] = channel
 
-	return nil
-}
+		if channel.HasChanStatus(channeldb.ChanStatusRestored) ||
+			channel.HasChanStatus(
+				channeldb.ChanStatusLocalDataLoss,
+			) {
 
-// AbandonChannel removes all channel state from the database except for a
-// close summary. This method can be used to get rid of permanently unusable
-// channels due to bugs fixed in newer versions of lnd.
-func (r *rpcServer) AbandonChannel(_ context.Context,
-	in *lnrpc.AbandonChannelRequest) (*lnrpc.AbandonChannelResponse, error) {
+			return fmt.Errorf("cannot close channel %v with "+
+				"state: %v", chanPoint, channel.ChanStatus())
+		}
 
-	// If this isn't the dev build, then we won't allow the RPC to be
-	// executed, as it's an advanced feature and won't be activated in
-	// regular production/release builds except for the explicit case of
-	// externally funded channels that are still pending. Due to repeated
-	// requests, we also allow this requirement to be overwritten by a new
-	// flag that attests to the user knowing what they're doing and the risk
-	// associated with the command/RPC.
-	if !in.IKnowWhatIAmDoing && !in.PendingFundingShimOnly &&
-		!build.IsDevBuild() {
+		err = r.canCoopClose(channel, bestHeight, in.NoWait)
+		if err != nil {
+			return fmt.Errorf("channel %v: %w", chanPoint, err)
+		}
+	}
 
-		return nil, fmt.Errorf("AbandonChannel RPC call only " +
-			"available in dev builds")
+	// All closures share the same fee rate and maximum fee rate.
+	targetConf := maybeUseDefaultConf(
+		0, in.SatPerVbyte, uint32(in.TargetConf),
+	)
+	feeRate, err := lnrpc.CalculateFeeRate(
+		0, in.SatPerVbyte, targetConf, r.server.cc.FeeEstimator,
+	)
+	if err != nil {
+		return err
 	}
+	maxFee := chainfee.SatPerKVByte(
+		in.MaxFeePerVbyte * 1000,
+	).FeePerKWeight()
 
-	// We'll parse out the arguments to we can obtain the chanPoint of the
-	// target channel.
-	txid, err := lnrpc.GetChanPointFundingTxid(in.GetChannelPoint())
+	rpcsLog.Debugf("Target sat/kw for batch closing transactions: %v",
+		int64(feeRate))
+
+	// If a fee budget is set for the whole batch, each channel gets an
+	// equal share of it, which caps the maximum fee rate of its closing
+	// transaction.
+	maxFees, err := batchCloseMaxFeeRates(
+		channels, feeRate, maxFee, btcutil.Amount(in.MaxFeeSat),
+	)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	index := in.ChannelPoint.OutputIndex
-	chanPoint := wire.NewOutPoint(txid, index)
 
-	// When we remove the channel from the database, we need to set a close
-	// height, so we'll just use the current best known height.
-	_, bestHeight, err := r.server.cc.ChainIO.GetBestBlock()
+	deliveryScript, err := r.parseDeliveryAddress(in.DeliveryAddress)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	dbChan, err := r.server.chanStateDB.FetchChannel(nil, *chanPoint)
-	switch {
-	// If the channel isn't found in the set of open channels, then we can
-	// continue on as it can't be loaded into the link/peer.
-	case err == channeldb.ErrChannelNotFound:
-		break
+	// We'll now forward all close requests to the htlc switch, and funnel
+	// the updates of each closure into a single channel, so they can be
+	// sent over the stream in the order they arrive.
+	updates := make(chan *batchCloseUpdate)
+	quit := make(chan struct{})
+	defer close(quit)
 
-	// If the channel is still known to be open, then before we modify any
-	// on-disk state, we'll remove the channel from the switch and peer
-	// state if it's been loaded in.
-	case err == nil:
-		// If the user requested the more safe version that only allows
-		// the removal of externally (shim) funded channels that are
-		// still pending, we enforce this option now that we know the
-		// state of the channel.
-		//
-		// TODO(guggero): Properly store the funding type (wallet, shim,
-		// PSBT) on the channel so we don't need to use the thaw height.
-		isShimFunded := dbChan.ThawHeight > 0
+	for i, chanPoint := range chanPoints {
+		updateChan, errChan := r.server.htlcSwitch.CloseLink(
+			chanPoint, contractcourt.CloseRegular, feeRate,
+			maxFees[i], deliveryScript,
+		)
+
+		go func(idx int) {
+			for {
+				update := &batchCloseUpdate{idx: idx}
+				select {
+				case update.err = <-errChan:
+				case update.update = <-updateChan:
+				case <-quit:
+					return
+				}
+
+				select {
+				case updates <- update:
+				case <-quit:
+					return
+				}
+
+				if update.final() {
+					return
+				}
+			}
+		}(i)
+	}
+
+	// If the user doesn't want to wait for the txids to come back then we
+	// will send an empty update for each channel to kick off the stream.
+	if in.NoWait {
+		rpcsLog.Trace("[batchclosechannel] sending instant updates")
+		instant := &lnrpc.CloseStatusUpdate{
+			Update: &lnrpc.CloseStatusUpdate_CloseInstant{},
+		}
+		for _, rpcChanPoint := range in.ChannelPoints {
+			err := updateStream.Send(&lnrpc.BatchCloseStatusUpdate{
+				ChannelPoint: rpcChanPoint,
+				Update:       instant,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Forward the updates until every closure in the batch has either
+	// completed or failed.
+	for pending := len(chanPoints); pending > 0; {
+		select {
+		case update := <-updates:
+			chanPoint := chanPoints[update.idx]
+			rpcUpdate := &lnrpc.BatchCloseStatusUpdate{
+				ChannelPoint: in.ChannelPoints[update.idx],
+			}
+
+			if update.err != nil {
+				rpcsLog.Errorf("[batchclosechannel] unable to "+
+					"close ChannelPoint(%v): %v", chanPoint,
+					update.err)
+
+				rpcUpdate.Error = update.err.Error()
+			} else {
+				rpcUpdate.Update, err = createRPCCloseUpdate(
+					update.update,
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			rpcsLog.Tracef("[batchclosechannel] sending update: %v",
+				rpcUpdate)
+
+			if err := updateStream.Send(rpcUpdate); err != nil {
+				return err
+			}
+
+			if !update.final() {
+				continue
+			}
+			pending--
+
+			u, ok := update.update.(*peer.ChannelCloseUpdate)
+			if ok {
+				h, _ := chainhash.NewHash(u.ClosingTxid)
+				rpcsLog.Infof("[batchclosechannel] close of "+
+					"ChannelPoint(%v) completed: txid(%v)",
+					chanPoint, h)
+			}
+
+		case <-updateStream.Context().Done():
+			return updateStream.Context().Err()
+
+		case <-r.quit:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// checkBatchDeliveryAddress returns an error if the delivery address would be
+// used for several channels of a batch close without the caller allowing it.
+// Paying out several channels to the same address links their closing
+// transactions, so by default every channel is closed to a fresh wallet
+// address.
+func checkBatchDeliveryAddress(address string, numChannels int,
+	reuse bool) error {
+
+	if address == "" || numChannels < 2 || reuse {
+		return nil
+	}
+
+	return fmt.Errorf("delivery address would be reused for %d "+
+		"channels, reuse_delivery_address must be set", numChannels)
+}
+
+// batchCloseMaxFeeRates returns the maximum fee rate of the closing
+// transaction of each of the given channels. The given maximum fee rate is
+// lowered for each channel so that the closing transactions of the batch don't
+// pay more than the total fee budget. A zero budget means no budget. An error
+// is returned if the fee rate of a closure already exceeds its budget.
+func batchCloseMaxFeeRates(channels []*channeldb.OpenChannel,
+	feeRate, maxFee chainfee.SatPerKWeight,
+	budget btcutil.Amount) ([]chainfee.SatPerKWeight, error) {
+
+	maxFees := make([]chainfee.SatPerKWeight, len(channels))
+	for i, channel := range channels {
+		maxFees[i] = maxFee
+		if budget == 0 {
+			continue
+		}
+
+		chanBudget := budget / btcutil.Amount(len(channels))
+		budgetFee := chancloser.MaxCoopCloseFeeRate(
+			channel.ChanType, chanBudget,
+		)
+		if feeRate > budgetFee {
+			return nil, fmt.Errorf("fee rate %v exceeds the fee "+
+				"budget of %v for channel %v", feeRate,
+				chanBudget, channel.FundingOutpoint)
+		}
+
+		if maxFees[i] == 0 || budgetFee < maxFees[i] {
+			maxFees[i] = budgetFee
+		}
+	}
+
+	return maxFees, nil
+}
+
+// canCoopClose returns an error if the given channel can't currently be
+// closed cooperatively, either because it is still frozen, its peer is
+// offline, or it has active HTLCs and the caller isn't willing to wait for
+// them to resolve.
+func (r *rpcServer) canCoopClose(channel *channeldb.OpenChannel,
+	bestHeight int32, noWait bool) error {
+
+	chanPoint := channel.FundingOutpoint
+
+	// If this is a frozen channel, then we only allow the co-op close to
+	// proceed if we were the responder to this channel if the absolute
+	// thaw height has not been met.
+	if channel.IsInitiator {
+		absoluteThawHeight, err := channel.AbsoluteThawHeight()
+		if err != nil {
+			return err
+		}
+		if uint32(bestHeight) < absoluteThawHeight {
+			return fmt.Errorf("cannot co-op close frozen "+
+				"channel as initiator until height=%v, "+
+				"(current_height=%v)",
+				absoluteThawHeight, bestHeight)
+		}
+	}
+
+	// If the link is not known by the switch, we cannot gracefully close
+	// the channel.
+	channelID := lnwire.NewChanIDFromOutPoint(chanPoint)
+	if _, err := r.server.htlcSwitch.GetLink(channelID); err != nil {
+		rpcsLog.Debugf("Trying to non-force close offline channel "+
+			"with chan_point=%v", chanPoint)
+		return fmt.Errorf("unable to gracefully close channel while "+
+			"peer is offline (try force closing it instead): %v",
+			err)
+	}
+
+	// If the user hasn't specified NoWait, then before we attempt to close
+	// the channel we ensure there are no active HTLCs on the link.
+	if !noWait && len(channel.ActiveHtlcs()) != 0 {
+		return fmt.Errorf("cannot co-op close channel " +
+			"with active htlcs")
+	}
+
+	return nil
+}
+
+// parseDeliveryAddress decodes the given delivery address and returns the
+// script to pay out to. An empty address results in an empty script. The
+// address must be permitted by the allowlist of close addresses.
+func (r *rpcServer) parseDeliveryAddress(
+	address string) (lnwire.DeliveryAddress, error) {
+
+	if len(address) == 0 {
+		return nil, nil
+	}
+
+	// Decode the address provided.
+	addr, err := btcutil.DecodeAddress(
+		address, r.cfg.ActiveNetParams.Params,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delivery address: %v", err)
+	}
+
+	if !addr.IsForNet(r.cfg.ActiveNetParams.Params) {
+		return nil, fmt.Errorf("delivery address is not for %s",
+			r.cfg.ActiveNetParams.Params.Name)
+	}
+
+	// Create a script to pay out to the address provided.
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure that the funds of the channel can only be paid out to an
+	// allowed address.
+	if err := r.server.deliveryAllowlist.Check(script); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return script, nil
+}
+
+func createRPCCloseUpdate(update interface{}) (
+	*lnrpc.CloseStatusUpdate, error) {
+
+	switch u := update.(type) {
+	case *peer.ChannelCloseUpdate:
+		return &lnrpc.CloseStatusUpdate{
+			Update: &lnrpc.CloseStatusUpdate_ChanClose{
+				ChanClose: &lnrpc.ChannelCloseUpdate{
+					ClosingTxid: u.ClosingTxid,
+					Success:     u.Success,
+				},
+			},
+		}, nil
+	case *peer.PendingUpdate:
+		return &lnrpc.CloseStatusUpdate{
+			Update: &lnrpc.CloseStatusUpdate_ClosePending{
+				ClosePending: &lnrpc.PendingUpdate{
+					Txid:        u.Txid,
+					OutputIndex: u.OutputIndex,
+				},
+			},
+		}, nil
+	}
+
+	return nil, errors.New("unknown close status update")
+}
+
+// abandonChanFromGraph attempts to remove a channel from the channel graph. If
+// we can't find the chanID in the graph, then we assume it has already been
+// removed, and will return a nop.
+func abandonChanFromGraph(chanGraph *channeldb.ChannelGraph,
+	chanPoint *wire.OutPoint) error {
+
+	// First, we'll obtain the channel ID. If we can't locate this, then
+	// it's the case that the channel may have already been removed from
+	// the graph, so we'll return a nil error.
+	chanID, err := chanGraph.ChannelID(chanPoint)
+	switch {
+	case errors.Is(err, channeldb.ErrEdgeNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	// If the channel ID is still in the graph, then that means the channel
+	// is still open, so we'll now move to purge it from the graph.
+	return chanGraph.DeleteChannelEdges(false, true, chanID)
+}
+
+// abandonChan removes a channel from the database, graph and contract court.
+func (r *rpcServer) abandonChan(chanPoint *wire.OutPoint,
+	bestHeight uint32) error {
+
+	// Before we remove the channel we cancel the rebroadcasting of the
+	// transaction. If this transaction does not exist in the rebroadcast
+	// queue anymore it is a noop.
+	txid, err := chainhash.NewHash(chanPoint.Hash[:])
+	if err != nil {
+		return err
+	}
+	r.server.cc.Wallet.CancelRebroadcast(*txid)
+
+	// Abandoning a channel is a three-step process: remove from the open
+	// channel state, remove from the graph, remove from the contract
+	// court. Between any step it's possible that the users restarts the
+	// process all over again. As a result, each of the steps below are
+	// intended to be idempotent.
+	err = r.server.chanStateDB.AbandonChannel(chanPoint, bestHeight)
+	if err != nil {
+		return err
+	}
+	err = abandonChanFromGraph(r.server.graphDB, chanPoint)
+	if err != nil {
+		return err
+	}
+	err = r.server.chainArb.ResolveContract(*chanPoint)
+	if err != nil {
+		return err
+	}
+
+	// If this channel was in the process of being closed, but didn't fully
+	// close, then it's possible that the nursery is hanging on to some
+	// state. To err on the side of caution, we'll now attempt to wipe any
+	// state for this channel from the nursery.
+	err = r.server.utxoNursery.RemoveChannel(chanPoint)
+	if err != nil && err != contractcourt.ErrContractNotFound {
+		return err
+	}
+
+	// Finally, notify the backup listeners that the channel can be removed
+	// from any channel backups.
+	r.server.channelNotifier.NotifyClosedChannelEvent(*chanPoint)
+
+	return nil
+}
+
+// AbandonChannel removes all channel state from the database except for a
+// close summary. This method can be used to get rid of permanently unusable
+// channels due to bugs fixed in newer versions of lnd.
+func (r *rpcServer) AbandonChannel(_ context.Context,
+	in *lnrpc.AbandonChannelRequest) (*lnrpc.AbandonChannelResponse, error) {
+
+	// If this isn't the dev build, then we won't allow the RPC to be
+	// executed, as it's an advanced feature and won't be activated in
+	// regular production/release builds except for the explicit case of
+	// externally funded channels that are still pending. Due to repeated
+	// requests, we also allow this requirement to be overwritten by a new
+	// flag that attests to the user knowing what they're doing and the risk
+	// associated with the command/RPC.
+	if !in.IKnowWhatIAmDoing && !in.PendingFundingShimOnly &&
+		!build.IsDevBuild() {
+
+		return nil, fmt.Errorf("AbandonChannel RPC call only " +
+			"available in dev builds")
+	}
+
+	// We'll parse out the arguments to we can obtain the chanPoint of the
+	// target channel.
+	txid, err := lnrpc.GetChanPointFundingTxid(in.GetChannelPoint())
+	if err != nil {
+		return nil, err
+	}
+	index := in.ChannelPoint.OutputIndex
+	chanPoint := wire.NewOutPoint(txid, index)
+
+	// When we remove the channel from the database, we need to set a close
+	// height, so we'll just use the current best known height.
+	_, bestHeight, err := r.server.cc.ChainIO.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	dbChan, err := r.server.chanStateDB.FetchChannel(nil, *chanPoint)
+	switch {
+	// If the channel isn't found in the set of open channels, then we can
+	// continue on as it can't be loaded into the link/peer.
+	case err == channeldb.ErrChannelNotFound:
+		break
+
+	// If the channel is still known to be open, then before we modify any
+	// on-disk state, we'll remove the channel from the switch and peer
+	// state if it's been loaded in.
+	case err == nil:
+		// If the user requested the more safe version that only allows
+		// the removal of externally (shim) funded channels that are
+		// still pending, we enforce this option now that we know the
+		// state of the channel.
+		//
+		// TODO(guggero): Properly store the funding type (wallet, shim,
+		// PSBT) on the channel so we don't need to use the thaw height.
+		isShimFunded := dbChan.ThawHeight > 0
 		isPendingShimFunded := isShimFunded && dbChan.IsPending
 		if !in.IKnowWhatIAmDoing && in.PendingFundingShimOnly &&
 			!isPendingShimFunded {
@@ -4489,6 +4980,9 @@ func createRPCOpenChannel(r *rpcServer, dbChannel *channeldb.OpenChannel,
 		ZeroConf:              dbChannel.IsZeroConf(),
 		ZeroConfConfirmedScid: dbChannel.ZeroConfRealScid().ToUint64(),
 		Memo:                  string(dbChannel.Memo),
+		CommitFeePolicy: marshalCommitFeePolicy(
+			dbChannel.CommitFeePolicy(),
+		),
 		// TODO: remove the following deprecated fields
 		CsvDelay:             uint32(dbChannel.LocalChanCfg.CsvDelay),
 		LocalChanReserveSat:  int64(dbChannel.LocalChanCfg.ChanReserve),
@@ -5768,7 +6262,9 @@ func (r *rpcServer) AddInvoice(ctx context.Context,
 		GenAmpInvoiceFeatures: func() *lnwire.FeatureVector {
 			return r.server.featureMgr.Get(feature.SetInvoiceAmp)
 		},
-		GetAlias: r.server.aliasMgr.GetPeerAlias,
+		GetAlias:       r.server.aliasMgr.GetPeerAlias,
+		HopHintScoring: newHopHintScoring(r.cfg.Invoices),
+		ChannelUptime:  r.server.channelUptime,
 	}
 
 	value, err := lnrpc.UnmarshallAmt(invoice.Value, invoice.ValueMsat)
@@ -5791,6 +6287,8 @@ func (r *rpcServer) AddInvoice(ctx context.Context,
 		Private:         invoice.Private,
 		RouteHints:      routeHints,
 		Amp:             invoice.IsAmp,
+		HopHintChannels: invoice.HopHintChanIds,
+		Metadata:        invoice.Metadata,
 	}
 
 	if invoice.RPreimage != nil {
@@ -5892,6 +6390,11 @@ func (r *rpcServer) ListInvoices(ctx context.Context,
 		}
 	}
 
+	if req.MetadataValue != "" && req.MetadataKey == "" {
+		return nil, fmt.Errorf("metadata value requires a metadata " +
+			"key")
+	}
+
 	// Next, we'll map the proto request into a format that is understood by
 	// the database.
 	q := invoices.InvoiceQuery{
@@ -5901,6 +6404,8 @@ func (r *rpcServer) ListInvoices(ctx context.Context,
 		Reversed:          req.Reversed,
 		CreationDateStart: int64(req.CreationDateStart),
 		CreationDateEnd:   int64(req.CreationDateEnd),
+		MetadataKey:       req.MetadataKey,
+		MetadataValue:     req.MetadataValue,
 	}
 
 	invoiceSlice, err := r.server.invoicesDB.QueryInvoices(ctx, q)
@@ -5933,6 +6438,11 @@ func (r *rpcServer) ListInvoices(ctx context.Context,
 func (r *rpcServer) SubscribeInvoices(req *lnrpc.InvoiceSubscription,
 	updateStream lnrpc.Lightning_SubscribeInvoicesServer) error {
 
+	filter, err := newInvoiceNtfnFilter(req)
+	if err != nil {
+		return err
+	}
+
 	invoiceClient, err := r.server.invoices.SubscribeNotifications(
 		updateStream.Context(), req.AddIndex, req.SettleIndex,
 	)
@@ -5944,6 +6454,10 @@ func (r *rpcServer) SubscribeInvoices(req *lnrpc.InvoiceSubscription,
 	for {
 		select {
 		case newInvoice := <-invoiceClient.NewInvoices:
+			if !filter.matches(newInvoice, false) {
+				continue
+			}
+
 			rpcInvoice, err := invoicesrpc.CreateRPCInvoice(
 				newInvoice, r.cfg.ActiveNetParams.Params,
 			)
@@ -5956,6 +6470,10 @@ func (r *rpcServer) SubscribeInvoices(req *lnrpc.InvoiceSubscription,
 			}
 
 		case settledInvoice := <-invoiceClient.SettledInvoices:
+			if !filter.matches(settledInvoice, true) {
+				continue
+			}
+
 			rpcInvoice, err := invoicesrpc.CreateRPCInvoice(
 				settledInvoice, r.cfg.ActiveNetParams.Params,
 			)
@@ -5982,6 +6500,101 @@ func (r *rpcServer) SubscribeInvoices(req *lnrpc.InvoiceSubscription,
 	}
 }
 
+// invoiceNtfnFilter holds the server-side filters of an invoice subscription.
+type invoiceNtfnFilter struct {
+	// notifyAdded and notifySettled indicate whether added and settled
+	// invoices are notified.
+	notifyAdded   bool
+	notifySettled bool
+
+	// minAmt is the minimum amount of the notified invoices.
+	minAmt lnwire.MilliSatoshi
+
+	// creationDateStart and creationDateEnd restrict the creation date of
+	// the notified invoices, if non-zero.
+	creationDateStart int64
+	creationDateEnd   int64
+}
+
+// newInvoiceNtfnFilter parses the filters of an invoice subscription request.
+func newInvoiceNtfnFilter(
+	req *lnrpc.InvoiceSubscription) (*invoiceNtfnFilter, error) {
+
+	if req.CreationDateStart != 0 && req.CreationDateEnd != 0 &&
+		req.CreationDateStart >= req.CreationDateEnd {
+
+		return nil, fmt.Errorf("start date(%v) must be before end "+
+			"date(%v)", req.CreationDateStart,
+			req.CreationDateEnd)
+	}
+
+	filter := &invoiceNtfnFilter{
+		notifyAdded:       len(req.States) == 0,
+		notifySettled:     len(req.States) == 0,
+		minAmt:            lnwire.MilliSatoshi(req.MinAmtMsat),
+		creationDateStart: int64(req.CreationDateStart),
+		creationDateEnd:   int64(req.CreationDateEnd),
+	}
+
+	for _, state := range req.States {
+		switch state {
+		case lnrpc.Invoice_OPEN:
+			filter.notifyAdded = true
+
+		case lnrpc.Invoice_SETTLED:
+			filter.notifySettled = true
+
+		default:
+			return nil, fmt.Errorf("invoice state %v is never "+
+				"notified, only %v and %v are valid", state,
+				lnrpc.Invoice_OPEN, lnrpc.Invoice_SETTLED)
+		}
+	}
+
+	return filter, nil
+}
+
+// matches returns true if a notification for the given added or settled
+// invoice passes the filter.
+func (f *invoiceNtfnFilter) matches(invoice *invoices.Invoice,
+	settled bool) bool {
+
+	switch {
+	case settled && !f.notifySettled:
+		return false
+
+	case !settled && !f.notifyAdded:
+		return false
+	}
+
+	createTime := invoice.CreationDate.Unix()
+	if createTime < f.creationDateStart {
+		return false
+	}
+	if f.creationDateEnd != 0 && createTime > f.creationDateEnd {
+		return false
+	}
+
+	amt := invoice.Terms.Value
+	if settled {
+		amt = invoice.AmtPaid
+
+		// Settled AMP invoices carry the settle index of the settled
+		// sub-payment, so we use the amount paid to it instead of the
+		// amount paid to the invoice in total.
+		if invoice.IsAMP() {
+			for _, ampState := range invoice.AMPState {
+				if ampState.SettleIndex == invoice.SettleIndex {
+					amt = ampState.AmtPaid
+					break
+				}
+			}
+		}
+	}
+
+	return amt >= f.minAmt
+}
+
 // SubscribeTransactions creates a uni-directional stream (server -> client) in
 // which any newly discovered transactions relevant to the wallet are sent
 // over.
@@ -6059,6 +6672,15 @@ func (r *rpcServer) GetTransactions(ctx context.Context,
 func (r *rpcServer) DescribeGraph(ctx context.Context,
 	req *lnrpc.ChannelGraphRequest) (*lnrpc.ChannelGraph, error) {
 
+	// A paginated query only returns a part of the graph, which is why
+	// it bypasses the response cache.
+	if req.PageSize != 0 {
+		return r.describeGraphPage(req)
+	}
+	if len(req.Cursor) != 0 {
+		return nil, fmt.Errorf("cursor requires a page size")
+	}
+
 	resp := &lnrpc.ChannelGraph{}
 	includeUnannounced := req.IncludeUnannounced
 
@@ -6118,13 +6740,103 @@ func (r *rpcServer) DescribeGraph(ctx context.Context,
 		return nil, err
 	}
 
-	// We still have the mutex held, so we can safely populate the cache
-	// now to save on GC churn for this query, but only if the cache isn't
-	// disabled.
-	if graphCacheActive {
-		r.describeGraphResp = resp
-	}
-
+	// We still have the mutex held, so we can safely populate the cache
+	// now to save on GC churn for this query, but only if the cache isn't
+	// disabled.
+	if graphCacheActive {
+		r.describeGraphResp = resp
+	}
+
+	return resp, nil
+}
+
+// errGraphPageFull is used to stop the graph traversal once a page of the
+// graph is complete.
+var errGraphPageFull = errors.New("graph page full")
+
+// describeGraphPage returns a single page of the channel graph. The nodes are
+// returned first, ordered by their public key, followed by the edges, ordered
+// by their channel id. The cursor is the public key of the last node or the
+// channel id of the last edge of the previous page.
+func (r *rpcServer) describeGraphPage(
+	req *lnrpc.ChannelGraphRequest) (*lnrpc.ChannelGraph, error) {
+
+	var (
+		resp     = &lnrpc.ChannelGraph{}
+		pageSize = int(req.PageSize)
+		graph    = r.server.graphDB
+
+		nodeCursor []byte
+		edgeCursor *uint64
+	)
+
+	switch len(req.Cursor) {
+	case 0:
+
+	case 33:
+		nodeCursor = req.Cursor
+
+	case 8:
+		chanID := binary.BigEndian.Uint64(req.Cursor)
+		edgeCursor = &chanID
+
+	default:
+		return nil, fmt.Errorf("invalid cursor length %v",
+			len(req.Cursor))
+	}
+
+	// Unless the previous page already reached the edges, we continue
+	// with the nodes after the cursor.
+	if edgeCursor == nil {
+		err := graph.ForEachNodeAfter(nodeCursor, func(_ kvdb.RTx,
+			node *channeldb.LightningNode) error {
+
+			pubKey := node.PubKeyBytes[:]
+			resp.Nodes = append(resp.Nodes, marshalNode(node))
+			if len(resp.Nodes) == pageSize {
+				resp.NextCursor = pubKey
+
+				return errGraphPageFull
+			}
+
+			return nil
+		})
+		switch {
+		case errors.Is(err, errGraphPageFull):
+			return resp, nil
+
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	err := graph.ForEachChannelAfter(edgeCursor, func(
+		edgeInfo *models.ChannelEdgeInfo,
+		c1, c2 *models.ChannelEdgePolicy) error {
+
+		if !req.IncludeUnannounced && edgeInfo.AuthProof == nil {
+			return nil
+		}
+
+		resp.Edges = append(resp.Edges, marshalDBEdge(edgeInfo, c1, c2))
+		if len(resp.Nodes)+len(resp.Edges) == pageSize {
+			resp.NextCursor = make([]byte, 8)
+			binary.BigEndian.PutUint64(
+				resp.NextCursor, edgeInfo.ChannelID,
+			)
+
+			return errGraphPageFull
+		}
+
+		return nil
+	})
+	switch {
+	case errors.Is(err, errGraphPageFull):
+	case errors.Is(err, channeldb.ErrGraphNoEdgesFound):
+	case err != nil:
+		return nil, err
+	}
+
 	return resp, nil
 }
 
@@ -6294,6 +7006,40 @@ func (r *rpcServer) GetNodeMetrics(ctx context.Context,
 	return resp, nil
 }
 
+// StartGraphAnalysis starts a background job that computes centrality and
+// connectivity metrics over the local graph.
+func (r *rpcServer) StartGraphAnalysis(_ context.Context,
+	req *lnrpc.StartGraphAnalysisRequest) (
+	*lnrpc.StartGraphAnalysisResponse, error) {
+
+	jobID, err := r.graphAnalyzer.start(req.Types)
+	switch {
+	case errors.Is(err, errGraphAnalysisRunning):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+
+	case err != nil:
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &lnrpc.StartGraphAnalysisResponse{
+		JobId: jobID,
+	}, nil
+}
+
+// GetGraphAnalysis returns the progress of a graph analysis job and its
+// results once it is done.
+func (r *rpcServer) GetGraphAnalysis(_ context.Context,
+	req *lnrpc.GraphAnalysisRequest) (*lnrpc.GraphAnalysisResponse,
+	error) {
+
+	resp, err := r.graphAnalyzer.status(req.JobId)
+	if errors.Is(err, errGraphAnalysisNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	return resp, err
+}
+
 // GetChanInfo returns the latest authenticated network announcement for the
 // given channel identified by either its channel ID or a channel outpoint. Both
 // uniquely identify the location of transaction's funding output within the
@@ -6588,6 +7334,20 @@ func (r *rpcServer) GetNetworkInfo(ctx context.Context,
 		netInfo.AvgChannelSize = 0
 	}
 
+	if metrics := graph.GraphCacheMetrics(); metrics != nil {
+		netInfo.GraphCache = &lnrpc.GraphCacheStats{
+			NumNodes:    uint32(metrics.NumNodes),
+			NumChannels: uint32(metrics.NumChannels),
+			Hits:        metrics.Hits,
+			Misses:      metrics.Misses,
+			HitRate:     metrics.HitRate(),
+			SizeBytes:   metrics.SizeBytes,
+			NumSnapshotChannels: uint32(
+				metrics.NumSnapshotChannels,
+			),
+		}
+	}
+
 	return netInfo, nil
 }
 
@@ -6624,6 +7384,28 @@ func (r *rpcServer) StopDaemon(_ context.Context,
 func (r *rpcServer) SubscribeChannelGraph(req *lnrpc.GraphTopologySubscription,
 	updateStream lnrpc.Lightning_SubscribeChannelGraphServer) error {
 
+	if req.MinCapacitySat < 0 {
+		return fmt.Errorf("min capacity must not be negative")
+	}
+
+	filter := &routing.TopologyFilter{
+		PolicyUpdatesOnly: req.PolicyUpdatesOnly,
+		MinCapacity:       btcutil.Amount(req.MinCapacitySat),
+	}
+
+	if len(req.NodePubkeys) > 0 {
+		filter.Nodes = make(
+			map[route.Vertex]struct{}, len(req.NodePubkeys),
+		)
+	}
+	for _, pubKey := range req.NodePubkeys {
+		node, err := route.NewVertexFromBytes(pubKey)
+		if err != nil {
+			return err
+		}
+		filter.Nodes[node] = struct{}{}
+	}
+
 	// First, we start by subscribing to a new intent to receive
 	// notifications from the channel router.
 	client, err := r.server.chanRouter.SubscribeTopology()
@@ -6650,6 +7432,13 @@ func (r *rpcServer) SubscribeChannelGraph(req *lnrpc.GraphTopologySubscription,
 				return errors.New("server shutting down")
 			}
 
+			// Drop the updates that the client isn't interested
+			// in.
+			topChange = filter.Apply(topChange)
+			if topChange == nil {
+				continue
+			}
+
 			// Convert the struct from the channel router into the
 			// form expected by the gRPC service then send it off
 			// to the client.
@@ -6903,6 +7692,39 @@ func (r *rpcServer) DeleteAllPayments(ctx context.Context,
 	return &lnrpc.DeleteAllPaymentsResponse{}, nil
 }
 
+// PruneFailedPaymentAttempts deletes the failed HTLC attempts of the payments
+// that were created before the given age, or reports what would be deleted in
+// a dry run.
+func (r *rpcServer) PruneFailedPaymentAttempts(_ context.Context,
+	req *lnrpc.PruneFailedPaymentAttemptsRequest) (
+	*lnrpc.PruneFailedPaymentAttemptsResponse, error) {
+
+	if req.OlderThanSeconds > math.MaxInt64/uint64(time.Second) {
+		return nil, fmt.Errorf("older_than_seconds %v out of range",
+			req.OlderThanSeconds)
+	}
+
+	createdBefore := time.Now().Add(
+		-time.Duration(req.OlderThanSeconds) * time.Second,
+	)
+
+	rpcsLog.Infof("[PruneFailedPaymentAttempts] created_before=%v, "+
+		"dry_run=%v", createdBefore, req.DryRun)
+
+	stats, err := r.server.miscDB.PruneFailedAttempts(
+		createdBefore, req.DryRun,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.PruneFailedPaymentAttemptsResponse{
+		NumPayments: stats.NumPayments,
+		NumAttempts: stats.NumAttempts,
+		FreedBytes:  stats.NumBytes,
+	}, nil
+}
+
 // DebugLevel allows a caller to programmatically set the logging verbosity of
 // lnd. The logging can be targeted according to a coarse daemon-wide logging
 // level, or in a granular fashion to specify the logging for a target
@@ -7170,7 +7992,34 @@ const minFeeRate = 1e-6
 func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 	req *lnrpc.PolicyUpdateRequest) (*lnrpc.PolicyUpdateResponse, error) {
 
-	var targetChans []wire.OutPoint
+	chanPolicy, targetChans, err := r.parsePolicyUpdate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// With the scope resolved, we'll now send this to the local channel
+	// manager so it can propagate the new policy for our target channel(s).
+	failedUpdates, err := r.server.localChanMgr.UpdatePolicy(chanPolicy,
+		targetChans...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.PolicyUpdateResponse{
+		FailedUpdates: failedUpdates,
+	}, nil
+}
+
+// parsePolicyUpdate validates a channel policy update request and returns the
+// new policy along with the channels it targets. An empty list of channels
+// denotes a global update.
+func (r *rpcServer) parsePolicyUpdate(req *lnrpc.PolicyUpdateRequest) (
+	routing.ChannelPolicy, []wire.OutPoint, error) {
+
+	var (
+		noPolicy    routing.ChannelPolicy
+		targetChans []wire.OutPoint
+	)
 	switch scope := req.Scope.(type) {
 	// If the request is targeting all active channels, then we don't need
 	// target any channels by their channel point.
@@ -7181,14 +8030,14 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 	case *lnrpc.PolicyUpdateRequest_ChanPoint:
 		txid, err := lnrpc.GetChanPointFundingTxid(scope.ChanPoint)
 		if err != nil {
-			return nil, err
+			return noPolicy, nil, err
 		}
 		targetChans = append(targetChans, wire.OutPoint{
 			Hash:  *txid,
 			Index: scope.ChanPoint.OutputIndex,
 		})
 	default:
-		return nil, fmt.Errorf("unknown scope: %v", scope)
+		return noPolicy, nil, fmt.Errorf("unknown scope: %v", scope)
 	}
 
 	var feeRateFixed uint32
@@ -7200,7 +8049,9 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 		errMsg := "cannot set both FeeRate and FeeRatePpm at the " +
 			"same time"
 
-		return nil, status.Errorf(codes.InvalidArgument, errMsg)
+		return noPolicy, nil, status.Errorf(
+			codes.InvalidArgument, errMsg,
+		)
 
 	// If the request is using fee_rate.
 	case req.FeeRate != 0:
@@ -7208,8 +8059,8 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 		// the passed fee rate is below 1e-6, or the lowest allowed
 		// non-zero fee rate expressible within the protocol.
 		if req.FeeRate != 0 && req.FeeRate < minFeeRate {
-			return nil, fmt.Errorf("fee rate of %v is too "+
-				"small, min fee rate is %v", req.FeeRate,
+			return noPolicy, nil, fmt.Errorf("fee rate of %v is "+
+				"too small, min fee rate is %v", req.FeeRate,
 				minFeeRate)
 		}
 
@@ -7232,25 +8083,25 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 	// We'll also ensure that the user isn't setting a CLTV delta that
 	// won't give outgoing HTLCs enough time to fully resolve if needed.
 	if req.TimeLockDelta < minTimeLockDelta {
-		return nil, fmt.Errorf("time lock delta of %v is too small, "+
-			"minimum supported is %v", req.TimeLockDelta,
+		return noPolicy, nil, fmt.Errorf("time lock delta of %v is "+
+			"too small, minimum supported is %v", req.TimeLockDelta,
 			minTimeLockDelta)
 	} else if req.TimeLockDelta > uint32(MaxTimeLockDelta) {
-		return nil, fmt.Errorf("time lock delta of %v is too big, "+
-			"maximum supported is %v", req.TimeLockDelta,
+		return noPolicy, nil, fmt.Errorf("time lock delta of %v is "+
+			"too big, maximum supported is %v", req.TimeLockDelta,
 			MaxTimeLockDelta)
 	}
 
 	// By default, positive inbound fees are rejected.
 	if !r.cfg.AcceptPositiveInboundFees && req.InboundFee != nil {
 		if req.InboundFee.BaseFeeMsat > 0 {
-			return nil, fmt.Errorf("positive values for inbound "+
-				"base fee msat are not supported: %v",
+			return noPolicy, nil, fmt.Errorf("positive values for "+
+				"inbound base fee msat are not supported: %v",
 				req.InboundFee.BaseFeeMsat)
 		}
 		if req.InboundFee.FeeRatePpm > 0 {
-			return nil, fmt.Errorf("positive values for inbound "+
-				"fee rate ppm are not supported: %v",
+			return noPolicy, nil, fmt.Errorf("positive values for "+
+				"inbound fee rate ppm are not supported: %v",
 				req.InboundFee.FeeRatePpm)
 		}
 	}
@@ -7294,19 +8145,345 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 		minHtlc, maxHtlc,
 		spew.Sdump(targetChans))
 
-	// With the scope resolved, we'll now send this to the local channel
-	// manager so it can propagate the new policy for our target channel(s).
-	failedUpdates, err := r.server.localChanMgr.UpdatePolicy(chanPolicy,
-		targetChans...)
+	return chanPolicy, targetChans, nil
+}
+
+// SchedulePolicyUpdate schedules a channel policy update that is applied at a
+// given time and optionally repeated at a fixed interval.
+func (r *rpcServer) SchedulePolicyUpdate(_ context.Context,
+	req *lnrpc.SchedulePolicyUpdateRequest) (*lnrpc.ScheduledPolicyUpdate,
+	error) {
+
+	if req.Update == nil {
+		return nil, status.Error(
+			codes.InvalidArgument, "update must be set",
+		)
+	}
+
+	// We validate the update right away, so the caller learns about an
+	// invalid request now rather than once it is due.
+	if _, _, err := r.parsePolicyUpdate(req.Update); err != nil {
+		return nil, err
+	}
+
+	if req.StartTime <= 0 {
+		return nil, status.Error(
+			codes.InvalidArgument, "start_time must be set",
+		)
+	}
+
+	// Anything that doesn't fit into a duration is way beyond any
+	// sensible interval, so we reject it.
+	if req.IntervalSeconds > uint64(math.MaxInt64/int64(time.Second)) {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"interval of %v seconds is too big",
+			req.IntervalSeconds)
+	}
+
+	update, err := r.policyScheduler.Schedule(
+		req.Update, time.Unix(req.StartTime, 0),
+		time.Duration(req.IntervalSeconds)*time.Second,
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &lnrpc.PolicyUpdateResponse{
+	return marshalScheduledPolicyUpdate(update), nil
+}
+
+// ListScheduledPolicyUpdates returns all scheduled channel policy updates.
+func (r *rpcServer) ListScheduledPolicyUpdates(_ context.Context,
+	_ *lnrpc.ListScheduledPolicyUpdatesRequest) (
+	*lnrpc.ListScheduledPolicyUpdatesResponse, error) {
+
+	updates := r.policyScheduler.ScheduledUpdates()
+
+	resp := &lnrpc.ListScheduledPolicyUpdatesResponse{
+		Updates: make([]*lnrpc.ScheduledPolicyUpdate, 0, len(updates)),
+	}
+	for _, update := range updates {
+		resp.Updates = append(
+			resp.Updates, marshalScheduledPolicyUpdate(update),
+		)
+	}
+
+	return resp, nil
+}
+
+// CancelScheduledPolicyUpdate removes a scheduled channel policy update.
+func (r *rpcServer) CancelScheduledPolicyUpdate(_ context.Context,
+	req *lnrpc.CancelScheduledPolicyUpdateRequest) (
+	*lnrpc.CancelScheduledPolicyUpdateResponse, error) {
+
+	err := r.policyScheduler.Cancel(req.Id)
+	if errors.Is(err, localchans.ErrScheduledUpdateNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.CancelScheduledPolicyUpdateResponse{}, nil
+}
+
+// applyScheduledPolicyUpdate applies a channel policy update on behalf of the
+// policy update scheduler.
+func (r *rpcServer) applyScheduledPolicyUpdate(
+	req *lnrpc.PolicyUpdateRequest) error {
+
+	resp, err := r.UpdateChannelPolicy(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	for _, failed := range resp.FailedUpdates {
+		rpcsLog.Warnf("Scheduled policy update failed for channel "+
+			"%v: %v", failed.Outpoint, failed.UpdateError)
+	}
+
+	return nil
+}
+
+// marshalScheduledPolicyUpdate converts a scheduled policy update into its
+// RPC counterpart.
+func marshalScheduledPolicyUpdate(
+	update *localchans.ScheduledUpdate) *lnrpc.ScheduledPolicyUpdate {
+
+	return &lnrpc.ScheduledPolicyUpdate{
+		Id:              update.ID,
+		Update:          update.Request,
+		NextRun:         update.NextRun.Unix(),
+		IntervalSeconds: uint64(update.Interval / time.Second),
+		LastError:       update.LastError,
+	}
+}
+
+// marshalCommitFeePolicy converts a commitment fee policy into its RPC
+// representation.
+func marshalCommitFeePolicy(
+	policy channeldb.CommitFeePolicy) *lnrpc.CommitFeePolicy {
+
+	return &lnrpc.CommitFeePolicy{
+		ConfTarget: policy.ConfTarget,
+		MinSatPerVbyte: uint64(
+			policy.MinFeeRate.FeePerKVByte() / 1000,
+		),
+		MaxSatPerVbyte: uint64(
+			policy.MaxFeeRate.FeePerKVByte() / 1000,
+		),
+	}
+}
+
+// unmarshalCommitFeePolicy converts the RPC representation of a commitment
+// fee policy into a validated channeldb.CommitFeePolicy.
+func unmarshalCommitFeePolicy(
+	policy *lnrpc.CommitFeePolicy) (channeldb.CommitFeePolicy, error) {
+
+	if policy == nil {
+		return channeldb.CommitFeePolicy{}, nil
+	}
+
+	commitFeePolicy := channeldb.CommitFeePolicy{
+		ConfTarget: policy.ConfTarget,
+		MinFeeRate: chainfee.SatPerKVByte(
+			policy.MinSatPerVbyte * 1000,
+		).FeePerKWeight(),
+		MaxFeeRate: chainfee.SatPerKVByte(
+			policy.MaxSatPerVbyte * 1000,
+		).FeePerKWeight(),
+	}
+	if err := commitFeePolicy.Validate(); err != nil {
+		return channeldb.CommitFeePolicy{}, err
+	}
+
+	return commitFeePolicy, nil
+}
+
+// UpdateCommitFeePolicy allows the caller to update the policy that governs
+// the commitment fee rate for all channels globally, or a particular channel.
+func (r *rpcServer) UpdateCommitFeePolicy(_ context.Context,
+	req *lnrpc.CommitFeePolicyUpdateRequest) (
+	*lnrpc.CommitFeePolicyUpdateResponse, error) {
+
+	policy, err := unmarshalCommitFeePolicy(req.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		channels      []*channeldb.OpenChannel
+		failedUpdates []*lnrpc.FailedUpdate
+	)
+	failUpdate := func(chanPoint wire.OutPoint,
+		reason lnrpc.UpdateFailure, err error) {
+
+		failedUpdates = append(failedUpdates, &lnrpc.FailedUpdate{
+			Outpoint:    lnrpc.MarshalOutPoint(&chanPoint),
+			Reason:      reason,
+			UpdateError: err.Error(),
+		})
+	}
+
+	switch scope := req.Scope.(type) {
+	// If the request is targeting all channels, then we'll update all of
+	// our open channels.
+	case *lnrpc.CommitFeePolicyUpdateRequest_Global:
+		channels, err = r.server.chanStateDB.FetchAllOpenChannels()
+		if err != nil {
+			return nil, err
+		}
+
+	// Otherwise, we're targeting an individual channel by its channel
+	// point.
+	case *lnrpc.CommitFeePolicyUpdateRequest_ChanPoint:
+		txid, err := lnrpc.GetChanPointFundingTxid(scope.ChanPoint)
+		if err != nil {
+			return nil, err
+		}
+		chanPoint := wire.OutPoint{
+			Hash:  *txid,
+			Index: scope.ChanPoint.OutputIndex,
+		}
+
+		channel, err := r.server.chanStateDB.FetchChannel(
+			nil, chanPoint,
+		)
+		switch {
+		case errors.Is(err, channeldb.ErrChannelNotFound):
+			failUpdate(
+				chanPoint,
+				lnrpc.UpdateFailure_UPDATE_FAILURE_NOT_FOUND,
+				err,
+			)
+
+		case err != nil:
+			return nil, err
+
+		default:
+			channels = append(channels, channel)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown scope: %v", scope)
+	}
+
+	rpcsLog.Debugf("[updatecommitfeepolicy] updating commitment fee "+
+		"policy conf_target=%v, min_fee_rate=%v, max_fee_rate=%v for "+
+		"%d channels", policy.ConfTarget, policy.MinFeeRate,
+		policy.MaxFeeRate, len(channels))
+
+	for _, channel := range channels {
+		// If the channel has an active link, we'll update the policy
+		// through it so that the link's channel state picks up the
+		// new policy. Otherwise, it's only persisted.
+		chanPoint := channel.FundingOutpoint
+		chanID := lnwire.NewChanIDFromOutPoint(chanPoint)
+		link, err := r.server.htlcSwitch.GetLink(chanID)
+		if err == nil {
+			err = link.UpdateCommitFeePolicy(policy)
+		} else {
+			err = channel.SetCommitFeePolicy(policy)
+		}
+		if err != nil {
+			rpcsLog.Errorf("Unable to update commitment fee "+
+				"policy of channel %v: %v", chanPoint, err)
+
+			failUpdate(
+				chanPoint,
+				lnrpc.UpdateFailure_UPDATE_FAILURE_INTERNAL_ERR,
+				err,
+			)
+		}
+	}
+
+	return &lnrpc.CommitFeePolicyUpdateResponse{
 		FailedUpdates: failedUpdates,
 	}, nil
 }
 
+// SetChannelSweepBudget sets the budget used when sweeping the outputs of a
+// channel after it has been force closed. Values that are not set fall back
+// to the global budget, and an empty budget removes the channel's budget.
+func (r *rpcServer) SetChannelSweepBudget(_ context.Context,
+	req *lnrpc.SetChannelSweepBudgetRequest) (
+	*lnrpc.SetChannelSweepBudgetResponse, error) {
+
+	if req.ChanPoint == nil {
+		return nil, fmt.Errorf("chan_point must be set")
+	}
+
+	txid, err := lnrpc.GetChanPointFundingTxid(req.ChanPoint)
+	if err != nil {
+		return nil, err
+	}
+	chanPoint := wire.OutPoint{
+		Hash:  *txid,
+		Index: req.ChanPoint.OutputIndex,
+	}
+
+	var budget contractcourt.BudgetConfig
+	if b := req.Budget; b != nil {
+		budget = contractcourt.BudgetConfig{
+			ToLocal:           btcutil.Amount(b.ToLocalSat),
+			ToLocalRatio:      b.ToLocalRatio,
+			AnchorCPFP:        btcutil.Amount(b.AnchorCpfpSat),
+			AnchorCPFPRatio:   b.AnchorCpfpRatio,
+			DeadlineHTLC:      btcutil.Amount(b.DeadlineHtlcSat),
+			DeadlineHTLCRatio: b.DeadlineHtlcRatio,
+			NoDeadlineHTLC: btcutil.Amount(
+				b.NoDeadlineHtlcSat,
+			),
+			NoDeadlineHTLCRatio: b.NoDeadlineHtlcRatio,
+			Total:               btcutil.Amount(b.TotalSat),
+		}
+	}
+
+	rpcsLog.Debugf("[setchannelsweepbudget] chan_point=%v", chanPoint)
+
+	err = r.server.chainArb.SetChannelBudget(chanPoint, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.SetChannelSweepBudgetResponse{}, nil
+}
+
+// ListChannelSweepBudgets returns the sweep budgets of all channels that have
+// a per-channel budget set.
+func (r *rpcServer) ListChannelSweepBudgets(_ context.Context,
+	_ *lnrpc.ListChannelSweepBudgetsRequest) (
+	*lnrpc.ListChannelSweepBudgetsResponse, error) {
+
+	budgets := r.server.chainArb.ChannelBudgets()
+
+	resp := &lnrpc.ListChannelSweepBudgetsResponse{
+		Budgets: make([]*lnrpc.ChannelSweepBudget, 0, len(budgets)),
+	}
+	for chanPoint, b := range budgets {
+		resp.Budgets = append(resp.Budgets, &lnrpc.ChannelSweepBudget{
+			ChannelPoint: chanPoint.String(),
+			Budget: &lnrpc.SweepBudget{
+				ToLocalSat:          uint64(b.ToLocal),
+				ToLocalRatio:        b.ToLocalRatio,
+				AnchorCpfpSat:       uint64(b.AnchorCPFP),
+				AnchorCpfpRatio:     b.AnchorCPFPRatio,
+				DeadlineHtlcSat:     uint64(b.DeadlineHTLC),
+				DeadlineHtlcRatio:   b.DeadlineHTLCRatio,
+				NoDeadlineHtlcSat:   uint64(b.NoDeadlineHTLC),
+				NoDeadlineHtlcRatio: b.NoDeadlineHTLCRatio,
+				TotalSat:            uint64(b.Total),
+			},
+		})
+	}
+
+	sort.Slice(resp.Budgets, func(i, j int) bool {
+		return resp.Budgets[i].ChannelPoint <
+			resp.Budgets[j].ChannelPoint
+	})
+
+	return resp, nil
+}
+
 // ForwardingHistory allows the caller to query the htlcswitch for a record of
 // all HTLC's forwarded within the target time range, and integer offset within
 // that time range. If no time-range is specified, then the first chunk of the
@@ -7845,6 +9022,73 @@ func (r *rpcServer) ChannelAcceptor(stream lnrpc.Lightning_ChannelAcceptorServer
 	return rpcAcceptor.Run()
 }
 
+// SetPeerChannelPolicy sets the policy that is applied to inbound channels
+// opened by a specific peer.
+func (r *rpcServer) SetPeerChannelPolicy(_ context.Context,
+	in *lnrpc.SetPeerChannelPolicyRequest) (
+	*lnrpc.SetPeerChannelPolicyResponse, error) {
+
+	peer, err := route.NewVertexFromBytes(in.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pub_key: %w", err)
+	}
+
+	// Neither setting has any effect if the corresponding protocol option
+	// isn't enabled, so we reject them to avoid any confusion.
+	if in.AllowZeroConf && !r.cfg.ProtocolOptions.ZeroConf() {
+		return nil, fmt.Errorf("cannot allow zero-conf channels " +
+			"without the zero-conf protocol option")
+	}
+	if in.RequireScidAlias && !r.cfg.ProtocolOptions.ScidAlias() {
+		return nil, fmt.Errorf("cannot require scid-alias channels " +
+			"without the scid-alias protocol option")
+	}
+
+	err = r.peerPolicies.SetPolicy(peer, channeldb.PeerChannelPolicy{
+		AllowZeroConf:    in.AllowZeroConf,
+		RequireScidAlias: in.RequireScidAlias,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rpcsLog.Infof("Set channel policy of peer %v: allow_zero_conf=%v, "+
+		"require_scid_alias=%v", peer, in.AllowZeroConf,
+		in.RequireScidAlias)
+
+	return &lnrpc.SetPeerChannelPolicyResponse{}, nil
+}
+
+// ListPeerChannelPolicies returns the inbound channel policies of all peers
+// that have one set.
+func (r *rpcServer) ListPeerChannelPolicies(_ context.Context,
+	_ *lnrpc.ListPeerChannelPoliciesRequest) (
+	*lnrpc.ListPeerChannelPoliciesResponse, error) {
+
+	policies := r.peerPolicies.Policies()
+
+	resp := &lnrpc.ListPeerChannelPoliciesResponse{
+		Policies: make([]*lnrpc.PeerChannelPolicy, 0, len(policies)),
+	}
+	for peer, policy := range policies {
+		peer := peer
+		resp.Policies = append(resp.Policies, &lnrpc.PeerChannelPolicy{
+			PubKey:           peer[:],
+			AllowZeroConf:    policy.AllowZeroConf,
+			RequireScidAlias: policy.RequireScidAlias,
+		})
+	}
+
+	// Sort the policies by pubkey to return them in a stable order.
+	sort.Slice(resp.Policies, func(i, j int) bool {
+		return bytes.Compare(
+			resp.Policies[i].PubKey, resp.Policies[j].PubKey,
+		) < 0
+	})
+
+	return resp, nil
+}
+
 // BakeMacaroon allows the creation of a new macaroon with custom read and write
 // permissions. No first-party caveats are added since this can be done offline.
 // If the --allow-external-permissions flag is set, the RPC will allow
@@ -8432,3 +9676,13 @@ func rpcInitiator(isInitiator bool) lnrpc.Initiator {
 
 	return lnrpc.Initiator_INITIATOR_REMOTE
 }
+
+// newHopHintScoring returns the hop hint scoring that is configured in the
+// invoices config.
+func newHopHintScoring(cfg *lncfg.Invoices) invoicesrpc.HopHintScoring {
+	return invoicesrpc.HopHintScoring{
+		CapacityWeight:      cfg.HopHintCapacityWeight,
+		RemoteBalanceWeight: cfg.HopHintBalanceWeight,
+		UptimeWeight:        cfg.HopHintUptimeWeight,
+	}
+}
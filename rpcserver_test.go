@@ -1,9 +1,26 @@
 package lnd
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"testing"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/lightningnetwork/lnd/chainreg"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/funding"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwallet/chancloser"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetAllPermissions(t *testing.T) {
@@ -12,3 +29,172 @@ func TestGetAllPermissions(t *testing.T) {
 	// Currently there are there are 16 entity:action pairs in use.
 	assert.Equal(t, len(perms), 16)
 }
+
+// TestParseBatchCloseChanPoints tests that the channel points of a batch close
+// request are parsed in order and that invalid requests are rejected.
+func TestParseBatchCloseChanPoints(t *testing.T) {
+	outPoint := func(txid byte, index uint32) *wire.OutPoint {
+		var hash chainhash.Hash
+		copy(hash[:], bytes.Repeat([]byte{txid}, chainhash.HashSize))
+
+		return wire.NewOutPoint(&hash, index)
+	}
+	chanPoint := func(txid byte, index uint32) *lnrpc.ChannelPoint {
+		op := outPoint(txid, index)
+
+		return &lnrpc.ChannelPoint{
+			FundingTxid: &lnrpc.ChannelPoint_FundingTxidBytes{
+				FundingTxidBytes: op.Hash[:],
+			},
+			OutputIndex: op.Index,
+		}
+	}
+
+	// An empty request is rejected.
+	_, err := parseBatchCloseChanPoints(nil)
+	require.Error(t, err)
+
+	// A nil channel point is rejected.
+	_, err = parseBatchCloseChanPoints([]*lnrpc.ChannelPoint{nil})
+	require.Error(t, err)
+
+	// Duplicate channel points are rejected.
+	_, err = parseBatchCloseChanPoints([]*lnrpc.ChannelPoint{
+		chanPoint(1, 0), chanPoint(2, 0), chanPoint(1, 0),
+	})
+	require.ErrorContains(t, err, "duplicate")
+
+	// Distinct channel points, including ones that only differ in their
+	// output index, are returned in the order of the request.
+	chanPoints, err := parseBatchCloseChanPoints([]*lnrpc.ChannelPoint{
+		chanPoint(2, 1), chanPoint(1, 0), chanPoint(2, 0),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*wire.OutPoint{
+		outPoint(2, 1), outPoint(1, 0), outPoint(2, 0),
+	}, chanPoints)
+}
+
+// TestCheckBatchDeliveryAddress tests that a delivery address is only used for
+// several channels of a batch close if the caller allows it.
+func TestCheckBatchDeliveryAddress(t *testing.T) {
+	const addr = "bcrt1qxyz"
+
+	require.NoError(t, checkBatchDeliveryAddress("", 3, false))
+	require.NoError(t, checkBatchDeliveryAddress(addr, 1, false))
+	require.NoError(t, checkBatchDeliveryAddress(addr, 3, true))
+	require.Error(t, checkBatchDeliveryAddress(addr, 3, false))
+}
+
+// TestBatchCloseMaxFeeRates tests that the fee budget of a batch close is
+// split between the channels and caps their maximum fee rates.
+func TestBatchCloseMaxFeeRates(t *testing.T) {
+	channels := []*channeldb.OpenChannel{
+		{ChanType: channeldb.SingleFunderTweaklessBit},
+		{ChanType: channeldb.SimpleTaprootFeatureBit},
+	}
+	const feeRate = chainfee.SatPerKWeight(253)
+
+	// Without a budget, the given maximum fee rate is used as is.
+	maxFees, err := batchCloseMaxFeeRates(channels, feeRate, 1000, 0)
+	require.NoError(t, err)
+	require.Equal(t, []chainfee.SatPerKWeight{1000, 1000}, maxFees)
+
+	// With a budget, every channel gets half of it. As no maximum fee
+	// rate is given, the budget determines the maximum fee rates.
+	const budget = btcutil.Amount(10_000)
+	budgetFees := []chainfee.SatPerKWeight{
+		chancloser.MaxCoopCloseFeeRate(channels[0].ChanType, budget/2),
+		chancloser.MaxCoopCloseFeeRate(channels[1].ChanType, budget/2),
+	}
+	maxFees, err = batchCloseMaxFeeRates(channels, feeRate, 0, budget)
+	require.NoError(t, err)
+	require.Equal(t, budgetFees, maxFees)
+
+	// A maximum fee rate below the budget is kept.
+	maxFees, err = batchCloseMaxFeeRates(channels, feeRate, 1000, budget)
+	require.NoError(t, err)
+	require.Equal(t, []chainfee.SatPerKWeight{1000, 1000}, maxFees)
+
+	// A fee rate that already exceeds the budget is rejected.
+	_, err = batchCloseMaxFeeRates(channels, feeRate, 0, 100)
+	require.Error(t, err)
+}
+
+// TestSendCoinsAllowlist tests that SendCoins and SendMany refuse to pay out
+// to addresses that aren't part of the allowlist of close addresses.
+func TestSendCoinsAllowlist(t *testing.T) {
+	netParams := chainreg.BitcoinRegTestNetParams
+	newAddr := func() string {
+		privKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(
+			btcutil.Hash160(
+				privKey.PubKey().SerializeCompressed(),
+			), netParams.Params,
+		)
+		require.NoError(t, err)
+
+		return addr.String()
+	}
+	allowedAddr, otherAddr := newAddr(), newAddr()
+
+	allowlist, err := chancloser.NewDeliveryAllowlist(
+		[]string{allowedAddr}, netParams.Params,
+		func(btcutil.Address) (waddrmgr.ManagedAddress, error) {
+			return nil, fmt.Errorf("unknown address")
+		},
+	)
+	require.NoError(t, err)
+
+	cc := &chainreg.ChainControl{
+		PartialChainControl: &chainreg.PartialChainControl{
+			FeeEstimator: chainfee.NewStaticEstimator(
+				chainfee.FeePerKwFloor, chainfee.FeePerKwFloor,
+			),
+		},
+	}
+	r := &rpcServer{
+		cfg: &Config{ActiveNetParams: netParams},
+		server: &server{
+			cc:                cc,
+			fundingMgr:        &funding.Manager{},
+			deliveryAllowlist: allowlist,
+		},
+	}
+
+	requireNotAllowed := func(err error) {
+		require.Error(t, err)
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		require.ErrorContains(
+			t, err, chancloser.ErrDeliveryAddrNotAllowed.Error(),
+		)
+	}
+
+	// Sending an amount to an address that isn't allowed is rejected.
+	_, err = r.SendCoins(context.Background(), &lnrpc.SendCoinsRequest{
+		Addr:        otherAddr,
+		Amount:      100_000,
+		SatPerVbyte: 10,
+	})
+	requireNotAllowed(err)
+
+	// So is sweeping the whole wallet to it.
+	_, err = r.SendCoins(context.Background(), &lnrpc.SendCoinsRequest{
+		Addr:        otherAddr,
+		SendAll:     true,
+		SatPerVbyte: 10,
+	})
+	requireNotAllowed(err)
+
+	// SendMany is rejected if any of its outputs isn't allowed.
+	_, err = r.SendMany(context.Background(), &lnrpc.SendManyRequest{
+		AddrToAmount: map[string]int64{
+			allowedAddr: 100_000,
+			otherAddr:   100_000,
+		},
+		SatPerVbyte: 10,
+	})
+	requireNotAllowed(err)
+}
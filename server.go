@@ -54,6 +54,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwallet/chancloser"
 	"github.com/lightningnetwork/lnd/lnwallet/chanfunding"
 	"github.com/lightningnetwork/lnd/lnwallet/rpcwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -63,6 +64,7 @@ import (
 	"github.com/lightningnetwork/lnd/peernotifier"
 	"github.com/lightningnetwork/lnd/pool"
 	"github.com/lightningnetwork/lnd/queue"
+	"github.com/lightningnetwork/lnd/rgs"
 	"github.com/lightningnetwork/lnd/routing"
 	"github.com/lightningnetwork/lnd/routing/localchans"
 	"github.com/lightningnetwork/lnd/routing/route"
@@ -103,6 +105,10 @@ const (
 	// multiAddrConnectionStagger is the number of seconds to wait between
 	// attempting to a peer with each of its advertised addresses.
 	multiAddrConnectionStagger = 10 * time.Second
+
+	// failedAttemptsPruneInterval is the interval at which the failed
+	// attempts of old payments are pruned, if enabled.
+	failedAttemptsPruneInterval = time.Hour
 )
 
 var (
@@ -238,6 +244,10 @@ type server struct {
 
 	fundingMgr *funding.Manager
 
+	// deliveryAllowlist restricts the external addresses that cooperative
+	// closes and on-chain sends may pay out to.
+	deliveryAllowlist *chancloser.DeliveryAllowlist
+
 	graphDB *channeldb.ChannelGraph
 
 	chanStateDB *channeldb.ChannelStateDB
@@ -268,8 +278,18 @@ type server struct {
 
 	breachArbitrator *contractcourt.BreachArbitrator
 
+	// mcManager manages the mission control instances of all
+	// namespaces.
+	mcManager *routing.MissionControlManager
+
+	// missionControl is the mission control instance of the default
+	// namespace.
 	missionControl *routing.MissionControl
 
+	// nodeScores holds the node scores that were imported from external
+	// sources.
+	nodeScores *routing.NodeScoreStore
+
 	chanRouter *routing.ChannelRouter
 
 	controlTower routing.ControlTower
@@ -322,6 +342,10 @@ type server struct {
 
 	hostAnn *netann.HostAnnouncer
 
+	// rgsServer serves rapid gossip sync snapshots of the graph if it is
+	// enabled.
+	rgsServer *rgs.Server
+
 	// livenessMonitor monitors that lnd has access to critical resources.
 	livenessMonitor *healthcheck.Monitor
 
@@ -564,6 +588,8 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		GcCanceledInvoicesOnStartup: cfg.GcCanceledInvoicesOnStartup,
 		GcCanceledInvoicesOnTheFly:  cfg.GcCanceledInvoicesOnTheFly,
 		KeysendHoldTime:             cfg.KeysendHoldTime,
+		KeysendPolicy:               cfg.Keysend.Policy(),
+		ExpiryWatcherWindow:         cfg.Invoices.ExpiryWatcherWindow,
 	}
 
 	s := &server{
@@ -930,18 +956,32 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		McFlushInterval:         routingConfig.McFlushInterval,
 		MinFailureRelaxInterval: routing.DefaultMinFailureRelaxInterval,
 	}
-	s.missionControl, err = routing.NewMissionControl(
+	s.mcManager, err = routing.NewMissionControlManager(
 		dbs.ChanStateDB, selfNode.PubKeyBytes, mcCfg,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("can't create mission control "+
+			"manager: %w", err)
+	}
+
+	s.missionControl, err = s.mcManager.GetNamespacedStore(
+		routing.DefaultMissionControlNamespace,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("can't create mission control: %w", err)
 	}
 
+	if routingConfig.CltvRiskFactor < 0 {
+		return nil, fmt.Errorf("cltv risk factor must not be "+
+			"negative: %v", routingConfig.CltvRiskFactor)
+	}
+
 	srvrLog.Debugf("Instantiating payment session source with config: "+
-		"AttemptCost=%v + %v%%, MinRouteProbability=%v",
-		int64(routingConfig.AttemptCost),
+		"AttemptCost=%v + %v%%, MinRouteProbability=%v, "+
+		"CltvRiskFactor=%v", int64(routingConfig.AttemptCost),
 		float64(routingConfig.AttemptCostPPM)/10000,
-		routingConfig.MinRouteProbability)
+		routingConfig.MinRouteProbability,
+		routingConfig.CltvRiskFactor)
 
 	pathFindingConfig := routing.PathFindingConfig{
 		AttemptCost: lnwire.NewMSatFromSatoshis(
@@ -949,18 +989,47 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		),
 		AttemptCostPPM: routingConfig.AttemptCostPPM,
 		MinProbability: routingConfig.MinRouteProbability,
+		CltvRiskFactor: routingConfig.CltvRiskFactor,
 	}
 
 	sourceNode, err := chanGraph.SourceNode()
 	if err != nil {
 		return nil, fmt.Errorf("error getting source node: %w", err)
 	}
+	var routeCache *routing.RouteCache
+	if routingConfig.RouteCacheSize > 0 {
+		routeCache = routing.NewRouteCache(
+			routingConfig.RouteCacheSize,
+			routingConfig.RouteCacheTTL,
+		)
+	}
+
+	shardSplitter, err := routing.NewShardSplitter(
+		routingConfig.SplitStrategy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	paymentSessionSource := &routing.SessionSource{
 		Graph:             chanGraph,
 		SourceNode:        sourceNode,
 		MissionControl:    s.missionControl,
 		GetLink:           s.htlcSwitch.GetLinkByShortID,
 		PathFindingConfig: pathFindingConfig,
+		RouteCache:        routeCache,
+		ShardSplitter:     shardSplitter,
+	}
+
+	// Imported node scores can always be queried, but pathfinding only
+	// takes them into account if enabled.
+	s.nodeScores, err = routing.NewNodeScoreStore(dbs.ChanStateDB)
+	if err != nil {
+		return nil, fmt.Errorf("can't create node score store: %w",
+			err)
+	}
+	if routingConfig.UseNodeScores {
+		paymentSessionSource.NodeScores = s.nodeScores
 	}
 
 	paymentControl := channeldb.NewPaymentControl(dbs.ChanStateDB)
@@ -978,8 +1047,8 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		Control:             s.controlTower,
 		MissionControl:      s.missionControl,
 		SessionSource:       paymentSessionSource,
-		ChannelPruneExpiry:  routing.DefaultChannelPruneExpiry,
-		GraphPruneInterval:  time.Hour,
+		ChannelPruneExpiry:  cfg.Routing.ZombieExpiry,
+		GraphPruneInterval:  cfg.Routing.ZombiePruneInterval,
 		FirstTimePruneDelay: routing.DefaultFirstTimePruneDelay,
 		GetLink:             s.htlcSwitch.GetLinkByShortID,
 		AssumeChannelValid:  cfg.Routing.AssumeChannelValid,
@@ -988,6 +1057,18 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		Clock:               clock.NewDefaultClock(),
 		StrictZombiePruning: strictPruning,
 		IsAlias:             aliasmgr.IsAlias,
+		RouteCache:          routeCache,
+		StalePaymentTimeout: routingConfig.StalePaymentTimeout,
+		GetMissionControl: func(namespace string) (
+			routing.MissionController, error) {
+
+			mc, err := s.mcManager.GetNamespacedStore(namespace)
+			if err != nil {
+				return nil, err
+			}
+
+			return mc, nil
+		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("can't create router: %w", err)
@@ -1101,6 +1182,16 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		Store:               utxnStore,
 		SweepInput:          s.sweeper.SweepInput,
 		Budget:              s.cfg.Sweeper.Budget,
+		FetchBudget: func(
+			chanPoint wire.OutPoint) contractcourt.BudgetConfig {
+
+			return s.chainArb.ChannelBudget(chanPoint)
+		},
+		AllocateBudget: func(chanPoint, op wire.OutPoint,
+			budget btcutil.Amount) btcutil.Amount {
+
+			return s.chainArb.AllocateBudget(chanPoint, op, budget)
+		},
 	})
 
 	// Construct a closure that wraps the htlcswitch's CloseLink method.
@@ -1320,6 +1411,14 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 			devCfg, reservationTimeout, zombieSweeperInterval)
 	}
 
+	s.deliveryAllowlist, err = chancloser.NewDeliveryAllowlist(
+		cfg.AllowedCloseAddrs, cfg.ActiveNetParams.Params,
+		cc.Wallet.AddressInfo,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowcloseaddr: %w", err)
+	}
+
 	//nolint:lll
 	s.fundingMgr, err = funding.NewFundingManager(funding.Config{
 		Dev:                devCfg,
@@ -1488,6 +1587,7 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		OpenChannelPredicate:          chanPredicate,
 		NotifyPendingOpenChannelEvent: s.channelNotifier.NotifyPendingOpenChannelEvent,
 		EnableUpfrontShutdown:         cfg.EnableUpfrontShutdown,
+		CheckShutdownScript:           s.deliveryAllowlist.Check,
 		MaxAnchorsCommitFeeRate: chainfee.SatPerKVByte(
 			s.cfg.MaxCommitFeeRateAnchors * 1000).FeePerKWeight(),
 		DeleteAliasEdge:   deleteAliasEdge,
@@ -1656,6 +1756,16 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 		})
 	}
 
+	if cfg.RGS.Active {
+		s.rgsServer = rgs.NewServer(&rgs.Config{
+			Graph:            s.graphDB,
+			ChainHash:        *s.cfg.ActiveNetParams.GenesisHash,
+			ListenAddr:       cfg.RGS.Listen,
+			SnapshotInterval: cfg.RGS.SnapshotInterval,
+			Now:              time.Now,
+		})
+	}
+
 	// Create liveness monitor.
 	s.createLivenessMonitor(cfg, cc)
 
@@ -1680,6 +1790,18 @@ func newServer(cfg *Config, listenAddrs []net.Addr,
 	return s, nil
 }
 
+// checkSendScript returns an error if wallet funds may not be sent to the
+// given output script. Next to the scripts accepted by the delivery allowlist,
+// the funding outputs of pending PSBT funding flows are allowed, so channels
+// can still be funded through the wallet kit.
+func (s *server) checkSendScript(script lnwire.DeliveryAddress) error {
+	if s.fundingMgr.IsPsbtFundingScript(script) {
+		return nil
+	}
+
+	return s.deliveryAllowlist.Check(script)
+}
+
 // signAliasUpdate takes a ChannelUpdate and returns the signature. This is
 // used for option_scid_alias channels where the ChannelUpdate to be sent back
 // may differ from what is on disk.
@@ -2046,9 +2168,9 @@ func (s *server) Start() error {
 			return nil
 		})
 
-		s.missionControl.RunStoreTicker()
+		s.mcManager.RunStoreTickers()
 		cleanup.add(func() error {
-			s.missionControl.StopStoreTicker()
+			s.mcManager.StopStoreTickers()
 			return nil
 		})
 
@@ -2090,6 +2212,14 @@ func (s *server) Start() error {
 		}
 		cleanup = cleanup.add(s.chanSubSwapper.Stop)
 
+		if s.rgsServer != nil {
+			if err := s.rgsServer.Start(); err != nil {
+				startErr = err
+				return
+			}
+			cleanup = cleanup.add(s.rgsServer.Stop)
+		}
+
 		if s.torController != nil {
 			if err := s.createNewHiddenService(); err != nil {
 				startErr = err
@@ -2103,6 +2233,11 @@ func (s *server) Start() error {
 			go s.watchExternalIP()
 		}
 
+		if s.cfg.PruneFailedPaymentAttemptsAfter != 0 {
+			s.wg.Add(1)
+			go s.pruneFailedPaymentAttempts()
+		}
+
 		// Start connmgr last to prevent connections before init.
 		s.connMgr.Start()
 		cleanup = cleanup.add(func() error {
@@ -2318,7 +2453,7 @@ func (s *server) Stop() error {
 				err)
 		}
 		s.chanEventStore.Stop()
-		s.missionControl.StopStoreTicker()
+		s.mcManager.StopStoreTickers()
 
 		// Disconnect from each active peers to ensure that
 		// peerTerminationWatchers signal completion to each peer.
@@ -2343,6 +2478,13 @@ func (s *server) Stop() error {
 			}
 		}
 
+		if s.rgsServer != nil {
+			if err := s.rgsServer.Stop(); err != nil {
+				srvrLog.Warnf("unable to shut down rapid "+
+					"gossip sync server: %v", err)
+			}
+		}
+
 		if s.hostAnn != nil {
 			if err := s.hostAnn.Stop(); err != nil {
 				srvrLog.Warnf("unable to shut down host "+
@@ -2417,6 +2559,41 @@ func (s *server) removePortForwarding() {
 	}
 }
 
+// pruneFailedPaymentAttempts periodically deletes the failed attempts of the
+// payments that were created longer ago than the configured retention.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *server) pruneFailedPaymentAttempts() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(failedAttemptsPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		createdBefore := time.Now().Add(
+			-s.cfg.PruneFailedPaymentAttemptsAfter,
+		)
+		stats, err := s.miscDB.PruneFailedAttempts(createdBefore, false)
+		switch {
+		case err != nil:
+			srvrLog.Errorf("Unable to prune failed payment "+
+				"attempts: %v", err)
+
+		case stats.NumAttempts > 0:
+			srvrLog.Infof("Pruned %v failed attempts of %v "+
+				"payments created before %v (%v bytes)",
+				stats.NumAttempts, stats.NumPayments,
+				createdBefore, stats.NumBytes)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
 // watchExternalIP continuously checks for an updated external IP address every
 // 15 minutes. Once a new IP address has been detected, it will automatically
 // handle port forwarding rules and send updated node announcements to the
@@ -2969,6 +3146,30 @@ func (s *server) findChannel(node *btcec.PublicKey, chanID lnwire.ChannelID) (
 	return nil, fmt.Errorf("unable to find channel")
 }
 
+// channelUptime returns the fraction of the monitored lifetime of a channel
+// during which its peer was online. Channels that are not known to the channel
+// event store yet have an uptime of zero.
+func (s *server) channelUptime(chanPoint wire.OutPoint,
+	peer route.Vertex) (float64, error) {
+
+	info, err := s.chanEventStore.GetChanInfo(chanPoint, peer)
+	switch err {
+	case chanfitness.ErrChannelNotFound:
+		return 0, nil
+
+	case nil:
+
+	default:
+		return 0, err
+	}
+
+	if info.Lifetime == 0 {
+		return 0, nil
+	}
+
+	return float64(info.Uptime) / float64(info.Lifetime), nil
+}
+
 // getNodeAnnouncement fetches the current, fully signed node announcement.
 func (s *server) getNodeAnnouncement() lnwire.NodeAnnouncement {
 	s.mu.Lock()
@@ -3897,6 +4098,7 @@ func (s *server) peerConnected(conn net.Conn, connReq *connmgr.ConnReq,
 		MaxOutgoingCltvExpiry:   s.cfg.MaxOutgoingCltvExpiry,
 		MaxChannelFeeAllocation: s.cfg.MaxChannelFeeAllocation,
 		CoopCloseTargetConfs:    s.cfg.CoopCloseTargetConfs,
+		CheckDeliveryScript:     s.deliveryAllowlist.Check,
 		MaxAnchorsCommitFeeRate: chainfee.SatPerKVByte(
 			s.cfg.MaxCommitFeeRateAnchors * 1000).FeePerKWeight(),
 		ChannelCommitInterval:  s.cfg.ChannelCommitInterval,
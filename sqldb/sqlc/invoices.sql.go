@@ -11,6 +11,33 @@ import (
 	"time"
 )
 
+const amendInvoice = `-- name: AmendInvoice :execresult
+UPDATE invoices
+SET memo = $2,
+    expiry = $3,
+    payment_request = $4,
+    payment_request_hash = $5
+WHERE id = $1
+`
+
+type AmendInvoiceParams struct {
+	ID                 int64
+	Memo               sql.NullString
+	Expiry             int32
+	PaymentRequest     sql.NullString
+	PaymentRequestHash []byte
+}
+
+func (q *Queries) AmendInvoice(ctx context.Context, arg AmendInvoiceParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, amendInvoice,
+		arg.ID,
+		arg.Memo,
+		arg.Expiry,
+		arg.PaymentRequest,
+		arg.PaymentRequestHash,
+	)
+}
+
 const deleteCanceledInvoices = `-- name: DeleteCanceledInvoices :execresult
 DELETE
 FROM invoices
@@ -55,6 +82,117 @@ func (q *Queries) DeleteInvoice(ctx context.Context, arg DeleteInvoiceParams) (s
 	)
 }
 
+const deleteInvoiceExpiry = `-- name: DeleteInvoiceExpiry :exec
+DELETE
+FROM invoice_expiries
+WHERE invoice_id = $1
+`
+
+func (q *Queries) DeleteInvoiceExpiry(ctx context.Context, invoiceID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteInvoiceExpiry, invoiceID)
+	return err
+}
+
+const fetchInvoiceExpiries = `-- name: FetchInvoiceExpiries :many
+SELECT i.hash, e.expires_at, e.is_keysend
+FROM invoice_expiries e
+JOIN invoices i ON i.id = e.invoice_id
+WHERE e.expires_at > $1 OR (
+    e.expires_at = $1 AND i.hash > $2
+)
+ORDER BY e.expires_at ASC, i.hash ASC
+LIMIT $3
+`
+
+type FetchInvoiceExpiriesParams struct {
+	ExpiresAfter int64
+	HashAfter    []byte
+	NumLimit     int32
+}
+
+type FetchInvoiceExpiriesRow struct {
+	Hash      []byte
+	ExpiresAt int64
+	IsKeysend bool
+}
+
+func (q *Queries) FetchInvoiceExpiries(ctx context.Context, arg FetchInvoiceExpiriesParams) ([]FetchInvoiceExpiriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, fetchInvoiceExpiries, arg.ExpiresAfter, arg.HashAfter, arg.NumLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FetchInvoiceExpiriesRow
+	for rows.Next() {
+		var i FetchInvoiceExpiriesRow
+		if err := rows.Scan(&i.Hash, &i.ExpiresAt, &i.IsKeysend); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const fetchUnindexedOpenInvoices = `-- name: FetchUnindexedOpenInvoices :many
+SELECT i.id, i.hash, i.preimage, i.settle_index, i.settled_at, i.memo, i.amount_msat, i.cltv_delta, i.expiry, i.payment_addr, i.payment_request, i.payment_request_hash, i.state, i.amount_paid_msat, i.is_amp, i.is_hodl, i.is_keysend, i.created_at
+FROM invoices i
+WHERE i.state = 0 AND NOT EXISTS (
+    SELECT 1
+    FROM invoice_expiries e
+    WHERE e.invoice_id = i.id
+)
+ORDER BY i.id ASC
+LIMIT $1
+`
+
+func (q *Queries) FetchUnindexedOpenInvoices(ctx context.Context, numLimit int32) ([]Invoice, error) {
+	rows, err := q.db.QueryContext(ctx, fetchUnindexedOpenInvoices, numLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Invoice
+	for rows.Next() {
+		var i Invoice
+		if err := rows.Scan(
+			&i.ID,
+			&i.Hash,
+			&i.Preimage,
+			&i.SettleIndex,
+			&i.SettledAt,
+			&i.Memo,
+			&i.AmountMsat,
+			&i.CltvDelta,
+			&i.Expiry,
+			&i.PaymentAddr,
+			&i.PaymentRequest,
+			&i.PaymentRequestHash,
+			&i.State,
+			&i.AmountPaidMsat,
+			&i.IsAmp,
+			&i.IsHodl,
+			&i.IsKeysend,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const filterInvoices = `-- name: FilterInvoices :many
 SELECT
     invoices.id, invoices.hash, invoices.preimage, invoices.settle_index, invoices.settled_at, invoices.memo, invoices.amount_msat, invoices.cltv_delta, invoices.expiry, invoices.payment_addr, invoices.payment_request, invoices.payment_request_hash, invoices.state, invoices.amount_paid_msat, invoices.is_amp, invoices.is_hodl, invoices.is_keysend, invoices.created_at
@@ -85,17 +223,28 @@ WHERE (
         WHEN $8 = TRUE THEN (state = 0 OR state = 3)
         ELSE TRUE 
     END
+) AND (
+    EXISTS (
+        SELECT 1
+        FROM invoice_metadata m
+        WHERE m.invoice_id = invoices.id AND
+            m.key = $9 AND (
+                m.value = $10 OR
+                $10 IS NULL
+            )
+    ) OR
+    $9 IS NULL
 )
 ORDER BY
 CASE
-    WHEN $9 = FALSE OR $9 IS NULL THEN id
+    WHEN $11 = FALSE OR $11 IS NULL THEN id
     ELSE NULL
     END ASC,
 CASE
-    WHEN $9 = TRUE THEN id
+    WHEN $11 = TRUE THEN id
     ELSE NULL
 END DESC
-LIMIT $11 OFFSET $10
+LIMIT $13 OFFSET $12
 `
 
 type FilterInvoicesParams struct {
@@ -107,6 +256,8 @@ type FilterInvoicesParams struct {
 	CreatedAfter   sql.NullTime
 	CreatedBefore  sql.NullTime
 	PendingOnly    interface{}
+	MetadataKey    sql.NullString
+	MetadataValue  sql.NullString
 	Reverse        interface{}
 	NumOffset      int32
 	NumLimit       int32
@@ -122,6 +273,8 @@ func (q *Queries) FilterInvoices(ctx context.Context, arg FilterInvoicesParams)
 		arg.CreatedAfter,
 		arg.CreatedBefore,
 		arg.PendingOnly,
+		arg.MetadataKey,
+		arg.MetadataValue,
 		arg.Reverse,
 		arg.NumOffset,
 		arg.NumLimit,
@@ -355,6 +508,53 @@ func (q *Queries) GetInvoiceHTLCs(ctx context.Context, invoiceID int64) ([]Invoi
 	return items, nil
 }
 
+const getInvoiceHodlPolicy = `-- name: GetInvoiceHodlPolicy :one
+SELECT invoice_id, hold_duration, action, preimage
+FROM invoice_hodl_policies
+WHERE invoice_id = $1
+`
+
+func (q *Queries) GetInvoiceHodlPolicy(ctx context.Context, invoiceID int64) (InvoiceHodlPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getInvoiceHodlPolicy, invoiceID)
+	var i InvoiceHodlPolicy
+	err := row.Scan(
+		&i.InvoiceID,
+		&i.HoldDuration,
+		&i.Action,
+		&i.Preimage,
+	)
+	return i, err
+}
+
+const getInvoiceMetadata = `-- name: GetInvoiceMetadata :many
+SELECT invoice_id, key, value
+FROM invoice_metadata
+WHERE invoice_id = $1
+`
+
+func (q *Queries) GetInvoiceMetadata(ctx context.Context, invoiceID int64) ([]InvoiceMetadatum, error) {
+	rows, err := q.db.QueryContext(ctx, getInvoiceMetadata, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []InvoiceMetadatum
+	for rows.Next() {
+		var i InvoiceMetadatum
+		if err := rows.Scan(&i.InvoiceID, &i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertInvoice = `-- name: InsertInvoice :one
 INSERT INTO invoices (
     hash, preimage, memo, amount_msat, cltv_delta, expiry, payment_addr, 
@@ -406,6 +606,25 @@ func (q *Queries) InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (i
 	return id, err
 }
 
+const insertInvoiceExpiry = `-- name: InsertInvoiceExpiry :exec
+INSERT INTO invoice_expiries (
+    invoice_id, expires_at, is_keysend
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type InsertInvoiceExpiryParams struct {
+	InvoiceID int64
+	ExpiresAt int64
+	IsKeysend bool
+}
+
+func (q *Queries) InsertInvoiceExpiry(ctx context.Context, arg InsertInvoiceExpiryParams) error {
+	_, err := q.db.ExecContext(ctx, insertInvoiceExpiry, arg.InvoiceID, arg.ExpiresAt, arg.IsKeysend)
+	return err
+}
+
 const insertInvoiceFeature = `-- name: InsertInvoiceFeature :exec
 INSERT INTO invoice_features (
     invoice_id, feature
@@ -483,6 +702,50 @@ func (q *Queries) InsertInvoiceHTLCCustomRecord(ctx context.Context, arg InsertI
 	return err
 }
 
+const insertInvoiceHodlPolicy = `-- name: InsertInvoiceHodlPolicy :exec
+INSERT INTO invoice_hodl_policies (
+    invoice_id, hold_duration, action, preimage
+) VALUES (
+    $1, $2, $3, $4
+)
+`
+
+type InsertInvoiceHodlPolicyParams struct {
+	InvoiceID    int64
+	HoldDuration int64
+	Action       int16
+	Preimage     []byte
+}
+
+func (q *Queries) InsertInvoiceHodlPolicy(ctx context.Context, arg InsertInvoiceHodlPolicyParams) error {
+	_, err := q.db.ExecContext(ctx, insertInvoiceHodlPolicy,
+		arg.InvoiceID,
+		arg.HoldDuration,
+		arg.Action,
+		arg.Preimage,
+	)
+	return err
+}
+
+const insertInvoiceMetadata = `-- name: InsertInvoiceMetadata :exec
+INSERT INTO invoice_metadata (
+    invoice_id, key, value
+) VALUES (
+    $1, $2, $3
+)
+`
+
+type InsertInvoiceMetadataParams struct {
+	InvoiceID int64
+	Key       string
+	Value     string
+}
+
+func (q *Queries) InsertInvoiceMetadata(ctx context.Context, arg InsertInvoiceMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, insertInvoiceMetadata, arg.InvoiceID, arg.Key, arg.Value)
+	return err
+}
+
 const nextInvoiceSettleIndex = `-- name: NextInvoiceSettleIndex :one
 UPDATE invoice_sequences SET current_value = current_value + 1
 WHERE name = 'settle_index'
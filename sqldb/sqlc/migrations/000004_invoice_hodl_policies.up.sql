@@ -0,0 +1,18 @@
+-- invoice_hodl_policies contains the policies that automatically resolve hodl
+-- invoices which are held in the accepted state for too long.
+CREATE TABLE IF NOT EXISTS invoice_hodl_policies (
+    -- The invoice id this policy belongs to.
+    invoice_id BIGINT PRIMARY KEY REFERENCES invoices(id) ON DELETE CASCADE,
+
+    -- The maximum time in nanoseconds the invoice is held after its htlc set
+    -- was accepted.
+    hold_duration BIGINT NOT NULL,
+
+    -- The action applied once the hold duration has passed. 0 cancels and 1
+    -- settles the invoice.
+    action SMALLINT NOT NULL,
+
+    -- The preimage used to settle the invoice. Only set if the action
+    -- settles the invoice.
+    preimage BLOB
+);
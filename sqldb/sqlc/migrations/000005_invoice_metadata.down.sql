@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS invoice_metadata_key_idx;
+DROP TABLE IF EXISTS invoice_metadata;
@@ -0,0 +1,16 @@
+-- invoice_metadata contains the key-value metadata that the creator of an
+-- invoice attached to it.
+CREATE TABLE IF NOT EXISTS invoice_metadata (
+    -- The invoice id this entry belongs to.
+    invoice_id BIGINT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+
+    -- The key of the entry.
+    key TEXT NOT NULL,
+
+    -- The value of the entry.
+    value TEXT NOT NULL,
+
+    PRIMARY KEY (invoice_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS invoice_metadata_key_idx ON invoice_metadata(key, value);
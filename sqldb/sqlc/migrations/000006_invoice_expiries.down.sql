@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS invoice_expiries_expires_at_idx;
+DROP TABLE IF EXISTS invoice_expiries;
@@ -0,0 +1,15 @@
+-- invoice_expiries indexes the open invoices by the time they expire, so that
+-- the invoices that expire next can be loaded without scanning all pending
+-- invoices.
+CREATE TABLE IF NOT EXISTS invoice_expiries (
+    -- The invoice id this entry belongs to.
+    invoice_id BIGINT PRIMARY KEY REFERENCES invoices(id) ON DELETE CASCADE,
+
+    -- The time the invoice expires in unix nanoseconds.
+    expires_at BIGINT NOT NULL,
+
+    -- This field will be true if the invoice has no payment request.
+    is_keysend BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS invoice_expiries_expires_at_idx ON invoice_expiries(expires_at);
@@ -62,11 +62,24 @@ type InvoiceEventType struct {
 	Description string
 }
 
+type InvoiceExpiry struct {
+	InvoiceID int64
+	ExpiresAt int64
+	IsKeysend bool
+}
+
 type InvoiceFeature struct {
 	Feature   int32
 	InvoiceID int64
 }
 
+type InvoiceHodlPolicy struct {
+	InvoiceID    int64
+	HoldDuration int64
+	Action       int16
+	Preimage     []byte
+}
+
 type InvoiceHtlc struct {
 	ID           int64
 	ChanID       string
@@ -87,6 +100,12 @@ type InvoiceHtlcCustomRecord struct {
 	HtlcID int64
 }
 
+type InvoiceMetadatum struct {
+	InvoiceID int64
+	Key       string
+	Value     string
+}
+
 type InvoiceSequence struct {
 	Name         string
 	CurrentValue int64
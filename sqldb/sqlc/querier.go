@@ -10,11 +10,15 @@ import (
 )
 
 type Querier interface {
+	AmendInvoice(ctx context.Context, arg AmendInvoiceParams) (sql.Result, error)
 	DeleteCanceledInvoices(ctx context.Context) (sql.Result, error)
 	DeleteInvoice(ctx context.Context, arg DeleteInvoiceParams) (sql.Result, error)
+	DeleteInvoiceExpiry(ctx context.Context, invoiceID int64) error
 	FetchAMPSubInvoiceHTLCs(ctx context.Context, arg FetchAMPSubInvoiceHTLCsParams) ([]FetchAMPSubInvoiceHTLCsRow, error)
 	FetchAMPSubInvoices(ctx context.Context, arg FetchAMPSubInvoicesParams) ([]AmpSubInvoice, error)
+	FetchInvoiceExpiries(ctx context.Context, arg FetchInvoiceExpiriesParams) ([]FetchInvoiceExpiriesRow, error)
 	FetchSettledAMPSubInvoices(ctx context.Context, arg FetchSettledAMPSubInvoicesParams) ([]FetchSettledAMPSubInvoicesRow, error)
+	FetchUnindexedOpenInvoices(ctx context.Context, numLimit int32) ([]Invoice, error)
 	FilterInvoices(ctx context.Context, arg FilterInvoicesParams) ([]Invoice, error)
 	GetAMPInvoiceID(ctx context.Context, setID []byte) (int64, error)
 	// This method may return more than one invoice if filter using multiple fields
@@ -24,11 +28,16 @@ type Querier interface {
 	GetInvoiceFeatures(ctx context.Context, invoiceID int64) ([]InvoiceFeature, error)
 	GetInvoiceHTLCCustomRecords(ctx context.Context, invoiceID int64) ([]GetInvoiceHTLCCustomRecordsRow, error)
 	GetInvoiceHTLCs(ctx context.Context, invoiceID int64) ([]InvoiceHtlc, error)
+	GetInvoiceHodlPolicy(ctx context.Context, invoiceID int64) (InvoiceHodlPolicy, error)
+	GetInvoiceMetadata(ctx context.Context, invoiceID int64) ([]InvoiceMetadatum, error)
 	InsertAMPSubInvoiceHTLC(ctx context.Context, arg InsertAMPSubInvoiceHTLCParams) error
 	InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (int64, error)
+	InsertInvoiceExpiry(ctx context.Context, arg InsertInvoiceExpiryParams) error
 	InsertInvoiceFeature(ctx context.Context, arg InsertInvoiceFeatureParams) error
 	InsertInvoiceHTLC(ctx context.Context, arg InsertInvoiceHTLCParams) (int64, error)
 	InsertInvoiceHTLCCustomRecord(ctx context.Context, arg InsertInvoiceHTLCCustomRecordParams) error
+	InsertInvoiceHodlPolicy(ctx context.Context, arg InsertInvoiceHodlPolicyParams) error
+	InsertInvoiceMetadata(ctx context.Context, arg InsertInvoiceMetadataParams) error
 	NextInvoiceSettleIndex(ctx context.Context) (int64, error)
 	OnAMPSubInvoiceCanceled(ctx context.Context, arg OnAMPSubInvoiceCanceledParams) error
 	OnAMPSubInvoiceCreated(ctx context.Context, arg OnAMPSubInvoiceCreatedParams) error
@@ -19,6 +19,30 @@ SELECT *
 FROM invoice_features
 WHERE invoice_id = $1;
 
+-- name: InsertInvoiceHodlPolicy :exec
+INSERT INTO invoice_hodl_policies (
+    invoice_id, hold_duration, action, preimage
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: GetInvoiceHodlPolicy :one
+SELECT *
+FROM invoice_hodl_policies
+WHERE invoice_id = $1;
+
+-- name: InsertInvoiceMetadata :exec
+INSERT INTO invoice_metadata (
+    invoice_id, key, value
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: GetInvoiceMetadata :many
+SELECT *
+FROM invoice_metadata
+WHERE invoice_id = $1;
+
 -- This method may return more than one invoice if filter using multiple fields
 -- from different invoices. It is the caller's responsibility to ensure that 
 -- we bubble up an error in those cases.
@@ -76,6 +100,17 @@ WHERE (
         WHEN sqlc.narg('pending_only') = TRUE THEN (state = 0 OR state = 3)
         ELSE TRUE 
     END
+) AND (
+    EXISTS (
+        SELECT 1
+        FROM invoice_metadata m
+        WHERE m.invoice_id = invoices.id AND
+            m.key = sqlc.narg('metadata_key') AND (
+                m.value = sqlc.narg('metadata_value') OR
+                sqlc.narg('metadata_value') IS NULL
+            )
+    ) OR
+    sqlc.narg('metadata_key') IS NULL
 )
 ORDER BY
 CASE
@@ -101,6 +136,14 @@ UPDATE invoices
 SET amount_paid_msat = $2
 WHERE id = $1;
 
+-- name: AmendInvoice :execresult
+UPDATE invoices
+SET memo = $2,
+    expiry = $3,
+    payment_request = $4,
+    payment_request_hash = $5
+WHERE id = $1;
+
 -- name: NextInvoiceSettleIndex :one
 UPDATE invoice_sequences SET current_value = current_value + 1
 WHERE name = 'settle_index'
@@ -162,3 +205,36 @@ INSERT INTO invoice_htlc_custom_records (
 SELECT ihcr.htlc_id, key, value
 FROM invoice_htlcs ih JOIN invoice_htlc_custom_records ihcr ON ih.id=ihcr.htlc_id 
 WHERE ih.invoice_id = $1;
+
+-- name: InsertInvoiceExpiry :exec
+INSERT INTO invoice_expiries (
+    invoice_id, expires_at, is_keysend
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: DeleteInvoiceExpiry :exec
+DELETE
+FROM invoice_expiries
+WHERE invoice_id = $1;
+
+-- name: FetchInvoiceExpiries :many
+SELECT i.hash, e.expires_at, e.is_keysend
+FROM invoice_expiries e
+JOIN invoices i ON i.id = e.invoice_id
+WHERE e.expires_at > @expires_after OR (
+    e.expires_at = @expires_after AND i.hash > @hash_after
+)
+ORDER BY e.expires_at ASC, i.hash ASC
+LIMIT @num_limit;
+
+-- name: FetchUnindexedOpenInvoices :many
+SELECT i.*
+FROM invoices i
+WHERE i.state = 0 AND NOT EXISTS (
+    SELECT 1
+    FROM invoice_expiries e
+    WHERE e.invoice_id = i.id
+)
+ORDER BY i.id ASC
+LIMIT @num_limit;
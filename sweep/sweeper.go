@@ -199,6 +199,11 @@ type SweeperInput struct {
 	// different from the DeadlineHeight in its params as it's an actual
 	// value than an option.
 	DeadlineHeight int32
+
+	// deferred indicates that the input wasn't included in any sweeping
+	// transaction during the last sweep attempt, because its budget
+	// cannot cover the fees required to sweep it.
+	deferred bool
 }
 
 // String returns a human readable interpretation of the pending input.
@@ -257,6 +262,11 @@ type PendingInputResponse struct {
 
 	// DeadlineHeight records the deadline height of this input.
 	DeadlineHeight uint32
+
+	// Deferred indicates that the input is currently not being swept,
+	// because the fees required to sweep it exceed its budget. It will be
+	// retried with every new block, e.g. once the fee rates drop.
+	Deferred bool
 }
 
 // updateReq is an internal message we'll use to represent an external caller's
@@ -1053,6 +1063,7 @@ func (s *UtxoSweeper) handlePendingSweepsReq(
 			BroadcastAttempts: inp.publishAttempts,
 			Params:            inp.params,
 			DeadlineHeight:    uint32(inp.DeadlineHeight),
+			Deferred:          inp.deferred,
 		}
 	}
 
@@ -1525,12 +1536,42 @@ func (s *UtxoSweeper) updateSweeperInputs() InputsMap {
 	return inputs
 }
 
+// markDeferredInputs marks all inputs that are not part of any of the given
+// input sets as deferred.
+func (s *UtxoSweeper) markDeferredInputs(inputs InputsMap, sets []InputSet) {
+	clustered := make(map[wire.OutPoint]struct{}, len(inputs))
+	for _, set := range sets {
+		for _, inp := range set.Inputs() {
+			clustered[inp.OutPoint()] = struct{}{}
+		}
+	}
+
+	var numDeferred int
+	for op, inp := range inputs {
+		_, ok := clustered[op]
+		inp.deferred = !ok
+
+		if inp.deferred {
+			numDeferred++
+		}
+	}
+
+	if numDeferred > 0 {
+		log.Infof("Deferred %v of %v inputs that cannot be swept "+
+			"within their budgets", numDeferred, len(inputs))
+	}
+}
+
 // sweepPendingInputs is called when the ticker fires. It will create clusters
 // and attempt to create and publish the sweeping transactions.
 func (s *UtxoSweeper) sweepPendingInputs(inputs InputsMap) {
 	// Cluster all of our inputs based on the specific Aggregator.
 	sets := s.cfg.Aggregator.ClusterInputs(inputs)
 
+	// The aggregator leaves out inputs that aren't economical to sweep,
+	// so we mark them as deferred in order to report them.
+	s.markDeferredInputs(inputs, sets)
+
 	// sweepWithLock is a helper closure that executes the sweep within a
 	// coin select lock to prevent the coins being selected for other
 	// transactions like funding of a channel.
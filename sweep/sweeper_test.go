@@ -727,6 +727,43 @@ func TestSweepPendingInputs(t *testing.T) {
 	s.sweepPendingInputs(pis)
 }
 
+// TestMarkDeferredInputs checks that the inputs that are not part of any
+// input set are marked as deferred, and reported as such.
+func TestMarkDeferredInputs(t *testing.T) {
+	t.Parallel()
+
+	// Create a test sweeper.
+	s := New(&UtxoSweeperConfig{})
+
+	// Create two mock inputs, of which only the first one is clustered.
+	op1 := wire.OutPoint{Index: 1}
+	op2 := wire.OutPoint{Index: 2}
+
+	inp1 := &input.MockInput{}
+	defer inp1.AssertExpectations(t)
+	inp1.On("OutPoint").Return(op1)
+
+	inp2 := &input.MockInput{}
+	defer inp2.AssertExpectations(t)
+
+	// The first input was deferred in a previous sweep attempt.
+	input1 := &SweeperInput{state: Init, Input: inp1, deferred: true}
+	input2 := &SweeperInput{state: Init, Input: inp2}
+	inputs := InputsMap{
+		op1: input1,
+		op2: input2,
+	}
+
+	set := &MockInputSet{}
+	defer set.AssertExpectations(t)
+	set.On("Inputs").Return([]input.Input{inp1}).Once()
+
+	s.markDeferredInputs(inputs, []InputSet{set})
+
+	require.False(t, input1.deferred)
+	require.True(t, input2.deferred)
+}
+
 // TestHandleBumpEventTxFailed checks that the sweeper correctly handles the
 // case where the bump event tx fails to be published.
 func TestHandleBumpEventTxFailed(t *testing.T) {